@@ -240,6 +240,7 @@ const (
 	ColumnOptionFulltext
 	ColumnOptionComment
 	ColumnOptionGenerated
+	ColumnOptionCheck
 )
 
 // ColumnOption is used for parsing column constraint info from SQL.
@@ -248,7 +249,7 @@ type ColumnOption struct {
 
 	Tp ColumnOptionType
 	// For ColumnOptionDefaultValue or ColumnOptionOnUpdate, it's the target value.
-	// For ColumnOptionGenerated, it's the target expression.
+	// For ColumnOptionGenerated and ColumnOptionCheck, it's the target expression.
 	Expr ExprNode
 	// Stored is only for ColumnOptionGenerated, default is false.
 	Stored bool
@@ -309,6 +310,7 @@ const (
 	ConstraintUniqIndex
 	ConstraintForeignKey
 	ConstraintFulltext
+	ConstraintCheck
 )
 
 // Constraint is constraint for table definition.
@@ -323,6 +325,8 @@ type Constraint struct {
 	Refer *ReferenceDef // Used for foreign key.
 
 	Option *IndexOption // Index Options
+
+	Expr ExprNode // Used for CHECK.
 }
 
 // Accept implements Node Accept interface.
@@ -353,6 +357,13 @@ func (n *Constraint) Accept(v Visitor) (Node, bool) {
 		}
 		n.Option = node.(*IndexOption)
 	}
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
+	}
 	return v.Leave(n)
 }
 
@@ -398,6 +409,8 @@ type CreateTableStmt struct {
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	// Select is the query whose result is used to create and fill the table.
+	Select ResultSetNode
 }
 
 // Accept implements Node Accept interface.
@@ -433,6 +446,13 @@ func (n *CreateTableStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Constraints[i] = node.(*Constraint)
 	}
+	if n.Select != nil {
+		node, ok = n.Select.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Select = node.(ResultSetNode)
+	}
 	return v.Leave(n)
 }
 
@@ -618,6 +638,8 @@ const (
 	TableOptionDelayKeyWrite
 	TableOptionRowFormat
 	TableOptionStatsPersistent
+	TableOptionShardRowID
+	TableOptionPreSplitRegion
 )
 
 // RowFormat types
@@ -690,6 +712,7 @@ const (
 	AlterTableRenameTable
 	AlterTableAlterColumn
 	AlterTableLock
+	AlterTableDropCheck
 
 // TODO: Add more actions
 )
@@ -30,6 +30,7 @@ var (
 
 	_ Node = &Assignment{}
 	_ Node = &ByItem{}
+	_ Node = &CommonTableExpression{}
 	_ Node = &FieldList{}
 	_ Node = &GroupByClause{}
 	_ Node = &HavingClause{}
@@ -43,6 +44,7 @@ var (
 	_ Node = &TableSource{}
 	_ Node = &UnionSelectList{}
 	_ Node = &WildCardField{}
+	_ Node = &WithClause{}
 )
 
 // JoinType is join type, including cross/left/right/full.
@@ -115,6 +117,8 @@ type TableName struct {
 
 	DBInfo    *model.DBInfo
 	TableInfo *model.TableInfo
+	// CTE is set by the name resolver if the table name refers to a common table expression.
+	CTE *CommonTableExpression
 
 	IndexHints []*IndexHint
 }
@@ -214,6 +218,9 @@ type TableSource struct {
 
 	// AsName is the alias name of the table source.
 	AsName model.CIStr
+
+	// AsOf is the AS OF TIMESTAMP clause of the table, the table is read at the timestamp if it's not nil.
+	AsOf *AsOfClause
 }
 
 // Accept implements Node Accept interface.
@@ -228,6 +235,37 @@ func (n *TableSource) Accept(v Visitor) (Node, bool) {
 		return n, false
 	}
 	n.Source = node.(ResultSetNode)
+	if n.AsOf != nil {
+		node, ok := n.AsOf.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.AsOf = node.(*AsOfClause)
+	}
+	return v.Leave(n)
+}
+
+// AsOfClause represents the AS OF TIMESTAMP clause of a table, like "t AS OF TIMESTAMP '2017-11-11 00:00:00'".
+// It reads the historical data at the timestamp.
+type AsOfClause struct {
+	node
+
+	// TsExpr is the expression of the timestamp.
+	TsExpr ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *AsOfClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*AsOfClause)
+	node, ok := n.TsExpr.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.TsExpr = node.(ExprNode)
 	return v.Leave(n)
 }
 
@@ -241,6 +279,59 @@ func (n *TableSource) GetResultFields() []*ResultField {
 	return n.Source.GetResultFields()
 }
 
+// CommonTableExpression represents a named subquery defined in the WITH clause,
+// like "cte (a, b) AS (SELECT ...)".
+type CommonTableExpression struct {
+	node
+
+	// Name is the name of the common table expression.
+	Name model.CIStr
+	// ColNames is the optional column name list, it renames the result columns of the query.
+	ColNames []model.CIStr
+	// Query is the SelectStmt or UnionStmt that defines the common table expression.
+	Query ResultSetNode
+}
+
+// Accept implements Node Accept interface.
+func (n *CommonTableExpression) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CommonTableExpression)
+	node, ok := n.Query.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Query = node.(ResultSetNode)
+	return v.Leave(n)
+}
+
+// WithClause represents the WITH clause of a query, it defines the common table expressions
+// that can be referenced in the query like tables.
+type WithClause struct {
+	node
+
+	CTEs []*CommonTableExpression
+}
+
+// Accept implements Node Accept interface.
+func (n *WithClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WithClause)
+	for i, cte := range n.CTEs {
+		node, ok := cte.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.CTEs[i] = node.(*CommonTableExpression)
+	}
+	return v.Leave(n)
+}
+
 // SelectLockType is the lock type for SelectStmt.
 type SelectLockType int
 
@@ -365,12 +456,24 @@ func (n *TableRefsClause) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// NullOrderType is the type for the NULLS FIRST / NULLS LAST option of ByItem.
+type NullOrderType int
+
+// NullOrder types.
+const (
+	NullOrderDefault NullOrderType = iota
+	NullsFirst
+	NullsLast
+)
+
 // ByItem represents an item in order by or group by.
 type ByItem struct {
 	node
 
 	Expr ExprNode
 	Desc bool
+	// NullOrder is either NullOrderDefault, NullsFirst or NullsLast.
+	NullOrder NullOrderType
 }
 
 // Accept implements Node Accept interface.
@@ -484,6 +587,8 @@ type SelectStmt struct {
 	LockTp SelectLockType
 	// TableHints represents the level Optimizer Hint
 	TableHints []*TableOptimizerHint
+	// With is the WITH clause of the query.
+	With *WithClause
 }
 
 // Accept implements Node Accept interface.
@@ -494,6 +599,14 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	}
 
 	n = newNode.(*SelectStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
+
 	if n.TableHints != nil && len(n.TableHints) != 0 {
 		newHints := make([]*TableOptimizerHint, len(n.TableHints))
 		for i, hint := range n.TableHints {
@@ -599,6 +712,8 @@ type UnionStmt struct {
 	SelectList *UnionSelectList
 	OrderBy    *OrderByClause
 	Limit      *Limit
+	// With is the WITH clause of the query.
+	With *WithClause
 }
 
 // Accept implements Node Accept interface.
@@ -608,6 +723,13 @@ func (n *UnionStmt) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNode)
 	}
 	n = newNode.(*UnionStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
 	if n.SelectList != nil {
 		node, ok := n.SelectList.Accept(v)
 		if !ok {
@@ -963,6 +1085,7 @@ const (
 	ShowStatsMeta
 	ShowStatsHistograms
 	ShowStatsBuckets
+	ShowOptRules
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
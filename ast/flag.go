@@ -84,6 +84,8 @@ func (f *flagSetter) Leave(in Node) (Node, bool) {
 		} else {
 			x.SetFlag(FlagHasVariable | x.Value.GetFlag())
 		}
+	case *WindowFuncExpr:
+		f.windowFunc(x)
 	}
 
 	return in, true
@@ -154,3 +156,17 @@ func (f *flagSetter) aggregateFunc(x *AggregateFuncExpr) {
 	}
 	x.SetFlag(flag)
 }
+
+func (f *flagSetter) windowFunc(x *WindowFuncExpr) {
+	flag := FlagHasFunc
+	for _, val := range x.Args {
+		flag |= val.GetFlag()
+	}
+	for _, val := range x.Spec.PartitionBy {
+		flag |= val.GetFlag()
+	}
+	for _, item := range x.Spec.OrderBy {
+		flag |= item.Expr.GetFlag()
+	}
+	x.SetFlag(flag)
+}
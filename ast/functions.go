@@ -22,6 +22,7 @@ var (
 	_ FuncNode = &AggregateFuncExpr{}
 	_ FuncNode = &FuncCallExpr{}
 	_ FuncNode = &FuncCastExpr{}
+	_ FuncNode = &WindowFuncExpr{}
 )
 
 // List scalar function names.
@@ -401,6 +402,12 @@ const (
 	AggFuncMin = "min"
 	// AggFuncGroupConcat is the name of group_concat function.
 	AggFuncGroupConcat = "group_concat"
+	// AggFuncBitAnd is the name of bit_and function.
+	AggFuncBitAnd = "bit_and"
+	// AggFuncBitOr is the name of bit_or function.
+	AggFuncBitOr = "bit_or"
+	// AggFuncBitXor is the name of bit_xor function.
+	AggFuncBitXor = "bit_xor"
 )
 
 // AggregateFuncExpr represents aggregate function expression.
@@ -432,3 +439,110 @@ func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 	}
 	return v.Leave(n)
 }
+
+const (
+	// WindowFuncRowNumber is the name of row_number function.
+	WindowFuncRowNumber = "row_number"
+	// WindowFuncRank is the name of rank function.
+	WindowFuncRank = "rank"
+	// WindowFuncDenseRank is the name of dense_rank function.
+	WindowFuncDenseRank = "dense_rank"
+)
+
+// WindowFuncExpr represents window function expression.
+// It's a ranking function or an aggregate function with an OVER clause, it computes a value for every row
+// from the rows in the window of the row, e.g. "sum(c1) over (partition by c2 order by c3)".
+type WindowFuncExpr struct {
+	funcNode
+	// F is the function name.
+	F string
+	// Args is the function args.
+	Args []ExprNode
+	// Distinct is true if the aggregate function has DISTINCT, it's not supported by the planner now.
+	Distinct bool
+	// Spec is the window specification in the OVER clause.
+	Spec WindowSpec
+}
+
+// WindowSpec is the window specification of a window function.
+type WindowSpec struct {
+	// PartitionBy is the expressions to split the rows into partitions.
+	PartitionBy []ExprNode
+	// OrderBy is the order of the rows in a partition.
+	OrderBy []*ByItem
+	// Frame is the frame of the window, nil means the default frame.
+	Frame *FrameClause
+}
+
+// FrameType is the unit of the window frame.
+type FrameType int
+
+// Window frame types.
+const (
+	// FrameRows means the frame bounds are the offsets of rows.
+	FrameRows FrameType = iota
+	// FrameRange means the frame bounds are the ranges of the order values.
+	FrameRange
+)
+
+// BoundType is the type of the window frame bound.
+type BoundType int
+
+// Window frame bound types.
+const (
+	// Preceding is "UNBOUNDED PRECEDING" or "N PRECEDING".
+	Preceding BoundType = iota
+	// Following is "UNBOUNDED FOLLOWING" or "N FOLLOWING".
+	Following
+	// CurrentRow is "CURRENT ROW".
+	CurrentRow
+)
+
+// FrameBound is the start or the end of the window frame.
+type FrameBound struct {
+	Type BoundType
+	// UnBounded is true for "UNBOUNDED PRECEDING" and "UNBOUNDED FOLLOWING".
+	UnBounded bool
+	// Offset is the N of "N PRECEDING" and "N FOLLOWING".
+	Offset uint64
+}
+
+// FrameClause is the frame clause of the window specification.
+type FrameClause struct {
+	Type  FrameType
+	Start FrameBound
+	End   FrameBound
+}
+
+// Accept implements Node Accept interface.
+// The ByItems of the window are not visited as nodes, their expressions are visited directly,
+// because they are not the ORDER BY clause of the select statement.
+func (n *WindowFuncExpr) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WindowFuncExpr)
+	for i, val := range n.Args {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Args[i] = node.(ExprNode)
+	}
+	for i, val := range n.Spec.PartitionBy {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Spec.PartitionBy[i] = node.(ExprNode)
+	}
+	for _, item := range n.Spec.OrderBy {
+		node, ok := item.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		item.Expr = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
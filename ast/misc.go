@@ -33,9 +33,12 @@ var (
 	_ StmtNode = &DoStmt{}
 	_ StmtNode = &ExecuteStmt{}
 	_ StmtNode = &ExplainStmt{}
+	_ StmtNode = &ExplainForStmt{}
 	_ StmtNode = &GrantStmt{}
 	_ StmtNode = &PrepareStmt{}
 	_ StmtNode = &RollbackStmt{}
+	_ StmtNode = &SavepointStmt{}
+	_ StmtNode = &ReleaseSavepointStmt{}
 	_ StmtNode = &SetPwdStmt{}
 	_ StmtNode = &SetStmt{}
 	_ StmtNode = &UseStmt{}
@@ -100,6 +103,24 @@ func (n *ExplainStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ExplainForStmt is a statement to provide information about how is the statement running on a connection executed.
+// See https://dev.mysql.com/doc/refman/5.7/en/explain-for-connection.html
+type ExplainForStmt struct {
+	stmtNode
+
+	ConnectionID uint64
+}
+
+// Accept implements Node Accept interface.
+func (n *ExplainForStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ExplainForStmt)
+	return v.Leave(n)
+}
+
 // PrepareStmt is a statement to prepares a SQL statement which contains placeholders,
 // and it is executed with ExecuteStmt and released with DeallocateStmt.
 // See https://dev.mysql.com/doc/refman/5.7/en/prepare.html
@@ -222,10 +243,13 @@ func (n *CommitStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
-// RollbackStmt is a statement to roll back the current transaction.
+// RollbackStmt is a statement to roll back the current transaction,
+// or to roll back to the savepoint if SavepointName is set.
 // See https://dev.mysql.com/doc/refman/5.7/en/commit.html
 type RollbackStmt struct {
 	stmtNode
+
+	SavepointName string
 }
 
 // Accept implements Node Accept interface.
@@ -238,6 +262,42 @@ func (n *RollbackStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// SavepointStmt is a statement to set a savepoint of the current transaction.
+// See https://dev.mysql.com/doc/refman/5.7/en/savepoint.html
+type SavepointStmt struct {
+	stmtNode
+
+	Name string
+}
+
+// Accept implements Node Accept interface.
+func (n *SavepointStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SavepointStmt)
+	return v.Leave(n)
+}
+
+// ReleaseSavepointStmt is a statement to remove a savepoint and the ones set after it.
+// See https://dev.mysql.com/doc/refman/5.7/en/savepoint.html
+type ReleaseSavepointStmt struct {
+	stmtNode
+
+	Name string
+}
+
+// Accept implements Node Accept interface.
+func (n *ReleaseSavepointStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ReleaseSavepointStmt)
+	return v.Leave(n)
+}
+
 // UseStmt is a statement to use the DBName database as the current database.
 // See https://dev.mysql.com/doc/refman/5.7/en/use.html
 type UseStmt struct {
@@ -257,9 +317,12 @@ func (n *UseStmt) Accept(v Visitor) (Node, bool) {
 }
 
 const (
-	// SetNames is the const for set names/charset stmt.
-	// If VariableAssignment.Name == Names, it should be set names/charset stmt.
+	// SetNames is the const for set names stmt.
+	// If VariableAssignment.Name == SetNames, it should be set names stmt.
 	SetNames = "SetNAMES"
+	// SetCharset is the const for set character set stmt.
+	// If VariableAssignment.Name == SetCharset, it should be set character set stmt.
+	SetCharset = "SetCHARSET"
 )
 
 // VariableAssignment is a variable assignment struct.
@@ -338,7 +401,8 @@ type KillStmt struct {
 	// new a connection; kill xxx;
 	// kill command may send to the wrong TiDB, because the exists of LVS proxy, and kill the wrong session.
 	// So, "KILL TIDB" grammar is introduced, and it REQUIRES DIRECT client -> TiDB TOPOLOGY.
-	// TODO: The standard KILL grammar will be supported once we have global connectionID.
+	// The standard KILL grammar is supported when the server is started with -enable-global-kill,
+	// the connection IDs are unique among the servers then, and KILL is routed to the owning server.
 	TiDBExtension bool
 }
 
@@ -525,14 +589,39 @@ type AdminStmtType int
 const (
 	AdminShowDDL = iota + 1
 	AdminCheckTable
+	AdminBlockDigests
+	AdminUnblockDigests
+	AdminUnblockAllDigests
+	AdminRecoverAutoIncrement
+	AdminRecommendIndex
+	AdminShowIndexUsage
+	AdminEnableSQLLog
+	AdminDisableSQLLog
+	AdminShowTopSQL
+	AdminShowSlow
 )
 
 // AdminStmt is the struct for Admin statement.
 type AdminStmt struct {
 	stmtNode
 
-	Tp     AdminStmtType
-	Tables []*TableName
+	Tp      AdminStmtType
+	Tables  []*TableName
+	Digests []string
+	// Stmt is the query to recommend indexes for.
+	Stmt StmtNode
+	// ConnectionID is the connection whose statements are logged by "admin enable log for connection".
+	ConnectionID uint64
+	// LogFile is the file the statements of the connection are written to.
+	LogFile string
+	// TopSQLByTime ranks the statements by the total execution time instead of the CPU time in "admin show top sql".
+	TopSQLByTime bool
+	// TopSQLCount is the number of the statements returned by "admin show top sql", 0 means the default.
+	TopSQLCount uint64
+	// ShowSlowTop returns the slowest queries instead of the most recent ones in "admin show slow".
+	ShowSlowTop bool
+	// ShowSlowCount is the number of the slow queries returned by "admin show slow".
+	ShowSlowCount uint64
 }
 
 // Accept implements Node Accpet interface.
@@ -550,6 +639,13 @@ func (n *AdminStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Tables[i] = node.(*TableName)
 	}
+	if n.Stmt != nil {
+		node, ok := n.Stmt.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Stmt = node.(StmtNode)
+	}
 
 	return v.Leave(n)
 }
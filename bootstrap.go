@@ -65,6 +65,7 @@ const (
 		Create_user_priv		ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Event_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Trigger_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
+		Default_db			CHAR(64) NOT NULL DEFAULT '',
 		PRIMARY KEY (Host, User));`
 	// CreateDBPrivTable is the SQL statement creates DB scope privilege table in system db.
 	CreateDBPrivTable = `CREATE TABLE if not exists mysql.db (
@@ -185,6 +186,20 @@ const (
 		UNIQUE KEY (element_id),
 		KEY (job_id, element_id)
 	);`
+
+	// CreateBlockedDigestsTable stores the digests of the statements that are not allowed to run.
+	CreateBlockedDigestsTable = `CREATE TABLE IF NOT EXISTS mysql.blocked_digests (
+		digest VARCHAR(64) NOT NULL PRIMARY KEY
+	);`
+
+	// CreateStatsIndexUsageTable stores the access count and the last access time of every index.
+	CreateStatsIndexUsageTable = `CREATE TABLE IF NOT EXISTS mysql.stats_index_usage (
+		table_id bigint(64) NOT NULL,
+		index_id bigint(64) NOT NULL,
+		access_count bigint(64) NOT NULL DEFAULT 0,
+		last_used_at datetime,
+		unique index tbl(table_id, index_id)
+	);`
 )
 
 // bootstrap initiates system DB for a store.
@@ -226,6 +241,9 @@ const (
 	version13 = 13
 	version14 = 14
 	version15 = 15
+	version16 = 16
+	version17 = 17
+	version18 = 18
 )
 
 func checkBootstrapped(s Session) (bool, error) {
@@ -340,6 +358,18 @@ func upgrade(s Session) {
 		upgradeToVer15(s)
 	}
 
+	if ver < version16 {
+		upgradeToVer16(s)
+	}
+
+	if ver < version17 {
+		upgradeToVer17(s)
+	}
+
+	if ver < version18 {
+		upgradeToVer18(s)
+	}
+
 	updateBootstrapVer(s)
 	_, err = s.Execute("COMMIT")
 
@@ -559,6 +589,19 @@ func upgradeToVer15(s Session) {
 	}
 }
 
+func upgradeToVer16(s Session) {
+	mustExecute(s, CreateBlockedDigestsTable)
+}
+
+func upgradeToVer17(s Session) {
+	mustExecute(s, CreateStatsIndexUsageTable)
+}
+
+// upgradeToVer18 adds the default database of the user, it's used by the connections not specifying a database.
+func upgradeToVer18(s Session) {
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `Default_db` CHAR(64) NOT NULL DEFAULT '' AFTER `Trigger_priv`", infoschema.ErrColumnExists)
+}
+
 // updateBootstrapVer updates bootstrap version variable in mysql.TiDB table.
 func updateBootstrapVer(s Session) {
 	// Update bootstrap version.
@@ -605,6 +648,10 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateStatsBucketsTable)
 	// Create gc_delete_range table.
 	mustExecute(s, CreateGCDeleteRangeTable)
+	// Create blocked_digests table.
+	mustExecute(s, CreateBlockedDigestsTable)
+	// Create stats_index_usage table.
+	mustExecute(s, CreateStatsIndexUsageTable)
 }
 
 // doDMLWorks executes DML statements in bootstrap stage.
@@ -614,7 +661,7 @@ func doDMLWorks(s Session) {
 
 	// Insert a default user with empty password.
 	mustExecute(s, `INSERT INTO mysql.user VALUES
-		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "")`)
 
 	// Init global system variables table.
 	values := make([]string, 0, len(variable.SysVars))
@@ -13,7 +13,10 @@
 
 package config
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // Config contains configuration options.
 type Config struct {
@@ -28,8 +31,72 @@ type Config struct {
 	SlowThreshold  int    `json:"slow_threshold" toml:"slow_threshold"`
 	QueryLogMaxlen int    `json:"query_log_max_len" toml:"query_log_max_len"`
 	TCPKeepAlive   bool   `json:"tcp_keep_alive" toml:"tcp_keep_alive"`
+	// LogRedactLiterals makes the SQL text written to the slow query log and the general log normalized,
+	// the literal values are replaced by '?', so the sensitive data isn't leaked to the logs.
+	LogRedactLiterals bool `json:"log_redact_literals" toml:"log_redact_literals"`
+	// HandshakeTimeout is the maximum duration for a client to finish the connection handshake,
+	// the connection is closed if it's exceeded. 0 means no timeout.
+	HandshakeTimeout time.Duration `json:"handshake_timeout" toml:"handshake_timeout"`
+	// IdleInTxnTimeout is the maximum duration a connection can stay idle in a transaction, the connection is
+	// closed and the transaction is rolled back if it's exceeded. 0 means no timeout.
+	IdleInTxnTimeout time.Duration `json:"idle_in_transaction_timeout" toml:"idle_in_transaction_timeout"`
+	// ForceTextProtocol makes the server encode the result sets of prepared statements in text protocol,
+	// it's a compatibility option for the clients which can't handle the binary protocol correctly.
+	ForceTextProtocol bool `json:"force_text_protocol" toml:"force_text_protocol"`
+	// DumpDir is the directory where the profiles requested by /status/debug/dump are written,
+	// the system temporary directory is used if it's empty.
+	DumpDir string `json:"dump_dir" toml:"dump_dir"`
+	// EnableGlobalKill makes the connection IDs unique among the servers sharing the store, so the standard
+	// KILL statement can be sent to any server. A global connection ID sets the highest bit of the 32 bits ID,
+	// and stores the server ID in the following 11 bits, the local connection ID in the lowest 20 bits.
+	EnableGlobalKill bool `json:"enable_global_kill" toml:"enable_global_kill"`
+	// LogConnections makes the server log every connection when it's established and closed, with the user,
+	// host, connection ID, the duration of the connection and the reason of closing.
+	LogConnections bool `json:"log_connections" toml:"log_connections"`
+	// DisallowEmptyPassword rejects the login of the users with empty passwords,
+	// and the statements which create users or set passwords with empty passwords.
+	DisallowEmptyPassword bool `json:"disallow_empty_password" toml:"disallow_empty_password"`
+	// ExportDir is the directory where the tables exported by /export/{db}/{table} are written,
+	// the export API is disabled if it's empty.
+	ExportDir string `json:"export_dir" toml:"export_dir"`
+	// ExportConcurrency is the max number of key ranges that are exported concurrently by an export request.
+	ExportConcurrency int `json:"export_concurrency" toml:"export_concurrency"`
+	// SQLLogDir is the directory where the files of ADMIN ENABLE LOG FOR CONNECTION are written,
+	// the statement is disabled if it's empty.
+	SQLLogDir string `json:"sql_log_dir" toml:"sql_log_dir"`
+	// GeneralLog makes the server write the statements received from all the connections to the general log,
+	// otherwise only the statements of the sessions with the general_log variable on are written.
+	GeneralLog bool `json:"general_log" toml:"general_log"`
+	// GeneralLogFile is the file the general log is appended to, the server log is used if it's empty.
+	GeneralLogFile string `json:"general_log_file" toml:"general_log_file"`
+	// GeneralLogSampleRate is the fraction of the statements written to the general log,
+	// the statements are sampled only if it's between 0 and 1.
+	GeneralLogSampleRate float64 `json:"general_log_sample_rate" toml:"general_log_sample_rate"`
+	// StatusSSLCert and StatusSSLKey are the certificate and the private key files the status server serves HTTPS with,
+	// the status server serves plaintext HTTP if they're empty.
+	StatusSSLCert string `json:"status_ssl_cert" toml:"status_ssl_cert"`
+	StatusSSLKey  string `json:"status_ssl_key" toml:"status_ssl_key"`
+	// StatusSSLCA is the CA file to verify the client certificates with, the status server requires
+	// the clients to present certificates signed by it if it's not empty.
+	StatusSSLCA string `json:"status_ssl_ca" toml:"status_ssl_ca"`
+	// MaxPreparedStmtCount is the max number of the statements prepared by COM_STMT_PREPARE and not closed
+	// in all the connections, 0 means no limit.
+	MaxPreparedStmtCount int `json:"max_prepared_stmt_count" toml:"max_prepared_stmt_count"`
+	// MaxPreparedStmtCountPerConn is the max number of the statements prepared by COM_STMT_PREPARE and not closed
+	// in a connection, 0 means no limit.
+	MaxPreparedStmtCountPerConn int `json:"max_prepared_stmt_count_per_conn" toml:"max_prepared_stmt_count_per_conn"`
+	// MaxPreparedStmtParams is the max number of the placeholders in a prepared statement,
+	// 0 means DefMaxPreparedStmtParams.
+	MaxPreparedStmtParams int `json:"max_prepared_stmt_params" toml:"max_prepared_stmt_params"`
 }
 
+// DefMaxPreparedStmtCount is the default value of MaxPreparedStmtCount, which is the same as MySQL.
+const DefMaxPreparedStmtCount = 16382
+
+// DefMaxPreparedStmtParams is the default and the max value of MaxPreparedStmtParams,
+// the number of the parameters is a 2 bytes integer in the protocol.
+const DefMaxPreparedStmtParams = 65535
+
 var cfg *Config
 var once sync.Once
 
@@ -39,8 +106,12 @@ var once sync.Once
 func GetGlobalConfig() *Config {
 	once.Do(func() {
 		cfg = &Config{
-			SlowThreshold:  300,
-			QueryLogMaxlen: 2048,
+			SlowThreshold:         300,
+			QueryLogMaxlen:        2048,
+			HandshakeTimeout:      10 * time.Second,
+			GeneralLogSampleRate:  1,
+			MaxPreparedStmtCount:  DefMaxPreparedStmtCount,
+			MaxPreparedStmtParams: DefMaxPreparedStmtParams,
 		}
 	})
 	return cfg
@@ -0,0 +1,438 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// BuildCheckExpr builds the expression of the check constraint on the public columns of the table.
+// It's set by the plan package, the existing rows are validated with it when the constraint is added.
+var BuildCheckExpr func(ctx context.Context, tblInfo *model.TableInfo, ckInfo *model.CheckInfo) (expression.Expression, error)
+
+// disallowedCheckFuncs are the functions whose results are not determined by the row,
+// they can't be used in check constraints.
+var disallowedCheckFuncs = map[string]struct{}{
+	ast.Rand: {}, ast.UUID: {}, ast.Sleep: {}, ast.GetLock: {}, ast.ReleaseLock: {},
+	ast.Now: {}, ast.Sysdate: {}, ast.CurrentTimestamp: {}, ast.CurrentDate: {}, ast.CurrentTime: {},
+	ast.Curdate: {}, ast.Curtime: {}, ast.LocalTime: {}, ast.LocalTimestamp: {},
+	ast.UTCDate: {}, ast.UTCTime: {}, ast.UTCTimestamp: {},
+	ast.ConnectionID: {}, ast.LastInsertId: {}, ast.FoundRows: {}, ast.RowCount: {},
+	ast.CurrentUser: {}, ast.User: {}, ast.SessionUser: {}, ast.SystemUser: {}, ast.Database: {}, ast.Schema: {},
+}
+
+// checkExprVisitor validates the expression of a check constraint and collects the columns referenced by it.
+type checkExprVisitor struct {
+	name string
+	cols []model.CIStr
+	err  error
+}
+
+// Enter implements ast.Visitor interface.
+func (v *checkExprVisitor) Enter(inNode ast.Node) (ast.Node, bool) {
+	switch x := inNode.(type) {
+	case *ast.SubqueryExpr, *ast.AggregateFuncExpr, *ast.WindowFuncExpr, *ast.ParamMarkerExpr, *ast.DefaultExpr:
+		v.err = errCheckConstraintFunction.GenByArgs(v.name)
+	case *ast.VariableExpr:
+		v.err = errCheckConstraintVariables.GenByArgs(v.name)
+	case *ast.FuncCallExpr:
+		if _, ok := disallowedCheckFuncs[x.FnName.L]; ok {
+			v.err = errCheckConstraintNamedFunction.GenByArgs(v.name, x.FnName.O)
+		}
+	case *ast.ColumnNameExpr:
+		for _, col := range v.cols {
+			if col.L == x.Name.Name.L {
+				return inNode, true
+			}
+		}
+		v.cols = append(v.cols, x.Name.Name)
+	}
+	return inNode, v.err != nil
+}
+
+// Leave implements ast.Visitor interface.
+func (v *checkExprVisitor) Leave(inNode ast.Node) (ast.Node, bool) {
+	return inNode, v.err == nil
+}
+
+// genCheckName generates the name of a check constraint like MySQL, it's "<table>_chk_<n>".
+func genCheckName(tblInfo *model.TableInfo) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s_chk_%d", tblInfo.Name.O, i)
+		if findCheck(tblInfo, strings.ToLower(name)) == nil {
+			return name
+		}
+	}
+}
+
+func findCheck(tblInfo *model.TableInfo, name string) *model.CheckInfo {
+	for _, ck := range tblInfo.Checks {
+		if ck.Name.L == name {
+			return ck
+		}
+	}
+	return nil
+}
+
+// buildCheckInfo builds the CheckInfo of the check constraint for the table, the columns of the table must be built.
+func buildCheckInfo(tblInfo *model.TableInfo, constr *ast.Constraint) (*model.CheckInfo, error) {
+	name := constr.Name
+	if name == "" {
+		name = genCheckName(tblInfo)
+	} else if findCheck(tblInfo, strings.ToLower(name)) != nil {
+		return nil, errCheckConstraintDupName.GenByArgs(name)
+	}
+	v := &checkExprVisitor{name: name}
+	constr.Expr.Accept(v)
+	if v.err != nil {
+		return nil, errors.Trace(v.err)
+	}
+	for _, col := range v.cols {
+		if findCol(tblInfo.Columns, col.L) == nil {
+			return nil, errCheckConstraintUnknownColumn.GenByArgs(name, col.O)
+		}
+	}
+	return &model.CheckInfo{
+		Name:       model.NewCIStr(name),
+		ExprString: strings.TrimSpace(constr.Expr.Text()),
+		Cols:       v.cols,
+	}, nil
+}
+
+// checkColumnWithCheck returns an error if the column is used by a check constraint of the table.
+func checkColumnWithCheck(tblInfo *model.TableInfo, colName model.CIStr) error {
+	for _, ck := range tblInfo.Checks {
+		for _, col := range ck.Cols {
+			if col.L == colName.L {
+				return errDependentByCheckConstraint.GenByArgs(ck.Name.O, colName.O)
+			}
+		}
+	}
+	return nil
+}
+
+// AddCheck adds a check constraint to the table, the job fails if the existing rows don't satisfy it.
+func (d *ddl) AddCheck(ctx context.Context, ti ast.Ident, constr *ast.Constraint) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
+	ckInfo, err := buildCheckInfo(t.Meta(), constr)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionAddCheck,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{ckInfo},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// DropCheck drops the check constraint of the table.
+func (d *ddl) DropCheck(ctx context.Context, ti ast.Ident, name model.CIStr) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+	if findCheck(t.Meta(), name.L) == nil {
+		return errConstraintNotFound.GenByArgs(name.O)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionDropCheck,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{name},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) onAddCheck(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	var newCheck model.CheckInfo
+	err = job.DecodeArgs(&newCheck)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	ckInfo := findCheck(tblInfo, newCheck.Name.L)
+	if ckInfo != nil && ckInfo.State == model.StatePublic {
+		job.State = model.JobCancelled
+		return ver, errCheckConstraintDupName.GenByArgs(newCheck.Name.O)
+	}
+	if ckInfo == nil {
+		for _, col := range newCheck.Cols {
+			if findCol(tblInfo.Columns, col.L) == nil {
+				job.State = model.JobCancelled
+				return ver, errCheckConstraintUnknownColumn.GenByArgs(newCheck.Name.O, col.O)
+			}
+		}
+		ckInfo = &newCheck
+		ckInfo.ID = allocateIndexID(tblInfo)
+		ckInfo.State = model.StateNone
+		tblInfo.Checks = append(tblInfo.Checks, ckInfo)
+	}
+
+	// The constraint is enforced on the DML since write only state, then the existing rows are validated
+	// in reorganization state. So the rows written by the servers on the old schema are validated too.
+	originalState := ckInfo.State
+	switch ckInfo.State {
+	case model.StateNone:
+		// none -> write only
+		job.SchemaState = model.StateWriteOnly
+		ckInfo.State = model.StateWriteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		ckInfo.State = model.StateWriteReorganization
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		// reorganization -> public
+		var reorgInfo *reorgInfo
+		reorgInfo, err = d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return ver, errors.Trace(err)
+		}
+
+		var tbl table.Table
+		tbl, err = d.getTable(schemaID, tblInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		err = d.runReorgJob(job, func() error {
+			return d.validateCheckRows(tbl, ckInfo, reorgInfo, job)
+		})
+		if err != nil {
+			if errWaitReorgTimeout.Equal(err) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return ver, nil
+			}
+			if table.ErrCheckConstraintViolated.Equal(err) {
+				// The existing rows violate the constraint, remove it and cancel the job.
+				removeCheck(tblInfo, ckInfo.Name)
+				job.SchemaState = model.StateNone
+				job.State = model.JobCancelled
+				if _, err1 := updateTableInfo(t, job, tblInfo, originalState); err1 != nil {
+					return ver, errors.Trace(err1)
+				}
+			}
+			return ver, errors.Trace(err)
+		}
+
+		job.SchemaState = model.StatePublic
+		ckInfo.State = model.StatePublic
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	default:
+		err = ErrInvalidCheckState.Gen("invalid check constraint state %v", ckInfo.State)
+	}
+
+	return ver, errors.Trace(err)
+}
+
+// validateCheckRows checks whether the rows in the snapshot of the reorganization satisfy the check constraint.
+// The rows written after the snapshot are checked by the DML, since the constraint is enforced before it's taken.
+func (d *ddl) validateCheckRows(t table.Table, ckInfo *model.CheckInfo, reorgInfo *reorgInfo, job *model.Job) error {
+	ctx := d.newContext()
+	expr, err := BuildCheckExpr(ctx, t.Meta(), ckInfo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	cols := t.Cols()
+	colTps := make(map[int64]*types.FieldType, len(cols))
+	for _, col := range cols {
+		if !col.IsPKHandleColumn(t.Meta()) {
+			colTps[col.ID] = &col.FieldType
+		}
+	}
+	seekHandle := reorgInfo.Handle
+	count := job.GetRowCount()
+
+	for {
+		startTime := time.Now()
+		var lastHandle int64
+		batchCnt := 0
+		err = d.iterateSnapshotRows(t, reorgInfo.SnapshotVer, seekHandle,
+			func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+				row, err1 := decodeRowWithCols(ctx, t, h, rawRecord, cols, colTps)
+				if err1 != nil {
+					return false, errors.Trace(err1)
+				}
+				// Like the DML, the constraint is violated only if its expression is false.
+				v, err1 := expr.Eval(row)
+				if err1 != nil {
+					return false, errors.Trace(err1)
+				}
+				if !v.IsNull() {
+					b, err1 := v.ToBool(sc)
+					if err1 != nil {
+						return false, errors.Trace(err1)
+					}
+					if b == 0 {
+						return false, table.ErrCheckConstraintViolated.GenByArgs(ckInfo.Name.O)
+					}
+				}
+				lastHandle = h
+				batchCnt++
+				return batchCnt < defaultBatchCnt, nil
+			})
+		if err != nil {
+			return errors.Trace(err)
+		} else if batchCnt == 0 {
+			return nil
+		}
+
+		count += int64(batchCnt)
+		seekHandle = lastHandle + 1
+		err = kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+			return errors.Trace(reorgInfo.UpdateHandle(txn, seekHandle))
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		d.setReorgRowCount(count)
+		log.Infof("[ddl] validated check constraint for %v rows, take time %v", count, time.Since(startTime).Seconds())
+	}
+}
+
+// decodeRowWithCols decodes the raw record of the row to the datums of the columns.
+func decodeRowWithCols(ctx context.Context, t table.Table, h int64, rawRecord []byte, cols []*table.Column,
+	colTps map[int64]*types.FieldType) ([]types.Datum, error) {
+	rowMap, err := tablecodec.DecodeRow(rawRecord, colTps, ctx.GetSessionVars().GetTimeZone())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make([]types.Datum, len(cols))
+	defaultVals := make([]types.Datum, len(cols))
+	for i, col := range cols {
+		if col.IsPKHandleColumn(t.Meta()) {
+			if mysql.HasUnsignedFlag(col.Flag) {
+				row[i].SetUint64(uint64(h))
+			} else {
+				row[i].SetInt64(h)
+			}
+			continue
+		}
+		if v, ok := rowMap[col.ID]; ok {
+			row[i] = v
+			continue
+		}
+		row[i], err = tables.GetColDefaultValue(ctx, col, defaultVals)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return row, nil
+}
+
+// removeCheck removes the check constraint from the table.
+func removeCheck(tblInfo *model.TableInfo, name model.CIStr) {
+	nchecks := tblInfo.Checks[:0]
+	for _, ck := range tblInfo.Checks {
+		if ck.Name.L != name.L {
+			nchecks = append(nchecks, ck)
+		}
+	}
+	tblInfo.Checks = nchecks
+}
+
+func (d *ddl) onDropCheck(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	var name model.CIStr
+	err = job.DecodeArgs(&name)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	ckInfo := findCheck(tblInfo, name.L)
+	if ckInfo == nil {
+		job.State = model.JobCancelled
+		return ver, errConstraintNotFound.GenByArgs(name.O)
+	}
+
+	removeCheck(tblInfo, name)
+
+	// public -> none
+	originalState := ckInfo.State
+	job.SchemaState = model.StateNone
+	ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -146,6 +147,8 @@ func (d *ddl) onAddColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
 	case model.StateWriteReorganization:
 		// reorganization -> public
+		// The existing rows are not backfilled, a row without the column reads its OriginDefaultValue,
+		// so adding a column only changes the table info.
 		// Adjust column offset.
 		d.adjustColumnOffset(tblInfo.Columns, tblInfo.Indices, offset, true)
 		columnInfo.State = model.StatePublic
@@ -351,6 +354,8 @@ type columnMeta struct {
 	colID      int64
 	defaultVal types.Datum
 	oldColMap  map[int64]*types.FieldType
+	// newCol is set when the column type is modified.
+	newCol *model.ColumnInfo
 }
 
 func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, reorgInfo *reorgInfo) error {
@@ -367,7 +372,15 @@ func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *column
 				return errors.Trace(err)
 			}
 
-			nextHandle, err1 := d.backfillColumnInTxn(t, colMeta, handles[:endIdx], txn)
+			var (
+				nextHandle int64
+				err1       error
+			)
+			if colMeta.newCol != nil {
+				nextHandle, err1 = d.convertColumnInTxn(ctx, t, colMeta, handles[:endIdx], txn)
+			} else {
+				nextHandle, err1 = d.backfillColumnInTxn(t, colMeta, handles[:endIdx], txn)
+			}
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
@@ -404,16 +417,236 @@ func (d *ddl) onModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error)
 		return ver, errors.Trace(err)
 	}
 
-	return d.doModifyColumn(t, job, newCol, oldColName, pos)
-}
-
-// doModifyColumn updates the column information and reorders all columns.
-func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
 	tblInfo, err := getTableInfo(t, job, job.SchemaID)
 	if err != nil {
 		return ver, errors.Trace(err)
 	}
+	oldCol := findCol(tblInfo.Columns, oldColName.L)
+	if oldCol == nil || oldCol.State != model.StatePublic {
+		job.State = model.JobCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldColName, tblInfo.Name)
+	}
+	if modifiable(&oldCol.FieldType, &newCol.FieldType) == nil {
+		return d.doModifyColumn(t, job, tblInfo, newCol, oldColName, pos)
+	}
+
+	// The type change can't be done by only updating the metadata. A hidden changing column with the new type
+	// is added, the DML writes the value converted to the new type to it and fails if the value doesn't fit,
+	// the existing rows are converted in reorganization state, then the changing column replaces the old one.
+	changingCol := findChangingCol(tblInfo.Columns, oldCol.ID)
+	if changingCol == nil {
+		changingCol = newCol.Clone()
+		changingCol.ID = allocateColumnID(tblInfo)
+		changingCol.Name = model.NewCIStr(changingColumnPrefix + oldCol.Name.O)
+		changingCol.Offset = len(tblInfo.Columns)
+		// Every row stores the value of the changing column when it becomes public.
+		changingCol.OriginDefaultValue = nil
+		changingCol.ChangingFrom = oldCol.ID
+		changingCol.State = model.StateNone
+		tblInfo.Columns = append(tblInfo.Columns, changingCol)
+	}
+
+	originalState := changingCol.State
+	switch changingCol.State {
+	case model.StateNone:
+		// none -> write only
+		// The column keeps its original definition, the changing column is only written.
+		job.SchemaState = model.StateWriteOnly
+		changingCol.State = model.StateWriteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		changingCol.State = model.StateWriteReorganization
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		// reorganization -> public
+		var reorgInfo *reorgInfo
+		reorgInfo, err = d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return ver, errors.Trace(err)
+		}
+
+		var tbl table.Table
+		tbl, err = d.getTable(job.SchemaID, tblInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		err = d.runReorgJob(job, func() error {
+			return d.modifyTableColumnData(tbl, oldCol, changingCol, reorgInfo, job)
+		})
+		if err != nil {
+			if errWaitReorgTimeout.Equal(err) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return ver, nil
+			}
+			if isConvertError(err) {
+				// The existing data doesn't fit the new type, remove the changing column and cancel the job.
+				// The values written to the changing column are ignored like the values of a dropped column.
+				tblInfo.Columns = tblInfo.Columns[:len(tblInfo.Columns)-1]
+				job.SchemaState = model.StateNone
+				job.State = model.JobCancelled
+				if _, err1 := updateTableInfo(t, job, tblInfo, originalState); err1 != nil {
+					return ver, errors.Trace(err1)
+				}
+			}
+			return ver, errors.Trace(err)
+		}
+
+		// The changing column replaces the old column, the data of the old column is no longer read.
+		tblInfo.Columns = tblInfo.Columns[:len(tblInfo.Columns)-1]
+		newCol.ID = changingCol.ID
+		newCol.OriginDefaultValue = nil
+		return d.doModifyColumn(t, job, tblInfo, newCol, oldColName, pos)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+
+	return ver, errors.Trace(err)
+}
+
+// changingColumnPrefix is the name prefix of the hidden column used to change the type of a column.
+const changingColumnPrefix = "_Col$_"
+
+// findChangingCol finds the hidden column that the column whose ID is colID is being changed to.
+func findChangingCol(cols []*model.ColumnInfo, colID int64) *model.ColumnInfo {
+	for _, col := range cols {
+		if col.ChangingFrom == colID {
+			return col
+		}
+	}
+	return nil
+}
+
+// isConvertError checks if the error is returned by converting a value to the new column type.
+func isConvertError(err error) bool {
+	if tErr, ok := errors.Cause(err).(*terror.Error); ok {
+		return tErr.Class() == terror.ClassTypes || table.ErrTruncateWrongValue.Equal(tErr)
+	}
+	return false
+}
+
+// modifyTableColumnData fills the changing column with the converted data of the column whose type is changed.
+// How to fill the changing column in reorganization state?
+//  1. Generate a snapshot with special version.
+//  2. Traverse the snapshot, get every row in the table.
+//  3. For one row, if the row has been already deleted, skip to next row.
+//  4. Convert the column value with the new column type in strict mode,
+//     return the error if the value doesn't fit the new type.
+//  5. Write back the row with the converted value in the changing column.
+//
+// The rows written after the snapshot are converted by the DML, since the changing column
+// is writable before the snapshot is taken.
+func (d *ddl) modifyTableColumnData(t table.Table, oldCol, changingCol *model.ColumnInfo, reorgInfo *reorgInfo, job *model.Job) error {
+	seekHandle := reorgInfo.Handle
+	version := reorgInfo.SnapshotVer
+	count := job.GetRowCount()
+	ctx := d.newContext()
+
+	colMeta := &columnMeta{
+		colID:     oldCol.ID,
+		newCol:    changingCol,
+		oldColMap: make(map[int64]*types.FieldType)}
+	for _, col := range t.Meta().Columns {
+		colMeta.oldColMap[col.ID] = &col.FieldType
+	}
+	var err error
+	colMeta.defaultVal, err = table.GetColOriginDefaultValue(ctx, oldCol)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	handles := make([]int64, 0, defaultBatchCnt)
+
+	for {
+		startTime := time.Now()
+		handles = handles[:0]
+		err = d.iterateSnapshotRows(t, version, seekHandle,
+			func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+				handles = append(handles, h)
+				if len(handles) == defaultBatchCnt {
+					return false, nil
+				}
+				return true, nil
+			})
+		if err != nil {
+			return errors.Trace(err)
+		} else if len(handles) == 0 {
+			return nil
+		}
+
+		count += int64(len(handles))
+		seekHandle = handles[len(handles)-1] + 1
+		sub := time.Since(startTime).Seconds()
+		err = d.backfillColumn(ctx, t, colMeta, handles, reorgInfo)
+		if err != nil {
+			log.Warnf("[ddl] modified column for %v rows failed, take time %v", count, sub)
+			return errors.Trace(err)
+		}
+
+		d.setReorgRowCount(count)
+		batchHandleDataHistogram.WithLabelValues(batchModifyCol).Observe(sub)
+		log.Infof("[ddl] modified column for %v rows, take time %v", count, sub)
+	}
+}
+
+// convertColumnInTxn writes the column value converted to the new column type to the changing column in a Transaction.
+func (d *ddl) convertColumnInTxn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
+	nextHandle := handles[0]
+	for _, handle := range handles {
+		rowKey := t.RecordKey(handle)
+		rowVal, err := txn.Get(rowKey)
+		if err != nil {
+			if kv.ErrNotExist.Equal(err) {
+				// If row doesn't exist, skip it.
+				continue
+			}
+			return 0, errors.Trace(err)
+		}
+
+		rowColumns, err := tablecodec.DecodeRow(rowVal, colMeta.oldColMap, time.UTC)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		oldVal, ok := rowColumns[colMeta.colID]
+		if !ok {
+			// The column is not stored, its origin default value is converted.
+			oldVal = colMeta.defaultVal
+		}
+		newVal, err := table.CastValue(ctx, oldVal, colMeta.newCol)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+
+		if rowColumns == nil {
+			rowColumns = make(map[int64]types.Datum, 1)
+		}
+		rowColumns[colMeta.newCol.ID] = newVal
+		newColumnIDs := make([]int64, 0, len(rowColumns))
+		newRow := make([]types.Datum, 0, len(rowColumns))
+		for colID, val := range rowColumns {
+			newColumnIDs = append(newColumnIDs, colID)
+			newRow = append(newRow, val)
+		}
+		newRowVal, err := tablecodec.EncodeRow(newRow, newColumnIDs, time.UTC)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		err = txn.Set(rowKey, newRowVal)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+
+	return nextHandle, nil
+}
 
+// doModifyColumn updates the column information and reorders all columns.
+func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
 	oldCol := findCol(tblInfo.Columns, oldName.L)
 	if oldCol == nil || oldCol.State != model.StatePublic {
 		job.State = model.JobCancelled
@@ -480,7 +713,7 @@ func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo
 
 	originalState := job.SchemaState
 	job.SchemaState = model.StatePublic
-	ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	ver, err := updateTableInfo(t, job, tblInfo, originalState)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
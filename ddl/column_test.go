@@ -821,6 +821,68 @@ func (s *testColumnSuite) TestAddColumn(c *C) {
 	s.d.start(goctx.Background())
 }
 
+// TestAddColumnKeepRows checks adding a column only changes the table info, the existing rows aren't rewritten,
+// the value of the new column is filled by its origin default value when the rows are read.
+func (s *testColumnSuite) TestAddColumnKeepRows(c *C) {
+	defer testleak.AfterTest(c)()
+	tblInfo := testTableInfo(c, s.d, "t_keep_rows", 3)
+	ctx := testNewContext(s.d)
+
+	err := ctx.NewTxn()
+	c.Assert(err, IsNil)
+
+	testCreateTable(c, ctx, s.d, s.dbInfo, tblInfo)
+	t := testGetTable(c, s.d, s.dbInfo.ID, tblInfo.ID)
+
+	num := 10
+	handles := make([]int64, 0, num)
+	for i := 0; i < num; i++ {
+		h, err1 := t.AddRecord(ctx, types.MakeDatums(i, 10*i, 100*i))
+		c.Assert(err1, IsNil)
+		handles = append(handles, h)
+	}
+	err = ctx.Txn().Commit()
+	c.Assert(err, IsNil)
+
+	getRawRows := func() map[int64][]byte {
+		txn, err1 := s.store.Begin()
+		c.Assert(err1, IsNil)
+		defer txn.Rollback()
+		rows := make(map[int64][]byte, len(handles))
+		for _, h := range handles {
+			val, err1 := txn.Get(t.RecordKey(h))
+			c.Assert(err1, IsNil)
+			rows[h] = val
+		}
+		return rows
+	}
+	oldRows := getRawRows()
+
+	// A nullable column without default value and a column with default value.
+	job := testCreateColumn(c, ctx, s.d, s.dbInfo, tblInfo, "c4", &ast.ColumnPosition{Tp: ast.ColumnPositionNone}, nil)
+	testCheckJobDone(c, s.d, job, true)
+	job = testCreateColumn(c, ctx, s.d, s.dbInfo, tblInfo, "c5", &ast.ColumnPosition{Tp: ast.ColumnPositionNone}, 5)
+	testCheckJobDone(c, s.d, job, true)
+	c.Assert(getRawRows(), DeepEquals, oldRows)
+
+	t = testGetTable(c, s.d, s.dbInfo.ID, tblInfo.ID)
+	err = ctx.NewTxn()
+	c.Assert(err, IsNil)
+	for i, h := range handles {
+		values, err1 := t.RowWithCols(ctx, h, t.Cols())
+		c.Assert(err1, IsNil)
+		c.Assert(values, HasLen, 5)
+		c.Assert(values[2].GetInt64(), Equals, int64(100*i))
+		c.Assert(values[3].IsNull(), IsTrue)
+		c.Assert(values[4].GetInt64(), Equals, int64(5))
+	}
+	err = ctx.Txn().Commit()
+	c.Assert(err, IsNil)
+
+	job = testDropTable(c, ctx, s.d, s.dbInfo, tblInfo)
+	testCheckJobDone(c, s.d, job, false)
+}
+
 func (s *testColumnSuite) TestDropColumn(c *C) {
 	defer testleak.AfterTest(c)()
 	d := testNewDDL(goctx.Background(), nil, s.store, nil, nil, testLease)
@@ -922,6 +984,31 @@ func (s *testColumnSuite) TestModifyColumn(c *C) {
 			c.Assert(err.Error(), Equals, tt.err.Error())
 		}
 	}
+
+	reorgTests := []struct {
+		origin string
+		to     string
+		err    error
+	}{
+		{"int", "int unsigned", nil},
+		{"bigint", "int", nil},
+		{"varchar(10)", "varchar(8)", nil},
+		{"int", "varchar(10)", nil},
+		{"varchar(10)", "decimal(10,2)", nil},
+		{"text", "blob", errUnsupportedModifyColumn.GenByArgs("charset binary not match origin utf8")},
+		{"int", "datetime", errUnsupportedModifyColumn.GenByArgs("type 12 not match origin 3")},
+	}
+	for _, tt := range reorgTests {
+		ftA := s.colDefStrToFieldType(c, tt.origin)
+		ftB := s.colDefStrToFieldType(c, tt.to)
+		err := modifiableWithReorg(ftA, ftB)
+		if err == nil {
+			c.Assert(tt.err, IsNil)
+		} else {
+			c.Assert(tt.err, NotNil, Commentf("%s -> %s: %v", tt.origin, tt.to, err))
+			c.Assert(err.Error(), Equals, tt.err.Error())
+		}
+	}
 }
 
 func (s *testColumnSuite) colDefStrToFieldType(c *C, str string) *types.FieldType {
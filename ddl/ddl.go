@@ -64,7 +64,8 @@ var (
 	errUnsupportedModifyColumn = terror.ClassDDL.New(codeUnsupportedModifyColumn, "unsupported modify column %s")
 	errUnsupportedPKHandle     = terror.ClassDDL.New(codeUnsupportedDropPKHandle,
 		"unsupported drop integer primary key")
-	errUnsupportedCharset = terror.ClassDDL.New(codeUnsupportedCharset, "unsupported charset %s collate %s")
+	errUnsupportedCharset    = terror.ClassDDL.New(codeUnsupportedCharset, "unsupported charset %s collate %s")
+	errUnsupportedShardRowID = terror.ClassDDL.New(codeUnsupportedShardRowID, "unsupported shard_row_id_bits for table with %s")
 
 	errBlobKeyWithoutLength = terror.ClassDDL.New(codeBlobKeyWithoutLength, "index for BLOB/TEXT column must specificate a key length")
 	errIncorrectPrefixKey   = terror.ClassDDL.New(codeIncorrectPrefixKey, "Incorrect prefix key; the used key part isn't a string, the used length is longer than the key part, or the storage engine doesn't support unique prefix keys")
@@ -93,6 +94,20 @@ var (
 	errJSONUsedAsKey = terror.ClassDDL.New(codeJSONUsedAsKey, mysql.MySQLErrName[mysql.ErrJSONUsedAsKey])
 	// errBlobCantHaveDefault forbiddens to give not null default value to TEXT/BLOB/JSON.
 	errBlobCantHaveDefault = terror.ClassDDL.New(codeBlobCantHaveDefault, mysql.MySQLErrName[mysql.ErrBlobCantHaveDefault])
+	// errCheckConstraintNamedFunction forbiddens to use non-deterministic functions in check constraints.
+	errCheckConstraintNamedFunction = terror.ClassDDL.New(codeCheckConstraintNamedFunction, mysql.MySQLErrName[mysql.ErrCheckConstraintNamedFunctionIsNotAllowed])
+	// errCheckConstraintFunction forbiddens to use subqueries and aggregate functions in check constraints.
+	errCheckConstraintFunction = terror.ClassDDL.New(codeCheckConstraintFunction, mysql.MySQLErrName[mysql.ErrCheckConstraintFunctionIsNotAllowed])
+	// errCheckConstraintVariables forbiddens to use variables in check constraints.
+	errCheckConstraintVariables = terror.ClassDDL.New(codeCheckConstraintVariables, mysql.MySQLErrName[mysql.ErrCheckConstraintVariables])
+	// errCheckConstraintUnknownColumn is for check constraints referring to columns which don't exist.
+	errCheckConstraintUnknownColumn = terror.ClassDDL.New(codeCheckConstraintUnknownColumn, mysql.MySQLErrName[mysql.ErrCheckConstraintRefersUnknownColumn])
+	// errCheckConstraintDupName is for duplicate check constraint names in a table.
+	errCheckConstraintDupName = terror.ClassDDL.New(codeCheckConstraintDupName, mysql.MySQLErrName[mysql.ErrCheckConstraintDupName])
+	// errConstraintNotFound is for dropping check constraints which don't exist.
+	errConstraintNotFound = terror.ClassDDL.New(codeConstraintNotFound, mysql.MySQLErrName[mysql.ErrConstraintNotFound])
+	// errDependentByCheckConstraint forbiddens to drop or rename columns which are used by check constraints.
+	errDependentByCheckConstraint = terror.ClassDDL.New(codeDependentByCheckConstraint, mysql.MySQLErrName[mysql.ErrDependentByCheckConstraint])
 
 	// ErrInvalidDBState returns for invalid database state.
 	ErrInvalidDBState = terror.ClassDDL.New(codeInvalidDBState, "invalid database state")
@@ -104,6 +119,8 @@ var (
 	ErrInvalidIndexState = terror.ClassDDL.New(codeInvalidIndexState, "invalid index state")
 	// ErrInvalidForeignKeyState returns for invalid foreign key state.
 	ErrInvalidForeignKeyState = terror.ClassDDL.New(codeInvalidForeignKeyState, "invalid foreign key state")
+	// ErrInvalidCheckState returns for invalid check constraint state.
+	ErrInvalidCheckState = terror.ClassDDL.New(codeInvalidCheckState, "invalid check constraint state")
 	// ErrUnsupportedModifyPrimaryKey returns an error when add or drop the primary key.
 	// It's exported for testing.
 	ErrUnsupportedModifyPrimaryKey = terror.ClassDDL.New(codeUnsupportedModifyPrimaryKey, "unsupported %s primary key")
@@ -143,6 +160,7 @@ type DDL interface {
 	AlterTable(ctx context.Context, tableIdent ast.Ident, spec []*ast.AlterTableSpec) error
 	TruncateTable(ctx context.Context, tableIdent ast.Ident) error
 	RenameTable(ctx context.Context, oldTableIdent, newTableIdent ast.Ident) error
+	RenameTables(ctx context.Context, oldTableIdents, newTableIdents []ast.Ident) error
 	// SetLease will reset the lease time for online DDL change,
 	// it's a very dangerous function and you must guarantee that all servers have the same lease time.
 	SetLease(ctx goctx.Context, lease time.Duration)
@@ -218,6 +236,8 @@ type ddl struct {
 	wait   sync.WaitGroup
 
 	workerVars *variable.SessionVars
+	// ctxPool is used to read the global variables, it may be nil in tests.
+	ctxPool *pools.ResourcePool
 
 	delRangeManager delRangeManager
 }
@@ -287,6 +307,7 @@ func newDDL(ctx goctx.Context, etcdCli *clientv3.Client, store kv.Storage,
 		ownerManager: manager,
 		schemaSyncer: syncer,
 		workerVars:   variable.NewSessionVars(),
+		ctxPool:      ctxPool,
 	}
 	d.workerVars.BinlogClient = binloginfo.GetPumpClient()
 
@@ -318,8 +339,9 @@ func (d *ddl) start(ctx goctx.Context) {
 	d.quitCh = make(chan struct{})
 	d.ownerManager.CampaignOwner(ctx)
 
-	d.wait.Add(1)
+	d.wait.Add(2)
 	go d.onDDLWorker()
+	go d.onPruneHistoryJobs()
 
 	// For every start, we will send a fake job to let worker
 	// check owner firstly and try to find whether a job exists and run.
@@ -518,6 +540,7 @@ const (
 	codeInvalidColumnState     = 102
 	codeInvalidIndexState      = 103
 	codeInvalidForeignKeyState = 104
+	codeInvalidCheckState      = 105
 
 	codeCantDropColWithIndex        = 201
 	codeUnsupportedAddColumn        = 202
@@ -525,6 +548,7 @@ const (
 	codeUnsupportedDropPKHandle     = 204
 	codeUnsupportedCharset          = 205
 	codeUnsupportedModifyPrimaryKey = 206
+	codeUnsupportedShardRowID       = 207
 
 	codeFileNotFound                 = 1017
 	codeErrorOnRename                = 1025
@@ -550,6 +574,13 @@ const (
 	codeGeneratedColumnNonPrior      = 3107
 	codeDependentByGeneratedColumn   = 3108
 	codeJSONUsedAsKey                = 3152
+	codeCheckConstraintNamedFunction = 3814
+	codeCheckConstraintFunction      = 3815
+	codeCheckConstraintVariables     = 3816
+	codeCheckConstraintUnknownColumn = 3820
+	codeCheckConstraintDupName       = 3822
+	codeConstraintNotFound           = 3940
+	codeDependentByCheckConstraint   = 3959
 	codeWrongNameForIndex            = terror.ErrCode(mysql.ErrWrongNameForIndex)
 )
 
@@ -576,6 +607,13 @@ func init() {
 		codeGeneratedColumnNonPrior:      mysql.ErrGeneratedColumnNonPrior,
 		codeDependentByGeneratedColumn:   mysql.ErrDependentByGeneratedColumn,
 		codeJSONUsedAsKey:                mysql.ErrJSONUsedAsKey,
+		codeCheckConstraintNamedFunction: mysql.ErrCheckConstraintNamedFunctionIsNotAllowed,
+		codeCheckConstraintFunction:      mysql.ErrCheckConstraintFunctionIsNotAllowed,
+		codeCheckConstraintVariables:     mysql.ErrCheckConstraintVariables,
+		codeCheckConstraintUnknownColumn: mysql.ErrCheckConstraintRefersUnknownColumn,
+		codeCheckConstraintDupName:       mysql.ErrCheckConstraintDupName,
+		codeConstraintNotFound:           mysql.ErrConstraintNotFound,
+		codeDependentByCheckConstraint:   mysql.ErrDependentByCheckConstraint,
 		codeBlobCantHaveDefault:          mysql.ErrBlobCantHaveDefault,
 		codeWrongColumnName:              mysql.ErrWrongColumnName,
 		codeWrongKeyColumn:               mysql.ErrWrongKeyColumn,
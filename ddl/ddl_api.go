@@ -24,14 +24,17 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/types"
@@ -313,12 +316,20 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 				col.GeneratedStored = v.Stored
 				_, dependColNames := findDependedColumnNames(colDef)
 				col.Dependences = dependColNames
+			case ast.ColumnOptionCheck:
+				constraint := &ast.Constraint{Tp: ast.ConstraintCheck, Expr: v.Expr}
+				constraints = append(constraints, constraint)
 			case ast.ColumnOptionFulltext:
 				// TODO: Support this type.
 			}
 		}
 	}
 
+	// An explicit ON UPDATE clause takes effect regardless of the position of
+	// NULL or DEFAULT options, which only remove the implicit one.
+	if setOnUpdateNow {
+		col.Flag |= mysql.OnUpdateNowFlag
+	}
 	setTimestampDefaultValue(col, hasDefaultValue, setOnUpdateNow)
 
 	// Set `NoDefaultValueFlag` if this field doesn't have a default value and
@@ -521,6 +532,9 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 
 	// Check not empty constraint name whether is duplicated.
 	for _, constr := range constraints {
+		if constr.Tp == ast.ConstraintCheck {
+			continue
+		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			err := checkDuplicateConstraint(fkNames, constr.Name, true)
 			if err != nil {
@@ -536,6 +550,10 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 
 	// Set empty constraint names.
 	for _, constr := range constraints {
+		if constr.Tp == ast.ConstraintCheck {
+			// The names of check constraints are checked and generated when they are built.
+			continue
+		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			setEmptyConstraintName(fkNames, constr, true)
 		} else {
@@ -559,6 +577,16 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 		tbInfo.Columns = append(tbInfo.Columns, v.ToInfo())
 	}
 	for _, constr := range constraints {
+		if constr.Tp == ast.ConstraintCheck {
+			ckInfo, err := buildCheckInfo(tbInfo, constr)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			ckInfo.ID = allocateIndexID(tbInfo)
+			ckInfo.State = model.StatePublic
+			tbInfo.Checks = append(tbInfo.Checks, ckInfo)
+			continue
+		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			for _, fk := range tbInfo.ForeignKeys {
 				if fk.Name.L == strings.ToLower(constr.Name) {
@@ -732,6 +760,12 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	}
 
 	handleTableOptions(options, tbInfo)
+	if err = checkShardRowIDBits(tbInfo); err != nil {
+		return errors.Trace(err)
+	}
+	if err = d.checkPreSplitRegions(tbInfo); err != nil {
+		return errors.Trace(err)
+	}
 	err = d.doDDLJob(ctx, job)
 	if err == nil {
 		if tbInfo.AutoIncID > 1 {
@@ -739,6 +773,9 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 			// If the first id is expected to greater than 1, we need to do rebase.
 			d.handleAutoIncID(tbInfo, schema.ID)
 		}
+		if tbInfo.PreSplitRegions > 0 {
+			d.preSplitTableRegions(tbInfo)
+		}
 	}
 	err = d.callHookOnChanged(err)
 	return errors.Trace(err)
@@ -778,6 +815,60 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
 			tbInfo.Charset = op.StrValue
 		case ast.TableOptionCollate:
 			tbInfo.Collate = op.StrValue
+		case ast.TableOptionShardRowID:
+			tbInfo.ShardRowIDBits = op.UintValue
+		case ast.TableOptionPreSplitRegion:
+			tbInfo.PreSplitRegions = op.UintValue
+		}
+	}
+}
+
+// MaxShardRowIDBits is the maximum value of the shard_row_id_bits table option.
+const MaxShardRowIDBits = 15
+
+// checkShardRowIDBits checks the shard_row_id_bits and pre_split_regions table options.
+func checkShardRowIDBits(tbInfo *model.TableInfo) error {
+	if tbInfo.ShardRowIDBits == 0 {
+		if tbInfo.PreSplitRegions > 0 {
+			return errUnsupportedShardRowID.GenByArgs("pre_split_regions but without shard_row_id_bits")
+		}
+		return nil
+	}
+	if tbInfo.PKIsHandle {
+		return errUnsupportedShardRowID.GenByArgs("integer primary key as handle")
+	}
+	if tbInfo.ShardRowIDBits > MaxShardRowIDBits {
+		return errUnsupportedShardRowID.GenByArgs(fmt.Sprintf("more than %d bits", MaxShardRowIDBits))
+	}
+	if tbInfo.PreSplitRegions > tbInfo.ShardRowIDBits {
+		return errUnsupportedShardRowID.GenByArgs("pre_split_regions greater than shard_row_id_bits")
+	}
+	return nil
+}
+
+// checkPreSplitRegions checks the store can split the regions of a table with the pre_split_regions table option,
+// so the option is never accepted but silently ignored.
+func (d *ddl) checkPreSplitRegions(tbInfo *model.TableInfo) error {
+	if tbInfo.PreSplitRegions == 0 {
+		return nil
+	}
+	if store, ok := d.store.(kv.SplitableStore); !ok || !store.SupportSplitRegion() {
+		return errUnsupportedShardRowID.GenByArgs("pre_split_regions on a store which can't split regions")
+	}
+	return nil
+}
+
+// preSplitTableRegions splits the record range of the table into 2^PreSplitRegions regions,
+// one region for each value of the highest PreSplitRegions shard bits.
+// A failed split only leaves the table with fewer regions, so it's logged rather than returned.
+func (d *ddl) preSplitTableRegions(tbInfo *model.TableInfo) {
+	store := d.store.(kv.SplitableStore)
+	step := int64(1) << (64 - tbInfo.PreSplitRegions - 1)
+	for i := int64(1); i < int64(1)<<tbInfo.PreSplitRegions; i++ {
+		key := tablecodec.EncodeRowKeyWithHandle(tbInfo.ID, i*step)
+		if err := store.SplitRegion(key); err != nil {
+			log.Warnf("[ddl] pre split table %s region at %v failed %v", tbInfo.Name, key, errors.ErrorStack(err))
+			return
 		}
 	}
 }
@@ -817,11 +908,15 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 				err = d.CreateForeignKey(ctx, ident, model.NewCIStr(constr.Name), spec.Constraint.Keys, spec.Constraint.Refer)
 			case ast.ConstraintPrimaryKey:
 				err = ErrUnsupportedModifyPrimaryKey.GenByArgs("add")
+			case ast.ConstraintCheck:
+				err = d.AddCheck(ctx, ident, constr)
 			default:
 				// Nothing to do now.
 			}
 		case ast.AlterTableDropForeignKey:
 			err = d.DropForeignKey(ctx, ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableDropCheck:
+			err = d.DropCheck(ctx, ident, model.NewCIStr(spec.Name))
 		case ast.AlterTableModifyColumn:
 			err = d.ModifyColumn(ctx, ident, spec)
 		case ast.AlterTableChangeColumn:
@@ -848,7 +943,7 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 func checkColumnConstraint(constraints []*ast.ColumnOption) error {
 	for _, constraint := range constraints {
 		switch constraint.Tp {
-		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey:
+		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey, ast.ColumnOptionCheck:
 			return errUnsupportedAddColumn.Gen("unsupported add column constraint - %v", constraint.Tp)
 		}
 	}
@@ -958,6 +1053,9 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 	if err = isDroppableColumn(tblInfo, colName); err != nil {
 		return errors.Trace(err)
 	}
+	if err = checkColumnWithCheck(tblInfo, col.Name); err != nil {
+		return errors.Trace(err)
+	}
 	// We don't support dropping column with PK handle covered now.
 	if col.IsPKHandleColumn(tblInfo) {
 		return errUnsupportedPKHandle
@@ -1027,6 +1125,53 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 	return errUnsupportedModifyColumn.GenByArgs(msg)
 }
 
+// modifiableWithReorg checks if the 'origin' type can be modified to 'to' type by rewriting the existing
+// data in the table. It is used when 'modifiable' returns an error, the column data is converted to the
+// new type in the reorganization state and the job fails if some value doesn't fit the new type.
+// Only conversions among integer, decimal and string types are supported, and a string type can only be
+// changed to another string type with the same charset and collation.
+func modifiableWithReorg(origin *types.FieldType, to *types.FieldType) error {
+	// The numeric types always use the binary charset, only check the charset between string types.
+	if origin.ToClass() == types.ClassString && to.ToClass() == types.ClassString {
+		if to.Charset != origin.Charset {
+			msg := fmt.Sprintf("charset %s not match origin %s", to.Charset, origin.Charset)
+			return errUnsupportedModifyColumn.GenByArgs(msg)
+		}
+		if to.Collate != origin.Collate {
+			msg := fmt.Sprintf("collate %s not match origin %s", to.Collate, origin.Collate)
+			return errUnsupportedModifyColumn.GenByArgs(msg)
+		}
+	}
+	if origin.Tp == mysql.TypeEnum || to.Tp == mysql.TypeEnum {
+		return errUnsupportedModifyColumn.GenByArgs("modify enum column is not supported")
+	}
+	if !isReorgModifiableType(origin.Tp) || !isReorgModifiableType(to.Tp) {
+		msg := fmt.Sprintf("type %v not match origin %v", to.Tp, origin.Tp)
+		return errUnsupportedModifyColumn.GenByArgs(msg)
+	}
+	return nil
+}
+
+func isReorgModifiableType(tp byte) bool {
+	switch tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong,
+		mysql.TypeNewDecimal, mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString,
+		mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		return true
+	}
+	return false
+}
+
+// isValuePreservingChange returns true if every value that fits the 'to' type keeps the same
+// encoding as it is in the 'origin' type, so the indices on the column need not be rebuilt.
+func isValuePreservingChange(origin *types.FieldType, to *types.FieldType) bool {
+	if mysql.HasUnsignedFlag(origin.Flag) != mysql.HasUnsignedFlag(to.Flag) {
+		return false
+	}
+	originClass, toClass := origin.ToClass(), to.ToClass()
+	return originClass == toClass && (originClass == types.ClassInt || originClass == types.ClassString)
+}
+
 func setDefaultValue(ctx context.Context, col *table.Column, option *ast.ColumnOption) error {
 	value, err := getDefaultValue(ctx, option, col.Tp, col.Decimal)
 	if err != nil {
@@ -1146,9 +1291,17 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	err = modifiable(&col.FieldType, &newCol.FieldType)
-	if err != nil {
-		return nil, errors.Trace(err)
+	if err = modifiable(&col.FieldType, &newCol.FieldType); err != nil {
+		// The type change needs to rewrite the column data in the reorganization state.
+		if err = modifiableWithReorg(&col.FieldType, &newCol.FieldType); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if t.Meta().PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
+			return nil, errUnsupportedModifyColumn.GenByArgs("type of the primary key handle column")
+		}
+		if !isValuePreservingChange(&col.FieldType, &newCol.FieldType) && isColumnWithIndex(col.Name.L, t.Meta().Indices) {
+			return nil, errUnsupportedModifyColumn.GenByArgs("type of an indexed column that needs to rebuild the index")
+		}
 	}
 	if err = setDefaultAndComment(ctx, newCol, spec.NewColumn.Options); err != nil {
 		return nil, errors.Trace(err)
@@ -1175,6 +1328,12 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	if err = checkModifyGeneratedColumn(t.Cols(), col, newCol); err != nil {
 		return nil, errors.Trace(err)
 	}
+	// The check constraints refer to the columns by name.
+	if col.Name.L != newCol.Name.L {
+		if err = checkColumnWithCheck(t.Meta(), col.Name); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -1186,9 +1345,8 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	return job, nil
 }
 
-// ChangeColumn renames an existing column and modifies the column's definition,
-// currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// ChangeColumn renames an existing column and modifies the column's definition.
+// If the type change is incompatible with the existing data, the data is rewritten in reorganization state.
 func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return ErrWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1213,8 +1371,8 @@ func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.Alter
 	return errors.Trace(err)
 }
 
-// ModifyColumn does modification on an existing column, currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// ModifyColumn does modification on an existing column. Compatible type changes only update the metadata,
+// other supported type changes rewrite and validate the column data in reorganization state.
 func (d *ddl) ModifyColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return ErrWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1359,6 +1517,77 @@ func (d *ddl) RenameTable(ctx context.Context, oldIdent, newIdent ast.Ident) err
 	return errors.Trace(err)
 }
 
+// RenameTables renames a list of tables in one DDL job, so the renames take effect atomically.
+// The renames are applied in order, e.g. "RENAME TABLE a TO b, b TO c" renames a to c,
+// and "RENAME TABLE a TO tmp, b TO a, tmp TO b" swaps a and b.
+func (d *ddl) RenameTables(ctx context.Context, oldIdents, newIdents []ast.Ident) error {
+	is := d.GetInformationSchema()
+	// renamed records the table IDs of the names changed by the former renames, 0 means the name is free.
+	renamed := make(map[ast.Ident]int64)
+	getTableID := func(ident ast.Ident) (int64, bool) {
+		key := ast.Ident{Schema: model.NewCIStr(ident.Schema.L), Name: model.NewCIStr(ident.Name.L)}
+		if id, ok := renamed[key]; ok {
+			return id, id != 0
+		}
+		tbl, err := is.TableByName(ident.Schema, ident.Name)
+		if err != nil {
+			return 0, false
+		}
+		return tbl.Meta().ID, true
+	}
+	setTableID := func(ident ast.Ident, id int64) {
+		renamed[ast.Ident{Schema: model.NewCIStr(ident.Schema.L), Name: model.NewCIStr(ident.Name.L)}] = id
+	}
+
+	// A table renamed several times is moved to its last name directly.
+	var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+	var tableNames []model.CIStr
+	offsets := make(map[int64]int, len(oldIdents))
+	for i, oldIdent := range oldIdents {
+		newIdent := newIdents[i]
+		oldSchema, ok := is.SchemaByName(oldIdent.Schema)
+		if !ok {
+			return errFileNotFound.GenByArgs(oldIdent.Schema, oldIdent.Name)
+		}
+		tableID, ok := getTableID(oldIdent)
+		if !ok {
+			return errFileNotFound.GenByArgs(oldIdent.Schema, oldIdent.Name)
+		}
+		newSchema, ok := is.SchemaByName(newIdent.Schema)
+		if !ok {
+			return errErrorOnRename.GenByArgs(oldIdent.Schema, oldIdent.Name, newIdent.Schema, newIdent.Name)
+		}
+		if _, ok = getTableID(newIdent); ok {
+			return infoschema.ErrTableExists.GenByArgs(newIdent)
+		}
+		setTableID(oldIdent, 0)
+		setTableID(newIdent, tableID)
+
+		offset, ok := offsets[tableID]
+		if !ok {
+			offset = len(tableIDs)
+			offsets[tableID] = offset
+			tableIDs = append(tableIDs, tableID)
+			oldSchemaIDs = append(oldSchemaIDs, oldSchema.ID)
+			newSchemaIDs = append(newSchemaIDs, 0)
+			tableNames = append(tableNames, model.CIStr{})
+		}
+		newSchemaIDs[offset], tableNames[offset] = newSchema.ID, newIdent.Name
+	}
+
+	job := &model.Job{
+		SchemaID:   newSchemaIDs[0],
+		TableID:    tableIDs[0],
+		Type:       model.ActionRenameTables,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{oldSchemaIDs, newSchemaIDs, tableNames, tableIDs},
+	}
+
+	err := d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func getAnonymousIndex(t table.Table, colName model.CIStr) model.CIStr {
 	id := 2
 	l := len(t.Indices())
@@ -182,6 +182,112 @@ func (s *testStateChangeSuite) test(c *C, tableName, alterTableSQL string, testI
 	d.SetHook(callback)
 }
 
+func (s *testStateChangeSuite) TestAddCheckWriteOnly(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := s.se.Execute("create table tc (a int, b int)")
+	c.Assert(err, IsNil)
+	defer s.se.Execute("drop table tc")
+	_, err = s.se.Execute("insert into tc values (1, 1)")
+	c.Assert(err, IsNil)
+	se, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	_, err = se.Execute("use test_db_state")
+	c.Assert(err, IsNil)
+
+	callback := &ddl.TestDDLCallback{}
+	var checkErr error
+	executed := false
+	callback.OnJobUpdatedExported = func(job *model.Job) {
+		if job.SchemaState != model.StateWriteOnly || executed {
+			return
+		}
+		executed = true
+		if checkErr = s.dom.Reload(); checkErr != nil {
+			return
+		}
+		// The constraint is enforced before the existing rows are validated.
+		if _, err1 := se.Execute("insert into tc values (-1, 2)"); err1 == nil {
+			checkErr = errors.New("the row violating the constraint is inserted")
+			return
+		}
+		if _, err1 := se.Execute("update tc set a = -1 where b = 1"); err1 == nil {
+			checkErr = errors.New("the row violating the constraint is updated")
+			return
+		}
+		if _, err1 := se.Execute("insert into tc values (3, 3)"); err1 != nil {
+			checkErr = errors.Trace(err1)
+		}
+	}
+	d := s.dom.DDL()
+	d.SetHook(callback)
+	_, err = s.se.Execute("alter table tc add constraint chk_a check (a > 0)")
+	c.Assert(err, IsNil)
+	c.Assert(errors.ErrorStack(checkErr), Equals, "")
+	c.Assert(executed, IsTrue)
+	d.SetHook(&ddl.TestDDLCallback{})
+
+	rs, err := s.se.Execute("select count(*) from tc")
+	c.Assert(err, IsNil)
+	rows, err := tidb.GetRows(rs[0])
+	c.Assert(err, IsNil)
+	c.Assert(rows[0][0].GetInt64(), Equals, int64(2))
+}
+
+func (s *testStateChangeSuite) TestModifyColumnWriteOnly(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := s.se.Execute("create table tm (a bigint, b int)")
+	c.Assert(err, IsNil)
+	defer s.se.Execute("drop table tm")
+	_, err = s.se.Execute("insert into tm values (1, 1)")
+	c.Assert(err, IsNil)
+	se, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	_, err = se.Execute("use test_db_state")
+	c.Assert(err, IsNil)
+
+	callback := &ddl.TestDDLCallback{}
+	var checkErr error
+	executed := false
+	callback.OnJobUpdatedExported = func(job *model.Job) {
+		if job.SchemaState != model.StateWriteOnly || executed {
+			return
+		}
+		executed = true
+		if checkErr = s.dom.Reload(); checkErr != nil {
+			return
+		}
+		// The values written before the data is converted must fit the new type too.
+		if _, err1 := se.Execute("insert into tm values (4294967296, 2)"); err1 == nil {
+			checkErr = errors.New("the value out of the new type range is inserted")
+			return
+		}
+		if _, err1 := se.Execute("update tm set a = 4294967296 where b = 1"); err1 == nil {
+			checkErr = errors.New("the value out of the new type range is updated")
+			return
+		}
+		if _, err1 := se.Execute("insert into tm values (3, 3)"); err1 != nil {
+			checkErr = errors.Trace(err1)
+		}
+	}
+	d := s.dom.DDL()
+	d.SetHook(callback)
+	_, err = s.se.Execute("alter table tm modify a int")
+	c.Assert(err, IsNil)
+	c.Assert(errors.ErrorStack(checkErr), Equals, "")
+	c.Assert(executed, IsTrue)
+	d.SetHook(&ddl.TestDDLCallback{})
+
+	rs, err := s.se.Execute("select a from tm order by b")
+	c.Assert(err, IsNil)
+	rows, err := tidb.GetRows(rs[0])
+	c.Assert(err, IsNil)
+	c.Assert(rows, HasLen, 2)
+	c.Assert(rows[0][0].GetInt64(), Equals, int64(1))
+	c.Assert(rows[1][0].GetInt64(), Equals, int64(3))
+}
+
 type stateCase struct {
 	session     tidb.Session
 	rawStmt     ast.StmtNode
@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	tmysql "github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
@@ -193,6 +194,39 @@ func (s *testDBSuite) TestAddIndexWithPK(c *C) {
 	s.tk.MustQuery("select * from test_add_index_with_pk2").Check(testkit.Rows("1 1 1 1", "2 2 2 2"))
 }
 
+func (s *testDBSuite) TestAddIndexWithReorgVars(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	defer func() {
+		s.tk.MustExec("set @@global.tidb_ddl_reorg_worker_cnt = 16")
+		s.tk.MustExec("set @@global.tidb_ddl_reorg_batch_size = 128")
+		s.tk.MustExec("set @@global.tidb_ddl_reorg_priority = 'PRIORITY_NORMAL'")
+	}()
+
+	_, err := s.tk.Exec("set @@global.tidb_ddl_reorg_worker_cnt = 0")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = s.tk.Exec("set @@global.tidb_ddl_reorg_batch_size = 10241")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = s.tk.Exec("set @@global.tidb_ddl_reorg_priority = 'low'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = s.tk.Exec("set @@session.tidb_ddl_reorg_worker_cnt = 1")
+	c.Assert(err, NotNil)
+
+	s.tk.MustExec("set @@global.tidb_ddl_reorg_worker_cnt = 2")
+	s.tk.MustExec("set @@global.tidb_ddl_reorg_batch_size = 32")
+	s.tk.MustExec("set @@global.tidb_ddl_reorg_priority = 'priority_low'")
+	s.tk.MustQuery("select @@global.tidb_ddl_reorg_priority").Check(testkit.Rows("PRIORITY_LOW"))
+
+	s.tk.MustExec("create table test_reorg_vars(a int, b int)")
+	for i := 0; i < 200; i++ {
+		s.tk.MustExec("insert into test_reorg_vars values(?, ?)", i, i)
+	}
+	s.tk.MustExec("alter table test_reorg_vars add index idx(b)")
+	s.tk.MustExec("admin check table test_reorg_vars")
+	s.tk.MustQuery("select count(*) from test_reorg_vars use index(idx) where b >= 100").Check(testkit.Rows("100"))
+}
+
 func (s *testDBSuite) TestIndex(c *C) {
 	defer testleak.AfterTest(c)()
 	s.tk = testkit.NewTestKit(c, s.store)
@@ -494,6 +528,10 @@ LOOP:
 			}
 			c.Assert(err, IsNil, Commentf("err:%v", errors.ErrorStack(err)))
 		case <-ticker.C:
+			// Queries on the indexed column must return correct results in every state of the index.
+			n := rand.Intn(num)
+			matchRows(c, s.mustQuery(c, "select c1 from t1 where c3 = ?", n), [][]interface{}{{n}})
+			matchRows(c, s.mustQuery(c, "select count(*) from t1 where c3 >= 0"), [][]interface{}{{num}})
 			step := 10
 			// delete some rows, and add some data
 			for i := num; i < num+step; i++ {
@@ -886,6 +924,38 @@ func (s *testDBSuite) TestChangeColumn(c *C) {
 	s.testErrorCode(c, sql, tmysql.ErrUnknown)
 }
 
+func (s *testDBSuite) TestModifyColumnWithReorg(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+
+	s.mustExec(c, "create table t_reorg (id int primary key, a bigint, b varchar(100), c int, index idx_b(b))")
+	s.mustExec(c, "insert into t_reorg values (1, 1, 'a', 10), (2, -2, 'bb', 20), (3, null, null, null)")
+	// Narrow the integer column, all the values fit the new type.
+	s.mustExec(c, "alter table t_reorg modify a int")
+	s.tk.MustQuery("select a from t_reorg").Check(testkit.Rows("1", "-2", "<nil>"))
+	// Narrow the indexed string column, the index doesn't need to be rebuilt.
+	s.mustExec(c, "alter table t_reorg modify b varchar(50)")
+	s.tk.MustQuery("select b from t_reorg where b = 'bb'").Check(testkit.Rows("bb"))
+	// Convert the integer column to a string column.
+	s.mustExec(c, "alter table t_reorg modify c varchar(10)")
+	s.tk.MustQuery("select c from t_reorg").Check(testkit.Rows("10", "20", "<nil>"))
+	s.mustExec(c, "insert into t_reorg values (4, 4, 'd', 'abc')")
+	s.tk.MustQuery("select c from t_reorg where id = 4").Check(testkit.Rows("abc"))
+
+	// The existing data doesn't fit the new type, the column isn't changed.
+	s.testErrorCode(c, "alter table t_reorg modify a int unsigned", tmysql.ErrDataOutOfRange)
+	s.testErrorCode(c, "alter table t_reorg modify b varchar(1)", tmysql.ErrDataTooLong)
+	s.tk.MustQuery("select a, b from t_reorg").Check(testkit.Rows("1 a", "-2 bb", "<nil> <nil>", "4 d"))
+	s.mustExec(c, "insert into t_reorg values (5, -5, 'ccc', null)")
+	s.tk.MustQuery("select a, b from t_reorg where id = 5").Check(testkit.Rows("-5 ccc"))
+
+	// Unsupported changes.
+	s.testErrorCode(c, "alter table t_reorg modify id bigint unsigned", tmysql.ErrUnknown)
+	s.testErrorCode(c, "alter table t_reorg modify b int", tmysql.ErrUnknown)
+	s.mustExec(c, "drop table t_reorg")
+}
+
 func (s *testDBSuite) TestAlterColumn(c *C) {
 	defer testleak.AfterTest(c)()
 	s.tk = testkit.NewTestKit(c, s.store)
@@ -1174,6 +1244,13 @@ func (s *testDBSuite) TestTruncateTable(c *C) {
 		time.Sleep(time.Millisecond * 100)
 	}
 	c.Assert(hasOldTableData, IsFalse)
+
+	// The auto increment ID starts over after truncating the table.
+	tk.MustExec("create table t_auto (id int primary key auto_increment, c int)")
+	tk.MustExec("insert t_auto (c) values (1), (2), (3)")
+	tk.MustExec("truncate table t_auto")
+	tk.MustExec("insert t_auto (c) values (4)")
+	tk.MustQuery("select id, c from t_auto").Check(testkit.Rows("1 4"))
 }
 
 func (s *testDBSuite) TestRenameTable(c *C) {
@@ -1245,12 +1322,43 @@ func (s *testDBSuite) TestRenameMultiTables(c *C) {
 	s.tk.MustExec("use test")
 	s.tk.MustExec("create table t1(id int)")
 	s.tk.MustExec("create table t2(id int)")
-	// Currently it will fail only.
-	sql := fmt.Sprintf("rename table t1 to t3, t2 to t4")
-	_, err = s.tk.Exec(sql)
-	c.Assert(err, NotNil)
-	originErr := errors.Cause(err)
-	c.Assert(originErr.Error(), Equals, "can't run multi schema change")
+	s.tk.MustExec("insert t1 values (1)")
+	s.tk.MustExec("insert t2 values (2)")
+	s.tk.MustExec("rename table t1 to t3, t2 to t4")
+	s.tk.MustQuery("show tables").Check(testkit.Rows("t3", "t4"))
+	s.tk.MustQuery("select * from t3").Check(testkit.Rows("1"))
+	s.tk.MustQuery("select * from t4").Check(testkit.Rows("2"))
+
+	// The renames are applied in order.
+	s.tk.MustExec("rename table t3 to t1, t4 to t3, t1 to t4")
+	s.tk.MustQuery("select * from t3").Check(testkit.Rows("2"))
+	s.tk.MustQuery("select * from t4").Check(testkit.Rows("1"))
+	s.tk.MustExec("rename table t3 to t5, t5 to t6")
+	s.tk.MustQuery("show tables").Check(testkit.Rows("t4", "t6"))
+	s.tk.MustQuery("select * from t6").Check(testkit.Rows("2"))
+
+	// Move tables across databases, the indices and the auto IDs move with the tables.
+	s.tk.MustExec("create database test1")
+	s.tk.MustExec("create table t7 (id int primary key auto_increment, c int, index idx_c (c))")
+	s.tk.MustExec("insert t7 (c) values (7)")
+	s.tk.MustExec("rename table t7 to test1.t8, t4 to test1.t4")
+	s.tk.MustQuery("show tables").Check(testkit.Rows("t6"))
+	s.tk.MustQuery("show tables in test1").Check(testkit.Rows("t4", "t8"))
+	s.tk.MustExec("insert test1.t8 (c) values (8)")
+	s.tk.MustQuery("select * from test1.t8").Check(testkit.Rows("1 7", "2 8"))
+	s.tk.MustQuery("select id from test1.t8 where c = 8").Check(testkit.Rows("2"))
+
+	// None of the renames takes effect if one fails.
+	failSQL := "rename table t6 to t9, test1.t4 to test1.t8"
+	s.testErrorCode(c, failSQL, tmysql.ErrTableExists)
+	failSQL = "rename table t6 to t9, t_not_exist to t10"
+	s.testErrorCode(c, failSQL, tmysql.ErrFileNotFound)
+	failSQL = "rename table t6 to t9, test1.t4 to test_not_exist.t4"
+	s.testErrorCode(c, failSQL, tmysql.ErrErrorOnRename)
+	failSQL = "rename table t6 to t9, t9 to t10, test1.t4 to test.t10"
+	s.testErrorCode(c, failSQL, tmysql.ErrTableExists)
+	s.tk.MustQuery("show tables").Check(testkit.Rows("t6"))
+	s.tk.MustQuery("show tables in test1").Check(testkit.Rows("t4", "t8"))
 }
 
 func (s *testDBSuite) TestAddNotNullColumn(c *C) {
@@ -1422,3 +1530,59 @@ func (s *testDBSuite) TestGeneratedColumnDDL(c *C) {
 	result = s.tk.MustQuery(`DESC test_gv_ddl`)
 	result.Check(testkit.Rows(`a int(11) YES  <nil> `, `b bigint(21) YES  <nil> VIRTUAL GENERATED`, `cnew bigint(21) YES  <nil> `))
 }
+
+func (s *testDBSuite) TestCheckConstraintDDL(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use test")
+	s.tk.MustExec("drop table if exists test_check, test_check_bad")
+	s.tk.MustExec("create table test_check (a int, b int, c int, check (a + b > 0))")
+	s.tk.MustExec("insert into test_check values (1, 2, 3), (5, -1, 0)")
+
+	// The existing rows are checked when the constraint is added, the constraint is removed if they violate it.
+	s.testErrorCode(c, "alter table test_check add constraint chk_bc check (b > c)", mysql.ErrCheckConstraintViolated)
+	s.tk.MustExec("insert into test_check values (2, 1, 3)")
+	s.tk.MustExec("delete from test_check where a = 2")
+	s.tk.MustExec("alter table test_check add constraint chk_ac check (a + c > 1)")
+	_, err := s.tk.Exec("insert into test_check values (0, 1, 0)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*'chk_ac' is violated.*")
+
+	checkTests := []struct {
+		stmt string
+		err  int
+	}{
+		{"alter table test_check add constraint chk_ac check (a > 0)", mysql.ErrCheckConstraintDupName},
+		{"alter table test_check add check (d > 0)", mysql.ErrCheckConstraintRefersUnknownColumn},
+		{"alter table test_check drop check chk_none", mysql.ErrConstraintNotFound},
+		{"alter table test_check drop column c", mysql.ErrDependentByCheckConstraint},
+		{"alter table test_check change column b b2 int", mysql.ErrDependentByCheckConstraint},
+		{"create table test_check_bad (a int, check (a > (select 1)))", mysql.ErrCheckConstraintFunctionIsNotAllowed},
+		{"create table test_check_bad (a int, check (a > @x))", mysql.ErrCheckConstraintVariables},
+		{"create table test_check_bad (a int check (a > rand()))", mysql.ErrCheckConstraintNamedFunctionIsNotAllowed},
+		{"create table test_check_bad (a int, check (b > 0))", mysql.ErrCheckConstraintRefersUnknownColumn},
+		{"create table test_check_bad (a int, constraint c1 check (a > 0), constraint c1 check (a < 10))", mysql.ErrCheckConstraintDupName},
+	}
+	for _, tt := range checkTests {
+		s.testErrorCode(c, tt.stmt, tt.err)
+	}
+
+	s.tk.MustExec("alter table test_check drop check chk_ac")
+	s.tk.MustExec("insert into test_check values (0, 1, 0)")
+	s.tk.MustExec("alter table test_check drop check test_check_chk_1")
+	s.tk.MustExec("insert into test_check values (-1, -1, 2)")
+	s.tk.MustExec("alter table test_check drop column c")
+	s.tk.MustQuery("select count(*) from test_check").Check(testkit.Rows("4"))
+}
+
+func (s *testDBSuite) TestPreSplitRegionsUnsupported(c *C) {
+	defer testleak.AfterTest(c)
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+
+	// The goleveldb store can't split regions, so pre_split_regions is rejected rather than ignored.
+	_, err := s.tk.Exec("create table t_pre_split (a int) shard_row_id_bits = 4 pre_split_regions = 2")
+	c.Assert(err, ErrorMatches, ".*pre_split_regions on a store which can't split regions.*")
+	s.tk.MustExec("create table t_pre_split (a int) shard_row_id_bits = 4")
+	s.tk.MustExec("drop table t_pre_split")
+}
@@ -30,6 +30,17 @@ import (
 // RunWorker indicates if this TiDB server starts DDL worker and can run DDL job.
 var RunWorker = true
 
+// HistoryJobLimit is the maximum number of history DDL jobs retained in the store.
+// The older jobs are pruned by the DDL owner in background, 0 means no limit.
+var HistoryJobLimit int64
+
+// pruneHistoryJobInterval is the interval to check and prune the history DDL jobs.
+var pruneHistoryJobInterval = 10 * time.Minute
+
+// pruneHistoryJobBatchSize is the maximum number of history DDL jobs removed in one transaction,
+// so pruning a large history doesn't exceed the transaction size limit.
+var pruneHistoryJobBatchSize int64 = 256
+
 // onDDLWorker is for async online schema changing, it will try to become the owner firstly,
 // then wait or pull the job queue to handle a schema change job.
 func (d *ddl) onDDLWorker() {
@@ -62,6 +73,59 @@ func (d *ddl) onDDLWorker() {
 	}
 }
 
+// onPruneHistoryJobs prunes the history DDL jobs beyond HistoryJobLimit periodically.
+func (d *ddl) onPruneHistoryJobs() {
+	defer d.wait.Done()
+	if !RunWorker || HistoryJobLimit <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(pruneHistoryJobInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.quitCh:
+			return
+		}
+
+		err := d.pruneHistoryDDLJobs(HistoryJobLimit)
+		if err != nil {
+			log.Errorf("[ddl] prune history ddl jobs err %v", errors.ErrorStack(err))
+		}
+	}
+}
+
+// pruneHistoryDDLJobs removes the oldest history DDL jobs and keeps the latest 'limit' jobs.
+// Only the owner prunes the history DDL jobs.
+func (d *ddl) pruneHistoryDDLJobs(limit int64) error {
+	if !d.isOwner() {
+		return nil
+	}
+
+	var total int64
+	for {
+		var cnt int64
+		err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+			var err1 error
+			cnt, err1 = meta.NewMeta(txn).PruneHistoryDDLJobs(limit, pruneHistoryJobBatchSize)
+			return errors.Trace(err1)
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		total += cnt
+		if cnt < pruneHistoryJobBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Infof("[ddl] prune %d history ddl jobs, keep the latest %d jobs", total, limit)
+	}
+	return nil
+}
+
 func asyncNotify(ch chan struct{}) {
 	select {
 	case ch <- struct{}{}:
@@ -283,10 +347,16 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onCreateForeignKey(t, job)
 	case model.ActionDropForeignKey:
 		ver, err = d.onDropForeignKey(t, job)
+	case model.ActionAddCheck:
+		ver, err = d.onAddCheck(t, job)
+	case model.ActionDropCheck:
+		ver, err = d.onDropCheck(t, job)
 	case model.ActionTruncateTable:
 		ver, err = d.onTruncateTable(t, job)
 	case model.ActionRenameTable:
 		ver, err = d.onRenameTable(t, job)
+	case model.ActionRenameTables:
+		ver, err = d.onRenameTables(t, job)
 	case model.ActionSetDefaultValue:
 		ver, err = d.onSetDefaultValue(t, job)
 	default:
@@ -384,6 +454,22 @@ func updateSchemaVersion(t *meta.Meta, job *model.Job) (int64, error) {
 			return 0, errors.Trace(err)
 		}
 		diff.TableID = job.TableID
+	} else if job.Type == model.ActionRenameTables {
+		var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+		var tableNames []model.CIStr
+		err = job.DecodeArgs(&oldSchemaIDs, &newSchemaIDs, &tableNames, &tableIDs)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		diff.TableID = job.TableID
+		diff.AffectedOpts = make([]*model.AffectedOption, 0, len(tableIDs))
+		for i, tableID := range tableIDs {
+			diff.AffectedOpts = append(diff.AffectedOpts, &model.AffectedOption{
+				SchemaID:    newSchemaIDs[i],
+				TableID:     tableID,
+				OldSchemaID: oldSchemaIDs[i],
+			})
+		}
 	} else {
 		diff.TableID = job.TableID
 	}
@@ -14,8 +14,10 @@
 package ddl
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
@@ -49,6 +51,49 @@ func (s *testDDLSuite) TestCheckOwner(c *C) {
 	c.Assert(d1.GetLease(), Equals, 2*time.Second)
 }
 
+func (s *testDDLSuite) TestPruneHistoryJobs(c *C) {
+	defer testleak.AfterTest(c)()
+	store := testCreateStore(c, "test_prune_history_jobs")
+	defer store.Close()
+
+	d := testNewDDL(goctx.Background(), nil, store, nil, nil, testLease)
+	defer d.Stop()
+	ctx := testNewContext(d)
+
+	var jobIDs []int64
+	for i := 0; i < 3; i++ {
+		dbInfo := testSchemaInfo(c, d, fmt.Sprintf("test_prune_%d", i))
+		job := testCreateSchema(c, ctx, d, dbInfo)
+		jobIDs = append(jobIDs, job.ID)
+	}
+
+	getHistoryJobIDs := func() []int64 {
+		var ids []int64
+		err := kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+			jobs, err1 := meta.NewMeta(txn).GetAllHistoryDDLJobs()
+			for _, job := range jobs {
+				ids = append(ids, job.ID)
+			}
+			return errors.Trace(err1)
+		})
+		c.Assert(err, IsNil)
+		return ids
+	}
+	c.Assert(getHistoryJobIDs(), DeepEquals, jobIDs)
+
+	// The old jobs are pruned in batches and the recent jobs are retained.
+	defer func(batchSize int64) {
+		pruneHistoryJobBatchSize = batchSize
+	}(pruneHistoryJobBatchSize)
+	pruneHistoryJobBatchSize = 1
+	err := d.pruneHistoryDDLJobs(1)
+	c.Assert(err, IsNil)
+	c.Assert(getHistoryJobIDs(), DeepEquals, jobIDs[2:])
+	err = d.pruneHistoryDDLJobs(2)
+	c.Assert(err, IsNil)
+	c.Assert(getHistoryJobIDs(), DeepEquals, jobIDs[2:])
+}
+
 func (s *testDDLSuite) TestSchemaError(c *C) {
 	defer testleak.AfterTest(c)()
 	store := testCreateStore(c, "test_schema_error")
@@ -16,16 +16,20 @@ package ddl
 import (
 	"math"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
@@ -390,11 +394,12 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 func (d *ddl) fetchRowColVals(txn kv.Transaction, t table.Table, taskOpInfo *indexTaskOpInfo, handleInfo *handleInfo) (
 	[]*indexRecord, *taskResult) {
 	startTime := time.Now()
-	handleCnt := defaultTaskHandleCnt
+	handleCnt := taskOpInfo.batchSize
 	rawRecords := make([][]byte, 0, handleCnt)
 	idxRecords := make([]*indexRecord, 0, handleCnt)
 	ret := &taskResult{doneHandle: handleInfo.startHandle}
-	err := d.iterateSnapshotRows(t, txn.StartTS(), handleInfo.startHandle,
+	// Scan the rows by the transaction, so the requests are sent with the reorg priority.
+	err := d.iterateRows(txn, t, handleInfo.startHandle,
 		func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
 			rawRecords = append(rawRecords, rawRecord)
 			indexRecord := &indexRecord{handle: h, key: rowKey}
@@ -475,10 +480,61 @@ func (d *ddl) getIndexRecords(t table.Table, taskOpInfo *indexTaskOpInfo, rawRec
 const (
 	defaultBatchCnt      = 1024
 	defaultSmallBatchCnt = 128
-	defaultTaskHandleCnt = 128
-	defaultTaskCnt       = 16
 )
 
+// reorgVars is the settings of the ADD INDEX backfill, they are read from the global variables
+// tidb_ddl_reorg_worker_cnt, tidb_ddl_reorg_batch_size and tidb_ddl_reorg_priority.
+type reorgVars struct {
+	workerCnt int
+	batchSize int
+	priority  int
+}
+
+// loadReorgVars reads the ADD INDEX backfill settings, it's called between the batches so the changes of the variables
+// take effect on the running jobs. The default values are used if the variables can't be read.
+func (d *ddl) loadReorgVars() reorgVars {
+	vars := reorgVars{
+		workerCnt: variable.DefTiDBDDLReorgWorkerCount,
+		batchSize: variable.DefTiDBDDLReorgBatchSize,
+		priority:  kv.PriorityNormal,
+	}
+	if d.ctxPool == nil {
+		return vars
+	}
+	resource, err := d.ctxPool.Get()
+	if err != nil {
+		log.Warnf("[ddl] get session to read reorg variables failed %v", err)
+		return vars
+	}
+	defer d.ctxPool.Put(resource)
+	ctx := resource.(context.Context)
+	ctx.GetSessionVars().InRestrictedSQL = true
+
+	getVar := func(name string) (string, bool) {
+		val, err := varsutil.GetGlobalSystemVar(ctx.GetSessionVars(), name)
+		if err != nil {
+			log.Warnf("[ddl] read variable %s failed %v", name, err)
+			return "", false
+		}
+		val, err = varsutil.ValidateGlobalSystemVar(name, val)
+		if err != nil {
+			log.Warnf("[ddl] invalid variable %s, %v", name, err)
+			return "", false
+		}
+		return val, true
+	}
+	if val, ok := getVar(variable.TiDBDDLReorgWorkerCount); ok {
+		vars.workerCnt, _ = strconv.Atoi(val)
+	}
+	if val, ok := getVar(variable.TiDBDDLReorgBatchSize); ok {
+		vars.batchSize, _ = strconv.Atoi(val)
+	}
+	if val, ok := getVar(variable.TiDBDDLReorgPriority); ok {
+		vars.priority, _ = varsutil.ParseDDLReorgPriority(val)
+	}
+	return vars
+}
+
 // taskResult is the result of the task.
 type taskResult struct {
 	count      int   // The number of records that has been processed in the task.
@@ -505,13 +561,15 @@ type indexTaskOpInfo struct {
 	colMap    map[int64]*types.FieldType // It's the index columns map.
 	taskRetCh chan *taskResult           // Get the results of all tasks.
 	nextCh    chan int64                 // It notifies to start the next task.
+	batchSize int                        // The max number of rows backfilled in a task.
+	priority  int                        // The priority of the task transactions.
 }
 
 // addTableIndex adds index into table.
 // TODO: Move this to doc or wiki.
 // How to add index in reorganization state?
-// Concurrently process the defaultTaskHandleCnt tasks. Each task deals with a handle range of the index record.
-// The handle range size is defaultTaskHandleCnt.
+// Concurrently process the tidb_ddl_reorg_worker_cnt tasks. Each task deals with a handle range of the index record.
+// The handle range size is tidb_ddl_reorg_batch_size. The variables are reloaded before each round of the tasks.
 // Because each handle range depends on the previous one, it's necessary to obtain the handle range serially.
 // Real concurrent processing needs to perform after the handle range has been acquired.
 // The operation flow of the each task of data is as follows:
@@ -533,12 +591,11 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 		col := cols[v.Offset]
 		colMap[col.ID] = &col.FieldType
 	}
-	taskCnt := defaultTaskCnt
 	taskOpInfo := &indexTaskOpInfo{
 		tblIndex:  tables.NewIndex(t.Meta(), indexInfo),
 		colMap:    colMap,
 		nextCh:    make(chan int64, 1),
-		taskRetCh: make(chan *taskResult, taskCnt),
+		taskRetCh: make(chan *taskResult, variable.MaxDDLReorgWorkerCount),
 	}
 
 	addedCount := job.GetRowCount()
@@ -546,6 +603,10 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 
 	for {
 		startTime := time.Now()
+		vars := d.loadReorgVars()
+		taskCnt := vars.workerCnt
+		taskOpInfo.batchSize = vars.batchSize
+		taskOpInfo.priority = vars.priority
 		wg := sync.WaitGroup{}
 		for i := 0; i < taskCnt; i++ {
 			wg.Add(1)
@@ -636,6 +697,7 @@ func (d *ddl) doBackfillIndexTask(t table.Table, taskOpInfo *indexTaskOpInfo, st
 	ret := new(taskResult)
 	handleInfo := &handleInfo{startHandle: startHandle}
 	err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+		txn.SetOption(kv.Priority, taskOpInfo.priority)
 		err1 := d.isReorgRunnable(txn)
 		if err1 != nil {
 			return errors.Trace(err1)
@@ -661,7 +723,7 @@ func (d *ddl) doBackfillIndexTask(t table.Table, taskOpInfo *indexTaskOpInfo, st
 }
 
 // doBackfillIndexTaskInTxn deals with a part of backfilling index data in a Transaction.
-// This part of the index data rows is defaultTaskHandleCnt.
+// This part of the index data rows is tidb_ddl_reorg_batch_size.
 func (d *ddl) doBackfillIndexTaskInTxn(t table.Table, txn kv.Transaction, taskOpInfo *indexTaskOpInfo,
 	handleInfo *handleInfo) *taskResult {
 	idxRecords, taskRet := d.fetchRowColVals(txn, t, taskOpInfo, handleInfo)
@@ -715,9 +777,13 @@ func (d *ddl) iterateSnapshotRows(t table.Table, version uint64, seekHandle int6
 	if err != nil {
 		return errors.Trace(err)
 	}
+	return errors.Trace(d.iterateRows(snap, t, seekHandle, fn))
+}
 
+// iterateRows iterates the records of the table from seekHandle by the retriever.
+func (d *ddl) iterateRows(retriever kv.Retriever, t table.Table, seekHandle int64, fn recordIterFunc) error {
 	firstKey := t.RecordKey(seekHandle)
-	it, err := snap.Seek(firstKey)
+	it, err := retriever.Seek(firstKey)
 	if err != nil {
 		return errors.Trace(err)
 	}
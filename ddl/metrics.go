@@ -38,6 +38,7 @@ var (
 
 	// handle batch data type.
 	batchAddCol              = "batch_add_col"
+	batchModifyCol           = "batch_modify_col"
 	batchAddIdx              = "batch_add_idx"
 	batchDelData             = "batch_del_data"
 	batchHandleDataHistogram = prometheus.NewHistogramVec(
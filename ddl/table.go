@@ -137,7 +137,10 @@ func (d *ddl) getTable(schemaID int64, tblInfo *model.TableInfo) (table.Table, e
 }
 
 func getTableInfo(t *meta.Meta, job *model.Job, schemaID int64) (*model.TableInfo, error) {
-	tableID := job.TableID
+	return getTableInfoByID(t, job, schemaID, job.TableID)
+}
+
+func getTableInfoByID(t *meta.Meta, job *model.Job, schemaID, tableID int64) (*model.TableInfo, error) {
 	tblInfo, err := t.GetTable(schemaID, tableID)
 	if err != nil {
 		if meta.ErrDBNotExists.Equal(err) {
@@ -180,11 +183,13 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 		return ver, errors.Trace(err)
 	}
 
+	// The auto increment ID is dropped with the old table, so the new table starts it over.
 	err = t.DropTable(schemaID, tableID, true)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
 	}
+	oldTblInfo := *tblInfo
 	tblInfo.ID = newTableID
 	err = t.CreateTable(schemaID, tblInfo)
 	if err != nil {
@@ -200,6 +205,9 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	job.BinlogInfo.AddTableInfo(ver, tblInfo)
 	startKey := tablecodec.EncodeTablePrefix(tableID)
 	job.Args = []interface{}{startKey}
+	// The statistics of the old table are dropped, and the new table starts with empty statistics.
+	d.asyncNotifyEvent(&Event{Tp: model.ActionDropTable, TableInfo: &oldTblInfo})
+	d.asyncNotifyEvent(&Event{Tp: model.ActionCreateTable, TableInfo: tblInfo})
 	return ver, nil
 }
 
@@ -249,6 +257,78 @@ func (d *ddl) onRenameTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	return ver, nil
 }
 
+// onRenameTables renames the tables in one schema version.
+// All the renames are checked before the meta is changed, so either all or none of them take effect.
+func (d *ddl) onRenameTables(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+	var tableNames []model.CIStr
+	if err := job.DecodeArgs(&oldSchemaIDs, &newSchemaIDs, &tableNames, &tableIDs); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfos := make([]*model.TableInfo, 0, len(tableIDs))
+	renamed := make(map[int64]bool, len(tableIDs))
+	for i, tableID := range tableIDs {
+		tblInfo, err := getTableInfoByID(t, job, oldSchemaIDs[i], tableID)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		tblInfos = append(tblInfos, tblInfo)
+		renamed[tableID] = true
+	}
+	// The renamed tables may swap their names, so a new name only conflicts with the tables which are not renamed.
+	newNames := make(map[string]bool, len(tableIDs))
+	for i, tableName := range tableNames {
+		key := fmt.Sprintf("%d.%s", newSchemaIDs[i], tableName.L)
+		if newNames[key] {
+			job.State = model.JobCancelled
+			return ver, infoschema.ErrTableExists.GenByArgs(tableName)
+		}
+		newNames[key] = true
+		tables, err := t.ListTables(newSchemaIDs[i])
+		if err != nil {
+			if meta.ErrDBNotExists.Equal(err) {
+				job.State = model.JobCancelled
+				return ver, infoschema.ErrDatabaseNotExists.GenByArgs("")
+			}
+			return ver, errors.Trace(err)
+		}
+		for _, tbl := range tables {
+			if tbl.Name.L == tableName.L && !renamed[tbl.ID] {
+				job.State = model.JobCancelled
+				return ver, infoschema.ErrTableExists.GenByArgs(tbl.Name)
+			}
+		}
+	}
+
+	for i, tblInfo := range tblInfos {
+		if err := t.DropTable(oldSchemaIDs[i], tblInfo.ID, false); err != nil {
+			return ver, errors.Trace(err)
+		}
+	}
+	for i, tblInfo := range tblInfos {
+		if newSchemaIDs[i] != oldSchemaIDs[i] && tblInfo.OldSchemaID == 0 {
+			// The auto ID is still kept in the old database.
+			tblInfo.OldSchemaID = oldSchemaIDs[i]
+		}
+		tblInfo.Name = tableNames[i]
+		if err := t.CreateTable(newSchemaIDs[i], tblInfo); err != nil {
+			return ver, errors.Trace(err)
+		}
+	}
+
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfos[0])
+	return ver, nil
+}
+
 func checkTableNotExists(t *meta.Meta, job *model.Job, schemaID int64, tableName string) error {
 	// Check this table's database.
 	tables, err := t.ListTables(schemaID)
@@ -0,0 +1,187 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/sqlexec"
+	goctx "golang.org/x/net/context"
+)
+
+const blocklistKey = "/tidb/blocklist"
+
+// DigestBlocklist caches the statement digests stored in mysql.blocked_digests.
+// Statements whose digest is in the blocklist are rejected before they are compiled.
+type DigestBlocklist struct {
+	// mu serializes the writers, readers load the digests without locking.
+	mu      sync.Mutex
+	digests atomic.Value // map[string]struct{}
+}
+
+func newDigestBlocklist() *DigestBlocklist {
+	bl := &DigestBlocklist{}
+	bl.digests.Store(make(map[string]struct{}))
+	return bl
+}
+
+func (bl *DigestBlocklist) load() map[string]struct{} {
+	return bl.digests.Load().(map[string]struct{})
+}
+
+// Empty returns true if no digest is blocked, callers can use it to skip computing the digest.
+func (bl *DigestBlocklist) Empty() bool {
+	return len(bl.load()) == 0
+}
+
+// Blocked returns whether the digest is in the blocklist.
+func (bl *DigestBlocklist) Blocked(digest string) bool {
+	_, ok := bl.load()[digest]
+	return ok
+}
+
+// Update reloads the blocklist from mysql.blocked_digests.
+func (bl *DigestBlocklist) Update(ctx context.Context) error {
+	tmp, err := ctx.(sqlexec.SQLExecutor).Execute("select digest from mysql.blocked_digests")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rs := tmp[0]
+	defer rs.Close()
+
+	digests := make(map[string]struct{})
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		digests[row.Data[0].GetString()] = struct{}{}
+	}
+	bl.mu.Lock()
+	bl.digests.Store(digests)
+	bl.mu.Unlock()
+	return nil
+}
+
+// Block adds the digests to the local blocklist. It takes effect on this server immediately,
+// other servers are notified by NotifyUpdateBlocklist.
+func (bl *DigestBlocklist) Block(digests []string) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	old := bl.load()
+	m := make(map[string]struct{}, len(old)+len(digests))
+	for d := range old {
+		m[d] = struct{}{}
+	}
+	for _, d := range digests {
+		m[d] = struct{}{}
+	}
+	bl.digests.Store(m)
+}
+
+// Unblock removes the digests from the local blocklist.
+func (bl *DigestBlocklist) Unblock(digests []string) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	old := bl.load()
+	m := make(map[string]struct{}, len(old))
+	for d := range old {
+		m[d] = struct{}{}
+	}
+	for _, d := range digests {
+		delete(m, d)
+	}
+	bl.digests.Store(m)
+}
+
+// UnblockAll clears the local blocklist.
+func (bl *DigestBlocklist) UnblockAll() {
+	bl.mu.Lock()
+	bl.digests.Store(make(map[string]struct{}))
+	bl.mu.Unlock()
+}
+
+// DigestBlocklist returns the statement digest blocklist.
+func (do *Domain) DigestBlocklist() *DigestBlocklist {
+	return do.blocklist
+}
+
+// LoadBlocklistLoop creates a goroutine loads the blocked digests in a loop, it
+// should be called only once in BootstrapSession.
+func (do *Domain) LoadBlocklistLoop(ctx context.Context) error {
+	ctx.GetSessionVars().InRestrictedSQL = true
+	err := do.blocklist.Update(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var watchCh clientv3.WatchChan
+	duration := 5 * time.Minute
+	if do.etcdClient != nil {
+		watchCh = do.etcdClient.Watch(goctx.Background(), blocklistKey)
+		duration = 10 * time.Minute
+	}
+
+	go func() {
+		var count int
+		for {
+			ok := true
+			select {
+			case <-do.exit:
+				return
+			case _, ok = <-watchCh:
+			case <-time.After(duration):
+			}
+			if !ok {
+				log.Error("[domain] load blocklist loop watch channel closed.")
+				watchCh = do.etcdClient.Watch(goctx.Background(), blocklistKey)
+				count++
+				if count > 10 {
+					time.Sleep(time.Duration(count) * time.Second)
+				}
+				continue
+			}
+
+			count = 0
+			err := do.blocklist.Update(ctx)
+			if err != nil {
+				log.Error("[domain] load blocklist fail:", errors.ErrorStack(err))
+			} else {
+				log.Info("[domain] reload blocklist success.")
+			}
+		}
+	}()
+	return nil
+}
+
+// NotifyUpdateBlocklist updates blocklist key in etcd, TiDB client that watches
+// the key will reload the blocked digests.
+func (do *Domain) NotifyUpdateBlocklist(ctx context.Context) {
+	if do.etcdClient != nil {
+		kv := do.etcdClient.KV
+		_, err := kv.Put(goctx.Background(), blocklistKey, "")
+		if err != nil {
+			log.Warn("notify update blocklist failed:", err)
+		}
+	}
+}
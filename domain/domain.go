@@ -43,6 +43,7 @@ type Domain struct {
 	store           kv.Storage
 	infoHandle      *infoschema.Handle
 	privHandle      *privileges.Handle
+	blocklist       *DigestBlocklist
 	statsHandle     unsafe.Pointer
 	statsLease      time.Duration
 	ddl             ddl.DDL
@@ -51,6 +52,11 @@ type Domain struct {
 	sysSessionPool  *pools.ResourcePool
 	exit            chan struct{}
 	etcdClient      *clientv3.Client
+	// serverIDs are the server IDs acquired by the servers using this domain.
+	serverIDs struct {
+		sync.Mutex
+		ids []uint64
+	}
 
 	MockReloadFailed MockFailure // It mocks reload failed.
 }
@@ -228,12 +234,17 @@ func (do *Domain) InfoSchema() infoschema.InfoSchema {
 
 // GetSnapshotInfoSchema gets a snapshot information schema.
 func (do *Domain) GetSnapshotInfoSchema(snapshotTS uint64) (infoschema.InfoSchema, error) {
+	is := do.infoHandle.Get()
 	snapHandle := do.infoHandle.EmptyClone()
-	_, _, err := do.loadInfoSchema(snapHandle, do.infoHandle.Get().SchemaMetaVersion(), snapshotTS)
+	_, _, err := do.loadInfoSchema(snapHandle, is.SchemaMetaVersion(), snapshotTS)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return snapHandle.Get(), nil
+	// Nothing is loaded if the schema version at snapshotTS is the same as the current one.
+	if snapIS := snapHandle.Get(); snapIS != nil {
+		return snapIS, nil
+	}
+	return is, nil
 }
 
 // PerfSchema gets performance schema from domain.
@@ -348,6 +359,7 @@ func (do *Domain) loadSchemaInLoop(lease time.Duration) {
 // Close closes the Domain and release its resource.
 func (do *Domain) Close() {
 	do.ddl.Stop()
+	do.releaseServerIDs()
 	close(do.exit)
 	if do.etcdClient != nil {
 		do.etcdClient.Close()
@@ -410,6 +422,7 @@ func NewDomain(store kv.Storage, ddlLease time.Duration, statsLease time.Duratio
 		exit:            make(chan struct{}),
 		sysSessionPool:  pools.NewResourcePool(factory, capacity, capacity, idleTimeout),
 		statsLease:      statsLease,
+		blocklist:       newDigestBlocklist(),
 	}
 
 	if ebd, ok := store.(EtcdBackend); ok {
@@ -528,7 +541,12 @@ func (do *Domain) StatsHandle() *statistics.Handle {
 
 // CreateStatsHandle is used only for test.
 func (do *Domain) CreateStatsHandle(ctx context.Context) {
-	atomic.StorePointer(&do.statsHandle, unsafe.Pointer(statistics.NewHandle(ctx, do.statsLease)))
+	statsHandle := statistics.NewHandle(ctx, do.statsLease)
+	atomic.StorePointer(&do.statsHandle, unsafe.Pointer(statsHandle))
+	// The mocked domain has no infoHandle.
+	if do.infoHandle != nil {
+		do.infoHandle.SetStatsProvider(statsHandle)
+	}
 }
 
 // UpdateTableStatsLoop creates a goroutine loads stats info and updates stats info in a loop. It
@@ -537,11 +555,16 @@ func (do *Domain) UpdateTableStatsLoop(ctx context.Context) error {
 	ctx.GetSessionVars().InRestrictedSQL = true
 	statsHandle := statistics.NewHandle(ctx, do.statsLease)
 	atomic.StorePointer(&do.statsHandle, unsafe.Pointer(statsHandle))
+	do.infoHandle.SetStatsProvider(statsHandle)
 	do.ddl.RegisterEventCh(statsHandle.DDLEventCh())
 	err := statsHandle.Update(do.InfoSchema())
 	if err != nil {
 		return errors.Trace(err)
 	}
+	err = statsHandle.LoadIndexUsage()
+	if err != nil {
+		return errors.Trace(err)
+	}
 	lease := do.statsLease
 	if lease <= 0 {
 		return nil
@@ -560,6 +583,7 @@ func (do *Domain) UpdateTableStatsLoop(ctx context.Context) error {
 				if err != nil {
 					log.Error(errors.ErrorStack(err))
 				}
+				statsHandle.UpdateStatsByQueryFeedback()
 			case <-do.exit:
 				return
 			// This channel is sent only by ddl owner or the drop stats executor.
@@ -568,6 +592,8 @@ func (do *Domain) UpdateTableStatsLoop(ctx context.Context) error {
 				if err != nil {
 					log.Error(errors.ErrorStack(err))
 				}
+			case done := <-statsHandle.ReloadCh():
+				done <- statsHandle.Reload(do.InfoSchema())
 			case t := <-statsHandle.AnalyzeResultCh():
 				for _, hg := range t.Hist {
 					err = hg.SaveToStorage(t.Ctx, t.TableID, t.Count, t.IsIndex)
@@ -577,6 +603,10 @@ func (do *Domain) UpdateTableStatsLoop(ctx context.Context) error {
 				}
 			case <-deltaUpdateTicker.C:
 				statsHandle.DumpStatsDeltaToKV()
+				err = statsHandle.DumpIndexUsageToKV()
+				if err != nil {
+					log.Error(errors.ErrorStack(err))
+				}
 			}
 		}
 	}(do)
@@ -601,6 +631,7 @@ func (do *Domain) NotifyUpdatePrivilege(ctx context.Context) {
 const (
 	codeInfoSchemaExpired terror.ErrCode = 1
 	codeInfoSchemaChanged terror.ErrCode = 2
+	codeQueryBlocked      terror.ErrCode = 3
 )
 
 var (
@@ -608,4 +639,6 @@ var (
 	ErrInfoSchemaExpired = terror.ClassDomain.New(codeInfoSchemaExpired, "Information schema is out of date.")
 	// ErrInfoSchemaChanged returns the error that information schema is changed.
 	ErrInfoSchemaChanged = terror.ClassDomain.New(codeInfoSchemaChanged, "Information schema is changed.")
+	// ErrQueryBlocked returns the error that the statement digest is in the blocklist.
+	ErrQueryBlocked = terror.ClassDomain.New(codeQueryBlocked, "Statement with digest %s is blocked.")
 )
@@ -21,6 +21,8 @@ import (
 	"github.com/ngaut/pools"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
@@ -100,3 +102,76 @@ func (*testSuite) TestT(c *C) {
 	err = store.Close()
 	c.Assert(err, IsNil)
 }
+
+func (*testSuite) TestGlobalKill(c *C) {
+	defer testleak.AfterTest(c)()
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	dom, err := NewDomain(store, 80*time.Millisecond, 0, mockFactory, sysMockFactory)
+	c.Assert(err, IsNil)
+	defer dom.Close()
+	globalKillPollInterval = 10 * time.Millisecond
+
+	id1, err := dom.AcquireServerID(2, func() {})
+	c.Assert(err, IsNil)
+	c.Assert(id1, Equals, uint64(1))
+	id2, err := dom.AcquireServerID(2, func() {})
+	c.Assert(err, IsNil)
+	c.Assert(id2, Equals, uint64(2))
+	_, err = dom.AcquireServerID(2, func() {})
+	c.Assert(err, NotNil)
+
+	killed := make(chan globalKillRequest, 1)
+	dom.GlobalKillLoop(id2, func(connID uint64, query bool) {
+		killed <- globalKillRequest{ConnID: connID, Query: query}
+	})
+	err = dom.GlobalKill(id2, 10, true)
+	c.Assert(err, IsNil)
+	select {
+	case req := <-killed:
+		c.Assert(req, Equals, globalKillRequest{ConnID: 10, Query: true})
+	case <-time.After(5 * time.Second):
+		c.Fatal("the KILL request isn't received")
+	}
+	err = dom.GlobalKill(3, 10, false)
+	c.Assert(err, NotNil)
+}
+
+func (*testSuite) TestServerIDLost(c *C) {
+	defer testleak.AfterTest(c)()
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	dom, err := NewDomain(store, 80*time.Millisecond, 0, mockFactory, sysMockFactory)
+	c.Assert(err, IsNil)
+	defer dom.Close()
+	defer func(lease time.Duration) { serverIDLease = lease }(serverIDLease)
+	serverIDLease = 60 * time.Millisecond
+
+	lost := make(chan struct{})
+	id, err := dom.AcquireServerID(1, func() { close(lost) })
+	c.Assert(err, IsNil)
+	c.Assert(dom.ownServerID(id), IsTrue)
+	// Another server acquires the server ID.
+	err = kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		return meta.NewMeta(txn).SetServerID(id, time.Now().Add(time.Hour).UnixNano())
+	})
+	c.Assert(err, IsNil)
+	select {
+	case <-lost:
+	case <-time.After(5 * time.Second):
+		c.Fatal("the lost server ID isn't detected")
+	}
+	c.Assert(dom.ownServerID(id), IsFalse)
+	// The server ID of the other server isn't released when the domain is closed.
+	dom.releaseServerIDs()
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		expire, err1 := meta.NewMeta(txn).GetServerID(id)
+		c.Assert(expire, Greater, time.Now().UnixNano())
+		return errors.Trace(err1)
+	})
+	c.Assert(err, IsNil)
+}
@@ -0,0 +1,263 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	goctx "golang.org/x/net/context"
+)
+
+const globalKillKeyPrefix = "/tidb/global_kill/"
+
+var (
+	// serverIDLease is the duration a server ID is kept after the last heartbeat of the server,
+	// then the server ID can be acquired by another server.
+	serverIDLease = 30 * time.Second
+	// globalKillPollInterval is the interval to check the KILL requests if etcd isn't available.
+	globalKillPollInterval = time.Second
+)
+
+// globalKillRequest is a KILL request sent to the server which owns the connection.
+type globalKillRequest struct {
+	ConnID uint64 `json:"conn_id"`
+	Query  bool   `json:"query"`
+}
+
+func globalKillKey(serverID uint64) string {
+	return fmt.Sprintf("%s%d", globalKillKeyPrefix, serverID)
+}
+
+// errServerIDLost is returned by the heartbeat if the server ID is acquired by another server.
+var errServerIDLost = errors.New("server ID is acquired by another server")
+
+// AcquireServerID registers an unused server ID in [1, maxServerID] in the store. The server ID is kept by a
+// heartbeat until the domain is closed, so it's unique among the alive servers which share the store.
+// If the heartbeat can't renew the server ID before it expires, the server ID is given up and onLost is called,
+// the server must not use it any more because it may be acquired by another server.
+func (do *Domain) AcquireServerID(maxServerID uint64, onLost func()) (uint64, error) {
+	var serverID uint64
+	var expire int64
+	err := kv.RunInNewTxn(do.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		ids, err := t.GetServerIDs()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		now := time.Now()
+		serverID = 0
+		for id := uint64(1); id <= maxServerID; id++ {
+			if e, ok := ids[id]; !ok || e < now.UnixNano() {
+				serverID = id
+				break
+			}
+		}
+		if serverID == 0 {
+			return errors.Errorf("all the %d server IDs are in use", maxServerID)
+		}
+		expire = now.Add(serverIDLease).UnixNano()
+		return errors.Trace(t.SetServerID(serverID, expire))
+	})
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	log.Infof("[domain] acquire server ID %d", serverID)
+	do.serverIDs.Lock()
+	do.serverIDs.ids = append(do.serverIDs.ids, serverID)
+	do.serverIDs.Unlock()
+	go do.serverIDHeartbeatLoop(serverID, expire, onLost)
+	return serverID, nil
+}
+
+// ownServerID checks whether the server ID is acquired by this domain and isn't lost.
+func (do *Domain) ownServerID(serverID uint64) bool {
+	do.serverIDs.Lock()
+	defer do.serverIDs.Unlock()
+	for _, id := range do.serverIDs.ids {
+		if id == serverID {
+			return true
+		}
+	}
+	return false
+}
+
+// loseServerID removes the server ID from the acquired ones, so it's not released when the domain is closed.
+func (do *Domain) loseServerID(serverID uint64) {
+	do.serverIDs.Lock()
+	defer do.serverIDs.Unlock()
+	for i, id := range do.serverIDs.ids {
+		if id == serverID {
+			do.serverIDs.ids = append(do.serverIDs.ids[:i], do.serverIDs.ids[i+1:]...)
+			return
+		}
+	}
+}
+
+// releaseServerIDs unregisters the server IDs acquired by AcquireServerID.
+func (do *Domain) releaseServerIDs() {
+	do.serverIDs.Lock()
+	defer do.serverIDs.Unlock()
+	for _, serverID := range do.serverIDs.ids {
+		err := kv.RunInNewTxn(do.store, true, func(txn kv.Transaction) error {
+			return errors.Trace(meta.NewMeta(txn).DelServerID(serverID))
+		})
+		if err != nil {
+			log.Errorf("[domain] release server ID %d fail: %v", serverID, errors.ErrorStack(err))
+		}
+	}
+	do.serverIDs.ids = nil
+}
+
+func (do *Domain) serverIDHeartbeatLoop(serverID uint64, expire int64, onLost func()) {
+	ticker := time.NewTicker(serverIDLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-do.exit:
+			return
+		case <-ticker.C:
+		}
+		newExpire := time.Now().Add(serverIDLease).UnixNano()
+		err := kv.RunInNewTxn(do.store, true, func(txn kv.Transaction) error {
+			t := meta.NewMeta(txn)
+			old, err := t.GetServerID(serverID)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if old != expire && old > time.Now().UnixNano() {
+				return errServerIDLost
+			}
+			return errors.Trace(t.SetServerID(serverID, newExpire))
+		})
+		if err != nil {
+			log.Errorf("[domain] renew server ID %d fail: %v", serverID, errors.ErrorStack(err))
+			// Another server can acquire the server ID once it expires.
+			if errors.Cause(err) == errServerIDLost || time.Now().UnixNano() >= expire {
+				log.Errorf("[domain] server ID %d is lost", serverID)
+				do.loseServerID(serverID)
+				onLost()
+				return
+			}
+			continue
+		}
+		expire = newExpire
+	}
+}
+
+// GlobalKill sends a KILL request to the server which owns the connection.
+func (do *Domain) GlobalKill(serverID uint64, connID uint64, query bool) error {
+	req, err := json.Marshal(&globalKillRequest{ConnID: connID, Query: query})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = kv.RunInNewTxn(do.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		expire, err := t.GetServerID(serverID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if expire < time.Now().UnixNano() {
+			return errors.Errorf("server %d of connection %d is not found", serverID, connID)
+		}
+		return errors.Trace(t.EnQueueGlobalKill(serverID, req))
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if do.etcdClient != nil {
+		_, err = do.etcdClient.KV.Put(goctx.Background(), globalKillKey(serverID), "")
+		if err != nil {
+			log.Warn("[domain] notify global kill failed:", err)
+		}
+	}
+	return nil
+}
+
+// GlobalKillLoop creates a goroutine which receives the KILL requests sent to the server and
+// calls kill for each of them, it should be called only once after the server ID is acquired.
+func (do *Domain) GlobalKillLoop(serverID uint64, kill func(connID uint64, query bool)) {
+	var watchCh clientv3.WatchChan
+	duration := globalKillPollInterval
+	if do.etcdClient != nil {
+		watchCh = do.etcdClient.Watch(goctx.Background(), globalKillKey(serverID))
+		duration = 10 * globalKillPollInterval
+	}
+
+	go func() {
+		var count int
+		for {
+			ok := true
+			select {
+			case <-do.exit:
+				return
+			case _, ok = <-watchCh:
+			case <-time.After(duration):
+			}
+			// The KILL requests are sent to the new owner of the server ID.
+			if !do.ownServerID(serverID) {
+				return
+			}
+			if !ok {
+				log.Error("[domain] global kill loop watch channel closed.")
+				watchCh = do.etcdClient.Watch(goctx.Background(), globalKillKey(serverID))
+				count++
+				if count > 10 {
+					time.Sleep(time.Duration(count) * time.Second)
+				}
+				continue
+			}
+
+			count = 0
+			reqs, err := do.fetchGlobalKills(serverID)
+			if err != nil {
+				log.Error("[domain] fetch global kill requests fail:", errors.ErrorStack(err))
+				continue
+			}
+			for _, req := range reqs {
+				log.Infof("[domain] kill connection %d, query %v", req.ConnID, req.Query)
+				kill(req.ConnID, req.Query)
+			}
+		}
+	}()
+}
+
+func (do *Domain) fetchGlobalKills(serverID uint64) ([]*globalKillRequest, error) {
+	var reqs []*globalKillRequest
+	err := kv.RunInNewTxn(do.store, true, func(txn kv.Transaction) error {
+		reqs = reqs[:0]
+		t := meta.NewMeta(txn)
+		for {
+			data, err := t.DeQueueGlobalKill(serverID)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if data == nil {
+				return nil
+			}
+			req := &globalKillRequest{}
+			if err = json.Unmarshal(data, req); err != nil {
+				return errors.Trace(err)
+			}
+			reqs = append(reqs, req)
+		}
+	})
+	return reqs, errors.Trace(err)
+}
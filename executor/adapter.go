@@ -27,11 +27,15 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/topsql"
 )
 
 type processinfoSetter interface {
-	SetProcessInfo(string)
+	SetProcessInfo(sql string, p plan.Plan)
 }
 
 // recordSet wraps an executor, implements ast.RecordSet interface
@@ -41,6 +45,7 @@ type recordSet struct {
 	stmt        *statement
 	processinfo processinfoSetter
 	err         error
+	closed      bool
 }
 
 func (a *recordSet) Fields() ([]*ast.ResultField, error) {
@@ -80,10 +85,15 @@ func (a *recordSet) Next() (*ast.Row, error) {
 }
 
 func (a *recordSet) Close() error {
+	// Close may be called more than once, the statement must be logged only once.
+	if a.closed {
+		return nil
+	}
+	a.closed = true
 	err := a.executor.Close()
 	a.stmt.logSlowQuery()
 	if a.processinfo != nil {
-		a.processinfo.SetProcessInfo("")
+		a.processinfo.SetProcessInfo("", nil)
 	}
 	return errors.Trace(err)
 }
@@ -98,6 +108,8 @@ type statement struct {
 	startTime      time.Time
 	isPreparedStmt bool
 	expensive      bool
+	// topSQL is the statement tracked by the top SQL collector, it's nil if top SQL is disabled.
+	topSQL *topsql.Stmt
 }
 
 func (a *statement) OriginText() string {
@@ -112,9 +124,19 @@ func (a *statement) IsPrepared() bool {
 // This function builds an Executor from a plan. If the Executor doesn't return result,
 // like the INSERT, UPDATE statements, it executes in this function, if the Executor returns
 // result, execution is done after this function returns, in the returned ast.RecordSet Next method.
-func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
+func (a *statement) Exec(ctx context.Context) (rs ast.RecordSet, err error) {
 	a.startTime = time.Now()
 	a.ctx = ctx
+	if collector := topsql.Global(); collector != nil && !ctx.GetSessionVars().InRestrictedSQL {
+		a.topSQL = collector.Begin()
+		defer func() {
+			// A returned record set finishes the statement when it's closed, on the other exit paths,
+			// a panic included, the statement is finished here so it doesn't stay running in the collector.
+			if _, ok := rs.(*recordSet); !ok || err != nil {
+				a.finishTopSQL()
+			}
+		}()
+	}
 
 	e, err := a.buildExecutor(ctx)
 	if err != nil {
@@ -129,7 +151,7 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 	if raw, ok := ctx.(processinfoSetter); ok {
 		pi = raw
 		// Update processinfo, ShowProcess() will use it.
-		pi.SetProcessInfo(a.OriginText())
+		pi.SetProcessInfo(a.OriginText(), a.plan)
 	}
 
 	// Fields or Schema are only used for statements that return result set.
@@ -157,7 +179,7 @@ func (a *statement) handleNoDelayExecutor(e Executor, ctx context.Context, pi pr
 
 	defer func() {
 		if pi != nil {
-			pi.SetProcessInfo("")
+			pi.SetProcessInfo("", nil)
 		}
 		e.Close()
 		a.logSlowQuery()
@@ -231,16 +253,45 @@ func (a *statement) buildExecutor(ctx context.Context) (Executor, error) {
 func (a *statement) logSlowQuery() {
 	cfg := config.GetGlobalConfig()
 	costTime := time.Since(a.startTime)
-	sql := a.text
-	if len(sql) > cfg.QueryLogMaxlen {
-		sql = sql[:cfg.QueryLogMaxlen] + fmt.Sprintf("(len:%d)", len(sql))
+	sql := logutil.FormatQuery(cfg, a.text)
+	a.finishTopSQL()
+	sessVars := a.ctx.GetSessionVars()
+	if l := sessVars.SQLLogger(); l != nil {
+		l.Write(a.startTime, costTime, a.text)
+	}
+	connID := sessVars.ConnectionID
+	logPrefix := fmt.Sprintf("[%d]", connID)
+	if len(sessVars.TraceID) > 0 {
+		logPrefix += fmt.Sprintf("[trace_id=%s]", sessVars.TraceID)
 	}
-	connID := a.ctx.GetSessionVars().ConnectionID
 	if costTime < time.Duration(cfg.SlowThreshold)*time.Millisecond {
-		log.Debugf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+		log.Debugf("%s[TIME_QUERY] %v %s", logPrefix, costTime, sql)
 	} else {
-		log.Warnf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+		log.Warnf("%s[TIME_QUERY] %v %s", logPrefix, costTime, sql)
+		info := &util.SlowQueryInfo{
+			ConnID:   connID,
+			DB:       sessVars.CurrentDB,
+			Start:    a.startTime,
+			Duration: costTime,
+			Query:    sql,
+			TraceID:  sessVars.TraceID,
+		}
+		if sessVars.User != nil {
+			info.User = sessVars.User.Username
+			info.Host = sessVars.User.Hostname
+		}
+		util.SlowQueries.Push(info)
+	}
+}
+
+// finishTopSQL adds the statement to the top SQL collector, it's called at most once for a statement.
+func (a *statement) finishTopSQL() {
+	if a.topSQL == nil {
+		return
 	}
+	normalized, digest := parser.NormalizeDigest(a.text)
+	a.topSQL.Finish(digest, normalized)
+	a.topSQL = nil
 }
 
 // IsPointGetWithPKOrUniqueKeyByAutoCommit returns true when meets following conditions:
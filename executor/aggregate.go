@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/mvmap"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -37,12 +38,18 @@ type HashAggExec struct {
 	groupMap      *mvmap.MVMap
 	groupIterator *mvmap.Iterator
 	GroupByItems  []expression.Expression
+
+	memTracker *memory.Tracker
 }
 
 // Close implements the Executor Close interface.
 func (e *HashAggExec) Close() error {
 	e.groupMap = nil
 	e.groupIterator = nil
+	if e.memTracker != nil {
+		e.memTracker.Detach()
+		e.memTracker = nil
+	}
 	for _, agg := range e.AggFuncs {
 		agg.Reset()
 	}
@@ -54,6 +61,11 @@ func (e *HashAggExec) Open() error {
 	e.executed = false
 	e.groupMap = mvmap.NewMVMap()
 	e.groupIterator = e.groupMap.NewIterator()
+	var err error
+	e.memTracker, err = newMemTracker(e.ctx, "HashAggExec")
+	if err != nil {
+		return errors.Trace(err)
+	}
 	return errors.Trace(e.children[0].Open())
 }
 
@@ -132,6 +144,10 @@ func (e *HashAggExec) innerNext() (ret bool, err error) {
 		return false, errors.Trace(err)
 	}
 	if e.groupMap.Get(groupKey) == nil {
+		// Every group keeps an evaluation context for each aggregate function, its size is estimated as a datum.
+		if err = e.memTracker.Consume(int64(len(groupKey)) + int64(len(e.AggFuncs))*datumMemSize); err != nil {
+			return false, errors.Trace(err)
+		}
 		e.groupMap.Put(groupKey, []byte{})
 	}
 	for _, af := range e.AggFuncs {
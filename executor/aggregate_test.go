@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -66,14 +67,13 @@ func (s *testSuite) TestAggregation(c *C) {
 	defer func() {
 		atomic.StoreInt32(&expression.TurnOnNewExprEval, origin)
 	}()
-	plan.JoinConcurrency = 1
 	defer func() {
-		plan.JoinConcurrency = 5
 		s.cleanEnv(c)
 		testleak.AfterTest(c)()
 	}()
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_hash_join_concurrency = 1")
 	tk.MustExec("drop table if exists t")
 	tk.MustExec("create table t (c int, d int)")
 	tk.MustExec("insert t values (NULL, 1)")
@@ -286,7 +286,7 @@ func (s *testSuite) TestAggregation(c *C) {
 
 	result = tk.MustQuery("select count(*) from information_schema.columns")
 	// When adding new memory table in information_schema, please update this variable.
-	columnCountOfAllInformationSchemaTables := "738"
+	columnCountOfAllInformationSchemaTables := "763"
 	result.Check(testkit.Rows(columnCountOfAllInformationSchemaTables))
 
 	tk.MustExec("drop table if exists t1")
@@ -336,6 +336,75 @@ func (s *testSuite) TestAggPrune(c *C) {
 	tk.MustQuery("select count(distinct b, c, d) from t group by id").Check(testkit.Rows("0", "0", "0", "0", "0", "0", "0", "1"))
 }
 
+func (s *testSuite) TestOnlyFullGroupBy(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int, c int)")
+	tk.MustExec("insert into t values(1, 1, 1), (1, 2, 2), (2, 3, 3)")
+
+	// Without ONLY_FULL_GROUP_BY, the nonaggregated column returns the value of an arbitrary row in the group.
+	tk.MustQuery("select a, b from t group by a").Check(testkit.Rows("1 1", "2 3"))
+	tk.MustQuery("select b, count(*) from t where a = 2").Check(testkit.Rows("3 1"))
+
+	tk.MustExec("set sql_mode = 'ONLY_FULL_GROUP_BY'")
+	_, err := tk.Exec("select a, b from t group by a")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+	c.Assert(err.Error(), Equals, "[plan:1055]Expression #2 of SELECT list is not in GROUP BY clause and contains nonaggregated column 'test.t.b' which is not functionally dependent on columns in GROUP BY clause; this is incompatible with sql_mode=only_full_group_by")
+	_, err = tk.Exec("select a, b + 1 from t group by a, c + 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select * from t group by a, b")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select b, count(*) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrMixOfGroupFuncAndFields), IsTrue, Commentf("err %v", err))
+	c.Assert(err.Error(), Equals, "[plan:1140]In aggregated query without GROUP BY, expression #1 of SELECT list contains nonaggregated column 'test.t.b'; this is incompatible with sql_mode=only_full_group_by")
+
+	// The grouped columns and expressions, aggregate functions and the outer columns are allowed.
+	tk.MustQuery("select a, sum(b), max(c) + 1 from t group by a order by a").Check(testkit.Rows("1 3 3", "2 3 4"))
+	tk.MustQuery("select t.a, a + 1 from t group by 1 order by a").Check(testkit.Rows("1 2", "2 3"))
+	tk.MustQuery("select b + c from t group by b + c order by 1").Check(testkit.Rows("2", "4", "6"))
+	tk.MustQuery("select count(*), 1 from t").Check(testkit.Rows("3 1"))
+	tk.MustQuery("select a, (select count(*) from t t1 where t1.a = t.a) from t group by a order by a").Check(testkit.Rows("1 2", "2 1"))
+	tk.MustQuery("select a from t t1 where a = (select max(a) + t1.b - t1.b from t where b > 2)").Check(testkit.Rows("2"))
+
+	// The columns functionally dependent on a grouped primary key or unique NOT NULL key are allowed.
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1(id int primary key, a int, b int)")
+	tk.MustExec("create table t2(a int not null, b int not null, c int, d int, unique key(a, b), unique key(d))")
+	tk.MustExec("insert into t1 values(1, 1, 1), (2, 1, 2)")
+	tk.MustExec("insert into t2 values(1, 1, 1, 1), (1, 2, 2, 2)")
+	tk.MustQuery("select id, a, b, count(*) from t1 group by id order by id").Check(testkit.Rows("1 1 1 1", "2 1 2 1"))
+	tk.MustQuery("select t2.a, t2.b, t2.c, t1.b from t1 join t2 on t1.id = t2.c group by t2.a, t2.b, t1.id order by t2.c").Check(testkit.Rows("1 1 1 1", "1 2 2 2"))
+	_, err = tk.Exec("select a, b, c from t2 group by a")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+	// The unique key on a nullable column doesn't determine the other columns.
+	_, err = tk.Exec("select d, c from t2 group by d")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+
+	// HAVING and ORDER BY are checked too, except the names referring to the select fields.
+	_, err = tk.Exec("select a from t group by a order by a, c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+	c.Assert(err.Error(), Equals, "[plan:1055]Expression #2 of ORDER BY clause is not in GROUP BY clause and contains nonaggregated column 'test.t.c' which is not functionally dependent on columns in GROUP BY clause; this is incompatible with sql_mode=only_full_group_by")
+	_, err = tk.Exec("select count(*) from t order by b")
+	c.Assert(terror.ErrorEqual(err, plan.ErrMixOfGroupFuncAndFields), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select a, sum(b) as s from t group by a having s > 2 and a > 0 order by s, max(c), a + 1").Check(testkit.Rows("1 3", "2 3"))
+	tk.MustQuery("select a + 1 as x, count(*) from t group by a + 1 having x > 2 order by a + 1").Check(testkit.Rows("3 1"))
+	tk.MustQuery("select id, count(*) from t1 group by id having max(b) > 1 order by a, b").Check(testkit.Rows("2 1"))
+	_, err = tk.Exec("select a, count(*) from t1 group by a order by b")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+
+	// ANY_VALUE suppresses the check and returns the value of an arbitrary row in the group.
+	tk.MustQuery("select a, any_value(b) from t where c < 3 group by a").Check(testkit.Rows("1 1"))
+	tk.MustQuery("select a, any_value(b) + any_value(c), count(*) from t group by a order by a").Check(testkit.Rows("1 2 2", "2 6 1"))
+	tk.MustQuery("select any_value(b), count(*) from t where a = 2").Check(testkit.Rows("3 1"))
+	_, err = tk.Exec("select a, any_value(b) + c from t group by a")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldNotInGroupBy), IsTrue, Commentf("err %v", err))
+}
+
 func (s *testSuite) TestSelectDistinct(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -367,3 +436,40 @@ func (s *testSuite) TestAggPushDown(c *C) {
 	tk.MustQuery("select max(a.b), max(b.b) from t a join tt b on a.a = b.a group by a.c").Check(testkit.Rows("1 2"))
 	tk.MustQuery("select a, count(b) from (select * from t union all select * from tt) k group by a").Check(testkit.Rows("1 2", "2 1"))
 }
+
+func (s *testSuite) TestBitAggregation(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key, g int, a int, b bigint unsigned)")
+
+	// An empty table gets the identity values.
+	tk.MustQuery("select bit_and(a), bit_or(a), bit_xor(a) from t").Check(testkit.Rows("18446744073709551615 0 0"))
+
+	tk.MustExec("insert into t values (1, 1, 12, 1), (2, 1, 10, 2), (3, 1, 7, NULL), (4, 2, 5, 18446744073709551615), (5, 3, NULL, NULL), (6, 4, -1, 3), (7, 4, 1, 3)")
+	tk.MustQuery("select bit_and(a), bit_or(a), bit_xor(a) from t where id <= 3").Check(testkit.Rows("0 15 1"))
+	tk.MustQuery("select bit_and(a), bit_or(a), bit_xor(a) from t where id > 100").Check(testkit.Rows("18446744073709551615 0 0"))
+
+	// Groups with a single row, only NULL values and negative values.
+	tk.MustQuery("select g, bit_and(a), bit_or(a), bit_xor(a) from t group by g order by g").Check(testkit.Rows(
+		"1 0 15 1",
+		"2 5 5 5",
+		"3 18446744073709551615 0 0",
+		"4 1 18446744073709551615 18446744073709551614"))
+	tk.MustQuery("select g, bit_and(b), bit_or(b), bit_xor(b) from t group by g order by g").Check(testkit.Rows(
+		"1 0 3 3",
+		"2 18446744073709551615 18446744073709551615 18446744073709551615",
+		"3 18446744073709551615 0 0",
+		"4 3 3 0"))
+
+	// Non-integer arguments are rounded to integers.
+	tk.MustQuery("select bit_or(1.5), bit_and('3'), bit_xor(2.4) from t where id = 1").Check(testkit.Rows("2 3 2"))
+
+	// The functions are also evaluated by the stream aggregation over the ordered handle.
+	tk.MustQuery("select id, bit_or(a) from t group by id order by id limit 3").Check(testkit.Rows("1 12", "2 10", "3 7"))
+	tk.MustQuery("select bit_xor(a) from t group by g having bit_and(a) = 5").Check(testkit.Rows("5"))
+}
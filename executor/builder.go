@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
@@ -70,6 +71,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildExecute(v)
 	case *plan.Explain:
 		return b.buildExplain(v)
+	case *plan.RecommendIndex:
+		return b.buildRecommendIndex(v)
 	case *plan.Insert:
 		return b.buildInsert(v)
 	case *plan.LoadData:
@@ -82,6 +85,12 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildSelectLock(v)
 	case *plan.ShowDDL:
 		return b.buildShowDDL(v)
+	case *plan.ShowIndexUsage:
+		return b.buildShowIndexUsage(v)
+	case *plan.ShowTopSQL:
+		return b.buildShowTopSQL(v)
+	case *plan.ShowSlow:
+		return b.buildShowSlow(v)
 	case *plan.Show:
 		return b.buildShow(v)
 	case *plan.Simple:
@@ -126,6 +135,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildExists(v)
 	case *plan.MaxOneRow:
 		return b.buildMaxOneRow(v)
+	case *plan.Window:
+		return b.buildWindow(v)
 	case *plan.Cache:
 		return b.buildCache(v)
 	case *plan.Analyze:
@@ -170,6 +181,29 @@ func (b *executorBuilder) buildShowDDL(v *plan.ShowDDL) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildShowIndexUsage(v *plan.ShowIndexUsage) Executor {
+	return &ShowIndexUsageExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		is:           b.is,
+	}
+}
+
+func (b *executorBuilder) buildShowTopSQL(v *plan.ShowTopSQL) Executor {
+	return &ShowTopSQLExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		byTime:       v.ByTime,
+		count:        v.Count,
+	}
+}
+
+func (b *executorBuilder) buildShowSlow(v *plan.ShowSlow) Executor {
+	return &ShowSlowExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		top:          v.Top,
+		count:        v.Count,
+	}
+}
+
 func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	return &CheckTableExec{
 		tables: v.Tables,
@@ -275,6 +309,8 @@ func (b *executorBuilder) buildInsert(v *plan.Insert) Executor {
 		Columns: v.Columns,
 		Lists:   v.Lists,
 		Setlist: v.Setlist,
+
+		CheckConstraints: v.CheckConstraints,
 	}
 	if len(v.Children()) > 0 {
 		ivs.SelectExec = b.build(v.Children()[0])
@@ -298,7 +334,7 @@ func (b *executorBuilder) buildLoadData(v *plan.LoadData) Executor {
 		b.err = errors.Errorf("Can not get table %d", v.Table.TableInfo.ID)
 		return nil
 	}
-	insertVal := &InsertValues{ctx: b.ctx, Table: tbl, Columns: v.Columns}
+	insertVal := &InsertValues{ctx: b.ctx, Table: tbl, Columns: v.Columns, CheckConstraints: v.CheckConstraints}
 	tableCols := tbl.Cols()
 	columns, err := insertVal.getColumns(tableCols)
 	if err != nil {
@@ -351,7 +387,11 @@ func (b *executorBuilder) buildRevoke(revoke *ast.RevokeStmt) Executor {
 }
 
 func (b *executorBuilder) buildDDL(v *plan.DDL) Executor {
-	return &DDLExec{Statement: v.Statement, ctx: b.ctx, is: b.is}
+	e := &DDLExec{Statement: v.Statement, ctx: b.ctx, is: b.is}
+	if v.SelectPlan != nil {
+		e.selectExec = b.build(v.SelectPlan)
+	}
+	return e
 }
 
 func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
@@ -365,6 +405,19 @@ func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
 	return exec
 }
 
+// buildRecommendIndex builds an ExplainExec because the recommendations are made by the planner,
+// the executor only returns the rows.
+func (b *executorBuilder) buildRecommendIndex(v *plan.RecommendIndex) Executor {
+	exec := &ExplainExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+	}
+	exec.rows = make([]Row, 0, len(v.Rows))
+	for _, row := range v.Rows {
+		exec.rows = append(exec.rows, row)
+	}
+	return exec
+}
+
 func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor {
 	src := b.build(v.Children()[0])
 	if b.err != nil {
@@ -581,10 +634,14 @@ func (b *executorBuilder) buildSelection(v *plan.Selection) Executor {
 }
 
 func (b *executorBuilder) buildProjection(v *plan.Projection) Executor {
-	return &ProjectionExec{
+	e := &ProjectionExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
 		exprs:        v.Exprs,
 	}
+	if canEvalInParallel(v.Exprs) {
+		e.concurrency = b.ctx.GetSessionVars().ProjectionConcurrency
+	}
+	return e
 }
 
 func (b *executorBuilder) buildTableDual(v *plan.TableDual) Executor {
@@ -595,7 +652,10 @@ func (b *executorBuilder) buildTableDual(v *plan.TableDual) Executor {
 }
 
 func (b *executorBuilder) getStartTS() uint64 {
-	startTS := b.ctx.GetSessionVars().SnapshotTS
+	startTS := b.ctx.GetSessionVars().StmtCtx.SnapshotTS
+	if startTS == 0 {
+		startTS = b.ctx.GetSessionVars().SnapshotTS
+	}
 	if startTS == 0 {
 		startTS = b.ctx.Txn().StartTS()
 	}
@@ -656,6 +716,7 @@ func (b *executorBuilder) buildIndexScan(v *plan.PhysicalIndexScan) Executor {
 	if b.err != nil {
 		return nil
 	}
+	b.recordIndexUsage(v.Table.ID, v.Index.ID)
 	table, _ := b.is.TableByID(v.Table.ID)
 	client := b.ctx.GetClient()
 	supportDesc := client.IsRequestTypeSupported(kv.ReqTypeIndex, kv.ReqSubTypeDesc)
@@ -716,6 +777,16 @@ func (b *executorBuilder) buildIndexScan(v *plan.PhysicalIndexScan) Executor {
 	return e
 }
 
+// recordIndexUsage counts the access to the index for ADMIN SHOW INDEX USAGE. The internal SQL isn't counted.
+func (b *executorBuilder) recordIndexUsage(tableID, indexID int64) {
+	if b.ctx.GetSessionVars().InRestrictedSQL {
+		return
+	}
+	if dom := sessionctx.GetDomain(b.ctx); dom != nil && dom.StatsHandle() != nil {
+		dom.StatsHandle().RecordIndexUsage(tableID, indexID)
+	}
+}
+
 func (b *executorBuilder) buildSort(v *plan.Sort) Executor {
 	sortExec := SortExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -731,6 +802,22 @@ func (b *executorBuilder) buildSort(v *plan.Sort) Executor {
 	return &sortExec
 }
 
+func (b *executorBuilder) buildWindow(v *plan.Window) Executor {
+	e := &WindowExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
+		Name:         v.Name,
+		PartitionBy:  v.PartitionBy,
+		OrderBy:      v.OrderBy,
+		Frame:        v.Frame,
+	}
+	switch v.Name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank:
+	default:
+		e.AggFunc = expression.NewAggFunction(v.Name, v.Args, false)
+	}
+	return e
+}
+
 func (b *executorBuilder) buildTopN(v *plan.TopN) Executor {
 	sortExec := SortExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -831,6 +918,8 @@ func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
 		SelectExec:   b.build(v.Children()[0]),
 		OrderedList:  v.OrderedList,
 		tblID2table:  tblID2table,
+
+		CheckConstraints: v.CheckConstraints,
 	}
 }
 
@@ -1073,6 +1162,7 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 		return nil
 	}
 	is := v.IndexPlans[0].(*plan.PhysicalIndexScan)
+	b.recordIndexUsage(is.Table.ID, is.Index.ID)
 	table, _ := b.is.TableByID(is.Table.ID)
 	var handleCol *expression.Column
 	if v.NeedColHandle {
@@ -1092,6 +1182,11 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 		handleCol: handleCol,
 		priority:  b.priority,
 	}
+	// Only a plain index scan returns all the rows in the ranges, the feedback is meaningless if
+	// some rows are filtered or aggregated in coprocessor.
+	if b.ctx.GetSessionVars().EnableStatsFeedback && len(v.IndexPlans) == 1 {
+		e.feedback = statistics.NewQueryFeedback(is.Table.ID, is.Index.ID, is.Ranges)
+	}
 
 	for _, col := range v.OutputColumns {
 		// If it's ID is ExtraHandleID, then it must is the tail of the slice.
@@ -1114,6 +1209,7 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 		return nil
 	}
 	is := v.IndexPlans[0].(*plan.PhysicalIndexScan)
+	b.recordIndexUsage(is.Table.ID, is.Index.ID)
 	table, _ := b.is.TableByID(is.Table.ID)
 	var handleCol *expression.Column
 	if v.NeedColHandle {
@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
 )
 
 // Compiler compiles an ast.StmtNode to a stmt.Statement.
@@ -30,7 +31,10 @@ type Compiler struct {
 // After preprocessed and validated, it will be optimized to a plan,
 // then wrappped to an adapter *statement as stmt.Statement.
 func (c *Compiler) Compile(ctx context.Context, node ast.StmtNode) (ast.Statement, error) {
-	is := GetInfoSchema(ctx)
+	is, err := getStmtInfoSchema(ctx, node)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	if err := plan.Preprocess(node, is, ctx); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -54,6 +58,21 @@ func (c *Compiler) Compile(ctx context.Context, node ast.StmtNode) (ast.Statemen
 	return sa, nil
 }
 
+// getStmtInfoSchema gets the InfoSchema to compile the statement with. If the statement reads the historical data by
+// the AS OF TIMESTAMP clause, the timestamp is set to StmtCtx.SnapshotTS and the InfoSchema at it is returned.
+func getStmtInfoSchema(ctx context.Context, node ast.StmtNode) (infoschema.InfoSchema, error) {
+	ts, err := getAsOfTS(ctx, node)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if ts == 0 {
+		return GetInfoSchema(ctx), nil
+	}
+	ctx.GetSessionVars().StmtCtx.SnapshotTS = ts
+	is, err := sessionctx.GetDomain(ctx).GetSnapshotInfoSchema(ts)
+	return is, errors.Trace(err)
+}
+
 // GetInfoSchema gets TxnCtx InfoSchema if snapshot schema is not set,
 // Otherwise, snapshot schema is returned.
 func GetInfoSchema(ctx context.Context) infoschema.InfoSchema {
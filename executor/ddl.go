@@ -17,15 +17,19 @@ import (
 	"strings"
 
 	"github.com/juju/errors"
+	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -36,6 +40,8 @@ type DDLExec struct {
 	ctx       context.Context
 	is        infoschema.InfoSchema
 	done      bool
+	// selectExec is the executor of the query in CREATE TABLE ... SELECT.
+	selectExec Executor
 }
 
 // Schema implements the Executor Schema interface.
@@ -102,9 +108,15 @@ func (e *DDLExec) executeTruncateTable(s *ast.TruncateTableStmt) error {
 }
 
 func (e *DDLExec) executeRenameTable(s *ast.RenameTableStmt) error {
-	if len(s.TableToTables) != 1 {
-		// Now we only allow one schema changing at the same time.
-		return errors.Errorf("can't run multi schema change")
+	if len(s.TableToTables) > 1 {
+		oldIdents := make([]ast.Ident, 0, len(s.TableToTables))
+		newIdents := make([]ast.Ident, 0, len(s.TableToTables))
+		for _, t := range s.TableToTables {
+			oldIdents = append(oldIdents, ast.Ident{Schema: t.OldTable.Schema, Name: t.OldTable.Name})
+			newIdents = append(newIdents, ast.Ident{Schema: t.NewTable.Schema, Name: t.NewTable.Name})
+		}
+		err := sessionctx.GetDomain(e.ctx).DDL().RenameTables(e.ctx, oldIdents, newIdents)
+		return errors.Trace(err)
 	}
 	oldIdent := ast.Ident{Schema: s.OldTable.Schema, Name: s.OldTable.Name}
 	newIdent := ast.Ident{Schema: s.NewTable.Schema, Name: s.NewTable.Name}
@@ -135,6 +147,9 @@ func (e *DDLExec) executeCreateDatabase(s *ast.CreateDatabaseStmt) error {
 }
 
 func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
+	if s.Select != nil {
+		return e.executeCreateTableSelect(s)
+	}
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	var err error
 	if s.ReferTable == nil {
@@ -152,6 +167,159 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	return errors.Trace(err)
 }
 
+// executeCreateTableSelect creates the table with the defined columns and the columns inferred from the query,
+// then fills it with the query result.
+func (e *DDLExec) executeCreateTableSelect(s *ast.CreateTableStmt) error {
+	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
+	dom := sessionctx.GetDomain(e.ctx)
+	colDefs := mergeColumnDefs(s.Cols, columnDefsFromSchema(e.selectExec.Schema()))
+	err := dom.DDL().CreateTable(e.ctx, ident, colDefs, s.Constraints, s.Options)
+	if infoschema.ErrTableExists.Equal(err) {
+		if s.IfNotExists {
+			return nil
+		}
+		return err
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	is := dom.InfoSchema()
+	tbl, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The rows are written in new transactions, they must pass the schema check with the created table.
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	txnCtx.InfoSchema = is
+	txnCtx.SchemaVersion = is.SchemaMetaVersion()
+	err = e.fillTableBySelect(tbl)
+	if err != nil {
+		// Don't leave a partially filled table behind.
+		if dropErr := dom.DDL().DropTable(e.ctx, ident); dropErr != nil {
+			log.Errorf("[ddl] drop table %s after create table ... select failed: %v", ident, errors.ErrorStack(dropErr))
+		}
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// fillTableBySelect inserts the result of the select executor into the columns of tbl with the same names,
+// the other columns get their default values. The rows are committed every BatchInsertSize rows.
+func (e *DDLExec) fillTableBySelect(tbl table.Table) error {
+	if err := e.ctx.NewTxn(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := e.selectExec.Open(); err != nil {
+		return errors.Trace(err)
+	}
+	defer e.selectExec.Close()
+
+	checks, err := plan.BuildCheckConstraints(e.ctx, tbl.Meta())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ivs := &InsertValues{ctx: e.ctx, Table: tbl, CheckConstraints: checks}
+	cols := make([]*table.Column, 0, e.selectExec.Schema().Len())
+	for _, col := range e.selectExec.Schema().Columns {
+		cols = append(cols, table.FindCol(tbl.Cols(), col.ColName.O))
+	}
+	rowCount := 0
+	for {
+		innerRow, err := e.selectExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if innerRow == nil {
+			return nil
+		}
+		if rowCount >= BatchInsertSize {
+			if err = e.ctx.NewTxn(); err != nil {
+				return errors.Trace(err)
+			}
+			rowCount = 0
+		}
+		row, err := ivs.fillRowData(cols, innerRow, false)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = checkConstraints(e.ctx, ivs.CheckConstraints, row); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err = tbl.AddRecord(e.ctx, row); err != nil {
+			return errors.Trace(err)
+		}
+		rowCount++
+	}
+}
+
+// columnDefsFromSchema infers the column definitions of CREATE TABLE ... SELECT from the query schema.
+// Only the types and the unsigned and binary flags are kept, so the columns are nullable and have no default value.
+func columnDefsFromSchema(schema *expression.Schema) []*ast.ColumnDef {
+	colDefs := make([]*ast.ColumnDef, 0, schema.Len())
+	for _, col := range schema.Columns {
+		tp := *col.RetType
+		tp.Flag &= mysql.UnsignedFlag | mysql.BinaryFlag
+		if !charset.ValidCharsetAndCollation(tp.Charset, tp.Collate) {
+			// The collation of an expression result may be unset or not accurate, use the default one.
+			tp.Collate = ""
+		}
+		switch tp.Tp {
+		case mysql.TypeNull:
+			tp = *types.NewFieldType(mysql.TypeString)
+			tp.Flen = 0
+			tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+			tp.Flag |= mysql.BinaryFlag
+		case mysql.TypeVarString, mysql.TypeVarchar:
+			tp.Tp = mysql.TypeVarchar
+			if tp.Flen == types.UnspecifiedLength {
+				tp.Tp = mysql.TypeLongBlob
+			}
+		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+			tp.Decimal = types.UnspecifiedLength
+		case mysql.TypeNewDecimal:
+			if tp.Flen == types.UnspecifiedLength {
+				tp.Flen = mysql.MaxDecimalWidth
+			}
+			if tp.Decimal == types.UnspecifiedLength {
+				tp.Decimal = mysql.MaxDecimalScale
+			}
+		}
+		colDefs = append(colDefs, &ast.ColumnDef{Name: &ast.ColumnName{Name: col.ColName}, Tp: &tp})
+	}
+	return colDefs
+}
+
+// mergeColumnDefs merges the column definitions of CREATE TABLE ... SELECT with the ones inferred from the query like MySQL:
+// the defined columns not in the query come first, a query column with the name of a defined column takes its definition.
+func mergeColumnDefs(defined, selected []*ast.ColumnDef) []*ast.ColumnDef {
+	if len(defined) == 0 {
+		return selected
+	}
+	colDefs := make([]*ast.ColumnDef, 0, len(defined)+len(selected))
+	for _, def := range defined {
+		if findColumnDef(selected, def.Name.Name.L) == nil {
+			colDefs = append(colDefs, def)
+		}
+	}
+	for _, sel := range selected {
+		if def := findColumnDef(defined, sel.Name.Name.L); def != nil {
+			sel = def
+		}
+		colDefs = append(colDefs, sel)
+	}
+	return colDefs
+}
+
+func findColumnDef(colDefs []*ast.ColumnDef, name string) *ast.ColumnDef {
+	for _, colDef := range colDefs {
+		if colDef.Name.Name.L == name {
+			return colDef
+		}
+	}
+	return nil
+}
+
 func (e *DDLExec) executeCreateIndex(s *ast.CreateIndexStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().CreateIndex(e.ctx, ident, s.Unique, model.NewCIStr(s.IndexName), s.IndexColNames, s.IndexOption)
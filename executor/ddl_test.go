@@ -18,6 +18,8 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
@@ -109,6 +111,72 @@ func (s *testSuite) TestCreateTable(c *C) {
 	r.Check(testkit.Rows("1000 aa"))
 }
 
+func (s *testSuite) TestCreateTableSelect(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table src (a int unsigned not null, b varchar(10), c decimal(5, 2), d datetime, primary key(a))")
+	tk.MustExec(`insert into src values (1, "a", 1.1, "2017-01-01 00:00:00"), (2, "b", 2.2, null), (3, null, null, null)`)
+
+	tk.MustExec("create table t1 select * from src")
+	tk.MustQuery("show create table t1").Check(testkit.Rows("t1 CREATE TABLE `t1` (\n" +
+		"  `a` int(11) UNSIGNED DEFAULT NULL,\n" +
+		"  `b` varchar(10) DEFAULT NULL,\n" +
+		"  `c` decimal(5,2) DEFAULT NULL,\n" +
+		"  `d` datetime DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 a 1.10 2017-01-01 00:00:00", "2 b 2.20 <nil>", "3 <nil> <nil> <nil>"))
+
+	tk.MustExec("create table t2 as select a + 1 as x, concat(b, 'x') y, count(*) from src where a > 1 group by a")
+	tk.MustQuery("show create table t2").Check(testkit.Rows("t2 CREATE TABLE `t2` (\n" +
+		"  `x` bigint(20) UNSIGNED DEFAULT NULL,\n" +
+		"  `y` varchar(11) DEFAULT NULL,\n" +
+		"  `count(*)` bigint(21) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	tk.MustQuery("select * from t2").Check(testkit.Rows("3 bx 1", "4 <nil> 1"))
+
+	// The rows are inserted in batches.
+	defer func(size int) { executor.BatchInsertSize = size }(executor.BatchInsertSize)
+	executor.BatchInsertSize = 2
+	tk.MustExec("create table t3 as select a from src union all select a + 10 from src")
+	tk.MustQuery("select a from t3 order by a").Check(testkit.Rows("1", "2", "3", "11", "12", "13"))
+
+	_, err := tk.Exec("create table t3 select * from src")
+	c.Assert(infoschema.ErrTableExists.Equal(err), IsTrue)
+	tk.MustExec("create table if not exists t3 select * from src")
+	tk.MustQuery("select count(*) from t3").Check(testkit.Rows("6"))
+
+	_, err = tk.Exec("create table t4 select b, b from src")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table t4 select a from not_exist")
+	c.Assert(infoschema.ErrTableNotExists.Equal(err), IsTrue)
+
+	// A failed insert doesn't leave the table behind.
+	_, err = tk.Exec("create table t4 select cast(b as json) from src")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from t4")
+	c.Assert(infoschema.ErrTableNotExists.Equal(err), IsTrue)
+
+	// The defined columns not in the query come first, a query column takes the definition with its name.
+	tk.MustExec("create table t5 (id int not null auto_increment, b char(5) not null default 'x', primary key (id), key k (b)) " +
+		"comment 'ctas' as select b, a from src where b is not null")
+	tk.MustQuery("show create table t5").Check(testkit.Rows("t5 CREATE TABLE `t5` (\n" +
+		"  `id` int(11) NOT NULL AUTO_INCREMENT,\n" +
+		"  `b` char(5) NOT NULL DEFAULT 'x',\n" +
+		"  `a` bigint(20) UNSIGNED DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `k` (`b`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin COMMENT='ctas'"))
+	tk.MustQuery("select * from t5").Check(testkit.Rows("1 a 1", "2 b 2"))
+	_, err = tk.Exec("create table t6 (b int not null) select b from src")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from t6")
+	c.Assert(infoschema.ErrTableNotExists.Equal(err), IsTrue)
+}
+
 func (s *testSuite) TestCreateDropDatabase(c *C) {
 	defer testleak.AfterTest(c)()
 	tk := testkit.NewTestKit(c, s.store)
@@ -198,8 +266,10 @@ func (s *testSuite) TestAlterTableModifyColumn(c *C) {
 	_, err = tk.Exec("alter table mc modify column c2 blob")
 	c.Assert(err, NotNil)
 
+	tk.MustExec("insert into mc values (1, 'abcdefghi')")
 	_, err = tk.Exec("alter table mc modify column c2 varchar(8)")
 	c.Assert(err, NotNil)
+	tk.MustExec("delete from mc")
 	tk.MustExec("alter table mc modify column c2 varchar(11)")
 	tk.MustExec("alter table mc modify column c2 text(13)")
 	tk.MustExec("alter table mc modify column c2 text")
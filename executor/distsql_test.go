@@ -23,9 +23,11 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/store/tikv"
+	mocktikv "github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/testkit"
 )
@@ -133,3 +135,105 @@ func (s *testSuite) TestCopClientSend(c *C) {
 	keyword := "(*copIterator).work"
 	c.Check(checkGoroutineExists(keyword), IsFalse)
 }
+
+func (s *testSuite) TestMaxScanRegions(c *C) {
+	if _, ok := s.store.GetClient().(*tikv.CopClient); !ok {
+		// Make sure the store is tikv store.
+		return
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table scan_regions (id int primary key)")
+	var values []string
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d)", i))
+	}
+	tk.MustExec("insert scan_regions values " + strings.Join(values, ","))
+	dom := sessionctx.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("scan_regions"))
+	c.Assert(err, IsNil)
+	s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 10)
+
+	defer func(old int) { tikv.MaxScanRegions = old }(tikv.MaxScanRegions)
+	tikv.MaxScanRegions = 5
+	// The query over the whole table scans all the 10 regions.
+	rs, err := tk.Exec("select count(*) from scan_regions")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(kv.ErrTooManyScanRegions.Equal(err), IsTrue, Commentf("err %v", err))
+	rs.Close()
+	tk.MustQuery("select count(*) from scan_regions where id < 20").Check(testkit.Rows("20"))
+
+	tikv.MaxScanRegions = 0
+	tk.MustQuery("select count(*) from scan_regions").Check(testkit.Rows("100"))
+}
+
+func (s *testSuite) TestShardRowIDBits(c *C) {
+	if _, ok := s.store.GetClient().(*tikv.CopClient); !ok {
+		// Make sure the store is tikv store.
+		return
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table shard_t (a int, b int) shard_row_id_bits = 4 pre_split_regions = 2")
+	tk.MustQuery("show create table shard_t").Check(testkit.Rows("shard_t CREATE TABLE `shard_t` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` int(11) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin /*T! SHARD_ROW_ID_BITS=4 PRE_SPLIT_REGIONS=2 */"))
+	// TiDB parses the options in the comment.
+	tk.MustExec("create table shard_t2 (a int) /*T! SHARD_ROW_ID_BITS=3 */")
+	tk.MustQuery("show create table shard_t2").Check(testkit.Rows("shard_t2 CREATE TABLE `shard_t2` (\n" +
+		"  `a` int(11) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin /*T! SHARD_ROW_ID_BITS=3 */"))
+	tk.MustExec("drop table shard_t2")
+	dom := sessionctx.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("shard_t"))
+	c.Assert(err, IsNil)
+	tblID := tbl.Meta().ID
+
+	// The record range of the table is split into 2^2 regions at the highest 2 shard bits.
+	regionIDs := make(map[uint64]struct{})
+	for i := int64(0); i < 8; i++ {
+		key := tablecodec.EncodeRowKeyWithHandle(tblID, i<<60)
+		region, _ := s.cluster.GetRegionByKey(mocktikv.NewMvccKey(key))
+		c.Assert(region, NotNil)
+		regionIDs[region.GetId()] = struct{}{}
+	}
+	c.Assert(regionIDs, HasLen, 4)
+
+	for i := 0; i < 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert shard_t values (%d, %d)", i, i))
+	}
+	tk.MustQuery("select count(*), sum(a) from shard_t").Check(testkit.Rows("100 4950"))
+	tk.MustQuery("select b from shard_t where a = 10").Check(testkit.Rows("10"))
+	tk.MustExec("update shard_t set b = b + 1 where a < 50")
+	tk.MustExec("delete from shard_t where a >= 90")
+	tk.MustQuery("select count(*), sum(b) from shard_t").Check(testkit.Rows("90 4055"))
+
+	// The rows inserted by different transactions are scattered over the shards.
+	shards := make(map[int64]struct{})
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	prefix := tablecodec.GenTableRecordPrefix(tblID)
+	it, err := txn.Seek(prefix)
+	c.Assert(err, IsNil)
+	for it.Valid() && it.Key().HasPrefix(prefix) {
+		_, h, err := tablecodec.DecodeRecordKey(it.Key())
+		c.Assert(err, IsNil)
+		c.Assert(h, Greater, int64(0))
+		shards[h>>59] = struct{}{}
+		c.Assert(it.Next(), IsNil)
+	}
+	it.Close()
+	txn.Rollback()
+	c.Assert(len(shards), Greater, 1)
+
+	_, err = tk.Exec("create table shard_t1 (a int primary key) shard_row_id_bits = 4")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table shard_t1 (a int) shard_row_id_bits = 16")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table shard_t1 (a int) shard_row_id_bits = 2 pre_split_regions = 3")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table shard_t1 (a int) pre_split_regions = 3")
+	c.Assert(err, NotNil)
+}
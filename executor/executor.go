@@ -14,8 +14,12 @@
 package executor
 
 import (
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+	"unsafe"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -27,10 +31,14 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/topsql"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -45,7 +53,11 @@ var (
 	_ Executor = &SelectionExec{}
 	_ Executor = &SelectLockExec{}
 	_ Executor = &ShowDDLExec{}
+	_ Executor = &ShowIndexUsageExec{}
+	_ Executor = &ShowTopSQLExec{}
+	_ Executor = &ShowSlowExec{}
 	_ Executor = &SortExec{}
+	_ Executor = &WindowExec{}
 	_ Executor = &StreamAggExec{}
 	_ Executor = &TableDualExec{}
 	_ Executor = &TableScanExec{}
@@ -67,6 +79,17 @@ var (
 	ErrBuildExecutor        = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
 	ErrBatchInsertFail      = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
 	ErrWrongValueCountOnRow = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrInvalidDigest        = terror.ClassExecutor.New(codeInvalidDigest, "Invalid statement digest '%s'")
+	ErrInvalidAsOfTS        = terror.ClassExecutor.New(codeInvalidAsOfTS, "Invalid AS OF TIMESTAMP: %s")
+	ErrAsOfNotSupported     = terror.ClassExecutor.New(codeAsOfNotSupported, "AS OF TIMESTAMP is not supported %s")
+	ErrNoAutoIncrement      = terror.ClassExecutor.New(codeNoAutoIncrement, "Table '%s' has no auto_increment column")
+	ErrEmptyPassword        = terror.ClassExecutor.New(codeNotValidPassword, "Your password does not satisfy the current policy requirements, the empty password is disallowed")
+	ErrTopSQLDisabled       = terror.ClassExecutor.New(codeTopSQLDisabled, "Top SQL is disabled, start the server with --enable-top-sql")
+	ErrSQLLogDisabled       = terror.ClassExecutor.New(codeSQLLogDisabled, "ADMIN ENABLE LOG is disabled, start the server with --sql-log-dir")
+	ErrSQLLogFile           = terror.ClassExecutor.New(codeSQLLogFile, "The log file '%s' is not in the directory '%s'")
+	ErrAdminCheckTable      = terror.ClassExecutor.New(codeAdminCheckTable, "Table '%s' index '%s' is inconsistent with the data: %s")
+	ErrSavepointNotExists   = terror.ClassExecutor.New(codeSavepointNotExists, "SAVEPOINT %s does not exist")
+	ErrPsManyParam          = terror.ClassExecutor.New(codePsManyParam, "Prepared statement contains too many placeholders, the limit is %d")
 )
 
 // Error codes.
@@ -81,9 +104,20 @@ const (
 	codeResultIsEmpty        terror.ErrCode = 8
 	codeErrBuildExec         terror.ErrCode = 9
 	codeBatchInsertFail      terror.ErrCode = 10
+	codeInvalidDigest        terror.ErrCode = 11
+	codeInvalidAsOfTS        terror.ErrCode = 12
+	codeAsOfNotSupported     terror.ErrCode = 13
+	codeNoAutoIncrement      terror.ErrCode = 14
+	codeTopSQLDisabled       terror.ErrCode = 15
+	codeAdminCheckTable      terror.ErrCode = 16
+	codeSQLLogDisabled       terror.ErrCode = 17
+	codeSQLLogFile           terror.ErrCode = 18
 	CodePasswordNoMatch      terror.ErrCode = 1133 // MySQL error code
 	CodeCannotUser           terror.ErrCode = 1396 // MySQL error code
 	codeWrongValueCountOnRow terror.ErrCode = 1136 // MySQL error code
+	codeNotValidPassword     terror.ErrCode = 1819 // MySQL error code
+	codeSavepointNotExists   terror.ErrCode = 1305 // MySQL error code
+	codePsManyParam          terror.ErrCode = 1390 // MySQL error code
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -97,6 +131,31 @@ const (
 // Otherwise the executor's returned rows don't need to store the handle information.
 type Row []types.Datum
 
+// datumMemSize is the memory consumed by a Datum struct, not including the data it refers to.
+var datumMemSize = int64(unsafe.Sizeof(types.Datum{}))
+
+// memUsage returns the estimated memory consumed by the row.
+func (row Row) memUsage() int64 {
+	usage := int64(len(row)) * datumMemSize
+	for _, d := range row {
+		usage += int64(len(d.GetBytes()))
+	}
+	return usage
+}
+
+// newMemTracker creates a memory tracker for an executor, and attaches it to the tracker of the current statement.
+// It returns memory.ErrServerBusy if the server is shedding load and the statement hasn't consumed any memory yet,
+// so the new memory-intensive statements are rejected while the running ones can go on.
+func newMemTracker(ctx context.Context, label string) (*memory.Tracker, error) {
+	stmtTracker := ctx.GetSessionVars().StmtCtx.MemTracker
+	if memory.GlobalTracker().Shedding() && (stmtTracker == nil || stmtTracker.BytesConsumed() == 0) {
+		return nil, memory.ErrServerBusy
+	}
+	tracker := memory.NewTracker(label)
+	tracker.AttachTo(stmtTracker)
+	return tracker, nil
+}
+
 type baseExecutor struct {
 	children []Executor
 	ctx      context.Context
@@ -181,6 +240,157 @@ func (e *ShowDDLExec) Next() (Row, error) {
 	return row, nil
 }
 
+// ShowIndexUsageExec represents a show index usage executor.
+// It returns the access count and the last access time of every index, the indices which are
+// never used have zero access count and NULL last access time.
+type ShowIndexUsageExec struct {
+	baseExecutor
+
+	is     infoschema.InfoSchema
+	rows   []Row
+	cursor int
+	done   bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ShowIndexUsageExec) Next() (Row, error) {
+	if !e.done {
+		e.fetchAll()
+		e.done = true
+	}
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+func (e *ShowIndexUsageExec) fetchAll() {
+	h := sessionctx.GetDomain(e.ctx).StatsHandle()
+	if h == nil {
+		return
+	}
+	dbs := e.is.AllSchemaNames()
+	sort.Strings(dbs)
+	for _, db := range dbs {
+		if infoschema.IsMemoryDB(strings.ToLower(db)) {
+			continue
+		}
+		tables := e.is.SchemaTables(model.NewCIStr(db))
+		sort.Slice(tables, func(i, j int) bool {
+			return tables[i].Meta().Name.L < tables[j].Meta().Name.L
+		})
+		for _, tbl := range tables {
+			tblInfo := tbl.Meta()
+			for _, idx := range tblInfo.Indices {
+				if idx.State != model.StatePublic {
+					continue
+				}
+				usage := h.GetIndexUsage(tblInfo.ID, idx.ID)
+				row := types.MakeDatums(db, tblInfo.Name.O, idx.Name.O, usage.AccessCount, nil)
+				if !usage.LastUsed.IsZero() {
+					row[4].SetMysqlTime(types.Time{Time: types.FromGoTime(usage.LastUsed), Type: mysql.TypeDatetime})
+				}
+				e.rows = append(e.rows, row)
+			}
+		}
+	}
+}
+
+// ShowTopSQLExec represents a show top sql executor.
+// It returns the statements consuming the most CPU time or total execution time in the window of the top SQL collector,
+// the statements with the same digest are aggregated.
+type ShowTopSQLExec struct {
+	baseExecutor
+
+	byTime bool
+	count  int
+	rows   []Row
+	cursor int
+	done   bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ShowTopSQLExec) Next() (Row, error) {
+	if !e.done {
+		if err := e.fetchAll(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.done = true
+	}
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+func (e *ShowTopSQLExec) fetchAll() error {
+	collector := topsql.Global()
+	if collector == nil {
+		return ErrTopSQLDisabled
+	}
+	var stats []*topsql.Stats
+	if e.byTime {
+		stats = collector.TopByTime(e.count)
+	} else {
+		stats = collector.TopByCPU(e.count)
+	}
+	toMS := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	for _, st := range stats {
+		row := types.MakeDatums(st.Digest, st.SQL, st.ExecCount, toMS(st.CPUTime), toMS(st.TotalTime),
+			toMS(st.TotalTime/time.Duration(st.ExecCount)), nil)
+		row[6].SetMysqlTime(types.Time{Time: types.FromGoTime(st.LastSeen), Type: mysql.TypeDatetime})
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
+// ShowSlowExec represents a show slow executor.
+// It returns the most recent or the slowest queries kept in memory by the slow query log of this server.
+type ShowSlowExec struct {
+	baseExecutor
+
+	top    bool
+	count  int
+	rows   []Row
+	cursor int
+	done   bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ShowSlowExec) Next() (Row, error) {
+	if !e.done {
+		e.fetchAll()
+		e.done = true
+	}
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+func (e *ShowSlowExec) fetchAll() {
+	var items []*util.SlowQueryInfo
+	if e.top {
+		items = util.SlowQueries.Top(e.count)
+	} else {
+		items = util.SlowQueries.Recent(e.count)
+	}
+	for _, item := range items {
+		row := types.MakeDatums(nil, float64(item.Duration)/float64(time.Millisecond), item.ConnID, item.User,
+			item.Host, item.DB, item.Query, item.TraceID)
+		row[0].SetMysqlTime(types.Time{Time: types.FromGoTime(item.Start), Type: mysql.TypeDatetime, Fsp: types.MaxFsp})
+		e.rows = append(e.rows, row)
+	}
+}
+
 // CheckTableExec represents a check table executor.
 // It is built from the "admin check table" statement, and it checks if the
 // index matches the records in the table.
@@ -207,10 +417,12 @@ func (e *CheckTableExec) Next() (Row, error) {
 			return nil, errors.Trace(err)
 		}
 		for _, idx := range tb.Indices() {
-			txn := e.ctx.Txn()
-			err = inspectkv.CompareIndexData(txn, tb, idx)
-			if err != nil {
-				return nil, errors.Errorf("%v err:%v", t.Name, err)
+			// The index being added or dropped is incomplete.
+			if idx.Meta().State != model.StatePublic {
+				continue
+			}
+			if err = e.checkIndex(tb, idx); err != nil {
+				return nil, errors.Trace(err)
 			}
 		}
 	}
@@ -219,6 +431,30 @@ func (e *CheckTableExec) Next() (Row, error) {
 	return nil, nil
 }
 
+// checkIndex scans the index and the table, it returns an error describing the inconsistencies if any is found.
+func (e *CheckTableExec) checkIndex(tb table.Table, idx table.Index) error {
+	// One more is fetched to know whether there are more than the reported ones.
+	inconsistencies, err := inspectkv.CheckIndex(e.ctx.Txn(), tb, idx, maxReportedInconsistencies+1)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(inconsistencies) == 0 {
+		return nil
+	}
+	descs := make([]string, 0, len(inconsistencies))
+	for i, ic := range inconsistencies {
+		if i == maxReportedInconsistencies {
+			descs = append(descs, "...")
+			break
+		}
+		descs = append(descs, ic.String())
+	}
+	return ErrAdminCheckTable.GenByArgs(tb.Meta().Name.O, idx.Meta().Name.O, strings.Join(descs, ", "))
+}
+
+// maxReportedInconsistencies is the max number of the inconsistencies of an index reported by admin check table.
+const maxReportedInconsistencies = 10
+
 // Close implements plan.Plan Close interface.
 func (e *CheckTableExec) Close() error {
 	return nil
@@ -342,6 +578,9 @@ func init() {
 		CodeCannotUser:           mysql.ErrCannotUser,
 		CodePasswordNoMatch:      mysql.ErrPasswordNoMatch,
 		codeWrongValueCountOnRow: mysql.ErrWrongValueCountOnRow,
+		codeNotValidPassword:     mysql.ErrNotValidPassword,
+		codeSavepointNotExists:   mysql.ErrSpDoesNotExist,
+		codePsManyParam:          mysql.ErrPsManyParam,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
@@ -351,10 +590,39 @@ type ProjectionExec struct {
 	baseExecutor
 
 	exprs []expression.Expression
+	// concurrency is the number of goroutines evaluating the expressions, the expressions are
+	// evaluated in the goroutine calling Next if it's not greater than 1.
+	concurrency int
+
+	prepared bool
+	// finished is closed to notify the fetcher and the workers to exit.
+	finished chan struct{}
+	wg       sync.WaitGroup
+	// workCh passes the fetched batches to the workers.
+	workCh chan *projectionBatch
+	// resultCh passes the fetched batches to Next in the order they are read from the child.
+	resultCh chan *projectionBatch
+	rows     []Row
+	cursor   int
+}
+
+// Open implements the Executor Open interface.
+func (e *ProjectionExec) Open() error {
+	e.stopWorkers()
+	return errors.Trace(e.baseExecutor.Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *ProjectionExec) Close() error {
+	e.stopWorkers()
+	return errors.Trace(e.baseExecutor.Close())
 }
 
 // Next implements the Executor Next interface.
 func (e *ProjectionExec) Next() (retRow Row, err error) {
+	if e.concurrency > 1 {
+		return e.parallelNext()
+	}
 	srcRow, err := e.children[0].Next()
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -15,8 +15,13 @@ package executor
 
 import (
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/types"
 )
 
 var _ = Suite(&testExecSuite{})
@@ -61,3 +66,25 @@ func (s *testExecSuite) TestMergeHandles(c *C) {
 		c.Assert(kr.EndKey, DeepEquals, ekr.EndKey)
 	}
 }
+
+func (s *testExecSuite) TestCanEvalInParallel(c *C) {
+	ctx := mock.NewContext()
+	col := &expression.Column{RetType: types.NewFieldType(mysql.TypeLonglong)}
+	plus, err := expression.NewFunction(ctx, ast.Plus, types.NewFieldType(mysql.TypeLonglong), col, col)
+	c.Assert(err, IsNil)
+	c.Assert(canEvalInParallel([]expression.Expression{col, plus}), IsTrue)
+
+	// The functions keeping a state across the rows are evaluated serially, even as an argument.
+	for _, name := range []string{ast.Rand, ast.UUID, ast.GetVar, ast.FoundRows} {
+		var args []expression.Expression
+		if name == ast.GetVar {
+			args = append(args, &expression.Constant{Value: types.NewStringDatum("a"), RetType: types.NewFieldType(mysql.TypeString)})
+		}
+		fun, err := expression.NewFunction(ctx, name, types.NewFieldType(mysql.TypeDouble), args...)
+		c.Assert(err, IsNil, Commentf("%s", name))
+		c.Assert(canEvalInParallel([]expression.Expression{fun}), IsFalse, Commentf("%s", name))
+		sum, err := expression.NewFunction(ctx, ast.Plus, types.NewFieldType(mysql.TypeDouble), col, fun)
+		c.Assert(err, IsNil)
+		c.Assert(canEvalInParallel([]expression.Expression{col, sum}), IsFalse, Commentf("%s", name))
+	}
+}
@@ -16,7 +16,11 @@ package executor_test
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -27,10 +31,12 @@ import (
 	. "github.com/pingcap/check"
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
@@ -43,9 +49,13 @@ import (
 	mocktikv "github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/sqllog"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/topsql"
 	"github.com/pingcap/tidb/util/types"
 	goctx "golang.org/x/net/context"
 )
@@ -163,6 +173,540 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testSuite) TestAdminCheckTableInconsistency(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists admin_check")
+	tk.MustExec("create table admin_check (a int primary key, b int, index idx_b (b))")
+	tk.MustExec("insert admin_check values (1, 10), (2, 20), (3, 30)")
+	_, err := tk.Exec("admin check table admin_check")
+	c.Assert(err, IsNil)
+
+	// Corrupt the index by the low level writes.
+	is := sessionctx.GetDomain(tk.Se.(context.Context)).InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("admin_check"))
+	c.Assert(err, IsNil)
+	idx := tb.Indices()[0]
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	_, err = idx.Create(txn, types.MakeDatums(int64(40)), 4)
+	c.Assert(err, IsNil)
+	err = idx.Delete(txn, types.MakeDatums(int64(20)), 2)
+	c.Assert(err, IsNil)
+	err = txn.Commit()
+	c.Assert(err, IsNil)
+
+	_, err = tk.Exec("admin check table admin_check")
+	c.Assert(terror.ErrorEqual(err, executor.ErrAdminCheckTable), IsTrue)
+	c.Assert(err.Error(), Equals, "[executor:16]Table 'admin_check' index 'idx_b' is inconsistent with the data: "+
+		"orphan index entry handle:4 index:[40], missing index entry handle:2 record:[20]")
+
+	// Repair the index and the table passes the check again.
+	txn, err = s.store.Begin()
+	c.Assert(err, IsNil)
+	err = idx.Delete(txn, types.MakeDatums(int64(40)), 4)
+	c.Assert(err, IsNil)
+	_, err = idx.Create(txn, types.MakeDatums(int64(20)), 2)
+	c.Assert(err, IsNil)
+	err = txn.Commit()
+	c.Assert(err, IsNil)
+	_, err = tk.Exec("admin check table admin_check")
+	c.Assert(err, IsNil)
+}
+
+func (s *testSuite) TestAdminRecoverAutoIncrement(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists admin_test")
+	tk.MustExec("create table admin_test (id int primary key auto_increment, c int)")
+	tk.MustExec("insert admin_test (c) values (1)")
+
+	// Add the rows without rebasing the allocator, like they are restored.
+	ctx := tk.Se.(context.Context)
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("admin_test"))
+	c.Assert(err, IsNil)
+	c.Assert(ctx.NewTxn(), IsNil)
+	_, err = tb.AddRecord(ctx, types.MakeDatums(2, 2))
+	c.Assert(err, IsNil)
+	_, err = tb.AddRecord(ctx, types.MakeDatums(10000, 3))
+	c.Assert(err, IsNil)
+	c.Assert(ctx.Txn().Commit(), IsNil)
+	_, err = tk.Exec("insert admin_test (c) values (4)")
+	c.Assert(err, NotNil)
+
+	tk.MustExec("admin recover auto_increment admin_test")
+	tk.MustExec("insert admin_test (c) values (4)")
+	tk.MustQuery("select id from admin_test where c = 4").Check(testkit.Rows("10001"))
+	// The allocator base isn't decreased.
+	tk.MustExec("delete from admin_test where id > 2")
+	tk.MustExec("admin recover auto_increment test.admin_test")
+	tk.MustExec("insert admin_test (c) values (5)")
+	tk.MustQuery("select id from admin_test where c = 5").Check(testkit.Rows("10002"))
+
+	// The auto_increment column which isn't the handle.
+	tk.MustExec("drop table if exists admin_test1")
+	tk.MustExec("create table admin_test1 (id int auto_increment, c int, key(id))")
+	tk.MustExec("insert admin_test1 (c) values (1)")
+	tb, err = sessionctx.GetDomain(ctx).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("admin_test1"))
+	c.Assert(err, IsNil)
+	c.Assert(ctx.NewTxn(), IsNil)
+	_, err = tb.AddRecord(ctx, types.MakeDatums(20000, 2))
+	c.Assert(err, IsNil)
+	c.Assert(ctx.Txn().Commit(), IsNil)
+	tk.MustExec("admin recover auto_increment admin_test1")
+	tk.MustExec("insert admin_test1 (c) values (3)")
+	tk.MustQuery("select id from admin_test1 where c = 3").Check(testkit.Rows("20001"))
+
+	_, err = tk.Exec("admin recover auto_increment admin_test_error")
+	c.Assert(err, NotNil)
+	tk.MustExec("drop table if exists admin_test2")
+	tk.MustExec("create table admin_test2 (c int)")
+	_, err = tk.Exec("admin recover auto_increment admin_test2")
+	c.Assert(executor.ErrNoAutoIncrement.Equal(err), IsTrue)
+}
+
+func (s *testSuite) TestAdminRecommendIndex(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b int, c varchar(20), d text, key(a))")
+
+	tests := []struct {
+		sql  string
+		recs [][]string
+	}{
+		{"select * from t where b = 1", [][]string{{"test.t", "b", "ALTER TABLE `test`.`t` ADD INDEX `idx_b`(`b`)"}}},
+		{"select * from t where b = 1 and c > 'x'", [][]string{{"test.t", "b,c", "ALTER TABLE `test`.`t` ADD INDEX `idx_b_c`(`b`, `c`)"}}},
+		{"select * from t where c < 'x' and b in (1, 2)", [][]string{{"test.t", "b,c", "ALTER TABLE `test`.`t` ADD INDEX `idx_b_c`(`b`, `c`)"}}},
+		{"select * from t where b = 1 union select * from t where c = 'x'", [][]string{
+			{"test.t", "b", "ALTER TABLE `test`.`t` ADD INDEX `idx_b`(`b`)"},
+			{"test.t", "c", "ALTER TABLE `test`.`t` ADD INDEX `idx_c`(`c`)"},
+		}},
+		// The existing index, the handle and the full scan don't need a new index.
+		{"select * from t where a = 1", nil},
+		{"select * from t where id = 1", nil},
+		{"select * from t", nil},
+		// The blob column can't be indexed without a prefix length.
+		{"select * from t where d = 'x'", nil},
+	}
+	for _, tt := range tests {
+		rows := tk.MustQuery("admin recommend index " + tt.sql).Rows()
+		c.Assert(rows, HasLen, len(tt.recs), Commentf("for %s", tt.sql))
+		for i, row := range rows {
+			c.Assert(row[:3], DeepEquals, []interface{}{tt.recs[i][0], tt.recs[i][1], tt.recs[i][2]}, Commentf("for %s", tt.sql))
+			cost, err := strconv.ParseFloat(row[3].(string), 64)
+			c.Assert(err, IsNil)
+			costWithIndex, err := strconv.ParseFloat(row[4].(string), 64)
+			c.Assert(err, IsNil)
+			c.Assert(costWithIndex, Less, cost)
+		}
+	}
+
+	// The index is created as recommended, then the query doesn't need another one.
+	tk.MustExec("alter table t add index idx_b(b)")
+	tk.MustQuery("admin recommend index select * from t where b = 1").Check(testkit.Rows())
+
+	// The index isn't recommended if its name is used by another index.
+	tk.MustExec("alter table t add index idx_c(b, a)")
+	tk.MustQuery("admin recommend index select * from t where c = 'x'").Check(testkit.Rows())
+}
+
+func (s *testSuite) TestAdminShowIndexUsage(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("create database if not exists index_usage")
+	tk.MustExec("use index_usage")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b int, index idx_a(a), index idx_b(b))")
+	tk.MustExec("create table t2 (a int primary key, b int, unique index idx_b(b))")
+	tk.MustExec("insert into t1 values (1, 1)")
+	tk.MustExec("insert into t2 values (1, 1)")
+
+	tk.MustQuery("select * from t1 use index(idx_a) where a = 1").Check(testkit.Rows("1 1"))
+	tk.MustQuery("select a from t1 use index(idx_a) where a = 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select * from t2 use index(idx_b) where b = 1").Check(testkit.Rows("1 1"))
+
+	var rows [][]interface{}
+	for _, row := range tk.MustQuery("admin show index usage").Rows() {
+		if row[0] == "index_usage" {
+			rows = append(rows, row)
+		}
+	}
+	c.Assert(rows, HasLen, 3)
+	c.Assert(rows[0][:4], DeepEquals, []interface{}{"index_usage", "t1", "idx_a", "2"})
+	c.Assert(rows[0][4], Not(Equals), "<nil>")
+	// The unused index has zero access count and NULL last used time.
+	c.Assert(rows[1], DeepEquals, []interface{}{"index_usage", "t1", "idx_b", "0", "<nil>"})
+	c.Assert(rows[2][:4], DeepEquals, []interface{}{"index_usage", "t2", "idx_b", "1"})
+	tk.MustExec("drop database index_usage")
+}
+
+func (s *testSuite) TestAdminShowTopSQL(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	rs, err := tk.Exec("admin show top sql")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(terror.ErrorEqual(err, executor.ErrTopSQLDisabled), IsTrue, Commentf("err %v", err))
+	c.Assert(rs.Close(), IsNil)
+
+	topsql.SetGlobal(topsql.NewCollector(time.Minute, 100))
+	defer topsql.SetGlobal(nil)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key)")
+	tk.MustExec("insert into t values (1)")
+	tk.MustExec("insert into t values (2)")
+	tk.MustQuery("select a from t where a = 1").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t where a = 2").Check(testkit.Rows("2"))
+	tk.MustQuery("select a from t where a = 3").Check(testkit.Rows())
+	_, err = tk.Exec("insert into t values (1)")
+	c.Assert(err, NotNil)
+
+	rows := tk.MustQuery("admin show top sql by time limit 100").Rows()
+	counts := make(map[string]string, len(rows))
+	for _, row := range rows {
+		c.Assert(row, HasLen, 7)
+		counts[row[1].(string)] = row[2].(string)
+	}
+	// The failed statement is counted too.
+	c.Assert(counts["insert into t values ( ? )"], Equals, "3")
+	c.Assert(counts["select a from t where a = ?"], Equals, "3")
+	c.Assert(tk.MustQuery("admin show top sql limit 2").Rows(), HasLen, 2)
+}
+
+type mockSessionManager struct {
+	sessions []tidb.Session
+}
+
+func (msm *mockSessionManager) ShowProcessList() []util.ProcessInfo {
+	var ret []util.ProcessInfo
+	for _, se := range msm.sessions {
+		ret = append(ret, se.ShowProcess())
+	}
+	return ret
+}
+
+func (msm *mockSessionManager) Kill(connectionID uint64, query bool) error {
+	return nil
+}
+
+func (msm *mockSessionManager) SetSQLLogger(connectionID uint64, logger *sqllog.Logger) bool {
+	for _, se := range msm.sessions {
+		if se.GetSessionVars().ConnectionID == connectionID {
+			if old := se.GetSessionVars().SetSQLLogger(logger); old != nil {
+				old.Close()
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (s *testSuite) TestExplainForConnection(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, index idx_a(a))")
+	tk.MustExec("insert into t values (1, 1), (2, 2)")
+	expected := tk.MustQuery("explain select * from t where a = 1").Rows()
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk1.Se.SetConnectionID(2)
+	tk.Se.SetConnectionID(1)
+	sm := &mockSessionManager{sessions: []tidb.Session{tk.Se, tk1.Se}}
+	tk.Se.SetSessionManager(sm)
+
+	// The connection is running the query until the result set is closed.
+	rs, err := tk1.Exec("select * from t where a = 1")
+	c.Assert(err, IsNil)
+	tk.MustQuery("explain for connection 2").Check(expected)
+	// The plan executed by the other session isn't modified by the explanation.
+	p := tk1.Se.ShowProcess().Plan.(plan.Plan)
+	c.Assert(p.Parents(), HasLen, 0)
+	for _, child := range p.Children() {
+		c.Assert(child.Parents(), HasLen, 0)
+	}
+	c.Assert(rs.Close(), IsNil)
+
+	// The result is empty if the connection is idle.
+	tk.MustQuery("explain for connection 2").Check(testkit.Rows())
+	_, err = tk.Exec("explain for connection 3")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNoSuchThread), IsTrue, Commentf("err %v", err))
+}
+
+func (s *testSuite) TestShowProcessListTxn(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk1.MustExec("set @@tx_isolation = 'REPEATABLE-READ'")
+	sm := &mockSessionManager{sessions: []tidb.Session{tk.Se, tk1.Se}}
+	tk.Se.SetSessionManager(sm)
+
+	// SHOW PROCESSLIST keeps the columns of MySQL, the transaction of each connection is in INFORMATION_SCHEMA.PROCESSLIST.
+	for _, row := range tk.MustQuery("show processlist").Rows() {
+		c.Assert(row, HasLen, 8)
+	}
+	// The process info keeps the connection ID of the first statement.
+	connID := tk1.Se.GetSessionVars().ConnectionID
+	checkTxn := func(expected ...string) {
+		tk.MustQuery(fmt.Sprintf("select isolation_level, txn_mode, in_transaction from information_schema.processlist where id = %d", connID)).
+			Check(testkit.Rows(strings.Join(expected, " ")))
+	}
+	checkTxn("REPEATABLE-READ", "OPTIMISTIC", "0")
+
+	tk1.MustExec("set @@tx_isolation = 'READ-COMMITTED'")
+	tk1.MustExec("begin")
+	checkTxn("READ-COMMITTED", "OPTIMISTIC", "1")
+	tk1.MustExec("commit")
+	checkTxn("READ-COMMITTED", "OPTIMISTIC", "0")
+}
+
+func (s *testSuite) TestAdminSQLLog(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	dir, err := ioutil.TempDir("", "sql-log")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sql.log")
+	cfg := config.GetGlobalConfig()
+	oldDir := cfg.SQLLogDir
+	defer func() { cfg.SQLLogDir = oldDir }()
+
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk.Se.SetConnectionID(1)
+	tk1.Se.SetConnectionID(2)
+	tk.Se.SetSessionManager(&mockSessionManager{sessions: []tidb.Session{tk.Se, tk1.Se}})
+
+	_, err = tk.Exec(fmt.Sprintf("admin enable log for connection 2 to '%s'", path))
+	c.Assert(terror.ErrorEqual(err, executor.ErrSQLLogDisabled), IsTrue, Commentf("err %v", err))
+	cfg.SQLLogDir = dir
+	// The files outside the directory are rejected.
+	for _, file := range []string{"/tmp/sql.log", "../sql.log", "a/../../sql.log", "."} {
+		_, err = tk.Exec(fmt.Sprintf("admin enable log for connection 2 to '%s'", file))
+		c.Assert(terror.ErrorEqual(err, executor.ErrSQLLogFile), IsTrue, Commentf("file %s, err %v", file, err))
+	}
+
+	tk1.MustExec("drop table if exists t")
+	// A relative path is relative to the directory.
+	tk.MustExec("admin enable log for connection 2 to 'sql.log'")
+	tk1.MustExec("create table t (a int)")
+	tk1.MustExec("insert into t values (1)")
+	tk1.MustQuery("select a from t").Check(testkit.Rows("1"))
+	// The statements of the other connections are not logged.
+	tk.MustQuery("select 1").Check(testkit.Rows("1"))
+	tk.MustExec("admin disable log for connection 2")
+	tk1.MustExec("drop table t")
+
+	content, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	c.Assert(lines, HasLen, 3)
+	for i, sql := range []string{"create table t (a int)", "insert into t values (1)", "select a from t"} {
+		fields := strings.Split(lines[i], "\t")
+		c.Assert(fields, HasLen, 4, Commentf("line %s", lines[i]))
+		c.Assert(fields[1], Equals, "2")
+		_, err = time.ParseDuration(fields[2])
+		c.Assert(err, IsNil)
+		c.Assert(fields[3], Equals, sql)
+	}
+	c.Assert(tk1.Se.GetSessionVars().SQLLogger(), IsNil)
+
+	_, err = tk.Exec("admin enable log for connection 3 to 'sql-log-3'")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNoSuchThread), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("admin disable log for connection 3")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNoSuchThread), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("admin enable log for connection 2 to 'non-existent-dir/sql.log'")
+	c.Assert(err, NotNil)
+
+	// The log file is closed when the session is closed.
+	tk.MustExec(fmt.Sprintf("admin enable log for connection 2 to '%s'", path))
+	c.Assert(tk1.Se.GetSessionVars().SQLLogger(), NotNil)
+	tk1.Se.Close()
+	c.Assert(tk1.Se.GetSessionVars().SQLLogger(), IsNil)
+}
+
+func (s *testSuite) TestSlowQuery(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	cfg := config.GetGlobalConfig()
+	oldThreshold := cfg.SlowThreshold
+	defer func() { cfg.SlowThreshold = oldThreshold }()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	// The statement is logged when the record set is closed, GetRows closes it once.
+	query := func() {
+		rs, err := tk.Exec("select 'slow_query_test'")
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows(rs)
+		c.Assert(err, IsNil)
+	}
+	query()
+	tk.MustQuery(`select count(*) from information_schema.slow_query where sql_text = "select 'slow_query_test'"`).Check(testkit.Rows("0"))
+
+	// Every statement is recorded with 0 threshold.
+	cfg.SlowThreshold = 0
+	query()
+	tk.MustExec("use mysql")
+	query()
+	tk.MustQuery(`select db, sql_text from information_schema.slow_query where sql_text = "select 'slow_query_test'" order by start_time desc`).
+		Check(testkit.Rows("mysql select 'slow_query_test'", "test select 'slow_query_test'"))
+	tk.MustQuery(`select count(*) from information_schema.slow_query where sql_text = "select 'slow_query_test'" and query_time >= 0 and start_time > '2017-01-01'`).
+		Check(testkit.Rows("2"))
+}
+
+func (s *testSuite) TestAdminShowSlow(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	cfg := config.GetGlobalConfig()
+	oldThreshold := cfg.SlowThreshold
+	defer func() { cfg.SlowThreshold = oldThreshold }()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+
+	// The statement is logged when the record set is closed, GetRows closes it once.
+	query := func(sql string) {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows(rs)
+		c.Assert(err, IsNil)
+	}
+	cfg.SlowThreshold = 0
+	query("select sleep(0.2)")
+	query("select 'admin_show_slow_test'")
+	// The admin statements are recorded too, but after they return the rows.
+	rows := tk.MustQuery("admin show slow recent 2").Rows()
+	c.Assert(rows, HasLen, 2)
+	c.Assert(rows[0], HasLen, 8)
+	c.Assert(rows[0][5:7], DeepEquals, []interface{}{"test", "select 'admin_show_slow_test'"})
+	c.Assert(rows[1][5:7], DeepEquals, []interface{}{"test", "select sleep(0.2)"})
+	c.Assert(tk.MustQuery("admin show slow recent 1").Rows()[0][6], Equals, "admin show slow recent 2")
+	c.Assert(tk.MustQuery("admin show slow recent 0").Rows(), HasLen, 0)
+
+	rows = tk.MustQuery("admin show slow top 1024").Rows()
+	var found bool
+	for i, row := range rows {
+		duration, err := strconv.ParseFloat(row[1].(string), 64)
+		c.Assert(err, IsNil)
+		if i > 0 {
+			last, err := strconv.ParseFloat(rows[i-1][1].(string), 64)
+			c.Assert(err, IsNil)
+			c.Assert(last >= duration, IsTrue, Commentf("%v is after %v", duration, last))
+		}
+		if row[6] == "select sleep(0.2)" {
+			found = true
+			c.Assert(duration >= 200, IsTrue, Commentf("duration %v", duration))
+		}
+	}
+	c.Assert(found, IsTrue)
+	c.Assert(tk.MustQuery("admin show slow top 1").Rows(), DeepEquals, rows[:1])
+}
+
+func (s *testSuite) TestSlowQueryRedact(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	cfg := config.GetGlobalConfig()
+	oldThreshold, oldMaxLen, oldRedact := cfg.SlowThreshold, cfg.QueryLogMaxlen, cfg.LogRedactLiterals
+	defer func() {
+		cfg.SlowThreshold, cfg.QueryLogMaxlen, cfg.LogRedactLiterals = oldThreshold, oldMaxLen, oldRedact
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	cfg.SlowThreshold = 0
+	// The statement is logged when the record set is closed, GetRows closes it once.
+	query := func(sql string) {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows(rs)
+		c.Assert(err, IsNil)
+	}
+
+	cfg.LogRedactLiterals = true
+	query("select 'redact_secret', 42 as redact_col")
+	cfg.LogRedactLiterals = false
+	tk.MustQuery(`select count(*) from information_schema.slow_query where sql_text = "select ? , ? as redact_col"`).Check(testkit.Rows("1"))
+	tk.MustQuery(`select count(*) from information_schema.slow_query where sql_text like "%redact_secret%"`).Check(testkit.Rows("0"))
+
+	cfg.QueryLogMaxlen = 20
+	query("select 'truncate_query_test'")
+	cfg.QueryLogMaxlen = oldMaxLen
+	tk.MustQuery(`select count(*) from information_schema.slow_query where sql_text = "select 'truncate_que...(len:28)"`).Check(testkit.Rows("1"))
+}
+
+func (s *testSuite) TestSlowQueryTraceID(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	cfg := config.GetGlobalConfig()
+	oldThreshold := cfg.SlowThreshold
+	defer func() { cfg.SlowThreshold = oldThreshold }()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	cfg.SlowThreshold = 0
+	// The statement is logged when the record set is closed, GetRows closes it once.
+	query := func(sql string) {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows(rs)
+		c.Assert(err, IsNil)
+	}
+
+	query("select 'trace_id_test_1'")
+	tk.MustExec("set @@tidb_trace_id = '4bf92f3577b34da6a3ce929d0e0e4736'")
+	tk.MustQuery("select @@tidb_trace_id").Check(testkit.Rows("4bf92f3577b34da6a3ce929d0e0e4736"))
+	query("select 'trace_id_test_2'")
+	tk.MustExec("set @@tidb_trace_id = ''")
+	query("select 'trace_id_test_3'")
+	tk.MustQuery(`select sql_text, trace_id from information_schema.slow_query where sql_text like "select 'trace_id_test_%'" order by sql_text`).
+		Check(testkit.Rows("select 'trace_id_test_1' ", "select 'trace_id_test_2' 4bf92f3577b34da6a3ce929d0e0e4736", "select 'trace_id_test_3' "))
+
+	_, err := tk.Exec("set @@tidb_trace_id = 'has space'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = tk.Exec(fmt.Sprintf("set @@tidb_trace_id = '%s'", strings.Repeat("a", variable.MaxTraceIDLength+1)))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+}
+
 func (s *testSuite) fillData(tk *testkit.TestKit, table string) {
 	tk.MustExec("use test")
 	tk.MustExec(fmt.Sprintf("create table %s(id int not null default 1, name varchar(255), PRIMARY KEY(id));", table))
@@ -578,6 +1122,128 @@ func (s *testSuite) TestSelectOrderBy(c *C) {
 	tk.MustQuery("select * from t where a between 1 and 2 order by a desc").Check(testkit.Rows("2 2", "1 1"))
 }
 
+func (s *testSuite) TestTopN(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(1024))")
+	var values []string
+	for i := 0; i < 200; i++ {
+		values = append(values, fmt.Sprintf("(%d, '%s')", i*37%200, strings.Repeat("x", 1000)))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ", "))
+
+	// The results of the topN match the ones of the full sort.
+	sorted := tk.MustQuery("select a from t order by a desc").Rows()
+	c.Assert(sorted, HasLen, 200)
+	tests := []struct {
+		offset, count int
+	}{{0, 1}, {0, 10}, {10, 5}, {195, 10}, {199, 1}, {300, 10}, {0, 0}}
+	for _, tt := range tests {
+		var expected [][]interface{}
+		if tt.offset < len(sorted) {
+			end := tt.offset + tt.count
+			if end > len(sorted) {
+				end = len(sorted)
+			}
+			expected = sorted[tt.offset:end]
+		}
+		sql := fmt.Sprintf("select a from t order by a desc limit %d, %d", tt.offset, tt.count)
+		tk.MustQuery(sql).Check(expected)
+		// The topN which puts NULLs first isn't pushed down.
+		sql = fmt.Sprintf("select a from t order by a desc nulls first limit %d, %d", tt.offset, tt.count)
+		tk.MustQuery(sql).Check(expected)
+	}
+	tk.MustQuery("select a from t order by a desc limit 1, 18446744073709551615").Check(sorted[1:])
+	tk.MustQuery("select a from t order by a desc limit 18446744073709551615 offset 18446744073709551615").Check(testkit.Rows())
+
+	// The topN only keeps Offset+Count rows in memory.
+	global := memory.GlobalTracker()
+	global.SetBytesLimit(global.BytesConsumed() + 50*1024)
+	defer global.SetBytesLimit(0)
+	c.Assert(tk.MustQuery("select * from t order by a desc nulls first limit 10, 5").Rows(), HasLen, 5)
+	rs, err := tk.Exec("select * from t order by a desc nulls first")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(terror.ErrorEqual(err, memory.ErrMemoryExceeded), IsTrue, Commentf("err %v", err))
+}
+
+func (s *testSuite) TestOrderByNulls(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, index idx(a))")
+	tk.MustExec("insert into t values (1, 2), (2, null), (3, 1), (4, null), (5, 3)")
+
+	// By default NULL is the smallest value like MySQL.
+	tk.MustQuery("select a from t order by a, id").Check(testkit.Rows("<nil>", "<nil>", "1", "2", "3"))
+	tk.MustQuery("select a from t order by a desc, id").Check(testkit.Rows("3", "2", "1", "<nil>", "<nil>"))
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("<nil>", "<nil>", "1", "2", "3"))
+	tk.MustQuery("select a from t order by a nulls first").Check(testkit.Rows("<nil>", "<nil>", "1", "2", "3"))
+	tk.MustQuery("select a from t order by a desc nulls last").Check(testkit.Rows("3", "2", "1", "<nil>", "<nil>"))
+
+	tk.MustQuery("select a from t order by a nulls last").Check(testkit.Rows("1", "2", "3", "<nil>", "<nil>"))
+	tk.MustQuery("select a from t order by a desc nulls first").Check(testkit.Rows("<nil>", "<nil>", "3", "2", "1"))
+	tk.MustQuery("select id, a from t order by a nulls last, id desc").Check(testkit.Rows("3 1", "1 2", "5 3", "4 <nil>", "2 <nil>"))
+	tk.MustQuery("select a from t order by a + 1 desc nulls first").Check(testkit.Rows("<nil>", "<nil>", "3", "2", "1"))
+	tk.MustQuery("select a from t order by a nulls last limit 2").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select a from t order by a desc nulls first limit 1, 2").Check(testkit.Rows("<nil>", "3"))
+	tk.MustQuery("select a from t where a > 1 or a is null order by a nulls last").Check(testkit.Rows("2", "3", "<nil>", "<nil>"))
+	tk.MustQuery("select a from (select a from t union all select null) t order by a nulls last limit 4").Check(testkit.Rows("1", "2", "3", "<nil>"))
+
+	// The session default applies to the order by items without NULLS FIRST or NULLS LAST.
+	tk.MustExec("set @@tidb_default_null_order = 'high'")
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("1", "2", "3", "<nil>", "<nil>"))
+	tk.MustQuery("select a from t order by a desc limit 3").Check(testkit.Rows("<nil>", "<nil>", "3"))
+	tk.MustQuery("select a from t order by a nulls first").Check(testkit.Rows("<nil>", "<nil>", "1", "2", "3"))
+	tk.MustExec("set @@tidb_default_null_order = 'first'")
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("<nil>", "<nil>", "1", "2", "3"))
+	tk.MustQuery("select a from t order by a desc").Check(testkit.Rows("<nil>", "<nil>", "3", "2", "1"))
+	tk.MustExec("set @@tidb_default_null_order = 'last'")
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("1", "2", "3", "<nil>", "<nil>"))
+	tk.MustQuery("select a from t order by a desc").Check(testkit.Rows("3", "2", "1", "<nil>", "<nil>"))
+	tk.MustQuery("select a from t order by a desc nulls first").Check(testkit.Rows("<nil>", "<nil>", "3", "2", "1"))
+	tk.MustExec("set @@tidb_default_null_order = 'low'")
+	tk.MustQuery("select a from t order by a desc").Check(testkit.Rows("3", "2", "1", "<nil>", "<nil>"))
+	_, err := tk.Exec("set @@tidb_default_null_order = 'middle'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+}
+
+func (s *testSuite) TestTimeIndexRange(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, d date, ts timestamp null, index idx_d(d), index idx_ts(ts))")
+	tk.MustExec(`insert into t values (1, '2020-01-01', '2020-01-01 00:00:00'), (2, '2020-01-02', '2020-01-01 10:00:00'),
+		(3, '2020-01-10', '2020-01-10 00:00:00'), (4, '2020-02-01', '2020-02-01 00:00:00'), (5, '2020-02-02', '2020-02-01 00:00:01'), (6, null, null)`)
+
+	tk.MustQuery("select id from t use index(idx_d) where d between '2020-01-01' and '2020-02-01'").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustQuery("select id from t use index(idx_d) where d between '2020-01-01 10:00:00' and '2020-02-01 10:00:00'").Check(testkit.Rows("2", "3", "4"))
+	tk.MustQuery("select id from t use index(idx_d) where d > '2020-1-2' and d <= 20200201").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select id from t use index(idx_d) where d = '2020-01-01 10:00:00'").Check(testkit.Rows())
+	tk.MustQuery("select id from t use index(idx_d) where d in ('2020-01-02', '2020-1-2', '2020-01-01 10:00:00')").Check(testkit.Rows("2"))
+	tk.MustQuery("select id from t use index(idx_ts) where ts between '2020-01-01' and '2020-02-01'").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustQuery("select id from t use index(idx_ts) where ts > '2020-01-01' and ts < '2020-02-01 00:00:01'").Check(testkit.Rows("2", "3", "4"))
+
+	// The comparison with string literals should be used to build a tight index range instead of a full index scan.
+	rows := tk.MustQuery("explain select id from t where d between '2020-01-01' and '2020-02-01'").Rows()
+	c.Assert(fmt.Sprintf("%v", rows[0]), Equals, "[IndexScan_7   cop table:t, index:d, range:[2020-01-01,2020-02-01], out of order:true 250]")
+	rows = tk.MustQuery("explain select id from t where ts > '2020-01-01' and ts < '2020-02-01 00:00:01'").Rows()
+	c.Assert(fmt.Sprintf("%v", rows[0]), Equals, "[IndexScan_11   cop table:t, index:ts, range:(2020-01-01 00:00:00,2020-02-01 00:00:01), out of order:true 250]")
+}
+
 func (s *testSuite) TestSelectErrorRow(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -787,6 +1453,69 @@ func (s *testSuite) TestUnion(c *C) {
 	tk.MustQuery("SELECT @x:=0 UNION ALL SELECT @x:=0 UNION ALL SELECT @x")
 }
 
+func (s *testSuite) TestCommonTableExpression(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (id int, name varchar(10))")
+	tk.MustExec("create table t2 (id int, score int)")
+	tk.MustExec("insert t1 values (1, 'a'), (2, 'b'), (3, 'c')")
+	tk.MustExec("insert t2 values (1, 10), (1, 20), (2, 30), (4, 40)")
+
+	// Single CTE.
+	r := tk.MustQuery("with c as (select id, name from t1 where id > 1) select * from c order by id")
+	r.Check(testkit.Rows("2 b", "3 c"))
+	r = tk.MustQuery("with c as (select id from t1) select c.id from c where c.id = 2")
+	r.Check(testkit.Rows("2"))
+	r = tk.MustQuery("with c (x, y) as (select id, name from t1) select y, x + 1 from c where x < 3 order by x")
+	r.Check(testkit.Rows("a 2", "b 3"))
+	r = tk.MustQuery("with c as (select 1 as a union select 2) select sum(a) from c")
+	r.Check(testkit.Rows("3"))
+	// The CTE shadows the table with the same name.
+	r = tk.MustQuery("with t2 as (select id from t1) select count(*) from t2")
+	r.Check(testkit.Rows("3"))
+
+	// Multiple CTEs feeding a join.
+	r = tk.MustQuery(`with names as (select id, name from t1), totals (id, total) as (select id, sum(score) from t2 group by id)
+		select names.name, totals.total from names join totals on names.id = totals.id order by names.id`)
+	r.Check(testkit.Rows("a 30", "b 30"))
+	r = tk.MustQuery(`with names as (select id, name from t1), totals (id, total) as (select id, sum(score) from t2 group by id)
+		select n.name, t.total from names n left join totals t on n.id = t.id order by n.id`)
+	r.Check(testkit.Rows("a 30", "b 30", "c <nil>"))
+	// A CTE can refer to the CTEs defined before it.
+	r = tk.MustQuery(`with c1 as (select id from t2 where score > 10), c2 as (select t1.name from t1 join c1 on t1.id = c1.id)
+		select name from c2 order by name`)
+	r.Check(testkit.Rows("a", "b"))
+	// A CTE referenced more than once.
+	r = tk.MustQuery("with c as (select id, score from t2) select a.score, b.score from c a join c b on a.id = b.id and a.score < b.score")
+	r.Check(testkit.Rows("10 20"))
+	r = tk.MustQuery("with c as (select id from t1) select id from c where id in (select id from c where id > 2)")
+	r.Check(testkit.Rows("3"))
+	r = tk.MustQuery("with c as (select id from t1 where id < 2) select id from c union all select id from c")
+	r.Check(testkit.Rows("1", "1"))
+	r = tk.MustQuery("with c as (select * from t1) select score from t2 where exists (select 1 from c where c.id = t2.id) order by score")
+	r.Check(testkit.Rows("10", "20", "30"))
+	r = tk.MustQuery("with c as (select * from t1) select c.* from c order by c.id desc limit 1")
+	r.Check(testkit.Rows("3 c"))
+
+	// Name resolution errors.
+	_, err := tk.Exec("with c as (select 1), c as (select 2) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUniqTable), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("with c (a, b) as (select 1) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewWrongList), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("with c (a, a) as (select 1, 2) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrDupFieldName), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("with c (x) as (select id from t1) select id from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrUnknownColumn), IsTrue, Commentf("err %v", err))
+	// A CTE can't refer to the CTEs defined after it.
+	_, err = tk.Exec("with c1 as (select * from c2), c2 as (select 1) select * from c1")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableNotExists), IsTrue, Commentf("err %v", err))
+}
+
 func (s *testSuite) TestIn(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1341,6 +2070,34 @@ func (s *testSuite) TestTableScan(c *C) {
 	result.Check(testkit.Rows(rowStr1, rowStr2))
 }
 
+func (s *testSuite) TestInfoSchemaStatistics(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int not null, b varchar(20), unique index idx_a (a), index idx_ab (a, b(5)))")
+	tk.MustExec("insert into t values (1, 1, 'x'), (2, 2, 'x'), (3, 3, 'y')")
+	sql := `select table_schema, table_name, non_unique, index_name, seq_in_index, column_name, collation,
+		cardinality, sub_part, nullable, index_type from information_schema.statistics where table_name = 't'`
+	// The cardinality is unknown before the table is analyzed.
+	tk.MustQuery(sql).Check(testkit.Rows(
+		"test t 0 PRIMARY 1 id A <nil> <nil>  BTREE",
+		"test t 0 idx_a 1 a A <nil> <nil>  BTREE",
+		"test t 1 idx_ab 1 a A <nil> <nil>  BTREE",
+		"test t 1 idx_ab 2 b A <nil> 5 YES BTREE",
+	))
+	tk.MustExec("analyze table t")
+	tk.MustQuery(sql).Check(testkit.Rows(
+		"test t 0 PRIMARY 1 id A 3 <nil>  BTREE",
+		"test t 0 idx_a 1 a A 3 <nil>  BTREE",
+		"test t 1 idx_ab 1 a A 3 <nil>  BTREE",
+		"test t 1 idx_ab 2 b A 3 5 YES BTREE",
+	))
+}
+
 func (s *testSuite) TestAdapterStatement(c *C) {
 	defer testleak.AfterTest(c)()
 	se, err := tidb.CreateSession(s.store)
@@ -1390,6 +2147,39 @@ func (s *testSuite) TestPointGet(c *C) {
 	}
 }
 
+func (s *testSuite) TestPointGetOnCompositePK(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10), c int, primary key(a, b))")
+	tk.MustExec("insert t values (1, 'x', 1), (1, 'y', 2), (2, 'x', 3), (3, 'z', 4)")
+
+	// The points are read by the primary key, instead of a full table scan.
+	rows := tk.MustQuery("explain select * from t where (a, b) in ((3, 'z'), (1, 'y'))").Rows()
+	c.Assert(rows[0][0], Matches, "IndexScan_.*")
+	c.Assert(rows[0][4], Matches, ".*index:a, b, range:\\[1 y,1 y\\], \\[3 z,3 z\\].*")
+
+	tk.MustQuery("select * from t where (a, b) in ((3, 'z'), (1, 'y'), (2, 'y'), (3, 'z')) order by a").Check(testkit.Rows("1 y 2", "3 z 4"))
+	tk.MustQuery("select * from t where a = 1 and b = 'x' or a = 2 and b = 'x' order by a").Check(testkit.Rows("1 x 1", "2 x 3"))
+	tk.MustQuery("select * from t where (a, b) in ((2, 'x'), (1, 'x'), (1, 'y')) order by a, b").Check(testkit.Rows("1 x 1", "1 y 2", "2 x 3"))
+	tk.MustQuery("select * from t where (a, b) in ((2, 'x'), (1, 'x'), (1, 'y')) order by a desc, b desc").Check(testkit.Rows("2 x 3", "1 y 2", "1 x 1"))
+	tk.MustQuery("select * from t where (a, b) in ((1, 'x'), (3, 'z')) and c > 1").Check(testkit.Rows("3 z 4"))
+	// The condition is kept as a filter if the points don't cover it.
+	tk.MustQuery("select * from t where a = 1 and b = 'y' or a = 2 and b = 'x' and c = 0").Check(testkit.Rows("1 y 2"))
+	tk.MustQuery("select * from t where a = 1 and b = 'y' or a = 2 order by a").Check(testkit.Rows("1 y 2", "2 x 3"))
+
+	// The uncommitted changes in the transaction are read too.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (4, 'w', 5)")
+	tk.MustExec("delete from t where a = 1 and b = 'y'")
+	tk.MustQuery("select * from t where (a, b) in ((4, 'w'), (1, 'y'), (1, 'x')) order by a").Check(testkit.Rows("1 x 1", "4 w 5"))
+	tk.MustExec("rollback")
+}
+
 func (s *testSuite) TestRow(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1540,6 +2330,76 @@ func (s *testSuite) TestHistoryRead(c *C) {
 	tk.MustQuery("select * from history_read order by a").Check(testkit.Rows("2 <nil>", "4 <nil>", "8 8", "9 9"))
 }
 
+func (s *testSuite) TestAsOfTimestamp(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("insert t values (1)")
+	tk.MustExec("insert t1 values (1)")
+
+	// For mocktikv, safe point is not initialized, we manually insert it for snapshot to use.
+	tk.MustExec(`INSERT INTO mysql.tidb VALUES ('tikv_gc_safe_point', '20060102-15:04:05 -0700 MST', '')
+	ON DUPLICATE KEY UPDATE variable_value = '20060102-15:04:05 -0700 MST'`)
+
+	time.Sleep(time.Millisecond)
+	t1 := time.Now()
+	ts1 := t1.Format("2006-01-02 15:04:05.999999")
+	time.Sleep(time.Millisecond)
+	tk.MustExec("insert t values (2)")
+	tk.MustExec("alter table t add column b int")
+	tk.MustExec("insert t values (3, 3)")
+	time.Sleep(time.Millisecond)
+	ts2 := time.Now().Format("2006-01-02 15:04:05.999999")
+	time.Sleep(time.Millisecond)
+	tk.MustExec("delete from t where a = 1")
+
+	tk.MustQuery("select * from t order by a").Check(testkit.Rows("2 <nil>", "3 3"))
+	// The table is read with the schema at the timestamp.
+	tk.MustQuery("select * from t as of timestamp '" + ts1 + "'").Check(testkit.Rows("1"))
+	tk.MustQuery("select * from t as of timestamp '" + ts2 + "' order by a").Check(testkit.Rows("1 <nil>", "2 <nil>", "3 3"))
+	tk.MustQuery("select x.a from t as of timestamp '" + ts2 + "' as x where x.b is null order by x.a").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select t.a, t1.a from t as of timestamp '" + ts1 + "' join t1 as of timestamp '" + ts1 + "' on t.a = t1.a").Check(testkit.Rows("1 1"))
+	tk.MustQuery("explain select * from t as of timestamp '" + ts1 + "'")
+	// The current data isn't affected.
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("2", "3"))
+
+	tk.MustExec("prepare stmt from 'select * from t as of timestamp ? order by a'")
+	tk.MustExec("set @ts = '" + ts1 + "'")
+	tk.MustQuery("execute stmt using @ts").Check(testkit.Rows("1"))
+	tk.MustExec("set @ts = '" + ts2 + "'")
+	tk.MustQuery("execute stmt using @ts").Check(testkit.Rows("1 <nil>", "2 <nil>", "3 3"))
+
+	// The timestamp is in the time zone of the session.
+	tk.MustExec("set @@time_zone = '+00:00'")
+	tk.MustQuery("select * from t as of timestamp '" + t1.UTC().Format("2006-01-02 15:04:05.999999") + "'").Check(testkit.Rows("1"))
+	tk.MustExec("set @@time_zone = '+01:00'")
+	tk.MustQuery("select * from t as of timestamp '" + t1.In(time.FixedZone("", 3600)).Format("2006-01-02 15:04:05.999999") + "'").Check(testkit.Rows("1"))
+	tk.MustExec("set @@time_zone = 'SYSTEM'")
+
+	_, err := tk.Exec("select * from t as of timestamp '" + ts1 + "' join t1 as of timestamp '" + ts2 + "'")
+	c.Assert(terror.ErrorEqual(err, executor.ErrInvalidAsOfTS), IsTrue)
+	_, err = tk.Exec("select * from t as of timestamp '2100-01-01 00:00:00'")
+	c.Assert(terror.ErrorEqual(err, executor.ErrInvalidAsOfTS), IsTrue)
+	_, err = tk.Exec("select * from t as of timestamp null")
+	c.Assert(terror.ErrorEqual(err, executor.ErrInvalidAsOfTS), IsTrue)
+	_, err = tk.Exec("select * from t as of timestamp '2006-01-01 15:04:05'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrSnapshotTooOld), IsTrue)
+	_, err = tk.Exec("select * from t as of timestamp '" + ts1 + "' for update")
+	c.Assert(terror.ErrorEqual(err, executor.ErrAsOfNotSupported), IsTrue)
+	_, err = tk.Exec("insert into t1 select a from t as of timestamp '" + ts1 + "'")
+	c.Assert(terror.ErrorEqual(err, executor.ErrAsOfNotSupported), IsTrue)
+	tk.MustExec("begin")
+	_, err = tk.Exec("select * from t as of timestamp '" + ts1 + "'")
+	c.Assert(terror.ErrorEqual(err, executor.ErrAsOfNotSupported), IsTrue)
+	tk.MustExec("rollback")
+}
+
 func (s *testSuite) TestScanControlSelection(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1941,3 +2801,145 @@ func (s testPrioritySuite) TestCoprocessorPriority(c *C) {
 	cli.priority = pb.CommandPri_Low
 	tk.MustQuery("select LOW_PRIORITY id from t where id = 1")
 }
+
+func (s *testSuite) TestMemoryTracker(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int, b varchar(64))")
+	tk.MustExec("create table t1 (a int, b varchar(64))")
+	for i := 0; i < 50; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, '%s')", i, strings.Repeat("x", i)))
+		tk.MustExec(fmt.Sprintf("insert into t1 values (%d, '%s')", i, strings.Repeat("y", i)))
+	}
+
+	global := memory.GlobalTracker()
+	baseline := global.BytesConsumed()
+	queries := []string{
+		"select * from t order by b",
+		"select * from t join t1 on t.a = t1.a",
+		"select b, count(*) from t group by b",
+	}
+	// The memory consumed by the executors is released when the statements finish.
+	for _, sql := range queries {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		rows, err := tidb.GetRows(rs)
+		c.Assert(err, IsNil)
+		c.Assert(rows, HasLen, 50)
+		c.Assert(global.BytesConsumed(), Equals, baseline, Commentf("sql %s", sql))
+	}
+
+	// The executors fail when the memory quota of the server is exceeded.
+	global.SetBytesLimit(baseline + 1)
+	defer global.SetBytesLimit(0)
+	for _, sql := range queries {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows(rs)
+		c.Assert(terror.ErrorEqual(err, memory.ErrMemoryExceeded), IsTrue, Commentf("sql %s, err %v", sql, err))
+		c.Assert(global.BytesConsumed(), Equals, baseline, Commentf("sql %s", sql))
+	}
+	global.SetBytesLimit(0)
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("50"))
+}
+
+func (s *testSuite) TestMemoryLoadShedding(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int, b varchar(64))")
+	tk.MustExec("create table t1 (a int, b varchar(64))")
+	tk.MustExec("insert into t values (1, 'a'), (2, 'b')")
+	tk.MustExec("insert into t1 values (1, 'a'), (2, 'b')")
+
+	// The server sheds load when the memory consumption is close to the quota.
+	global := memory.GlobalTracker()
+	global.SetShedding(true)
+	defer global.SetShedding(false)
+	queries := []string{
+		"select * from t order by b",
+		"select * from t join t1 on t.a = t1.a",
+		"select b, count(*) from t group by b",
+	}
+	for _, sql := range queries {
+		_, err := tk.Exec(sql)
+		c.Assert(terror.ErrorEqual(err, memory.ErrServerBusy), IsTrue, Commentf("sql %s, err %v", sql, err))
+	}
+	// The statements which don't buffer rows in memory are not rejected.
+	tk.MustQuery("select * from t where a = 1").Check(testkit.Rows("1 a"))
+	tk.MustExec("insert into t values (3, 'c')")
+
+	global.SetShedding(false)
+	tk.MustQuery("select * from t order by b desc").Check(testkit.Rows("3 c", "2 b", "1 a"))
+}
+
+func (s *testSuite) TestWindowFunction(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c int)")
+	tk.MustExec("insert into t values (1, 1, 10), (1, 2, 20), (1, 2, 30), (2, 1, 40), (2, 3, 50), (3, NULL, 60)")
+
+	tk.MustQuery("select a, c, row_number() over (partition by a order by c) from t order by a, c").Check(testkit.Rows(
+		"1 10 1", "1 20 2", "1 30 3", "2 40 1", "2 50 2", "3 60 1"))
+	tk.MustQuery("select a, b, rank() over (partition by a order by b), dense_rank() over (order by a) from t order by a, b").Check(testkit.Rows(
+		"1 1 1 1", "1 2 2 1", "1 2 2 1", "2 1 1 2", "2 3 2 2", "3 <nil> 1 3"))
+
+	// The running sum includes the peers of the current row.
+	tk.MustQuery("select c, sum(c) over (order by c) from t order by c").Check(testkit.Rows(
+		"10 10", "20 30", "30 60", "40 100", "50 150", "60 210"))
+	tk.MustQuery("select a, b, sum(c) over (partition by a order by b) from t order by a, b, c").Check(testkit.Rows(
+		"1 1 10", "1 2 60", "1 2 60", "2 1 40", "2 3 90", "3 <nil> 60"))
+	tk.MustQuery("select a, count(*) over (partition by a), max(c) over (partition by a) from t order by a, c").Check(testkit.Rows(
+		"1 3 30", "1 3 30", "1 3 30", "2 2 50", "2 2 50", "3 1 60"))
+
+	// Framed aggregations.
+	tk.MustQuery("select c, sum(c) over (order by c rows 1 preceding) from t order by c").Check(testkit.Rows(
+		"10 10", "20 30", "30 50", "40 70", "50 90", "60 110"))
+	tk.MustQuery("select c, avg(c) over (order by c rows between 1 preceding and 1 following) from t order by c").Check(testkit.Rows(
+		"10 15.0000", "20 20.0000", "30 30.0000", "40 40.0000", "50 50.0000", "60 55.0000"))
+	tk.MustQuery("select c, min(c) over (order by c rows between current row and unbounded following) from t where a = 1 order by c").Check(testkit.Rows(
+		"10 10", "20 20", "30 30"))
+
+	_, err := tk.Exec("select a from t where row_number() over (order by a) > 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidWindowUse), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select sum(c) over (order by c range 1 preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowUnsupported), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("select sum(c) over (order by c rows between unbounded following and current row) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameIllegal), IsTrue, Commentf("err %v", err))
+}
+
+func (s *testSuite) TestProjectionConcurrency(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b varchar(20))")
+	for i := 0; i < 300; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, 'v%d')", i, i%13))
+	}
+	tk.MustQuery("select @@tidb_projection_concurrency").Check(testkit.Rows("1"))
+	expected := tk.MustQuery("select a * 2, concat(b, '-', a), a + 1 > 100 from t order by a").Rows()
+	joined := tk.MustQuery("select t1.a + t2.a from t t1 join t t2 on t1.a = t2.a + 1 order by t1.a").Rows()
+	for _, concurrency := range []int{2, 8} {
+		tk.MustExec(fmt.Sprintf("set @@tidb_projection_concurrency = %d", concurrency))
+		tk.MustQuery("select @@tidb_projection_concurrency").Check(testkit.Rows(fmt.Sprintf("%d", concurrency)))
+		tk.MustQuery("select a * 2, concat(b, '-', a), a + 1 > 100 from t order by a").Check(expected)
+		tk.MustQuery("select t1.a + t2.a from t t1 join t t2 on t1.a = t2.a + 1 order by t1.a").Check(joined)
+		tk.MustQuery("select a * 2 from t order by a limit 3").Check(testkit.Rows("0", "2", "4"))
+
+		// The expressions with user variables are evaluated in order.
+		tk.MustExec("set @n = 0")
+		tk.MustQuery("select max(x) from (select @n := @n + 1 as x from t) s").Check(testkit.Rows("300"))
+	}
+	tk.MustExec("set @@tidb_projection_concurrency = 0")
+	tk.MustQuery("select @@tidb_projection_concurrency").Check(testkit.Rows("0"))
+	tk.MustQuery("select count(a * 2) from t").Check(testkit.Rows("300"))
+}
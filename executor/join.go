@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/mvmap"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -41,6 +42,7 @@ type HashJoinExec struct {
 	smallExec     Executor
 	bigExec       Executor
 	prepared      bool
+	fetching      bool
 	ctx           context.Context
 	smallFilter   expression.CNFExprs
 	bigFilter     expression.CNFExprs
@@ -65,6 +67,10 @@ type HashJoinExec struct {
 
 	// Channels for output.
 	resultCh chan *execResult
+
+	memTracker *memory.Tracker
+	// spill is set when the build side is partitioned to disk because the memory quota is exceeded.
+	spill *hashJoinSpill
 }
 
 // hashJoinCtx holds the variables needed to do a hash join in one of many concurrent goroutines.
@@ -74,6 +80,8 @@ type hashJoinCtx struct {
 	// datumBuffer is used for encode hash keys.
 	datumBuffer   []types.Datum
 	hashKeyBuffer []byte
+	// rowBuffer is used for encode the big table rows spilled to disk.
+	rowBuffer []byte
 }
 
 // Close implements the Executor Close interface.
@@ -83,13 +91,39 @@ func (e *HashJoinExec) Close() error {
 		for range e.resultCh {
 		}
 		<-e.closeCh
+	} else {
+		if e.fetching {
+			// The preparation failed, drain the channels so the goroutine fetching the big table rows can exit.
+			for _, ch := range e.bigTableResultCh {
+				go func(ch chan *execResult) {
+					for range ch {
+					}
+				}(ch)
+			}
+			e.wg.Wait()
+		}
+		// The spilled partitions are removed by the join workers if the executor has been prepared.
+		if e.spill != nil {
+			e.spill.close()
+		}
 	}
+	e.fetching = false
+	e.spill = nil
 	e.rows = nil
+	if e.memTracker != nil {
+		e.memTracker.Detach()
+		e.memTracker = nil
+	}
 	return nil
 }
 
 // Open implements the Executor Open interface.
 func (e *HashJoinExec) Open() error {
+	var err error
+	e.memTracker, err = newMemTracker(e.ctx, "HashJoinExec")
+	if err != nil {
+		return errors.Trace(err)
+	}
 	e.closeCh = make(chan struct{})
 	e.finished.Store(false)
 	e.bigTableResultCh = make([]chan *execResult, e.concurrency)
@@ -99,7 +133,7 @@ func (e *HashJoinExec) Open() error {
 	}
 	e.prepared = false
 	e.cursor = 0
-	err := e.smallExec.Open()
+	err = e.smallExec.Open()
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -205,6 +239,7 @@ func (e *HashJoinExec) fetchBigExec() {
 func (e *HashJoinExec) prepare() error {
 	// Start a worker to fetch big table rows.
 	e.wg.Add(1)
+	e.fetching = true
 	go e.fetchBigExec()
 
 	e.hashTable = mvmap.NewMVMap()
@@ -239,6 +274,24 @@ func (e *HashJoinExec) prepare() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if e.spill != nil {
+			if err = e.spill.appendBuild(joinKey, buffer); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+		if err = e.memTracker.Consume(int64(len(joinKey) + len(buffer))); err != nil {
+			if !e.ctx.GetSessionVars().EnableSpill || !memory.ErrMemoryExceeded.Equal(err) {
+				return errors.Trace(err)
+			}
+			if err = e.spillHashTable(); err != nil {
+				return errors.Trace(err)
+			}
+			if err = e.spill.appendBuild(joinKey, buffer); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
 		e.hashTable.Put(joinKey, buffer)
 	}
 
@@ -266,13 +319,13 @@ func (e *HashJoinExec) encodeRow(b []byte, row Row) ([]byte, error) {
 	return b, nil
 }
 
-func (e *HashJoinExec) decodeRow(data []byte) (Row, error) {
-	values := make([]types.Datum, e.smallExec.Schema().Len())
+func (e *HashJoinExec) decodeRow(data []byte, schema *expression.Schema) (Row, error) {
+	values := make([]types.Datum, schema.Len())
 	err := codec.SetRawValues(data, values)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	err = decodeRawValues(values, e.smallExec.Schema(), e.ctx.GetSessionVars().GetTimeZone())
+	err = decodeRawValues(values, schema, e.ctx.GetSessionVars().GetTimeZone())
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -281,6 +334,10 @@ func (e *HashJoinExec) decodeRow(data []byte) (Row, error) {
 
 func (e *HashJoinExec) waitJoinWorkersAndCloseResultChan() {
 	e.wg.Wait()
+	if e.spill != nil {
+		e.joinSpilledPartitions()
+		e.spill.close()
+	}
 	close(e.resultCh)
 	e.hashTable = nil
 	close(e.closeCh)
@@ -312,7 +369,12 @@ func (e *HashJoinExec) runJoinWorker(idx int) {
 			break
 		}
 		for _, bigRow := range bigTableResult.rows {
-			succ := e.joinOneBigRow(e.hashJoinContexts[idx], bigRow, result)
+			var succ bool
+			if e.spill != nil {
+				succ = e.spillOneBigRow(e.hashJoinContexts[idx], bigRow, result)
+			} else {
+				succ = e.joinOneBigRow(e.hashJoinContexts[idx], bigRow, result)
+			}
 			if !succ {
 				break
 			}
@@ -376,7 +438,7 @@ func (e *HashJoinExec) constructMatchedRows(ctx *hashJoinCtx, bigRow Row) (match
 	// match eq condition
 	for _, value := range values {
 		var smallRow Row
-		smallRow, err = e.decodeRow(value)
+		smallRow, err = e.decodeRow(value, e.smallExec.Schema())
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -0,0 +1,283 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/disk"
+	"github.com/pingcap/tidb/util/mvmap"
+)
+
+// spillPartitionCount is the number of partitions the hash join splits both sides into when it spills.
+var spillPartitionCount = 16
+
+// hashJoinSpill holds the partitions of a hash join spilled to disk, it's a grace hash join: the rows of both
+// sides are partitioned by the hash of the join key, so a row can only match the rows in the same partition
+// of the other side, and the partitions are joined one by one with a hash table built for one partition at a time.
+type hashJoinSpill struct {
+	dir        string
+	buildFiles []*disk.File
+	probeFiles []*disk.File
+	// probeLocks protects the probe files, which are appended to by the join workers concurrently.
+	probeLocks []sync.Mutex
+	buffer     []byte
+}
+
+func newHashJoinSpill() (*hashJoinSpill, error) {
+	dir, err := ioutil.TempDir("", "tidb_hash_join")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	s := &hashJoinSpill{
+		dir:        dir,
+		buildFiles: make([]*disk.File, spillPartitionCount),
+		probeFiles: make([]*disk.File, spillPartitionCount),
+		probeLocks: make([]sync.Mutex, spillPartitionCount),
+	}
+	for i := 0; i < spillPartitionCount; i++ {
+		if s.buildFiles[i], err = disk.NewFile(dir, "build"); err != nil {
+			s.close()
+			return nil, errors.Trace(err)
+		}
+		if s.probeFiles[i], err = disk.NewFile(dir, "probe"); err != nil {
+			s.close()
+			return nil, errors.Trace(err)
+		}
+	}
+	return s, nil
+}
+
+func partitionOfJoinKey(joinKey []byte) int {
+	h := fnv.New32a()
+	h.Write(joinKey)
+	return int(h.Sum32() % uint32(spillPartitionCount))
+}
+
+// encodeSpillRecord encodes the join key and the encoded row into a record of the partition files.
+func encodeSpillRecord(b []byte, joinKey []byte, row []byte) []byte {
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(len(joinKey)))
+	b = append(b, header[:n]...)
+	b = append(b, joinKey...)
+	return append(b, row...)
+}
+
+func decodeSpillRecord(record []byte) (joinKey []byte, row []byte, err error) {
+	size, n := binary.Uvarint(record)
+	if n <= 0 || uint64(len(record)-n) < size {
+		return nil, nil, errors.New("invalid spilled record")
+	}
+	return record[n : n+int(size)], record[n+int(size):], nil
+}
+
+// appendBuild appends a row of the small table to its partition, it's only called by the builder.
+func (s *hashJoinSpill) appendBuild(joinKey []byte, row []byte) error {
+	s.buffer = encodeSpillRecord(s.buffer[:0], joinKey, row)
+	return errors.Trace(s.buildFiles[partitionOfJoinKey(joinKey)].Append(s.buffer))
+}
+
+// appendProbe appends a row of the big table to its partition. Only the row is stored,
+// the join key is computed again when the row is joined.
+func (s *hashJoinSpill) appendProbe(joinKey []byte, row []byte) error {
+	idx := partitionOfJoinKey(joinKey)
+	s.probeLocks[idx].Lock()
+	err := s.probeFiles[idx].Append(row)
+	s.probeLocks[idx].Unlock()
+	return errors.Trace(err)
+}
+
+// close removes the partition files and counts the spilled bytes.
+func (s *hashJoinSpill) close() {
+	var spilled int64
+	for _, files := range [][]*disk.File{s.buildFiles, s.probeFiles} {
+		for _, f := range files {
+			if f == nil {
+				continue
+			}
+			spilled += f.Bytes()
+			if err := f.Close(); err != nil {
+				log.Warnf("[hash join] remove the spilled file failed %v", err)
+			}
+		}
+	}
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.Warnf("[hash join] remove the spill directory %s failed %v", s.dir, err)
+	}
+	spilledBytesCounter.Add(float64(spilled))
+}
+
+// spillHashTable moves the rows in the hash table to the build partitions, and releases the memory of the hash table.
+// The big table rows are spilled to the probe partitions since then.
+func (e *HashJoinExec) spillHashTable() error {
+	spill, err := newHashJoinSpill()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.spill = spill
+	log.Infof("[hash join] the memory quota is exceeded, spill the hash table to %s", spill.dir)
+	it := e.hashTable.NewIterator()
+	for {
+		joinKey, row := it.Next()
+		if joinKey == nil {
+			break
+		}
+		if err = spill.appendBuild(joinKey, row); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	e.hashTable = mvmap.NewMVMap()
+	return errors.Trace(e.memTracker.Consume(-e.memTracker.BytesConsumed()))
+}
+
+// spillOneBigRow appends a row of the big table to its probe partition.
+// The row can't match any row if it's filtered out or its join key has null, the null filled result row is
+// created at once for outer join.
+func (e *HashJoinExec) spillOneBigRow(ctx *hashJoinCtx, bigRow Row, result *execResult) bool {
+	bigMatched, err := expression.EvalBool(ctx.bigFilter, bigRow, e.ctx)
+	if err != nil {
+		result.err = errors.Trace(err)
+		return false
+	}
+	if bigMatched {
+		hasNull, joinKey, err := getJoinKey(e.bigHashKey, bigRow, ctx.datumBuffer, ctx.hashKeyBuffer[0:0:cap(ctx.hashKeyBuffer)])
+		if err != nil {
+			result.err = errors.Trace(err)
+			return false
+		}
+		if !hasNull {
+			ctx.rowBuffer, err = e.encodeRow(ctx.rowBuffer[:0], bigRow)
+			if err != nil {
+				result.err = errors.Trace(err)
+				return false
+			}
+			if err = e.spill.appendProbe(joinKey, ctx.rowBuffer); err != nil {
+				result.err = errors.Trace(err)
+				return false
+			}
+			return true
+		}
+	}
+	if e.outer {
+		result.rows = append(result.rows, e.fillRowWithDefaultValues(bigRow))
+	}
+	return true
+}
+
+// joinSpilledPartitions joins the spilled partitions one by one after all the big table rows are partitioned,
+// and sends the result rows to the result channel.
+func (e *HashJoinExec) joinSpilledPartitions() {
+	maxRowsCnt := 1000
+	txnCtx := e.ctx.GoCtx()
+	ctx := e.hashJoinContexts[0]
+	send := func(result *execResult) bool {
+		select {
+		case <-txnCtx.Done():
+			return false
+		case e.resultCh <- result:
+			return true
+		}
+	}
+	for i := 0; i < spillPartitionCount; i++ {
+		if e.finished.Load().(bool) {
+			return
+		}
+		result := &execResult{rows: make([]Row, 0, maxRowsCnt)}
+		err := e.joinSpilledPartition(ctx, i, func(rows []Row) bool {
+			result.rows = append(result.rows, rows...)
+			if len(result.rows) < maxRowsCnt {
+				return true
+			}
+			if !send(result) {
+				return false
+			}
+			result = &execResult{rows: make([]Row, 0, maxRowsCnt)}
+			return !e.finished.Load().(bool)
+		})
+		if err != nil {
+			send(&execResult{err: errors.Trace(err)})
+			return
+		}
+		if len(result.rows) > 0 && !send(result) {
+			return
+		}
+	}
+}
+
+// joinSpilledPartition builds the hash table of a build partition and probes it with the probe partition.
+// The hash table of a partition has to fit in the memory quota. It stops when emit returns false.
+func (e *HashJoinExec) joinSpilledPartition(ctx *hashJoinCtx, idx int, emit func([]Row) bool) error {
+	defer func() {
+		e.hashTable = nil
+		e.memTracker.Consume(-e.memTracker.BytesConsumed())
+	}()
+	e.hashTable = mvmap.NewMVMap()
+	r, err := e.spill.buildFiles[idx].NewReader()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		record, err := r.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if record == nil {
+			break
+		}
+		joinKey, row, err := decodeSpillRecord(record)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = e.memTracker.Consume(int64(len(joinKey) + len(row))); err != nil {
+			return errors.Trace(err)
+		}
+		e.hashTable.Put(joinKey, row)
+	}
+
+	r, err = e.spill.probeFiles[idx].NewReader()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	bigSchema := e.bigExec.Schema()
+	for {
+		row, err := r.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+		bigRow, err := e.decodeRow(row, bigSchema)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		matchedRows, err := e.constructMatchedRows(ctx, bigRow)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(matchedRows) == 0 && e.outer {
+			matchedRows = append(matchedRows, e.fillRowWithDefaultValues(bigRow))
+		}
+		if len(matchedRows) > 0 && !emit(matchedRows) {
+			return nil
+		}
+	}
+}
@@ -15,6 +15,10 @@ package executor_test
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	. "github.com/pingcap/check"
@@ -24,6 +28,9 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
@@ -179,14 +186,32 @@ func (s *testSuite) TestJoin(c *C) {
 	result = tk.MustQuery("select a.c1 from t a , (select * from t1 limit 3) b where a.c1 = b.c1 order by b.c1;")
 	result.Check(testkit.Rows("1", "2", "3"))
 
-	plan.AllowCartesianProduct = false
+	tk.MustExec("set @@tidb_allow_cartesian_product = 0")
 	_, err := tk.Exec("select * from t, t1")
 	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
 	_, err = tk.Exec("select * from t left join t1 on 1")
 	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
 	_, err = tk.Exec("select * from t right join t1 on 1")
 	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
-	plan.AllowCartesianProduct = true
+	tk.MustExec("set @@tidb_allow_cartesian_product = 1")
+
+	defer func(v int) { plan.MaxJoinTables = v }(plan.MaxJoinTables)
+	plan.MaxJoinTables = 3
+	tk.MustQuery("select count(*) from t a, t1 b, t1 c where a.c1 = b.c1 and b.c1 = c.c1").Check(testkit.Rows("7"))
+	// The subqueries are counted as single tables.
+	tk.MustQuery("select count(*) from t a, t1 b, (select c.c1 from t c, t1 d where c.c1 = d.c1) e where a.c1 = b.c1 and b.c1 = e.c1").Check(testkit.Rows("7"))
+	_, err = tk.Exec("select * from t a, t1 b, t1 c, t d")
+	c.Check(plan.ErrTooManyTables.Equal(err), IsTrue)
+	_, err = tk.Exec("select * from t a join t1 b on a.c1 = b.c1 left join (t1 c, t d) on a.c1 = c.c1")
+	c.Check(plan.ErrTooManyTables.Equal(err), IsTrue)
+	_, err = tk.Exec("select * from t where c1 in (select a.c1 from t a, t1 b, t1 c, t d)")
+	c.Check(plan.ErrTooManyTables.Equal(err), IsTrue)
+	_, err = tk.Exec("update t a, t1 b, t1 c, t d set a.c1 = 1")
+	c.Check(plan.ErrTooManyTables.Equal(err), IsTrue)
+	_, err = tk.Exec("delete a from t a, t1 b, t1 c, t d")
+	c.Check(plan.ErrTooManyTables.Equal(err), IsTrue)
+	plan.MaxJoinTables = 0
+	tk.MustQuery("select count(*) from t a, t1 b, t1 c, t d where a.c1 = b.c1 and b.c1 = c.c1 and c.c1 = d.c1").Check(testkit.Rows("7"))
 	tk.MustExec("drop table if exists t,t2,t1")
 	tk.MustExec("create table t(c1 int)")
 	tk.MustExec("create table t1(c1 int, c2 int)")
@@ -232,6 +257,34 @@ func (s *testSuite) TestJoin(c *C) {
 
 }
 
+func (s *testSuite) TestAllowCartesianProduct(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t(a int)")
+	tk.MustExec("create table t1(a int)")
+	tk.MustExec("insert into t values (1), (2)")
+	tk.MustExec("insert into t1 values (3)")
+
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+	tk2.MustQuery("select @@tidb_allow_cartesian_product").Check(testkit.Rows("1"))
+
+	// The variable only affects the session which sets it.
+	tk.MustExec("set @@tidb_allow_cartesian_product = 0")
+	tk.MustQuery("select @@tidb_allow_cartesian_product").Check(testkit.Rows("0"))
+	_, err := tk.Exec("select * from t, t1")
+	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
+	tk2.MustQuery("select t.a, t1.a from t, t1 order by t.a").Check(testkit.Rows("1 3", "2 3"))
+	tk.MustQuery("select t.a from t, t1 where t.a + 2 = t1.a").Check(testkit.Rows("1"))
+
+	tk2.MustExec("set @@tidb_allow_cartesian_product = 0")
+	_, err = tk2.Exec("select * from t left join t1 on 1")
+	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
+	tk.MustExec("set @@tidb_allow_cartesian_product = 1")
+	tk.MustQuery("select t.a, t1.a from t left join t1 on 1 order by t.a").Check(testkit.Rows("1 3", "2 3"))
+}
+
 func (s *testSuite) TestJoinCast(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -458,14 +511,13 @@ func (s *testSuite) TestSubquerySameTable(c *C) {
 }
 
 func (s *testSuite) TestSubquery(c *C) {
-	plan.JoinConcurrency = 1
 	defer func() {
 		s.cleanEnv(c)
 		testleak.AfterTest(c)()
-		plan.JoinConcurrency = 5
 	}()
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_hash_join_concurrency = 1")
 	tk.MustExec("drop table if exists t")
 	tk.MustExec("create table t (c int, d int)")
 	tk.MustExec("insert t values (1, 1)")
@@ -661,16 +713,39 @@ func (s *testSuite) TestInSubquery(c *C) {
 	result.Check(testkit.Rows("2", "2", "1"))
 }
 
+func (s *testSuite) TestHashJoinConcurrency(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1(a int, b int)")
+	tk.MustExec("create table t2(a int, b int)")
+	for i := 0; i < 50; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t1 values(%d, %d)", i, i%7))
+		tk.MustExec(fmt.Sprintf("insert into t2 values(%d, %d)", i%10, i))
+	}
+	tk.MustQuery("select @@tidb_hash_join_concurrency").Check(testkit.Rows("5"))
+	expected := tk.MustQuery("select t1.a, count(*), sum(t2.b) from t1 join t2 on t1.a = t2.a group by t1.a order by t1.a").Rows()
+	outer := tk.MustQuery("select count(*), sum(t2.b) from t1 left join t2 on t1.b = t2.a").Rows()
+	for _, concurrency := range []int{1, 2, 16} {
+		tk.MustExec(fmt.Sprintf("set @@tidb_hash_join_concurrency = %d", concurrency))
+		tk.MustQuery("select @@tidb_hash_join_concurrency").Check(testkit.Rows(fmt.Sprintf("%d", concurrency)))
+		tk.MustQuery("select t1.a, count(*), sum(t2.b) from t1 join t2 on t1.a = t2.a group by t1.a order by t1.a").Check(expected)
+		tk.MustQuery("select count(*), sum(t2.b) from t1 left join t2 on t1.b = t2.a").Check(outer)
+	}
+}
+
 func (s *testSuite) TestJoinLeak(c *C) {
-	savedConcurrency := plan.JoinConcurrency
-	plan.JoinConcurrency = 1
 	defer func() {
-		plan.JoinConcurrency = savedConcurrency
 		s.cleanEnv(c)
 		testleak.AfterTest(c)()
 	}()
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_hash_join_concurrency = 1")
 	tk.MustExec("drop table if exists t")
 	tk.MustExec("create table t (d int)")
 	tk.MustExec("begin")
@@ -698,3 +773,114 @@ func (s *testSuite) TestHashJoinExecEncodeDecodeRow(c *C) {
 	result := tk.MustQuery("select ts from t1 inner join t2 where t2.name = 'xxx'")
 	result.Check(testkit.Rows("2003-06-09 10:51:26"))
 }
+
+func (s *testSuite) TestIndexLookupSizeInJoin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int primary key, b int)")
+	tk.MustExec("create table t2 (a int, b int, index idx_b(b))")
+	tk.MustExec("insert into t1 values (1, 10), (2, 20), (3, 30), (4, 40)")
+	tk.MustExec("insert into t2 values (11, 1), (12, 1), (21, 2), (31, 3), (51, 5)")
+
+	sql := "select /*+ TIDB_INLJ(t2) */ t1.a, t1.b, t2.a from t1 join t2 on t1.a = t2.b order by t2.a"
+	expected := testkit.Rows("1 10 11", "1 10 12", "2 20 21", "3 30 31")
+	tk.MustQuery("select @@tidb_index_lookup_size").Check(testkit.Rows("20000"))
+	tk.MustQuery(sql).Check(expected)
+	for _, size := range []string{"1", "2", "3", "100"} {
+		tk.MustExec("set @@tidb_index_lookup_size = " + size)
+		tk.MustQuery(sql).Check(expected)
+	}
+	// The invalid value falls back to the default value.
+	tk.MustExec("set @@tidb_index_lookup_size = 0")
+	c.Assert(tk.Se.GetSessionVars().IndexLookupSize, Equals, variable.DefaultIndexLookupSize())
+	tk.MustQuery(sql).Check(expected)
+}
+
+func (s *testSuite) TestHashJoinSpill(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b varchar(64))")
+	tk.MustExec("create table t2 (a int, b varchar(64))")
+	for i := 0; i < 300; i++ {
+		a := fmt.Sprintf("%d", i%100)
+		if i%37 == 0 {
+			a = "NULL"
+		}
+		tk.MustExec(fmt.Sprintf("insert into t1 values (%s, '%s')", a, strings.Repeat("x", i%50)))
+		tk.MustExec(fmt.Sprintf("insert into t2 values (%s, '%s')", a, strings.Repeat("x", i%41)))
+	}
+	queries := []string{
+		"select * from t1 join t2 on t1.a = t2.a",
+		"select * from t1 join t2 on t1.a = t2.a and t1.b < t2.b",
+		"select * from t1 left join t2 on t1.a = t2.a and t2.a > 50",
+		"select * from t1 right join t2 on t1.a = t2.a and length(t1.b) > 10",
+		"select t1.a, t2.b from t1 join t2 on t1.a = t2.a and t1.b = t2.b",
+	}
+	// The rows are compared as sorted strings, since the order of the rows produced by the hash join is not fixed.
+	sortedRows := func(sql string) []string {
+		var rows []string
+		for _, row := range tk.MustQuery(sql).Rows() {
+			rows = append(rows, fmt.Sprintf("%v", row))
+		}
+		sort.Strings(rows)
+		return rows
+	}
+	expected := make([][]string, 0, len(queries))
+	for _, sql := range queries {
+		expected = append(expected, sortedRows(sql))
+	}
+	spillDirs := func() int {
+		dirs, err := filepath.Glob(filepath.Join(os.TempDir(), "tidb_hash_join*"))
+		c.Assert(err, IsNil)
+		return len(dirs)
+	}
+	dirCount := spillDirs()
+
+	// The hash join fails when the memory quota is exceeded and spilling is disabled.
+	tk.MustExec("set @@tidb_mem_quota_query = 2000")
+	for _, sql := range queries {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil)
+		_, err = tidb.GetRows(rs)
+		c.Assert(terror.ErrorEqual(err, memory.ErrMemoryExceeded), IsTrue, Commentf("sql %s, err %v", sql, err))
+	}
+
+	// The partitions spilled to disk produce the same results, and they're removed when the statements finish.
+	tk.MustExec("set @@tidb_enable_spill = 1")
+	for i, sql := range queries {
+		c.Assert(sortedRows(sql), DeepEquals, expected[i], Commentf("sql %s", sql))
+		c.Assert(spillDirs(), Equals, dirCount, Commentf("sql %s", sql))
+	}
+
+	// The partitions are removed when the statement is closed before all the rows are read.
+	rs, err := tk.Exec(queries[0])
+	c.Assert(err, IsNil)
+	row, err := rs.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row, NotNil)
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(spillDirs(), Equals, dirCount)
+
+	// The hash table of a partition still has to fit in the memory quota.
+	tk.MustExec("set @@tidb_mem_quota_query = 10")
+	rs, err = tk.Exec(queries[0])
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(terror.ErrorEqual(err, memory.ErrMemoryExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(spillDirs(), Equals, dirCount)
+
+	tk.MustExec("set @@tidb_mem_quota_query = 0")
+	_, err = tk.Exec("set @@tidb_mem_quota_query = -1")
+	c.Assert(err, NotNil)
+}
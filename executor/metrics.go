@@ -40,11 +40,19 @@ var (
 			Name:      "expensive_query_total",
 			Help:      "Counter of expensive query.",
 		}, []string{"type"})
+	spilledBytesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "spilled_bytes_total",
+			Help:      "Counter of the bytes spilled to disk by the executors exceeding the memory quota.",
+		})
 )
 
 func init() {
 	prometheus.MustRegister(stmtNodeCounter)
 	prometheus.MustRegister(expensiveQueryCounter)
+	prometheus.MustRegister(spilledBytesCounter)
 }
 
 func stmtCount(node ast.StmtNode, p plan.Plan, inRestrictedSQL bool) bool {
@@ -136,7 +144,7 @@ func StatementLabel(node ast.StmtNode, p plan.Plan, isExpensive *bool) string {
 		return DropIndex
 	case *ast.DropTableStmt:
 		return DropTable
-	case *ast.ExplainStmt:
+	case *ast.ExplainStmt, *ast.ExplainForStmt:
 		return Explain
 	case *ast.InsertStmt:
 		if x.IsReplace {
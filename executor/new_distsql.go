@@ -22,6 +22,8 @@ import (
 	"github.com/pingcap/tidb/distsql"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
@@ -183,6 +185,8 @@ type IndexReaderExecutor struct {
 	// columns are only required by union scan.
 	columns  []*model.ColumnInfo
 	priority int
+	// feedback collects the actual row count of the scan, it's nil if the feedback is not needed.
+	feedback *statistics.QueryFeedback
 }
 
 // Schema implements the Executor Schema interface.
@@ -210,6 +214,7 @@ func (e *IndexReaderExecutor) Next() (Row, error) {
 			}
 			if e.partialResult == nil {
 				// Finished.
+				e.storeFeedback()
 				return nil, nil
 			}
 		}
@@ -224,6 +229,9 @@ func (e *IndexReaderExecutor) Next() (Row, error) {
 			e.partialResult = nil
 			continue
 		}
+		if e.feedback != nil {
+			e.feedback.Update(1)
+		}
 		values := make([]types.Datum, e.schema.Len())
 		if handleIsExtra(e.handleCol) {
 			err = codec.SetRawValues(rowData, values[:len(values)-1])
@@ -242,6 +250,17 @@ func (e *IndexReaderExecutor) Next() (Row, error) {
 	}
 }
 
+// storeFeedback hands the feedback to the stats handle, it's only called when the whole ranges are scanned.
+func (e *IndexReaderExecutor) storeFeedback() {
+	if e.feedback == nil {
+		return
+	}
+	if dom := sessionctx.GetDomain(e.ctx); dom != nil && dom.StatsHandle() != nil {
+		dom.StatsHandle().StoreQueryFeedback(e.feedback)
+	}
+	e.feedback = nil
+}
+
 // Open implements the Executor Open interface.
 func (e *IndexReaderExecutor) Open() error {
 	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
@@ -262,6 +281,8 @@ func (e *IndexReaderExecutor) Open() error {
 
 // doRequestForDatums constructs kv ranges by datums. It is used by index look up executor.
 func (e *IndexReaderExecutor) doRequestForDatums(values [][]types.Datum, goCtx goctx.Context) error {
+	// The scanned ranges are not the ones the feedback is built on.
+	e.feedback = nil
 	kvRanges, err := indexValuesToKVRanges(e.tableID, e.index.ID, values)
 	if err != nil {
 		return errors.Trace(err)
@@ -14,18 +14,23 @@
 package executor
 
 import (
+	"fmt"
 	"math"
 	"sort"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/sqlexec"
 )
 
@@ -145,6 +150,10 @@ func (e *PrepareExec) DoPrepare() {
 	}
 	var extractor paramMarkerExtractor
 	stmt.Accept(&extractor)
+	if maxParams := maxPreparedStmtParams(); len(extractor.markers) > maxParams {
+		e.Err = ErrPsManyParam.GenByArgs(maxParams)
+		return
+	}
 	err = plan.Preprocess(stmt, e.IS, e.Ctx)
 	if err != nil {
 		e.Err = errors.Trace(err)
@@ -181,6 +190,15 @@ func (e *PrepareExec) DoPrepare() {
 	vars.PreparedStmts[e.ID] = prepared
 }
 
+// maxPreparedStmtParams returns the max number of the placeholders in a prepared statement.
+func maxPreparedStmtParams() int {
+	maxParams := config.GetGlobalConfig().MaxPreparedStmtParams
+	if maxParams <= 0 || maxParams > config.DefMaxPreparedStmtParams {
+		return config.DefMaxPreparedStmtParams
+	}
+	return maxParams
+}
+
 // ExecuteExec represents an EXECUTE executor.
 // It cannot be executed by itself, all it needs to do is to build
 // another Executor from a prepared statement.
@@ -230,6 +248,10 @@ func (e *ExecuteExec) Build() error {
 		return errors.Trace(ErrStmtNotFound)
 	}
 	prepared := v.(*Prepared)
+	// The digest may be blocked after the statement is prepared, so it's checked on every execution.
+	if err := checkPreparedBlocklist(e.Ctx, prepared); err != nil {
+		return errors.Trace(err)
+	}
 
 	if len(prepared.Params) != len(e.UsingVars) {
 		return errors.Trace(ErrWrongParamCount)
@@ -242,6 +264,17 @@ func (e *ExecuteExec) Build() error {
 		}
 		prepared.Params[i].SetDatum(val)
 	}
+	snapshotTS, err := getAsOfTS(e.Ctx, prepared.Stmt)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if snapshotTS != 0 {
+		e.Ctx.GetSessionVars().StmtCtx.SnapshotTS = snapshotTS
+		e.IS, err = sessionctx.GetDomain(e.Ctx).GetSnapshotInfoSchema(snapshotTS)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	if prepared.SchemaVersion != e.IS.SchemaMetaVersion() {
 		// If the schema version has changed we need to prepare it again,
 		// if this time it failed, the real reason for the error is schema changed.
@@ -272,6 +305,7 @@ func (e *ExecuteExec) Build() error {
 	e.Stmt = prepared.Stmt
 	e.Plan = p
 	ResetStmtCtx(e.Ctx, e.Stmt)
+	e.Ctx.GetSessionVars().StmtCtx.SnapshotTS = snapshotTS
 	stmtCount(e.Stmt, e.Plan, e.Ctx.GetSessionVars().InRestrictedSQL)
 	return nil
 }
@@ -310,6 +344,23 @@ func (e *DeallocateExec) Open() error {
 	return nil
 }
 
+// checkPreparedBlocklist returns an error if the digest of the prepared statement is in the blocklist.
+// The placeholders and the literals are normalized to the same token, so a prepared statement has the same
+// digest as the statement it is prepared from.
+func checkPreparedBlocklist(ctx context.Context, prepared *Prepared) error {
+	if ctx.GetSessionVars().InRestrictedSQL {
+		return nil
+	}
+	blocklist := sessionctx.GetDomain(ctx).DigestBlocklist()
+	if blocklist.Empty() {
+		return nil
+	}
+	if digest := parser.Digest(prepared.Stmt.Text()); blocklist.Blocked(digest) {
+		return domain.ErrQueryBlocked.GenByArgs(digest)
+	}
+	return nil
+}
+
 // CompileExecutePreparedStmt compiles a session Execute command to a stmt.Statement.
 func CompileExecutePreparedStmt(ctx context.Context, ID uint32, args ...interface{}) ast.Statement {
 	execPlan := &plan.Execute{ExecID: ID}
@@ -341,9 +392,16 @@ func ResetStmtCtx(ctx context.Context, s ast.StmtNode) {
 		sc.OverflowAsWarning = false
 		sc.TruncateAsWarning = !sessVars.StrictSQLMode
 		sc.InUpdateOrDeleteStmt = true
+		if update, ok := s.(*ast.UpdateStmt); ok {
+			sc.DividedByZeroAsWarning = update.Ignore
+		}
 	case *ast.InsertStmt:
+		// INSERT IGNORE treats data conversion and NOT NULL errors as warnings even in strict mode,
+		// duplicate-key errors are handled by the insert executor.
 		sc.IgnoreTruncate = false
-		sc.TruncateAsWarning = !sessVars.StrictSQLMode
+		sc.TruncateAsWarning = !sessVars.StrictSQLMode || stmt.Ignore
+		sc.BadNullAsWarning = stmt.Ignore
+		sc.DividedByZeroAsWarning = stmt.Ignore
 		sc.InInsertStmt = true
 	case *ast.CreateTableStmt, *ast.AlterTableStmt:
 		// Make sure the sql_mode is strict when checking column default value.
@@ -384,5 +442,20 @@ func ResetStmtCtx(ctx context.Context, s ast.StmtNode) {
 		sessVars.LastInsertID = 0
 	}
 	sessVars.InsertID = 0
+	// Detach the tracker of the former statement, so the finished statements don't pile up in the global tracker.
+	if sessVars.StmtCtx.MemTracker != nil {
+		sessVars.StmtCtx.MemTracker.Detach()
+	}
+	sc.MemTracker = memory.NewTracker(fmt.Sprintf("the statement of connection %d", sessVars.ConnectionID))
+	sc.MemTracker.SetBytesLimit(sessVars.MemQuotaQuery)
+	if c, ok := ctx.(canceler); ok {
+		sc.MemTracker.SetKillFunc(c.Cancel)
+	}
+	sc.MemTracker.AttachTo(memory.GlobalTracker())
 	sessVars.StmtCtx = sc
 }
+
+// canceler is implemented by the session, which cancels the execution of the current statement.
+type canceler interface {
+	Cancel()
+}
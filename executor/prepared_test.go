@@ -14,9 +14,15 @@
 package executor_test
 
 import (
+	"strings"
+
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -92,6 +98,36 @@ func (s *testSuite) TestPrepared(c *C) {
 	exec.Close()
 }
 
+func (s *testSuite) TestPreparedMaxParams(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	cfg := config.GetGlobalConfig()
+	defer func(maxParams int) { cfg.MaxPreparedStmtParams = maxParams }(cfg.MaxPreparedStmtParams)
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+
+	// The default limit is the max number of the parameters in the protocol.
+	query := "select 1 from dual where 1 in (?" + strings.Repeat(", ?", config.DefMaxPreparedStmtParams) + ")"
+	_, _, _, err := tk.Se.PrepareStmt(query)
+	c.Assert(terror.ErrorEqual(err, executor.ErrPsManyParam), IsTrue, Commentf("err %v", err))
+	c.Assert(errors.Cause(err).(*terror.Error).ToSQLError().Code, Equals, uint16(mysql.ErrPsManyParam))
+
+	cfg.MaxPreparedStmtParams = 3
+	_, _, params, err := tk.Se.PrepareStmt("select ?, ?, ?")
+	c.Assert(err, IsNil)
+	c.Assert(params, HasLen, 3)
+	_, _, _, err = tk.Se.PrepareStmt("select ?, ?, ?, ?")
+	c.Assert(terror.ErrorEqual(err, executor.ErrPsManyParam), IsTrue, Commentf("err %v", err))
+	c.Assert(err.Error(), Matches, ".*the limit is 3")
+	_, err = tk.Exec("prepare stmt from 'select ? + ? + ? + ?'")
+	c.Assert(terror.ErrorEqual(err, executor.ErrPsManyParam), IsTrue, Commentf("err %v", err))
+	tk.MustExec("prepare stmt from 'select ? + ? + ?'")
+	tk.MustExec("set @a = 1")
+	tk.MustQuery("execute stmt using @a, @a, @a").Check(testkit.Rows("3"))
+}
+
 func (s *testSuite) TestPreparedLimitOffset(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -0,0 +1,205 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// projectionBatch is a batch of rows read from the child of a projection, a worker replaces
+// the rows by the projected ones and closes done.
+type projectionBatch struct {
+	rows []Row
+	err  error
+	done chan struct{}
+}
+
+// serialOnlyFunctions are the functions that read or write the session state, keep a state across
+// the rows or have side effects, the expressions calling them must be evaluated row by row in order.
+var serialOnlyFunctions = map[string]struct{}{
+	ast.SetVar:          {},
+	ast.GetVar:          {},
+	ast.LastInsertId:    {},
+	ast.FoundRows:       {},
+	ast.RowCount:        {},
+	ast.Rand:            {},
+	ast.RandomBytes:     {},
+	ast.UUID:            {},
+	ast.UUIDShort:       {},
+	ast.Sysdate:         {},
+	ast.Sleep:           {},
+	ast.Benchmark:       {},
+	ast.GetLock:         {},
+	ast.ReleaseLock:     {},
+	ast.ReleaseAllLocks: {},
+	ast.IsFreeLock:      {},
+	ast.IsUsedLock:      {},
+}
+
+// canEvalInParallel checks whether the expressions can be evaluated by multiple goroutines.
+func canEvalInParallel(exprs []expression.Expression) bool {
+	for _, expr := range exprs {
+		fun, ok := expr.(*expression.ScalarFunction)
+		if !ok {
+			continue
+		}
+		if _, ok := serialOnlyFunctions[fun.FuncName.L]; ok {
+			return false
+		}
+		if !canEvalInParallel(fun.GetArgs()) {
+			return false
+		}
+	}
+	return true
+}
+
+// prepare starts a goroutine fetching the rows from the child in batches, and the workers evaluating
+// the expressions of the batches.
+func (e *ProjectionExec) prepare() {
+	e.finished = make(chan struct{})
+	e.workCh = make(chan *projectionBatch, e.concurrency)
+	e.resultCh = make(chan *projectionBatch, e.concurrency)
+	e.rows = nil
+	e.cursor = 0
+	e.wg.Add(1)
+	go e.fetchChildRows()
+	for i := 0; i < e.concurrency; i++ {
+		exprs := make([]expression.Expression, 0, len(e.exprs))
+		for _, expr := range e.exprs {
+			exprs = append(exprs, expr.Clone())
+		}
+		e.wg.Add(1)
+		go e.runWorker(exprs)
+	}
+	e.prepared = true
+}
+
+// stopWorkers notifies the fetcher and the workers to exit and waits for them, so the child
+// is not read any more.
+func (e *ProjectionExec) stopWorkers() {
+	if !e.prepared {
+		return
+	}
+	close(e.finished)
+	e.wg.Wait()
+	e.rows = nil
+	e.prepared = false
+}
+
+func (e *ProjectionExec) fetchChildRows() {
+	defer func() {
+		close(e.workCh)
+		close(e.resultCh)
+		e.wg.Done()
+	}()
+	for {
+		batch := &projectionBatch{rows: make([]Row, 0, batchSize), done: make(chan struct{})}
+		for len(batch.rows) < batchSize {
+			row, err := e.children[0].Next()
+			if err != nil {
+				batch.err = errors.Trace(err)
+				break
+			}
+			if row == nil {
+				break
+			}
+			batch.rows = append(batch.rows, row)
+		}
+		if len(batch.rows) == 0 && batch.err == nil {
+			return
+		}
+		// The batch is queued for Next before it's evaluated to keep the order of the rows.
+		select {
+		case e.resultCh <- batch:
+		case <-e.finished:
+			return
+		}
+		if batch.err != nil {
+			close(batch.done)
+			return
+		}
+		select {
+		case e.workCh <- batch:
+		case <-e.finished:
+			return
+		}
+	}
+}
+
+func (e *ProjectionExec) runWorker(exprs []expression.Expression) {
+	defer e.wg.Done()
+	for {
+		var batch *projectionBatch
+		select {
+		case b, ok := <-e.workCh:
+			if !ok {
+				return
+			}
+			batch = b
+		case <-e.finished:
+			return
+		}
+		for i, srcRow := range batch.rows {
+			row := make([]types.Datum, 0, len(exprs))
+			for _, expr := range exprs {
+				val, err := expr.Eval(srcRow)
+				if err != nil {
+					batch.err = errors.Trace(err)
+					break
+				}
+				row = append(row, val)
+			}
+			if batch.err != nil {
+				break
+			}
+			batch.rows[i] = row
+		}
+		close(batch.done)
+	}
+}
+
+func (e *ProjectionExec) parallelNext() (Row, error) {
+	if !e.prepared {
+		e.prepare()
+	}
+	for e.cursor >= len(e.rows) {
+		var batch *projectionBatch
+		txnCtx := e.ctx.GoCtx()
+		select {
+		case b, ok := <-e.resultCh:
+			if !ok {
+				return nil, nil
+			}
+			batch = b
+		case <-txnCtx.Done():
+			return nil, errors.Trace(txnCtx.Err())
+		}
+		select {
+		case <-batch.done:
+		case <-txnCtx.Done():
+			return nil, errors.Trace(txnCtx.Err())
+		}
+		if batch.err != nil {
+			return nil, errors.Trace(batch.err)
+		}
+		e.rows = batch.rows
+		e.cursor = 0
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
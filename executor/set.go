@@ -24,10 +24,10 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
-	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -58,13 +58,22 @@ func (e *SetExecutor) executeSet() error {
 	for _, v := range e.vars {
 		// Variable is case insensitive, we use lower case.
 		if v.Name == ast.SetNames {
-			// This is set charset stmt.
+			// This is set names stmt.
 			cs := v.Expr.(*expression.Constant).Value.GetString()
 			var co string
 			if v.ExtendValue != nil {
 				co = v.ExtendValue.Value.GetString()
 			}
-			err := e.setCharset(cs, co)
+			err := varsutil.SetNames(sessionVars, cs, co)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+		if v.Name == ast.SetCharset {
+			// This is set character set stmt.
+			cs := v.Expr.(*expression.Constant).Value.GetString()
+			err := varsutil.SetCharacterSet(sessionVars, cs)
 			if err != nil {
 				return errors.Trace(err)
 			}
@@ -114,6 +123,10 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			svalue, err = varsutil.ValidateGlobalSystemVar(name, svalue)
+			if err != nil {
+				return errors.Trace(err)
+			}
 			err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(name, svalue)
 			if err != nil {
 				return errors.Trace(err)
@@ -127,6 +140,12 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if name == variable.TiDBOptDisableRules {
+				rules, _ := value.ToString()
+				if err = plan.CheckOptRules(varsutil.ParseOptRuleList(rules)); err != nil {
+					return errors.Trace(err)
+				}
+			}
 			oldSnapshotTS := sessionVars.SnapshotTS
 			err = varsutil.SetSessionSystemVar(sessionVars, name, value)
 			if err != nil {
@@ -177,22 +196,6 @@ func validateSnapshot(ctx context.Context, snapshotTS uint64) error {
 	return nil
 }
 
-func (e *SetExecutor) setCharset(cs, co string) error {
-	var err error
-	if len(co) == 0 {
-		co, err = charset.GetDefaultCollation(cs)
-		if err != nil {
-			return errors.Trace(err)
-		}
-	}
-	sessionVars := e.ctx.GetSessionVars()
-	for _, v := range variable.SetNamesVariables {
-		sessionVars.Systems[v] = cs
-	}
-	sessionVars.Systems[variable.CollationConnection] = co
-	return nil
-}
-
 func (e *SetExecutor) getVarValue(v *expression.VarAssignment, sysVar *variable.SysVar) (value types.Datum, err error) {
 	if v.IsDefault {
 		// To set a SESSION variable to the GLOBAL value or a GLOBAL value
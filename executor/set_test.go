@@ -18,6 +18,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -176,4 +177,33 @@ func (s *testSuite) TestSetCharset(c *C) {
 
 	// Issue 1523
 	tk.MustExec(`SET NAMES binary`)
+
+	tk.MustExec(`SET NAMES UTF8MB4 COLLATE utf8mb4_general_ci`)
+	tk.MustQuery(`select @@character_set_client, @@character_set_connection, @@character_set_results, @@collation_connection`).Check(
+		testkit.Rows("utf8mb4 utf8mb4 utf8mb4 utf8mb4_general_ci"))
+	_, err = tk.Exec(`SET NAMES gbk`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCharset), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec(`SET NAMES utf8 COLLATE latin1_bin`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrCollationCharset), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec(`SET NAMES utf8 COLLATE unknown_ci`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCollation), IsTrue, Commentf("err %v", err))
+	// The variables are unchanged by the failed statements.
+	tk.MustQuery(`select @@character_set_client, @@collation_connection`).Check(testkit.Rows("utf8mb4 utf8mb4_general_ci"))
+
+	// SET CHARACTER SET uses the charset of the current database for the connection.
+	tk.MustExec(`SET CHARACTER SET latin1`)
+	tk.MustQuery(`select @@character_set_client, @@character_set_results, @@character_set_connection = @@character_set_database, @@collation_connection = @@collation_database`).Check(
+		testkit.Rows("latin1 latin1 1 1"))
+	_, err = tk.Exec(`SET CHARSET gbk`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCharset), IsTrue, Commentf("err %v", err))
+
+	// The charset and collation variables are validated and changed together.
+	tk.MustExec(`SET character_set_connection = 'UTF8'`)
+	tk.MustQuery(`select @@character_set_connection, @@collation_connection`).Check(testkit.Rows("utf8 utf8_bin"))
+	tk.MustExec(`SET collation_connection = 'latin1_swedish_ci'`)
+	tk.MustQuery(`select @@character_set_connection, @@collation_connection`).Check(testkit.Rows("latin1 latin1_swedish_ci"))
+	_, err = tk.Exec(`SET character_set_client = 'gbk'`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCharset), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec(`SET collation_connection = 'gbk_chinese_ci'`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCollation), IsTrue, Commentf("err %v", err))
 }
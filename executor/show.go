@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
@@ -117,6 +118,8 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowStatsHistogram()
 	case ast.ShowStatsBuckets:
 		return e.fetchShowStatsBuckets()
+	case ast.ShowOptRules:
+		return e.fetchShowOptRules()
 	}
 	return nil
 }
@@ -343,24 +346,63 @@ func (e *ShowExec) fetchShowCharset() error {
 
 func (e *ShowExec) fetchShowVariables() error {
 	sessionVars := e.ctx.GetSessionVars()
-	for _, v := range variable.SysVars {
-		var err error
-		var value string
+	// Load all the global values at once instead of reading them one by one.
+	globalVars, err := sessionVars.GlobalVarsAccessor.GetAllSysVars()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	names := make([]string, 0, len(variable.SysVars))
+	for name := range variable.SysVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := variable.SysVars[name]
+		if e.GlobalScope && v.Scope == variable.ScopeSession {
+			// The session only variables don't have global values.
+			continue
+		}
+		value, ok := "", false
 		if !e.GlobalScope {
 			// Try to get Session Scope variable value first.
-			value, err = varsutil.GetSessionSystemVar(sessionVars, v.Name)
-		} else {
-			value, err = varsutil.GetGlobalSystemVar(sessionVars, v.Name)
+			value, ok = sessionVars.Systems[name]
 		}
-		if err != nil {
-			return errors.Trace(err)
+		if !ok && v.Scope&variable.ScopeGlobal != 0 {
+			value, ok = globalVars[name]
+		}
+		if !ok && v.Scope == variable.ScopeSession {
+			// The session only variables may be virtual, like tidb_current_ts.
+			value, err = varsutil.GetSessionSystemVar(sessionVars, name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			ok = true
 		}
-		row := types.MakeDatums(v.Name, value)
+		if !ok {
+			// The read only variables and the global variables not saved yet use the default values.
+			value = v.Value
+		}
+		row := types.MakeDatums(name, showBoolValue(v, value))
 		e.rows = append(e.rows, row)
 	}
 	return nil
 }
 
+// showBoolValue shows the value of a boolean variable as ON or OFF like MySQL, a boolean variable is recognized
+// by its default value.
+func showBoolValue(v *variable.SysVar, value string) string {
+	if v.Value != "ON" && v.Value != "OFF" {
+		return value
+	}
+	switch value {
+	case "1":
+		return "ON"
+	case "0":
+		return "OFF"
+	}
+	return value
+}
+
 func (e *ShowExec) fetchShowStatus() error {
 	sessionVars := e.ctx.GetSessionVars()
 	statusVars, err := variable.GetStatusVars(sessionVars)
@@ -506,6 +548,13 @@ func (e *ShowExec) fetchShowCreateTable() error {
 			buf.WriteString(fmt.Sprintf(" ON UPDATE %s", ast.ReferOptionType(fk.OnUpdate)))
 		}
 	}
+
+	for _, ck := range tb.Meta().Checks {
+		if ck.State != model.StatePublic {
+			continue
+		}
+		buf.WriteString(fmt.Sprintf(",\n  CONSTRAINT `%s` CHECK (%s)", ck.Name.O, ck.ExprString))
+	}
 	buf.WriteString("\n")
 
 	buf.WriteString(") ENGINE=InnoDB")
@@ -525,6 +574,15 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		buf.WriteString(fmt.Sprintf(" AUTO_INCREMENT=%d", tb.Meta().AutoIncID))
 	}
 
+	if tb.Meta().ShardRowIDBits > 0 {
+		// The TiDB specific options are written in a "/*T! */" comment, which MySQL ignores.
+		buf.WriteString(fmt.Sprintf(" /*T! SHARD_ROW_ID_BITS=%d", tb.Meta().ShardRowIDBits))
+		if tb.Meta().PreSplitRegions > 0 {
+			buf.WriteString(fmt.Sprintf(" PRE_SPLIT_REGIONS=%d", tb.Meta().PreSplitRegions))
+		}
+		buf.WriteString(" */")
+	}
+
 	if len(tb.Meta().Comment) > 0 {
 		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", tb.Meta().Comment))
 	}
@@ -617,6 +675,18 @@ func (e *ShowExec) fetchShowWarnings() error {
 	return nil
 }
 
+func (e *ShowExec) fetchShowOptRules() error {
+	disabled := e.ctx.GetSessionVars().DisabledOptRules
+	for _, rule := range plan.OptRules {
+		isDisabled := 0
+		if plan.IsOptRuleDisabled(disabled, rule) {
+			isDisabled = 1
+		}
+		e.rows = append(e.rows, types.MakeDatums(rule.Name, rule.Description, isDisabled))
+	}
+	return nil
+}
+
 func (e *ShowExec) getTable() (table.Table, error) {
 	if e.Table == nil {
 		return nil, errors.New("table not found")
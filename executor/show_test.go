@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
@@ -130,6 +131,59 @@ func (s *testSuite) TestShow(c *C) {
 	c.Check(result.Rows(), HasLen, 1)
 }
 
+func (s *testSuite) TestShowVariables(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	defer func() {
+		tk.MustExec("set @@global.tidb_ddl_reorg_batch_size = 128")
+		tk.MustExec("set @@global.tidb_distsql_scan_concurrency = 10")
+	}()
+
+	// The session only variables are not shown in the global scope.
+	tk.MustExec("set @@session.tidb_hash_join_concurrency = 4")
+	tk.MustQuery("show variables like 'tidb_hash_join_concurrency'").Check(testkit.Rows("tidb_hash_join_concurrency 4"))
+	tk.MustQuery("show session variables like 'tidb_hash_join_concurrency'").Check(testkit.Rows("tidb_hash_join_concurrency 4"))
+	tk.MustQuery("show global variables like 'tidb_hash_join_concurrency'").Check(testkit.Rows())
+
+	// The global only variables are shown in both scopes, and the rows are sorted by the names.
+	tk.MustExec("set @@global.tidb_ddl_reorg_batch_size = 256")
+	tk.MustQuery("show variables like 'tidb_ddl_reorg%'").Check(testkit.Rows(
+		"tidb_ddl_reorg_batch_size 256",
+		"tidb_ddl_reorg_priority PRIORITY_NORMAL",
+		"tidb_ddl_reorg_worker_cnt 16"))
+	tk.MustQuery("show global variables like 'TIDB_DDL_REORG%'").Check(testkit.Rows(
+		"tidb_ddl_reorg_batch_size 256",
+		"tidb_ddl_reorg_priority PRIORITY_NORMAL",
+		"tidb_ddl_reorg_worker_cnt 16"))
+
+	// The session value is shown in the session scope, the global value is shown in the global scope.
+	tk.MustExec("set @@session.tidb_distsql_scan_concurrency = 3")
+	tk.MustExec("set @@global.tidb_distsql_scan_concurrency = 7")
+	tk.MustQuery("show variables like 'tidb_distsql_scan_concurrency'").Check(testkit.Rows("tidb_distsql_scan_concurrency 3"))
+	tk.MustQuery("show global variables like 'tidb_distsql_scan_concurrency'").Check(testkit.Rows("tidb_distsql_scan_concurrency 7"))
+
+	// The read only and the virtual variables.
+	tk.MustQuery("show global variables like 'version_comment'").Check(testkit.Rows("version_comment MySQL Community Server (Apache License 2.0)"))
+	result := tk.MustQuery("show variables where variable_name = 'tidb_current_ts'")
+	c.Assert(result.Rows(), HasLen, 1)
+	c.Assert(result.Rows()[0][1], Not(Equals), "0")
+
+	result = tk.MustQuery("show variables")
+	c.Assert(result.Rows(), HasLen, len(variable.SysVars))
+	result = tk.MustQuery("show global variables")
+	sessionOnly := 0
+	for _, v := range variable.SysVars {
+		if v.Scope == variable.ScopeSession {
+			sessionOnly++
+		}
+	}
+	c.Assert(result.Rows(), HasLen, len(variable.SysVars)-sessionOnly)
+}
+
 func (s *testSuite) TestShowVisibility(c *C) {
 	save := privileges.Enable
 	privileges.Enable = true
@@ -283,3 +337,51 @@ func (s *testSuite) TestIssue3641(c *C) {
 	_, err = tk.Exec("show table status;")
 	c.Assert(err.Error(), Equals, plan.ErrNoDB.Error())
 }
+
+func (s *testSuite) TestShowOptRules(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists opt_rules")
+	tk.MustExec("create table opt_rules (a int, b int, index idx_a(a))")
+	tk.MustExec("insert opt_rules values (1, 1), (2, 2), (1, 3)")
+
+	tk.MustQuery("show opt_rules where disabled = 1").Check(testkit.Rows())
+	tk.MustQuery("explain select b from opt_rules where a = 1").Check(testkit.Rows(
+		"IndexScan_7   cop table:opt_rules, index:a, range:[1,1], out of order:true 10",
+		"TableScan_8   cop table:opt_rules, keep order:false 10",
+		"IndexLookUp_9 Projection_3  root index:IndexScan_7, table:TableScan_8 10",
+		"Projection_3  IndexLookUp_9 root test.opt_rules.b 10",
+	))
+
+	// Without predicate push down, the filter isn't used to build the index ranges.
+	// The aggregation push down depends on the predicate push down, it's skipped too.
+	tk.MustExec("set @@tidb_opt_disable_rules = ' Predicate_Push_Down, '")
+	tk.MustQuery("show opt_rules where disabled = 1").Check(testkit.Rows(
+		"predicate_push_down push the filters down to the joins and the data sources 1",
+		"aggregation_push_down push the aggregations down through the joins, it also needs tidb_opt_agg_push_down and predicate_push_down 1",
+	))
+	tk.MustQuery("show opt_rules like 'predicate%'").Check(testkit.Rows(
+		"predicate_push_down push the filters down to the joins and the data sources 1",
+	))
+	tk.MustQuery("explain select b from opt_rules where a = 1").Check(testkit.Rows(
+		"TableScan_4   cop table:opt_rules, range:(-inf,+inf), keep order:false 8000",
+		"TableReader_5 Selection_2  root data:TableScan_4 8000",
+		"Selection_2 Projection_3 TableReader_5 root eq(test.opt_rules.a, 1) 6400",
+		"Projection_3  Selection_2 root test.opt_rules.b 6400",
+	))
+	tk.MustQuery("select b from opt_rules where a = 1 order by b").Check(testkit.Rows("1", "3"))
+
+	// The rules are only disabled in the session.
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustQuery("show opt_rules where disabled = 1").Check(testkit.Rows())
+
+	// The unknown rules and the rules which can't be disabled are rejected.
+	_, err := tk.Exec("set @@tidb_opt_disable_rules = 'decorrelate,no_such_rule'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("set @@tidb_opt_disable_rules = 'column_pruning'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select @@tidb_opt_disable_rules").Check(testkit.Rows(" Predicate_Push_Down, "))
+
+	tk.MustExec("set @@tidb_opt_disable_rules = ''")
+	tk.MustQuery("show opt_rules where disabled = 1").Check(testkit.Rows())
+}
@@ -15,21 +15,27 @@ package executor
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/sqllog"
+	"github.com/pingcap/tipb/go-binlog"
 )
 
 // SimpleExec represents simple statement executor.
@@ -62,6 +68,10 @@ func (e *SimpleExec) Next() (Row, error) {
 		e.executeCommit(x)
 	case *ast.RollbackStmt:
 		err = e.executeRollback(x)
+	case *ast.SavepointStmt:
+		err = e.executeSavepoint(x)
+	case *ast.ReleaseSavepointStmt:
+		err = e.executeReleaseSavepoint(x)
 	case *ast.CreateUserStmt:
 		err = e.executeCreateUser(x)
 	case *ast.AlterUserStmt:
@@ -77,6 +87,15 @@ func (e *SimpleExec) Next() (Row, error) {
 		return nil, nil
 	case *ast.DropStatsStmt:
 		err = e.executeDropStats(x)
+	case *ast.AdminStmt:
+		switch x.Tp {
+		case ast.AdminRecoverAutoIncrement:
+			err = e.executeAdminRecoverAutoIncrement(x)
+		case ast.AdminEnableSQLLog, ast.AdminDisableSQLLog:
+			err = e.executeAdminSQLLog(x)
+		default:
+			err = e.executeAdminBlocklist(x)
+		}
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -124,6 +143,9 @@ func (e *SimpleExec) executeCommit(s *ast.CommitStmt) {
 
 func (e *SimpleExec) executeRollback(s *ast.RollbackStmt) error {
 	sessVars := e.ctx.GetSessionVars()
+	if s.SavepointName != "" {
+		return e.executeRollbackToSavepoint(s.SavepointName)
+	}
 	log.Infof("[%d] execute rollback statement", sessVars.ConnectionID)
 	sessVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
 	if e.ctx.Txn().Valid() {
@@ -132,6 +154,91 @@ func (e *SimpleExec) executeRollback(s *ast.RollbackStmt) error {
 	return nil
 }
 
+// executeSavepoint records the state of the transaction, a savepoint with the same name is replaced.
+func (e *SimpleExec) executeSavepoint(s *ast.SavepointStmt) error {
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	if i := findSavepoint(txnCtx.Savepoints, s.Name); i >= 0 {
+		txnCtx.Savepoints = append(txnCtx.Savepoints[:i], txnCtx.Savepoints[i+1:]...)
+	}
+	record := variable.SavepointRecord{
+		Name:          s.Name,
+		Checkpoint:    e.ctx.Txn().Checkpoint(),
+		TableDeltaMap: cloneTableDeltaMap(txnCtx.TableDeltaMap),
+	}
+	if txnCtx.DirtyDB != nil {
+		record.DirtyDB = txnCtx.DirtyDB.(*dirtyDB).clone()
+	}
+	if txnCtx.Binlog != nil {
+		record.Binlog = cloneBinlog(txnCtx.Binlog.(*binlog.PrewriteValue))
+	}
+	txnCtx.Savepoints = append(txnCtx.Savepoints, record)
+	return nil
+}
+
+// executeRollbackToSavepoint discards the changes after the savepoint, the savepoints set after it are removed
+// and the transaction stays active.
+func (e *SimpleExec) executeRollbackToSavepoint(name string) error {
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	i := findSavepoint(txnCtx.Savepoints, name)
+	if i < 0 {
+		return ErrSavepointNotExists.GenByArgs(name)
+	}
+	record := txnCtx.Savepoints[i]
+	e.ctx.Txn().RollbackToCheckpoint(record.Checkpoint)
+	// The savepoint is kept, so the states are cloned to be restored again.
+	txnCtx.TableDeltaMap = cloneTableDeltaMap(record.TableDeltaMap)
+	txnCtx.DirtyDB = nil
+	if record.DirtyDB != nil {
+		txnCtx.DirtyDB = record.DirtyDB.(*dirtyDB).clone()
+	}
+	txnCtx.Binlog = nil
+	if record.Binlog != nil {
+		txnCtx.Binlog = cloneBinlog(record.Binlog.(*binlog.PrewriteValue))
+	}
+	txnCtx.Savepoints = txnCtx.Savepoints[:i+1]
+	return nil
+}
+
+// executeReleaseSavepoint removes the savepoint and the savepoints set after it.
+func (e *SimpleExec) executeReleaseSavepoint(s *ast.ReleaseSavepointStmt) error {
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	i := findSavepoint(txnCtx.Savepoints, s.Name)
+	if i < 0 {
+		return ErrSavepointNotExists.GenByArgs(s.Name)
+	}
+	txnCtx.Savepoints = txnCtx.Savepoints[:i]
+	return nil
+}
+
+// findSavepoint returns the index of the savepoint, the savepoint names are case-insensitive.
+func findSavepoint(savepoints []variable.SavepointRecord, name string) int {
+	for i, record := range savepoints {
+		if strings.EqualFold(record.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func cloneTableDeltaMap(m map[int64]variable.TableDelta) map[int64]variable.TableDelta {
+	if m == nil {
+		return nil
+	}
+	newMap := make(map[int64]variable.TableDelta, len(m))
+	for k, v := range m {
+		newMap[k] = v
+	}
+	return newMap
+}
+
+// cloneBinlog copies the binlog prewrite value, the rows of a mutation are only appended, so the row slices
+// are shared.
+func cloneBinlog(v *binlog.PrewriteValue) *binlog.PrewriteValue {
+	newValue := *v
+	newValue.Mutations = append([]binlog.TableMutation(nil), v.Mutations...)
+	return &newValue
+}
+
 func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 	users := make([]string, 0, len(s.Specs))
 	for _, spec := range s.Specs {
@@ -153,6 +260,9 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 				pwd = auth.EncodePassword(spec.AuthOpt.HashString)
 			}
 		}
+		if err := checkEmptyPassword(pwd); err != nil {
+			return errors.Trace(err)
+		}
 		user := fmt.Sprintf(`("%s", "%s", "%s")`, spec.User.Hostname, spec.User.Username, pwd)
 		users = append(users, user)
 	}
@@ -202,6 +312,9 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 				pwd = auth.EncodePassword(spec.AuthOpt.HashString)
 			}
 		}
+		if err = checkEmptyPassword(pwd); err != nil {
+			return errors.Trace(err)
+		}
 		sql := fmt.Sprintf(`UPDATE %s.%s SET Password = "%s" WHERE Host = "%s" and User = "%s";`,
 			mysql.SystemDB, mysql.UserTable, pwd, spec.User.Hostname, spec.User.Username)
 		_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
@@ -252,6 +365,14 @@ func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
 	return nil
 }
 
+// checkEmptyPassword returns an error if the encoded password is empty and the empty passwords are disallowed.
+func checkEmptyPassword(pwd string) error {
+	if pwd == "" && config.GetGlobalConfig().DisallowEmptyPassword {
+		return ErrEmptyPassword
+	}
+	return nil
+}
+
 func userExists(ctx context.Context, name string, host string) (bool, error) {
 	sql := fmt.Sprintf(`SELECT * FROM %s.%s WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, name, host)
 	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
@@ -276,21 +397,25 @@ func (e *SimpleExec) executeSetPwd(s *ast.SetPwdStmt) error {
 	if !exists {
 		return errors.Trace(ErrPasswordNoMatch)
 	}
+	pwd := auth.EncodePassword(s.Password)
+	if err = checkEmptyPassword(pwd); err != nil {
+		return errors.Trace(err)
+	}
 
 	// update mysql.user
-	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s" WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, auth.EncodePassword(s.Password), s.User.Username, s.User.Hostname)
+	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s" WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, pwd, s.User.Username, s.User.Hostname)
 	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
 	return errors.Trace(err)
 }
 
 func (e *SimpleExec) executeKillStmt(s *ast.KillStmt) error {
-	if s.TiDBExtension {
+	if s.TiDBExtension || config.GetGlobalConfig().EnableGlobalKill {
 		sm := e.ctx.GetSessionManager()
 		if sm == nil {
 			return nil
 		}
-		sm.Kill(s.ConnectionID, s.Query)
+		return errors.Trace(sm.Kill(s.ConnectionID, s.Query))
 	}
 	return nil
 }
@@ -325,3 +450,150 @@ func (e *SimpleExec) executeDropStats(s *ast.DropStatsStmt) error {
 	h.DDLEventCh() <- &ddl.Event{Tp: model.ActionDropTable, TableInfo: s.Table.TableInfo}
 	return nil
 }
+
+// executeAdminBlocklist handles the "admin block digest", "admin unblock digest" and "admin unblock all" statements.
+// The blocklist is persisted in mysql.blocked_digests, the local cache is updated at once and the other servers are notified.
+func (e *SimpleExec) executeAdminBlocklist(s *ast.AdminStmt) error {
+	digests := make([]string, 0, len(s.Digests))
+	quoted := make([]string, 0, len(s.Digests))
+	for _, d := range s.Digests {
+		if !isValidDigest(d) {
+			return ErrInvalidDigest.GenByArgs(d)
+		}
+		d = strings.ToLower(d)
+		digests = append(digests, d)
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, d))
+	}
+	blocklist := sessionctx.GetDomain(e.ctx).DigestBlocklist()
+	var sql string
+	switch s.Tp {
+	case ast.AdminBlockDigests:
+		sql = fmt.Sprintf(`INSERT IGNORE INTO %s.%s VALUES (%s);`, mysql.SystemDB, mysql.BlockedDigestsTable, strings.Join(quoted, "), ("))
+	case ast.AdminUnblockDigests:
+		sql = fmt.Sprintf(`DELETE FROM %s.%s WHERE digest IN (%s);`, mysql.SystemDB, mysql.BlockedDigestsTable, strings.Join(quoted, ", "))
+	case ast.AdminUnblockAllDigests:
+		sql = fmt.Sprintf(`DELETE FROM %s.%s;`, mysql.SystemDB, mysql.BlockedDigestsTable)
+	}
+	_, err := e.ctx.(sqlexec.SQLExecutor).Execute(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	switch s.Tp {
+	case ast.AdminBlockDigests:
+		blocklist.Block(digests)
+	case ast.AdminUnblockDigests:
+		blocklist.Unblock(digests)
+	case ast.AdminUnblockAllDigests:
+		blocklist.UnblockAll()
+	}
+	sessionctx.GetDomain(e.ctx).NotifyUpdateBlocklist(e.ctx)
+	return nil
+}
+
+// executeAdminSQLLog handles the "admin enable log for connection" and "admin disable log for connection" statements.
+// The statements of the connection are appended to the file with their execution time, until it's disabled
+// or the connection is closed.
+func (e *SimpleExec) executeAdminSQLLog(s *ast.AdminStmt) error {
+	sm := e.ctx.GetSessionManager()
+	if sm == nil {
+		return plan.ErrNoSuchThread.GenByArgs(s.ConnectionID)
+	}
+	var logger *sqllog.Logger
+	if s.Tp == ast.AdminEnableSQLLog {
+		path, err := sqlLogPath(s.LogFile)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		logger, err = sqllog.NewLogger(s.ConnectionID, path)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if !sm.SetSQLLogger(s.ConnectionID, logger) {
+		if logger != nil {
+			logger.Close()
+		}
+		return plan.ErrNoSuchThread.GenByArgs(s.ConnectionID)
+	}
+	return nil
+}
+
+// sqlLogPath resolves the file of ADMIN ENABLE LOG in the configured SQL log directory. A relative path is
+// relative to the directory, and the path is rejected if it's outside the directory.
+func sqlLogPath(file string) (string, error) {
+	dir := config.GetGlobalConfig().SQLLogDir
+	if dir == "" {
+		return "", ErrSQLLogDisabled
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrSQLLogFile.GenByArgs(file, dir)
+	}
+	return path, nil
+}
+
+// executeAdminRecoverAutoIncrement handles the "admin recover auto_increment" statement. It rebases the auto ID
+// allocator of each table to the max value of the auto_increment column, so the IDs allocated later don't conflict
+// with the existing rows, e.g. the rows restored with explicit IDs.
+// The allocator base is never decreased, and the IDs already cached by the other servers are kept.
+func (e *SimpleExec) executeAdminRecoverAutoIncrement(s *ast.AdminStmt) error {
+	for _, t := range s.Tables {
+		dbName := t.Schema
+		if dbName.O == "" {
+			dbName = model.NewCIStr(e.ctx.GetSessionVars().CurrentDB)
+		}
+		tb, err := e.is.TableByName(dbName, t.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var autoCol *table.Column
+		for _, col := range tb.Cols() {
+			if mysql.HasAutoIncrementFlag(col.Flag) {
+				autoCol = col
+				break
+			}
+		}
+		if autoCol == nil {
+			return ErrNoAutoIncrement.GenByArgs(t.Name.O)
+		}
+		sql := fmt.Sprintf("SELECT MAX(`%s`) FROM `%s`.`%s`;", autoCol.Name.O, dbName.O, t.Name.O)
+		rows, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(rows) == 0 || rows[0].Data[0].IsNull() {
+			continue
+		}
+		maxID, err := rows[0].Data[0].ToInt64(e.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = tb.RebaseAutoID(maxID, false); err != nil {
+			return errors.Trace(err)
+		}
+		log.Infof("[admin] recover auto_increment of %s.%s, max ID %d", dbName, t.Name, maxID)
+	}
+	return nil
+}
+
+// isValidDigest checks if d is a hex encoded sha256 hash, as returned by parser.Digest.
+func isValidDigest(d string) bool {
+	if len(d) != 64 {
+		return false
+	}
+	for _, c := range d {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -16,6 +16,7 @@ package executor_test
 import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/model"
@@ -85,6 +86,60 @@ func (s *testSuite) TestTransaction(c *C) {
 	tk.MustQuery("select * from txn").Check(testkit.Rows("1", "2"))
 }
 
+func (s *testSuite) TestSavepoint(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table sp (a int primary key, b int, index idx(b))")
+	tk.MustExec("insert sp values (1, 1)")
+
+	tk.MustExec("begin")
+	tk.MustExec("insert sp values (2, 2)")
+	tk.MustExec("savepoint s1")
+	tk.MustExec("insert sp values (3, 3)")
+	tk.MustExec("update sp set b = 10 where a = 1")
+	tk.MustExec("savepoint s2")
+	tk.MustExec("delete from sp where a = 2")
+	tk.MustQuery("select * from sp").Check(testkit.Rows("1 10", "3 3"))
+
+	tk.MustExec("rollback to savepoint s2")
+	tk.MustQuery("select * from sp").Check(testkit.Rows("1 10", "2 2", "3 3"))
+	// The union scan sees the restored index entries.
+	tk.MustQuery("select b from sp use index(idx) where b > 1").Check(testkit.Rows("2", "3", "10"))
+
+	tk.MustExec("rollback to S1")
+	tk.MustQuery("select * from sp").Check(testkit.Rows("1 1", "2 2"))
+	ctx := tk.Se.(context.Context)
+	c.Assert(inTxn(ctx), IsTrue)
+	// The savepoints set after s1 are removed, s1 is kept.
+	_, err := tk.Exec("rollback to s2")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSavepointNotExists), IsTrue, Commentf("err %v", err))
+	tk.MustExec("insert sp values (4, 4)")
+	tk.MustExec("rollback to s1")
+	tk.MustQuery("select * from sp").Check(testkit.Rows("1 1", "2 2"))
+
+	// The savepoint with the same name is replaced.
+	tk.MustExec("insert sp values (5, 5)")
+	tk.MustExec("savepoint s1")
+	tk.MustExec("insert sp values (6, 6)")
+	tk.MustExec("rollback to s1")
+	tk.MustQuery("select * from sp").Check(testkit.Rows("1 1", "2 2", "5 5"))
+
+	tk.MustExec("release savepoint s1")
+	_, err = tk.Exec("rollback to s1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSavepointNotExists), IsTrue, Commentf("err %v", err))
+	tk.MustExec("commit")
+	tk.MustQuery("select * from sp").Check(testkit.Rows("1 1", "2 2", "5 5"))
+	tk.MustExec("admin check table sp")
+
+	// The savepoints are discarded when the transaction ends.
+	tk.MustExec("begin")
+	tk.MustExec("savepoint s1")
+	tk.MustExec("commit")
+	_, err = tk.Exec("release savepoint s1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSavepointNotExists), IsTrue, Commentf("err %v", err))
+}
+
 func inTxn(ctx context.Context) bool {
 	return (ctx.GetSessionVars().Status & mysql.ServerStatusInTrans) > 0
 }
@@ -208,6 +263,28 @@ func (s *testSuite) TestSetPwd(c *C) {
 	result.Check(testkit.Rows(auth.EncodePassword("pwd")))
 }
 
+func (s *testSuite) TestDisallowEmptyPassword(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	cfg := config.GetGlobalConfig()
+	cfg.DisallowEmptyPassword = true
+	defer func() { cfg.DisallowEmptyPassword = false }()
+
+	_, err := tk.Exec(`CREATE USER 'emptypwd'@'localhost'`)
+	c.Assert(terror.ErrorEqual(err, executor.ErrEmptyPassword), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec(`CREATE USER 'emptypwd'@'localhost' IDENTIFIED BY ''`)
+	c.Assert(terror.ErrorEqual(err, executor.ErrEmptyPassword), IsTrue, Commentf("err %v", err))
+	tk.MustQuery(`SELECT User FROM mysql.User WHERE User="emptypwd"`).Check(testkit.Rows())
+
+	tk.MustExec(`CREATE USER 'emptypwd'@'localhost' IDENTIFIED BY 'pwd'`)
+	_, err = tk.Exec(`SET PASSWORD FOR 'emptypwd'@'localhost' = ''`)
+	c.Assert(terror.ErrorEqual(err, executor.ErrEmptyPassword), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec(`ALTER USER 'emptypwd'@'localhost' IDENTIFIED BY ''`)
+	c.Assert(terror.ErrorEqual(err, executor.ErrEmptyPassword), IsTrue, Commentf("err %v", err))
+	tk.MustQuery(`SELECT Password FROM mysql.User WHERE User="emptypwd"`).Check(testkit.Rows(auth.EncodePassword("pwd")))
+	tk.MustExec(`DROP USER 'emptypwd'@'localhost'`)
+}
+
 func (s *testSuite) TestFlushPrivileges(c *C) {
 	defer testleak.AfterTest(c)()
 	// Global variables is really bad, when the test cases run concurrently.
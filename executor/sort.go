@@ -20,6 +20,8 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -29,6 +31,21 @@ type orderByRow struct {
 	row Row
 }
 
+func (r *orderByRow) memUsage() int64 {
+	return r.row.memUsage() + Row(r.key).memUsage()
+}
+
+// compareByItem compares the order values of a by item, the sort direction is not taken into account.
+func compareByItem(sc *variable.StatementContext, by *plan.ByItems, v1, v2 types.Datum) (int, error) {
+	if by.NullsHigh && v1.IsNull() != v2.IsNull() {
+		if v1.IsNull() {
+			return 1, nil
+		}
+		return -1, nil
+	}
+	return v1.CompareDatum(sc, v2)
+}
+
 // SortExec represents sorting executor.
 type SortExec struct {
 	baseExecutor
@@ -39,11 +56,17 @@ type SortExec struct {
 	fetched bool
 	err     error
 	schema  *expression.Schema
+
+	memTracker *memory.Tracker
 }
 
 // Close implements the Executor Close interface.
 func (e *SortExec) Close() error {
 	e.Rows = nil
+	if e.memTracker != nil {
+		e.memTracker.Detach()
+		e.memTracker = nil
+	}
 	return errors.Trace(e.children[0].Close())
 }
 
@@ -52,6 +75,11 @@ func (e *SortExec) Open() error {
 	e.fetched = false
 	e.Idx = 0
 	e.Rows = nil
+	var err error
+	e.memTracker, err = newMemTracker(e.ctx, "SortExec")
+	if err != nil {
+		return errors.Trace(err)
+	}
 	return errors.Trace(e.children[0].Open())
 }
 
@@ -72,7 +100,7 @@ func (e *SortExec) Less(i, j int) bool {
 		v1 := e.Rows[i].key[index]
 		v2 := e.Rows[j].key[index]
 
-		ret, err := v1.CompareDatum(sc, v2)
+		ret, err := compareByItem(sc, by, v1, v2)
 		if err != nil {
 			e.err = errors.Trace(err)
 			return true
@@ -113,6 +141,10 @@ func (e *SortExec) Next() (Row, error) {
 					return nil, errors.Trace(err)
 				}
 			}
+			err = e.memTracker.Consume(orderRow.memUsage())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
 			e.Rows = append(e.Rows, orderRow)
 		}
 		sort.Sort(e)
@@ -145,7 +177,7 @@ func (e *TopNExec) Less(i, j int) bool {
 		v1 := e.Rows[i].key[index]
 		v2 := e.Rows[j].key[index]
 
-		ret, err := v1.CompareDatum(sc, v2)
+		ret, err := compareByItem(sc, by, v1, v2)
 		if err != nil {
 			e.err = errors.Trace(err)
 			return true
@@ -182,14 +214,22 @@ func (e *TopNExec) Pop() interface{} {
 	return nil
 }
 
+// maxInt is the max value of int, the Offset+Count of a limit beyond it can't be reached.
+const maxInt = int(^uint(0) >> 1)
+
 // Next implements the Executor Next interface.
 func (e *TopNExec) Next() (Row, error) {
 	if !e.fetched {
-		e.Idx = int(e.limit.Offset)
-		e.totalCount = int(e.limit.Offset + e.limit.Count)
-		cap := e.totalCount + 1
-		if cap > 1024 {
-			cap = 1024
+		total := e.limit.Offset + e.limit.Count
+		if total < e.limit.Offset || total > uint64(maxInt) {
+			// The heap never fills up, all the rows are sorted.
+			e.totalCount = maxInt
+		} else {
+			e.totalCount = int(total)
+		}
+		cap := 1024
+		if e.totalCount < cap {
+			cap = e.totalCount + 1
 		}
 		e.Rows = make([]*orderByRow, 0, cap)
 		e.heapSize = 0
@@ -217,21 +257,41 @@ func (e *TopNExec) Next() (Row, error) {
 				// to reduce the number of comparisons.
 				e.Rows = append(e.Rows, orderRow)
 				if e.Less(0, e.heapSize) {
+					// The heap keeps its size, only the memory difference of the rows is consumed.
+					err = e.memTracker.Consume(orderRow.memUsage() - e.Rows[0].memUsage())
+					if err != nil {
+						return nil, errors.Trace(err)
+					}
 					e.Swap(0, e.heapSize)
 					heap.Fix(e, 0)
 				}
 				e.Rows = e.Rows[:e.heapSize]
 			} else {
+				err = e.memTracker.Consume(orderRow.memUsage())
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
 				heap.Push(e, orderRow)
 			}
+			if e.err != nil {
+				return nil, errors.Trace(e.err)
+			}
 		}
-		if e.limit.Offset == 0 {
+		if e.limit.Offset == 0 || e.heapSize < e.totalCount {
 			sort.Sort(&e.SortExec)
 		} else {
+			// The heap is full, so the Count fits in int.
 			for i := 0; i < int(e.limit.Count) && e.Len() > 0; i++ {
 				heap.Pop(e)
 			}
 		}
+		if e.err != nil {
+			return nil, errors.Trace(e.err)
+		}
+		e.Idx = len(e.Rows)
+		if e.limit.Offset < uint64(len(e.Rows)) {
+			e.Idx = int(e.limit.Offset)
+		}
 		e.fetched = true
 	}
 	if e.Idx >= len(e.Rows) {
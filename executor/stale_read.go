@@ -0,0 +1,119 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// asOfCollector collects the AS OF TIMESTAMP clauses in a statement.
+type asOfCollector struct {
+	clauses []*ast.AsOfClause
+}
+
+func (c *asOfCollector) Enter(in ast.Node) (ast.Node, bool) {
+	if asOf, ok := in.(*ast.AsOfClause); ok {
+		c.clauses = append(c.clauses, asOf)
+		return in, true
+	}
+	return in, false
+}
+
+func (c *asOfCollector) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+// getAsOfTS evaluates the AS OF TIMESTAMP clauses in the statement and returns the timestamp to read the
+// historical data at, it returns 0 if there isn't any AS OF TIMESTAMP clause.
+// All the tables in the statement are read at the same timestamp, so the clauses must be evaluated to the same time.
+func getAsOfTS(ctx context.Context, node ast.StmtNode) (uint64, error) {
+	collector := &asOfCollector{}
+	node.Accept(collector)
+	if len(collector.clauses) == 0 {
+		return 0, nil
+	}
+	if err := checkAsOfStmt(ctx, node); err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	var ts uint64
+	for _, clause := range collector.clauses {
+		t, err := evalAsOfTS(ctx, clause)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if ts != 0 && t != ts {
+			return 0, ErrInvalidAsOfTS.GenByArgs("all the tables must be read at the same timestamp")
+		}
+		ts = t
+	}
+	if ts > varsutil.GoTimeToTS(time.Now()) {
+		return 0, ErrInvalidAsOfTS.GenByArgs("the timestamp is in the future")
+	}
+	if err := validateSnapshot(ctx, ts); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return ts, nil
+}
+
+// checkAsOfStmt checks if the statement can read the historical data, only the read-only statements
+// outside of an explicit transaction are allowed.
+func checkAsOfStmt(ctx context.Context, node ast.StmtNode) error {
+	if explain, ok := node.(*ast.ExplainStmt); ok {
+		node = explain.Stmt
+	}
+	switch x := node.(type) {
+	case *ast.SelectStmt:
+		if x.LockTp == ast.SelectLockForUpdate {
+			return ErrAsOfNotSupported.GenByArgs("with SELECT FOR UPDATE")
+		}
+	case *ast.UnionStmt:
+	default:
+		return ErrAsOfNotSupported.GenByArgs("in the write statements")
+	}
+	if ctx.GetSessionVars().InTxn() {
+		return ErrAsOfNotSupported.GenByArgs("in an explicit transaction")
+	}
+	return nil
+}
+
+func evalAsOfTS(ctx context.Context, clause *ast.AsOfClause) (uint64, error) {
+	d, err := expression.EvalAstExpr(clause.TsExpr, ctx)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if d.IsNull() {
+		return 0, ErrInvalidAsOfTS.GenByArgs("the timestamp is NULL")
+	}
+	ft := types.NewFieldType(mysql.TypeDatetime)
+	ft.Decimal = types.MaxFsp
+	d, err = d.ConvertTo(ctx.GetSessionVars().StmtCtx, ft)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	// The timestamp is in the time zone of the session, like the other datetime values.
+	t, err := d.GetMysqlTime().Time.GoTime(ctx.GetSessionVars().GetTimeZone())
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return varsutil.GoTimeToTS(t), nil
+}
@@ -0,0 +1,306 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// WindowExec computes a window function for every row of its child and appends the result to the row.
+// The rows of the child are sorted by the partition items and the order items, so the rows of a partition
+// are adjacent, they are buffered and computed together.
+type WindowExec struct {
+	baseExecutor
+
+	Name string
+	// AggFunc is the aggregate function, it's nil for the ranking functions.
+	AggFunc     expression.AggregationFunction
+	PartitionBy []expression.Expression
+	OrderBy     []*plan.ByItems
+	Frame       *ast.FrameClause
+
+	// rows are the rows of the current partition and results are the results of them.
+	rows         []Row
+	results      []types.Datum
+	cursor       int
+	partitionKey []types.Datum
+	// pendingRow is the first row of the next partition, it has been read from the child.
+	pendingRow Row
+	pendingKey []types.Datum
+	exhausted  bool
+
+	memTracker *memory.Tracker
+}
+
+// Open implements the Executor Open interface.
+func (e *WindowExec) Open() error {
+	e.rows, e.results = nil, nil
+	e.cursor = 0
+	e.pendingRow, e.pendingKey = nil, nil
+	e.exhausted = false
+	var err error
+	e.memTracker, err = newMemTracker(e.ctx, "WindowExec")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(e.children[0].Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *WindowExec) Close() error {
+	e.rows, e.results = nil, nil
+	e.pendingRow = nil
+	if e.memTracker != nil {
+		e.memTracker.Detach()
+		e.memTracker = nil
+	}
+	return errors.Trace(e.children[0].Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *WindowExec) Next() (Row, error) {
+	if e.cursor >= len(e.rows) {
+		if err := e.fetchPartition(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(e.rows) == 0 {
+			return nil, nil
+		}
+	}
+	srcRow := e.rows[e.cursor]
+	row := make(Row, 0, len(srcRow)+1)
+	row = append(row, srcRow...)
+	row = append(row, e.results[e.cursor])
+	e.cursor++
+	return row, nil
+}
+
+// fetchPartition reads the rows of the next partition and computes the results of them.
+func (e *WindowExec) fetchPartition() error {
+	// The rows of the last partition are released.
+	if err := e.memTracker.Consume(-e.memTracker.BytesConsumed()); err != nil {
+		return errors.Trace(err)
+	}
+	e.rows, e.results = e.rows[:0], e.results[:0]
+	e.cursor = 0
+	if e.pendingRow != nil {
+		if err := e.memTracker.Consume(e.pendingRow.memUsage()); err != nil {
+			return errors.Trace(err)
+		}
+		e.rows = append(e.rows, e.pendingRow)
+		e.partitionKey = e.pendingKey
+		e.pendingRow, e.pendingKey = nil, nil
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	for !e.exhausted {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			e.exhausted = true
+			break
+		}
+		key, err := evalRowKey(row, e.PartitionBy)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(e.rows) == 0 {
+			e.partitionKey = key
+		} else {
+			same, err := equalKeys(sc, e.partitionKey, key, nil)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !same {
+				e.pendingRow, e.pendingKey = row, key
+				break
+			}
+		}
+		if err = e.memTracker.Consume(row.memUsage()); err != nil {
+			return errors.Trace(err)
+		}
+		e.rows = append(e.rows, row)
+	}
+	if len(e.rows) == 0 {
+		return nil
+	}
+	return errors.Trace(e.computePartition())
+}
+
+// computePartition computes the window function for the rows of the current partition.
+func (e *WindowExec) computePartition() error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	n := len(e.rows)
+	// peerStart and peerEnd are the bounds of the peers of every row, the peers are the rows with the same order values.
+	peerStart, peerEnd := make([]int, n), make([]int, n)
+	var prevKey []types.Datum
+	for i, row := range e.rows {
+		key := make([]types.Datum, len(e.OrderBy))
+		for j, item := range e.OrderBy {
+			v, err := item.Expr.Eval(row)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			key[j] = v
+		}
+		peerStart[i] = i
+		if i > 0 {
+			same, err := equalKeys(sc, prevKey, key, e.OrderBy)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if same {
+				peerStart[i] = peerStart[i-1]
+			}
+		}
+		prevKey = key
+	}
+	for i := n - 1; i >= 0; i-- {
+		peerEnd[i] = i + 1
+		if i+1 < n && peerStart[i+1] == peerStart[i] {
+			peerEnd[i] = peerEnd[i+1]
+		}
+	}
+
+	switch e.Name {
+	case ast.WindowFuncRowNumber:
+		for i := 0; i < n; i++ {
+			e.results = append(e.results, types.NewIntDatum(int64(i+1)))
+		}
+	case ast.WindowFuncRank:
+		for i := 0; i < n; i++ {
+			e.results = append(e.results, types.NewIntDatum(int64(peerStart[i]+1)))
+		}
+	case ast.WindowFuncDenseRank:
+		var rank int64
+		for i := 0; i < n; i++ {
+			if peerStart[i] == i {
+				rank++
+			}
+			e.results = append(e.results, types.NewIntDatum(rank))
+		}
+	default:
+		return errors.Trace(e.computeAggregation(peerStart, peerEnd))
+	}
+	return nil
+}
+
+// computeAggregation computes the aggregate function over the frame of every row.
+// If the frame start doesn't move, the rows entering the frame are added to the last result,
+// otherwise the result is computed from the start of the frame again.
+func (e *WindowExec) computeAggregation(peerStart, peerEnd []int) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	n := len(e.rows)
+	e.AggFunc.Reset()
+	lastStart, lastEnd := 0, 0
+	for i := 0; i < n; i++ {
+		start, end := e.frameBounds(i, peerStart, peerEnd)
+		if start != lastStart || end < lastEnd {
+			e.AggFunc.Reset()
+			lastStart, lastEnd = start, start
+		}
+		for ; lastEnd < end; lastEnd++ {
+			if err := e.AggFunc.Update(e.rows[lastEnd], nil, sc); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		e.results = append(e.results, e.AggFunc.GetGroupResult(nil))
+	}
+	e.AggFunc.Reset()
+	return nil
+}
+
+// frameBounds returns the range [start, end) of the frame of the i-th row in the partition.
+func (e *WindowExec) frameBounds(i int, peerStart, peerEnd []int) (start, end int) {
+	n := len(e.rows)
+	start = e.boundOffset(e.Frame.Start, i, peerStart[i], n)
+	end = e.boundOffset(e.Frame.End, i, peerEnd[i]-1, n) + 1
+	if start < 0 {
+		start = 0
+	}
+	if end > n {
+		end = n
+	}
+	if start > end {
+		start = end
+	}
+	return start, end
+}
+
+// boundOffset returns the offset of the row at the frame bound of the i-th row, peer is the offset of the
+// first or the last peer of the i-th row, which is the current row bound of a RANGE frame.
+func (e *WindowExec) boundOffset(bound ast.FrameBound, i, peer, n int) int {
+	switch {
+	case bound.Type == ast.CurrentRow && e.Frame.Type == ast.FrameRange:
+		return peer
+	case bound.Type == ast.CurrentRow:
+		return i
+	case bound.UnBounded && bound.Type == ast.Preceding:
+		return 0
+	case bound.UnBounded:
+		return n - 1
+	case bound.Type == ast.Preceding:
+		if bound.Offset > uint64(i) {
+			return -1
+		}
+		return i - int(bound.Offset)
+	default:
+		if bound.Offset >= uint64(n-i) {
+			return n
+		}
+		return i + int(bound.Offset)
+	}
+}
+
+// evalRowKey evaluates the expressions on the row.
+func evalRowKey(row Row, exprs []expression.Expression) ([]types.Datum, error) {
+	key := make([]types.Datum, 0, len(exprs))
+	for _, expr := range exprs {
+		v, err := expr.Eval(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		key = append(key, v)
+	}
+	return key, nil
+}
+
+// equalKeys checks whether two keys are equal, the keys are compared as order values if byItems is not nil.
+func equalKeys(sc *variable.StatementContext, k1, k2 []types.Datum, byItems []*plan.ByItems) (bool, error) {
+	for i := range k1 {
+		var (
+			c   int
+			err error
+		)
+		if byItems != nil {
+			c, err = compareByItem(sc, byItems[i], k1[i], k2[i])
+		} else {
+			c, err = k1[i].CompareDatum(sc, k2[i])
+		}
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if c != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
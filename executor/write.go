@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -37,10 +38,35 @@ var (
 	_ Executor = &LoadData{}
 )
 
+// checkConstraints returns an error if the row violates a check constraint.
+// Like MySQL, the constraint is violated only if its expression is false, NULL satisfies it.
+func checkConstraints(ctx context.Context, checks []*plan.CheckConstraint, row []types.Datum) error {
+	sc := ctx.GetSessionVars().StmtCtx
+	for _, ck := range checks {
+		v, err := ck.Expr.Eval(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if v.IsNull() {
+			continue
+		}
+		b, err := v.ToBool(sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if b == 0 {
+			return table.ErrCheckConstraintViolated.GenByArgs(ck.Name)
+		}
+	}
+	return nil
+}
+
 // updateRecord updates the row specified by the handle `h`, from `oldData` to `newData`.
 // `modified` means which columns are really modified. It's used for secondary indices.
 // Length of `oldData` and `newData` equals to length of `t.WritableCols()`.
-func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, modified []bool, t table.Table, onDup bool) (bool, error) {
+// `checks` are the check constraints of the table, the new row must satisfy them.
+func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, modified []bool, t table.Table,
+	checks []*plan.CheckConstraint, onDup bool) (bool, error) {
 	var sc = ctx.GetSessionVars().StmtCtx
 	var changed, handleChanged = false, false
 	// onUpdateSpecified is for "UPDATE SET ts_field = old_value", the
@@ -112,6 +138,9 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 			newData[i] = v
 		}
 	}
+	if err = checkConstraints(ctx, checks, newData); err != nil {
+		return false, errors.Trace(err)
+	}
 
 	if handleChanged {
 		err = t.RemoveRecord(ctx, h, oldData)
@@ -549,6 +578,9 @@ func (e *LoadDataInfo) insertData(cols []string) {
 		e.row[i].SetString(cols[i])
 	}
 	row, err := e.insertVal.fillRowData(e.columns, e.row, true)
+	if err == nil {
+		err = checkConstraints(e.insertVal.ctx, e.insertVal.CheckConstraints, row)
+	}
 	if err != nil {
 		warnLog := fmt.Sprintf("Load Data: insert data:%v failed:%v", e.row, errors.ErrorStack(err))
 		e.insertVal.handleLoadDataWarnings(err, warnLog)
@@ -637,6 +669,8 @@ type InsertValues struct {
 	Lists     [][]expression.Expression
 	Setlist   []*expression.Assignment
 	IsPrepare bool
+	// CheckConstraints are evaluated on the rows to insert.
+	CheckConstraints []*plan.CheckConstraint
 }
 
 // InsertExec represents an insert executor.
@@ -679,6 +713,10 @@ func (e *InsertExec) Next() (Row, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	rows, err = e.checkRows(rows, e.Ignore)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	// If tidb_batch_insert is ON and not in a transaction, we could use BatchInsert mode.
 	batchInsert := e.ctx.GetSessionVars().BatchInsert && !e.ctx.GetSessionVars().InTxn()
@@ -710,6 +748,7 @@ func (e *InsertExec) Next() (Row, error) {
 			// For example, without IGNORE, a row that duplicates an existing UNIQUE index or PRIMARY KEY value in
 			// the table causes a duplicate-key error and the statement is aborted. With IGNORE, the row is discarded and no error occurs.
 			if e.Ignore {
+				e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 				continue
 			}
 			if len(e.OnDuplicate) > 0 {
@@ -863,8 +902,9 @@ func (e *InsertValues) getRow(cols []*table.Column, list []expression.Expression
 	return e.fillRowData(cols, vals, false)
 }
 
+// getRowsSelect reads all the rows of `insert|replace into ... select ... from ...` before any of them is written,
+// so the statement selecting from the target table, e.g. `insert into t select * from t`, doesn't read its own writes.
 func (e *InsertValues) getRowsSelect(cols []*table.Column) ([][]types.Datum, error) {
-	// process `insert|replace into ... select ... from ...`
 	if e.SelectExec.Schema().Len() != len(cols) {
 		return nil, ErrWrongValueCountOnRow.GenByArgs(1)
 	}
@@ -908,6 +948,26 @@ func (e *InsertValues) fillRowData(cols []*table.Column, vals []types.Datum, ign
 	return row, nil
 }
 
+// checkRows checks the rows against the check constraints before any of them is written.
+// If ignoreErr is true, the rows violating a constraint are discarded with warnings like MySQL's INSERT IGNORE.
+func (e *InsertValues) checkRows(rows [][]types.Datum, ignoreErr bool) ([][]types.Datum, error) {
+	if len(e.CheckConstraints) == 0 {
+		return rows, nil
+	}
+	checkedRows := rows[:0]
+	for _, row := range rows {
+		if err := checkConstraints(e.ctx, e.CheckConstraints, row); err != nil {
+			if ignoreErr && table.ErrCheckConstraintViolated.Equal(err) {
+				e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+				continue
+			}
+			return nil, errors.Trace(err)
+		}
+		checkedRows = append(checkedRows, row)
+	}
+	return checkedRows, nil
+}
+
 func (e *InsertValues) filterErr(err error, ignoreErr bool) error {
 	if err == nil {
 		return nil
@@ -923,6 +983,7 @@ func (e *InsertValues) filterErr(err error, ignoreErr bool) error {
 
 func (e *InsertValues) initDefaultValues(row []types.Datum, hasValue []bool, ignoreErr bool) error {
 	var defaultValueCols []*table.Column
+	sc := e.ctx.GetSessionVars().StmtCtx
 	strictSQL := e.ctx.GetSessionVars().StrictSQLMode
 
 	for i, c := range e.Table.Cols() {
@@ -932,6 +993,10 @@ func (e *InsertValues) initDefaultValues(row []types.Datum, hasValue []bool, ign
 		} else if mysql.HasNotNullFlag(c.Flag) && row[i].IsNull() && !strictSQL {
 			needDefaultValue = true
 			// TODO: Append Warning ErrColumnCantNull.
+		} else if mysql.HasNotNullFlag(c.Flag) && row[i].IsNull() && sc.BadNullAsWarning && !mysql.HasAutoIncrementFlag(c.Flag) {
+			// INSERT IGNORE uses the zero value instead of NULL, like MySQL does.
+			sc.AppendWarning(c.CheckNotNull(row[i]))
+			row[i] = table.GetZeroValue(c.ToInfo())
 		}
 		if mysql.HasAutoIncrementFlag(c.Flag) {
 			needDefaultValue = false
@@ -994,8 +1059,9 @@ func (e *InsertValues) adjustAutoIncrementDatum(row []types.Datum, i int, c *tab
 		if e.filterErr(errors.Trace(err), ignoreErr) != nil {
 			return errors.Trace(err)
 		}
-		// It's compatible with mysql. So it sets last insert id to the first row.
-		if e.currRow == 0 {
+		// It's compatible with mysql. So it sets last insert id to the first auto-generated value,
+		// the rows before it may have the given auto_increment column values.
+		if e.lastInsertID == 0 {
 			e.lastInsertID = uint64(recordID)
 		}
 	}
@@ -1028,7 +1094,7 @@ func (e *InsertExec) onDuplicateUpdate(row []types.Datum, h int64, cols []*expre
 		newData[col.Col.Index] = val
 		assignFlag[col.Col.Index] = true
 	}
-	if _, err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, true); err != nil {
+	if _, err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, e.CheckConstraints, true); err != nil {
 		return errors.Trace(err)
 	}
 	return nil
@@ -1093,6 +1159,10 @@ func (e *ReplaceExec) Next() (Row, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	rows, err = e.checkRows(rows, false)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	/*
 	 * MySQL uses the following algorithm for REPLACE (and LOAD DATA ... REPLACE):
@@ -1137,7 +1207,8 @@ func (e *ReplaceExec) Next() (Row, error) {
 			idx++
 			continue
 		}
-		// Remove current row and try replace again.
+		// Remove current row and try replace again. AddRecord returns the handle of the row which conflicts on
+		// the primary key or any unique index, so the rows conflicting on different keys are all removed in turn.
 		err1 = e.Table.RemoveRecord(e.ctx, h, oldRow)
 		if err1 != nil {
 			return nil, errors.Trace(err1)
@@ -1159,6 +1230,8 @@ type UpdateExec struct {
 
 	SelectExec  Executor
 	OrderedList []*expression.Assignment
+	// CheckConstraints are the check constraints of the updated tables, the key is the table ID.
+	CheckConstraints map[int64][]*plan.CheckConstraint
 
 	// updatedRowKeys is a map for unique (Table, handle) pair.
 	updatedRowKeys map[int64]map[int64]struct{}
@@ -1210,7 +1283,7 @@ func (e *UpdateExec) Next() (Row, error) {
 				continue
 			}
 			// Update row
-			changed, err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, false)
+			changed, err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, e.CheckConstraints[id], false)
 			if err1 != nil {
 				return nil, errors.Trace(err1)
 			}
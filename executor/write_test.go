@@ -24,8 +24,10 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -326,6 +328,91 @@ func (s *testSuite) TestInsertAutoInc(c *C) {
 	r.Check(testkit.Rows(rowStr4, rowStr1, rowStr2, rowStr3, rowStr5, rowStr6, rowStr7, rowStr8))
 }
 
+func (s *testSuite) TestInsertSelectSameTable(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key auto_increment, a int)")
+	tk.MustExec("insert into t (a) values (1), (2)")
+	// The rows inserted by the statement aren't read by itself, so the table is doubled each time.
+	for i, cnt := range []int{4, 8, 16} {
+		tk.MustExec("insert into t (a) select a from t where a > 0")
+		c.Assert(tk.Se.AffectedRows(), Equals, uint64(cnt/2))
+		tk.MustQuery("select count(*), sum(a) from t").Check(testkit.Rows(fmt.Sprintf("%d %d", cnt, 3<<uint(i+1))))
+	}
+
+	// The rows written earlier in the transaction are read, but not the ones written by the statement.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t (a) values (100)")
+	tk.MustExec("insert into t (a) select a + 1 from t where a >= 100")
+	tk.MustExec("insert into t (a) select a + 10 from t where a >= 100")
+	tk.MustQuery("select a from t where a >= 100 order by a").Check(testkit.Rows("100", "101", "110", "111"))
+	tk.MustExec("commit")
+	tk.MustQuery("select a from t where a >= 100 order by a").Check(testkit.Rows("100", "101", "110", "111"))
+
+	// The updated rows aren't read again by the statement.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2)")
+	tk.MustExec("insert into t select id + 1, a from t on duplicate key update a = t.a + 10")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 12", "3 2"))
+	tk.MustExec("replace into t select id + 1, a + 100 from t")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 101", "3 112", "4 102"))
+}
+
+func (s *testSuite) TestInsertOnDupValues(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s")
+	tk.MustExec("create table t (id int primary key, u int, a int, b int default 7, unique key(u))")
+	tk.MustExec("insert into t values (1, 1, 1, 1), (2, 2, 2, 2)")
+
+	// VALUES() resolves to the value of the conflicting row, not the first row.
+	tk.MustExec("insert into t (id, u, a) values (1, 10, 10), (2, 20, 20), (3, 30, 30) on duplicate key update a = values(a), b = values(b)")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("1 1 10 7", "2 2 20 7", "3 30 30 7"))
+	// The rows inserted or updated earlier in the statement are updated again.
+	tk.MustExec("insert into t (id, u, a) values (4, 40, 1), (4, 41, 2), (4, 42, 3) on duplicate key update a = a + values(a), u = values(u)")
+	tk.MustQuery("select * from t where id = 4").Check(testkit.Rows("4 42 6 7"))
+	// The unique key conflicts.
+	tk.MustExec("insert into t values (5, 1, 5, 5), (6, 2, 6, 6) on duplicate key update a = values(a) * 10, b = values(id)")
+	tk.MustQuery("select * from t where id < 3 order by id").Check(testkit.Rows("1 1 50 5", "2 2 60 6"))
+	// VALUES() in an expression with the other columns.
+	tk.MustExec("insert into t (id, a) values (1, 100), (2, 200) on duplicate key update a = values(a) + b")
+	tk.MustQuery("select id, a from t where id < 3 order by id").Check(testkit.Rows("1 105", "2 206"))
+	// VALUES() with INSERT ... SELECT.
+	tk.MustExec("create table s (id int, a int)")
+	tk.MustExec("insert into s values (1, 11), (2, 22), (7, 77)")
+	tk.MustExec("insert into t (id, a) select id, a from s on duplicate key update a = values(a)")
+	tk.MustQuery("select id, a from t where id in (1, 2, 7) order by id").Check(testkit.Rows("1 11", "2 22", "7 77"))
+	// VALUES() in a transaction.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t (id, a) values (8, 8), (8, 88) on duplicate key update a = values(a)")
+	tk.MustQuery("select id, a from t where id = 8").Check(testkit.Rows("8 88"))
+	tk.MustExec("insert into t (id, a) values (8, 888) on duplicate key update a = values(a) + a")
+	tk.MustQuery("select id, a from t where id = 8").Check(testkit.Rows("8 976"))
+	tk.MustExec("commit")
+	tk.MustQuery("select id, a from t where id = 8").Check(testkit.Rows("8 976"))
+	// VALUES() of a column not in the insert list is its default value.
+	tk.MustExec("insert into t (id) values (1), (2) on duplicate key update a = values(b)")
+	tk.MustQuery("select id, a from t where id < 3 order by id").Check(testkit.Rows("1 7", "2 7"))
+	// VALUES() is NULL out of the ON DUPLICATE KEY UPDATE clause.
+	tk.MustQuery("select values(a) from t where id = 1").Check(testkit.Rows("<nil>"))
+	tk.MustExec("update t set a = values(a) where id = 1")
+	tk.MustQuery("select id, a from t where id < 3 order by id").Check(testkit.Rows("1 <nil>", "2 7"))
+	// VALUES() of the qualified column names.
+	tk.MustExec("insert into t (id, a) values (3, 3) on duplicate key update a = values(t.a)")
+	tk.MustExec("insert into t (id, a) values (3, 3) on duplicate key update a = values(test.t.a) + 1")
+	tk.MustQuery("select id, a from t where id = 3").Check(testkit.Rows("3 4"))
+}
+
 func (s *testSuite) TestInsertIgnore(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -355,6 +442,111 @@ func (s *testSuite) TestInsertIgnore(c *C) {
 	_, err := tk.Exec("insert ignore into t values (1, 3)")
 	c.Assert(err, NotNil)
 	cfg.SetGetError(nil)
+
+	// INSERT IGNORE turns the errors into warnings in strict mode.
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+
+	// Duplicate-key errors of the primary key and unique indices.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, unique key idx_b (b))")
+	tk.MustExec("insert ignore into t values (1, 1), (1, 2), (2, 1), (3, 3)")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(2))
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "3 3"))
+	_, err = tk.Exec("insert into t values (4, 3)")
+	c.Assert(err, NotNil)
+
+	// Data conversion errors, the values are truncated or clipped.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a tinyint, b varchar(3), c int)")
+	tk.MustExec("insert ignore into t values (1000, 'abcd', '12x')")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(3))
+	tk.MustQuery("select * from t").Check(testkit.Rows("127 abc 12"))
+	_, err = tk.Exec("insert into t values (1000, 'a', 1)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("insert into t values (1, 'a', '12x')")
+	c.Assert(err, NotNil)
+
+	// NOT NULL errors, NULL and missing values are replaced by the zero value.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int not null, b varchar(10) not null, c int)")
+	tk.MustExec("insert ignore into t values (null, null, null)")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(2))
+	tk.MustExec("insert ignore into t (c) values (1)")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(2))
+	tk.MustQuery("select * from t").Check(testkit.Rows("0  <nil>", "0  1"))
+	_, err = tk.Exec("insert into t values (null, 'a', 1)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("insert into t (c) values (1)")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestInsertTruncate(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s")
+	tk.MustExec("create table t (a tinyint, b varchar(3), c int, d decimal(5, 2), e char(2))")
+	tk.MustExec("create table s (a bigint, b varchar(10))")
+	tk.MustExec("insert into s values (1000, 'abcd')")
+
+	tests := []struct {
+		sql string
+		err *terror.Error
+	}{
+		{"insert into t (a) values (1000)", types.ErrOverflow},
+		{"insert into t (a) values ('-1000')", types.ErrOverflow},
+		{"insert into t (b) values ('abcd')", types.ErrDataTooLong},
+		{"insert into t (b) values (12345)", types.ErrDataTooLong},
+		{"insert into t (c) values ('12x')", types.ErrTruncated},
+		{"insert into t (c) values ('x')", types.ErrTruncated},
+		{"insert into t (d) values (1000)", types.ErrOverflow},
+		{"insert into t (d) values ('1.2x')", types.ErrTruncated},
+		{"insert into t (e) values ('abc')", types.ErrDataTooLong},
+		{"insert into t (b) values ('ab'), ('abcd')", types.ErrDataTooLong},
+		{"insert into t (a, b) select a, b from s", types.ErrOverflow},
+		{"replace into t (b) values ('abcd')", types.ErrDataTooLong},
+	}
+
+	// The values are truncated or clipped with warnings in non-strict mode.
+	tk.MustExec("set sql_mode = ''")
+	for _, t := range tests {
+		tk.MustExec(t.sql)
+		c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Greater, uint16(0), Commentf("sql: %s", t.sql))
+	}
+	tk.MustQuery("select * from t").Check(testkit.Rows(
+		"127 <nil> <nil> <nil> <nil>",
+		"-128 <nil> <nil> <nil> <nil>",
+		"<nil> abc <nil> <nil> <nil>",
+		"<nil> 123 <nil> <nil> <nil>",
+		"<nil> <nil> 12 <nil> <nil>",
+		"<nil> <nil> 0 <nil> <nil>",
+		"<nil> <nil> <nil> 999.99 <nil>",
+		"<nil> <nil> <nil> 1.20 <nil>",
+		"<nil> <nil> <nil> <nil> ab",
+		"<nil> ab <nil> <nil> <nil>",
+		"<nil> abc <nil> <nil> <nil>",
+		"127 abc <nil> <nil> <nil>",
+		"<nil> abc <nil> <nil> <nil>",
+	))
+
+	// They are errors in strict mode, and nothing is inserted.
+	tk.MustExec("delete from t")
+	for _, mode := range []string{"STRICT_TRANS_TABLES", "STRICT_ALL_TABLES"} {
+		tk.MustExec(fmt.Sprintf("set sql_mode = '%s'", mode))
+		for _, t := range tests {
+			_, err := tk.Exec(t.sql)
+			c.Assert(terror.ErrorEqual(err, t.err), IsTrue, Commentf("sql: %s, err: %v", t.sql, err))
+		}
+		tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+	}
+
+	// The fraction of a decimal is rounded with a warning in strict mode too, like MySQL does.
+	tk.MustExec("insert into t (d) values (1.234)")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	tk.MustQuery("select d from t").Check(testkit.Rows("1.23"))
 }
 
 func (s *testSuite) TestReplace(c *C) {
@@ -480,6 +672,33 @@ func (s *testSuite) TestReplace(c *C) {
 	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(3))
 	r = tk.MustQuery("select * from tIssue1012;")
 	r.Check(testkit.Rows("1 1"))
+
+	// The new row conflicts on the secondary unique keys only.
+	tk.MustExec(`create table replace_test_6 (a int, b int, c int, d int, UNIQUE KEY(a), UNIQUE KEY(b, c));`)
+	tk.MustExec(`insert into replace_test_6 values (1, 1, 1, 1), (2, 2, 2, 2), (3, 3, 3, 3);`)
+	tk.MustExec(`replace into replace_test_6 values (4, 2, 2, 4);`)
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(2))
+	tk.MustQuery(`select * from replace_test_6 order by a;`).Check(testkit.Rows("1 1 1 1", "3 3 3 3", "4 2 2 4"))
+	// The new row conflicts with different rows on different unique keys, all of them are deleted.
+	tk.MustExec(`replace into replace_test_6 values (1, 3, 3, 5);`)
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(3))
+	tk.MustQuery(`select * from replace_test_6 order by a;`).Check(testkit.Rows("1 3 3 5", "4 2 2 4"))
+	tk.MustQuery(`select * from replace_test_6 use index(b) where b > 0;`).Check(testkit.Rows("4 2 2 4", "1 3 3 5"))
+	tk.MustExec(`admin check table replace_test_6;`)
+
+	// The new row conflicts on the primary key and a secondary unique key with different rows.
+	tk.MustExec(`create table replace_test_7 (id int primary key, a int, b int, UNIQUE KEY(a), UNIQUE KEY(b));`)
+	tk.MustExec(`insert into replace_test_7 values (1, 1, 1), (2, 2, 2), (3, 3, 3);`)
+	tk.MustExec("begin")
+	tk.MustExec(`replace into replace_test_7 values (1, 2, 3), (4, 4, 1);`)
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(5))
+	tk.MustQuery(`select * from replace_test_7;`).Check(testkit.Rows("1 2 3", "4 4 1"))
+	tk.MustQuery(`select * from replace_test_7 use index(a) where a > 0;`).Check(testkit.Rows("1 2 3", "4 4 1"))
+	tk.MustExec("commit")
+	tk.MustExec(`replace into replace_test_7 select id + 10, a, b from replace_test_7;`)
+	c.Assert(int64(tk.Se.AffectedRows()), Equals, int64(4))
+	tk.MustQuery(`select * from replace_test_7;`).Check(testkit.Rows("11 2 3", "14 4 1"))
+	tk.MustExec(`admin check table replace_test_7;`)
 }
 
 func (s *testSuite) TestUpdate(c *C) {
@@ -1039,6 +1258,102 @@ func (s *testSuite) TestNullDefault(c *C) {
 	tk.MustQuery("select * from test_null_default").Check(testkit.Rows("<nil>", "1970-01-01 08:20:34"))
 }
 
+func (s *testSuite) TestDefaultValueInDML(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test; drop table if exists t, t1;")
+	tk.MustExec("set time_zone = '+00:00'")
+	tk.MustExec("set timestamp = 1000")
+	tk.MustExec(`create table t (id int primary key auto_increment, a int default 10, b int default 20,
+		ts timestamp default current_timestamp)`)
+
+	// DEFAULT refers to the column at its offset in the insert column list.
+	tk.MustExec("insert into t (a, b) values (1, default)")
+	tk.MustExec("insert into t (b, a) values (2, default)")
+	tk.MustExec("insert into t (id, b, a) values (default, default(a), 3)")
+	tk.MustExec("insert into t values (default, 4, default, default), (default, default, 5, default)")
+	tk.MustExec("insert into t set a = default, b = 6")
+	tk.MustQuery("select * from t").Check(testkit.Rows(
+		"1 1 20 1970-01-01 00:16:40",
+		"2 10 2 1970-01-01 00:16:40",
+		"3 3 10 1970-01-01 00:16:40",
+		"4 4 20 1970-01-01 00:16:40",
+		"5 10 5 1970-01-01 00:16:40",
+		"6 10 6 1970-01-01 00:16:40"))
+
+	tk.MustExec("set timestamp = 2000")
+	tk.MustExec("update t set b = default, ts = default where id = 1")
+	tk.MustExec("update t set a = default(b) where id = 2")
+	tk.MustExec("insert into t (id, a) values (3, 1) on duplicate key update a = default")
+	tk.MustQuery("select * from t where id <= 3").Check(testkit.Rows(
+		"1 1 20 1970-01-01 00:33:20",
+		"2 20 2 1970-01-01 00:16:40",
+		"3 10 10 1970-01-01 00:16:40"))
+
+	tk.MustExec("create table t1 (id int, v int default 7)")
+	tk.MustExec("insert into t1 values (4, 1)")
+	tk.MustExec("update t, t1 as x set t.b = default, x.v = default where t.id = x.id")
+	tk.MustQuery("select b from t where id = 4").Check(testkit.Rows("20"))
+	tk.MustQuery("select v from t1").Check(testkit.Rows("7"))
+
+	_, err := tk.Exec("insert into t (a) values (1, default)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("insert into t (a) values (default(c))")
+	c.Assert(plan.ErrUnknownColumn.Equal(err), IsTrue)
+}
+
+func (s *testSuite) TestUpdateOnUpdateNow(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test; drop table if exists t;")
+	tk.MustExec("set time_zone = '+00:00'")
+	tk.MustExec("set timestamp = 1000")
+	tk.MustExec(`create table t (id int primary key, v int,
+		ts timestamp on update current_timestamp null default '2000-01-01 00:00:00',
+		dt datetime on update current_timestamp default null)`)
+	tk.MustExec("insert into t(id, v) values (1, 1), (2, 2)")
+	tk.MustQuery("select ts, dt from t where id = 1").Check(testkit.Rows("2000-01-01 00:00:00 <nil>"))
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `v` int(11) DEFAULT NULL,\n" +
+		"  `ts` timestamp DEFAULT '2000-01-01 00:00:00' ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  `dt` datetime DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+
+	// The row is not changed, so the on-update columns are kept.
+	tk.MustExec("set timestamp = 2000")
+	tk.MustExec("update t set v = 1 where id = 1")
+	tk.CheckExecResult(0, 0)
+	tk.MustQuery("select ts, dt from t where id = 1").Check(testkit.Rows("2000-01-01 00:00:00 <nil>"))
+
+	// The row is changed, so the on-update columns are set to the current timestamp.
+	tk.MustExec("update t set v = 10 where id = 1")
+	tk.CheckExecResult(1, 0)
+	tk.MustQuery("select ts, dt from t where id = 1").Check(testkit.Rows("1970-01-01 00:33:20 1970-01-01 00:33:20"))
+	tk.MustQuery("select ts, dt from t where id = 2").Check(testkit.Rows("2000-01-01 00:00:00 <nil>"))
+
+	// An explicitly assigned value wins over the current timestamp, even if it equals the old one.
+	tk.MustExec("set timestamp = 3000")
+	tk.MustExec("update t set v = 20, ts = ts where id = 1")
+	tk.MustQuery("select ts, dt from t where id = 1").Check(testkit.Rows("1970-01-01 00:33:20 1970-01-01 00:50:00"))
+	tk.MustExec("update t set v = 30, ts = '2010-01-01 00:00:00' where id = 1")
+	tk.MustQuery("select ts, dt from t where id = 1").Check(testkit.Rows("2010-01-01 00:00:00 1970-01-01 00:50:00"))
+
+	// INSERT ... ON DUPLICATE KEY UPDATE follows the same rule.
+	tk.MustExec("set timestamp = 4000")
+	tk.MustExec("insert into t(id, v) values (2, 2) on duplicate key update v = values(v)")
+	tk.MustQuery("select ts, dt from t where id = 2").Check(testkit.Rows("2000-01-01 00:00:00 <nil>"))
+	tk.MustExec("insert into t(id, v) values (2, 3) on duplicate key update v = values(v)")
+	tk.MustQuery("select ts, dt from t where id = 2").Check(testkit.Rows("1970-01-01 01:06:40 1970-01-01 01:06:40"))
+}
+
 func (s *testSuite) TestGetFieldsFromLine(c *C) {
 	tests := []struct {
 		input    string
@@ -1106,3 +1421,57 @@ func (s *testSuite) TestIssue4067(c *C) {
 	tk.MustExec("delete from t1 where id in (select id from t2)")
 	tk.MustQuery("select * from t1").Check(nil)
 }
+
+func (s *testSuite) TestCheckConstraint(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (id int primary key, age int check (age >= 0), lo int, hi int, name varchar(20),
+		constraint chk_range check (lo <= hi), check (length(name) > 2 and name <> 'admin'))`)
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) NOT NULL,\n  `age` int(11) DEFAULT NULL,\n  `lo` int(11) DEFAULT NULL,\n" +
+		"  `hi` int(11) DEFAULT NULL,\n  `name` varchar(20) DEFAULT NULL,\n  PRIMARY KEY (`id`),\n" +
+		"  CONSTRAINT `chk_range` CHECK (lo <= hi),\n" +
+		"  CONSTRAINT `t_chk_1` CHECK (length(name) > 2 and name <> 'admin'),\n" +
+		"  CONSTRAINT `t_chk_2` CHECK (age >= 0)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+
+	tk.MustExec("insert into t values (1, 20, 1, 2, 'tom')")
+	// NULL doesn't violate the constraints.
+	tk.MustExec("insert into t values (2, null, null, 2, null)")
+	checkErr := func(sql, name string) {
+		_, err := tk.Exec(sql)
+		c.Assert(table.ErrCheckConstraintViolated.Equal(err), IsTrue, Commentf("sql %s, err %v", sql, err))
+		c.Assert(err.Error(), Matches, fmt.Sprintf(".*'%s' is violated.*", name))
+	}
+	checkErr("insert into t values (3, -1, 1, 2, 'bob')", "t_chk_2")
+	checkErr("insert into t values (3, 1, 3, 2, 'bob')", "chk_range")
+	checkErr("insert into t (id, lo, hi, name) values (3, 1, 2, 'al')", "t_chk_1")
+	checkErr("insert into t set id = 3, name = 'admin'", "t_chk_1")
+	checkErr("insert into t select 3, age - 100, lo, hi, name from t where id = 1", "t_chk_2")
+	checkErr("replace into t values (1, 20, 5, 2, 'tom')", "chk_range")
+	// INSERT IGNORE discards the rows violating the constraints with warnings.
+	tk.MustExec("insert ignore into t values (3, -1, 1, 2, 'bob'), (4, 30, 1, 2, 'ann'), (5, 1, 3, 2, 'joe')")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(2))
+	tk.MustQuery("select id from t where id > 2").Check(testkit.Rows("4"))
+	tk.MustExec("delete from t where id = 4")
+
+	// The multi-column constraint is checked with the updated row.
+	checkErr("update t set lo = hi + 1 where id = 1", "chk_range")
+	tk.MustExec("update t set lo = 2, hi = 10 where id = 1")
+	checkErr("update t set hi = lo - 1", "chk_range")
+	checkErr("insert into t values (1, 20, 1, 2, 'tom') on duplicate key update age = -age", "t_chk_2")
+	tk.MustExec("insert into t values (1, 20, 1, 2, 'tom') on duplicate key update age = age + 1")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 21 2 10 tom", "2 <nil> <nil> 2 <nil>"))
+
+	// The constraints of the updated tables are checked in the multi-table update.
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (id int, v int check (v < 100))")
+	tk.MustExec("insert into t1 values (1, 50)")
+	checkErr("update t, t1 set t.age = t.age + 1, t1.v = t1.v * 2 where t.id = t1.id", "t1_chk_1")
+	tk.MustQuery("select age from t where id = 1").Check(testkit.Rows("21"))
+}
@@ -17,8 +17,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 
+	"github.com/cznic/mathutil"
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
@@ -114,6 +116,12 @@ func NewAggFunction(funcType string, funcArgs []Expression, distinct bool) Aggre
 		return &maxMinFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), isMax: false}
 	case ast.AggFuncFirstRow:
 		return &firstRowFunction{aggFunction: newAggFunc(tp, funcArgs, distinct)}
+	case ast.AggFuncBitAnd:
+		return &bitFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), identity: math.MaxUint64, compute: types.ComputeBitAnd}
+	case ast.AggFuncBitOr:
+		return &bitFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), compute: types.ComputeBitOr}
+	case ast.AggFuncBitXor:
+		return &bitFunction{aggFunction: newAggFunc(tp, funcArgs, distinct), compute: types.ComputeBitXor}
 	}
 	return nil
 }
@@ -405,7 +413,12 @@ func (sf *sumFunction) GetType() *types.FieldType {
 	ft := types.NewFieldType(mysql.TypeNewDecimal)
 	types.SetBinChsClnFlag(ft)
 	ft.Flen = mysql.MaxRealWidth
-	ft.Decimal = sf.Args[0].GetType().Decimal
+	argTp := sf.Args[0].GetType()
+	ft.Decimal = argTp.Decimal
+	if argTp.ToClass() == types.ClassDecimal && argTp.Flen != types.UnspecifiedLength {
+		// Like MySQL, the sum of DECIMAL(M, D) is DECIMAL(M + 22, D).
+		ft.Flen = mathutil.Min(argTp.Flen+22, mysql.MaxDecimalWidth)
+	}
 	return ft
 }
 
@@ -969,3 +982,107 @@ func (ff *firstRowFunction) CalculateDefaultValue(schema *Schema, ctx context.Co
 	}
 	return d, false
 }
+
+// bitFunction is used for bit_and, bit_or and bit_xor functions.
+// The argument is converted to an unsigned 64-bit integer, and a group without any non-null value
+// gets the identity of the operation, which is all bits set for bit_and and 0 for bit_or and bit_xor.
+type bitFunction struct {
+	aggFunction
+	identity uint64
+	compute  func(sc *variable.StatementContext, a, b types.Datum) (types.Datum, error)
+}
+
+// Clone implements AggregationFunction interface.
+func (bf *bitFunction) Clone() AggregationFunction {
+	nf := *bf
+	for i, arg := range bf.Args {
+		nf.Args[i] = arg.Clone()
+	}
+	nf.resultMapper = make(aggCtxMapper)
+	return &nf
+}
+
+// CalculateDefaultValue implements AggregationFunction interface.
+func (bf *bitFunction) CalculateDefaultValue(schema *Schema, ctx context.Context) (d types.Datum, valid bool) {
+	arg := bf.Args[0]
+	result, err := EvaluateExprWithNull(ctx, schema, arg)
+	if err != nil {
+		log.Warnf("Evaluate expr with null failed in function %s, err msg is %s", bf, err.Error())
+		return d, false
+	}
+	con, ok := result.(*Constant)
+	if !ok {
+		return d, false
+	}
+	d = types.NewUintDatum(bf.identity)
+	if con.Value.IsNull() {
+		return d, true
+	}
+	d, err = bf.compute(ctx.GetSessionVars().StmtCtx, d, con.Value)
+	if err != nil {
+		log.Warnf("Calculate default value failed in function %s, err msg is %s", bf, err.Error())
+		return d, false
+	}
+	return d, true
+}
+
+// GetType implements AggregationFunction interface.
+func (bf *bitFunction) GetType() *types.FieldType {
+	ft := types.NewFieldType(mysql.TypeLonglong)
+	ft.Flen = 21
+	types.SetBinChsClnFlag(ft)
+	ft.Flag |= mysql.UnsignedFlag | mysql.NotNullFlag
+	return ft
+}
+
+func (bf *bitFunction) update(ctx *aggEvaluateContext, row []types.Datum, sc *variable.StatementContext) error {
+	if len(bf.Args) != 1 {
+		return errors.New("Wrong number of args for AggFuncBit")
+	}
+	value, err := bf.Args[0].Eval(row)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if value.IsNull() {
+		return nil
+	}
+	if ctx.Value.IsNull() {
+		ctx.Value = types.NewUintDatum(bf.identity)
+	}
+	ctx.Value, err = bf.compute(sc, ctx.Value, value)
+	return errors.Trace(err)
+}
+
+// Update implements AggregationFunction interface.
+func (bf *bitFunction) Update(row []types.Datum, groupKey []byte, sc *variable.StatementContext) error {
+	return bf.update(bf.getContext(groupKey), row, sc)
+}
+
+// StreamUpdate implements AggregationFunction interface.
+func (bf *bitFunction) StreamUpdate(row []types.Datum, sc *variable.StatementContext) error {
+	return bf.update(bf.getStreamedContext(), row, sc)
+}
+
+func (bf *bitFunction) result(ctx *aggEvaluateContext) types.Datum {
+	if ctx == nil || ctx.Value.IsNull() {
+		return types.NewUintDatum(bf.identity)
+	}
+	return ctx.Value
+}
+
+// GetGroupResult implements AggregationFunction interface.
+func (bf *bitFunction) GetGroupResult(groupKey []byte) types.Datum {
+	return bf.result(bf.getContext(groupKey))
+}
+
+// GetPartialResult implements AggregationFunction interface.
+func (bf *bitFunction) GetPartialResult(groupKey []byte) []types.Datum {
+	return []types.Datum{bf.GetGroupResult(groupKey)}
+}
+
+// GetStreamResult implements AggregationFunction interface.
+func (bf *bitFunction) GetStreamResult() types.Datum {
+	d := bf.result(bf.streamCtx)
+	bf.streamCtx = nil
+	return d
+}
@@ -17,10 +17,12 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/cznic/mathutil"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -78,7 +80,7 @@ func setFlenDecimal4Int(retTp, a, b *types.FieldType) {
 
 // setFlenDecimal4Real is called to set proper `Flen` and `Decimal` of return
 // type according to the two input parameter's types.
-func setFlenDecimal4RealOrDecimal(retTp, a, b *types.FieldType, isReal bool) {
+func setFlenDecimal4Real(retTp, a, b *types.FieldType) {
 	if a.Decimal != types.UnspecifiedLength && b.Decimal != types.UnspecifiedLength {
 		retTp.Decimal = a.Decimal + b.Decimal
 		if a.Flen == types.UnspecifiedLength || b.Flen == types.UnspecifiedLength {
@@ -87,17 +89,73 @@ func setFlenDecimal4RealOrDecimal(retTp, a, b *types.FieldType, isReal bool) {
 		}
 		digitsInt := int(math.Max(float64(a.Flen-a.Decimal), float64(b.Flen-b.Decimal)))
 		retTp.Flen = digitsInt + retTp.Decimal + 3
-		if isReal {
-			retTp.Flen = int(math.Min(float64(retTp.Flen), float64(mysql.MaxRealWidth)))
-			return
-		}
-		retTp.Flen = int(math.Min(float64(retTp.Flen), float64(mysql.MaxDecimalWidth)))
+		retTp.Flen = int(math.Min(float64(retTp.Flen), float64(mysql.MaxRealWidth)))
 		return
 	}
 	retTp.Decimal = types.UnspecifiedLength
 	retTp.Flen = types.UnspecifiedLength
 }
 
+// decimalFlenAndDecimal returns the precision and scale of a numeric argument in decimal context,
+// the scale of an integer argument is 0.
+func decimalFlenAndDecimal(tp *types.FieldType) (flen, decimal int) {
+	flen, decimal = tp.Flen, tp.Decimal
+	if numericContextResultType(tp) == types.ClassInt {
+		decimal = 0
+	}
+	return
+}
+
+// setFlenDecimal4PlusMinusDecimal sets the `Flen` and `Decimal` of the decimal result of `+` and `-`
+// like MySQL: the scale is the larger scale of the arguments, and the integer part has one more
+// digit than the larger integer part of the arguments.
+func setFlenDecimal4PlusMinusDecimal(retTp, a, b *types.FieldType) {
+	flenA, decA := decimalFlenAndDecimal(a)
+	flenB, decB := decimalFlenAndDecimal(b)
+	if decA == types.UnspecifiedLength || decB == types.UnspecifiedLength {
+		retTp.Flen, retTp.Decimal = types.UnspecifiedLength, types.UnspecifiedLength
+		return
+	}
+	retTp.Decimal = mathutil.Max(decA, decB)
+	if flenA == types.UnspecifiedLength || flenB == types.UnspecifiedLength {
+		retTp.Flen = types.UnspecifiedLength
+		return
+	}
+	retTp.Flen = mathutil.Max(flenA-decA, flenB-decB) + 1 + retTp.Decimal
+	retTp.Flen = mathutil.Min(retTp.Flen, mysql.MaxDecimalWidth)
+}
+
+// setFlenDecimal4MulDecimal sets the `Flen` and `Decimal` of the decimal result of `*` like MySQL:
+// both the precision and the scale are the sums of the arguments' ones.
+func setFlenDecimal4MulDecimal(retTp, a, b *types.FieldType) {
+	flenA, decA := decimalFlenAndDecimal(a)
+	flenB, decB := decimalFlenAndDecimal(b)
+	if decA == types.UnspecifiedLength || decB == types.UnspecifiedLength {
+		retTp.Flen, retTp.Decimal = types.UnspecifiedLength, types.UnspecifiedLength
+		return
+	}
+	retTp.Decimal = mathutil.Min(decA+decB, mysql.MaxDecimalScale)
+	if flenA == types.UnspecifiedLength || flenB == types.UnspecifiedLength {
+		retTp.Flen = types.UnspecifiedLength
+		return
+	}
+	retTp.Flen = mathutil.Min(flenA+flenB, mysql.MaxDecimalWidth)
+}
+
+// checkDecimalResult rounds the scale of the decimal result to the max scale of DECIMAL, and returns
+// ErrOverflow if the result exceeds the max precision of DECIMAL.
+func checkDecimalResult(d *types.MyDecimal) error {
+	if int(d.GetDigitsFrac()) > mysql.MaxDecimalScale {
+		if err := d.Round(d, mysql.MaxDecimalScale, types.ModeHalfEven); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if precision, _ := d.PrecisionAndFrac(); precision > mysql.MaxDecimalWidth {
+		return types.ErrOverflow
+	}
+	return nil
+}
+
 func (c *arithmeticDivideFunctionClass) setType4DivDecimal(retTp, a, b *types.FieldType) {
 	var deca, decb = a.Decimal, b.Decimal
 	if deca == types.UnspecifiedFsp {
@@ -138,7 +196,7 @@ func (c *arithmeticPlusFunctionClass) getFunction(args []Expression, ctx context
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		setFlenDecimal4RealOrDecimal(bf.tp, args[0].GetType(), args[1].GetType(), true)
+		setFlenDecimal4Real(bf.tp, args[0].GetType(), args[1].GetType())
 		sig := &builtinArithmeticPlusRealSig{baseRealBuiltinFunc{bf}}
 		return sig.setSelf(sig), nil
 	} else if tcA == types.ClassDecimal || tcB == types.ClassDecimal {
@@ -146,7 +204,7 @@ func (c *arithmeticPlusFunctionClass) getFunction(args []Expression, ctx context
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		setFlenDecimal4RealOrDecimal(bf.tp, args[0].GetType(), args[1].GetType(), false)
+		setFlenDecimal4PlusMinusDecimal(bf.tp, tpA, tpB)
 		sig := &builtinArithmeticPlusDecimalSig{baseDecimalBuiltinFunc{bf}}
 		return sig.setSelf(sig), nil
 	} else {
@@ -180,10 +238,11 @@ func (s *builtinArithmeticPlusIntSig) evalInt(row []types.Datum) (val int64, isN
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	if (a > 0 && b > math.MaxInt64-a) || (a < 0 && b < math.MinInt64-a) {
-		return 0, true, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String()))
+	result, err := types.AddInt64(a, b)
+	if err != nil {
+		return overflowedInt64(a > 0), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String())))
 	}
-	return a + b, false, nil
+	return result, false, nil
 }
 
 type builtinArithmeticPlusIntUnsignedSig struct {
@@ -196,16 +255,29 @@ func (s *builtinArithmeticPlusIntUnsignedSig) evalInt(row []types.Datum) (val in
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedA := uint64(a)
 	b, isNull, err := s.args[1].EvalInt(row, sc)
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedB := uint64(b)
-	if unsignedA > math.MaxUint64-unsignedB {
-		return 0, true, types.ErrOverflow.GenByArgs("BIGINT UNSIGNED", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String()))
+	var (
+		result   uint64
+		positive bool
+	)
+	switch isUnsignedA, isUnsignedB := mysql.HasUnsignedFlag(s.args[0].GetType().Flag), mysql.HasUnsignedFlag(s.args[1].GetType().Flag); {
+	case isUnsignedA && isUnsignedB:
+		result, err = types.AddUint64(uint64(a), uint64(b))
+		positive = true
+	case isUnsignedA:
+		result, err = types.AddInteger(uint64(a), b)
+		positive = b >= 0
+	default:
+		result, err = types.AddInteger(uint64(b), a)
+		positive = a >= 0
 	}
-	return a + b, false, nil
+	if err != nil {
+		return int64(overflowedUint64(positive)), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("BIGINT UNSIGNED", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String())))
+	}
+	return int64(result), false, nil
 }
 
 type builtinArithmeticPlusDecimalSig struct {
@@ -224,7 +296,13 @@ func (s *builtinArithmeticPlusDecimalSig) evalDecimal(row []types.Datum) (*types
 	}
 	c := &types.MyDecimal{}
 	err = types.DecimalAdd(a, b, c)
+	if err == nil || terror.ErrorEqual(err, types.ErrTruncated) {
+		err = checkDecimalResult(c)
+	}
 	if err != nil {
+		if terror.ErrorEqual(err, types.ErrOverflow) {
+			return overflowedDecimal(a.IsNegative(), s.tp), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("DECIMAL", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String())))
+		}
 		return nil, true, errors.Trace(err)
 	}
 	return c, false, nil
@@ -245,7 +323,7 @@ func (s *builtinArithmeticPlusRealSig) evalReal(row []types.Datum) (float64, boo
 		return 0, isNull, errors.Trace(err)
 	}
 	if (a > 0 && b > math.MaxFloat64-a) || (a < 0 && b < -math.MaxFloat64-a) {
-		return 0, true, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String()))
+		return overflowedReal(a > 0), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s + %s)", s.args[0].String(), s.args[1].String())))
 	}
 	return a + b, false, nil
 }
@@ -265,7 +343,7 @@ func (c *arithmeticMinusFunctionClass) getFunction(args []Expression, ctx contex
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		setFlenDecimal4RealOrDecimal(bf.tp, args[0].GetType(), args[1].GetType(), true)
+		setFlenDecimal4Real(bf.tp, args[0].GetType(), args[1].GetType())
 		sig := &builtinArithmeticMinusRealSig{baseRealBuiltinFunc{bf}}
 		return sig.setSelf(sig), nil
 	} else if tcA == types.ClassDecimal || tcB == types.ClassDecimal {
@@ -273,7 +351,7 @@ func (c *arithmeticMinusFunctionClass) getFunction(args []Expression, ctx contex
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		setFlenDecimal4RealOrDecimal(bf.tp, args[0].GetType(), args[1].GetType(), false)
+		setFlenDecimal4PlusMinusDecimal(bf.tp, tpA, tpB)
 		sig := &builtinArithmeticMinusDecimalSig{baseDecimalBuiltinFunc{bf}}
 		return sig.setSelf(sig), nil
 	} else {
@@ -309,7 +387,7 @@ func (s *builtinArithmeticMinusRealSig) evalReal(row []types.Datum) (float64, bo
 		return 0, isNull, errors.Trace(err)
 	}
 	if (a > 0 && -b > math.MaxFloat64-a) || (a < 0 && -b < -math.MaxFloat64-a) {
-		return 0, true, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String()))
+		return overflowedReal(a > 0), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String())))
 	}
 	return a - b, false, nil
 }
@@ -326,7 +404,13 @@ func (s *builtinArithmeticMinusDecimalSig) evalDecimal(row []types.Datum) (*type
 	}
 	c := &types.MyDecimal{}
 	err = types.DecimalSub(a, b, c)
+	if err == nil || terror.ErrorEqual(err, types.ErrTruncated) {
+		err = checkDecimalResult(c)
+	}
 	if err != nil {
+		if terror.ErrorEqual(err, types.ErrOverflow) {
+			return overflowedDecimal(a.IsNegative(), s.tp), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("DECIMAL", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String())))
+		}
 		return nil, true, errors.Trace(err)
 	}
 	return c, false, nil
@@ -338,16 +422,27 @@ func (s *builtinArithmeticMinusIntUnsignedSig) evalInt(row []types.Datum) (val i
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedA := uint64(a)
 	b, isNull, err := s.args[1].EvalInt(row, sc)
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedB := uint64(b)
-	if unsignedA < unsignedB {
-		return 0, true, types.ErrOverflow.GenByArgs("BIGINT UNSIGNED", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String()))
+	var (
+		result   uint64
+		positive bool
+	)
+	switch isUnsignedA, isUnsignedB := mysql.HasUnsignedFlag(s.args[0].GetType().Flag), mysql.HasUnsignedFlag(s.args[1].GetType().Flag); {
+	case isUnsignedA && isUnsignedB:
+		result, err = types.SubUint64(uint64(a), uint64(b))
+	case isUnsignedA:
+		result, err = types.SubUintWithInt(uint64(a), b)
+		positive = b < 0
+	default:
+		result, err = types.SubIntWithUint(a, uint64(b))
 	}
-	return a - b, false, nil
+	if err != nil {
+		return int64(overflowedUint64(positive)), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("BIGINT UNSIGNED", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String())))
+	}
+	return int64(result), false, nil
 }
 
 func (s *builtinArithmeticMinusIntSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
@@ -360,10 +455,11 @@ func (s *builtinArithmeticMinusIntSig) evalInt(row []types.Datum) (val int64, is
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	if (a > 0 && -b > math.MaxInt64-a) || (a < 0 && -b < math.MinInt64-a) {
-		return 0, true, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String()))
+	result, err := types.SubInt64(a, b)
+	if err != nil {
+		return overflowedInt64(a >= 0), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%s - %s)", s.args[0].String(), s.args[1].String())))
 	}
-	return a - b, false, nil
+	return result, false, nil
 }
 
 type arithmeticMultiplyFunctionClass struct {
@@ -381,7 +477,7 @@ func (c *arithmeticMultiplyFunctionClass) getFunction(args []Expression, ctx con
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		setFlenDecimal4RealOrDecimal(bf.tp, args[0].GetType(), args[1].GetType(), true)
+		setFlenDecimal4Real(bf.tp, args[0].GetType(), args[1].GetType())
 		sig := &builtinArithmeticMultiplyRealSig{baseRealBuiltinFunc{bf}}
 		return sig.setSelf(sig), nil
 	} else if tcA == types.ClassDecimal || tcB == types.ClassDecimal {
@@ -389,7 +485,7 @@ func (c *arithmeticMultiplyFunctionClass) getFunction(args []Expression, ctx con
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		setFlenDecimal4RealOrDecimal(bf.tp, args[0].GetType(), args[1].GetType(), false)
+		setFlenDecimal4MulDecimal(bf.tp, tpA, tpB)
 		sig := &builtinArithmeticMultiplyDecimalSig{baseDecimalBuiltinFunc{bf}}
 		return sig.setSelf(sig), nil
 	} else {
@@ -426,7 +522,7 @@ func (s *builtinArithmeticMultiplyRealSig) evalReal(row []types.Datum) (float64,
 	}
 	result := a * b
 	if math.IsInf(result, 0) {
-		return 0, true, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String()))
+		return overflowedReal(result > 0), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String())))
 	}
 	return result, false, nil
 }
@@ -443,7 +539,13 @@ func (s *builtinArithmeticMultiplyDecimalSig) evalDecimal(row []types.Datum) (*t
 	}
 	c := &types.MyDecimal{}
 	err = types.DecimalMul(a, b, c)
+	if err == nil || terror.ErrorEqual(err, types.ErrTruncated) {
+		err = checkDecimalResult(c)
+	}
 	if err != nil {
+		if terror.ErrorEqual(err, types.ErrOverflow) {
+			return overflowedDecimal(a.IsNegative() != b.IsNegative(), s.tp), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("DECIMAL", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String())))
+		}
 		return nil, true, errors.Trace(err)
 	}
 	return c, false, nil
@@ -455,15 +557,27 @@ func (s *builtinArithmeticMultiplyIntUnsignedSig) evalInt(row []types.Datum) (va
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedA := uint64(a)
 	b, isNull, err := s.args[1].EvalInt(row, sc)
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	unsignedB := uint64(b)
-	result := unsignedA * unsignedB
-	if unsignedA != 0 && result/unsignedA != unsignedB {
-		return 0, true, types.ErrOverflow.GenByArgs("BIGINT UNSIGNED", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String()))
+	var (
+		result   uint64
+		positive bool
+	)
+	switch isUnsignedA, isUnsignedB := mysql.HasUnsignedFlag(s.args[0].GetType().Flag), mysql.HasUnsignedFlag(s.args[1].GetType().Flag); {
+	case isUnsignedA && isUnsignedB:
+		result, err = types.MulUint64(uint64(a), uint64(b))
+		positive = true
+	case isUnsignedA:
+		result, err = types.MulInteger(uint64(a), b)
+		positive = b >= 0
+	default:
+		result, err = types.MulInteger(uint64(b), a)
+		positive = a >= 0
+	}
+	if err != nil {
+		return int64(overflowedUint64(positive)), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("BIGINT UNSIGNED", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String())))
 	}
 	return int64(result), false, nil
 }
@@ -478,9 +592,9 @@ func (s *builtinArithmeticMultiplyIntSig) evalInt(row []types.Datum) (val int64,
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	result := a * b
-	if a != 0 && result/a != b {
-		return 0, true, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String()))
+	result, err := types.MulInt64(a, b)
+	if err != nil {
+		return overflowedInt64((a > 0) == (b > 0)), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%s * %s)", s.args[0].String(), s.args[1].String())))
 	}
 	return result, false, nil
 }
@@ -527,11 +641,11 @@ func (s *builtinArithmeticDivideRealSig) evalReal(row []types.Datum) (float64, b
 		return 0, isNull, errors.Trace(err)
 	}
 	if b == 0 {
-		return 0, true, nil
+		return 0, true, handleDivisionByZero(s.ctx)
 	}
 	result := a / b
 	if math.IsInf(result, 0) {
-		return 0, true, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s / %s)", s.args[0].String(), s.args[1].String()))
+		return overflowedReal(result > 0), false, handleArithmeticOverflow(s.ctx, types.ErrOverflow.GenByArgs("DOUBLE", fmt.Sprintf("(%s / %s)", s.args[0].String(), s.args[1].String())))
 	}
 	return result, false, nil
 }
@@ -551,7 +665,7 @@ func (s *builtinArithmeticDivideDecimalSig) evalDecimal(row []types.Datum) (*typ
 	c := &types.MyDecimal{}
 	err = types.DecimalDiv(a, b, c, types.DivFracIncr)
 	if err == types.ErrDivByZero {
-		return c, true, nil
+		return c, true, handleDivisionByZero(s.ctx)
 	}
 	return c, false, err
 }
@@ -599,14 +713,95 @@ func (s *builtinArithmeticSig) eval(row []types.Datum) (d types.Datum, err error
 
 	switch s.op {
 	case opcode.Mul:
-		return types.ComputeMul(a, b)
+		d, err = types.ComputeMul(a, b)
 	case opcode.Div:
-		return types.ComputeDiv(sc, a, b)
+		d, err = types.ComputeDiv(sc, a, b)
 	case opcode.Mod:
-		return types.ComputeMod(sc, a, b)
+		d, err = types.ComputeMod(sc, a, b)
 	case opcode.IntDiv:
-		return types.ComputeIntDiv(sc, a, b)
+		d, err = types.ComputeIntDiv(sc, a, b)
 	default:
 		return d, errInvalidOperation.Gen("invalid op %v in arithmetic operation", s.op)
 	}
+	if err != nil && terror.ErrorEqual(err, types.ErrOverflow) {
+		return overflowedDatum(a, b), handleArithmeticOverflow(s.ctx, err)
+	}
+	if err == nil && d.IsNull() {
+		// The result of the non-NULL operands is NULL only if the divisor is zero.
+		return d, handleDivisionByZero(s.ctx)
+	}
+	return d, errors.Trace(err)
+}
+
+// handleArithmeticOverflow handles the overflow error of an arithmetic operation based on the sql_mode.
+// In strict mode the error is returned, otherwise it is appended as a warning and the result is clipped
+// to the bound of its type by the caller, see overflowedInt64 and its siblings.
+func handleArithmeticOverflow(ctx context.Context, err error) error {
+	sessVars := ctx.GetSessionVars()
+	if sessVars.StrictSQLMode {
+		return errors.Trace(err)
+	}
+	sessVars.StmtCtx.AppendWarning(err)
+	return nil
+}
+
+// overflowedInt64 returns the bound of BIGINT which an overflowed result is clipped to.
+func overflowedInt64(positive bool) int64 {
+	if positive {
+		return math.MaxInt64
+	}
+	return math.MinInt64
+}
+
+// overflowedUint64 returns the bound of BIGINT UNSIGNED which an overflowed result is clipped to.
+func overflowedUint64(positive bool) uint64 {
+	if positive {
+		return math.MaxUint64
+	}
+	return 0
+}
+
+// overflowedReal returns the bound of DOUBLE which an overflowed result is clipped to.
+func overflowedReal(positive bool) float64 {
+	if positive {
+		return math.MaxFloat64
+	}
+	return -math.MaxFloat64
+}
+
+// overflowedDecimal returns the bound of the DECIMAL result type which an overflowed result is clipped to.
+func overflowedDecimal(negative bool, tp *types.FieldType) *types.MyDecimal {
+	frac := tp.Decimal
+	if frac == types.UnspecifiedLength || frac > mysql.MaxDecimalScale {
+		frac = mysql.MaxDecimalScale
+	}
+	return types.NewMaxOrMinDec(negative, mysql.MaxDecimalWidth, frac)
+}
+
+// overflowedDatum returns the bound which the overflowed result of the integer operands a and b is clipped to,
+// it's unsigned if either operand is unsigned and negative if the signs of the operands differ.
+func overflowedDatum(a, b types.Datum) (d types.Datum) {
+	negative := (a.Kind() == types.KindInt64 && a.GetInt64() < 0) != (b.Kind() == types.KindInt64 && b.GetInt64() < 0)
+	if a.Kind() == types.KindUint64 || b.Kind() == types.KindUint64 {
+		d.SetUint64(overflowedUint64(!negative))
+	} else {
+		d.SetInt64(overflowedInt64(!negative))
+	}
+	return d
+}
+
+// handleDivisionByZero handles the division by zero of an arithmetic operation based on the sql_mode, the result
+// is NULL. If ERROR_FOR_DIVISION_BY_ZERO is set, the error is returned for the statements changing data in strict
+// mode unless they are IGNORE, otherwise it's appended as a warning.
+func handleDivisionByZero(ctx context.Context) error {
+	sessVars := ctx.GetSessionVars()
+	if sessVars.SQLMode&mysql.ModeErrorForDivisionByZero == 0 {
+		return nil
+	}
+	sc := sessVars.StmtCtx
+	if sessVars.StrictSQLMode && (sc.InInsertStmt || sc.InUpdateOrDeleteStmt) && !sc.DividedByZeroAsWarning {
+		return errors.Trace(types.ErrDivByZero)
+	}
+	sc.AppendWarning(types.ErrDivByZero)
+	return nil
 }
@@ -22,7 +22,7 @@ import (
 	"github.com/pingcap/tidb/util/types"
 )
 
-func (s *testEvaluatorSuite) TestSetFlenDecimal4RealOrDecimal(c *C) {
+func (s *testEvaluatorSuite) TestSetFlenDecimal4Real(c *C) {
 	defer testleak.AfterTest(c)()
 
 	ret := &types.FieldType{}
@@ -34,25 +34,70 @@ func (s *testEvaluatorSuite) TestSetFlenDecimal4RealOrDecimal(c *C) {
 		Decimal: 0,
 		Flen:    2,
 	}
-	setFlenDecimal4RealOrDecimal(ret, a, b, true)
+	setFlenDecimal4Real(ret, a, b)
 	c.Assert(ret.Decimal, Equals, 1)
 	c.Assert(ret.Flen, Equals, 6)
 
 	b.Flen = 65
-	setFlenDecimal4RealOrDecimal(ret, a, b, true)
+	setFlenDecimal4Real(ret, a, b)
 	c.Assert(ret.Decimal, Equals, 1)
 	c.Assert(ret.Flen, Equals, mysql.MaxRealWidth)
-	setFlenDecimal4RealOrDecimal(ret, a, b, false)
+
+	b.Flen = types.UnspecifiedLength
+	setFlenDecimal4Real(ret, a, b)
 	c.Assert(ret.Decimal, Equals, 1)
+	c.Assert(ret.Flen, Equals, types.UnspecifiedLength)
+
+	b.Decimal = types.UnspecifiedLength
+	setFlenDecimal4Real(ret, a, b)
+	c.Assert(ret.Decimal, Equals, types.UnspecifiedLength)
+	c.Assert(ret.Flen, Equals, types.UnspecifiedLength)
+}
+
+func (s *testEvaluatorSuite) TestSetFlenDecimal4Decimal(c *C) {
+	defer testleak.AfterTest(c)()
+
+	ret := &types.FieldType{}
+	a := &types.FieldType{
+		Tp:      mysql.TypeNewDecimal,
+		Decimal: 2,
+		Flen:    10,
+	}
+	b := &types.FieldType{
+		Tp:      mysql.TypeNewDecimal,
+		Decimal: 2,
+		Flen:    10,
+	}
+	setFlenDecimal4PlusMinusDecimal(ret, a, b)
+	c.Assert(ret.Decimal, Equals, 2)
+	c.Assert(ret.Flen, Equals, 11)
+	setFlenDecimal4MulDecimal(ret, a, b)
+	c.Assert(ret.Decimal, Equals, 4)
+	c.Assert(ret.Flen, Equals, 20)
+
+	b.Tp, b.Flen, b.Decimal = mysql.TypeLong, 11, types.UnspecifiedLength
+	setFlenDecimal4PlusMinusDecimal(ret, a, b)
+	c.Assert(ret.Decimal, Equals, 2)
+	c.Assert(ret.Flen, Equals, 14)
+	setFlenDecimal4MulDecimal(ret, a, b)
+	c.Assert(ret.Decimal, Equals, 2)
+	c.Assert(ret.Flen, Equals, 21)
+
+	b.Tp, b.Flen, b.Decimal = mysql.TypeNewDecimal, 65, 30
+	setFlenDecimal4PlusMinusDecimal(ret, a, b)
+	c.Assert(ret.Decimal, Equals, 30)
+	c.Assert(ret.Flen, Equals, mysql.MaxDecimalWidth)
+	setFlenDecimal4MulDecimal(ret, a, b)
+	c.Assert(ret.Decimal, Equals, mysql.MaxDecimalScale)
 	c.Assert(ret.Flen, Equals, mysql.MaxDecimalWidth)
 
 	b.Flen = types.UnspecifiedLength
-	setFlenDecimal4RealOrDecimal(ret, a, b, true)
-	c.Assert(ret.Decimal, Equals, 1)
+	setFlenDecimal4PlusMinusDecimal(ret, a, b)
+	c.Assert(ret.Decimal, Equals, 30)
 	c.Assert(ret.Flen, Equals, types.UnspecifiedLength)
 
 	b.Decimal = types.UnspecifiedLength
-	setFlenDecimal4RealOrDecimal(ret, a, b, true)
+	setFlenDecimal4MulDecimal(ret, a, b)
 	c.Assert(ret.Decimal, Equals, types.UnspecifiedLength)
 	c.Assert(ret.Flen, Equals, types.UnspecifiedLength)
 }
@@ -962,8 +962,8 @@ func (b *builtinCastTimeAsTimeSig) evalTime(row []types.Datum) (res types.Time,
 	if b.tp.Tp == mysql.TypeDate {
 		// Truncate hh:mm:ss part if the type is Date.
 		res.Time = types.FromDate(res.Time.Year(), res.Time.Month(), res.Time.Day(), 0, 0, 0, 0)
-		res.Type = b.tp.Tp
 	}
+	res.Type = b.tp.Tp
 	return res, false, errors.Trace(err)
 }
 
@@ -34,7 +34,12 @@ var (
 )
 
 var (
-	_ builtinFunc = &builtinCoalesceSig{}
+	_ builtinFunc = &builtinCoalesceIntSig{}
+	_ builtinFunc = &builtinCoalesceRealSig{}
+	_ builtinFunc = &builtinCoalesceDecimalSig{}
+	_ builtinFunc = &builtinCoalesceStringSig{}
+	_ builtinFunc = &builtinCoalesceTimeSig{}
+	_ builtinFunc = &builtinCoalesceDurationSig{}
 	_ builtinFunc = &builtinGreatestSig{}
 	_ builtinFunc = &builtinLeastSig{}
 	_ builtinFunc = &builtinIntervalSig{}
@@ -86,36 +91,145 @@ type coalesceFunctionClass struct {
 	baseFunctionClass
 }
 
-func (c *coalesceFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
-	if err := c.verifyArgs(args); err != nil {
+func (c *coalesceFunctionClass) getFunction(args []Expression, ctx context.Context) (sig builtinFunc, err error) {
+	if err = c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	fieldTps := make([]*types.FieldType, 0, len(args))
+	for _, arg := range args {
+		fieldTps = append(fieldTps, arg.GetType())
+	}
+	fieldTp, tp := inferType4ControlFuncs(fieldTps)
+	if err = wrapWithCast4ControlFuncs(args, fieldTp, tp, ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]evalTp, 0, len(args))
+	for range args {
+		argTps = append(argTps, tp)
+	}
+	bf, err := newBaseBuiltinFuncWithTp(args, ctx, tp, argTps...)
+	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	sig := &builtinCoalesceSig{newBaseBuiltinFunc(args, ctx)}
+	bf.tp = fieldTp
+	switch tp {
+	case tpInt:
+		sig = &builtinCoalesceIntSig{baseIntBuiltinFunc{bf}}
+	case tpReal:
+		sig = &builtinCoalesceRealSig{baseRealBuiltinFunc{bf}}
+	case tpDecimal:
+		sig = &builtinCoalesceDecimalSig{baseDecimalBuiltinFunc{bf}}
+	case tpString:
+		sig = &builtinCoalesceStringSig{baseStringBuiltinFunc{bf}}
+	case tpTime:
+		sig = &builtinCoalesceTimeSig{baseTimeBuiltinFunc{bf}}
+	case tpDuration:
+		sig = &builtinCoalesceDurationSig{baseDurationBuiltinFunc{bf}}
+	}
 	return sig.setSelf(sig), nil
 }
 
-type builtinCoalesceSig struct {
-	baseBuiltinFunc
+type builtinCoalesceIntSig struct {
+	baseIntBuiltinFunc
 }
 
-func (b *builtinCoalesceSig) eval(row []types.Datum) (types.Datum, error) {
-	args, err := b.evalArgs(row)
-	if err != nil {
-		return types.Datum{}, errors.Trace(err)
+// evalInt evals a builtinCoalesceIntSig.
+// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
+func (b *builtinCoalesceIntSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	for _, a := range b.getArgs() {
+		res, isNull, err = a.EvalInt(row, sc)
+		if err != nil || !isNull {
+			break
+		}
 	}
-	return builtinCoalesce(args, b.ctx)
+	return res, isNull, errors.Trace(err)
 }
 
-// builtinCoalesce returns the first non-NULL value in the list,
-// or NULL if there are no non-NULL values.
+type builtinCoalesceRealSig struct {
+	baseRealBuiltinFunc
+}
+
+// evalReal evals a builtinCoalesceRealSig.
 // See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
-func builtinCoalesce(args []types.Datum, ctx context.Context) (d types.Datum, err error) {
-	for _, d = range args {
-		if !d.IsNull() {
-			return d, nil
+func (b *builtinCoalesceRealSig) evalReal(row []types.Datum) (res float64, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	for _, a := range b.getArgs() {
+		res, isNull, err = a.EvalReal(row, sc)
+		if err != nil || !isNull {
+			break
 		}
 	}
-	return d, nil
+	return res, isNull, errors.Trace(err)
+}
+
+type builtinCoalesceDecimalSig struct {
+	baseDecimalBuiltinFunc
+}
+
+// evalDecimal evals a builtinCoalesceDecimalSig.
+// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
+func (b *builtinCoalesceDecimalSig) evalDecimal(row []types.Datum) (res *types.MyDecimal, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	for _, a := range b.getArgs() {
+		res, isNull, err = a.EvalDecimal(row, sc)
+		if err != nil || !isNull {
+			break
+		}
+	}
+	return res, isNull, errors.Trace(err)
+}
+
+type builtinCoalesceStringSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals a builtinCoalesceStringSig.
+// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
+func (b *builtinCoalesceStringSig) evalString(row []types.Datum) (res string, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	for _, a := range b.getArgs() {
+		res, isNull, err = a.EvalString(row, sc)
+		if err != nil || !isNull {
+			break
+		}
+	}
+	return res, isNull, errors.Trace(err)
+}
+
+type builtinCoalesceTimeSig struct {
+	baseTimeBuiltinFunc
+}
+
+// evalTime evals a builtinCoalesceTimeSig.
+// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
+func (b *builtinCoalesceTimeSig) evalTime(row []types.Datum) (res types.Time, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	for _, a := range b.getArgs() {
+		res, isNull, err = a.EvalTime(row, sc)
+		if err != nil || !isNull {
+			break
+		}
+	}
+	res.Type = b.tp.Tp
+	return res, isNull, errors.Trace(err)
+}
+
+type builtinCoalesceDurationSig struct {
+	baseDurationBuiltinFunc
+}
+
+// evalDuration evals a builtinCoalesceDurationSig.
+// See http://dev.mysql.com/doc/refman/5.7/en/comparison-operators.html#function_coalesce
+func (b *builtinCoalesceDurationSig) evalDuration(row []types.Datum) (res types.Duration, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	for _, a := range b.getArgs() {
+		res, isNull, err = a.EvalDuration(row, sc)
+		if err != nil || !isNull {
+			break
+		}
+	}
+	return res, isNull, errors.Trace(err)
 }
 
 type greatestFunctionClass struct {
@@ -370,10 +484,22 @@ func (c *compareFunctionClass) getFunction(args []Expression, ctx context.Contex
 
 // genCmpSigs generates compare function signatures.
 func (c *compareFunctionClass) generateCmpSigs(args []Expression, tp evalTp, ctx context.Context) (sig builtinFunc, err error) {
+	origArgs := make([]Expression, len(args))
+	copy(origArgs, args)
 	bf, err := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tp, tp)
 	if err != nil {
 		return sig, errors.Trace(err)
 	}
+	if tp == tpTime {
+		// Date, datetime and timestamp values are compared by their time part, so they needn't be casted to datetime.
+		// Keeping the column uncasted lets the comparison be pushed down and be used to build the index range,
+		// the range builder already converts the literal to the column type and adjusts the open/closed bounds.
+		for i, arg := range origArgs {
+			if types.IsTypeTime(arg.GetType().Tp) {
+				bf.args[i] = arg
+			}
+		}
+	}
 	bf.tp.Flen = 1
 	intBf := baseIntBuiltinFunc{bf}
 	switch tp {
@@ -17,6 +17,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
@@ -114,17 +115,79 @@ func (s *testEvaluatorSuite) TestCoalesce(c *C) {
 	tests := []struct {
 		args     []interface{}
 		expected interface{}
+		isNil    bool
 	}{
-		{[]interface{}{1, nil, 1.2}, int64(1)},
-		{[]interface{}{nil, 1, 1, 2}, int64(1)},
-		{[]interface{}{nil, nil, 1}, int64(1)},
+		{[]interface{}{1, nil, 1.2}, float64(1), false},
+		{[]interface{}{nil, 1, 1, 2}, int64(1), false},
+		{[]interface{}{nil, nil, 1}, int64(1), false},
+		{[]interface{}{nil, types.NewDecFromFloatForTest(123.123), 1}, types.NewDecFromFloatForTest(123.123), false},
+		{[]interface{}{nil, "abc", 1}, "abc", false},
+		{[]interface{}{nil, tm}, tm, false},
+		{[]interface{}{nil, duration, nil}, duration, false},
+		{[]interface{}{nil, nil}, nil, true},
 	}
 
 	for _, t := range tests {
-		bf, err := funcs[ast.Coalesce].getFunction(primitiveValsToConstants(t.args), s.ctx)
+		f, err := newFunctionForTest(s.ctx, ast.Coalesce, primitiveValsToConstants(t.args)...)
 		c.Assert(err, IsNil)
-		result, err := bf.eval(nil)
-		c.Assert(result.GetValue(), Equals, t.expected)
+		d, err := f.Eval(nil)
 		c.Assert(err, IsNil)
+		if t.isNil {
+			c.Assert(d.Kind(), Equals, types.KindNull)
+		} else {
+			c.Assert(d.GetValue(), DeepEquals, t.expected)
+		}
+	}
+
+	newTp := func(tp byte, flen, decimal int, flag uint) *types.FieldType {
+		ft := types.NewFieldType(tp)
+		ft.Flen, ft.Decimal, ft.Flag = flen, decimal, flag
+		if mysql.HasBinaryFlag(flag) {
+			ft.Charset, ft.Collate = charset.CharsetBin, charset.CollationBin
+		}
+		return ft
+	}
+	typeTests := []struct {
+		args    []*types.FieldType
+		tp      byte
+		flen    int
+		decimal int
+		charset string
+		flag    uint
+	}{
+		{[]*types.FieldType{newTp(mysql.TypeLong, 11, 0, 0), newTp(mysql.TypeLonglong, 20, 0, 0)}, mysql.TypeLonglong, 20, 0, charset.CharsetBin, mysql.BinaryFlag},
+		{[]*types.FieldType{newTp(mysql.TypeNull, 0, -1, 0), newTp(mysql.TypeLong, 10, 0, mysql.UnsignedFlag)}, mysql.TypeLong, 10, 0, charset.CharsetBin, mysql.BinaryFlag | mysql.UnsignedFlag},
+		{[]*types.FieldType{newTp(mysql.TypeLonglong, 20, 0, mysql.UnsignedFlag), newTp(mysql.TypeLonglong, 20, 0, 0)}, mysql.TypeNewDecimal, 20, 0, charset.CharsetBin, mysql.BinaryFlag},
+		{[]*types.FieldType{newTp(mysql.TypeLong, 11, 0, 0), newTp(mysql.TypeNewDecimal, 10, 4, 0)}, mysql.TypeNewDecimal, 15, 4, charset.CharsetBin, mysql.BinaryFlag},
+		{[]*types.FieldType{newTp(mysql.TypeNewDecimal, 10, 2, 0), newTp(mysql.TypeDouble, 22, -1, 0)}, mysql.TypeDouble, 22, -1, charset.CharsetBin, mysql.BinaryFlag},
+		{[]*types.FieldType{newTp(mysql.TypeLong, 11, 0, 0), newTp(mysql.TypeVarchar, 30, 0, 0)}, mysql.TypeVarString, 30, 0, mysql.DefaultCharset, 0},
+		{[]*types.FieldType{newTp(mysql.TypeVarchar, 30, 0, 0), newTp(mysql.TypeBlob, 65535, 0, mysql.BinaryFlag)}, mysql.TypeBlob, 65535, 0, charset.CharsetBin, mysql.BinaryFlag},
+		{[]*types.FieldType{newTp(mysql.TypeDate, 10, 0, 0), newTp(mysql.TypeDatetime, 26, 6, 0)}, mysql.TypeDatetime, 26, 6, charset.CharsetBin, mysql.BinaryFlag},
+		{[]*types.FieldType{newTp(mysql.TypeDate, 10, 0, 0), newTp(mysql.TypeLong, 11, 0, 0)}, mysql.TypeVarString, 11, 0, mysql.DefaultCharset, 0},
+		{[]*types.FieldType{newTp(mysql.TypeNull, 0, -1, 0), newTp(mysql.TypeNull, 0, -1, 0)}, mysql.TypeNull, 0, -1, charset.CharsetBin, mysql.BinaryFlag},
+	}
+	for i, t := range typeTests {
+		args := make([]Expression, 0, len(t.args))
+		for _, tp := range t.args {
+			args = append(args, &Column{RetType: tp})
+		}
+		f, err := funcs[ast.Coalesce].getFunction(args, s.ctx)
+		c.Assert(err, IsNil)
+		tp := f.getRetTp()
+		comment := Commentf("case %d, obtained %s", i, tp)
+		c.Assert(tp.Tp, Equals, t.tp, comment)
+		c.Assert(tp.Flen, Equals, t.flen, comment)
+		c.Assert(tp.Decimal, Equals, t.decimal, comment)
+		c.Assert(tp.Charset, Equals, t.charset, comment)
+		c.Assert(tp.Flag, Equals, t.flag, comment)
+
+		// IFNULL infers the same type as COALESCE with two arguments.
+		args = args[:0]
+		for _, tp := range t.args {
+			args = append(args, &Column{RetType: tp})
+		}
+		f, err = funcs[ast.Ifnull].getFunction(args, s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.getRetTp(), DeepEquals, tp, comment)
 	}
 }
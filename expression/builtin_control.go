@@ -16,9 +16,9 @@ package expression
 import (
 	"github.com/cznic/mathutil"
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
-	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -57,43 +57,29 @@ func (c *caseWhenFunctionClass) getFunction(args []Expression, ctx context.Conte
 	if err = c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(err)
 	}
+	// when clause(condition, result) -> args[i], args[i+1]; else clause -> args[l-1] if l is odd.
 	l := len(args)
-	// Fill in each 'THEN' clause parameter type.
-	fieldTps := make([]*types.FieldType, 0, (l+1)/2)
-	decimal, flen, isBinaryStr := args[1].GetType().Decimal, 0, false
+	results := make([]Expression, 0, (l+1)/2)
 	for i := 1; i < l; i += 2 {
-		fieldTps = append(fieldTps, args[i].GetType())
-		decimal = mathutil.Max(decimal, args[i].GetType().Decimal)
-		flen = mathutil.Max(flen, args[i].GetType().Flen)
-		isBinaryStr = isBinaryStr || types.IsBinaryStr(args[i].GetType())
+		results = append(results, args[i])
 	}
 	if l%2 == 1 {
-		fieldTps = append(fieldTps, args[l-1].GetType())
-		decimal = mathutil.Max(decimal, args[l-1].GetType().Decimal)
-		flen = mathutil.Max(flen, args[l-1].GetType().Flen)
-		isBinaryStr = isBinaryStr || types.IsBinaryStr(args[l-1].GetType())
+		results = append(results, args[l-1])
 	}
-
-	fieldTp := types.AggFieldType(fieldTps)
-	tp := fieldTp2EvalTp(fieldTp)
-
-	if tp == tpInt {
-		decimal = 0
-	}
-	fieldTp.Decimal, fieldTp.Flen = decimal, flen
-	if fieldTp.ToClass() == types.ClassString && !isBinaryStr {
-		fieldTp.Charset, fieldTp.Collate = mysql.DefaultCharset, mysql.DefaultCollationName
-	}
-	// Set retType to BINARY(0) if all arguments are of type NULL.
-	if fieldTp.Tp == mysql.TypeNull {
-		fieldTp.Flen, fieldTp.Decimal = 0, -1
-		types.SetBinChsClnFlag(fieldTp)
+	fieldTp, tp, err := inferAndCastResults4ControlFuncs(results, ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
 	argTps := make([]evalTp, 0, l)
 	for i := 0; i < l-1; i += 2 {
+		if args[i], err = wrapWithIsTrue(args[i], ctx); err != nil {
+			return nil, errors.Trace(err)
+		}
+		args[i+1] = results[i/2]
 		argTps = append(argTps, tpInt, tp)
 	}
 	if l%2 == 1 {
+		args[l-1] = results[len(results)-1]
 		argTps = append(argTps, tp)
 	}
 	bf, err := newBaseBuiltinFuncWithTp(args, ctx, tp, argTps...)
@@ -262,6 +248,7 @@ func (b *builtinCaseWhenTimeSig) evalTime(row []types.Datum) (ret types.Time, is
 			continue
 		}
 		ret, isNull, err = args[i+1].EvalTime(row, sc)
+		ret.Type = b.tp.Tp
 		return ret, isNull, errors.Trace(err)
 	}
 	// when clause(condition, result) -> args[i], args[i+1]; (i >= 0 && i+1 < l-1)
@@ -269,6 +256,7 @@ func (b *builtinCaseWhenTimeSig) evalTime(row []types.Datum) (ret types.Time, is
 	// If case clause has else clause, l%2 == 1.
 	if l%2 == 1 {
 		ret, isNull, err = args[l-1].EvalTime(row, sc)
+		ret.Type = b.tp.Tp
 		return ret, isNull, errors.Trace(err)
 	}
 	return ret, true, nil
@@ -314,8 +302,13 @@ func (c *ifFunctionClass) getFunction(args []Expression, ctx context.Context) (s
 	if err = c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(err)
 	}
-	retTp := c.inferType(args[1].GetType(), args[2].GetType())
-	evalTps := fieldTp2EvalTp(retTp)
+	if args[0], err = wrapWithIsTrue(args[0], ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+	retTp, evalTps, err := inferAndCastResults4ControlFuncs(args[1:], ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	bf, err := newBaseBuiltinFuncWithTp(args, ctx, evalTps, tpInt, evalTps, evalTps)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -338,54 +331,6 @@ func (c *ifFunctionClass) getFunction(args []Expression, ctx context.Context) (s
 	return sig.setSelf(sig), nil
 }
 
-func (c *ifFunctionClass) inferType(tp1, tp2 *types.FieldType) *types.FieldType {
-	retTp, typeClass := &types.FieldType{}, types.ClassString
-	if tp1.Tp == mysql.TypeNull {
-		*retTp, typeClass = *tp2, tp2.ToClass()
-		// If both arguments are NULL, make resulting type BINARY(0).
-		if tp2.Tp == mysql.TypeNull {
-			retTp.Tp, typeClass = mysql.TypeString, types.ClassString
-			retTp.Flen, retTp.Decimal = 0, 0
-			types.SetBinChsClnFlag(retTp)
-		}
-	} else if tp2.Tp == mysql.TypeNull {
-		*retTp, typeClass = *tp1, tp1.ToClass()
-	} else {
-		var unsignedFlag uint
-		typeClass = types.AggTypeClass([]*types.FieldType{tp1, tp2}, &unsignedFlag)
-		retTp = types.AggFieldType([]*types.FieldType{tp1, tp2})
-		retTp.Decimal = mathutil.Max(tp1.Decimal, tp2.Decimal)
-		types.SetBinChsClnFlag(retTp)
-		if types.IsNonBinaryStr(tp1) && !types.IsBinaryStr(tp2) {
-			retTp.Charset, retTp.Collate, retTp.Flag = charset.CharsetUTF8, charset.CollationUTF8, 0
-			if mysql.HasBinaryFlag(tp1.Flag) {
-				retTp.Flag |= mysql.BinaryFlag
-			}
-		} else if types.IsNonBinaryStr(tp2) && !types.IsBinaryStr(tp1) {
-			retTp.Charset, retTp.Collate, retTp.Flag = charset.CharsetUTF8, charset.CollationUTF8, 0
-			if mysql.HasBinaryFlag(tp2.Flag) {
-				retTp.Flag |= mysql.BinaryFlag
-			}
-		}
-		if typeClass == types.ClassDecimal || typeClass == types.ClassInt {
-			unsignedFlag1, unsignedFlag2 := mysql.HasUnsignedFlag(tp1.Flag), mysql.HasUnsignedFlag(tp2.Flag)
-			flagLen1, flagLen2 := 0, 0
-			if !unsignedFlag1 {
-				flagLen1 = 1
-			}
-			if !unsignedFlag2 {
-				flagLen2 = 1
-			}
-			len1 := tp1.Flen - tp1.Decimal - flagLen1
-			len2 := tp2.Flen - tp2.Decimal - flagLen2
-			retTp.Flen = mathutil.Max(len1, len2) + retTp.Decimal + 1
-		} else {
-			retTp.Flen = mathutil.Max(tp1.Flen, tp2.Flen)
-		}
-	}
-	return retTp
-}
-
 type builtinIfIntSig struct {
 	baseIntBuiltinFunc
 }
@@ -396,21 +341,13 @@ func (b *builtinIfIntSig) evalInt(row []types.Datum) (ret int64, isNull bool, er
 	if err != nil {
 		return 0, false, errors.Trace(err)
 	}
-	arg1, isNull1, err := b.args[1].EvalInt(row, sc)
-	if err != nil {
-		return 0, false, errors.Trace(err)
-	}
-	arg2, isNull2, err := b.args[2].EvalInt(row, sc)
-	if err != nil {
-		return 0, false, errors.Trace(err)
-	}
-	switch {
-	case isNull0 || arg0 == 0:
-		ret, isNull = arg2, isNull2
-	case arg0 != 0:
-		ret, isNull = arg1, isNull1
+	// Only the returned branch is evaluated.
+	if !isNull0 && arg0 != 0 {
+		ret, isNull, err = b.args[1].EvalInt(row, sc)
+	} else {
+		ret, isNull, err = b.args[2].EvalInt(row, sc)
 	}
-	return
+	return ret, isNull, errors.Trace(err)
 }
 
 type builtinIfRealSig struct {
@@ -423,21 +360,13 @@ func (b *builtinIfRealSig) evalReal(row []types.Datum) (ret float64, isNull bool
 	if err != nil {
 		return 0, false, errors.Trace(err)
 	}
-	arg1, isNull1, err := b.args[1].EvalReal(row, sc)
-	if err != nil {
-		return 0, false, errors.Trace(err)
-	}
-	arg2, isNull2, err := b.args[2].EvalReal(row, sc)
-	if err != nil {
-		return 0, false, errors.Trace(err)
-	}
-	switch {
-	case isNull0 || arg0 == 0:
-		ret, isNull = arg2, isNull2
-	case arg0 != 0:
-		ret, isNull = arg1, isNull1
+	// Only the returned branch is evaluated.
+	if !isNull0 && arg0 != 0 {
+		ret, isNull, err = b.args[1].EvalReal(row, sc)
+	} else {
+		ret, isNull, err = b.args[2].EvalReal(row, sc)
 	}
-	return
+	return ret, isNull, errors.Trace(err)
 }
 
 type builtinIfDecimalSig struct {
@@ -450,21 +379,13 @@ func (b *builtinIfDecimalSig) evalDecimal(row []types.Datum) (ret *types.MyDecim
 	if err != nil {
 		return nil, false, errors.Trace(err)
 	}
-	arg1, isNull1, err := b.args[1].EvalDecimal(row, sc)
-	if err != nil {
-		return nil, false, errors.Trace(err)
-	}
-	arg2, isNull2, err := b.args[2].EvalDecimal(row, sc)
-	if err != nil {
-		return nil, false, errors.Trace(err)
-	}
-	switch {
-	case isNull0 || arg0 == 0:
-		ret, isNull = arg2, isNull2
-	case arg0 != 0:
-		ret, isNull = arg1, isNull1
+	// Only the returned branch is evaluated.
+	if !isNull0 && arg0 != 0 {
+		ret, isNull, err = b.args[1].EvalDecimal(row, sc)
+	} else {
+		ret, isNull, err = b.args[2].EvalDecimal(row, sc)
 	}
-	return
+	return ret, isNull, errors.Trace(err)
 }
 
 type builtinIfStringSig struct {
@@ -477,21 +398,13 @@ func (b *builtinIfStringSig) evalString(row []types.Datum) (ret string, isNull b
 	if err != nil {
 		return "", false, errors.Trace(err)
 	}
-	arg1, isNull1, err := b.args[1].EvalString(row, sc)
-	if err != nil {
-		return "", false, errors.Trace(err)
-	}
-	arg2, isNull2, err := b.args[2].EvalString(row, sc)
-	if err != nil {
-		return "", false, errors.Trace(err)
-	}
-	switch {
-	case isNull0 || arg0 == 0:
-		ret, isNull = arg2, isNull2
-	case arg0 != 0:
-		ret, isNull = arg1, isNull1
+	// Only the returned branch is evaluated.
+	if !isNull0 && arg0 != 0 {
+		ret, isNull, err = b.args[1].EvalString(row, sc)
+	} else {
+		ret, isNull, err = b.args[2].EvalString(row, sc)
 	}
-	return
+	return ret, isNull, errors.Trace(err)
 }
 
 type builtinIfTimeSig struct {
@@ -504,21 +417,15 @@ func (b *builtinIfTimeSig) evalTime(row []types.Datum) (ret types.Time, isNull b
 	if err != nil {
 		return ret, false, errors.Trace(err)
 	}
-	arg1, isNull1, err := b.args[1].EvalTime(row, sc)
-	if err != nil {
-		return ret, false, errors.Trace(err)
-	}
-	arg2, isNull2, err := b.args[2].EvalTime(row, sc)
-	if err != nil {
-		return ret, false, errors.Trace(err)
-	}
-	switch {
-	case isNull0 || arg0 == 0:
-		ret, isNull = arg2, isNull2
-	case arg0 != 0:
-		ret, isNull = arg1, isNull1
+	// Only the returned branch is evaluated.
+	if !isNull0 && arg0 != 0 {
+		ret, isNull, err = b.args[1].EvalTime(row, sc)
+	} else {
+		ret, isNull, err = b.args[2].EvalTime(row, sc)
 	}
-	return
+	// The results are evaluated as datetimes, the value has the result type, e.g. DATE.
+	ret.Type = b.tp.Tp
+	return ret, isNull, errors.Trace(err)
 }
 
 type builtinIfDurationSig struct {
@@ -531,21 +438,13 @@ func (b *builtinIfDurationSig) evalDuration(row []types.Datum) (ret types.Durati
 	if err != nil {
 		return ret, false, errors.Trace(err)
 	}
-	arg1, isNull1, err := b.args[1].EvalDuration(row, sc)
-	if err != nil {
-		return ret, false, errors.Trace(err)
-	}
-	arg2, isNull2, err := b.args[2].EvalDuration(row, sc)
-	if err != nil {
-		return ret, false, errors.Trace(err)
-	}
-	switch {
-	case isNull0 || arg0 == 0:
-		ret, isNull = arg2, isNull2
-	case arg0 != 0:
-		ret, isNull = arg1, isNull1
+	// Only the returned branch is evaluated.
+	if !isNull0 && arg0 != 0 {
+		ret, isNull, err = b.args[1].EvalDuration(row, sc)
+	} else {
+		ret, isNull, err = b.args[2].EvalDuration(row, sc)
 	}
-	return
+	return ret, isNull, errors.Trace(err)
 }
 
 type ifNullFunctionClass struct {
@@ -556,56 +455,166 @@ func (c *ifNullFunctionClass) getFunction(args []Expression, ctx context.Context
 	if err = errors.Trace(c.verifyArgs(args)); err != nil {
 		return nil, errors.Trace(err)
 	}
-	tp0, tp1 := args[0].GetType(), args[1].GetType()
-	fieldTp := types.AggFieldType([]*types.FieldType{tp0, tp1})
-	types.SetBinChsClnFlag(fieldTp)
-	classType := types.AggTypeClass([]*types.FieldType{tp0, tp1}, &fieldTp.Flag)
-	fieldTp.Decimal = mathutil.Max(tp0.Decimal, tp1.Decimal)
-	// TODO: make it more accurate when inferring FLEN
-	fieldTp.Flen = tp0.Flen + tp1.Flen
+	fieldTp, tp := inferType4ControlFuncs([]*types.FieldType{args[0].GetType(), args[1].GetType()})
+	if err = wrapWithCast4ControlFuncs(args, fieldTp, tp, ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf, err := newBaseBuiltinFuncWithTp(args, ctx, tp, tp, tp)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf.tp = fieldTp
+	switch tp {
+	case tpInt:
+		sig = &builtinIfNullIntSig{baseIntBuiltinFunc{bf}}
+	case tpReal:
+		sig = &builtinIfNullRealSig{baseRealBuiltinFunc{bf}}
+	case tpDecimal:
+		sig = &builtinIfNullDecimalSig{baseDecimalBuiltinFunc{bf}}
+	case tpString:
+		sig = &builtinIfNullStringSig{baseStringBuiltinFunc{bf}}
+	case tpTime:
+		sig = &builtinIfNullTimeSig{baseTimeBuiltinFunc{bf}}
+	case tpDuration:
+		sig = &builtinIfNullDurationSig{baseDurationBuiltinFunc{bf}}
+	}
+	return sig.setSelf(sig), nil
+}
+
+// inferType4ControlFuncs infers the result type of IF, CASE, IFNULL and COALESCE, which return one of their arguments.
+// The type is the aggregated type of the arguments, NULL arguments are ignored and the result is BINARY(0)
+// if all the arguments are NULL. A decimal result is wide enough to hold the integer digits and the
+// fraction digits of every argument.
+// See https://dev.mysql.com/doc/refman/5.7/en/control-flow-functions.html#function_ifnull
+func inferType4ControlFuncs(argTps []*types.FieldType) (*types.FieldType, evalTp) {
+	tps := make([]*types.FieldType, 0, len(argTps))
+	for _, tp := range argTps {
+		if tp.Tp != mysql.TypeNull {
+			tps = append(tps, tp)
+		}
+	}
+	if len(tps) == 0 {
+		fieldTp := types.NewFieldType(mysql.TypeNull)
+		fieldTp.Flen, fieldTp.Decimal = 0, types.UnspecifiedLength
+		types.SetBinChsClnFlag(fieldTp)
+		return fieldTp, tpString
+	}
+
+	fieldTp := types.NewFieldType(types.AggFieldType(tps).Tp)
+	classType := types.AggTypeClass(tps, &fieldTp.Flag)
+	flen, decimal, digitsInt := 0, 0, 0
+	for _, tp := range tps {
+		if tp.Flen == types.UnspecifiedLength || flen == types.UnspecifiedLength {
+			flen = types.UnspecifiedLength
+		} else {
+			flen = mathutil.Max(flen, tp.Flen)
+		}
+		argDecimal := tp.Decimal
+		if tp.ToClass() == types.ClassInt || (argDecimal == types.UnspecifiedFsp && (types.IsTypeTime(tp.Tp) || tp.Tp == mysql.TypeDuration)) {
+			argDecimal = 0
+		}
+		if argDecimal == types.UnspecifiedLength || decimal == types.UnspecifiedLength {
+			decimal = types.UnspecifiedLength
+		} else {
+			decimal = mathutil.Max(decimal, argDecimal)
+		}
+		digitsInt = mathutil.Max(digitsInt, tp.Flen-mathutil.Max(argDecimal, 0))
+	}
 
-	var evalTps evalTp
+	var tp evalTp
 	switch classType {
 	case types.ClassInt:
-		evalTps = tpInt
-		fieldTp.Decimal = 0
+		tp, decimal = tpInt, 0
 	case types.ClassReal:
-		evalTps = tpReal
+		tp = tpReal
 	case types.ClassDecimal:
-		evalTps = tpDecimal
-	case types.ClassString:
-		evalTps = tpString
-		if !types.IsBinaryStr(tp0) && !types.IsBinaryStr(tp1) {
-			fieldTp.Charset, fieldTp.Collate = mysql.DefaultCharset, mysql.DefaultCollationName
-			fieldTp.Flag ^= mysql.BinaryFlag
+		// The aggregated type of a signed and an unsigned BIGINT is BIGINT, but the result is a decimal.
+		tp, fieldTp.Tp = tpDecimal, mysql.TypeNewDecimal
+		if decimal == types.UnspecifiedLength {
+			decimal = mysql.MaxDecimalScale
+		}
+		if flen != types.UnspecifiedLength {
+			flen = mathutil.Min(digitsInt+decimal, mysql.MaxDecimalWidth)
 		}
-		if types.IsTypeTime(fieldTp.Tp) {
-			evalTps = tpTime
-		} else if fieldTp.Tp == mysql.TypeDuration {
-			evalTps = tpDuration
+	default:
+		tp = fieldTp2EvalTp(fieldTp)
+		switch fieldTp.Tp {
+		case mysql.TypeVarchar, mysql.TypeEnum, mysql.TypeSet:
+			// VARCHAR, ENUM and SET results are returned as VAR_STRING.
+			fieldTp.Tp = mysql.TypeVarString
 		}
 	}
-	bf, err := newBaseBuiltinFuncWithTp(args, ctx, evalTps, evalTps, evalTps)
-	if err != nil {
-		return nil, errors.Trace(err)
+	fieldTp.Flen, fieldTp.Decimal = flen, decimal
+
+	if tp != tpString {
+		types.SetBinChsClnFlag(fieldTp)
+		return fieldTp, tp
+	}
+	// The result is a binary string only if some argument is, numbers and times are converted to utf8 strings.
+	// A non-binary string argument with the binary flag, e.g. CHAR(20) BINARY, keeps the flag.
+	fieldTp.Flag &^= mysql.BinaryFlag
+	fieldTp.Charset, fieldTp.Collate = mysql.DefaultCharset, mysql.DefaultCollationName
+	for _, argTp := range tps {
+		if types.IsBinaryStr(argTp) {
+			types.SetBinChsClnFlag(fieldTp)
+			break
+		}
+		if types.IsNonBinaryStr(argTp) && mysql.HasBinaryFlag(argTp.Flag) {
+			fieldTp.Flag |= mysql.BinaryFlag
+		}
 	}
-	bf.tp = fieldTp
-	switch classType {
+	return fieldTp, tp
+}
+
+// wrapWithCast4ControlFuncs casts the arguments of IFNULL and COALESCE, or the results of IF and CASE,
+// to the result type if it is a decimal or a time,
+// so the result has the same fraction digits and time type whichever argument is returned.
+func wrapWithCast4ControlFuncs(args []Expression, fieldTp *types.FieldType, tp evalTp, ctx context.Context) (err error) {
+	if tp != tpDecimal && tp != tpTime {
+		return nil
+	}
+	for i, arg := range args {
+		argTp := arg.GetType()
+		if argTp.Tp == mysql.TypeNull || (argTp.Tp == fieldTp.Tp && argTp.Decimal == fieldTp.Decimal) {
+			continue
+		}
+		castTp := *fieldTp
+		args[i], err = buildCastFunction(arg, &castTp, ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// inferAndCastResults4ControlFuncs infers the result type of IF and CASE from their result arguments,
+// and casts the result arguments to it if needed.
+func inferAndCastResults4ControlFuncs(results []Expression, ctx context.Context) (*types.FieldType, evalTp, error) {
+	fieldTps := make([]*types.FieldType, 0, len(results))
+	for _, result := range results {
+		fieldTps = append(fieldTps, result.GetType())
+	}
+	fieldTp, tp := inferType4ControlFuncs(fieldTps)
+	err := wrapWithCast4ControlFuncs(results, fieldTp, tp, ctx)
+	return fieldTp, tp, errors.Trace(err)
+}
+
+// wrapWithIsTrue wraps the condition of IF and CASE WHEN with IS TRUE if it isn't an integer,
+// since a condition is true if it's non-zero, e.g. 0.1 and '0.1' are true, but they're false when converted to integers.
+func wrapWithIsTrue(cond Expression, ctx context.Context) (Expression, error) {
+	switch cond.GetTypeClass() {
 	case types.ClassInt:
-		sig = &builtinIfNullIntSig{baseIntBuiltinFunc{bf}}
-	case types.ClassReal:
-		sig = &builtinIfNullRealSig{baseRealBuiltinFunc{bf}}
-	case types.ClassDecimal:
-		sig = &builtinIfNullDecimalSig{baseDecimalBuiltinFunc{bf}}
+		return cond, nil
 	case types.ClassString:
-		sig = &builtinIfNullStringSig{baseStringBuiltinFunc{bf}}
-		if types.IsTypeTime(fieldTp.Tp) {
-			sig = &builtinIfNullTimeSig{baseTimeBuiltinFunc{bf}}
-		} else if fieldTp.Tp == mysql.TypeDuration {
-			sig = &builtinIfNullDurationSig{baseDurationBuiltinFunc{bf}}
+		// Strings are converted to doubles, like MySQL does.
+		var err error
+		cond, err = buildCastFunction(cond, types.NewFieldType(mysql.TypeDouble), ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
 	}
-	return sig.setSelf(sig), nil
+	isTrue, err := NewFunction(ctx, ast.IsTruth, types.NewFieldType(mysql.TypeLonglong), cond)
+	return isTrue, errors.Trace(err)
 }
 
 type builtinIfNullIntSig struct {
@@ -672,9 +681,11 @@ func (b *builtinIfNullTimeSig) evalTime(row []types.Datum) (types.Time, bool, er
 	sc := b.ctx.GetSessionVars().StmtCtx
 	arg0, isNull, err := b.args[0].EvalTime(row, sc)
 	if !isNull {
+		arg0.Type = b.tp.Tp
 		return arg0, false, errors.Trace(err)
 	}
 	arg1, isNull, err := b.args[1].EvalTime(row, sc)
+	arg1.Type = b.tp.Tp
 	return arg1, isNull, errors.Trace(err)
 }
 
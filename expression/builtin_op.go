@@ -652,12 +652,12 @@ func (b *builtinUnaryMinusIntSig) evalInt(row []types.Datum) (res int64, isNull
 	if mysql.HasUnsignedFlag(b.args[0].GetType().Flag) {
 		uval := uint64(val)
 		if uval > uint64(-math.MinInt64) {
-			return 0, false, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("-%v", uval))
+			return math.MinInt64, false, handleArithmeticOverflow(b.ctx, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("-%v", uval)))
 		} else if uval == uint64(-math.MinInt64) {
 			return math.MinInt64, false, nil
 		}
 	} else if val == math.MinInt64 {
-		return 0, false, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("-%v", val))
+		return math.MaxInt64, false, handleArithmeticOverflow(b.ctx, types.ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("-%v", val)))
 	}
 	return -val, false, errors.Trace(err)
 }
@@ -157,10 +157,13 @@ type builtinValuesSig struct {
 	offset int
 }
 
+// eval evals VALUES(col), it's the value the current row of INSERT would insert into the column,
+// and it's NULL out of the ON DUPLICATE KEY UPDATE clause.
+// See http://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_values
 func (b *builtinValuesSig) eval(_ []types.Datum) (types.Datum, error) {
 	values := b.ctx.GetSessionVars().CurrInsertValues
 	if values == nil {
-		return types.Datum{}, errors.New("Session current insert values is nil")
+		return types.Datum{}, nil
 	}
 	row := values.([]types.Datum)
 	if len(row) > b.offset {
@@ -162,14 +162,15 @@ func (s *testEvaluatorSuite) TestValues(c *C) {
 	sig, err := fc.getFunction(datumsToConstants(types.MakeDatums()), s.ctx)
 	c.Assert(err, IsNil)
 	c.Assert(sig.isDeterministic(), Equals, false)
-	_, err = sig.eval(nil)
-	c.Assert(err.Error(), Equals, "Session current insert values is nil")
+	ret, err := sig.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(ret.IsNull(), IsTrue)
 	s.ctx.GetSessionVars().CurrInsertValues = types.MakeDatums("1")
 	_, err = sig.eval(nil)
 	c.Assert(err.Error(), Equals, fmt.Sprintf("Session current insert values len %d and column's offset %v don't match", 1, 1))
 	currInsertValues := types.MakeDatums("1", "2")
 	s.ctx.GetSessionVars().CurrInsertValues = currInsertValues
-	ret, err := sig.eval(nil)
+	ret, err = sig.eval(nil)
 	c.Assert(err, IsNil)
 	cmp, err := ret.CompareDatum(nil, currInsertValues[1])
 	c.Assert(err, IsNil)
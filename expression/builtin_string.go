@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/types"
@@ -126,6 +127,9 @@ var (
 	_ builtinFunc = &builtinCharSig{}
 	_ builtinFunc = &builtinCharLengthSig{}
 	_ builtinFunc = &builtinFindInSetSig{}
+	_ builtinFunc = &builtinFieldIntSig{}
+	_ builtinFunc = &builtinFieldRealSig{}
+	_ builtinFunc = &builtinFieldStringSig{}
 	_ builtinFunc = &builtinMakeSetSig{}
 	_ builtinFunc = &builtinOctSig{}
 	_ builtinFunc = &builtinOrdSig{}
@@ -521,13 +525,44 @@ func (c *repeatFunctionClass) getFunction(args []Expression, ctx context.Context
 		return nil, errors.Trace(err)
 	}
 	bf.tp.Flen = mysql.MaxBlobWidth
+	if con, ok := args[1].(*Constant); ok && args[0].GetType().Flen >= 0 {
+		num, isNull, err := con.EvalInt(nil, ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if isNull || num < 1 {
+			bf.tp.Flen = 0
+		} else if int64(args[0].GetType().Flen) < mysql.MaxBlobWidth/num {
+			bf.tp.Flen = args[0].GetType().Flen * int(num)
+		}
+	}
 	setBinFlagOrBinStr(args[0].GetType(), bf.tp)
-	sig := &builtinRepeatSig{baseStringBuiltinFunc{bf}}
+	maxAllowedPacket, err := getMaxAllowedPacket(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinRepeatSig{baseStringBuiltinFunc{bf}, maxAllowedPacket}
 	return sig.setSelf(sig), nil
 }
 
+// getMaxAllowedPacket returns the max_allowed_packet of the session, the functions return NULL for the results longer than it.
+func getMaxAllowedPacket(ctx context.Context) (uint64, error) {
+	sessionVars := ctx.GetSessionVars()
+	val := variable.SysVars[variable.MaxAllowedPacket].Value
+	if sessionVars.GlobalVarsAccessor != nil {
+		var err error
+		val, err = varsutil.GetSessionSystemVar(sessionVars, variable.MaxAllowedPacket)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	maxAllowedPacket, err := strconv.ParseUint(val, 10, 64)
+	return maxAllowedPacket, errors.Trace(err)
+}
+
 type builtinRepeatSig struct {
 	baseStringBuiltinFunc
+	maxAllowedPacket uint64
 }
 
 // eval evals a builtinRepeatSig.
@@ -550,7 +585,8 @@ func (b *builtinRepeatSig) evalString(row []types.Datum) (d string, isNull bool,
 		num = math.MaxInt32
 	}
 
-	if int64(len(str)) > int64(b.tp.Flen)/num {
+	if uint64(len(str)) > b.maxAllowedPacket/uint64(num) {
+		sc.AppendWarning(errWarnAllowedPacketOverflowed.GenByArgs("repeat", b.maxAllowedPacket))
 		return "", true, nil
 	}
 	return strings.Repeat(str, int(num)), false, nil
@@ -1098,6 +1134,11 @@ func (b *builtinSubstringIndexSig) evalString(row []types.Datum) (d string, isNu
 		return "", false, nil
 	}
 
+	// A huge unsigned count is still counted from the left.
+	if count < 0 && mysql.HasUnsignedFlag(b.args[2].GetType().Flag) {
+		count = math.MaxInt64
+	}
+
 	strs := strings.Split(str, delim)
 	start, end := int64(0), int64(len(strs))
 	if count > 0 {
@@ -1105,12 +1146,14 @@ func (b *builtinSubstringIndexSig) evalString(row []types.Datum) (d string, isNu
 		if count < end {
 			end = count
 		}
-	} else {
+	} else if count < 0 {
 		// If count is negative, everything to the right of the final delimiter (counting from the right) is returned.
-		count = -count
-		if count < end {
-			start = end - count
+		// Compare -count with end by end + count, because -count overflows when count is math.MinInt64.
+		if end+count > 0 {
+			start = end + count
 		}
+	} else {
+		end = 0
 	}
 	substrs := strs[start:end]
 	return strings.Join(substrs, delim), false, nil
@@ -1132,25 +1175,39 @@ func (c *locateFunctionClass) getFunction(args []Expression, ctx context.Context
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	bf.tp.Flen = 11
 	var sig builtinFunc
-	// Loacte is multibyte safe, and is case-sensitive only if at least one argument is a binary string.
-	hasBianryInput := types.IsBinaryStr(args[0].GetType()) || types.IsBinaryStr(args[1].GetType())
+	// Locate is multibyte safe, and its case sensitivity is derived from the collations of the arguments.
+	hasBinaryInput := types.IsBinaryStr(args[0].GetType()) || types.IsBinaryStr(args[1].GetType())
+	caseSensitive := isCaseSensitive(args[0].GetType()) || isCaseSensitive(args[1].GetType())
 	switch {
-	case hasStartPos && hasBianryInput:
+	case hasStartPos && hasBinaryInput:
 		sig = &builtinLocateBinary3ArgsSig{baseIntBuiltinFunc{bf}}
-		break
 	case hasStartPos:
-		sig = &builtinLocate3ArgsSig{baseIntBuiltinFunc{bf}}
-		break
-	case hasBianryInput:
+		sig = &builtinLocate3ArgsSig{baseIntBuiltinFunc{bf}, caseSensitive}
+	case hasBinaryInput:
 		sig = &builtinLocateBinary2ArgsSig{baseIntBuiltinFunc{bf}}
-		break
 	default:
-		sig = &builtinLocate2ArgsSig{baseIntBuiltinFunc{bf}}
+		sig = &builtinLocate2ArgsSig{baseIntBuiltinFunc{bf}, caseSensitive}
 	}
 	return sig.setSelf(sig), nil
 }
 
+// isCaseSensitive returns whether the string search on the argument of the type is case-sensitive, which is true
+// for the binary strings and the `_cs` collations. The `_bin` collations of the non-binary charsets are only the
+// nominal defaults, so they keep the case-insensitive behavior of MySQL's default `_general_ci` collations.
+func isCaseSensitive(tp *types.FieldType) bool {
+	return types.IsBinaryStr(tp) || strings.HasSuffix(tp.Collate, "_cs")
+}
+
+// toLowerIfCaseInsensitive returns the lower case of the string if the search is case-insensitive.
+func toLowerIfCaseInsensitive(str string, caseSensitive bool) string {
+	if caseSensitive {
+		return str
+	}
+	return strings.ToLower(str)
+}
+
 type builtinLocateBinary2ArgsSig struct {
 	baseIntBuiltinFunc
 }
@@ -1180,9 +1237,10 @@ func (b *builtinLocateBinary2ArgsSig) evalInt(row []types.Datum) (int64, bool, e
 
 type builtinLocate2ArgsSig struct {
 	baseIntBuiltinFunc
+	caseSensitive bool
 }
 
-// evalInt evals LOCATE(substr,str), non case-sensitive.
+// evalInt evals LOCATE(substr,str), case-sensitive only if the collation of any argument is.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_locate
 func (b *builtinLocate2ArgsSig) evalInt(row []types.Datum) (int64, bool, error) {
 	sc := b.ctx.GetSessionVars().StmtCtx
@@ -1197,8 +1255,8 @@ func (b *builtinLocate2ArgsSig) evalInt(row []types.Datum) (int64, bool, error)
 	if int64(len([]rune(subStr))) == 0 {
 		return 1, false, nil
 	}
-	slice := string([]rune(strings.ToLower(str)))
-	ret, idx := 0, strings.Index(slice, strings.ToLower(subStr))
+	slice := toLowerIfCaseInsensitive(str, b.caseSensitive)
+	ret, idx := 0, strings.Index(slice, toLowerIfCaseInsensitive(subStr, b.caseSensitive))
 	if idx != -1 {
 		ret = utf8.RuneCountInString(slice[:idx]) + 1
 	}
@@ -1243,9 +1301,10 @@ func (b *builtinLocateBinary3ArgsSig) evalInt(row []types.Datum) (int64, bool, e
 
 type builtinLocate3ArgsSig struct {
 	baseIntBuiltinFunc
+	caseSensitive bool
 }
 
-// evalInt evals LOCATE(substr,str,pos), non case-sensitive.
+// evalInt evals LOCATE(substr,str,pos), case-sensitive only if the collation of any argument is.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_locate
 func (b *builtinLocate3ArgsSig) evalInt(row []types.Datum) (int64, bool, error) {
 	sc := b.ctx.GetSessionVars().StmtCtx
@@ -1264,13 +1323,14 @@ func (b *builtinLocate3ArgsSig) evalInt(row []types.Datum) (int64, bool, error)
 		return 0, isNull, errors.Trace(err)
 	}
 	subStrLen := len([]rune(subStr))
-	if pos < 0 || pos > int64(len([]rune(strings.ToLower(str)))-subStrLen) {
+	runes := []rune(toLowerIfCaseInsensitive(str, b.caseSensitive))
+	if pos < 0 || pos > int64(len(runes)-subStrLen) {
 		return 0, false, nil
 	} else if subStrLen == 0 {
 		return pos + 1, false, nil
 	}
-	slice := string([]rune(strings.ToLower(str))[pos:])
-	idx := strings.Index(slice, strings.ToLower(subStr))
+	slice := string(runes[pos:])
+	idx := strings.Index(slice, toLowerIfCaseInsensitive(subStr, b.caseSensitive))
 	if idx != -1 {
 		return pos + int64(utf8.RuneCountInString(slice[:idx])) + 1, false, nil
 	}
@@ -2048,88 +2108,119 @@ type fieldFunctionClass struct {
 	baseFunctionClass
 }
 
+// getFunction compares the arguments as strings if all of them are strings, as integers if all of them are integers,
+// otherwise as doubles.
 func (c *fieldFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	if err := c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(err)
 	}
-	sig := &builtinFieldSig{newBaseBuiltinFunc(args, ctx)}
+	isAllString, isAllInt := true, true
+	for _, arg := range args {
+		tp := fieldTp2EvalTp(arg.GetType())
+		isAllString = isAllString && tp == tpString
+		isAllInt = isAllInt && tp == tpInt
+	}
+	argTp := tpReal
+	if isAllString {
+		argTp = tpString
+	} else if isAllInt {
+		argTp = tpInt
+	}
+	argTps := make([]evalTp, len(args))
+	for i := range argTps {
+		argTps[i] = argTp
+	}
+	bf, err := newBaseBuiltinFuncWithTp(args, ctx, tpInt, argTps...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf.tp.Flen = 3
+	var sig builtinFunc
+	switch argTp {
+	case tpString:
+		sig = &builtinFieldStringSig{baseIntBuiltinFunc{bf}}
+	case tpInt:
+		sig = &builtinFieldIntSig{baseIntBuiltinFunc{bf}}
+	default:
+		sig = &builtinFieldRealSig{baseIntBuiltinFunc{bf}}
+	}
 	return sig.setSelf(sig), nil
 }
 
-type builtinFieldSig struct {
-	baseBuiltinFunc
+type builtinFieldIntSig struct {
+	baseIntBuiltinFunc
 }
 
-// eval evals a builtinFieldSig.
+// evalInt evals FIELD(str,str1,str2,str3,...) whose arguments are all integers.
 // See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_field
-// Returns the index (position) of arg0 in the arg1, arg2, arg3, ... list.
-// Returns 0 if arg0 is not found.
-// If arg0 is NULL, the return value is 0 because NULL fails equality comparison with any value.
-// If all arguments are strings, all arguments are compared as strings.
-// If all arguments are numbers, they are compared as numbers.
-// Otherwise, the arguments are compared as double.
-func (b *builtinFieldSig) eval(row []types.Datum) (d types.Datum, err error) {
-	args, err := b.evalArgs(row)
-	if err != nil {
-		return types.Datum{}, errors.Trace(err)
-	}
-	d.SetInt64(0)
-	if args[0].IsNull() {
-		return
+// It returns the position of the first argument in the other ones, or 0 if it's not found or it's NULL,
+// because NULL fails equality comparison with any value.
+func (b *builtinFieldIntSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	val, isNull, err := b.args[0].EvalInt(row, sc)
+	if isNull || err != nil {
+		return 0, err != nil, errors.Trace(err)
 	}
-	var (
-		pos                  int64
-		allString, allNumber bool
-		newArgs              []types.Datum
-	)
-	allString, allNumber = true, true
-	for i := 0; i < len(args) && (allString || allNumber); i++ {
-		switch args[i].Kind() {
-		case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
-			allString = false
-		case types.KindString, types.KindBytes:
-			allNumber = false
-		default:
-			allString, allNumber = false, false
+	unsigned := mysql.HasUnsignedFlag(b.args[0].GetType().Flag)
+	for i := 1; i < len(b.args); i++ {
+		cur, isNull, err := b.args[i].EvalInt(row, sc)
+		if err != nil {
+			return 0, true, errors.Trace(err)
+		}
+		// The integers with different signedness are different if either of them is out of the range of int64.
+		if !isNull && val == cur && (val >= 0 || unsigned == mysql.HasUnsignedFlag(b.args[i].GetType().Flag)) {
+			return int64(i), false, nil
 		}
 	}
-	newArgs, err = argsToSpecifiedType(args, allString, allNumber, b.ctx)
-	if err != nil {
-		return d, errors.Trace(err)
+	return 0, false, nil
+}
+
+type builtinFieldRealSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals FIELD(str,str1,str2,str3,...) whose arguments are compared as doubles.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_field
+func (b *builtinFieldRealSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	val, isNull, err := b.args[0].EvalReal(row, sc)
+	if isNull || err != nil {
+		return 0, err != nil, errors.Trace(err)
 	}
-	arg0, sc := newArgs[0], b.ctx.GetSessionVars().StmtCtx
-	for i, curArg := range newArgs[1:] {
-		cmpResult, _ := arg0.CompareDatum(sc, curArg)
-		if cmpResult == 0 {
-			pos = int64(i + 1)
-			break
+	for i := 1; i < len(b.args); i++ {
+		cur, isNull, err := b.args[i].EvalReal(row, sc)
+		if err != nil {
+			return 0, true, errors.Trace(err)
+		}
+		if !isNull && val == cur {
+			return int64(i), false, nil
 		}
 	}
-	d.SetInt64(pos)
-	return d, errors.Trace(err)
+	return 0, false, nil
 }
 
-// argsToSpecifiedType converts the type of all arguments in args into string type or double type.
-func argsToSpecifiedType(args []types.Datum, allString bool, allNumber bool, ctx context.Context) (newArgs []types.Datum, err error) {
-	if allNumber { // If all arguments are numbers, they can be compared directly without type converting.
-		return args, nil
+type builtinFieldStringSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals FIELD(str,str1,str2,str3,...) whose arguments are all strings.
+// See https://dev.mysql.com/doc/refman/5.7/en/string-functions.html#function_field
+func (b *builtinFieldStringSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	val, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, err != nil, errors.Trace(err)
 	}
-	sc := ctx.GetSessionVars().StmtCtx
-	newArgs = make([]types.Datum, len(args))
-	for i, arg := range args {
-		if allString {
-			str, err := arg.ToString()
-			if err != nil {
-				return newArgs, errors.Trace(err)
-			}
-			newArgs[i] = types.NewStringDatum(str)
-		} else {
-			// If error occurred when convert arg to float64, ignore it and set f as 0.
-			f, _ := arg.ToFloat64(sc)
-			newArgs[i] = types.NewFloat64Datum(f)
+	for i := 1; i < len(b.args); i++ {
+		cur, isNull, err := b.args[i].EvalString(row, sc)
+		if err != nil {
+			return 0, true, errors.Trace(err)
+		}
+		if !isNull && val == cur {
+			return int64(i), false, nil
 		}
 	}
-	return
+	return 0, false, nil
 }
 
 type makeSetFunctionClass struct {
@@ -2756,14 +2847,18 @@ func (c *instrFunctionClass) getFunction(args []Expression, ctx context.Context)
 		sig := &builtinInstrBinarySig{baseIntBuiltinFunc{bf}}
 		return sig.setSelf(sig), nil
 	}
-	sig := &builtinInstrSig{baseIntBuiltinFunc{bf}}
+	caseSensitive := isCaseSensitive(bf.args[0].GetType()) || isCaseSensitive(bf.args[1].GetType())
+	sig := &builtinInstrSig{baseIntBuiltinFunc{bf}, caseSensitive}
 	return sig.setSelf(sig), nil
 }
 
-type builtinInstrSig struct{ baseIntBuiltinFunc }
+type builtinInstrSig struct {
+	baseIntBuiltinFunc
+	caseSensitive bool
+}
 type builtinInstrBinarySig struct{ baseIntBuiltinFunc }
 
-// evalInt evals INSTR(str,substr), case insensitive
+// evalInt evals INSTR(str,substr), case-sensitive only if the collation of any argument is.
 // See https://dev.mysql.com/doc/refman/5.6/en/string-functions.html#function_instr
 func (b *builtinInstrSig) evalInt(row []types.Datum) (int64, bool, error) {
 	sc := b.ctx.GetSessionVars().StmtCtx
@@ -2772,13 +2867,13 @@ func (b *builtinInstrSig) evalInt(row []types.Datum) (int64, bool, error) {
 	if IsNull || err != nil {
 		return 0, true, errors.Trace(err)
 	}
-	str = strings.ToLower(str)
+	str = toLowerIfCaseInsensitive(str, b.caseSensitive)
 
 	substr, IsNull, err := b.args[1].EvalString(row, sc)
 	if IsNull || err != nil {
 		return 0, true, errors.Trace(err)
 	}
-	substr = strings.ToLower(substr)
+	substr = toLowerIfCaseInsensitive(substr, b.caseSensitive)
 
 	idx := strings.Index(str, substr)
 	if idx == -1 {
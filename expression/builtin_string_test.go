@@ -15,6 +15,7 @@ package expression
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +24,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
@@ -366,12 +368,24 @@ func (s *testEvaluatorSuite) TestRepeat(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v.GetString(), Equals, "aa")
 
-	args = []interface{}{"a", uint64(16777217)}
+	// The result longer than max_allowed_packet is NULL with a warning.
+	sc := s.ctx.GetSessionVars().StmtCtx
+	warnCnt := len(sc.GetWarnings())
+	args = []interface{}{"a", uint64(67108865)}
 	f, err = fc.getFunction(datumsToConstants(types.MakeDatums(args...)), s.ctx)
 	c.Assert(err, IsNil)
 	v, err = f.eval(nil)
 	c.Assert(err, IsNil)
 	c.Assert(v.IsNull(), IsTrue)
+	c.Assert(sc.GetWarnings(), HasLen, warnCnt+1)
+	c.Assert(terror.ErrorEqual(sc.GetWarnings()[warnCnt], errWarnAllowedPacketOverflowed), IsTrue)
+
+	args = []interface{}{"a", uint64(67108864)}
+	f, err = fc.getFunction(datumsToConstants(types.MakeDatums(args...)), s.ctx)
+	c.Assert(err, IsNil)
+	v, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(len(v.GetString()), Equals, 67108864)
 
 	args = []interface{}{"a", uint64(16777216)}
 	f, err = fc.getFunction(datumsToConstants(types.MakeDatums(args...)), s.ctx)
@@ -688,6 +702,11 @@ func (s *testEvaluatorSuite) TestSubstringIndex(c *C) {
 		{[]interface{}{"www.pingcap.com", ".", 0}, false, false, ""},
 		{[]interface{}{"www.pingcap.com", ".", 100}, false, false, "www.pingcap.com"},
 		{[]interface{}{"www.pingcap.com", ".", -100}, false, false, "www.pingcap.com"},
+		{[]interface{}{"www.pingcap.com", ".", -1}, false, false, "com"},
+		{[]interface{}{"www.pingcap.com", ".", -3}, false, false, "www.pingcap.com"},
+		{[]interface{}{"www.pingcap.com", ".", int64(math.MinInt64)}, false, false, "www.pingcap.com"},
+		{[]interface{}{"www.pingcap.com", ".", uint64(math.MaxUint64)}, false, false, "www.pingcap.com"},
+		{[]interface{}{"a||b||c", "||", -2}, false, false, "b||c"},
 		{[]interface{}{"www.pingcap.com", "d", 0}, false, false, ""},
 		{[]interface{}{"www.pingcap.com", "d", 1}, false, false, "www.pingcap.com"},
 		{[]interface{}{"www.pingcap.com", "d", -1}, false, false, "www.pingcap.com"},
@@ -1208,10 +1227,22 @@ func (s *testEvaluatorSuite) TestField(c *C) {
 		{[]interface{}{"1.1a", 2.1, 3.1, 11.1, 1.1}, int64(4)},
 		{[]interface{}{1.10, 0, 11e-1}, int64(2)},
 		{[]interface{}{"abc", 0, 1, 11.1, 1.1}, int64(1)},
-	}
+		{[]interface{}{"x", "a", "b", "c"}, int64(0)},
+		{[]interface{}{"b", nil, "b", "b"}, int64(2)},
+		{[]interface{}{"a", "A", "a"}, int64(2)},
+		{[]interface{}{5, 1, 2, 3}, int64(0)},
+		{[]interface{}{-1, uint64(math.MaxUint64), -1}, int64(2)},
+		{[]interface{}{nil, nil, 1}, int64(0)},
+	}
+	sc := s.ctx.GetSessionVars().StmtCtx
+	originIgnoreTruncate := sc.IgnoreTruncate
+	sc.IgnoreTruncate = true
+	defer func() {
+		sc.IgnoreTruncate = originIgnoreTruncate
+	}()
 	for _, t := range tbl {
 		fc := funcs[ast.Field]
-		f, err := fc.getFunction(datumsToConstants(types.MakeDatums(t.argLst...)), s.ctx)
+		f, err := fc.getFunction(primitiveValsToConstants(t.argLst), s.ctx)
 		c.Assert(err, IsNil)
 		r, err := f.eval(nil)
 		c.Assert(err, IsNil)
@@ -1638,6 +1669,9 @@ func (s *testEvaluatorSuite) TestElt(c *C) {
 		{[]interface{}{0, 2, 3, 11, 1}, nil},
 		{[]interface{}{3, 2, 3, 11, 1}, "11"},
 		{[]interface{}{1.1, "2.1", "3.1", "11.1", "1.1"}, "2.1"},
+		{[]interface{}{nil, "Hej", "ej"}, nil},
+		{[]interface{}{2, "Hej", nil}, nil},
+		{[]interface{}{1, "Hej", nil}, "Hej"},
 	}
 	for _, t := range tbl {
 		fc := funcs[ast.Elt]
@@ -2142,52 +2142,64 @@ func (b *builtinConvertTzSig) eval(row []types.Datum) (d types.Datum, err error)
 
 	dt := arg0.GetMysqlTime()
 
-	fromTZ := args[1].GetString()
-	toTZ := args[2].GetString()
-
-	const tzArgReg = `(^(\+|-)(0?[0-9]|1[0-2]):[0-5]?\d$)|(^\+13:00$)`
-	r, _ := regexp.Compile(tzArgReg)
-	fmatch := r.MatchString(fromTZ)
-	tmatch := r.MatchString(toTZ)
-
-	if !fmatch && !tmatch {
-		ftz, err := time.LoadLocation(fromTZ)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
+	fromTZ, err := args[1].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	toTZ, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	// An invalid time zone makes the result NULL.
+	fromLoc, toLoc := convertTzLocation(fromTZ), convertTzLocation(toTZ)
+	if fromLoc == nil || toLoc == nil {
+		return
+	}
 
-		ttz, err := time.LoadLocation(toTZ)
-		if err != nil {
+	t, err := dt.Time.GoTime(fromLoc)
+	if err != nil {
+		wall, err1 := dt.Time.GoTime(time.UTC)
+		if err1 != nil {
 			return d, errors.Trace(err)
 		}
-
-		t, err := dt.Time.GoTime(ftz)
-		if err != nil {
-			return d, errors.Trace(err)
+		// The time is valid but skipped by a daylight saving time transition in the time zone,
+		// like MySQL, it's converted as the time when the transition happens.
+		start, end := t.ZoneBounds()
+		if time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).Before(wall) {
+			t = end
+		} else {
+			t = start
 		}
-
-		d.SetMysqlTime(types.Time{
-			Time: types.FromGoTime(t.In(ttz)),
-			Type: mysql.TypeDatetime,
-			Fsp:  dt.Fsp,
-		})
-		return d, nil
 	}
 
-	if fmatch && tmatch {
-		t, err := dt.Time.GoTime(time.Local)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
+	d.SetMysqlTime(types.Time{
+		Time: types.FromGoTime(t.In(toLoc)),
+		Type: mysql.TypeDatetime,
+		Fsp:  dt.Fsp,
+	})
+	return d, nil
+}
 
-		d.SetMysqlTime(types.Time{
-			Time: types.FromGoTime(t.Add(timeZone2Duration(toTZ) - timeZone2Duration(fromTZ))),
-			Type: mysql.TypeDatetime,
-			Fsp:  dt.Fsp,
-		})
-	}
+// tzOffsetPattern matches the time zone offsets from -12:59 to +13:00.
+var tzOffsetPattern = regexp.MustCompile(`(^(\+|-)(0?[0-9]|1[0-2]):[0-5]?\d$)|(^\+13:00$)`)
 
-	return
+// convertTzLocation returns the location of a time zone, which is either an offset like "+08:00" or a
+// named time zone like "Europe/London" loaded from the zone data. nil is returned for an invalid time zone.
+func convertTzLocation(tz string) *time.Location {
+	if tzOffsetPattern.MatchString(tz) {
+		return time.FixedZone(tz, int(timeZone2Duration(tz)/time.Second))
+	}
+	if strings.EqualFold(tz, "SYSTEM") {
+		return time.Local
+	}
+	if tz == "" || tz == "Local" {
+		return nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil
+	}
+	return loc
 }
 
 type makeDateFunctionClass struct {
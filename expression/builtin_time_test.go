@@ -1741,11 +1741,28 @@ func (s *testEvaluatorSuite) TestConvertTz(c *C) {
 		{"2004-01-01 12:00:00", "-00:00", "+13:00", true, "2004-01-02 01:00:00"},
 		{"2004-01-01 12:00:00", "-00:00", "-13:00", true, ""},
 		{"2004-01-01 12:00:00", "-00:00", "-12:88", true, ""},
-		{"2004-01-01 12:00:00", "+10:82", "GMT", false, ""},
-		{"2004-01-01 12:00:00", "+00:00", "GMT", true, ""},
-		{"2004-01-01 12:00:00", "GMT", "+00:00", true, ""},
+		{"2004-01-01 12:00:00", "+10:82", "GMT", true, ""},
+		{"2004-01-01 12:00:00", "+00:00", "GMT", true, "2004-01-01 12:00:00"},
+		{"2004-01-01 12:00:00", "GMT", "+00:00", true, "2004-01-01 12:00:00"},
 		{20040101, "+00:00", "+10:32", true, "2004-01-01 10:32:00"},
 		{3.14159, "+00:00", "+10:32", false, ""},
+		// Offsets and named time zones can be mixed.
+		{"2017-06-01 12:00:00", "+08:00", "Europe/London", true, "2017-06-01 05:00:00"},
+		{"2017-06-01 12:00:00", "Asia/Shanghai", "-05:30", true, "2017-05-31 22:30:00"},
+		// Invalid time zones result in NULL.
+		{"2017-06-01 12:00:00", "Foo/Bar", "+00:00", true, ""},
+		{"2017-06-01 12:00:00", "+00:00", "", true, ""},
+		// Daylight saving time starts at 2017-03-12 02:00:00 in America/New_York.
+		{"2017-03-12 01:59:59", "America/New_York", "UTC", true, "2017-03-12 06:59:59"},
+		{"2017-03-12 02:30:00", "America/New_York", "UTC", true, "2017-03-12 07:00:00"},
+		{"2017-03-12 03:00:00", "America/New_York", "UTC", true, "2017-03-12 07:00:00"},
+		{"2017-03-12 06:59:59", "UTC", "America/New_York", true, "2017-03-12 01:59:59"},
+		{"2017-03-12 07:00:00", "UTC", "America/New_York", true, "2017-03-12 03:00:00"},
+		// Daylight saving time ends at 2017-11-05 02:00:00 in America/New_York, 01:00:00 to 01:59:59 happens twice.
+		{"2017-11-05 05:30:00", "UTC", "America/New_York", true, "2017-11-05 01:30:00"},
+		{"2017-11-05 06:30:00", "UTC", "America/New_York", true, "2017-11-05 01:30:00"},
+		{"2017-11-05 07:30:00", "UTC", "America/New_York", true, "2017-11-05 02:30:00"},
+		{"2017-11-05 02:30:00", "America/New_York", "+00:00", true, "2017-11-05 07:30:00"},
 	}
 	fc := funcs[ast.ConvertTz]
 	for _, test := range tests {
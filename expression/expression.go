@@ -37,23 +37,27 @@ var (
 	errZlibZData               = terror.ClassTypes.New(codeZlibZData, "ZLIB: Input data corrupted")
 	errIncorrectArgs           = terror.ClassExpression.New(codeIncorrectArgs, mysql.MySQLErrName[mysql.ErrWrongArguments])
 	ErrIncorrectParameterCount = terror.ClassExpression.New(codeIncorrectParameterCount, "Incorrect parameter count in the call to native function '%s'")
+
+	errWarnAllowedPacketOverflowed = terror.ClassExpression.New(codeWarnAllowedPacketOverflowed, "Result of %s() was larger than max_allowed_packet (%d) - truncated")
 )
 
 // Error codes.
 const (
-	codeInvalidOperation        terror.ErrCode = 1
-	codeIncorrectParameterCount                = 1582
-	codeFunctionNotExists                      = 1305
-	codeZlibZData                              = mysql.ErrZlibZData
-	codeIncorrectArgs                          = mysql.ErrWrongArguments
+	codeInvalidOperation            terror.ErrCode = 1
+	codeIncorrectParameterCount                    = 1582
+	codeFunctionNotExists                          = 1305
+	codeZlibZData                                  = mysql.ErrZlibZData
+	codeIncorrectArgs                              = mysql.ErrWrongArguments
+	codeWarnAllowedPacketOverflowed                = mysql.ErrWarnAllowedPacketOverflowed
 )
 
 func init() {
 	expressionMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeIncorrectParameterCount: mysql.ErrWrongParamcountToNativeFct,
-		codeFunctionNotExists:       mysql.ErrSpDoesNotExist,
-		codeZlibZData:               mysql.ErrZlibZData,
-		codeIncorrectArgs:           mysql.ErrWrongArguments,
+		codeIncorrectParameterCount:     mysql.ErrWrongParamcountToNativeFct,
+		codeFunctionNotExists:           mysql.ErrSpDoesNotExist,
+		codeZlibZData:                   mysql.ErrZlibZData,
+		codeIncorrectArgs:               mysql.ErrWrongArguments,
+		codeWarnAllowedPacketOverflowed: mysql.ErrWarnAllowedPacketOverflowed,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
@@ -83,8 +83,19 @@ func (s *testIntegrationSuite) TestFuncREPEAT(c *C) {
 	r = tk.MustQuery("SELECT REPEAT(a, 0), REPEAT(b, 0), REPEAT(c, 0), REPEAT(d, 0), REPEAT(e, 0), REPEAT(f, 0) FROM table_string;")
 	r.Check(testkit.Rows("     "))
 
-	r = tk.MustQuery("SELECT REPEAT(a, 16777217), REPEAT(b, 16777217), REPEAT(c, 16777217), REPEAT(d, 16777217), REPEAT(e, 16777217), REPEAT(f, 16777217) FROM table_string;")
+	r = tk.MustQuery("SELECT REPEAT(a, 67108865), REPEAT(b, 67108865), REPEAT(c, 67108865), REPEAT(d, 67108865), REPEAT(e, 67108865), REPEAT(f, 67108865) FROM table_string;")
 	r.Check(testkit.Rows("<nil> <nil> <nil> <nil> <nil> <nil>"))
+
+	r = tk.MustQuery("SELECT LENGTH(REPEAT(a, 16777217)), LENGTH(REPEAT(CONCAT(a, b), 33554432)) FROM table_string;")
+	r.Check(testkit.Rows("16777217 67108864"))
+
+	// The result longer than max_allowed_packet of the session is NULL with a warning.
+	tk.MustExec("SET GLOBAL max_allowed_packet = 1024")
+	defer tk.MustExec("SET GLOBAL max_allowed_packet = 67108864")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("USE test")
+	tk1.MustQuery("SELECT REPEAT(a, 1025), LENGTH(REPEAT(a, 1024)) FROM table_string").Check(testkit.Rows("<nil> 1024"))
+	tk1.MustQuery("SHOW WARNINGS").Check(testkit.Rows("Warning 1301 Result of repeat() was larger than max_allowed_packet (1024) - truncated"))
 }
 
 func (s *testIntegrationSuite) TestFuncLpadAndRpad(c *C) {
@@ -543,6 +554,12 @@ func (s *testIntegrationSuite) TestStringBuiltin(c *C) {
 	result.Check(testkit.Rows("<nil>"))
 	result = tk.MustQuery("select concat_ws(',', 'a', 'b')")
 	result.Check(testkit.Rows("a,b"))
+	// The NULL arguments after the separator are skipped, the empty strings are not.
+	tk.MustExec("insert into t(a, e) values(2, '')")
+	result = tk.MustQuery("select concat_ws('|', a, b, null, e, c) from t order by a")
+	result.Check(testkit.Rows("1|1.1|abcdef|2017-01-01 12:01:01", "2|"))
+	result = tk.MustQuery("select concat_ws(',', null, null), concat_ws(',', b), concat_ws(e, 'x', 'y') from t order by a")
+	result.Check(testkit.Rows(" 1.1 xabcdefy", "  xy"))
 	result = tk.MustQuery("select concat_ws(',','First name',NULL,'Last Name')")
 	result.Check(testkit.Rows("First name,Last Name"))
 
@@ -690,6 +707,17 @@ func (s *testIntegrationSuite) TestStringBuiltin(c *C) {
 	result.Check(testutil.RowsWithSep(",", "www.pingcap.com,,"))
 	result = tk.MustQuery(`select substring_index(null, '.', 1), substring_index('www.pingcap.com', null, 1), substring_index('www.pingcap.com', '.', null)`)
 	result.Check(testkit.Rows("<nil> <nil> <nil>"))
+	result = tk.MustQuery(`select substring_index('www.pingcap.com', '.', -1), substring_index('www.pingcap.com', '.', -2), substring_index('www.pingcap.com', '.', 18446744073709551615), substring_index('www.pingcap.com', '.', -9223372036854775808)`)
+	result.Check(testkit.Rows("com pingcap.com www.pingcap.com www.pingcap.com"))
+
+	// for field
+	result = tk.MustQuery(`select field('ej', 'Hej', 'ej', 'Heja', 'hej', 'foo'), field('fo', 'Hej', 'ej'), field(null, 'a', null), field('b', null, 'b'), field(3, 1, 2, 3), field(1.1, '1.10', 2)`)
+	result.Check(testkit.Rows("2 0 0 2 3 1"))
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b char(10), c double)")
+	tk.MustExec("insert into t values(2, 'b', 1.5), (4, 'd', null)")
+	result = tk.MustQuery(`select field(a, 1, 2, 3), field(b, 'a', 'b'), field(c, 1, 1.5) from t`)
+	result.Check(testkit.Rows("2 2 2", "0 0 0"))
 
 	// for hex
 	tk.MustExec("drop table if exists t")
@@ -754,6 +782,22 @@ func (s *testIntegrationSuite) TestStringBuiltin(c *C) {
 	result.Check(testkit.Rows("0"))
 	result = tk.MustQuery(`select locate("文", "中文字符串")`)
 	result.Check(testkit.Rows("2"))
+	result = tk.MustQuery(`select locate("字符", "中文字符串", 1), locate("字符", "中文字符串", 4), locate("", "中文", 3), locate("", "中文", 4)`)
+	result.Check(testkit.Rows("3 0 3 0"))
+	result = tk.MustQuery(`select locate(null, "abc"), locate("a", null), locate("a", "abc", null), position(null in "abc")`)
+	result.Check(testkit.Rows("<nil> <nil> <nil> <nil>"))
+	result = tk.MustQuery(`select position("文字" in "中文字符串"), position("ABC" in "xabc"), position("d" in "abc")`)
+	result.Check(testkit.Rows("2 2 0"))
+	// The case sensitivity is derived from the collations of the arguments.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a varchar(20) charset latin1 collate latin1_general_cs, b varchar(20), c varbinary(20))")
+	tk.MustExec(`insert into t values("FooBar", "FooBar", "FooBar")`)
+	result = tk.MustQuery(`select locate("bar", a), locate("Bar", a), locate("bar", a, 2), instr(a, "bar"), position("Bar" in a) from t`)
+	result.Check(testkit.Rows("0 4 0 0 4"))
+	result = tk.MustQuery(`select locate("bar", b), locate("bar", b, 2), instr(b, "bar"), position("bar" in b) from t`)
+	result.Check(testkit.Rows("4 4 4 4"))
+	result = tk.MustQuery(`select locate("bar", c), locate("Bar", c, 2), instr(c, "bar"), position("bar" in c) from t`)
+	result.Check(testkit.Rows("0 4 0 0"))
 
 	// for bin
 	result = tk.MustQuery(`select bin(-1);`)
@@ -776,6 +820,8 @@ func (s *testIntegrationSuite) TestStringBuiltin(c *C) {
 	result = tk.MustQuery(`select elt(0, "abc", "def"), elt(2, "hello", "中文", "tidb"), elt(4, "hello", "中文",
 	"tidb");`)
 	result.Check(testkit.Rows("<nil> 中文 <nil>"))
+	result = tk.MustQuery(`select elt(null, "abc"), elt(-1, "abc"), elt(2, "abc", null), elt(1.6, "abc", "def")`)
+	result.Check(testkit.Rows("<nil> <nil> <nil> def"))
 
 	// for instr
 	result = tk.MustQuery(`select instr("中国", "国"), instr("中国", ""), instr("abc", ""), instr("", ""), instr("", "abc");`)
@@ -1037,6 +1083,12 @@ func (s *testIntegrationSuite) TestTimeBuiltin(c *C) {
 	result.Check(testkit.Rows("<nil>"))
 	result = tk.MustQuery("SELECT TIME_FORMAT(123, '%H:%i:%s %p');")
 	result.Check(testkit.Rows("00:01:23 AM"))
+
+	// for convert_tz
+	result = tk.MustQuery("SELECT CONVERT_TZ('2017-03-12 01:30:00', 'America/New_York', '+00:00'), CONVERT_TZ('2017-03-12 03:30:00', 'America/New_York', '+00:00');")
+	result.Check(testkit.Rows("2017-03-12 06:30:00 2017-03-12 07:30:00"))
+	result = tk.MustQuery("SELECT CONVERT_TZ('2017-03-12 12:00:00', '+08:00', 'Unknown/Zone'), CONVERT_TZ('2017-03-12 12:00:00', '+08:00', '+14:30');")
+	result.Check(testkit.Rows("<nil> <nil>"))
 }
 
 func (s *testIntegrationSuite) TestOpBuiltin(c *C) {
@@ -1512,9 +1564,37 @@ func (s *testIntegrationSuite) TestControlBuiltin(c *C) {
 	result.Check(testkit.Rows("0 abc 12:00:00", "00:00:00 1 1abc", "0 0abc 12:59:59"))
 	result = tk.MustQuery("select if(1, 1.0, 1)")
 	result.Check(testkit.Rows("1.0"))
-	// FIXME: MySQL returns `1.0`.
 	result = tk.MustQuery("select if(1, 1, 1.0)")
-	result.Check(testkit.Rows("1"))
+	result.Check(testkit.Rows("1.0"))
+	// The condition is true if it's non-zero, strings are converted to numbers.
+	result = tk.MustQuery("select if(0.4, 'a', 'b'), if('0.4', 'a', 'b'), if('0', 'a', 'b'), if(null, 'a', 'b')")
+	result.Check(testkit.Rows("a a b b"))
+	// The results are converted to the result type, whichever branch is returned.
+	result = tk.MustQuery("select if(0, 1.5, 2.25), if(0, cast('2017-01-02' as date), cast('2017-01-03 04:05:06' as datetime)), if(1, cast('2017-01-02' as date), cast('2017-01-03 04:05:06' as datetime))")
+	result.Check(testkit.Rows("2.25 2017-01-03 04:05:06 2017-01-02 00:00:00"))
+	result = tk.MustQuery("select if(1, 1.5, 2.25), if(1, 1, 'a'), if(0, 1, 'a'), if(1, 2, cast('2017-01-02' as date))")
+	result.Check(testkit.Rows("1.50 1 a 2"))
+	// NULL branches don't affect the result type.
+	result = tk.MustQuery("select if(1, null, 1.5), if(0, null, 1.5), if(1, null, null), if(0, null, cast('2017-01-02' as date))")
+	result.Check(testkit.Rows("<nil> 1.5 <nil> 2017-01-02"))
+	// Only the returned branch is evaluated, an invalid JSON text is an error.
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (a int, b varchar(10))")
+	tk.MustExec(`insert into t1 values (1, '{}'), (2, 'a')`)
+	result = tk.MustQuery("select if(a = 1, json_type(b), 'b'), if(a = 2, 'b', json_type(b)) from t1 where a = 1")
+	result.Check(testkit.Rows("OBJECT OBJECT"))
+	result = tk.MustQuery("select if(a = 2, 'b', json_type(b)), if(a = 1, json_type(b), 'b') from t1 where a = 2")
+	result.Check(testkit.Rows("b b"))
+
+	// for case
+	result = tk.MustQuery("select case when 0.4 then 'a' else 'b' end, case when '0.4' then 'a' end, case when 0 then 'a' end, case when null then 'a' else 'b' end")
+	result.Check(testkit.Rows("a a <nil> b"))
+	result = tk.MustQuery("select case 1 when 2 then 1.5 when 1 then 2.125 else 3 end, case 2 when 1 then 1.5 else 3 end, case 'a' when 'b' then 1 else 'c' end")
+	result.Check(testkit.Rows("2.125 3.0 c"))
+	result = tk.MustQuery("select case when 1 then cast('2017-01-02' as date) else cast('2017-01-03 04:05:06' as datetime) end, case when 0 then cast('2017-01-02' as date) end, case when 0 then cast('04:05:06' as time) else cast('05:06:07' as time) end")
+	result.Check(testkit.Rows("2017-01-02 00:00:00 <nil> 05:06:07"))
+	result = tk.MustQuery("select case when 1 then null else 1.5 end, case when 0 then null else 1.5 end, case when 1 then null end, case 1 when 1 then null else null end")
+	result.Check(testkit.Rows("<nil> 1.5 <nil> <nil>"))
 
 	result = tk.MustQuery("SELECT 79 + + + CASE -87 WHEN -30 THEN COALESCE(COUNT(*), +COALESCE(+15, -33, -12 ) + +72) WHEN +COALESCE(+AVG(DISTINCT(60)), 21) THEN NULL ELSE NULL END AS col0;")
 	result.Check(testkit.Rows("<nil>"))
@@ -1753,3 +1833,201 @@ func (s *testIntegrationSuite) TestOtherBuiltin(c *C) {
 	result = tk.MustQuery("select (0,1) in ((0,1), (0,2)), (0,1) in ((0,0), (0,2))")
 	result.Check(testkit.Rows("1 0"))
 }
+
+func (s *testIntegrationSuite) TestArithmeticOverflow(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b bigint, c bigint unsigned)")
+	tk.MustExec("insert into t values (2147483647, 9223372036854775807, 18446744073709551615)")
+
+	// Overflow is an error in strict mode.
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	for _, sql := range []string{
+		"select b + 1 from t",
+		"select -b - 2 from t",
+		"select b * 2 from t",
+		"select c + 1 from t",
+		"select c * 2 from t",
+		"select a - c from t",
+		"select -(-b - 1) from t",
+		"select (-b - 1) div -1 from t",
+		"select a * b from t",
+	} {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil, Commentf("sql: %s", sql))
+		_, err = tidb.GetRows(rs)
+		c.Assert(terror.ErrorEqual(err, types.ErrOverflow), IsTrue, Commentf("sql: %s, err: %v", sql, err))
+	}
+	_, err := tk.Exec("update t set b = b + 1")
+	c.Assert(terror.ErrorEqual(err, types.ErrOverflow), IsTrue, Commentf("err: %v", err))
+	_, err = tk.Exec("update t set a = a * 2")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select a, b from t").Check(testkit.Rows("2147483647 9223372036854775807"))
+
+	// Results that do not overflow are correct in both modes.
+	tk.MustQuery("select -1 - (-b - 1), b - 9223372036854775807, c + (-1), c * 1, c - 18446744073709551615, a + a from t").
+		Check(testkit.Rows("9223372036854775807 0 18446744073709551614 18446744073709551615 0 4294967294"))
+
+	// Overflow is a warning and the result is clipped to the bound of its type in non-strict mode.
+	tk.MustExec("set sql_mode = ''")
+	tk.MustQuery("select b + 1, -b - 2, b * -2, c + 1, a - c, (-b - 1) div -1, -(-b - 1) from t").Check(testkit.Rows(
+		"9223372036854775807 -9223372036854775808 -9223372036854775808 18446744073709551615 0 9223372036854775807 9223372036854775807"))
+	tk.MustQuery("show warnings").Check(testkit.Rows(
+		"Warning 1690 BIGINT value is out of range in '(test.t.b + 1)'",
+		"Warning 1690 BIGINT value is out of range in '(unaryminus(test.t.b) - 2)'",
+		"Warning 1690 BIGINT value is out of range in '(test.t.b * -2)'",
+		"Warning 1690 BIGINT UNSIGNED value is out of range in '(test.t.c + 1)'",
+		"Warning 1690 BIGINT UNSIGNED value is out of range in '(test.t.a - test.t.c)'",
+		"Warning 1690 BIGINT value is out of range in '(-9223372036854775808, -1)'",
+		"Warning 1690 BIGINT value is out of range in '--9223372036854775808'",
+	))
+	tk.MustExec("create table t1 (b bigint not null, c bigint unsigned not null, d decimal(65, 0) not null)")
+	tk.MustExec("insert into t1 values (9223372036854775807, 0, 99999999999999999999999999999999999999999999999999999999999999999)")
+	tk.MustExec("update t1 set b = b + 1, c = c - 1, d = d + 1")
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(3))
+	tk.MustQuery("select b, c, d from t1").Check(testkit.Rows(
+		"9223372036854775807 0 99999999999999999999999999999999999999999999999999999999999999999"))
+	tk.MustExec("update t1 set b = -b - 2, c = c + 18446744073709551615 + 1")
+	tk.MustQuery("select b, c from t1").Check(testkit.Rows("-9223372036854775808 18446744073709551615"))
+	tk.MustExec("update t set b = b + 1")
+	tk.MustQuery("select a, b from t").Check(testkit.Rows("2147483647 9223372036854775807"))
+	tk.MustExec("update t set a = a * 2")
+	tk.MustQuery("select a from t").Check(testkit.Rows("2147483647"))
+}
+
+func (s *testIntegrationSuite) TestDecimalArithmetic(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a decimal(10, 2), b decimal(10, 2), c decimal(65, 0), d decimal(65, 30))")
+	tk.MustExec("insert into t values (12345678.91, 0.03, 99999999999999999999999999999999999999999999999999999999999999999, 0.000000000000000000000000000001)")
+
+	// The scale of + - * follows the arguments, and division increases the scale by 4.
+	tk.MustQuery("select a + b, a - b, a * b, a / b, a / 3, b / 7 from t").
+		Check(testkit.Rows("12345678.94 12345678.88 370370.3673 411522630.333333 4115226.303333 0.004286"))
+	tk.MustQuery("select c * 0.01, d * d, d * 2 from t").
+		Check(testkit.Rows("999999999999999999999999999999999999999999999999999999999999999.99 0.000000000000000000000000000000 0.000000000000000000000000000002"))
+
+	// Overflow is an error in strict mode.
+	tk.MustExec("insert into t values (1, 1, 1, 1)")
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	for _, sql := range []string{
+		"select c + c from t",
+		"select c - (-c) from t",
+		"select c * b from t",
+	} {
+		rs, err := tk.Exec(sql)
+		c.Assert(err, IsNil, Commentf("sql: %s", sql))
+		_, err = tidb.GetRows(rs)
+		c.Assert(terror.ErrorEqual(err, types.ErrOverflow), IsTrue, Commentf("sql: %s, err: %v", sql, err))
+	}
+	tk.MustQuery("select sum(a), sum(b) from t").Check(testkit.Rows("12345679.91 1.03"))
+
+	// The sum exceeding the max precision is replaced with the max DECIMAL value.
+	maxDec := strings.Repeat("9", 65)
+	tk.MustQuery("select sum(c), sum(-c) from t").Check(testkit.Rows(maxDec + " -" + maxDec))
+	// Overflow is a warning and the result is clipped to the max DECIMAL value in non-strict mode.
+	// Overflow is a warning in non-strict mode.
+	tk.MustExec("set sql_mode = ''")
+	tk.MustQuery("select c + c from t where b = 1").Check(testkit.Rows("2"))
+	tk.MustQuery("select c + c from t where b < 1").Check(testkit.Rows(maxDec))
+	tk.MustQuery("show warnings").Check(testkit.Rows("Warning 1690 DECIMAL value is out of range in '(test.t.c + test.t.c)'"))
+}
+
+func (s *testIntegrationSuite) TestDivisionByZero(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b decimal(10, 2), c double)")
+	tk.MustExec("insert into t values (1, 1.5, 2.5)")
+
+	// Without ERROR_FOR_DIVISION_BY_ZERO, the division by zero is NULL silently.
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	tk.MustQuery("select 1 / 0, a / 0, b / 0, c / 0, a div 0, a % 0 from t").Check(testkit.Rows("<nil> <nil> <nil> <nil> <nil> <nil>"))
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+	tk.MustExec("insert into t values (1 / 0, 2 div 0, 3 % 0)")
+	tk.MustQuery("select * from t where a is null").Check(testkit.Rows("<nil> <nil> <nil>"))
+
+	// With ERROR_FOR_DIVISION_BY_ZERO, the division by zero is a warning in SELECT.
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES,ERROR_FOR_DIVISION_BY_ZERO'")
+	tk.MustQuery("select 1 / 0, a / 0, b / 0 from t where a = 1").Check(testkit.Rows("<nil> <nil> <nil>"))
+	tk.MustQuery("show warnings").Check(testkit.Rows(
+		"Warning 1365 Division by 0", "Warning 1365 Division by 0", "Warning 1365 Division by 0"))
+	tk.MustQuery("select c / 0, a div 0, a % 0 from t where a = 1").Check(testkit.Rows("<nil> <nil> <nil>"))
+	tk.MustQuery("show warnings").Check(testkit.Rows(
+		"Warning 1365 Division by 0", "Warning 1365 Division by 0", "Warning 1365 Division by 0"))
+
+	// It's an error in the statements changing data in strict mode, unless they are IGNORE.
+	for _, sql := range []string{
+		"insert into t values (1 / 0, 1, 1)",
+		"insert into t select a, b / 0, c from t where a = 1",
+		"update t set c = c / 0 where a = 1",
+		"update t set a = a % 0 where a = 1",
+	} {
+		_, err := tk.Exec(sql)
+		c.Assert(terror.ErrorEqual(err, types.ErrDivByZero), IsTrue, Commentf("sql: %s, err: %v", sql, err))
+	}
+	tk.MustQuery("select * from t where a = 1").Check(testkit.Rows("1 1.50 2.5"))
+	tk.MustExec("insert ignore into t values (2, 1 / 0, 1)")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Warning 1365 Division by 0"))
+	tk.MustExec("update ignore t set c = c / 0 where a = 2")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Warning 1365 Division by 0"))
+	tk.MustQuery("select * from t where a = 2").Check(testkit.Rows("2 <nil> <nil>"))
+
+	// It's a warning in non-strict mode.
+	tk.MustExec("set sql_mode = 'ERROR_FOR_DIVISION_BY_ZERO'")
+	tk.MustExec("update t set c = a / 0 where a = 1")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Warning 1365 Division by 0"))
+	tk.MustQuery("select * from t where a = 1").Check(testkit.Rows("1 1.50 <nil>"))
+}
+
+func (s *testIntegrationSuite) TestCoalesceAndIfNullType(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b decimal(10, 2), c varchar(20), d date, e datetime, f bigint unsigned)")
+	tk.MustExec("insert into t values (1, 12.34, 'abc', '2017-10-01', '2017-10-01 10:11:12', 18446744073709551615), (null, null, null, null, null, null)")
+
+	tk.MustQuery("select coalesce(a, b), coalesce(c, a), coalesce(d, e), coalesce(null, null), ifnull(a, f), ifnull(d, 'x') from t").Check(testkit.Rows(
+		"1.00 abc 2017-10-01 00:00:00 <nil> 1 2017-10-01",
+		"<nil> <nil> <nil> <nil> <nil> x",
+	))
+	tk.MustQuery("select coalesce(a, b) > 1.5, coalesce(null, b, a) from t where a is null or b > 12").Check(testkit.Rows("0 12.34", "<nil> <nil>"))
+
+	rs, err := tk.Exec("select coalesce(a, b), coalesce(c, a), coalesce(d, e), coalesce(null, null), ifnull(a, f), ifnull(b, 1.5) from t")
+	c.Assert(err, IsNil)
+	fields, err := rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(rs.Close(), IsNil)
+	expected := []struct {
+		tp      byte
+		flen    int
+		decimal int
+	}{
+		{mysql.TypeNewDecimal, 13, 2},
+		{mysql.TypeVarString, 20, -1},
+		{mysql.TypeDatetime, 19, 0},
+		{mysql.TypeNull, 0, -1},
+		{mysql.TypeNewDecimal, 21, 0},
+		{mysql.TypeNewDecimal, 10, 2},
+	}
+	for i, f := range fields {
+		tp := &f.Column.FieldType
+		c.Assert(tp.Tp, Equals, expected[i].tp, Commentf("column %d: %s", i, tp))
+		c.Assert(tp.Flen, Equals, expected[i].flen, Commentf("column %d: %s", i, tp))
+		c.Assert(tp.Decimal, Equals, expected[i].decimal, Commentf("column %d: %s", i, tp))
+	}
+}
@@ -42,7 +42,7 @@ type typeInferrer struct {
 
 func (v *typeInferrer) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 	switch in.(type) {
-	case *ast.ColumnOption:
+	case *ast.ColumnOption, *ast.Constraint:
 		return in, true
 	}
 	return in, false
@@ -264,7 +264,7 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		chs = charset.CharsetBin
 	)
 	switch x.FnName.L {
-	case ast.Abs, ast.Ifnull, ast.Nullif:
+	case ast.Abs, ast.Nullif:
 		if len(x.Args) == 0 {
 			tp = types.NewFieldType(mysql.TypeNull)
 			break
@@ -321,19 +321,12 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 			tp = types.NewFieldType(mysql.TypeVarString)
 			chs = v.defaultCharset
 		}
-	case ast.Coalesce:
+	case ast.Coalesce, ast.Ifnull:
 		fieldTps := make([]*types.FieldType, 0, len(x.Args))
 		for _, arg := range x.Args {
 			fieldTps = append(fieldTps, arg.GetType())
 		}
-		tp = types.AggFieldType(fieldTps)
-		if tp.Tp == mysql.TypeVarchar {
-			tp.Tp = mysql.TypeVarString
-		}
-		classType := types.AggTypeClass(fieldTps, &tp.Flag)
-		if classType == types.ClassString && !mysql.HasBinaryFlag(tp.Flag) {
-			tp.Charset, tp.Collate = types.DefaultCharsetForType(tp.Tp)
-		}
+		tp, _ = inferType4ControlFuncs(fieldTps)
 	// number related
 	case ast.Ln, ast.Log, ast.Log2, ast.Log10, ast.Sqrt, ast.PI, ast.Exp, ast.Degrees, ast.Sin, ast.Cos, ast.Tan,
 		ast.Cot, ast.Acos, ast.Asin, ast.Atan, ast.Pow, ast.Power, ast.Rand, ast.Radians:
@@ -400,14 +393,7 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 	case ast.RandomBytes:
 		tp = types.NewFieldType(mysql.TypeVarString)
 	case ast.If:
-		// TODO: fix this
-		// See https://dev.mysql.com/doc/refman/5.5/en/control-flow-functions.html#function_if
-		// The default return type of IF() (which may matter when it is stored into a temporary table) is calculated as follows.
-		// Expression	Return Value
-		// expr2 or expr3 returns a string	string
-		// expr2 or expr3 returns a floating-point value	floating-point
-		// expr2 or expr3 returns an integer	integer
-		tp = x.Args[1].GetType()
+		tp, _ = inferType4ControlFuncs([]*types.FieldType{x.Args[1].GetType(), x.Args[2].GetType()})
 	case ast.Compress:
 		tp = types.NewFieldType(mysql.TypeBlob)
 	case ast.Uncompress:
@@ -456,16 +442,7 @@ func (v *typeInferrer) handleCaseExpr(x *ast.CaseExpr) {
 	for _, expr := range exprs {
 		fieldTps = append(fieldTps, expr.GetType())
 	}
-	tp := types.AggFieldType(fieldTps)
-	if tp.Tp == mysql.TypeVarchar {
-		tp.Tp = mysql.TypeVarString
-	}
-	classType := types.AggTypeClass(fieldTps, &tp.Flag)
-	if classType == types.ClassString && !mysql.HasBinaryFlag(tp.Flag) {
-		tp.Charset, tp.Collate = types.DefaultCharsetForType(tp.Tp)
-	} else {
-		types.SetBinChsClnFlag(tp)
-	}
+	tp, _ := inferType4ControlFuncs(fieldTps)
 	x.SetType(tp)
 }
 
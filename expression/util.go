@@ -139,12 +139,30 @@ func calculateSum(sc *variable.StatementContext, sum, v types.Datum) (data types
 	case types.KindNull:
 		return data, nil
 	case types.KindFloat64, types.KindMysqlDecimal:
-		return types.ComputePlus(sum, data)
+		data, err = types.ComputePlus(sum, data)
+		if err != nil || data.Kind() != types.KindMysqlDecimal {
+			return data, errors.Trace(err)
+		}
+		return data, errors.Trace(handleSumDecimalOverflow(sc, &data))
 	default:
 		return data, errors.Errorf("invalid value %v for aggregate", sum.Kind())
 	}
 }
 
+// handleSumDecimalOverflow replaces the decimal sum which exceeds the max precision of DECIMAL with the
+// max or min DECIMAL value, and returns ErrOverflow or appends it as a warning based on sc.
+func handleSumDecimalOverflow(sc *variable.StatementContext, sum *types.Datum) error {
+	dec := sum.GetMysqlDecimal()
+	if precision, _ := dec.PrecisionAndFrac(); precision <= mysql.MaxDecimalWidth {
+		return nil
+	}
+	frac := sum.Frac()
+	sum.SetMysqlDecimal(types.NewMaxOrMinDec(dec.IsNegative(), mysql.MaxDecimalWidth, frac))
+	sum.SetFrac(frac)
+	err := types.ErrOverflow.GenByArgs("DECIMAL", "sum")
+	return sc.HandleOverflow(err, err)
+}
+
 // getValidPrefix gets a prefix of string which can parsed to a number with base. the minimum base is 2 and the maximum is 36.
 func getValidPrefix(s string, base int64) string {
 	var (
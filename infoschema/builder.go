@@ -40,6 +40,8 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 	} else if diff.Type == model.ActionDropSchema {
 		tblIDs := b.applyDropSchema(diff.SchemaID)
 		return tblIDs, nil
+	} else if diff.Type == model.ActionRenameTables {
+		return b.applyRenameTables(m, diff)
 	}
 
 	roDBInfo, ok := b.is.SchemaByID(diff.SchemaID)
@@ -97,6 +99,42 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 	return tblIDs, nil
 }
 
+// applyRenameTables drops all the renamed tables before creating them with the new names,
+// since the tables may swap their names.
+func (b *Builder) applyRenameTables(m *meta.Meta, diff *model.SchemaDiff) ([]int64, error) {
+	tblIDs := make([]int64, 0, len(diff.AffectedOpts))
+	allocs := make([]autoid.Allocator, 0, len(diff.AffectedOpts))
+	for _, opt := range diff.AffectedOpts {
+		oldRoDBInfo, ok := b.is.SchemaByID(opt.OldSchemaID)
+		if !ok {
+			return nil, ErrDatabaseNotExists.GenByArgs(
+				fmt.Sprintf("(Schema ID %d)", opt.OldSchemaID),
+			)
+		}
+		b.copySchemaTables(oldRoDBInfo.Name.L)
+		b.copySortedTables(opt.TableID, opt.TableID)
+		// We try to reuse the old allocator, so the cached auto ID can be reused.
+		alloc, _ := b.is.AllocByID(opt.TableID)
+		allocs = append(allocs, alloc)
+		b.applyDropTable(oldRoDBInfo, opt.TableID)
+		tblIDs = append(tblIDs, opt.TableID)
+	}
+	for i, opt := range diff.AffectedOpts {
+		roDBInfo, ok := b.is.SchemaByID(opt.SchemaID)
+		if !ok {
+			return nil, ErrDatabaseNotExists.GenByArgs(
+				fmt.Sprintf("(Schema ID %d)", opt.SchemaID),
+			)
+		}
+		b.copySchemaTables(roDBInfo.Name.L)
+		err := b.applyCreateTable(m, roDBInfo, opt.TableID, allocs[i])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return tblIDs, nil
+}
+
 // copySortedTables copies sortedTables for old table and new table for later modification.
 func (b *Builder) copySortedTables(oldTableID, newTableID int64) {
 	buckets := b.is.sortedTablesBuckets
@@ -241,11 +241,21 @@ func (is *infoSchema) Clone() (result []*model.DBInfo) {
 	return
 }
 
+// StatsProvider provides the statistics shown by the information_schema tables.
+type StatsProvider interface {
+	// ColumnNDV returns the number of distinct values of the column, ok is false if it has no statistics.
+	ColumnNDV(tableID, colID int64) (ndv int64, ok bool)
+	// IndexNDV returns the number of distinct values of the index, ok is false if it has no statistics.
+	IndexNDV(tableID, idxID int64) (ndv int64, ok bool)
+}
+
 // Handle handles information schema, including getting and setting.
 type Handle struct {
 	value      atomic.Value
 	store      kv.Storage
 	perfHandle perfschema.PerfSchema
+	// stats holds the StatsProvider.
+	stats atomic.Value
 }
 
 // NewHandle creates a new Handle.
@@ -274,12 +284,28 @@ func (h *Handle) GetPerfHandle() perfschema.PerfSchema {
 	return h.perfHandle
 }
 
-// EmptyClone creates a new Handle with the same store and memSchema, but the value is not set.
+// SetStatsProvider sets the StatsProvider of the information_schema tables.
+func (h *Handle) SetStatsProvider(p StatsProvider) {
+	h.stats.Store(&p)
+}
+
+// GetStatsProvider gets the StatsProvider of the information_schema tables, it returns nil if it's not set.
+func (h *Handle) GetStatsProvider() StatsProvider {
+	if p, ok := h.stats.Load().(*StatsProvider); ok {
+		return *p
+	}
+	return nil
+}
+
+// EmptyClone creates a new Handle with the same store, memSchema and StatsProvider, but the value is not set.
 func (h *Handle) EmptyClone() *Handle {
 	newHandle := &Handle{
 		store:      h.store,
 		perfHandle: h.perfHandle,
 	}
+	if p := h.GetStatsProvider(); p != nil {
+		newHandle.SetStatsProvider(p)
+	}
 	return newHandle
 }
 
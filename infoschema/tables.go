@@ -16,6 +16,7 @@ package infoschema
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -27,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -63,6 +65,8 @@ const (
 	tableOptimizerTrace                     = "OPTIMIZER_TRACE"
 	tableTableSpaces                        = "TABLESPACES"
 	tableCollationCharacterSetApplicability = "COLLATION_CHARACTER_SET_APPLICABILITY"
+	tableSlowQuery                          = "SLOW_QUERY"
+	tableProcessList                        = "PROCESSLIST"
 )
 
 type columnInfo struct {
@@ -520,6 +524,32 @@ var tableCollationCharacterSetApplicabilityCols = []columnInfo{
 	{"TABLESPACE_COMMENT", mysql.TypeVarchar, 2048, 0, nil, nil},
 }
 
+var tableSlowQueryCols = []columnInfo{
+	{"START_TIME", mysql.TypeDatetime, 26, 0, nil, nil},
+	{"QUERY_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"CONN_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"HOST", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"SQL_TEXT", mysql.TypeBlob, 0, 0, nil, nil},
+	{"TRACE_ID", mysql.TypeVarchar, 128, 0, nil, nil},
+}
+
+// tableProcessListCols are the columns of SHOW PROCESSLIST, and the transaction of each connection.
+var tableProcessListCols = []columnInfo{
+	{"ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 16, 0, nil, nil},
+	{"HOST", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"COMMAND", mysql.TypeVarchar, 16, 0, nil, nil},
+	{"TIME", mysql.TypeLong, 7, 0, nil, nil},
+	{"STATE", mysql.TypeVarchar, 7, 0, nil, nil},
+	{"INFO", mysql.TypeString, 512, 0, nil, nil},
+	{"ISOLATION_LEVEL", mysql.TypeVarchar, 16, 0, nil, nil},
+	{"TXN_MODE", mysql.TypeVarchar, 16, 0, nil, nil},
+	{"IN_TRANSACTION", mysql.TypeTiny, 1, 0, nil, nil},
+}
+
 func dataForCharacterSets() (records [][]types.Datum) {
 	records = append(records,
 		types.MakeDatums("ascii", "ascii_general_ci", "US ASCII", 1),
@@ -556,6 +586,88 @@ func dataForSessionVar(ctx context.Context) (records [][]types.Datum, err error)
 	return
 }
 
+// dataForSlowQuery returns the latest slow queries of this server, which are kept in memory.
+// Like the process list, the users without the PROCESS or SUPER privilege only see their own statements.
+func dataForSlowQuery(ctx context.Context) (records [][]types.Datum) {
+	var user string
+	checker := privilege.GetPrivilegeManager(ctx)
+	if checker != nil && !checker.RequestVerification("", "", "", mysql.ProcessPriv) &&
+		!checker.RequestVerification("", "", "", mysql.SuperPriv) {
+		if u := ctx.GetSessionVars().User; u != nil {
+			user = u.Username
+		}
+		if len(user) == 0 {
+			return nil
+		}
+	}
+	for _, item := range util.SlowQueries.Items() {
+		if len(user) > 0 && item.User != user {
+			continue
+		}
+		startTime := types.Time{Time: types.FromGoTime(item.Start), Type: mysql.TypeDatetime, Fsp: types.MaxFsp}
+		record := types.MakeDatums(
+			startTime,               // START_TIME
+			item.Duration.Seconds(), // QUERY_TIME
+			item.ConnID,             // CONN_ID
+			item.User,               // USER
+			item.Host,               // HOST
+			item.DB,                 // DB
+			item.Query,              // SQL_TEXT
+			item.TraceID,            // TRACE_ID
+		)
+		records = append(records, record)
+	}
+	return records
+}
+
+// dataForProcessList returns the connections of this server, the users without the PROCESS or SUPER privilege
+// only see their own connections.
+func dataForProcessList(ctx context.Context) (records [][]types.Datum) {
+	sm := ctx.GetSessionManager()
+	if sm == nil {
+		return nil
+	}
+	var user string
+	checker := privilege.GetPrivilegeManager(ctx)
+	if checker != nil && !checker.RequestVerification("", "", "", mysql.ProcessPriv) &&
+		!checker.RequestVerification("", "", "", mysql.SuperPriv) {
+		if u := ctx.GetSessionVars().User; u != nil {
+			user = u.Username
+		}
+		if len(user) == 0 {
+			return nil
+		}
+	}
+	for _, pi := range sm.ShowProcessList() {
+		if len(user) > 0 && pi.User != user {
+			continue
+		}
+		var t uint64
+		if len(pi.Info) != 0 {
+			t = uint64(time.Since(pi.Time) / time.Second)
+		}
+		inTxn := 0
+		if pi.InTxn {
+			inTxn = 1
+		}
+		record := types.MakeDatums(
+			pi.ID,                       // ID
+			pi.User,                     // USER
+			pi.Host,                     // HOST
+			pi.DB,                       // DB
+			pi.Command,                  // COMMAND
+			t,                           // TIME
+			fmt.Sprintf("%d", pi.State), // STATE
+			pi.Info,                     // INFO
+			pi.IsolationLevel,           // ISOLATION_LEVEL
+			pi.TxnMode,                  // TXN_MODE
+			inTxn,                       // IN_TRANSACTION
+		)
+		records = append(records, record)
+	}
+	return records
+}
+
 func dataForUserPrivileges(ctx context.Context) [][]types.Datum {
 	pm := privilege.GetPrivilegeManager(ctx)
 	return pm.UserPrivilegesTable()
@@ -701,29 +813,29 @@ func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types
 			columnDefault,                        // COLUMN_DEFAULT
 			columnDesc.Null,                      // IS_NULLABLE
 			types.TypeToStr(col.Tp, col.Charset), // DATA_TYPE
-			colLen,                            // CHARACTER_MAXIMUM_LENGTH
-			colLen,                            // CHARACTER_OCTET_LENGTH
-			decimal,                           // NUMERIC_PRECISION
-			0,                                 // NUMERIC_SCALE
-			0,                                 // DATETIME_PRECISION
-			col.Charset,                       // CHARACTER_SET_NAME
-			col.Collate,                       // COLLATION_NAME
-			columnType,                        // COLUMN_TYPE
-			columnDesc.Key,                    // COLUMN_KEY
-			columnDesc.Extra,                  // EXTRA
-			"select,insert,update,references", // PRIVILEGES
-			"", // COLUMN_COMMENT
+			colLen,                               // CHARACTER_MAXIMUM_LENGTH
+			colLen,                               // CHARACTER_OCTET_LENGTH
+			decimal,                              // NUMERIC_PRECISION
+			0,                                    // NUMERIC_SCALE
+			0,                                    // DATETIME_PRECISION
+			col.Charset,                          // CHARACTER_SET_NAME
+			col.Collate,                          // COLLATION_NAME
+			columnType,                           // COLUMN_TYPE
+			columnDesc.Key,                       // COLUMN_KEY
+			columnDesc.Extra,                     // EXTRA
+			"select,insert,update,references",    // PRIVILEGES
+			"",                                   // COLUMN_COMMENT
 		)
 		rows = append(rows, record)
 	}
 	return rows
 }
 
-func dataForStatistics(schemas []*model.DBInfo) [][]types.Datum {
+func dataForStatistics(schemas []*model.DBInfo, stats StatsProvider) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
 		for _, table := range schema.Tables {
-			rs := dataForStatisticsInTable(schema, table)
+			rs := dataForStatisticsInTable(schema, table, stats)
 			for _, r := range rs {
 				rows = append(rows, r)
 			}
@@ -732,11 +844,35 @@ func dataForStatistics(schemas []*model.DBInfo) [][]types.Datum {
 	return rows
 }
 
-func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]types.Datum {
+// cardinality returns the estimated number of distinct values of the first seq columns of the index,
+// it's the NDV of the first column for the first one, and the NDV of the index for the others.
+// It returns nil if there are no statistics.
+func cardinality(stats StatsProvider, table *model.TableInfo, index *model.IndexInfo, col *model.ColumnInfo, seq int) interface{} {
+	if stats == nil {
+		return nil
+	}
+	var (
+		ndv int64
+		ok  bool
+	)
+	if seq == 1 {
+		ndv, ok = stats.ColumnNDV(table.ID, col.ID)
+	}
+	if !ok && index != nil {
+		ndv, ok = stats.IndexNDV(table.ID, index.ID)
+	}
+	if !ok {
+		return nil
+	}
+	return ndv
+}
+
+func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo, stats StatsProvider) [][]types.Datum {
 	rows := [][]types.Datum{}
 	if table.PKIsHandle {
 		for _, col := range table.Columns {
 			if mysql.HasPriKeyFlag(col.Flag) {
+				card := cardinality(stats, table, nil, col, 1)
 				record := types.MakeDatums(
 					catalogVal,    // TABLE_CATALOG
 					schema.Name.O, // TABLE_SCHEMA
@@ -747,7 +883,7 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 					1,             // SEQ_IN_INDEX
 					col.Name.O,    // COLUMN_NAME
 					"A",           // COLLATION
-					0,             // CARDINALITY
+					card,          // CARDINALITY
 					nil,           // SUB_PART
 					nil,           // PACKED
 					"",            // NULLABLE
@@ -764,6 +900,9 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 		nameToCol[c.Name.L] = c
 	}
 	for _, index := range table.Indices {
+		if index.State != model.StatePublic {
+			continue
+		}
 		nonUnique := "1"
 		if index.Unique {
 			nonUnique = "0"
@@ -774,6 +913,11 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 			if mysql.HasNotNullFlag(col.Flag) {
 				nullable = ""
 			}
+			var subPart interface{}
+			if key.Length != types.UnspecifiedLength {
+				subPart = key.Length
+			}
+			card := cardinality(stats, table, index, col, i+1)
 			record := types.MakeDatums(
 				catalogVal,    // TABLE_CATALOG
 				schema.Name.O, // TABLE_SCHEMA
@@ -784,8 +928,8 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 				i+1,           // SEQ_IN_INDEX
 				key.Name.O,    // COLUMN_NAME
 				"A",           // COLLATION
-				0,             // CARDINALITY
-				nil,           // SUB_PART
+				card,          // CARDINALITY
+				subPart,       // SUB_PART
 				nil,           // PACKED
 				nullable,      // NULLABLE
 				"BTREE",       // INDEX_TYPE
@@ -974,6 +1118,8 @@ var tableNameToColumns = map[string]([]columnInfo){
 	tableOptimizerTrace:                     tableOptimizerTraceCols,
 	tableTableSpaces:                        tableTableSpacesCols,
 	tableCollationCharacterSetApplicability: tableCollationCharacterSetApplicabilityCols,
+	tableSlowQuery:                          tableSlowQueryCols,
+	tableProcessList:                        tableProcessListCols,
 }
 
 func createInfoSchemaTable(handle *Handle, meta *model.TableInfo) *infoschemaTable {
@@ -1022,7 +1168,7 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableColumns:
 		fullRows = dataForColumns(dbs)
 	case tableStatistics:
-		fullRows = dataForStatistics(dbs)
+		fullRows = dataForStatistics(dbs, it.handle.GetStatsProvider())
 	case tableCharacterSets:
 		fullRows = dataForCharacterSets()
 	case tableCollations:
@@ -1056,6 +1202,10 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableOptimizerTrace:
 	case tableTableSpaces:
 	case tableCollationCharacterSetApplicability:
+	case tableSlowQuery:
+		fullRows = dataForSlowQuery(ctx)
+	case tableProcessList:
+		fullRows = dataForProcessList(ctx)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -14,6 +14,7 @@
 package inspectkv
 
 import (
+	"fmt"
 	"io"
 	"reflect"
 	"time"
@@ -235,6 +236,110 @@ func checkRecordAndIndex(txn kv.Transaction, t table.Table, idx table.Index) err
 	return nil
 }
 
+// The kinds of the inconsistencies between an index and the table rows.
+const (
+	// OrphanIndexEntry is an index entry whose row doesn't exist.
+	OrphanIndexEntry = "orphan index entry"
+	// MissingIndexEntry is a row without the index entry of its values.
+	MissingIndexEntry = "missing index entry"
+	// MismatchedIndexEntry is an index entry whose values are different from the values in its row.
+	MismatchedIndexEntry = "mismatched index entry"
+)
+
+// Inconsistency is an inconsistency between an index and the table rows found by CheckIndex.
+type Inconsistency struct {
+	Kind   string
+	Handle int64
+	// IndexValues are the values in the index entry, it's nil for MissingIndexEntry.
+	IndexValues []types.Datum
+	// RecordValues are the values of the index columns in the row, it's nil for OrphanIndexEntry.
+	RecordValues []types.Datum
+}
+
+func (ic *Inconsistency) String() string {
+	switch ic.Kind {
+	case OrphanIndexEntry:
+		return fmt.Sprintf("%s handle:%d index:%v", ic.Kind, ic.Handle, datumValues(ic.IndexValues))
+	case MissingIndexEntry:
+		return fmt.Sprintf("%s handle:%d record:%v", ic.Kind, ic.Handle, datumValues(ic.RecordValues))
+	default:
+		return fmt.Sprintf("%s handle:%d index:%v record:%v", ic.Kind, ic.Handle,
+			datumValues(ic.IndexValues), datumValues(ic.RecordValues))
+	}
+}
+
+func datumValues(data []types.Datum) []interface{} {
+	vals := make([]interface{}, 0, len(data))
+	for _, d := range data {
+		vals = append(vals, d.GetValue())
+	}
+	return vals
+}
+
+// CheckIndex scans the index and the table, and returns the inconsistencies between them.
+// Unlike CompareIndexData, it doesn't stop at the first inconsistency, the scan stops after
+// limit inconsistencies are found, limit <= 0 means no limit.
+func CheckIndex(txn kv.Transaction, t table.Table, idx table.Index, limit int) ([]*Inconsistency, error) {
+	cols := make([]*table.Column, len(idx.Meta().Columns))
+	for i, col := range idx.Meta().Columns {
+		cols[i] = t.Cols()[col.Offset]
+	}
+	var result []*Inconsistency
+	full := func() bool {
+		return limit > 0 && len(result) >= limit
+	}
+
+	// Check the index entries against the rows.
+	it, err := idx.SeekFirst(txn)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer it.Close()
+	// The rows with mismatched index entries have no index entries of their values,
+	// they're not reported again when the rows are checked.
+	mismatched := make(map[int64]struct{})
+	for !full() {
+		idxVals, h, err := it.Next()
+		if terror.ErrorEqual(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		recordVals, err := rowWithCols(txn, t, h, cols)
+		if kv.ErrNotExist.Equal(err) {
+			result = append(result, &Inconsistency{Kind: OrphanIndexEntry, Handle: h, IndexValues: idxVals})
+			continue
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !reflect.DeepEqual(idxVals, recordVals) {
+			result = append(result, &Inconsistency{Kind: MismatchedIndexEntry, Handle: h, IndexValues: idxVals, RecordValues: recordVals})
+			mismatched[h] = struct{}{}
+		}
+	}
+	if full() {
+		return result, nil
+	}
+
+	// Check the rows against the index entries.
+	filterFunc := func(h int64, vals []types.Datum, cols []*table.Column) (bool, error) {
+		exist, _, err := idx.Exist(txn, vals, h)
+		if err != nil && !kv.ErrKeyExists.Equal(err) {
+			return false, errors.Trace(err)
+		}
+		// ErrKeyExists means the unique index entry of the values points to another row.
+		if !exist || err != nil {
+			if _, ok := mismatched[h]; !ok {
+				result = append(result, &Inconsistency{Kind: MissingIndexEntry, Handle: h, RecordValues: vals})
+			}
+		}
+		return !full(), nil
+	}
+	err = iterRecords(txn, t, t.RecordKey(0), cols, filterFunc)
+	return result, errors.Trace(err)
+}
+
 func scanTableData(retriever kv.Retriever, t table.Table, cols []*table.Column, startHandle, limit int64) (
 	[]*RecordData, int64, error) {
 	var records []*RecordData
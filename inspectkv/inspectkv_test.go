@@ -331,6 +331,18 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 	record1 := &RecordData{Handle: int64(3), Values: types.MakeDatums(int64(30))}
 	diffMsg := newDiffRetError("index", record1, nil)
 	c.Assert(err.Error(), DeepEquals, diffMsg)
+	// CheckIndex reports all the inconsistencies.
+	inconsistencies, err := CheckIndex(txn, tb, idx, 0)
+	c.Assert(err, IsNil)
+	c.Assert(inconsistencies, DeepEquals, []*Inconsistency{
+		{Kind: OrphanIndexEntry, Handle: 3, IndexValues: types.MakeDatums(int64(30))},
+		{Kind: MissingIndexEntry, Handle: 4, RecordValues: types.MakeDatums(int64(40))},
+	})
+	c.Assert(inconsistencies[0].String(), Equals, "orphan index entry handle:3 index:[30]")
+	c.Assert(inconsistencies[1].String(), Equals, "missing index entry handle:4 record:[40]")
+	inconsistencies, err = CheckIndex(txn, tb, idx, 1)
+	c.Assert(err, IsNil)
+	c.Assert(inconsistencies, HasLen, 1)
 
 	// set data to:
 	// index     data (handle, data): (1, 10), (2, 20), (3, 30), (4, 40)
@@ -349,6 +361,13 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 	record2 := &RecordData{Handle: int64(3), Values: types.MakeDatums(int64(31))}
 	diffMsg = newDiffRetError("index", record1, record2)
 	c.Assert(err.Error(), DeepEquals, diffMsg)
+	// The row of the mismatched index entry isn't reported as missing again.
+	inconsistencies, err = CheckIndex(txn, tb, idx, 0)
+	c.Assert(err, IsNil)
+	c.Assert(inconsistencies, DeepEquals, []*Inconsistency{
+		{Kind: MismatchedIndexEntry, Handle: 3, IndexValues: types.MakeDatums(int64(30)), RecordValues: types.MakeDatums(int64(31))},
+	})
+	c.Assert(inconsistencies[0].String(), Equals, "mismatched index entry handle:3 index:[30] record:[31]")
 
 	// set data to:
 	// index     data (handle, data): (1, 10), (2, 20), (3, 30), (4, 40)
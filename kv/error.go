@@ -34,6 +34,8 @@ const (
 	codeNotImplemented                            = 10
 	codeTxnTooLarge                               = 11
 	codeEntryTooLarge                             = 12
+	codeTooManyScanRegions                        = 13
+	codeDiskFull                                  = 14
 
 	codeKeyExists = 1062
 )
@@ -60,6 +62,10 @@ var (
 	ErrTxnTooLarge = terror.ClassKV.New(codeTxnTooLarge, "transaction is too large")
 	// ErrEntryTooLarge is the error when a key value entry is too large.
 	ErrEntryTooLarge = terror.ClassKV.New(codeEntryTooLarge, "entry is too large")
+	// ErrTooManyScanRegions is the error when a request scans more regions than the limit.
+	ErrTooManyScanRegions = terror.ClassKV.New(codeTooManyScanRegions, "the query scans more than %d regions, which is limited by max-scan-regions")
+	// ErrDiskFull is the error when the disk of the store is full, the store is read-only until some space is freed.
+	ErrDiskFull = terror.ClassKV.New(codeDiskFull, "disk full, the store is read-only until some space is freed")
 
 	// ErrNotCommitted is the error returned by CommitVersion when this
 	// transaction is not committed.
@@ -74,6 +80,7 @@ var (
 func init() {
 	kvMySQLErrCodes := map[terror.ErrCode]uint16{
 		codeKeyExists: mysql.ErrDupEntry,
+		codeDiskFull:  mysql.ErrDiskFull,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassKV] = kvMySQLErrCodes
 }
@@ -131,6 +131,11 @@ type Transaction interface {
 	// Valid returns if the transaction is valid.
 	// A transaction become invalid after commit or rollback.
 	Valid() bool
+	// Checkpoint returns a checkpoint of the buffered writes, the writes after it can be discarded by
+	// RollbackToCheckpoint. It's used to implement savepoints.
+	Checkpoint() int
+	// RollbackToCheckpoint discards the buffered writes after the checkpoint.
+	RollbackToCheckpoint(cp int)
 }
 
 // Client is used to send request to KV layer.
@@ -222,6 +227,14 @@ type Storage interface {
 	SupportDeleteRange() (supported bool)
 }
 
+// SplitableStore is the storage that can split a region at a given key.
+type SplitableStore interface {
+	// SupportSplitRegion gets the storage support splitting regions on demand or not.
+	SupportSplitRegion() (supported bool)
+	// SplitRegion splits the region containing splitKey so that splitKey becomes the start key of a new region.
+	SplitRegion(splitKey Key) error
+}
+
 // FnKeyCmp is the function for iterator the keys
 type FnKeyCmp func(key Key) bool
 
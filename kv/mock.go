@@ -86,6 +86,13 @@ func (t *mockTxn) Valid() bool {
 	return t.valid
 }
 
+func (t *mockTxn) Checkpoint() int {
+	return 0
+}
+
+func (t *mockTxn) RollbackToCheckpoint(cp int) {
+}
+
 func (t *mockTxn) Len() int {
 	return 0
 }
@@ -35,6 +35,11 @@ type UnionStore interface {
 	DelOption(opt Option)
 	// GetOption gets an option.
 	GetOption(opt Option) interface{}
+	// Checkpoint returns a checkpoint of the buffered kv pairs, the writes after it can be discarded by
+	// RollbackToCheckpoint.
+	Checkpoint() int
+	// RollbackToCheckpoint discards the writes after the checkpoint, the checkpoints after it become invalid.
+	RollbackToCheckpoint(cp int)
 }
 
 // Option is used for customizing kv store's behaviors during a transaction.
@@ -54,6 +59,16 @@ type conditionPair struct {
 	err   error
 }
 
+// undoEntry records the state of a key in the buffer before it's changed, it's used to roll back to a checkpoint.
+type undoEntry struct {
+	key Key
+	// isCondition means the entry is for the lazy condition pair of the key.
+	isCondition bool
+	exist       bool
+	value       []byte
+	cond        *conditionPair
+}
+
 // UnionStore is an in-memory Store which contains a buffer for write and a
 // snapshot for read.
 type unionStore struct {
@@ -61,6 +76,8 @@ type unionStore struct {
 	snapshot           Snapshot                    // for read
 	lazyConditionPairs map[string](*conditionPair) // for delay check
 	opts               options
+	// undoLog is the journal of the changes after the first checkpoint, it's nil if there's no checkpoint.
+	undoLog []undoEntry
 }
 
 // NewUnionStore builds a new UnionStore.
@@ -123,6 +140,14 @@ func (lmb *lazyMemBuffer) Delete(k Key) error {
 	return lmb.mb.Delete(k)
 }
 
+// remove removes the entry of the key from the buffer, unlike Delete, it doesn't leave a tombstone.
+func (lmb *lazyMemBuffer) remove(k Key) {
+	if lmb.mb == nil {
+		return
+	}
+	lmb.mb.(*memDbBuffer).remove(k)
+}
+
 func (lmb *lazyMemBuffer) Seek(k Key) (Iterator, error) {
 	if lmb.mb == nil {
 		return invalidIterator{}, nil
@@ -177,9 +202,73 @@ func (us *unionStore) Get(k Key) ([]byte, error) {
 	return v, nil
 }
 
+// Set implements the Mutator interface.
+func (us *unionStore) Set(k Key, v []byte) error {
+	us.recordUndo(k)
+	return us.BufferStore.Set(k, v)
+}
+
+// Delete implements the Mutator interface.
+func (us *unionStore) Delete(k Key) error {
+	us.recordUndo(k)
+	return us.BufferStore.Delete(k)
+}
+
+// recordUndo records the buffered value of the key before it's changed if there's any checkpoint.
+func (us *unionStore) recordUndo(k Key) {
+	if us.undoLog == nil {
+		return
+	}
+	v, err := us.MemBuffer.Get(k)
+	us.undoLog = append(us.undoLog, undoEntry{
+		key:   k.Clone(),
+		exist: err == nil,
+		value: append([]byte(nil), v...),
+	})
+}
+
+// Checkpoint implements the UnionStore Checkpoint interface.
+func (us *unionStore) Checkpoint() int {
+	if us.undoLog == nil {
+		us.undoLog = make([]undoEntry, 0, 16)
+	}
+	return len(us.undoLog)
+}
+
+// RollbackToCheckpoint implements the UnionStore RollbackToCheckpoint interface.
+func (us *unionStore) RollbackToCheckpoint(cp int) {
+	for i := len(us.undoLog) - 1; i >= cp; i-- {
+		e := us.undoLog[i]
+		switch {
+		case e.isCondition && e.cond != nil:
+			us.lazyConditionPairs[string(e.key)] = e.cond
+		case e.isCondition:
+			delete(us.lazyConditionPairs, string(e.key))
+		case !e.exist:
+			us.MemBuffer.(*lazyMemBuffer).remove(e.key)
+		case len(e.value) == 0:
+			// Deleting a key in the buffer never fails.
+			us.MemBuffer.Delete(e.key)
+		default:
+			// The value was in the buffer before, so it can't exceed the limits.
+			us.MemBuffer.Set(e.key, e.value)
+		}
+	}
+	if cp < len(us.undoLog) {
+		us.undoLog = us.undoLog[:cp]
+	}
+}
+
 // markLazyConditionPair marks a kv pair for later check.
 // If condition not match, should return e as error.
 func (us *unionStore) markLazyConditionPair(k Key, v []byte, e error) {
+	if us.undoLog != nil {
+		us.undoLog = append(us.undoLog, undoEntry{
+			key:         k.Clone(),
+			isCondition: true,
+			cond:        us.lazyConditionPairs[string(k)],
+		})
+	}
 	us.lazyConditionPairs[string(k)] = &conditionPair{
 		key:   k.Clone(),
 		value: v,
@@ -125,6 +125,41 @@ func (s *testUnionStoreSuite) TestLazyConditionCheck(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testUnionStoreSuite) TestRollbackToCheckpoint(c *C) {
+	defer testleak.AfterTest(c)()
+	s.store.Set([]byte("1"), []byte("1"))
+	s.us.Set([]byte("2"), []byte("2"))
+
+	cp1 := s.us.Checkpoint()
+	s.us.Set([]byte("2"), []byte("22"))
+	s.us.Delete([]byte("1"))
+	s.us.Set([]byte("3"), []byte("3"))
+	cp2 := s.us.Checkpoint()
+	s.us.Set([]byte("3"), []byte("33"))
+	s.us.Set([]byte("4"), []byte("4"))
+
+	s.us.RollbackToCheckpoint(cp2)
+	iter, err := s.us.Seek(nil)
+	c.Assert(err, IsNil)
+	checkIterator(c, iter, [][]byte{[]byte("2"), []byte("3")}, [][]byte{[]byte("22"), []byte("3")})
+
+	s.us.RollbackToCheckpoint(cp1)
+	iter, err = s.us.Seek(nil)
+	c.Assert(err, IsNil)
+	checkIterator(c, iter, [][]byte{[]byte("1"), []byte("2")}, [][]byte{[]byte("1"), []byte("2")})
+	// The keys written after the checkpoint are removed from the buffer.
+	c.Assert(s.us.Len(), Equals, 1)
+
+	// The lazy condition pairs marked after the checkpoint are discarded.
+	s.us.SetOption(PresumeKeyNotExists, nil)
+	cp := s.us.Checkpoint()
+	_, err = s.us.Get([]byte("1"))
+	c.Assert(terror.ErrorEqual(err, ErrNotExist), IsTrue)
+	c.Assert(s.us.CheckLazyConditionPairs(), NotNil)
+	s.us.RollbackToCheckpoint(cp)
+	c.Assert(s.us.CheckLazyConditionPairs(), IsNil)
+}
+
 func checkIterator(c *C, iter Iterator, keys [][]byte, values [][]byte) {
 	defer iter.Close()
 	c.Assert(len(keys), Equals, len(values))
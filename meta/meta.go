@@ -47,6 +47,11 @@ var (
 //		TID:1 -> int64
 //		TID:2 -> int64
 //	}
+//	ServerIDs -> {
+//		1 -> lease expire time in unix nanoseconds
+//		2 -> lease expire time in unix nanoseconds
+//	}
+//	GlobalKill:1 -> [KILL requests sent to server 1]
 //
 
 var (
@@ -60,6 +65,8 @@ var (
 	mBootstrapKey     = []byte("BootstrapKey")
 	mTableStatsPrefix = "TStats"
 	mSchemaDiffPrefix = "Diff"
+	mServerIDsKey     = []byte("ServerIDs")
+	mGlobalKillPrefix = "GlobalKill"
 )
 
 var (
@@ -539,6 +546,25 @@ func (m *Meta) GetHistoryDDLJob(id int64) (*model.Job, error) {
 	return m.getHistoryDDLJob(mDDLJobHistoryKey, id)
 }
 
+// PruneHistoryDDLJobs removes at most 'batchSize' oldest history DDL jobs to keep the latest 'limit' jobs.
+// It returns the number of the removed jobs.
+func (m *Meta) PruneHistoryDDLJobs(limit, batchSize int64) (int64, error) {
+	// The field is the big endian encoded job ID, so the fields are in the order of job ID.
+	fields, err := m.txn.HKeys(mDDLJobHistoryKey)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	cnt := int64(len(fields)) - limit
+	if cnt <= 0 {
+		return 0, nil
+	}
+	if cnt > batchSize {
+		cnt = batchSize
+	}
+	err = m.txn.HDel(mDDLJobHistoryKey, fields[:cnt]...)
+	return cnt, errors.Trace(err)
+}
+
 // GetAllHistoryDDLJobs gets all history DDL jobs.
 func (m *Meta) GetAllHistoryDDLJobs() ([]*model.Job, error) {
 	pairs, err := m.txn.HGetAll(mDDLJobHistoryKey)
@@ -696,6 +722,68 @@ func (m *Meta) SetSchemaDiff(diff *model.SchemaDiff) error {
 	return errors.Trace(err)
 }
 
+func (m *Meta) serverIDField(serverID uint64) []byte {
+	return []byte(strconv.FormatUint(serverID, 10))
+}
+
+// GetServerIDs returns the registered server IDs and the expire time of their leases in unix nanoseconds.
+func (m *Meta) GetServerIDs() (map[uint64]int64, error) {
+	pairs, err := m.txn.HGetAll(mServerIDsKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ids := make(map[uint64]int64, len(pairs))
+	for _, pair := range pairs {
+		id, err := strconv.ParseUint(string(pair.Field), 10, 64)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		expire, err := strconv.ParseInt(string(pair.Value), 10, 64)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ids[id] = expire
+	}
+	return ids, nil
+}
+
+// GetServerID returns the expire time of the server ID's lease in unix nanoseconds,
+// it returns 0 if the server ID isn't registered.
+func (m *Meta) GetServerID(serverID uint64) (int64, error) {
+	expire, err := m.txn.HGetInt64(mServerIDsKey, m.serverIDField(serverID))
+	return expire, errors.Trace(err)
+}
+
+// SetServerID registers the server ID with the expire time of its lease in unix nanoseconds.
+func (m *Meta) SetServerID(serverID uint64, expire int64) error {
+	value := []byte(strconv.FormatInt(expire, 10))
+	return errors.Trace(m.txn.HSet(mServerIDsKey, m.serverIDField(serverID), value))
+}
+
+// DelServerID unregisters the server ID and drops the KILL requests sent to it.
+func (m *Meta) DelServerID(serverID uint64) error {
+	err := m.txn.HDel(mServerIDsKey, m.serverIDField(serverID))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(m.txn.LClear(m.globalKillKey(serverID)))
+}
+
+func (m *Meta) globalKillKey(serverID uint64) []byte {
+	return []byte(fmt.Sprintf("%s:%d", mGlobalKillPrefix, serverID))
+}
+
+// EnQueueGlobalKill adds a KILL request to the list of the server.
+func (m *Meta) EnQueueGlobalKill(serverID uint64, req []byte) error {
+	return errors.Trace(m.txn.RPush(m.globalKillKey(serverID), req))
+}
+
+// DeQueueGlobalKill pops a KILL request from the list of the server, it returns nil if the list is empty.
+func (m *Meta) DeQueueGlobalKill(serverID uint64) ([]byte, error) {
+	req, err := m.txn.LPop(m.globalKillKey(serverID))
+	return req, errors.Trace(err)
+}
+
 // meta error codes.
 const (
 	codeInvalidTableKey terror.ErrCode = 1
@@ -311,6 +311,25 @@ func (s *testSuite) TestDDL(c *C) {
 		lastID = job.ID
 	}
 
+	for id := int64(3); id <= 5; id++ {
+		err = t.AddHistoryDDLJob(&model.Job{ID: id})
+		c.Assert(err, IsNil)
+	}
+	pruned, err := t.PruneHistoryDDLJobs(2, 1)
+	c.Assert(err, IsNil)
+	c.Assert(pruned, Equals, int64(1))
+	pruned, err = t.PruneHistoryDDLJobs(2, 10)
+	c.Assert(err, IsNil)
+	c.Assert(pruned, Equals, int64(1))
+	all, err = t.GetAllHistoryDDLJobs()
+	c.Assert(err, IsNil)
+	c.Assert(all, HasLen, 2)
+	c.Assert(all[0].ID, Equals, int64(4))
+	c.Assert(all[1].ID, Equals, int64(5))
+	pruned, err = t.PruneHistoryDDLJobs(2, 10)
+	c.Assert(err, IsNil)
+	c.Assert(pruned, Equals, int64(0))
+
 	bgJob := &model.Job{ID: 1}
 	err = t.EnQueueBgJob(bgJob)
 	c.Assert(err, IsNil)
@@ -341,3 +360,54 @@ func (s *testSuite) TestDDL(c *C) {
 	err = txn.Commit()
 	c.Assert(err, IsNil)
 }
+
+func (s *testSuite) TestGlobalKill(c *C) {
+	defer testleak.AfterTest(c)()
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+
+	t := meta.NewMeta(txn)
+	ids, err := t.GetServerIDs()
+	c.Assert(err, IsNil)
+	c.Assert(ids, HasLen, 0)
+	err = t.SetServerID(1, 100)
+	c.Assert(err, IsNil)
+	err = t.SetServerID(2, 200)
+	c.Assert(err, IsNil)
+	ids, err = t.GetServerIDs()
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, map[uint64]int64{1: 100, 2: 200})
+	expire, err := t.GetServerID(2)
+	c.Assert(err, IsNil)
+	c.Assert(expire, Equals, int64(200))
+	expire, err = t.GetServerID(3)
+	c.Assert(err, IsNil)
+	c.Assert(expire, Equals, int64(0))
+
+	err = t.EnQueueGlobalKill(1, []byte("a"))
+	c.Assert(err, IsNil)
+	err = t.EnQueueGlobalKill(1, []byte("b"))
+	c.Assert(err, IsNil)
+	req, err := t.DeQueueGlobalKill(1)
+	c.Assert(err, IsNil)
+	c.Assert(req, BytesEquals, []byte("a"))
+	req, err = t.DeQueueGlobalKill(2)
+	c.Assert(err, IsNil)
+	c.Assert(req, IsNil)
+
+	// The pending KILL requests are dropped with the server ID.
+	err = t.DelServerID(1)
+	c.Assert(err, IsNil)
+	req, err = t.DeQueueGlobalKill(1)
+	c.Assert(err, IsNil)
+	c.Assert(req, IsNil)
+	ids, err = t.GetServerIDs()
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, map[uint64]int64{2: 200})
+}
@@ -24,15 +24,15 @@ var (
 			Subsystem: "server",
 			Name:      "session_execute_parse_duration",
 			Help:      "Bucketed histogram of processing time (s) in parse SQL.",
-			Buckets:   prometheus.LinearBuckets(0.00004, 0.00001, 13),
+			Buckets:   prometheus.ExponentialBuckets(0.00004, 2, 16),
 		})
 	sessionExecuteCompileDuration = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "tidb",
 			Subsystem: "server",
 			Name:      "session_execute_compile_duration",
-			Help:      "Bucketed histogram of processing time (s) in query optimize.",
-			Buckets:   prometheus.LinearBuckets(0.00004, 0.00001, 13),
+			Help:      "Bucketed histogram of processing time (s) in building the plan of a statement, it doesn't include the execution.",
+			Buckets:   prometheus.ExponentialBuckets(0.00004, 2, 16),
 		})
 	sessionExecuteRunDuration = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
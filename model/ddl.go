@@ -42,6 +42,9 @@ const (
 	ActionModifyColumn
 	ActionRenameTable
 	ActionSetDefaultValue
+	ActionRenameTables
+	ActionAddCheck
+	ActionDropCheck
 )
 
 func (action ActionType) String() string {
@@ -74,6 +77,12 @@ func (action ActionType) String() string {
 		return "rename table"
 	case ActionSetDefaultValue:
 		return "set default value"
+	case ActionRenameTables:
+		return "rename tables"
+	case ActionAddCheck:
+		return "add check"
+	case ActionDropCheck:
+		return "drop check"
 	default:
 		return "none"
 	}
@@ -263,4 +272,14 @@ type SchemaDiff struct {
 	OldTableID int64 `json:"old_table_id"`
 	// OldSchemaID is the schema ID before rename table, only used by rename table DDL.
 	OldSchemaID int64 `json:"old_schema_id"`
+
+	// AffectedOpts is the tables changed by a DDL of multiple tables, only used by rename tables DDL.
+	AffectedOpts []*AffectedOption `json:"affected_options"`
+}
+
+// AffectedOption is a table changed by a DDL of multiple tables.
+type AffectedOption struct {
+	SchemaID    int64 `json:"schema_id"`
+	TableID     int64 `json:"table_id"`
+	OldSchemaID int64 `json:"old_schema_id"`
 }
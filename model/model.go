@@ -70,6 +70,8 @@ type ColumnInfo struct {
 	types.FieldType     `json:"type"`
 	State               SchemaState `json:"state"`
 	Comment             string      `json:"comment"`
+	// ChangingFrom is the ID of the column whose type is being changed to the type of this hidden column.
+	ChangingFrom int64 `json:"changing_from"`
 }
 
 // Clone clones ColumnInfo.
@@ -92,6 +94,7 @@ type TableInfo struct {
 	Columns     []*ColumnInfo `json:"cols"`
 	Indices     []*IndexInfo  `json:"index_info"`
 	ForeignKeys []*FKInfo     `json:"fk_info"`
+	Checks      []*CheckInfo  `json:"check_info"`
 	State       SchemaState   `json:"state"`
 	PKIsHandle  bool          `json:"pk_is_handle"`
 	Comment     string        `json:"comment"`
@@ -103,6 +106,12 @@ type TableInfo struct {
 	// We need to save original schemaID to keep autoID unchanged
 	// while renaming a table from one database to another.
 	OldSchemaID int64 `json:"old_schema_id,omitempty"`
+
+	// ShardRowIDBits specifies if the implicit row ID is sharded,
+	// the high bits of the row ID are filled with a shard derived from the transaction.
+	ShardRowIDBits uint64 `json:"shard_row_id_bits"`
+	// PreSplitRegions specifies the table is split into 2^PreSplitRegions regions when it is created.
+	PreSplitRegions uint64 `json:"pre_split_regions"`
 }
 
 // Clone clones TableInfo.
@@ -111,6 +120,7 @@ func (t *TableInfo) Clone() *TableInfo {
 	nt.Columns = make([]*ColumnInfo, len(t.Columns))
 	nt.Indices = make([]*IndexInfo, len(t.Indices))
 	nt.ForeignKeys = make([]*FKInfo, len(t.ForeignKeys))
+	nt.Checks = make([]*CheckInfo, len(t.Checks))
 
 	for i := range t.Columns {
 		nt.Columns[i] = t.Columns[i].Clone()
@@ -124,6 +134,10 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	for i := range t.Checks {
+		nt.Checks[i] = t.Checks[i].Clone()
+	}
+
 	return &nt
 }
 
@@ -259,6 +273,27 @@ func (fk *FKInfo) Clone() *FKInfo {
 	return &nfk
 }
 
+// CheckInfo provides meta data describing a CHECK constraint.
+type CheckInfo struct {
+	ID   int64 `json:"id"`
+	Name CIStr `json:"check_name"`
+	// ExprString is the text of the expression, a row violates the constraint if the expression is false.
+	ExprString string `json:"expr_string"`
+	// Cols are the columns referenced by the expression.
+	Cols  []CIStr     `json:"cols"`
+	State SchemaState `json:"state"`
+}
+
+// Clone clones CheckInfo.
+func (ck *CheckInfo) Clone() *CheckInfo {
+	nck := *ck
+
+	nck.Cols = make([]CIStr, len(ck.Cols))
+	copy(nck.Cols, ck.Cols)
+
+	return &nck
+}
+
 // DBInfo provides meta data describing a DB.
 type DBInfo struct {
 	ID      int64        `json:"id"`      // Database ID
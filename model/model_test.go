@@ -66,6 +66,12 @@ func (*testModelSuite) TestModelBasic(c *C) {
 		Cols:    []CIStr{NewCIStr("a")},
 	}
 
+	check := &CheckInfo{
+		Name:       NewCIStr("t_chk_1"),
+		ExprString: "c > 0",
+		Cols:       []CIStr{NewCIStr("c")},
+	}
+
 	table := &TableInfo{
 		ID:          1,
 		Name:        NewCIStr("t"),
@@ -74,6 +80,7 @@ func (*testModelSuite) TestModelBasic(c *C) {
 		Columns:     []*ColumnInfo{column},
 		Indices:     []*IndexInfo{index},
 		ForeignKeys: []*FKInfo{fk},
+		Checks:      []*CheckInfo{check},
 		PKIsHandle:  true,
 	}
 
@@ -221,6 +228,7 @@ func (testModelSuite) TestString(c *C) {
 		{ActionTruncateTable, "truncate table"},
 		{ActionModifyColumn, "modify column"},
 		{ActionRenameTable, "rename table"},
+		{ActionRenameTables, "rename tables"},
 		{ActionSetDefaultValue, "set default value"},
 		{ActionCreateSchema, "create schema"},
 		{ActionDropSchema, "drop schema"},
@@ -230,6 +238,8 @@ func (testModelSuite) TestString(c *C) {
 		{ActionDropIndex, "drop index"},
 		{ActionAddColumn, "add column"},
 		{ActionDropColumn, "drop column"},
+		{ActionAddCheck, "add check"},
+		{ActionDropCheck, "drop check"},
 	}
 
 	for _, v := range acts {
@@ -152,6 +152,8 @@ const (
 	GlobalStatusTable = "GLOBAL_STATUS"
 	// TiDBTable is the table contains tidb info.
 	TiDBTable = "tidb"
+	// BlockedDigestsTable is the table contains the digests of the blocked statements.
+	BlockedDigestsTable = "blocked_digests"
 )
 
 // PrivilegeType  privilege
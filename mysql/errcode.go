@@ -890,4 +890,12 @@ const (
 	ErrInvalidJSONPath                                              = 3143
 	ErrInvalidJSONData                                              = 3146
 	ErrJSONUsedAsKey                                                = 3152
+	ErrCheckConstraintNamedFunctionIsNotAllowed                     = 3814
+	ErrCheckConstraintFunctionIsNotAllowed                          = 3815
+	ErrCheckConstraintVariables                                     = 3816
+	ErrCheckConstraintViolated                                      = 3819
+	ErrCheckConstraintRefersUnknownColumn                           = 3820
+	ErrCheckConstraintDupName                                       = 3822
+	ErrConstraintNotFound                                           = 3940
+	ErrDependentByCheckConstraint                                   = 3959
 )
@@ -887,4 +887,12 @@ var MySQLErrName = map[uint16]string{
 	ErrInvalidJSONPath:                                       "Invalid JSON path expression %s.",
 	ErrInvalidJSONData:                                       "Invalid data type for JSON data",
 	ErrJSONUsedAsKey:                                         "JSON column '%-.192s' cannot be used in key specification.",
+	ErrCheckConstraintNamedFunctionIsNotAllowed:              "An expression of a check constraint '%-.64s' contains disallowed function: %s.",
+	ErrCheckConstraintFunctionIsNotAllowed:                   "An expression of a check constraint '%-.64s' contains disallowed function.",
+	ErrCheckConstraintVariables:                              "An expression of a check constraint '%-.64s' cannot refer to a user or system variable.",
+	ErrCheckConstraintViolated:                               "Check constraint '%-.64s' is violated.",
+	ErrCheckConstraintRefersUnknownColumn:                    "Check constraint '%-.64s' refers to non-existing column '%-.64s'.",
+	ErrCheckConstraintDupName:                                "Duplicate check constraint name '%-.192s'.",
+	ErrConstraintNotFound:                                    "Constraint '%-.192s' does not exist.",
+	ErrDependentByCheckConstraint:                            "Check constraint '%-.64s' uses column '%-.64s', hence column cannot be dropped or renamed.",
 }
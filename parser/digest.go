@@ -0,0 +1,73 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Normalize returns the normalized text of a SQL statement. Literals are replaced by '?',
+// comments are removed, and identifiers and keywords are lower cased and separated by a single space,
+// so statements that only differ in these parts have the same normalized text.
+func Normalize(sql string) string {
+	s := NewScanner(sql)
+	var buf bytes.Buffer
+	for {
+		tok, _, lit := s.scan()
+		if tok == 0 || (tok == unicode.ReplacementChar && s.r.eof()) {
+			break
+		}
+		switch tok {
+		case ';':
+			continue
+		case intLit, floatLit, decLit, hexLit, bitLit, stringLit:
+			lit = "?"
+		case identifier, quotedIdentifier:
+			lit = strings.ToLower(lit)
+		case jss:
+			lit = "->"
+		case juss:
+			lit = "->>"
+		case hintBegin:
+			lit = "/*+"
+		case hintEnd:
+			lit = "*/"
+		default:
+			if lit == "" && tok < unicode.MaxASCII {
+				lit = string(rune(tok))
+			}
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(lit)
+	}
+	return buf.String()
+}
+
+// Digest returns the hex encoded sha256 hash of the normalized SQL statement.
+func Digest(sql string) string {
+	_, digest := NormalizeDigest(sql)
+	return digest
+}
+
+// NormalizeDigest returns the normalized text and the digest of a SQL statement.
+func NormalizeDigest(sql string) (normalized, digest string) {
+	normalized = Normalize(sql)
+	return normalized, fmt.Sprintf("%x", sha256.Sum256([]byte(normalized)))
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testDigestSuite{})
+
+type testDigestSuite struct {
+}
+
+func (s *testDigestSuite) TestNormalize(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"SELECT * FROM t WHERE a = 1 AND b = 'x';", "select * from t where a = ? and b = ?"},
+		{"select  *  from `T`  /* comment */ where a=2.5 and b=\"y\"", "select * from t where a = ? and b = ?"},
+		{"select a from t where c in (1, 1e3, 0x1f, b'01', x'ff')", "select a from t where c in ( ? , ? , ? , ? , ? )"},
+		{"insert into t values (-1, null), (@a, @@b, ?)", "insert into t values ( - ? , null ) , ( @a , @@b , ? )"},
+		{"select j->'$.a', j->>'$.b' from t where a >= 1 || b <=> 2", "select j -> ? , j ->> ? from t where a >= ? || b <=> ?"},
+	}
+	for _, t := range tests {
+		c.Assert(Normalize(t.input), Equals, t.expect, Commentf("%s", t.input))
+	}
+
+	c.Assert(Digest("select * from t where a = 1"), Equals, Digest("SELECT * FROM t WHERE a = 100"))
+	c.Assert(Digest("select * from t where a = 1"), Not(Equals), Digest("select * from t where b = 1"))
+	c.Assert(Digest("select 1"), HasLen, 64)
+}
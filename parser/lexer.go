@@ -354,7 +354,8 @@ func startWithSlash(s *Scanner) (tok int, pos Pos, lit string) {
 
 		// See http://dev.mysql.com/doc/refman/5.7/en/comments.html
 		// Convert "/*!VersionNumber MySQL-specific-code */" to "MySQL-specific-code".
-		if strings.HasPrefix(comment, "/*!") {
+		// "/*T! TiDB-specific-code */" is converted the same way, so MySQL ignores the code but TiDB doesn't.
+		if strings.HasPrefix(comment, "/*!") || strings.HasPrefix(comment, "/*T!") {
 			sql := specCodePattern.ReplaceAllStringFunc(comment, TrimComment)
 			s.specialComment = &mysqlSpecificCodeScanner{
 				Scanner: NewScanner(sql),
@@ -249,6 +249,12 @@ func (s *testLexerSuite) TestSpecialComment(c *C) {
 	c.Assert(tok, Equals, intLit)
 	c.Assert(lit, Equals, "5")
 	c.Assert(pos, Equals, Pos{1, 1, 16})
+
+	l = NewScanner("/*T! shard_row_id_bits = 4 */")
+	tok, pos, lit = l.scan()
+	c.Assert(tok, Equals, identifier)
+	c.Assert(lit, Equals, "shard_row_id_bits")
+	c.Assert(pos, Equals, Pos{0, 0, 5})
 }
 
 func (s *testLexerSuite) TestOptimizerHint(c *C) {
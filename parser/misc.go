@@ -164,6 +164,7 @@ var tokenMap = map[string]int{
 	"BETWEEN":                    between,
 	"BIN":                        bin,
 	"BINLOG":                     binlog,
+	"BLOCK":                      block,
 	"BOTH":                       both,
 	"BTREE":                      btree,
 	"BY":                         by,
@@ -195,6 +196,7 @@ var tokenMap = map[string]int{
 	"CONNECTION_ID":              connectionID,
 	"CONSTRAINT":                 constraint,
 	"CONSISTENT":                 consistent,
+	"CPU":                        cpu,
 	"CONVERT":                    convert,
 	"COS":                        cos,
 	"COT":                        cot,
@@ -204,6 +206,7 @@ var tokenMap = map[string]int{
 	"CURDATE":                    curDate,
 	"UTC_DATE":                   utcDate,
 	"UTC_TIMESTAMP":              utcTimestamp,
+	"CURRENT":                    current,
 	"CURRENT_DATE":               currentDate,
 	"CURTIME":                    curTime,
 	"CURRENT_TIME":               currentTime,
@@ -226,9 +229,11 @@ var tokenMap = map[string]int{
 	"DEFAULT":                    defaultKwd,
 	"DELAYED":                    delayed,
 	"DELAY_KEY_WRITE":            delayKeyWrite,
+	"DENSE_RANK":                 denseRank,
 	"DELETE":                     deleteKwd,
 	"DESC":                       desc,
 	"DESCRIBE":                   describe,
+	"DIGEST":                     digest,
 	"DISABLE":                    disable,
 	"DISTINCT":                   distinct,
 	"DISTINCTROW":                distinctRow,
@@ -279,6 +284,7 @@ var tokenMap = map[string]int{
 	"FUNCTION":                   function,
 	"FLOOR":                      floor,
 	"FLUSH":                      flush,
+	"FOLLOWING":                  following,
 	"GENERATED":                  generated,
 	"GET_FORMAT":                 getFormat,
 	"GET_LOCK":                   getLock,
@@ -320,6 +326,7 @@ var tokenMap = map[string]int{
 	"LEAST":                      least,
 	"LEFT":                       left,
 	"LENGTH":                     length,
+	"LAST":                       last,
 	"LESS":                       less,
 	"LEVEL":                      level,
 	"LIKE":                       like,
@@ -358,20 +365,24 @@ var tokenMap = map[string]int{
 	"NAMES":                      names,
 	"NATIONAL":                   national,
 	"NONE":                       none,
+	"NULLS":                      nulls,
 	"NOT":                        not,
 	"NO_WRITE_TO_BINLOG":         noWriteToBinLog,
 	"NULL":                       null,
 	"NULLIF":                     nullIf,
 	"OCT":                        oct,
 	"OCTET_LENGTH":               octetLength,
+	"OF":                         of,
 	"OFFSET":                     offset,
 	"ON":                         on,
 	"ONLY":                       only,
+	"OPT_RULES":                  optRules,
 	"OPTION":                     option,
 	"OR":                         or,
 	"ORD":                        ord,
 	"ORDER":                      order,
 	"OUTER":                      outer,
+	"OVER":                       over,
 	"PASSWORD":                   password,
 	"PERIOD_ADD":                 periodAdd,
 	"PERIOD_DIFF":                periodDiff,
@@ -380,6 +391,7 @@ var tokenMap = map[string]int{
 	"POW":                        pow,
 	"POWER":                      power,
 	"PREPARE":                    prepare,
+	"PRE_SPLIT_REGIONS":          preSplitRegions,
 	"PRIMARY":                    primary,
 	"PRIVILEGES":                 privileges,
 	"PROCEDURE":                  procedure,
@@ -391,11 +403,16 @@ var tokenMap = map[string]int{
 	"QUERY":                      query,
 	"QUOTE":                      quote,
 	"RANGE":                      rangeKwd,
+	"RANK":                       rank,
 	"RAND":                       rand,
 	"READ":                       read,
+	"RECOMMEND":                  recommend,
+	"RECENT":                     recent,
+	"RECOVER":                    recoverKwd,
 	"REDUNDANT":                  redundant,
 	"REFERENCES":                 references,
 	"REGEXP":                     regexpKwd,
+	"RELEASE":                    release,
 	"RELEASE_LOCK":               releaseLock,
 	"RENAME":                     rename,
 	"REPEAT":                     repeat,
@@ -407,13 +424,16 @@ var tokenMap = map[string]int{
 	"ROLLBACK":                   rollback,
 	"ROUND":                      round,
 	"ROW":                        row,
+	"ROWS":                       rows,
 	"ROW_FORMAT":                 rowFormat,
 	"RTRIM":                      rtrim,
 	"REVERSE":                    reverse,
 	"SCHEMA":                     schema,
 	"SCHEMAS":                    schemas,
 	"SEC_TO_TIME":                secToTime,
+	"SAVEPOINT":                  savepoint,
 	"SECOND":                     second,
+	"SHARD_ROW_ID_BITS":          shardRowIDBits,
 	"SELECT":                     selectKwd,
 	"SERIALIZABLE":               serializable,
 	"SESSION":                    session,
@@ -424,6 +444,7 @@ var tokenMap = map[string]int{
 	"SLEEP":                      sleep,
 	"SIGN":                       sign,
 	"SIGNED":                     signed,
+	"SLOW":                       slow,
 	"SIN":                        sin,
 	"SNAPSHOT":                   snapshot,
 	"SOME":                       some,
@@ -458,6 +479,7 @@ var tokenMap = map[string]int{
 	"TIME_TO_SEC":                timeToSec,
 	"TIMESTAMPADD":               timestampAdd,
 	"TIMESTAMPDIFF":              timestampDiff,
+	"TOP":                        top,
 	"THAN":                       than,
 	"THEN":                       then,
 	"TO":                         to,
@@ -471,6 +493,8 @@ var tokenMap = map[string]int{
 	"TRIM":                       trim,
 	"TRUE":                       trueKwd,
 	"TRUNCATE":                   truncate,
+	"UNBLOCK":                    unblock,
+	"UNBOUNDED":                  unbounded,
 	"UNCOMMITTED":                uncommitted,
 	"UNKNOWN":                    unknown,
 	"UNION":                      union,
@@ -482,6 +506,7 @@ var tokenMap = map[string]int{
 	"UPPER":                      upper,
 	"UCASE":                      ucase,
 	"UTC_TIME":                   utcTime,
+	"USAGE":                      usage,
 	"USE":                        use,
 	"USER":                       user,
 	"USING":                      using,
@@ -503,6 +528,7 @@ var tokenMap = map[string]int{
 	"YEARWEEK":                   yearweek,
 	"ZEROFILL":                   zerofill,
 	"SQL_CALC_FOUND_ROWS":        calcFoundRows,
+	"SQL":                        sql,
 	"SQL_CACHE":                  sqlCache,
 	"SQL_NO_CACHE":               sqlNoCache,
 	"CURRENT_TIMESTAMP":          currentTs,
@@ -522,6 +548,7 @@ var tokenMap = map[string]int{
 	"FLOAT":                      floatType,
 	"DOUBLE":                     doubleType,
 	"PRECISION":                  precisionType,
+	"PRECEDING":                  preceding,
 	"REAL":                       realType,
 	"DATE":                       dateType,
 	"TIME":                       timeType,
@@ -577,10 +604,13 @@ var tokenMap = map[string]int{
 	"CHAR_LENGTH":                charLength,
 	"CHARACTER_LENGTH":           charLength,
 	"CONV":                       conv,
+	"BIT_AND":                    bitAnd,
+	"BIT_OR":                     bitOr,
 	"BIT_XOR":                    bitXor,
 	"BENCHMARK":                  benchmark,
 	"COERCIBILITY":               coercibility,
 	"ROW_COUNT":                  rowCount,
+	"ROW_NUMBER":                 rowNumber,
 	"SESSION_USER":               sessionUser,
 	"SYSTEM_USER":                systemUser,
 	"CRC32":                      crc32,
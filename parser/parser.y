@@ -169,12 +169,14 @@ import (
 	numericType		"NUMERIC"
 	oct			"OCT"
 	octetLength		"OCTET_LENGTH"
+	of			"OF"
 	on			"ON"
 	option			"OPTION"
 	or			"OR"
 	ord			"ORD"
 	order			"ORDER"
 	outer			"OUTER"
+	over			"OVER"
 	partition		"PARTITION"
 	partitions		"PARTITIONS"
 	position		"POSITION"
@@ -187,6 +189,7 @@ import (
 	realType		"REAL"
 	references		"REFERENCES"
 	regexpKwd		"REGEXP"
+	release			"RELEASE"
 	rename         		"RENAME"
 	repeat			"REPEAT"
 	replace			"REPLACE"
@@ -336,6 +339,9 @@ import (
 	rand				"RAND"
 	radians				"RADIANS"
 	rowCount			"ROW_COUNT"
+	rowNumber			"ROW_NUMBER"
+	rank				"RANK"
+	denseRank			"DENSE_RANK"
 	secToTime			"SEC_TO_TIME"
 	second				"SECOND"
 	sessionUser			"SESSION_USER"
@@ -382,6 +388,8 @@ import (
 	charLength			"CHAR_LENGTH"
 	characterLength			"CHARACTER_LENGTH"
 	conv				"CONV"
+	bitAnd				"BIT_AND"
+	bitOr				"BIT_OR"
 	bitXor				"BIT_XOR"
 	crc32				"CRC32"
 	compress			"COMPRESS"
@@ -430,6 +438,7 @@ import (
 	begin		"BEGIN"
 	binlog		"BINLOG"
 	bitType		"BIT"
+	block		"BLOCK"
 	booleanType	"BOOLEAN"
 	boolType	"BOOL"
 	btree		"BTREE"
@@ -446,11 +455,14 @@ import (
 	compression	"COMPRESSION"
 	connection 	"CONNECTION"
 	consistent	"CONSISTENT"
+	cpu		"CPU"
+	current		"CURRENT"
 	data 		"DATA"
 	dateType	"DATE"
 	datetimeType	"DATETIME"
 	deallocate	"DEALLOCATE"
 	delayKeyWrite	"DELAY_KEY_WRITE"
+	digest		"DIGEST"
 	disable		"DISABLE"
 	do		"DO"
 	duplicate	"DUPLICATE"
@@ -466,6 +478,7 @@ import (
 	first		"FIRST"
 	fixed		"FIXED"
 	flush		"FLUSH"
+	following	"FOLLOWING"
 	full		"FULL"
 	function	"FUNCTION"
 	hash		"HASH"
@@ -474,6 +487,7 @@ import (
 	indexes		"INDEXES"
 	jsonType	"JSON"
 	keyBlockSize	"KEY_BLOCK_SIZE"
+	last		"LAST"
 	local		"LOCAL"
 	less		"LESS"
 	level		"LEVEL"
@@ -485,27 +499,39 @@ import (
 	national	"NATIONAL"
 	no		"NO"
 	none		"NONE"
+	nulls		"NULLS"
 	offset		"OFFSET"
 	only		"ONLY"
+	optRules	"OPT_RULES"
 	password	"PASSWORD"
 	prepare		"PREPARE"
+	preceding	"PRECEDING"
+	preSplitRegions	"PRE_SPLIT_REGIONS"
 	privileges	"PRIVILEGES"
 	processlist	"PROCESSLIST"
 	quarter		"QUARTER"
 	quick		"QUICK"
+	recommend	"RECOMMEND"
+	recent		"RECENT"
+	recoverKwd	"RECOVER"
 	redundant	"REDUNDANT"
 	repeatable	"REPEATABLE"
 	reverse		"REVERSE"
 	rollback	"ROLLBACK"
 	row 		"ROW"
+	rows		"ROWS"
 	rowFormat	"ROW_FORMAT"
+	savepoint	"SAVEPOINT"
 	serializable	"SERIALIZABLE"
 	session		"SESSION"
 	share		"SHARE"
+	shardRowIDBits	"SHARD_ROW_ID_BITS"
 	shared       	"SHARED"
 	signed		"SIGNED"
+	slow		"SLOW"
 	snapshot	"SNAPSHOT"
 	space 		"SPACE"
+	sql		"SQL"
 	sqlCache	"SQL_CACHE"
 	sqlNoCache	"SQL_NO_CACHE"
 	start		"START"
@@ -524,12 +550,16 @@ import (
 	timeType	"TIME"
 	timestampType	"TIMESTAMP"
 	timestampDiff	"TIMESTAMPDIFF"
+	top		"TOP"
 	transaction	"TRANSACTION"
 	trigger		"TRIGGER"
 	triggers	"TRIGGERS"
 	truncate	"TRUNCATE"
+	unblock		"UNBLOCK"
+	unbounded	"UNBOUNDED"
 	uncommitted	"UNCOMMITTED"
 	unknown 	"UNKNOWN"
+	usage		"USAGE"
 	user		"USER"
 	value		"VALUE"
 	variables	"VARIABLES"
@@ -574,6 +604,7 @@ import (
 	AlterUserStmt		"Alter user statement"
 	AnalyzeTableStmt	"Analyze table statement"
 	AnyOrAll		"Any or All for subquery"
+	AsOfClause		"AS OF TIMESTAMP clause"
 	Assignment		"assignment"
 	AssignmentList		"assignment list"
 	AssignmentListOpt	"assignment list opt"
@@ -591,6 +622,9 @@ import (
 	ColumnSetValue		"insert statement set value by column name"
 	ColumnSetValueList	"insert statement set value by column name list"
 	CommitStmt		"COMMIT statement"
+	CommonTableExpr		"common table expression"
+	CommonTableExprList	"common table expression list"
+	CTEColumnListOpt	"optional column name list of common table expression"
 	CompareOp		"Compare opcode"
 	ColumnOption		"column definition option"
 	ColumnOptionList	"column definition option list"
@@ -606,6 +640,8 @@ import (
 	DatabaseOptionList	"CREATE Database specification list"
 	DatabaseOptionListOpt	"CREATE Database specification list opt"
 	CreateTableStmt		"CREATE TABLE statement"
+	CreateTableSelect	"CREATE TABLE ... SELECT query"
+	CreateTableAsSelect	"CREATE TABLE ... [AS] SELECT query"
 	CreateUserStmt		"CREATE User statement"
 	DBName			"Database Name"
 	DeallocateStmt		"Deallocate prepared statement"
@@ -692,6 +728,7 @@ import (
 	NumLiteral		"Num/Int/Float/Decimal Literal"
 	NoWriteToBinLogAliasOpt "NO_WRITE_TO_BINLOG alias LOCAL or empty"
 	NowSymOptionFraction	"NowSym with optional fraction part"
+	NullOrderOpt		"ORDER BY clause optional NULLS FIRST or NULLS LAST"
 	ObjectType		"Grant statement object type"
 	OnDuplicateKeyUpdate	"ON DUPLICATE KEY UPDATE value list"
 	Operand			"operand"
@@ -728,9 +765,11 @@ import (
 	ReplaceIntoStmt		"REPLACE INTO statement"
 	ReplacePriority		"replace statement priority"
 	RevokeStmt		"Revoke statement"
+	ReleaseSavepointStmt	"RELEASE SAVEPOINT statement"
 	RollbackStmt		"ROLLBACK statement"
 	RowFormat		"Row format option"
 	SelectLockOpt		"FOR UPDATE or LOCK IN SHARE MODE,"
+	SavepointStmt		"SAVEPOINT statement"
 	SelectStmt		"SELECT statement"
 	SelectStmtCalcFoundRows	"SELECT statement optional SQL_CALC_FOUND_ROWS"
 	SelectStmtSQLCache	"SELECT statement optional SQL_CAHCE/SQL_NO_CACHE"
@@ -754,6 +793,7 @@ import (
 	StringList 		"string list"
 	ExplainableStmt		"explainable statement"
 	SubSelect		"Sub Select"
+	IdentList		"identifier list"
 	Symbol			"Constraint Symbol"
 	SystemVariable		"System defined variable name"
 	TableAsName		"table alias name"
@@ -773,6 +813,8 @@ import (
 	TableRefs 		"table references"
 	TableToTable 		"rename table to table"
 	TableToTableList 	"rename table to table by list"
+	TopSQLLimitOpt		"the number of the statements returned by admin show top sql"
+	TopSQLOrderOpt		"the order of admin show top sql"
 
 	TransactionChar		"Transaction characteristic"
 	TransactionChars	"Transaction characteristic list"
@@ -799,7 +841,15 @@ import (
 	WhenClause		"When clause"
 	WhenClauseList		"When clause list"
 	WithReadLockOpt		"With Read Lock opt"
+	WithClause		"WITH clause"
+	WithSelectStmt		"SELECT or UNION statement with WITH clause"
 	WithGrantOptionOpt	"With Grant Option opt"
+	WindowFuncCall		"Window function call"
+	WindowSpec		"Window specification in OVER clause"
+	WindowPartitionByOpt	"Optional PARTITION BY of window specification"
+	WindowFrameOpt		"Optional frame of window specification"
+	WindowFrameUnits	"Window frame units"
+	WindowFrameBound	"Window frame bound"
 	ElseOpt			"Optional else clause"
 	ExpressionOpt		"Optional expression"
 	Type			"Types"
@@ -1008,6 +1058,13 @@ AlterTableSpec:
 			Name: $4.(string),
 		}
 	}
+|	"DROP" "CHECK" Symbol
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp: ast.AlterTableDropCheck,
+			Name: $3.(string),
+		}
+	}
 |	"DISABLE" "KEYS"
 	{
 		$$ = &ast.AlterTableSpec{}
@@ -1351,9 +1408,11 @@ ColumnOption:
 	}
 |	"CHECK" '(' Expression ')'
 	{
-		// See https://dev.mysql.com/doc/refman/5.7/en/create-table.html
-		// The CHECK clause is parsed but ignored by all storage engines.
-		$$ = &ast.ColumnOption{}
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $3.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionCheck, Expr: expr}
 	}
 |	GeneratedAlways "AS" '(' Expression ')' VirtualOrStored
 	{
@@ -1478,6 +1537,17 @@ ConstraintElem:
 			Refer:	$7.(*ast.ReferenceDef),
 		}
 	}
+|	"CHECK" '(' Expression ')'
+	{
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $3.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.Constraint{
+			Tp:	ast.ConstraintCheck,
+			Expr:	expr,
+		}
+	}
 
 ReferDef:
 	"REFERENCES" TableName '(' IndexColNameList ')' OnDeleteOpt OnUpdateOpt
@@ -1748,6 +1818,63 @@ CreateTableStmt:
 			IfNotExists:    $3.(bool),
 		}
 	}
+|	"CREATE" "TABLE" IfNotExists TableName CreateTableAsSelect
+	{
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			Select:         $5.(ast.ResultSetNode),
+		}
+	}
+|	"CREATE" "TABLE" IfNotExists TableName TableOptionList CreateTableAsSelect
+	{
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			Options:        $5.([]*ast.TableOption),
+			Select:         $6.(ast.ResultSetNode),
+		}
+	}
+|	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' TableOptionListOpt CreateTableAsSelect
+	{
+		// The columns of the query are appended to the defined ones, so the list may only have indexes.
+		tes := $6.([]interface {})
+		var columnDefs []*ast.ColumnDef
+		var constraints []*ast.Constraint
+		for _, te := range tes {
+			switch te := te.(type) {
+			case *ast.ColumnDef:
+				columnDefs = append(columnDefs, te)
+			case *ast.Constraint:
+				constraints = append(constraints, te)
+			}
+		}
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			Cols:           columnDefs,
+			Constraints:    constraints,
+			Options:        $8.([]*ast.TableOption),
+			Select:         $9.(ast.ResultSetNode),
+		}
+	}
+
+CreateTableAsSelect:
+	CreateTableSelect
+|	"AS" CreateTableSelect
+	{
+		$$ = $2
+	}
+
+CreateTableSelect:
+	SelectStmt
+	{
+		$$ = $1.(*ast.SelectStmt)
+	}
+|	UnionStmt
+	{
+		$$ = $1.(*ast.UnionStmt)
+	}
 
 DefaultKwdOpt:
 	{}
@@ -1954,6 +2081,10 @@ ExplainStmt:
 	{
 		$$ = &ast.ExplainStmt{Stmt: $2.(ast.StmtNode)}
 	}
+|	ExplainSym "FOR" "CONNECTION" NUM
+	{
+		$$ = &ast.ExplainForStmt{ConnectionID: getUint64FromNUM($4)}
+	}
 
 LengthNum:
 	NUM
@@ -2411,7 +2542,7 @@ Identifier | ReservedKeyword
 UnReservedKeyword:
  "ACTION" | "ASCII" | "AUTO_INCREMENT" | "AFTER" | "ALWAYS" | "AT" | "AVG" | "BEGIN" | "BIT" | "BOOL" | "BOOLEAN" | "BTREE" | "CHARSET"
 | "COLUMNS" | "COMMIT" | "COMPACT" | "COMPRESSED" | "CONSISTENT" | "DATA" | "DATE" | "DATETIME" | "DEALLOCATE" | "DO"
-| "DYNAMIC"| "END" | "ENGINE" | "ENGINES" | "ESCAPE" | "EXECUTE" | "FIELDS" | "FIRST" | "FIXED" | "FORMAT" | "FULL" |"GLOBAL"
+| "DYNAMIC"| "END" | "LAST" | "NULLS" | "ENGINE" | "ENGINES" | "ESCAPE" | "EXECUTE" | "FIELDS" | "FIRST" | "FIXED" | "FORMAT" | "FULL" |"GLOBAL"
 | "HASH" | "LESS" | "LOCAL" | "NAMES" | "OFFSET" | "PASSWORD" %prec lowerThanEq | "PREPARE" | "QUICK" | "REDUNDANT"
 | "ROLLBACK" | "SESSION" | "SIGNED" | "SNAPSHOT" | "START" | "STATUS" | "TABLES" | "TEXT" | "THAN" | "TIDB" | "TIME" | "TIMESTAMP"
 | "TRANSACTION" | "TRUNCATE" | "UNKNOWN" | "VALUE" | "WARNINGS" | "YEAR" | "MODE"  | "WEEK"  | "ANY" | "SOME" | "USER" | "IDENTIFIED"
@@ -2420,6 +2551,8 @@ UnReservedKeyword:
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "SPACE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
 | "TIMESTAMPDIFF" | "NONE" | "SUPER" | "SHARED" | "EXCLUSIVE" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS"
+| "BLOCK" | "UNBLOCK" | "DIGEST" | "RECOVER" | "RECOMMEND" | "USAGE" | "CURRENT" | "FOLLOWING" | "PRECEDING" | "ROWS" | "UNBOUNDED"
+| "TOP" | "SQL" | "CPU" | "SAVEPOINT" | "SHARD_ROW_ID_BITS" | "PRE_SPLIT_REGIONS" | "SLOW" | "RECENT" | "OPT_RULES"
 
 ReservedKeyword:
 "ADD" | "ALL" | "ALTER" | "ANALYZE" | "AND" | "AS" | "ASC" | "BETWEEN" | "BIGINT"
@@ -2434,8 +2567,8 @@ ReservedKeyword:
 | "INTERVAL" | "IS" | "JOIN" | "KEY" | "KEYS" | "KILL" | "LEADING" | "LEFT" | "LIKE" | "LIMIT" | "LINES" | "LOAD"
 | "LOCALTIME" | "LOCALTIMESTAMP" | "LOCK" | "LONGBLOB" | "LONGTEXT" | "MAXVALUE" | "MEDIUMBLOB" | "MEDIUMINT" | "MEDIUMTEXT"
 | "MINUTE_MICROSECOND" | "MINUTE_SECOND" | "MOD" | "NOT" | "NO_WRITE_TO_BINLOG" | "NULL" | "NUMERIC"
-| "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ"
-| "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
+| "OF" | "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "OVER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ"
+| "REAL" | "REFERENCES" | "REGEXP" | "RELEASE" | "RENAME" | "REPEAT" | "REPLACE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
 | "SCHEMA" | "SCHEMAS" | "SECOND_MICROSECOND" | "SELECT" | "SET" | "SHOW" | "SMALLINT"
 | "STARTING" | "TABLE" | "STORED" | "TERMINATED" | "THEN" | "TINYBLOB" | "TINYINT" | "TINYTEXT" | "TO"
 | "TRAILING" | "TRIGGER" | "TRUE" | "UNION" | "UNIQUE" | "UNLOCK" | "UNSIGNED"
@@ -2458,6 +2591,7 @@ NotKeywordToken:
 |	"ANY_VALUE" | "INET_ATON" | "INET_NTOA" | "INET6_ATON" | "INET6_NTOA" | "IS_FREE_LOCK" | "IS_IPV4" | "IS_IPV4_COMPAT" | "IS_IPV4_MAPPED" | "IS_IPV6" | "IS_USED_LOCK" | "MASTER_POS_WAIT" | "NAME_CONST" | "RELEASE_ALL_LOCKS" | "UUID" | "UUID_SHORT"
 |	"COMPRESS" | "DECODE" | "DES_DECRYPT" | "DES_ENCRYPT" | "ENCODE" | "ENCRYPT" | "MD5" | "OLD_PASSWORD" | "RANDOM_BYTES" | "SHA1" | "SHA" | "SHA2" | "UNCOMPRESS" | "UNCOMPRESSED_LENGTH" | "VALIDATE_PASSWORD_STRENGTH"
 |	"JSON_EXTRACT" | "JSON_UNQUOTE" | "JSON_TYPE" | "JSON_MERGE" | "JSON_SET" | "JSON_INSERT" | "JSON_REPLACE" | "JSON_REMOVE" | "JSON_OBJECT" | "JSON_ARRAY" | "TIDB_VERSION"
+|	"ROW_NUMBER" | "RANK" | "DENSE_RANK" | "BIT_AND" | "BIT_OR" | "BIT_XOR"
 
 /************************************************************************************
  *
@@ -2626,6 +2760,10 @@ Literal:
 	{
 		tp := types.NewFieldType(mysql.TypeString)
 		tp.Charset, tp.Collate = parser.charset, parser.collation
+		if tp.Charset == charset.CharsetBin {
+			// The literals are binary strings after SET NAMES binary.
+			tp.Flag |= mysql.BinaryFlag
+		}
 		expr := ast.NewValueExpr($1)
 		expr.SetType(tp)
 		$$ = expr
@@ -2717,7 +2855,7 @@ ByList:
 	}
 
 ByItem:
-	Expression Order
+	Expression Order NullOrderOpt
 	{
 		expr := $1
 		valueExpr, ok := expr.(*ast.ValueExpr)
@@ -2727,7 +2865,21 @@ ByItem:
 				expr = &ast.PositionExpr{N: int(position)}
 			}
 		}
-		$$ = &ast.ByItem{Expr: expr.(ast.ExprNode), Desc: $2.(bool)}
+		$$ = &ast.ByItem{Expr: expr.(ast.ExprNode), Desc: $2.(bool), NullOrder: $3.(ast.NullOrderType)}
+	}
+
+NullOrderOpt:
+	/* EMPTY */
+	{
+		$$ = ast.NullOrderDefault
+	}
+|	"NULLS" "FIRST"
+	{
+		$$ = ast.NullsFirst
+	}
+|	"NULLS" "LAST"
+	{
+		$$ = ast.NullsLast
 	}
 
 Order:
@@ -2796,6 +2948,7 @@ Function:
 |	FunctionCallNonKeyword
 |	FunctionCallConflict
 |	FunctionCallAgg
+|	WindowFuncCall
 |	Identifier jss stringLit
 	{
 	    col := &ast.ColumnNameExpr{Name: &ast.ColumnName{Name: model.NewCIStr($1)}}
@@ -3882,6 +4035,14 @@ FunctionCallAgg:
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
 	}
+|	"BIT_AND" '(' Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
+|	"BIT_OR" '(' Expression ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+	}
 |	"BIT_XOR" '(' Expression ')'
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}}
@@ -3920,6 +4081,92 @@ FunctionCallAgg:
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
 	}
 
+WindowFuncCall:
+	FunctionCallAgg "OVER" WindowSpec
+	{
+		agg := $1.(*ast.AggregateFuncExpr)
+		$$ = &ast.WindowFuncExpr{F: agg.F, Args: agg.Args, Distinct: agg.Distinct, Spec: $3.(ast.WindowSpec)}
+	}
+|	"ROW_NUMBER" '(' ')' "OVER" WindowSpec
+	{
+		$$ = &ast.WindowFuncExpr{F: ast.WindowFuncRowNumber, Spec: $5.(ast.WindowSpec)}
+	}
+|	"RANK" '(' ')' "OVER" WindowSpec
+	{
+		$$ = &ast.WindowFuncExpr{F: ast.WindowFuncRank, Spec: $5.(ast.WindowSpec)}
+	}
+|	"DENSE_RANK" '(' ')' "OVER" WindowSpec
+	{
+		$$ = &ast.WindowFuncExpr{F: ast.WindowFuncDenseRank, Spec: $5.(ast.WindowSpec)}
+	}
+
+WindowSpec:
+	'(' WindowPartitionByOpt OrderByOptional WindowFrameOpt ')'
+	{
+		spec := ast.WindowSpec{PartitionBy: $2.([]ast.ExprNode)}
+		if $3 != nil {
+			spec.OrderBy = $3.(*ast.OrderByClause).Items
+		}
+		if $4 != nil {
+			spec.Frame = $4.(*ast.FrameClause)
+		}
+		$$ = spec
+	}
+
+WindowPartitionByOpt:
+	{
+		$$ = []ast.ExprNode(nil)
+	}
+|	"PARTITION" "BY" ExpressionList
+	{
+		$$ = $3
+	}
+
+WindowFrameOpt:
+	{
+		$$ = nil
+	}
+|	WindowFrameUnits WindowFrameBound
+	{
+		$$ = &ast.FrameClause{Type: $1.(ast.FrameType), Start: $2.(ast.FrameBound), End: ast.FrameBound{Type: ast.CurrentRow}}
+	}
+|	WindowFrameUnits "BETWEEN" WindowFrameBound "AND" WindowFrameBound
+	{
+		$$ = &ast.FrameClause{Type: $1.(ast.FrameType), Start: $3.(ast.FrameBound), End: $5.(ast.FrameBound)}
+	}
+
+WindowFrameUnits:
+	"ROWS"
+	{
+		$$ = ast.FrameRows
+	}
+|	"RANGE"
+	{
+		$$ = ast.FrameRange
+	}
+
+WindowFrameBound:
+	"UNBOUNDED" "PRECEDING"
+	{
+		$$ = ast.FrameBound{Type: ast.Preceding, UnBounded: true}
+	}
+|	"UNBOUNDED" "FOLLOWING"
+	{
+		$$ = ast.FrameBound{Type: ast.Following, UnBounded: true}
+	}
+|	LengthNum "PRECEDING"
+	{
+		$$ = ast.FrameBound{Type: ast.Preceding, Offset: $1.(uint64)}
+	}
+|	LengthNum "FOLLOWING"
+	{
+		$$ = ast.FrameBound{Type: ast.Following, Offset: $1.(uint64)}
+	}
+|	"CURRENT" "ROW"
+	{
+		$$ = ast.FrameBound{Type: ast.CurrentRow}
+	}
+
 FuncDatetimePrec:
 	{
 		$$ = nil
@@ -4396,6 +4643,29 @@ RollbackStmt:
 	{
 		$$ = &ast.RollbackStmt{}
 	}
+|	"ROLLBACK" "TO" Identifier
+	{
+		$$ = &ast.RollbackStmt{SavepointName: $3}
+	}
+|	"ROLLBACK" "TO" "SAVEPOINT" Identifier
+	{
+		$$ = &ast.RollbackStmt{SavepointName: $4}
+	}
+
+/*
+ * See https://dev.mysql.com/doc/refman/5.7/en/savepoint.html
+ */
+SavepointStmt:
+	"SAVEPOINT" Identifier
+	{
+		$$ = &ast.SavepointStmt{Name: $2}
+	}
+
+ReleaseSavepointStmt:
+	"RELEASE" "SAVEPOINT" Identifier
+	{
+		$$ = &ast.ReleaseSavepointStmt{Name: $3}
+	}
 
 SelectStmt:
 	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtLimit SelectLockOpt
@@ -4550,6 +4820,12 @@ TableFactor:
 		tn.IndexHints = $3.([]*ast.IndexHint)
 		$$ = &ast.TableSource{Source: tn, AsName: $2.(model.CIStr)}
 	}
+|	TableName AsOfClause TableAsNameOpt IndexHintListOpt
+	{
+		tn := $1.(*ast.TableName)
+		tn.IndexHints = $4.([]*ast.IndexHint)
+		$$ = &ast.TableSource{Source: tn, AsName: $3.(model.CIStr), AsOf: $2.(*ast.AsOfClause)}
+	}
 |	'(' SelectStmt ')' TableAsName
 	{
 		st := $2.(*ast.SelectStmt)
@@ -4566,6 +4842,12 @@ TableFactor:
 		$$ = $2
 	}
 
+AsOfClause:
+	"AS" "OF" "TIMESTAMP" Expression
+	{
+		$$ = &ast.AsOfClause{TsExpr: $4.(ast.ExprNode)}
+	}
+
 TableAsNameOpt:
 	{
 		$$ = model.CIStr{}
@@ -4953,6 +5235,66 @@ UnionOpt:
 DefaultTrueDistinctOpt
 
 
+// See https://dev.mysql.com/doc/refman/8.0/en/with.html
+WithSelectStmt:
+	WithClause SelectStmt
+	{
+		st := $2.(*ast.SelectStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+|	WithClause UnionStmt
+	{
+		st := $2.(*ast.UnionStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+
+WithClause:
+	"WITH" CommonTableExprList
+	{
+		$$ = &ast.WithClause{CTEs: $2.([]*ast.CommonTableExpression)}
+	}
+
+CommonTableExprList:
+	CommonTableExpr
+	{
+		$$ = []*ast.CommonTableExpression{$1.(*ast.CommonTableExpression)}
+	}
+|	CommonTableExprList ',' CommonTableExpr
+	{
+		$$ = append($1.([]*ast.CommonTableExpression), $3.(*ast.CommonTableExpression))
+	}
+
+CommonTableExpr:
+	Identifier CTEColumnListOpt "AS" SubSelect
+	{
+		$$ = &ast.CommonTableExpression{
+			Name:		model.NewCIStr($1),
+			ColNames:	$2.([]model.CIStr),
+			Query:		$4.(*ast.SubqueryExpr).Query,
+		}
+	}
+
+CTEColumnListOpt:
+	{
+		$$ = []model.CIStr(nil)
+	}
+|	'(' IdentList ')'
+	{
+		$$ = $2.([]model.CIStr)
+	}
+
+IdentList:
+	Identifier
+	{
+		$$ = []model.CIStr{model.NewCIStr($1)}
+	}
+|	IdentList ',' Identifier
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr($3))
+	}
+
 /********************Set Statement*******************************/
 SetStmt:
 	"SET" VariableAssignmentList
@@ -5106,7 +5448,7 @@ VariableAssignment:
 |	CharsetKw CharsetName
 	{
 		$$ = &ast.VariableAssignment{
-			Name: ast.SetNames,
+			Name: ast.SetCharset,
 			Value: ast.NewValueExpr($2.(string)),
 		}
 	}
@@ -5210,6 +5552,33 @@ AdminStmt:
 	{
 		$$ = &ast.AdminStmt{Tp: ast.AdminShowDDL}
 	}
+|	"ADMIN" "SHOW" "INDEX" "USAGE"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminShowIndexUsage}
+	}
+|	"ADMIN" "SHOW" "TOP" "SQL" TopSQLOrderOpt TopSQLLimitOpt
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminShowTopSQL,
+			TopSQLByTime:	$5.(bool),
+			TopSQLCount:	$6.(uint64),
+		}
+	}
+|	"ADMIN" "SHOW" "SLOW" "RECENT" LengthNum
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminShowSlow,
+			ShowSlowCount:	$5.(uint64),
+		}
+	}
+|	"ADMIN" "SHOW" "SLOW" "TOP" LengthNum
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminShowSlow,
+			ShowSlowTop:	true,
+			ShowSlowCount:	$5.(uint64),
+		}
+	}
 |	"ADMIN" "CHECK" "TABLE" TableNameList
 	{
 		$$ = &ast.AdminStmt{
@@ -5217,6 +5586,82 @@ AdminStmt:
 			Tables: $4.([]*ast.TableName),
 		}
 	}
+|	"ADMIN" "BLOCK" "DIGEST" StringList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	 ast.AdminBlockDigests,
+			Digests: $4.([]string),
+		}
+	}
+|	"ADMIN" "UNBLOCK" "DIGEST" StringList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	 ast.AdminUnblockDigests,
+			Digests: $4.([]string),
+		}
+	}
+|	"ADMIN" "UNBLOCK" "ALL"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminUnblockAllDigests}
+	}
+|	"ADMIN" "RECOVER" "AUTO_INCREMENT" TableNameList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminRecoverAutoIncrement,
+			Tables: $4.([]*ast.TableName),
+		}
+	}
+|	"ADMIN" "RECOMMEND" "INDEX" SelectStmt
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminRecommendIndex,
+			Stmt:	$4.(ast.StmtNode),
+		}
+	}
+|	"ADMIN" "RECOMMEND" "INDEX" UnionStmt
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminRecommendIndex,
+			Stmt:	$4.(ast.StmtNode),
+		}
+	}
+|	"ADMIN" "ENABLE" "LOG" "FOR" "CONNECTION" NUM "TO" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminEnableSQLLog,
+			ConnectionID:	getUint64FromNUM($6),
+			LogFile:	$8,
+		}
+	}
+|	"ADMIN" "DISABLE" "LOG" "FOR" "CONNECTION" NUM
+	{
+		$$ = &ast.AdminStmt{
+			Tp:		ast.AdminDisableSQLLog,
+			ConnectionID:	getUint64FromNUM($6),
+		}
+	}
+
+TopSQLOrderOpt:
+	{
+		$$ = false
+	}
+|	"BY" "CPU"
+	{
+		$$ = false
+	}
+|	"BY" "TIME"
+	{
+		$$ = true
+	}
+
+TopSQLLimitOpt:
+	{
+		$$ = uint64(0)
+	}
+|	"LIMIT" LengthNum
+	{
+		$$ = $2.(uint64)
+	}
 
 /****************************Show Statement*******************************/
 ShowStmt:
@@ -5307,6 +5752,20 @@ ShowStmt:
 		}
 		$$ = stmt
 	}
+|	"SHOW" "OPT_RULES" ShowLikeOrWhereOpt
+	{
+		stmt := &ast.ShowStmt{
+			Tp: ast.ShowOptRules,
+		}
+		if $3 != nil {
+			if x, ok := $3.(*ast.PatternLikeExpr); ok {
+				stmt.Pattern = x
+			} else {
+				stmt.Where = $3.(ast.ExprNode)
+			}
+		}
+		$$ = stmt
+	}
 
 ShowIndexKwd:
 	"INDEX"
@@ -5582,12 +6041,15 @@ Statement:
 |	KillStmt
 |	LoadDataStmt
 |	PreparedStmt
+|	ReleaseSavepointStmt
 |	RollbackStmt
 |	RenameTableStmt
 |	ReplaceIntoStmt
 |	RevokeStmt
+|	SavepointStmt
 |	SelectStmt
 |	UnionStmt
+|	WithSelectStmt
 |	SetStmt
 |	ShowStmt
 |	TruncateTableStmt
@@ -5609,6 +6071,7 @@ ExplainableStmt:
 |	InsertIntoStmt
 |	ReplaceIntoStmt
 |	UnionStmt
+|	WithSelectStmt
 
 StatementList:
 	Statement
@@ -5651,11 +6114,6 @@ TableElement:
 	{
 		$$ = $1.(*ast.Constraint)
 	}
-|	"CHECK" '(' Expression ')'
-	{
-		/* Nothing to do now */
-		$$ = nil
-	}
 
 TableElementList:
 	TableElement
@@ -5744,6 +6202,14 @@ TableOption:
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionStatsPersistent}
 	}
+|	"SHARD_ROW_ID_BITS" EqOpt LengthNum
+	{
+		$$ = &ast.TableOption{Tp: ast.TableOptionShardRowID, UintValue: $3.(uint64)}
+	}
+|	"PRE_SPLIT_REGIONS" EqOpt LengthNum
+	{
+		$$ = &ast.TableOption{Tp: ast.TableOptionPreSplitRegion, UintValue: $3.(uint64)}
+	}
 
 StatsPersistentVal:
 	"DEFAULT"
@@ -6255,6 +6721,7 @@ UpdateStmt:
 		}
 		st := &ast.UpdateStmt{
 			LowPriority:	$2.(bool),
+			Ignore:		$3.(bool),
 			TableRefs:	&ast.TableRefsClause{TableRefs: refs},
 			List:		$6.([]*ast.Assignment),
 		}
@@ -6273,6 +6740,7 @@ UpdateStmt:
 	{
 		st := &ast.UpdateStmt{
 			LowPriority:	$2.(bool),
+			Ignore:		$3.(bool),
 			TableRefs:	&ast.TableRefsClause{TableRefs: $4.(*ast.Join)},
 			List:		$6.([]*ast.Assignment),
 		}
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
@@ -53,14 +54,14 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"interval", "is", "join", "key", "keys", "kill", "leading", "left", "like", "limit", "lines", "load",
 		"localtime", "localtimestamp", "lock", "longblob", "longtext", "mediumblob", "maxvalue", "mediumint", "mediumtext",
 		"minute_microsecond", "minute_second", "mod", "not", "no_write_to_binlog", "null", "numeric",
-		"on", "option", "or", "order", "outer", "partition", "precision", "primary", "procedure", "range", "read", "real",
+		"on", "option", "or", "order", "outer", "over", "partition", "precision", "primary", "procedure", "range", "read", "real",
 		"references", "regexp", "rename", "repeat", "replace", "revoke", "restrict", "right", "rlike",
 		"schema", "schemas", "second_microsecond", "select", "set", "show", "smallint",
 		"starting", "table", "terminated", "then", "tinyblob", "tinyint", "tinytext", "to",
 		"trailing", "true", "union", "unique", "unlock", "unsigned",
 		"update", "use", "using", "utc_date", "values", "varbinary", "varchar",
 		"when", "where", "write", "xor", "year_month", "zerofill",
-		"generated", "virtual", "stored",
+		"generated", "virtual", "stored", "release",
 		// TODO: support the following keywords
 		// "delayed" , "high_priority" , "low_priority", "with",
 	}
@@ -82,7 +83,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 	unreservedKws := []string{
 		"auto_increment", "after", "begin", "bit", "bool", "boolean", "charset", "columns", "commit",
 		"date", "datediff", "datetime", "deallocate", "do", "from_days", "end", "engine", "engines", "execute", "first", "full",
-		"local", "names", "offset", "password", "prepare", "quick", "rollback", "session", "signed",
+		"local", "names", "offset", "password", "prepare", "quick", "rollback", "savepoint", "session", "signed", "shard_row_id_bits", "pre_split_regions",
 		"start", "global", "tables", "text", "time", "timestamp", "tidb", "transaction", "truncate", "unknown",
 		"value", "warnings", "year", "now", "substr", "substring", "mode", "any", "some", "user", "identified",
 		"collation", "comment", "avg_row_length", "checksum", "compression", "connection", "key_block_size",
@@ -93,7 +94,8 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
 		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
 		"ln", "log", "log2", "log10", "timestampdiff", "pi", "quote", "none", "super", "default", "shared", "exclusive",
-		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version",
+		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version", "block", "unblock", "digest",
+		"rows", "current", "preceding", "following", "unbounded", "row_number", "rank", "dense_rank",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -254,6 +256,13 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		// 45
 		{"COMMIT", true},
 		{"ROLLBACK", true},
+		{"SAVEPOINT sp1", true},
+		{"SAVEPOINT", false},
+		{"ROLLBACK TO sp1", true},
+		{"ROLLBACK TO SAVEPOINT sp1", true},
+		{"ROLLBACK TO", false},
+		{"RELEASE SAVEPOINT sp1", true},
+		{"RELEASE sp1", false},
 		{`BEGIN;
 			INSERT INTO foo VALUES (42, 3.14);
 			INSERT INTO foo VALUES (-1, 2.78);
@@ -325,6 +334,39 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		// for admin
 		{"admin show ddl;", true},
 		{"admin check table t1, t2;", true},
+		{"admin block digest 'abc';", true},
+		{"admin block digest 'abc', 'def';", true},
+		{"admin block digest;", false},
+		{"admin unblock digest 'abc', 'def';", true},
+		{"admin unblock all;", true},
+		{"admin recover auto_increment t1;", true},
+		{"admin recover auto_increment t1, test.t2;", true},
+		{"admin recover auto_increment;", false},
+		{"admin recommend index select * from t where a = 1;", true},
+		{"admin recommend index select a from t1 union select b from t2;", true},
+		{"admin recommend index insert into t values (1);", false},
+		{"admin recommend index;", false},
+		{"admin show index usage;", true},
+		{"admin enable log for connection 1 to '/tmp/conn1.log';", true},
+		{"admin enable log for connection 1;", false},
+		{"admin enable log for connection 1 to a;", false},
+		{"admin disable log for connection 1;", true},
+		{"admin disable log for connection;", false},
+		{"admin show index;", false},
+		{"create table usage (usage int);", true},
+		{"admin show top sql;", true},
+		{"admin show top sql by cpu;", true},
+		{"admin show top sql by time limit 5;", true},
+		{"admin show top sql limit 5;", true},
+		{"admin show top sql by memory;", false},
+		{"admin show top sql limit;", false},
+		{"create table top (sql int, cpu int);", true},
+		{"admin show slow recent 3;", true},
+		{"admin show slow top 10;", true},
+		{"admin show slow;", false},
+		{"admin show slow recent;", false},
+		{"admin show slow top -1;", false},
+		{"create table slow (recent int);", true},
 
 		// for on duplicate key update
 		{"INSERT INTO t (a,b,c) VALUES (1,2,3),(4,5,6) ON DUPLICATE KEY UPDATE c=VALUES(a)+VALUES(b);", true},
@@ -425,6 +467,10 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		// for show stats_buckets
 		{"show stats_buckets", true},
 		{"show stats_buckets where col_name = 'a'", true},
+		// for show opt_rules
+		{"show opt_rules", true},
+		{"show opt_rules like 'predicate%'", true},
+		{"show opt_rules where disabled = 1", true},
 
 		// set
 		// user defined
@@ -1026,6 +1072,12 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`select bit_xor(), bit_xor(distinct c1) from t;`, false},
 		{`select bit_xor(), bit_xor(distinctrow c1) from t;`, false},
 		{`select bit_xor(), bit_xor(all c1) from t;`, false},
+		{`select bit_and(c1) from t;`, true},
+		{`select bit_and(), bit_and(distinct c1) from t;`, false},
+		{`select bit_or(c1) from t;`, true},
+		{`select bit_or(), bit_or(distinct c1) from t;`, false},
+		{`select bit_and(c1), bit_or(c2), bit_xor(c3) from t group by c4;`, true},
+		{`select bit_and, bit_or, bit_xor from t;`, true},
 		{`select max(c1,c2) from t;`, false},
 		{`select max(distinct c1) from t;`, true},
 		{`select max(distinctrow c1) from t;`, true},
@@ -1163,6 +1215,14 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"CREATE TABLE foo (a TINYINT, b SMALLINT) CREATE TABLE bar (x INT, y int64)", false},
 		{"CREATE TABLE foo (a int, b float); CREATE TABLE bar (x double, y float)", true},
 		{"CREATE TABLE foo (a bytes)", false},
+		{"CREATE TABLE foo SELECT * FROM bar", true},
+		{"CREATE TABLE IF NOT EXISTS foo AS SELECT a, b + 1 AS c FROM bar WHERE a > 1", true},
+		{"CREATE TABLE foo AS SELECT a FROM bar UNION SELECT a FROM baz", true},
+		{"CREATE TABLE foo AS", false},
+		{"CREATE TABLE foo ENGINE = InnoDB DEFAULT CHARSET = utf8 SELECT * FROM bar", true},
+		{"CREATE TABLE foo (a INT NOT NULL, c VARCHAR(10), PRIMARY KEY (a)) COMMENT 'foo' AS SELECT a, b FROM bar", true},
+		{"CREATE TABLE foo (PRIMARY KEY (a)) SELECT a FROM bar", true},
+		{"CREATE TABLE foo () SELECT a FROM bar", false},
 		{"CREATE TABLE foo (a SMALLINT UNSIGNED, b INT UNSIGNED)", true},
 		{"CREATE TABLE foo (a SMALLINT UNSIGNED, b INT UNSIGNED) -- foo", true},
 		// {"CREATE TABLE foo (a SMALLINT UNSIGNED, b INT UNSIGNED) // foo", true},
@@ -1203,6 +1263,11 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"create table t (c int) STATS_PERSISTENT = default", true},
 		{"create table t (c int) STATS_PERSISTENT = 0", true},
 		{"create table t (c int) STATS_PERSISTENT = 1", true},
+		{"create table t (c int) SHARD_ROW_ID_BITS = 4 PRE_SPLIT_REGIONS = 2", true},
+		{"create table t (c int) /*T! SHARD_ROW_ID_BITS=4 PRE_SPLIT_REGIONS=2 */", true},
+		{"create table t (c int) shard_row_id_bits 4", true},
+		{"create table t (c int) pre_split_regions = -1", false},
+		{"alter table t shard_row_id_bits = 4", true},
 		// partition option
 		{"create table t (c int) PARTITION BY HASH (c) PARTITIONS 32;", true},
 		{"create table t (c int) PARTITION BY RANGE (Year(VDate)) (PARTITION p1980 VALUES LESS THAN (1980) ENGINE = MyISAM, PARTITION p1990 VALUES LESS THAN (1990) ENGINE = MyISAM, PARTITION pothers VALUES LESS THAN MAXVALUE ENGINE = MyISAM)", true},
@@ -1211,6 +1276,13 @@ func (s *testParserSuite) TestDDL(c *C) {
 		// for check clause
 		{"create table t (c1 bool, c2 bool, check (c1 in (0, 1)), check (c2 in (0, 1)))", true},
 		{"CREATE TABLE Customer (SD integer CHECK (SD > 0), First_Name varchar(30));", true},
+		{"create table t (a int, b int, constraint chk_ab check (a < b))", true},
+		{"create table t (a int, constraint check (a > 0))", true},
+		{"create table t (a int, check ())", false},
+		{"alter table t add check (a > 0)", true},
+		{"alter table t add constraint chk_a check (a > 0 and a < 10)", true},
+		{"alter table t drop check chk_a", true},
+		{"alter table t drop check", false},
 
 		{"create database xxx", true},
 		{"create database if exists xxx", false},
@@ -1600,6 +1672,43 @@ func (s *testParserSuite) TestUnion(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestCommonTableExpression(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"with c as (select 1) select * from c", true},
+		{"with c (a, b) as (select 1, 2) select a, b from c", true},
+		{"with c1 as (select a from t), c2 as (select b from t) select * from c1 join c2 on c1.a = c2.b", true},
+		{"with c as (select 1 union select 2) select * from c", true},
+		{"with c as (select 1) select * from c union select * from c", true},
+		{"with c as (select 1) (select * from c) union (select * from c) order by 1 limit 1", true},
+		{"explain with c as (select 1) select * from c", true},
+		{"with c as (select * from t where a in (select a from t1)) select * from c", true},
+		{"with c as select 1 select * from c", false},
+		{"with c () as (select 1) select * from c", false},
+		{"with c as (select 1)", false},
+		{"with c as (select 1) insert into t select * from c", false},
+		{"with as (select 1) select 1", false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("with c1 (x, y) as (select 1, 2), c2 as (select 3 union select 4) select * from c1, c2", "", "")
+	c.Assert(err, IsNil)
+	sel := stmt.(*ast.SelectStmt)
+	c.Assert(sel.With, NotNil)
+	c.Assert(sel.With.CTEs, HasLen, 2)
+	c.Assert(sel.With.CTEs[0].Name.L, Equals, "c1")
+	c.Assert(sel.With.CTEs[0].ColNames, DeepEquals, []model.CIStr{model.NewCIStr("x"), model.NewCIStr("y")})
+	c.Assert(sel.With.CTEs[0].Query, FitsTypeOf, &ast.SelectStmt{})
+	c.Assert(sel.With.CTEs[1].Name.L, Equals, "c2")
+	c.Assert(sel.With.CTEs[1].ColNames, IsNil)
+	c.Assert(sel.With.CTEs[1].Query, FitsTypeOf, &ast.UnionStmt{})
+
+	stmt, err = parser.ParseOneStmt("with c as (select 1) select * from c union select 2", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.UnionStmt).With.CTEs, HasLen, 1)
+}
+
 func (s *testParserSuite) TestLikeEscape(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -1647,6 +1756,104 @@ func (s *testParserSuite) TestIndexHint(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestAsOfTimestamp(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{`select * from t as of timestamp '2017-11-11 00:00:00'`, true},
+		{`select * from t as of timestamp '2017-11-11 00:00:00' as t1`, true},
+		{`select * from t as of timestamp '2017-11-11 00:00:00' t1 use index (idx)`, true},
+		{`select * from t as of timestamp date_sub(now(), interval 10 second) where a = 1`, true},
+		{`select * from t1 as of timestamp ?, t2 as of timestamp ?`, true},
+		{`select * from t as of timestamp '2017-11-11 00:00:00' join t2 on t.a = t2.a`, true},
+		{`select * from t as of '2017-11-11 00:00:00'`, false},
+		{`select * from t as of`, false},
+		{`select * from (select * from t) as of timestamp '2017-11-11 00:00:00'`, false},
+		{`select * from t as t1 as of timestamp '2017-11-11 00:00:00'`, false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.Parse("select * from t as of timestamp '2017-11-11 00:00:00' t1", "", "")
+	c.Assert(err, IsNil)
+	ts := stmt[0].(*ast.SelectStmt).From.TableRefs.Left.(*ast.TableSource)
+	c.Assert(ts.AsName.L, Equals, "t1")
+	c.Assert(ts.AsOf, NotNil)
+	c.Assert(ts.AsOf.TsExpr.GetValue(), Equals, "2017-11-11 00:00:00")
+}
+
+func (s *testParserSuite) TestNullOrder(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{`select * from t order by a nulls first`, true},
+		{`select * from t order by a asc nulls last`, true},
+		{`select * from t order by a desc nulls first, b nulls last`, true},
+		{`select * from t order by a desc nulls last limit 10`, true},
+		{`update t set a = 1 order by a nulls first limit 1`, true},
+		{`delete from t order by a desc nulls last limit 1`, true},
+		{`select nulls, last from t order by nulls nulls last`, true},
+		{`select * from t order by a nulls`, false},
+		{`select * from t order by a nulls first desc`, false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.Parse("select * from t order by a, b desc nulls first, c nulls last", "", "")
+	c.Assert(err, IsNil)
+	items := stmt[0].(*ast.SelectStmt).OrderBy.Items
+	c.Assert(items, HasLen, 3)
+	c.Assert(items[0].NullOrder, Equals, ast.NullOrderDefault)
+	c.Assert(items[1].Desc, IsTrue)
+	c.Assert(items[1].NullOrder, Equals, ast.NullsFirst)
+	c.Assert(items[2].Desc, IsFalse)
+	c.Assert(items[2].NullOrder, Equals, ast.NullsLast)
+}
+
+func (s *testParserSuite) TestWindowFunction(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{`select row_number() over () from t`, true},
+		{`select a, row_number() over (partition by b order by c desc) from t`, true},
+		{`select rank() over (order by a), dense_rank() over (partition by a, b order by c nulls last) from t`, true},
+		{`select sum(a) over (partition by b order by c) as s from t`, true},
+		{`select count(*) over (), avg(a) over (partition by b) from t`, true},
+		{`select max(a) over (order by b rows 2 preceding) from t`, true},
+		{`select min(a) over (order by b rows between 1 preceding and 1 following) from t`, true},
+		{`select sum(a) over (order by b rows between unbounded preceding and current row) from t`, true},
+		{`select sum(a) over (order by b range between current row and unbounded following) from t`, true},
+		{`select sum(a) over (partition by b) + 1 from t order by 1`, true},
+		{`select rank, rows, current, preceding from t`, true},
+		{`select row_number() from t`, false},
+		{`select row_number(a) over () from t`, false},
+		{`select sum(a) over from t`, false},
+		{`select sum(a) over (rows between 1 preceding) from t`, false},
+		{`select sum(a) over (order by b rows a preceding) from t`, false},
+		{`select abs(a) over () from t`, false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("select sum(a) over (partition by b, c order by d desc rows between 2 preceding and unbounded following) from t", "", "")
+	c.Assert(err, IsNil)
+	win := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.WindowFuncExpr)
+	c.Assert(win.F, Equals, ast.AggFuncSum)
+	c.Assert(win.Args, HasLen, 1)
+	c.Assert(win.Spec.PartitionBy, HasLen, 2)
+	c.Assert(win.Spec.OrderBy, HasLen, 1)
+	c.Assert(win.Spec.OrderBy[0].Desc, IsTrue)
+	c.Assert(win.Spec.Frame.Type, Equals, ast.FrameRows)
+	c.Assert(win.Spec.Frame.Start, Equals, ast.FrameBound{Type: ast.Preceding, Offset: 2})
+	c.Assert(win.Spec.Frame.End, Equals, ast.FrameBound{Type: ast.Following, UnBounded: true})
+
+	stmt, err = parser.ParseOneStmt("select row_number() over (order by a range unbounded preceding) from t", "", "")
+	c.Assert(err, IsNil)
+	win = stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.WindowFuncExpr)
+	c.Assert(win.F, Equals, ast.WindowFuncRowNumber)
+	c.Assert(win.Spec.PartitionBy, HasLen, 0)
+	c.Assert(win.Spec.Frame.Type, Equals, ast.FrameRange)
+	c.Assert(win.Spec.Frame.Start, Equals, ast.FrameBound{Type: ast.Preceding, UnBounded: true})
+	c.Assert(win.Spec.Frame.End, Equals, ast.FrameBound{Type: ast.CurrentRow})
+}
+
 func (s *testParserSuite) TestPriority(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -1664,6 +1871,51 @@ func (s *testParserSuite) TestPriority(c *C) {
 	c.Assert(sel.SelectStmtOpts.Priority, Equals, mysql.HighPriority)
 }
 
+func (s *testParserSuite) TestAdminShowTopSQL(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		src    string
+		byTime bool
+		count  uint64
+	}{
+		{"admin show top sql", false, 0},
+		{"admin show top sql by cpu limit 3", false, 3},
+		{"admin show top sql by time", true, 0},
+		{"ADMIN SHOW TOP SQL BY TIME LIMIT 20", true, 20},
+	}
+	parser := New()
+	for _, tt := range tests {
+		stmt, err := parser.ParseOneStmt(tt.src, "", "")
+		c.Assert(err, IsNil, Commentf("source %s", tt.src))
+		as := stmt.(*ast.AdminStmt)
+		c.Assert(as.Tp, Equals, ast.AdminStmtType(ast.AdminShowTopSQL))
+		c.Assert(as.TopSQLByTime, Equals, tt.byTime, Commentf("source %s", tt.src))
+		c.Assert(as.TopSQLCount, Equals, tt.count, Commentf("source %s", tt.src))
+	}
+}
+
+func (s *testParserSuite) TestAdminShowSlow(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		src   string
+		top   bool
+		count uint64
+	}{
+		{"admin show slow recent 3", false, 3},
+		{"admin show slow top 10", true, 10},
+		{"ADMIN SHOW SLOW TOP 1", true, 1},
+	}
+	parser := New()
+	for _, tt := range tests {
+		stmt, err := parser.ParseOneStmt(tt.src, "", "")
+		c.Assert(err, IsNil, Commentf("source %s", tt.src))
+		as := stmt.(*ast.AdminStmt)
+		c.Assert(as.Tp, Equals, ast.AdminStmtType(ast.AdminShowSlow))
+		c.Assert(as.ShowSlowTop, Equals, tt.top, Commentf("source %s", tt.src))
+		c.Assert(as.ShowSlowCount, Equals, tt.count, Commentf("source %s", tt.src))
+	}
+}
+
 func (s *testParserSuite) TestEscape(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -1716,6 +1968,10 @@ func (s *testParserSuite) TestExplain(c *C) {
 		{"explain replace into foo values (1 || 2)", true},
 		{"explain update t set id = id + 1 order by id desc;", true},
 		{"explain select c1 from t1 union (select c2 from t2) limit 1, 1", true},
+		{"explain for connection 42", true},
+		{"desc for connection 42", true},
+		{"explain for connection", false},
+		{"explain for connection 'a'", false},
 	}
 	s.RunTest(c, table)
 }
@@ -1821,6 +2077,31 @@ func (s *testParserSuite) TestDDLStatements(c *C) {
 	}
 }
 
+func (s *testParserSuite) TestSetNames(c *C) {
+	parser := New()
+	stmt, err := parser.ParseOneStmt("SET NAMES utf8 COLLATE utf8_general_ci, CHARACTER SET latin1", "", "")
+	c.Assert(err, IsNil)
+	vars := stmt.(*ast.SetStmt).Variables
+	c.Assert(vars[0].Name, Equals, ast.SetNames)
+	c.Assert(vars[0].Value.GetValue(), Equals, "utf8")
+	c.Assert(vars[0].ExtendValue.GetValue(), Equals, "utf8_general_ci")
+	c.Assert(vars[1].Name, Equals, ast.SetCharset)
+	c.Assert(vars[1].Value.GetValue(), Equals, "latin1")
+
+	// The string literals have the charset and collation of the connection.
+	stmt, err = parser.ParseOneStmt("select 'a'", "latin1", "latin1_bin")
+	c.Assert(err, IsNil)
+	tp := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.GetType()
+	c.Assert(tp.Charset, Equals, "latin1")
+	c.Assert(tp.Collate, Equals, "latin1_bin")
+	c.Assert(mysql.HasBinaryFlag(tp.Flag), IsFalse)
+	stmt, err = parser.ParseOneStmt("select 'a'", charset.CharsetBin, charset.CollationBin)
+	c.Assert(err, IsNil)
+	tp = stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.GetType()
+	c.Assert(tp.Charset, Equals, charset.CharsetBin)
+	c.Assert(mysql.HasBinaryFlag(tp.Flag), IsTrue)
+}
+
 func (s *testParserSuite) TestAnalyze(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -1863,6 +2144,34 @@ func (s *testParserSuite) TestGeneratedColumn(c *C) {
 
 }
 
+func (s *testParserSuite) TestCheckConstraint(c *C) {
+	defer testleak.AfterTest(c)()
+	parser := New()
+	stmts, err := parser.Parse("create table t (a int check (a >= 0), b int, constraint chk_ab check (a + b   < 10))", "", "")
+	c.Assert(err, IsNil)
+	stmt := stmts[0].(*ast.CreateTableStmt)
+	opt := stmt.Cols[0].Options[0]
+	c.Assert(opt.Tp, Equals, ast.ColumnOptionCheck)
+	c.Assert(opt.Expr.Text(), Equals, "a >= 0")
+	c.Assert(stmt.Constraints, HasLen, 1)
+	c.Assert(stmt.Constraints[0].Tp, Equals, ast.ConstraintCheck)
+	c.Assert(stmt.Constraints[0].Name, Equals, "chk_ab")
+	c.Assert(stmt.Constraints[0].Expr.Text(), Equals, "a + b   < 10")
+
+	stmts, err = parser.Parse("alter table t add check (b <> 'x y')", "", "")
+	c.Assert(err, IsNil)
+	spec := stmts[0].(*ast.AlterTableStmt).Specs[0]
+	c.Assert(spec.Tp, Equals, ast.AlterTableAddConstraint)
+	c.Assert(spec.Constraint.Name, Equals, "")
+	c.Assert(spec.Constraint.Expr.Text(), Equals, "b <> 'x y'")
+
+	stmts, err = parser.Parse("alter table t drop check chk_ab", "", "")
+	c.Assert(err, IsNil)
+	spec = stmts[0].(*ast.AlterTableStmt).Specs[0]
+	c.Assert(spec.Tp, Equals, ast.AlterTableDropCheck)
+	c.Assert(spec.Name, Equals, "chk_ab")
+}
+
 func (s *testParserSuite) TestSetTransaction(c *C) {
 	defer testleak.AfterTest(c)()
 	// Set transaction is equivalent to setting the global or session value of tx_isolation.
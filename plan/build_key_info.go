@@ -17,6 +17,7 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 )
 
@@ -166,7 +167,13 @@ func (p *LogicalJoin) buildKeyInfo() {
 
 func (p *DataSource) buildKeyInfo() {
 	p.baseLogicalPlan.buildKeyInfo()
-	indices, _ := availableIndices(p.indexHints, p.tableInfo)
+	indices, _ := availableIndices(p.indexHints, p.tableInfo, ast.HintForScan)
+	p.schema.Keys = append(p.schema.Keys, p.uniqueKeys(indices)...)
+}
+
+// uniqueKeys returns the unique keys built from the unique indices and the integer primary key.
+func (p *DataSource) uniqueKeys(indices []*model.IndexInfo) []expression.KeyInfo {
+	var keys []expression.KeyInfo
 	for _, idx := range indices {
 		if !idx.Unique {
 			continue
@@ -193,15 +200,16 @@ func (p *DataSource) buildKeyInfo() {
 			}
 		}
 		if ok {
-			p.schema.Keys = append(p.schema.Keys, newKey)
+			keys = append(keys, newKey)
 		}
 	}
 	if p.tableInfo.PKIsHandle {
 		for i, col := range p.Columns {
 			if mysql.HasPriKeyFlag(col.Flag) {
-				p.schema.Keys = append(p.schema.Keys, []*expression.Column{p.schema.Columns[i]})
+				keys = append(keys, []*expression.Column{p.schema.Columns[i]})
 				break
 			}
 		}
 	}
+	return keys
 }
@@ -118,6 +118,32 @@ func (p *LogicalAggregation) PruneColumns(parentUsedCols []*expression.Column) {
 	child.PruneColumns(selfUsedCols)
 }
 
+// PruneColumns implements LogicalPlan interface.
+func (p *Window) PruneColumns(parentUsedCols []*expression.Column) {
+	child := p.children[0].(LogicalPlan)
+	windowCol := p.schema.Columns[p.schema.Len()-1]
+	// The window column is produced here, it's not passed to the child.
+	var selfUsedCols []*expression.Column
+	for _, col := range parentUsedCols {
+		if !col.Equal(windowCol, nil) {
+			selfUsedCols = append(selfUsedCols, col)
+		}
+	}
+	parentUsedCols = selfUsedCols
+	for _, arg := range p.Args {
+		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(arg)...)
+	}
+	for _, expr := range p.PartitionBy {
+		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(expr)...)
+	}
+	for _, item := range p.OrderBy {
+		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	child.PruneColumns(parentUsedCols)
+	p.SetSchema(child.Schema().Clone())
+	p.schema.Append(windowCol)
+}
+
 // PruneColumns implements LogicalPlan interface.
 func (p *Sort) PruneColumns(parentUsedCols []*expression.Column) {
 	child := p.children[0].(LogicalPlan)
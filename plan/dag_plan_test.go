@@ -18,6 +18,8 @@ import (
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -116,6 +118,16 @@ func (s *testPlanSuite) TestDAGPlanBuilderSimpleCase(c *C) {
 			sql:  "select c from t order by c",
 			best: "IndexReader(Index(t.c_d_e)[[<nil>,+inf]])",
 		},
+		// Test Sort which puts NULLs last can't be done by index.
+		{
+			sql:  "select c from t order by c nulls last",
+			best: "TableReader(Table(t))->Sort",
+		},
+		// Test TopN which puts NULLs first can't be pushed down.
+		{
+			sql:  "select c from t order by t.a + t.b desc nulls first limit 1",
+			best: "TableReader(Table(t))->TopN([plus(test.t.a, test.t.b) true nulls_high],0,1)->Projection",
+		},
 		// Test index single read and Sort.
 		{
 			sql:  "select c from t where c = 1 order by e",
@@ -179,6 +191,83 @@ func (s *testPlanSuite) TestDAGPlanBuilderSimpleCase(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestDAGPlanBuilderIndexHintScope(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tests := []struct {
+		sql  string
+		best string
+	}{
+		// Test the hint for order by allows the index providing the order.
+		{
+			sql:  "select * from t use index for order by (c_d_e) order by c limit 1",
+			best: "IndexLookUp(Index(t.c_d_e)[[<nil>,+inf]]->Limit, Table(t))->Limit",
+		},
+		// Test the hint for order by excludes the index providing the order.
+		{
+			sql:  "select * from t ignore index for order by (c_d_e) order by c limit 1",
+			best: "TableReader(Table(t)->TopN([test.t.c],0,1))->TopN([test.t.c],0,1)",
+		},
+		// Test the hint for order by doesn't restrict finding the rows.
+		{
+			sql:  "select * from t use index for order by (f) where c = 1",
+			best: "IndexLookUp(Index(t.c_d_e)[[1,1]], Table(t))",
+		},
+		// Test the hint for join restricts finding the rows.
+		{
+			sql:  "select * from t use index for join (f) where c = 1",
+			best: "IndexLookUp(Index(t.f)[[<nil>,+inf]], Table(t)->Sel([eq(test.t.c, 1)]))",
+		},
+		{
+			sql:  "select * from t ignore index for join (c_d_e) where c = 1",
+			best: "TableReader(Table(t)->Sel([eq(test.t.c, 1)]))",
+		},
+		// Test the hint for join doesn't restrict the order.
+		{
+			sql:  "select * from t ignore index for join (c_d_e) order by c limit 1",
+			best: "IndexLookUp(Index(t.c_d_e)[[<nil>,+inf]]->Limit, Table(t))->Limit",
+		},
+		// Test the hint for group by doesn't restrict finding the rows.
+		{
+			sql:  "select c from t ignore index for group by (c_d_e) where c = 1 group by c",
+			best: "IndexReader(Index(t.c_d_e)[[1,1]]->HashAgg)->HashAgg",
+		},
+		// Test the hint without scope restricts all the operations.
+		{
+			sql:  "select * from t ignore index (c_d_e) order by c limit 1",
+			best: "TableReader(Table(t)->TopN([test.t.c],0,1))->TopN([test.t.c],0,1)",
+		},
+	}
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+
+		err = se.NewTxn()
+		c.Assert(err, IsNil)
+
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil)
+		c.Assert(plan.ToString(p), Equals, tt.best, Commentf("for %s", tt.sql))
+	}
+
+	stmt, err := s.ParseOneStmt("select * from t use index for order by (x)", "", "")
+	c.Assert(err, IsNil)
+	is, err := plan.MockResolve(stmt)
+	c.Assert(err, IsNil)
+	_, err = plan.Optimize(se, stmt, is)
+	c.Assert(terror.ErrorEqual(err, plan.ErrKeyDoesNotExist), IsTrue, Commentf("err %v", err))
+}
+
 func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 	store, err := newStoreWithBootstrap()
 	c.Assert(err, IsNil)
@@ -340,6 +429,45 @@ func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestDAGPlanHashJoinConcurrency(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tests := []struct {
+		set         string
+		concurrency int
+	}{
+		{"", variable.DefHashJoinConcurrency},
+		{"set @@session.tidb_hash_join_concurrency = 3", 3},
+		{"set @@session.tidb_hash_join_concurrency = 0", variable.DefHashJoinConcurrency},
+		{"set @@session.tidb_hash_join_concurrency = 12", 12},
+	}
+	sql := "select * from t t1 join t t2 on t1.b = t2.a"
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.set)
+		if tt.set != "" {
+			_, err = se.Execute(tt.set)
+			c.Assert(err, IsNil, comment)
+		}
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil, comment)
+
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil)
+		join, ok := p.(*plan.PhysicalHashJoin)
+		c.Assert(ok, IsTrue, Commentf("for %s", plan.ToString(p)))
+		c.Assert(join.Concurrency, Equals, tt.concurrency, comment)
+	}
+}
+
 func (s *testPlanSuite) TestDAGPlanBuilderSubquery(c *C) {
 	store, err := newStoreWithBootstrap()
 	c.Assert(err, IsNil)
@@ -435,6 +563,20 @@ func (s *testPlanSuite) TestDAGPlanTopN(c *C) {
 			sql:  "select * from t t1 left join t t2 on t1.b = t2.b left join t t3 on t2.b = t3.b limit 1",
 			best: "LeftHashJoin{LeftHashJoin{TableReader(Table(t)->Limit)->TableReader(Table(t))}(t1.b,t2.b)->TableReader(Table(t))}(t2.b,t3.b)->Limit",
 		},
+		// Test the TopN of a small limit is pushed down.
+		{
+			sql:  "select * from t order by b limit 100, 10",
+			best: "TableReader(Table(t)->TopN([test.t.b],0,110))->TopN([test.t.b],100,10)",
+		},
+		// Test the large limit keeps the sort.
+		{
+			sql:  "select * from t order by b limit 100000",
+			best: "TableReader(Table(t))->Sort->Limit",
+		},
+		{
+			sql:  "select * from t order by b limit 1, 18446744073709551615",
+			best: "TableReader(Table(t))->Sort->Limit",
+		},
 	}
 	for _, tt := range tests {
 		comment := Commentf("for %s", tt.sql)
@@ -182,7 +182,7 @@ func (p *Selection) checkScanController() int {
 		pkCol       *expression.Column
 	)
 	ds := p.children[0].(*DataSource)
-	indices, includeTableScan := availableIndices(ds.indexHints, ds.tableInfo)
+	indices, includeTableScan := availableIndices(ds.indexHints, ds.tableInfo, ast.HintForJoin)
 	for _, expr := range p.Conditions {
 		if !expr.IsCorrelated() {
 			continue
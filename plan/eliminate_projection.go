@@ -218,6 +218,18 @@ func (p *Sort) replaceExprColumns(replace map[string]*expression.Column) {
 	}
 }
 
+func (p *Window) replaceExprColumns(replace map[string]*expression.Column) {
+	for _, arg := range p.Args {
+		resolveExprAndReplace(arg, replace)
+	}
+	for _, expr := range p.PartitionBy {
+		resolveExprAndReplace(expr, replace)
+	}
+	for _, item := range p.OrderBy {
+		resolveExprAndReplace(item.Expr, replace)
+	}
+}
+
 func (p *TopN) replaceExprColumns(replace map[string]*expression.Column) {
 	for _, byItem := range p.ByItems {
 		resolveExprAndReplace(byItem.Expr, replace)
@@ -17,39 +17,29 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
 )
 
-func setParents4FinalPlan(plan PhysicalPlan) {
+// collectParents4FinalPlan collects the IDs of the parents of every plan in the final plan tree, including the plans
+// of the cop tasks. The plans aren't modified, so the plan of a statement running on another connection can be explained.
+func collectParents4FinalPlan(plan PhysicalPlan, parents map[string][]string) {
 	allPlans := []PhysicalPlan{plan}
-	planMark := map[string]bool{}
-	planMark[plan.ID()] = true
+	planMark := map[string]bool{plan.ID(): true}
 	for pID := 0; pID < len(allPlans); pID++ {
-		allPlans[pID].SetParents()
 		switch copPlan := allPlans[pID].(type) {
 		case *PhysicalTableReader:
-			setParents4FinalPlan(copPlan.tablePlan)
+			collectParents4FinalPlan(copPlan.tablePlan, parents)
 		case *PhysicalIndexReader:
-			setParents4FinalPlan(copPlan.indexPlan)
+			collectParents4FinalPlan(copPlan.indexPlan, parents)
 		case *PhysicalIndexLookUpReader:
-			setParents4FinalPlan(copPlan.indexPlan)
-			setParents4FinalPlan(copPlan.tablePlan)
+			collectParents4FinalPlan(copPlan.indexPlan, parents)
+			collectParents4FinalPlan(copPlan.tablePlan, parents)
 		}
 		for _, p := range allPlans[pID].Children() {
+			parents[p.ID()] = append(parents[p.ID()], allPlans[pID].ID())
 			if !planMark[p.ID()] {
-				allPlans = append(allPlans, p.(PhysicalPlan))
 				planMark[p.ID()] = true
-			}
-		}
-	}
-
-	allPlans = allPlans[0:1]
-	planMark[plan.ID()] = false
-	for pID := 0; pID < len(allPlans); pID++ {
-		for _, p := range allPlans[pID].Children() {
-			p.AddParent(allPlans[pID])
-			if planMark[p.ID()] {
-				planMark[p.ID()] = false
 				allPlans = append(allPlans, p.(PhysicalPlan))
 			}
 		}
@@ -165,6 +155,13 @@ func (p *Sort) ExplainInfo() string {
 			order = "desc"
 		}
 		buffer.WriteString(fmt.Sprintf("%s:%s", item.Expr.ExplainInfo(), order))
+		if item.NullsHigh {
+			if item.Desc {
+				buffer.WriteString(":nulls_first")
+			} else {
+				buffer.WriteString(":nulls_last")
+			}
+		}
 		if i+1 < len(p.ByItems) {
 			buffer.WriteString(", ")
 		}
@@ -172,6 +169,56 @@ func (p *Sort) ExplainInfo() string {
 	return buffer.String()
 }
 
+// ExplainInfo implements PhysicalPlan interface.
+func (p *Window) ExplainInfo() string {
+	buffer := bytes.NewBufferString(fmt.Sprintf("%s(%s) over(", p.Name, expression.ExplainExpressionList(p.Args)))
+	if len(p.PartitionBy) > 0 {
+		buffer.WriteString(fmt.Sprintf("partition by %s", expression.ExplainExpressionList(p.PartitionBy)))
+	}
+	if len(p.OrderBy) > 0 {
+		if len(p.PartitionBy) > 0 {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString("order by ")
+		for i, item := range p.OrderBy {
+			order := "asc"
+			if item.Desc {
+				order = "desc"
+			}
+			buffer.WriteString(fmt.Sprintf("%s:%s", item.Expr.ExplainInfo(), order))
+			if i+1 < len(p.OrderBy) {
+				buffer.WriteString(", ")
+			}
+		}
+	}
+	if p.Frame != nil {
+		if len(p.PartitionBy) > 0 || len(p.OrderBy) > 0 {
+			buffer.WriteString(" ")
+		}
+		units := "rows"
+		if p.Frame.Type == ast.FrameRange {
+			units = "range"
+		}
+		buffer.WriteString(fmt.Sprintf("%s between %s and %s", units, explainFrameBound(p.Frame.Start), explainFrameBound(p.Frame.End)))
+	}
+	buffer.WriteString(")")
+	return buffer.String()
+}
+
+func explainFrameBound(bound ast.FrameBound) string {
+	if bound.Type == ast.CurrentRow {
+		return "current row"
+	}
+	direction := "preceding"
+	if bound.Type == ast.Following {
+		direction = "following"
+	}
+	if bound.UnBounded {
+		return "unbounded " + direction
+	}
+	return fmt.Sprintf("%d %s", bound.Offset, direction)
+}
+
 // ExplainInfo implements PhysicalPlan interface.
 func (p *Limit) ExplainInfo() string {
 	return fmt.Sprintf("offset:%v, count:%v", p.Offset, p.Count)
@@ -234,6 +234,14 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 		}
 		er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
 		return inNode, true
+	case *ast.WindowFuncExpr:
+		index, ok := er.b.windowMapper[v]
+		if !ok {
+			er.err = ErrInvalidWindowUse
+			return inNode, true
+		}
+		er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
+		return inNode, true
 	case *ast.ColumnNameExpr:
 		if index, ok := er.b.colMapper[v]; ok {
 			er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
@@ -678,7 +686,7 @@ func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok
 		inNode = er.preprocess(inNode)
 	}
 	switch v := inNode.(type) {
-	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
+	case *ast.AggregateFuncExpr, *ast.WindowFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
 		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr:
 	case *ast.ValueExpr:
 		tp := &types.FieldType{}
@@ -1081,8 +1089,9 @@ func (er *expressionRewriter) rewriteFuncCall(v *ast.FuncCallExpr) bool {
 			er.err = err
 			return true
 		}
-		// if(param1 = param2, null, param1)
-		funcIf, err := expression.NewFunction(er.ctx, ast.If, &v.Type, funcCompare, expression.Null, param1)
+		// if(param1 = param2, null, param1), the NULL doesn't affect the result type.
+		null := &expression.Constant{Value: types.NewDatum(nil), RetType: types.NewFieldType(mysql.TypeNull)}
+		funcIf, err := expression.NewFunction(er.ctx, ast.If, &v.Type, funcCompare, null, param1)
 		if err != nil {
 			er.err = err
 			return true
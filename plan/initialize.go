@@ -49,6 +49,8 @@ const (
 	TypeIdxScan = "IndexScan"
 	// TypeSort is the type of Sort.
 	TypeSort = "Sort"
+	// TypeWindow is the type of Window.
+	TypeWindow = "Window"
 	// TypeTopN is the type of TopN.
 	TypeTopN = "TopN"
 	// TypeLimit is the type of Limit.
@@ -139,6 +141,13 @@ func (p Sort) init(allocator *idAllocator, ctx context.Context) *Sort {
 	return &p
 }
 
+func (p Window) init(allocator *idAllocator, ctx context.Context) *Window {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p TopN) init(allocator *idAllocator, ctx context.Context) *TopN {
 	p.basePlan = newBasePlan(TypeTopN, allocator, ctx, &p)
 	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
@@ -121,7 +121,11 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		case *ast.UnionStmt:
 			p = b.buildUnion(v)
 		case *ast.TableName:
-			p = b.buildDataSource(v)
+			if v.CTE != nil {
+				p = b.buildCTE(v.CTE)
+			} else {
+				p = b.buildDataSource(v)
+			}
 		default:
 			b.err = ErrUnsupportedType.Gen("unsupported table source type %T", v)
 			return nil
@@ -132,9 +136,13 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		if v, ok := p.(*DataSource); ok {
 			v.TableAsName = &x.AsName
 		}
-		if x.AsName.L != "" {
+		asName := x.AsName
+		if tn, ok := x.Source.(*ast.TableName); ok && tn.CTE != nil && asName.L == "" {
+			asName = tn.Name
+		}
+		if asName.L != "" {
 			for _, col := range p.Schema().Columns {
-				col.TblName = x.AsName
+				col.TblName = asName
 				col.DBName = model.NewCIStr("")
 			}
 		}
@@ -592,19 +600,47 @@ func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
 type ByItems struct {
 	Expr expression.Expression
 	Desc bool
+	// NullsHigh means NULL is compared as the largest value instead of the smallest one,
+	// so the NULLs come last in ascending order and first in descending order.
+	NullsHigh bool
 }
 
 // String implements fmt.Stringer interface.
 func (by *ByItems) String() string {
+	str := by.Expr.String()
 	if by.Desc {
-		return fmt.Sprintf("%s true", by.Expr)
+		str = fmt.Sprintf("%s true", str)
+	}
+	if by.NullsHigh {
+		str = fmt.Sprintf("%s nulls_high", str)
+	}
+	return str
+}
+
+// isNullsHigh returns whether NULL should be compared as the largest value for the order by item.
+// If NULLS FIRST or NULLS LAST is not specified, the order depends on tidb_default_null_order.
+func isNullsHigh(item *ast.ByItem, defaultOrder string) bool {
+	switch item.NullOrder {
+	case ast.NullsFirst:
+		return item.Desc
+	case ast.NullsLast:
+		return !item.Desc
+	}
+	switch defaultOrder {
+	case variable.NullOrderHigh:
+		return true
+	case variable.NullOrderFirst:
+		return item.Desc
+	case variable.NullOrderLast:
+		return !item.Desc
 	}
-	return by.Expr.String()
+	return false
 }
 
 func (b *planBuilder) buildSort(p LogicalPlan, byItems []*ast.ByItem, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
 	sort := Sort{}.init(b.allocator, b.ctx)
 	exprs := make([]*ByItems, 0, len(byItems))
+	defaultNullOrder := b.ctx.GetSessionVars().Systems[variable.TiDBDefaultNullOrder]
 	for _, item := range byItems {
 		it, np, err := b.rewrite(item.Expr, p, aggMapper, true)
 		if err != nil {
@@ -612,7 +648,7 @@ func (b *planBuilder) buildSort(p LogicalPlan, byItems []*ast.ByItem, aggMapper
 			return nil
 		}
 		p = np
-		exprs = append(exprs, &ByItems{Expr: it, Desc: item.Desc})
+		exprs = append(exprs, &ByItems{Expr: it, Desc: item.Desc, NullsHigh: isNullsHigh(item, defaultNullOrder)})
 	}
 	sort.ByItems = exprs
 	addChild(sort, p)
@@ -620,6 +656,135 @@ func (b *planBuilder) buildSort(p LogicalPlan, byItems []*ast.ByItem, aggMapper
 	return sort
 }
 
+// buildWindowFunctions builds a Window plan for every window function in the select fields.
+// The result column offsets of the window functions are recorded in b.windowMapper for building the projection.
+func (b *planBuilder) buildWindowFunctions(p LogicalPlan, fields []*ast.SelectField, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
+	extractor := &windowFuncExtractor{}
+	for _, field := range fields {
+		field.Expr.Accept(extractor)
+	}
+	for _, win := range extractor.windowFuncs {
+		p = b.buildWindow(p, win, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+	}
+	return p
+}
+
+func (b *planBuilder) buildWindow(p LogicalPlan, win *ast.WindowFuncExpr, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
+	if win.Distinct {
+		b.err = ErrWindowUnsupported.GenByArgs("with DISTINCT")
+		return nil
+	}
+	frame, err := buildWindowFrame(&win.Spec)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	args := make([]expression.Expression, 0, len(win.Args))
+	for _, arg := range win.Args {
+		newArg, np, err := b.rewrite(arg, p, aggMapper, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		p = np
+		args = append(args, newArg)
+	}
+	var retType *types.FieldType
+	switch win.F {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank:
+		retType = types.NewFieldType(mysql.TypeLonglong)
+		retType.Flen = 21
+		types.SetBinChsClnFlag(retType)
+	case ast.AggFuncCount, ast.AggFuncSum, ast.AggFuncAvg, ast.AggFuncMax, ast.AggFuncMin:
+		retType = expression.NewAggFunction(win.F, args, false).GetType()
+	default:
+		b.err = ErrWindowUnsupported.GenByArgs(win.F)
+		return nil
+	}
+	window := Window{Name: win.F, Args: args, Frame: frame}.init(b.allocator, b.ctx)
+	for _, item := range win.Spec.PartitionBy {
+		expr, np, err := b.rewrite(item, p, aggMapper, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		p = np
+		window.PartitionBy = append(window.PartitionBy, expr)
+	}
+	defaultNullOrder := b.ctx.GetSessionVars().Systems[variable.TiDBDefaultNullOrder]
+	for _, item := range win.Spec.OrderBy {
+		expr, np, err := b.rewrite(item.Expr, p, aggMapper, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		p = np
+		window.OrderBy = append(window.OrderBy, &ByItems{Expr: expr, Desc: item.Desc, NullsHigh: isNullsHigh(item, defaultNullOrder)})
+	}
+	// The rows of a partition must be adjacent and sorted by the order items.
+	if len(window.PartitionBy) > 0 || len(window.OrderBy) > 0 {
+		sort := Sort{}.init(b.allocator, b.ctx)
+		for _, expr := range window.PartitionBy {
+			sort.ByItems = append(sort.ByItems, &ByItems{Expr: expr})
+		}
+		sort.ByItems = append(sort.ByItems, window.OrderBy...)
+		addChild(sort, p)
+		sort.SetSchema(p.Schema().Clone())
+		p = sort
+	}
+	schema := p.Schema().Clone()
+	position := schema.Len()
+	schema.Append(&expression.Column{
+		FromID:      window.id,
+		ColName:     model.NewCIStr(fmt.Sprintf("%s_col_%d", window.id, position)),
+		Position:    position,
+		IsAggOrSubq: true,
+		RetType:     retType,
+	})
+	addChild(window, p)
+	window.SetSchema(schema)
+	if b.windowMapper == nil {
+		b.windowMapper = make(map[*ast.WindowFuncExpr]int)
+	}
+	b.windowMapper[win] = position
+	return window
+}
+
+// buildWindowFrame checks the frame of the window specification, it returns the default frame if it's not specified.
+func buildWindowFrame(spec *ast.WindowSpec) (*ast.FrameClause, error) {
+	frame := spec.Frame
+	if frame == nil {
+		if len(spec.OrderBy) == 0 {
+			// All the rows of the partition are in the window.
+			return &ast.FrameClause{
+				Type:  ast.FrameRows,
+				Start: ast.FrameBound{Type: ast.Preceding, UnBounded: true},
+				End:   ast.FrameBound{Type: ast.Following, UnBounded: true},
+			}, nil
+		}
+		// The window is from the first row of the partition to the last peer of the current row.
+		return &ast.FrameClause{
+			Type:  ast.FrameRange,
+			Start: ast.FrameBound{Type: ast.Preceding, UnBounded: true},
+			End:   ast.FrameBound{Type: ast.CurrentRow},
+		}, nil
+	}
+	if (frame.Start.Type == ast.Following && frame.Start.UnBounded) || (frame.End.Type == ast.Preceding && frame.End.UnBounded) {
+		return nil, ErrWindowFrameIllegal
+	}
+	if frame.Type == ast.FrameRange {
+		for _, bound := range []ast.FrameBound{frame.Start, frame.End} {
+			if bound.Type != ast.CurrentRow && !bound.UnBounded {
+				return nil, ErrWindowUnsupported.GenByArgs("with RANGE offset")
+			}
+		}
+	}
+	return frame, nil
+}
+
 // getUintForLimitOffset gets uint64 value for limit/offset.
 // For ordinary statement, limit/offset should be uint64 constant value.
 // For prepared statement, limit/offset is string. We should convert it to uint64.
@@ -974,6 +1139,102 @@ func (b *planBuilder) resolveGbyExprs(p LogicalPlan, gby *ast.GroupByClause, fie
 	return p, exprs
 }
 
+// checkOnlyFullGroupBy rejects the select fields, HAVING and ORDER BY items which contain nonaggregated columns
+// that aren't in the GROUP BY clause and aren't functionally dependent on it, or any nonaggregated columns if
+// there is no GROUP BY clause. It's used for the sql_mode ONLY_FULL_GROUP_BY, otherwise such a column returns
+// the value of an arbitrary row in the group.
+func (b *planBuilder) checkOnlyFullGroupBy(p LogicalPlan, sel *ast.SelectStmt, gbyCols []expression.Expression) {
+	for i, field := range sel.Fields.Fields {
+		if !b.checkNonAggColumns(p, sel, nil, field.Expr, gbyCols, i+1, "SELECT list") {
+			return
+		}
+	}
+	if sel.Having != nil && !b.checkNonAggColumns(p, sel, sel.Fields.Fields, sel.Having.Expr, gbyCols, 1, "HAVING clause") {
+		return
+	}
+	if sel.OrderBy != nil {
+		for i, item := range sel.OrderBy.Items {
+			if !b.checkNonAggColumns(p, sel, sel.Fields.Fields, item.Expr, gbyCols, i+1, "ORDER BY clause") {
+				return
+			}
+		}
+	}
+}
+
+// checkNonAggColumns checks the nonaggregated columns of the expression, which is the offset-th one of the clause.
+// The names which refer to the fields are skipped, since the select fields are checked already.
+func (b *planBuilder) checkNonAggColumns(p LogicalPlan, sel *ast.SelectStmt, fields []*ast.SelectField, expr ast.ExprNode,
+	gbyCols []expression.Expression, offset int, clause string) bool {
+	extractor := &nonAggColumnExtractor{}
+	expr.Accept(extractor)
+	for _, colExpr := range extractor.cols {
+		if idx, err := resolveFromSelectFields(colExpr, fields, false); err != nil || idx != -1 {
+			continue
+		}
+		col, err := p.Schema().FindColumn(colExpr.Name)
+		if err != nil || col == nil {
+			// The ambiguous columns are reported when building the projection,
+			// and the outer columns are constant in a group.
+			continue
+		}
+		if sel.GroupBy == nil {
+			b.err = ErrMixOfGroupFuncAndFields.GenByArgs(offset, clause, col.String())
+			return false
+		}
+		if !b.isExprInGroupBy(col, gbyCols) && !b.isDependentOnGroupBy(p, col, gbyCols) && !b.isFieldInGroupBy(p, expr, gbyCols) {
+			b.err = ErrFieldNotInGroupBy.GenByArgs(offset, clause, col.String())
+			return false
+		}
+	}
+	return true
+}
+
+// isExprInGroupBy checks whether the expression is one of the GROUP BY items.
+func (b *planBuilder) isExprInGroupBy(expr expression.Expression, gbyCols []expression.Expression) bool {
+	for _, gbyCol := range gbyCols {
+		if expr.Equal(gbyCol, b.ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDependentOnGroupBy checks whether the column is functionally dependent on the GROUP BY items, that is,
+// all the columns of the primary key or a unique key on NOT NULL columns of its table are in the GROUP BY clause.
+func (b *planBuilder) isDependentOnGroupBy(p LogicalPlan, col *expression.Column, gbyCols []expression.Expression) bool {
+	ds := findDataSourceOfColumn(p, col)
+	if ds == nil {
+		return false
+	}
+	indices, _ := availableIndices(nil, ds.tableInfo, ast.HintForScan)
+	for _, key := range ds.uniqueKeys(indices) {
+		determined := true
+		for _, keyCol := range key {
+			if !b.isExprInGroupBy(keyCol, gbyCols) {
+				determined = false
+				break
+			}
+		}
+		if determined {
+			return true
+		}
+	}
+	return false
+}
+
+// isFieldInGroupBy checks whether the select field without aggregate functions is one of the GROUP BY items,
+// e.g. the field `a+1` is in the GROUP BY clause `GROUP BY a+1`.
+func (b *planBuilder) isFieldInGroupBy(p LogicalPlan, expr ast.ExprNode, gbyCols []expression.Expression) bool {
+	if ast.HasAggFlag(expr) {
+		return false
+	}
+	newExpr, _, err := b.rewrite(expr, p, nil, true)
+	if err != nil {
+		return false
+	}
+	return b.isExprInGroupBy(newExpr, gbyCols)
+}
+
 func (b *planBuilder) unfoldWildStar(p LogicalPlan, selectFields []*ast.SelectField) (resultList []*ast.SelectField) {
 	for i, field := range selectFields {
 		if field.WildCard == nil {
@@ -1060,6 +1321,9 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		gbyCols                       []expression.Expression
 	)
 	if sel.From != nil {
+		if !b.checkJoinTables(sel.From.TableRefs) {
+			return nil
+		}
 		p = b.buildResultSetNode(sel.From.TableRefs)
 	} else {
 		p = b.buildTableDual()
@@ -1078,6 +1342,12 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 			return nil
 		}
 	}
+	if hasAgg && b.ctx.GetSessionVars().SQLMode&mysql.ModeOnlyFullGroupBy != 0 {
+		b.checkOnlyFullGroupBy(p, sel, gbyCols)
+		if b.err != nil {
+			return nil
+		}
+	}
 	// We must resolve having and order by clause before build projection,
 	// because when the query is "select a+1 as b from t having sum(b) < 0", we must replace sum(b) to sum(a+1),
 	// which only can be done before building projection and extracting Agg functions.
@@ -1105,6 +1375,10 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 			return nil
 		}
 	}
+	p = b.buildWindowFunctions(p, sel.Fields.Fields, totalMap)
+	if b.err != nil {
+		return nil
+	}
 	var oldLen int
 	p, oldLen = b.buildProjection(p, sel.Fields.Fields, totalMap)
 	if b.err != nil {
@@ -1152,6 +1426,20 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 	return p
 }
 
+// buildCTE builds the query of the common table expression. The common table expression is inlined,
+// so the query is built every time it's referenced.
+func (b *planBuilder) buildCTE(cte *ast.CommonTableExpression) LogicalPlan {
+	p := b.buildResultSetNode(cte.Query)
+	if b.err != nil {
+		return nil
+	}
+	// The number of column names has been checked by the name resolver.
+	for i, name := range cte.ColNames {
+		p.Schema().Columns[i].ColName = name
+	}
+	return p
+}
+
 func (b *planBuilder) buildTableDual() LogicalPlan {
 	dual := TableDual{RowCount: 1}.init(b.allocator, b.ctx)
 	dual.SetSchema(expression.NewSchema())
@@ -1178,6 +1466,10 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 		return nil
 	}
 	tableInfo := tbl.Meta()
+	if err = checkIndexHints(tn.IndexHints, tableInfo); err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
 
 	p := DataSource{
 		indexHints:     tn.IndexHints,
@@ -1356,6 +1648,9 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	b.inUpdateStmt = true
 	b.needColHandle++
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: update.TableRefs, Where: update.Where, OrderBy: update.Order, Limit: update.Limit}
+	if !b.checkJoinTables(sel.From.TableRefs) {
+		return nil
+	}
 	p := b.buildResultSetNode(sel.From.TableRefs)
 	if b.err != nil {
 		return nil
@@ -1389,18 +1684,25 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 			return nil
 		}
 	}
-	orderedList, np := b.buildUpdateLists(tableList, update.List, p)
+	orderedList, np := b.buildUpdateLists(sel.From.TableRefs, tableList, update.List, p)
 	if b.err != nil {
 		return nil
 	}
 	p = np
-	updt := Update{OrderedList: orderedList}.init(b.allocator, b.ctx)
+	checks := make(map[int64][]*CheckConstraint)
+	for _, tn := range tableList {
+		checks[tn.TableInfo.ID] = b.buildCheckConstraints(tn.TableInfo)
+		if b.err != nil {
+			return nil
+		}
+	}
+	updt := Update{OrderedList: orderedList, CheckConstraints: checks}.init(b.allocator, b.ctx)
 	addChild(updt, p)
 	updt.SetSchema(p.Schema())
 	return updt
 }
 
-func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.Assignment, p LogicalPlan) ([]*expression.Assignment, LogicalPlan) {
+func (b *planBuilder) buildUpdateLists(refs ast.ResultSetNode, tableList []*ast.TableName, list []*ast.Assignment, p LogicalPlan) ([]*expression.Assignment, LogicalPlan) {
 	modifyColumns := make(map[string]struct{}, p.Schema().Len()) // Which columns are in set list.
 	for _, assign := range list {
 		col, _, err := p.findColumn(assign.Column)
@@ -1434,13 +1736,24 @@ func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.A
 			return nil, nil
 		}
 		var newExpr expression.Expression
-		var np LogicalPlan
-		newExpr, np, err = b.rewrite(assign.Expr, p, nil, false)
+		if dft, ok := assign.Expr.(*ast.DefaultExpr); ok {
+			tbl := b.findColumnTable(refs, col)
+			if tbl == nil {
+				b.err = ErrUnknownColumn.GenByArgs(assign.Column.Name.O, "field_list")
+				return nil, nil
+			}
+			newExpr, err = b.buildAssignDefault(dft, tbl.Cols(), assign.Column)
+		} else {
+			var np LogicalPlan
+			newExpr, np, err = b.rewrite(assign.Expr, p, nil, false)
+			if err == nil {
+				p = np
+			}
+		}
 		if err != nil {
 			b.err = errors.Trace(err)
 			return nil, nil
 		}
-		p = np
 		newList = append(newList, &expression.Assignment{Col: col.Clone().(*expression.Column), Expr: newExpr})
 	}
 	return newList, p
@@ -1449,6 +1762,9 @@ func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.A
 func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 	b.needColHandle++
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: delete.TableRefs, Where: delete.Where, OrderBy: delete.Order, Limit: delete.Limit}
+	if !b.checkJoinTables(sel.From.TableRefs) {
+		return nil
+	}
 	p := b.buildResultSetNode(sel.From.TableRefs)
 	if b.err != nil {
 		return nil
@@ -1507,6 +1823,30 @@ func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 	return del
 }
 
+// checkJoinTables checks the number of tables in the join doesn't exceed MaxJoinTables.
+// The subqueries in the join are counted as single tables, their own joins are checked when they are built.
+func (b *planBuilder) checkJoinTables(node ast.ResultSetNode) bool {
+	if MaxJoinTables > 0 && countJoinTables(node) > MaxJoinTables {
+		b.err = ErrTooManyTables.GenByArgs(MaxJoinTables)
+		return false
+	}
+	return true
+}
+
+func countJoinTables(node ast.ResultSetNode) int {
+	switch x := node.(type) {
+	case *ast.Join:
+		count := countJoinTables(x.Left)
+		if x.Right != nil {
+			count += countJoinTables(x.Right)
+		}
+		return count
+	case *ast.TableSource:
+		return 1
+	}
+	return 0
+}
+
 func extractTableList(node ast.ResultSetNode, input []*ast.TableName) []*ast.TableName {
 	switch x := node.(type) {
 	case *ast.Join:
@@ -1520,6 +1860,34 @@ func extractTableList(node ast.ResultSetNode, input []*ast.TableName) []*ast.Tab
 	return input
 }
 
+// findColumnTable returns the table in the table refs that the column belongs to.
+func (b *planBuilder) findColumnTable(node ast.ResultSetNode, col *expression.Column) table.Table {
+	switch x := node.(type) {
+	case *ast.Join:
+		if tbl := b.findColumnTable(x.Left, col); tbl != nil {
+			return tbl
+		}
+		if x.Right != nil {
+			return b.findColumnTable(x.Right, col)
+		}
+	case *ast.TableSource:
+		tn, ok := x.Source.(*ast.TableName)
+		if !ok {
+			return nil
+		}
+		if x.AsName.L != "" {
+			if x.AsName.L != col.TblName.L {
+				return nil
+			}
+		} else if tn.Name.L != col.TblName.L || tn.Schema.L != col.DBName.L {
+			return nil
+		}
+		tbl, _ := b.is.TableByID(tn.TableInfo.ID)
+		return tbl
+	}
+	return nil
+}
+
 func appendVisitInfo(vi []visitInfo, priv mysql.PrivilegeType, db, tbl, col string) []visitInfo {
 	return append(vi, visitInfo{
 		privilege: priv,
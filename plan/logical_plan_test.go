@@ -681,6 +681,15 @@ func (s *testPlanSuite) TestPlanBuilder(c *C) {
 			sql:  "analyze table t, t",
 			plan: "*plan.Analyze",
 		},
+		{
+			// The common table expression is inlined.
+			sql:  "with c as (select a, b from t where c > 1) select * from c",
+			plan: "DataScan(t)->Selection->Projection->Projection",
+		},
+		{
+			sql:  "with c (x, y) as (select a, b from t) select c1.x from c c1 join c c2 on c1.x = c2.y",
+			plan: "Join{DataScan(t)->Projection->DataScan(t)->Projection}(c1.x,c2.y)->Projection",
+		},
 	}
 	for _, ca := range tests {
 		comment := Commentf("for %s", ca.sql)
@@ -35,6 +35,7 @@ var (
 	_ LogicalPlan = &DataSource{}
 	_ LogicalPlan = &Union{}
 	_ LogicalPlan = &Sort{}
+	_ LogicalPlan = &Window{}
 	_ LogicalPlan = &Update{}
 	_ LogicalPlan = &Delete{}
 	_ LogicalPlan = &SelectLock{}
@@ -342,6 +343,36 @@ func (p *Sort) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	return corCols
 }
 
+// Window computes a window function for every row of its child and appends the result to the row.
+// The rows of the child are sorted by PartitionBy and OrderBy.
+type Window struct {
+	*basePlan
+	baseLogicalPlan
+	basePhysicalPlan
+
+	// Name is the name of the window function, it's a ranking function or an aggregate function.
+	Name        string
+	Args        []expression.Expression
+	PartitionBy []expression.Expression
+	OrderBy     []*ByItems
+	// Frame is the frame of the aggregate function, the ranking functions ignore it.
+	Frame *ast.FrameClause
+}
+
+func (p *Window) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, arg := range p.Args {
+		corCols = append(corCols, extractCorColumns(arg)...)
+	}
+	for _, expr := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(expr)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 // TopN represents a top-n plan.
 type TopN struct {
 	*basePlan
@@ -383,6 +414,8 @@ type Update struct {
 	basePhysicalPlan
 
 	OrderedList []*expression.Assignment
+	// CheckConstraints are the check constraints of the updated tables, the key is the table ID.
+	CheckConstraints map[int64][]*CheckConstraint
 }
 
 // Delete represents a delete plan.
@@ -46,7 +46,7 @@ func (p *requiredProp) enforceProperty(task task, ctx context.Context, allocator
 	task = finishCopTask(task, ctx, allocator)
 	sort := Sort{ByItems: make([]*ByItems, 0, len(p.cols))}.init(allocator, ctx)
 	for _, col := range p.cols {
-		sort.ByItems = append(sort.ByItems, &ByItems{col, p.desc})
+		sort.ByItems = append(sort.ByItems, &ByItems{Expr: col, Desc: p.desc})
 	}
 	sort.SetSchema(task.plan().Schema())
 	sort.profile = task.plan().statsProfile()
@@ -74,6 +74,7 @@ func (p *Projection) getChildrenPossibleProps(prop *requiredProp) [][]*requiredP
 	}
 	newProp.cols = newCols
 	newProp.desc = prop.desc
+	newProp.hintScope = prop.hintScope
 	return [][]*requiredProp{{newProp}}
 }
 
@@ -154,7 +155,7 @@ func (p *LogicalJoin) getIndexJoinByOuterIdx(outerIdx int) []PhysicalPlan {
 	if !ok {
 		return nil
 	}
-	indices, includeTableScan := availableIndices(x.indexHints, x.tableInfo)
+	indices, includeTableScan := availableIndices(x.indexHints, x.tableInfo, ast.HintForJoin)
 	if includeTableScan && len(innerJoinKeys) == 1 {
 		pkCol := x.getPKIsHandleCol()
 		if pkCol != nil && innerJoinKeys[0].Equal(pkCol, nil) {
@@ -197,18 +198,18 @@ func (p *PhysicalIndexJoin) getChildrenPossibleProps(prop *requiredProp) [][]*re
 		}
 	}
 	requiredProps1 := make([]*requiredProp, 2)
-	requiredProps1[p.outerIndex] = &requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt, cols: prop.cols, desc: prop.desc}
-	requiredProps1[1-p.outerIndex] = &requiredProp{taskTp: copSingleReadTaskType, cols: p.InnerJoinKeys, expectedCnt: math.MaxFloat64}
+	requiredProps1[p.outerIndex] = &requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt, cols: prop.cols, desc: prop.desc, hintScope: prop.hintScope}
+	requiredProps1[1-p.outerIndex] = &requiredProp{taskTp: copSingleReadTaskType, cols: p.InnerJoinKeys, expectedCnt: math.MaxFloat64, hintScope: ast.HintForJoin}
 	requiredProps2 := make([]*requiredProp, 2)
-	requiredProps2[p.outerIndex] = &requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt, cols: prop.cols, desc: prop.desc}
-	requiredProps2[1-p.outerIndex] = &requiredProp{taskTp: copDoubleReadTaskType, cols: p.InnerJoinKeys, expectedCnt: math.MaxFloat64}
+	requiredProps2[p.outerIndex] = &requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt, cols: prop.cols, desc: prop.desc, hintScope: prop.hintScope}
+	requiredProps2[1-p.outerIndex] = &requiredProp{taskTp: copDoubleReadTaskType, cols: p.InnerJoinKeys, expectedCnt: math.MaxFloat64, hintScope: ast.HintForJoin}
 	return [][]*requiredProp{requiredProps1, requiredProps2}
 }
 
 func (p *PhysicalMergeJoin) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
-	lProp := &requiredProp{taskTp: rootTaskType, cols: p.leftKeys, expectedCnt: math.MaxFloat64}
-	rProp := &requiredProp{taskTp: rootTaskType, cols: p.rightKeys, expectedCnt: math.MaxFloat64}
+	lProp := &requiredProp{taskTp: rootTaskType, cols: p.leftKeys, expectedCnt: math.MaxFloat64, hintScope: ast.HintForJoin}
+	rProp := &requiredProp{taskTp: rootTaskType, cols: p.rightKeys, expectedCnt: math.MaxFloat64, hintScope: ast.HintForJoin}
 	if !prop.isEmpty() {
 		if prop.desc {
 			return nil
@@ -404,7 +405,7 @@ func (p *LogicalJoin) getHashJoin(smallTable int) PhysicalPlan {
 		RightConditions: p.RightConditions,
 		OtherConditions: p.OtherConditions,
 		JoinType:        p.JoinType,
-		Concurrency:     JoinConcurrency,
+		Concurrency:     p.ctx.GetSessionVars().HashJoinConcurrency,
 		DefaultValues:   p.DefaultValues,
 		SmallTable:      smallTable,
 	}.init(p.allocator, p.ctx)
@@ -414,13 +415,14 @@ func (p *LogicalJoin) getHashJoin(smallTable int) PhysicalPlan {
 }
 
 // getPropByOrderByItems will check if this sort property can be pushed or not. In order to simplify the problem, we only
-// consider the case that all expression are columns and all of them are asc or desc.
+// consider the case that all expression are columns and all of them are asc or desc. The order of NULLs must be the
+// natural one, since the index and the coprocessor always treat NULL as the smallest value.
 func getPropByOrderByItems(items []*ByItems) (*requiredProp, bool) {
 	desc := false
 	cols := make([]*expression.Column, 0, len(items))
 	for i, item := range items {
 		col, ok := item.Expr.(*expression.Column)
-		if !ok {
+		if !ok || item.NullsHigh {
 			return nil, false
 		}
 		cols = append(cols, col)
@@ -429,7 +431,7 @@ func getPropByOrderByItems(items []*ByItems) (*requiredProp, bool) {
 			return nil, false
 		}
 	}
-	return &requiredProp{cols: cols, desc: desc}, true
+	return &requiredProp{cols: cols, desc: desc, hintScope: ast.HintForOrderBy}, true
 }
 
 func (p *TopN) generatePhysicalPlans() []PhysicalPlan {
@@ -643,7 +645,7 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 		return t, p.storeTask(prop, t)
 	}
 	// TODO: We have not checked if this table has a predicate. If not, we can only consider table scan.
-	indices, includeTableScan := availableIndices(p.indexHints, p.tableInfo)
+	indices, includeTableScan := availableIndices(p.indexHints, p.tableInfo, prop.indexHintScope())
 	t = invalidTask
 	if includeTableScan {
 		t, err = p.convertToTableScan(prop)
@@ -976,7 +978,7 @@ func (p *PhysicalHashJoin) getChildrenPossibleProps(prop *requiredProp) [][]*req
 
 func (p *PhysicalHashSemiJoin) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
-	lProp := &requiredProp{taskTp: rootTaskType, cols: prop.cols, expectedCnt: prop.expectedCnt, desc: prop.desc}
+	lProp := &requiredProp{taskTp: rootTaskType, cols: prop.cols, expectedCnt: prop.expectedCnt, desc: prop.desc, hintScope: prop.hintScope}
 	for _, col := range lProp.cols {
 		idx := p.Schema().ColumnIndex(col)
 		if idx == -1 || idx >= p.rightChOffset {
@@ -988,7 +990,7 @@ func (p *PhysicalHashSemiJoin) getChildrenPossibleProps(prop *requiredProp) [][]
 
 func (p *PhysicalApply) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
-	lProp := &requiredProp{taskTp: rootTaskType, cols: prop.cols, expectedCnt: prop.expectedCnt, desc: prop.desc}
+	lProp := &requiredProp{taskTp: rootTaskType, cols: prop.cols, expectedCnt: prop.expectedCnt, desc: prop.desc, hintScope: prop.hintScope}
 	for _, col := range lProp.cols {
 		idx := p.Schema().ColumnIndex(col)
 		if idx == -1 || idx >= p.rightChOffset {
@@ -1009,12 +1011,22 @@ func (p *Limit) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 		if p.expectedProp != nil {
 			newProp.cols = p.expectedProp.cols
 			newProp.desc = p.expectedProp.desc
+			newProp.hintScope = p.expectedProp.hintScope
 		}
 		props = append(props, []*requiredProp{newProp})
 	}
 	return props
 }
 
+func (p *Window) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	p.expectedCnt = prop.expectedCnt
+	if !prop.isEmpty() {
+		return nil
+	}
+	// The window of a row may contain all the rows of the partition, so all the rows of the child are needed.
+	return [][]*requiredProp{{{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}}}
+}
+
 func (p *TopN) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
 	if !prop.isEmpty() {
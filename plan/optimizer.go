@@ -19,15 +19,18 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 )
 
-// AllowCartesianProduct means whether tidb allows cartesian join without equal conditions.
-var AllowCartesianProduct = true
+// MaxJoinTables is the max number of tables in a join, the planning time of a join grows quickly with the number
+// of tables. It's the same as MySQL by default, 0 means no limit.
+var MaxJoinTables = 61
 
 const (
 	flagPrunColumns uint64 = 1 << iota
@@ -49,6 +52,55 @@ var optRuleList = []logicalOptRule{
 	&pushDownTopNOptimizer{},
 }
 
+// OptRuleInfo is the name and the description of a logical optimizing rule.
+type OptRuleInfo struct {
+	Name        string
+	Description string
+	// required rules can't be disabled, the other rules or the executors depend on them.
+	required bool
+	// dependsOn is the rule this rule depends on, it's skipped if that rule is disabled.
+	dependsOn string
+}
+
+// OptRules are the logical optimizing rules in the same order as optRuleList, they're shown by "show opt_rules".
+// A rule is skipped if its name is in tidb_opt_disable_rules.
+var OptRules = []OptRuleInfo{
+	{Name: "column_pruning", Description: "prune the columns not used by the parent operators, it can't be disabled", required: true},
+	{Name: "projection_eliminate", Description: "eliminate the projections which only forward the columns of their children"},
+	{Name: "build_keys", Description: "derive the unique keys of the operators"},
+	{Name: "decorrelate", Description: "rewrite the correlated subqueries to joins"},
+	{Name: "predicate_push_down", Description: "push the filters down to the joins and the data sources"},
+	{Name: "aggregation_push_down", Description: "push the aggregations down through the joins, it also needs tidb_opt_agg_push_down and predicate_push_down",
+		dependsOn: "predicate_push_down"},
+	{Name: "topn_push_down", Description: "turn the sort with a limit into a top-N, and push the top-N and the limit down through the projections and the outer joins"},
+}
+
+// IsOptRuleDisabled checks if the rule is skipped with the rules disabled by tidb_opt_disable_rules.
+func IsOptRuleDisabled(disabled map[string]struct{}, rule OptRuleInfo) bool {
+	if _, ok := disabled[rule.Name]; ok {
+		return true
+	}
+	_, ok := disabled[rule.dependsOn]
+	return ok
+}
+
+// CheckOptRules checks the rules of tidb_opt_disable_rules are known logical optimizing rules and can be disabled.
+func CheckOptRules(disabled map[string]struct{}) error {
+	for name := range disabled {
+		canDisable := false
+		for _, rule := range OptRules {
+			if rule.Name == name {
+				canDisable = !rule.required
+				break
+			}
+		}
+		if !canDisable {
+			return variable.ErrWrongValueForVar.GenByArgs(variable.TiDBOptDisableRules, name)
+		}
+	}
+	return nil
+}
+
 // logicalOptRule means a logical optimizing rule, which contains decorrelate, ppd, column pruning, etc.
 type logicalOptRule interface {
 	optimize(LogicalPlan, context.Context, *idAllocator) (LogicalPlan, error)
@@ -120,7 +172,7 @@ func doOptimize(flag uint64, logic LogicalPlan, ctx context.Context, allocator *
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	if !AllowCartesianProduct && existsCartesianProduct(logic) {
+	if !ctx.GetSessionVars().AllowCartesianProduct && existsCartesianProduct(logic) {
 		return nil, errors.Trace(ErrCartesianProductUnsupported)
 	}
 	var physical PhysicalPlan
@@ -145,6 +197,9 @@ func logicalOptimize(flag uint64, logic LogicalPlan, ctx context.Context, alloc
 		if flag&(1<<uint(i)) == 0 {
 			continue
 		}
+		if IsOptRuleDisabled(ctx.GetSessionVars().DisabledOptRules, OptRules[i]) {
+			continue
+		}
 		logic, err = rule.optimize(logic, ctx, alloc)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -211,9 +266,13 @@ const (
 	CodeUnsupported         terror.ErrCode = 4
 	CodeInvalidGroupFuncUse terror.ErrCode = 5
 	CodeIllegalReference    terror.ErrCode = 6
+	CodeInvalidWindowUse    terror.ErrCode = 7
+	CodeWindowFrameIllegal  terror.ErrCode = 8
 
 	// MySQL error code.
-	CodeNoDB terror.ErrCode = mysql.ErrNoDB
+	CodeNoDB          terror.ErrCode = mysql.ErrNoDB
+	CodeTooManyTables terror.ErrCode = mysql.ErrTooManyTables
+	CodeNoSuchThread  terror.ErrCode = mysql.ErrNoSuchThread
 )
 
 // Optimizer base errors.
@@ -223,7 +282,12 @@ var (
 	ErrCartesianProductUnsupported = terror.ClassOptimizer.New(CodeUnsupported, "Cartesian product is unsupported")
 	ErrInvalidGroupFuncUse         = terror.ClassOptimizer.New(CodeInvalidGroupFuncUse, "Invalid use of group function")
 	ErrIllegalReference            = terror.ClassOptimizer.New(CodeIllegalReference, "Illegal reference")
+	ErrInvalidWindowUse            = terror.ClassOptimizer.New(CodeInvalidWindowUse, "Window function is only allowed in the select field list")
+	ErrWindowFrameIllegal          = terror.ClassOptimizer.New(CodeWindowFrameIllegal, "Window frame is illegal")
+	ErrWindowUnsupported           = terror.ClassOptimizer.New(CodeUnsupported, "Window function %s is unsupported")
 	ErrNoDB                        = terror.ClassOptimizer.New(CodeNoDB, "No database selected")
+	ErrTooManyTables               = terror.ClassOptimizer.New(CodeTooManyTables, "Too many tables; TiDB can only use %d tables in a join")
+	ErrNoSuchThread                = terror.ClassOptimizer.New(CodeNoSuchThread, "Unknown thread id: %d")
 )
 
 func init() {
@@ -233,7 +297,10 @@ func init() {
 		CodeInvalidGroupFuncUse: mysql.ErrInvalidGroupFuncUse,
 		CodeIllegalReference:    mysql.ErrIllegalReference,
 		CodeNoDB:                mysql.ErrNoDB,
+		CodeTooManyTables:       mysql.ErrTooManyTables,
+		CodeNoSuchThread:        mysql.ErrNoSuchThread,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 	expression.EvalAstExpr = evalAstExpr
+	ddl.BuildCheckExpr = BuildCheckExpr
 }
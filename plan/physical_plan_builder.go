@@ -18,6 +18,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
@@ -41,9 +42,6 @@ const (
 	joinFactor         = 0.3
 )
 
-// JoinConcurrency means the number of goroutines that participate in joining.
-var JoinConcurrency = 5
-
 func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	client := p.ctx.GetClient()
 	ts := PhysicalTableScan{
@@ -242,7 +240,7 @@ func (p *DataSource) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlan
 		p.storePlanInfo(prop, info)
 		return info, nil
 	}
-	indices, includeTableScan := availableIndices(p.indexHints, p.tableInfo)
+	indices, includeTableScan := availableIndices(p.indexHints, p.tableInfo, ast.HintForJoin)
 	if includeTableScan {
 		info, err = p.convert2TableScan(prop)
 		if err != nil {
@@ -465,7 +463,7 @@ func (p *LogicalJoin) convert2PhysicalPlanLeft(prop *requiredProperty, innerJoin
 		OtherConditions: p.OtherConditions,
 		SmallTable:      1,
 		// TODO: decide concurrency by data size.
-		Concurrency:   JoinConcurrency,
+		Concurrency:   p.ctx.GetSessionVars().HashJoinConcurrency,
 		DefaultValues: p.DefaultValues,
 	}.init(p.allocator, p.ctx)
 	join.SetSchema(p.schema)
@@ -534,7 +532,7 @@ func (p *LogicalJoin) convert2PhysicalPlanRight(prop *requiredProperty, innerJoi
 		RightConditions: p.RightConditions,
 		OtherConditions: p.OtherConditions,
 		// TODO: decide concurrency by data size.
-		Concurrency:   JoinConcurrency,
+		Concurrency:   p.ctx.GetSessionVars().HashJoinConcurrency,
 		DefaultValues: p.DefaultValues,
 	}.init(p.allocator, p.ctx)
 	join.SetSchema(p.schema)
@@ -687,7 +685,7 @@ func (p *LogicalJoin) convert2IndexNestedLoopJoinLeft(prop *requiredProperty, in
 	join := PhysicalHashJoin{
 		LeftConditions: p.LeftConditions,
 		// TODO: decide concurrency by data size.
-		Concurrency:   JoinConcurrency,
+		Concurrency:   p.ctx.GetSessionVars().HashJoinConcurrency,
 		DefaultValues: p.DefaultValues,
 		SmallTable:    1,
 	}.init(p.allocator, p.ctx)
@@ -763,7 +761,7 @@ func (p *LogicalJoin) convert2IndexNestedLoopJoinRight(prop *requiredProperty, i
 	join := PhysicalHashJoin{
 		RightConditions: p.RightConditions,
 		// TODO: decide concurrency by data size.
-		Concurrency:   JoinConcurrency,
+		Concurrency:   p.ctx.GetSessionVars().HashJoinConcurrency,
 		DefaultValues: p.DefaultValues,
 	}.init(p.allocator, p.ctx)
 	join.SetChildren(lInfo.p, rInfo.p)
@@ -1242,7 +1240,7 @@ func (p *Selection) makeScanController() *physicalPlanInfo {
 		corColConds []expression.Expression
 	)
 	ds := p.children[0].(*DataSource)
-	indices, _ := availableIndices(ds.indexHints, ds.tableInfo)
+	indices, _ := availableIndices(ds.indexHints, ds.tableInfo, ast.HintForJoin)
 	for _, expr := range p.Conditions {
 		if !expr.IsCorrelated() {
 			continue
@@ -1482,6 +1480,25 @@ func matchProp(ctx context.Context, target, new *requiredProperty) bool {
 	return true
 }
 
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *Window) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	// The window of a row may contain all the rows of the partition, so neither the order nor the limit can be pushed down.
+	info, err = p.children[0].(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	info = addPlanToResponse(p, info)
+	info = enforceProperty(prop, info)
+	return info, p.storePlanInfo(prop, info)
+}
+
 // convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
 func (p *Sort) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	info, err := p.getPlanInfo(prop)
@@ -1498,7 +1515,7 @@ func (p *Sort) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo,
 		props: make([]*columnProp, 0, len(p.ByItems)),
 	}
 	for _, by := range p.ByItems {
-		if col, ok := by.Expr.(*expression.Column); ok {
+		if col, ok := by.Expr.(*expression.Column); ok && !by.NullsHigh {
 			selfProp.props = append(selfProp.props, &columnProp{col: col, desc: by.Desc})
 		} else {
 			selfProp.props = nil
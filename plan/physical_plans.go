@@ -50,6 +50,7 @@ var (
 	_ PhysicalPlan = &TableDual{}
 	_ PhysicalPlan = &Union{}
 	_ PhysicalPlan = &Sort{}
+	_ PhysicalPlan = &Window{}
 	_ PhysicalPlan = &Update{}
 	_ PhysicalPlan = &Delete{}
 	_ PhysicalPlan = &SelectLock{}
@@ -1042,6 +1043,15 @@ func (p *Sort) Copy() PhysicalPlan {
 	return &np
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *Window) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.baseLogicalPlan = newBaseLogicalPlan(np.basePlan)
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *TopN) Copy() PhysicalPlan {
 	np := *p
@@ -1176,6 +1186,10 @@ func buildSchema(p PhysicalPlan) {
 		} else {
 			x.SetSchema(x.children[0].Schema().Clone())
 		}
+	case *Window:
+		windowCol := x.schema.Columns[x.schema.Len()-1]
+		x.SetSchema(x.children[0].Schema().Clone())
+		x.schema.Append(windowCol)
 	case *Union:
 		panic("Union shouldn't rebuild schema")
 	}
@@ -120,6 +120,9 @@ type requiredProp struct {
 	taskTp taskType
 	// expectedCnt means this operator may be closed after fetching expectedCnt records.
 	expectedCnt float64
+	// hintScope is the operation requiring the order, like ORDER BY or JOIN. Only the index hints of the scope
+	// restrict the access paths providing the order.
+	hintScope ast.IndexHintScope
 }
 
 func (p *requiredProp) equal(prop *requiredProp) bool {
@@ -141,15 +144,25 @@ func (p *requiredProp) isEmpty() bool {
 	return len(p.cols) == 0
 }
 
+// indexHintScope returns the scope of the index hints restricting the access paths for the prop.
+// The rows are found by the indices of the FOR JOIN scope if no order is required.
+func (p *requiredProp) indexHintScope() ast.IndexHintScope {
+	if p.isEmpty() || p.hintScope == 0 {
+		return ast.HintForJoin
+	}
+	return p.hintScope
+}
+
 // getHashKey encodes prop to a unique key. The key will be stored in the memory table.
 func (p *requiredProp) getHashKey() ([]byte, error) {
-	datums := make([]types.Datum, 0, len(p.cols)*2+3)
+	datums := make([]types.Datum, 0, len(p.cols)*2+4)
 	datums = append(datums, types.NewDatum(p.desc))
 	for _, c := range p.cols {
 		datums = append(datums, types.NewDatum(c.FromID), types.NewDatum(c.Position))
 	}
 	datums = append(datums, types.NewDatum(int(p.taskTp)))
 	datums = append(datums, types.NewDatum(p.expectedCnt))
+	datums = append(datums, types.NewDatum(int(p.hintScope)))
 	bytes, err := codec.EncodeValue(nil, datums...)
 	return bytes, errors.Trace(err)
 }
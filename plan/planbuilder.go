@@ -23,45 +23,65 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
 )
 
 // Error instances.
 var (
-	ErrUnsupportedType      = terror.ClassOptimizerPlan.New(CodeUnsupportedType, "Unsupported type")
-	SystemInternalErrorType = terror.ClassOptimizerPlan.New(SystemInternalError, "System internal error")
-	ErrUnknownColumn        = terror.ClassOptimizerPlan.New(CodeUnknownColumn, mysql.MySQLErrName[mysql.ErrBadField])
-	ErrUnknownTable         = terror.ClassOptimizerPlan.New(CodeUnknownColumn, mysql.MySQLErrName[mysql.ErrBadTable])
-	ErrWrongArguments       = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
-	ErrAmbiguous            = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in field list is ambiguous")
-	ErrAnalyzeMissIndex     = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
-	ErrAlterAutoID          = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
-	ErrBadGeneratedColumn   = terror.ClassOptimizerPlan.New(CodeBadGeneratedColumn, mysql.MySQLErrName[mysql.ErrBadGeneratedColumn])
+	ErrUnsupportedType         = terror.ClassOptimizerPlan.New(CodeUnsupportedType, "Unsupported type")
+	SystemInternalErrorType    = terror.ClassOptimizerPlan.New(SystemInternalError, "System internal error")
+	ErrUnknownColumn           = terror.ClassOptimizerPlan.New(CodeUnknownColumn, mysql.MySQLErrName[mysql.ErrBadField])
+	ErrUnknownTable            = terror.ClassOptimizerPlan.New(CodeUnknownColumn, mysql.MySQLErrName[mysql.ErrBadTable])
+	ErrWrongArguments          = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
+	ErrAmbiguous               = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in field list is ambiguous")
+	ErrAnalyzeMissIndex        = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
+	ErrAlterAutoID             = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
+	ErrBadGeneratedColumn      = terror.ClassOptimizerPlan.New(CodeBadGeneratedColumn, mysql.MySQLErrName[mysql.ErrBadGeneratedColumn])
+	ErrNonUniqTable            = terror.ClassOptimizerPlan.New(CodeNonUniqTable, mysql.MySQLErrName[mysql.ErrNonuniqTable])
+	ErrDupFieldName            = terror.ClassOptimizerPlan.New(CodeDupFieldName, mysql.MySQLErrName[mysql.ErrDupFieldName])
+	ErrViewWrongList           = terror.ClassOptimizerPlan.New(CodeViewWrongList, "In definition of view, derived table or common table expression, SELECT list and column names list have different column counts")
+	ErrFieldNotInGroupBy       = terror.ClassOptimizerPlan.New(CodeFieldNotInGroupBy, "Expression #%d of %s is not in GROUP BY clause and contains nonaggregated column '%s' which is not functionally dependent on columns in GROUP BY clause; this is incompatible with sql_mode=only_full_group_by")
+	ErrMixOfGroupFuncAndFields = terror.ClassOptimizerPlan.New(CodeMixOfGroupFuncAndFields, "In aggregated query without GROUP BY, expression #%d of %s contains nonaggregated column '%s'; this is incompatible with sql_mode=only_full_group_by")
+	ErrKeyDoesNotExist         = terror.ClassOptimizerPlan.New(CodeKeyDoesNotExist, mysql.MySQLErrName[mysql.ErrKeyDoesNotExits])
 )
 
 // Error codes.
 const (
-	CodeUnsupportedType    terror.ErrCode = 1
-	SystemInternalError                   = 2
-	CodeAlterAutoID                       = 3
-	CodeAnalyzeMissIndex                  = 4
-	CodeAmbiguous                         = 1052
-	CodeUnknownColumn                     = mysql.ErrBadField
-	CodeUnknownTable                      = mysql.ErrBadTable
-	CodeWrongArguments                    = 1210
-	CodeBadGeneratedColumn                = mysql.ErrBadGeneratedColumn
+	CodeUnsupportedType         terror.ErrCode = 1
+	SystemInternalError                        = 2
+	CodeAlterAutoID                            = 3
+	CodeAnalyzeMissIndex                       = 4
+	CodeAmbiguous                              = 1052
+	CodeUnknownColumn                          = mysql.ErrBadField
+	CodeUnknownTable                           = mysql.ErrBadTable
+	CodeWrongArguments                         = 1210
+	CodeBadGeneratedColumn                     = mysql.ErrBadGeneratedColumn
+	CodeNonUniqTable                           = mysql.ErrNonuniqTable
+	CodeDupFieldName                           = mysql.ErrDupFieldName
+	CodeViewWrongList                          = mysql.ErrViewWrongList
+	CodeFieldNotInGroupBy                      = mysql.ErrWrongFieldWithGroup
+	CodeMixOfGroupFuncAndFields                = mysql.ErrMixOfGroupFuncAndFields
+	CodeKeyDoesNotExist                        = mysql.ErrKeyDoesNotExits
 )
 
 func init() {
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeUnknownColumn:      mysql.ErrBadField,
-		CodeUnknownTable:       mysql.ErrBadTable,
-		CodeAmbiguous:          mysql.ErrNonUniq,
-		CodeWrongArguments:     mysql.ErrWrongArguments,
-		CodeBadGeneratedColumn: mysql.ErrBadGeneratedColumn,
+		CodeUnknownColumn:           mysql.ErrBadField,
+		CodeUnknownTable:            mysql.ErrBadTable,
+		CodeAmbiguous:               mysql.ErrNonUniq,
+		CodeWrongArguments:          mysql.ErrWrongArguments,
+		CodeBadGeneratedColumn:      mysql.ErrBadGeneratedColumn,
+		CodeNonUniqTable:            mysql.ErrNonuniqTable,
+		CodeDupFieldName:            mysql.ErrDupFieldName,
+		CodeViewWrongList:           mysql.ErrViewWrongList,
+		CodeFieldNotInGroupBy:       mysql.ErrWrongFieldWithGroup,
+		CodeMixOfGroupFuncAndFields: mysql.ErrMixOfGroupFuncAndFields,
+		CodeKeyDoesNotExist:         mysql.ErrKeyDoesNotExits,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizerPlan] = tableMySQLErrCodes
 }
@@ -127,6 +147,8 @@ type planBuilder struct {
 	needColHandle int
 	// colMapper stores the column that must be pre-resolved.
 	colMapper map[*ast.ColumnNameExpr]int
+	// windowMapper maps the window functions to the column offsets in the schema of their Window plans.
+	windowMapper map[*ast.WindowFuncExpr]int
 	// Collect the visit information for privilege check.
 	visitInfo     []visitInfo
 	tableHintInfo []tableHintInfo
@@ -146,6 +168,8 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildExecute(x)
 	case *ast.ExplainStmt:
 		return b.buildExplain(x)
+	case *ast.ExplainForStmt:
+		return b.buildExplainFor(x)
 	case *ast.InsertStmt:
 		return b.buildInsert(x)
 	case *ast.LoadDataStmt:
@@ -167,7 +191,8 @@ func (b *planBuilder) build(node ast.Node) Plan {
 	case *ast.AnalyzeTableStmt:
 		return b.buildAnalyze(x)
 	case *ast.BinlogStmt, *ast.FlushStmt, *ast.UseStmt,
-		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt,
+		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.SavepointStmt, *ast.ReleaseSavepointStmt,
+		*ast.CreateUserStmt, *ast.SetPwdStmt,
 		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt:
 		return b.buildSimple(node.(ast.StmtNode))
 	case ast.DDLNode:
@@ -263,10 +288,29 @@ func (b *planBuilder) detectSelectAgg(sel *ast.SelectStmt) bool {
 	return false
 }
 
-func availableIndices(hints []*ast.IndexHint, tableInfo *model.TableInfo) (indices []*model.IndexInfo, includeTableScan bool) {
+// checkIndexHints checks that the indices in the index hints exist in the table.
+func checkIndexHints(hints []*ast.IndexHint, tableInfo *model.TableInfo) error {
+	for _, hint := range hints {
+		for _, idxName := range hint.IndexNames {
+			if idxName.L == "primary" && tableInfo.PKIsHandle {
+				continue
+			}
+			if findIndexByName(tableInfo.Indices, idxName) == nil {
+				return ErrKeyDoesNotExist.GenByArgs(idxName.O, tableInfo.Name.O)
+			}
+		}
+	}
+	return nil
+}
+
+// availableIndices returns the indices and whether the table scan can be used by the operation of the scope.
+// The hints without a FOR clause apply to all the operations, the hints of FOR JOIN apply to finding rows and joins,
+// the hints of FOR ORDER BY and FOR GROUP BY apply to providing the order for sorting and grouping. Only the hints
+// without a FOR clause are used if the scope is HintForScan.
+func availableIndices(hints []*ast.IndexHint, tableInfo *model.TableInfo, scope ast.IndexHintScope) (indices []*model.IndexInfo, includeTableScan bool) {
 	var usableHints []*ast.IndexHint
 	for _, hint := range hints {
-		if hint.HintScope == ast.HintForScan {
+		if hint.HintScope == ast.HintForScan || hint.HintScope == scope {
 			usableHints = append(usableHints, hint)
 		}
 	}
@@ -366,6 +410,29 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 	case ast.AdminShowDDL:
 		p = &ShowDDL{}
 		p.SetSchema(buildShowDDLFields())
+	case ast.AdminShowIndexUsage:
+		p = &ShowIndexUsage{}
+		p.SetSchema(buildShowIndexUsageFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminShowTopSQL:
+		topSQL := &ShowTopSQL{ByTime: as.TopSQLByTime, Count: int(as.TopSQLCount)}
+		if topSQL.Count == 0 {
+			topSQL.Count = defaultTopSQLCount
+		}
+		topSQL.SetSchema(buildShowTopSQLFields())
+		p = topSQL
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminShowSlow:
+		p = &ShowSlow{Top: as.ShowSlowTop, Count: int(as.ShowSlowCount)}
+		p.SetSchema(buildShowSlowFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminBlockDigests, ast.AdminUnblockDigests, ast.AdminUnblockAllDigests, ast.AdminRecoverAutoIncrement,
+		ast.AdminEnableSQLLog, ast.AdminDisableSQLLog:
+		p = &Simple{Statement: as}
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminRecommendIndex:
+		return b.buildRecommendIndex(as)
 	default:
 		b.err = ErrUnsupportedType.Gen("Unsupported type %T", as)
 	}
@@ -386,7 +453,7 @@ func getColsInfo(tn *ast.TableName) (indicesInfo []*model.IndexInfo, colsInfo []
 			}
 		}
 	}
-	indices, _ := availableIndices(tn.IndexHints, tn.TableInfo)
+	indices, _ := availableIndices(tn.IndexHints, tn.TableInfo, ast.HintForScan)
 	for _, index := range indices {
 		for _, idx := range tn.TableInfo.Indices {
 			if index.Name.L == idx.Name.L {
@@ -460,6 +527,41 @@ func buildShowDDLFields() *expression.Schema {
 	return schema
 }
 
+func buildShowIndexUsageFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 5)...)
+	schema.Append(buildColumn("", "Db_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "Table_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "Index_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "Access_count", mysql.TypeLonglong, 20))
+	schema.Append(buildColumn("", "Last_used", mysql.TypeDatetime, 19))
+	return schema
+}
+
+func buildShowTopSQLFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 7)...)
+	schema.Append(buildColumn("", "Digest", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "Query", mysql.TypeVarchar, 4096))
+	schema.Append(buildColumn("", "Exec_count", mysql.TypeLonglong, 20))
+	schema.Append(buildColumn("", "Cpu_time_ms", mysql.TypeDouble, 22))
+	schema.Append(buildColumn("", "Total_time_ms", mysql.TypeDouble, 22))
+	schema.Append(buildColumn("", "Avg_time_ms", mysql.TypeDouble, 22))
+	schema.Append(buildColumn("", "Last_seen", mysql.TypeDatetime, 19))
+	return schema
+}
+
+func buildShowSlowFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 8)...)
+	schema.Append(buildColumn("", "Start_time", mysql.TypeDatetime, 26))
+	schema.Append(buildColumn("", "Duration_ms", mysql.TypeDouble, 22))
+	schema.Append(buildColumn("", "Conn_id", mysql.TypeLonglong, 20))
+	schema.Append(buildColumn("", "User", mysql.TypeVarchar, 32))
+	schema.Append(buildColumn("", "Host", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "Db", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "Query", mysql.TypeVarchar, 4096))
+	schema.Append(buildColumn("", "Trace_id", mysql.TypeVarchar, 64))
+	return schema
+}
+
 func buildColumn(tableName, name string, tp byte, size int) *expression.Column {
 	cs, cl := types.DefaultCharsetForType(tp)
 	flag := mysql.UnsignedFlag
@@ -507,13 +609,14 @@ func splitWhere(where ast.ExprNode) []ast.ExprNode {
 func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 	var resultPlan Plan
 	p := Show{
-		Tp:     show.Tp,
-		DBName: show.DBName,
-		Table:  show.Table,
-		Column: show.Column,
-		Flag:   show.Flag,
-		Full:   show.Full,
-		User:   show.User,
+		Tp:          show.Tp,
+		DBName:      show.DBName,
+		Table:       show.Table,
+		Column:      show.Column,
+		Flag:        show.Flag,
+		Full:        show.Full,
+		User:        show.User,
+		GlobalScope: show.GlobalScope,
 	}.init(b.allocator, b.ctx)
 	resultPlan = p
 	switch show.Tp {
@@ -624,6 +727,33 @@ func (b *planBuilder) findDefaultValue(cols []*table.Column, name *ast.ColumnNam
 	return nil, ErrUnknownColumn.GenByArgs(name.Name.O, "field_list")
 }
 
+// buildInsertDefault resolves the DEFAULT at the offset of a VALUES list. The bare DEFAULT
+// refers to the offset-th column of the insert column list, or of the table if the list is omitted.
+func (b *planBuilder) buildInsertDefault(dft *ast.DefaultExpr, cols []*table.Column, insertCols []*ast.ColumnName, offset int) (expression.Expression, error) {
+	if dft.Name != nil {
+		return b.findDefaultValue(cols, dft.Name)
+	}
+	if len(insertCols) > 0 {
+		if offset >= len(insertCols) {
+			// The value count mismatch is reported by the executor.
+			return expression.Null.Clone(), nil
+		}
+		return b.findDefaultValue(cols, insertCols[offset])
+	}
+	if offset >= len(cols) {
+		return expression.Null.Clone(), nil
+	}
+	return b.getDefaultValue(cols[offset])
+}
+
+// buildAssignDefault resolves the DEFAULT on the right side of an assignment to column name.
+func (b *planBuilder) buildAssignDefault(dft *ast.DefaultExpr, cols []*table.Column, name *ast.ColumnName) (expression.Expression, error) {
+	if dft.Name != nil {
+		name = dft.Name
+	}
+	return b.findDefaultValue(cols, name)
+}
+
 func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 	ts, ok := insert.Table.TableRefs.Left.(*ast.TableSource)
 	if !ok {
@@ -684,11 +814,7 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 			var expr expression.Expression
 			var err error
 			if dft, ok := valueItem.(*ast.DefaultExpr); ok {
-				if dft.Name != nil {
-					expr, err = b.findDefaultValue(cols, dft.Name)
-				} else {
-					expr, err = b.getDefaultValue(cols[i])
-				}
+				expr, err = b.buildInsertDefault(dft, cols, insert.Columns, i)
 			} else if val, ok := valueItem.(*ast.ValueExpr); ok {
 				expr = &expression.Constant{
 					Value:   val.Datum,
@@ -746,7 +872,12 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		}
 		// Here we keep different behaviours with MySQL. MySQL allow set a = b, b = a and the result is NULL, NULL.
 		// It's unreasonable.
-		expr, _, err := b.rewrite(assign.Expr, mockTablePlan, nil, true)
+		var expr expression.Expression
+		if dft, ok := assign.Expr.(*ast.DefaultExpr); ok {
+			expr, err = b.buildAssignDefault(dft, cols, assign.Column)
+		} else {
+			expr, _, err = b.rewrite(assign.Expr, mockTablePlan, nil, true)
+		}
 		if err != nil {
 			b.err = errors.Trace(err)
 			return nil
@@ -773,7 +904,12 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 			b.err = ErrBadGeneratedColumn.GenByArgs(assign.Column.Name.O, tableInfo.Name.O)
 			return nil
 		}
-		expr, _, err := b.rewrite(assign.Expr, mockTablePlan, nil, true)
+		var expr expression.Expression
+		if dft, ok := assign.Expr.(*ast.DefaultExpr); ok {
+			expr, err = b.buildAssignDefault(dft, cols, assign.Column)
+		} else {
+			expr, _, err = b.rewrite(assign.Expr, mockTablePlan, nil, true)
+		}
 		if err != nil {
 			b.err = errors.Trace(err)
 			return nil
@@ -804,10 +940,125 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		}
 		addChild(insertPlan, selectPlan)
 	}
+	insertPlan.CheckConstraints = b.buildCheckConstraints(tableInfo)
+	if b.err != nil {
+		return nil
+	}
 	insertPlan.SetSchema(expression.NewSchema())
 	return insertPlan
 }
 
+// BuildCheckConstraints builds the public check constraints of the table for the rows written out of a plan,
+// e.g. the rows of CREATE TABLE ... SELECT.
+func BuildCheckConstraints(ctx context.Context, tblInfo *model.TableInfo) ([]*CheckConstraint, error) {
+	b := &planBuilder{
+		ctx:       ctx,
+		allocator: new(idAllocator),
+		colMapper: make(map[*ast.ColumnNameExpr]int),
+	}
+	checks := b.buildCheckConstraints(tblInfo)
+	return checks, errors.Trace(b.err)
+}
+
+// checkColumnResolver resolves the column names in the expression of a check constraint to the columns of the table.
+type checkColumnResolver struct {
+	tblInfo *model.TableInfo
+	err     error
+}
+
+// Enter implements ast.Visitor interface.
+func (r *checkColumnResolver) Enter(inNode ast.Node) (ast.Node, bool) {
+	return inNode, false
+}
+
+// Leave implements ast.Visitor interface.
+func (r *checkColumnResolver) Leave(inNode ast.Node) (ast.Node, bool) {
+	if cn, ok := inNode.(*ast.ColumnNameExpr); ok {
+		for _, col := range r.tblInfo.Columns {
+			if col.Name.L == cn.Name.Name.L {
+				cn.Refer = &ast.ResultField{Column: col, Table: r.tblInfo}
+				return inNode, true
+			}
+		}
+		r.err = ErrUnknownColumn.GenByArgs(cn.Name.Name.O, "check constraint")
+		return inNode, false
+	}
+	return inNode, true
+}
+
+// buildCheckConstraints builds the check constraints of the table which are enforced on the DML, they're public
+// or being added. The expressions are built on the public columns of the table, so they can be evaluated on the rows
+// written to the table.
+func (b *planBuilder) buildCheckConstraints(tblInfo *model.TableInfo) []*CheckConstraint {
+	if len(tblInfo.Checks) == 0 {
+		return nil
+	}
+	mockTablePlan := b.buildCheckTablePlan(tblInfo)
+	checks := make([]*CheckConstraint, 0, len(tblInfo.Checks))
+	for _, ck := range tblInfo.Checks {
+		if ck.State == model.StateNone {
+			continue
+		}
+		expr := b.buildCheckExpr(tblInfo, ck, mockTablePlan)
+		if b.err != nil {
+			return nil
+		}
+		checks = append(checks, &CheckConstraint{Name: ck.Name.O, Expr: expr})
+	}
+	return checks
+}
+
+// BuildCheckExpr builds the expression of the check constraint on the public columns of the table.
+// It's used to validate the existing rows when the constraint is added.
+func BuildCheckExpr(ctx context.Context, tblInfo *model.TableInfo, ckInfo *model.CheckInfo) (expression.Expression, error) {
+	b := &planBuilder{
+		ctx:       ctx,
+		allocator: new(idAllocator),
+		colMapper: make(map[*ast.ColumnNameExpr]int),
+	}
+	expr := b.buildCheckExpr(tblInfo, ckInfo, b.buildCheckTablePlan(tblInfo))
+	return expr, errors.Trace(b.err)
+}
+
+// buildCheckTablePlan builds a mock plan whose schema is made of the public columns of the table.
+func (b *planBuilder) buildCheckTablePlan(tblInfo *model.TableInfo) LogicalPlan {
+	colInfos := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col.State == model.StatePublic {
+			colInfos = append(colInfos, col)
+		}
+	}
+	mockTablePlan := TableDual{}.init(b.allocator, b.ctx)
+	mockTablePlan.SetSchema(expression.NewSchema(expression.ColumnInfos2Columns(tblInfo.Name, colInfos)...))
+	return mockTablePlan
+}
+
+func (b *planBuilder) buildCheckExpr(tblInfo *model.TableInfo, ck *model.CheckInfo, p LogicalPlan) expression.Expression {
+	stmts, err := parser.New().Parse("select "+ck.ExprString, "", "")
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	node := stmts[0].(*ast.SelectStmt).Fields.Fields[0].Expr
+	resolver := &checkColumnResolver{tblInfo: tblInfo}
+	node.Accept(resolver)
+	if resolver.err != nil {
+		b.err = errors.Trace(resolver.err)
+		return nil
+	}
+	if err = expression.InferType(b.ctx.GetSessionVars().StmtCtx, node); err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	expr, _, err := b.rewrite(node, p, nil, true)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	expr.ResolveIndices(p.Schema())
+	return expr
+}
+
 func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 	p := &LoadData{
 		IsLocal:    ld.IsLocal,
@@ -817,11 +1068,16 @@ func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 		FieldsInfo: ld.FieldsInfo,
 		LinesInfo:  ld.LinesInfo,
 	}
+	p.CheckConstraints = b.buildCheckConstraints(ld.Table.TableInfo)
+	if b.err != nil {
+		return nil
+	}
 	p.SetSchema(expression.NewSchema())
 	return p
 }
 
 func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
+	var selectPlan Plan
 	switch v := node.(type) {
 	case *ast.AlterTableStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
@@ -853,6 +1109,19 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 				table:     v.ReferTable.Name.L,
 			})
 		}
+		if v.Select != nil {
+			b.visitInfo = append(b.visitInfo, visitInfo{
+				privilege: mysql.InsertPriv,
+				db:        v.Table.Schema.L,
+				table:     v.Table.Name.L,
+			})
+			var err error
+			selectPlan, err = Optimize(b.ctx, v.Select, b.is)
+			if err != nil {
+				b.err = errors.Trace(err)
+				return nil
+			}
+		}
 	case *ast.DropDatabaseStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.DropPriv,
@@ -879,19 +1148,21 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 			table:     v.Table.Name.L,
 		})
 	case *ast.RenameTableStmt:
-		b.visitInfo = append(b.visitInfo, visitInfo{
-			privilege: mysql.AlterPriv,
-			db:        v.OldTable.Schema.L,
-			table:     v.OldTable.Name.L,
-		})
-		b.visitInfo = append(b.visitInfo, visitInfo{
-			privilege: mysql.AlterPriv,
-			db:        v.NewTable.Schema.L,
-			table:     v.NewTable.Name.L,
-		})
+		for _, t := range v.TableToTables {
+			b.visitInfo = append(b.visitInfo, visitInfo{
+				privilege: mysql.AlterPriv,
+				db:        t.OldTable.Schema.L,
+				table:     t.OldTable.Name.L,
+			})
+			b.visitInfo = append(b.visitInfo, visitInfo{
+				privilege: mysql.AlterPriv,
+				db:        t.NewTable.Schema.L,
+				table:     t.NewTable.Name.L,
+			})
+		}
 	}
 
-	p := &DDL{Statement: node}
+	p := &DDL{Statement: node, SelectPlan: selectPlan}
 	p.SetSchema(expression.NewSchema())
 	return p
 }
@@ -905,8 +1176,46 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 		b.err = errors.Trace(err)
 		return nil
 	}
-	setParents4FinalPlan(targetPlan.(PhysicalPlan))
-	p := &Explain{StmtPlan: targetPlan}
+	return b.buildExplainPlan(targetPlan.(PhysicalPlan))
+}
+
+// buildExplainFor explains the plan of the statement running on the connection, the result is empty
+// if the connection isn't running a statement. The plan is being executed by the other session,
+// it's only read by the explanation.
+func (b *planBuilder) buildExplainFor(explainFor *ast.ExplainForStmt) Plan {
+	var pi *util.ProcessInfo
+	if sm := b.ctx.GetSessionManager(); sm != nil {
+		for _, info := range sm.ShowProcessList() {
+			if info.ID == explainFor.ConnectionID {
+				pi = &info
+				break
+			}
+		}
+	}
+	if pi == nil {
+		b.err = ErrNoSuchThread.GenByArgs(explainFor.ConnectionID)
+		return nil
+	}
+	if user := b.ctx.GetSessionVars().User; user == nil || user.Username != pi.User {
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ProcessPriv, "", "", "")
+	}
+	if pi.Plan == nil {
+		return b.buildExplainPlan(nil)
+	}
+	targetPlan, ok := pi.Plan.(PhysicalPlan)
+	if _, isShow := pi.Plan.(*Show); !ok || isShow {
+		b.err = ErrUnsupportedType.Gen("EXPLAIN FOR CONNECTION doesn't support %s", pi.Info)
+		return nil
+	}
+	return b.buildExplainPlan(targetPlan)
+}
+
+// buildExplainPlan builds the plan which explains the target plan, the result is empty if the target plan is nil.
+func (b *planBuilder) buildExplainPlan(targetPlan PhysicalPlan) Plan {
+	p := &Explain{StmtPlan: targetPlan, parents: make(map[string][]string)}
+	if targetPlan != nil {
+		collectParents4FinalPlan(targetPlan, p.parents)
+	}
 	if UseDAGPlanBuilder(b.ctx) {
 		retFields := []string{"id", "parents", "children", "task", "operator info"}
 		schema := expression.NewSchema(make([]*expression.Column, 0, len(retFields))...)
@@ -915,15 +1224,19 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 		}
 		schema.Append(buildColumn("", "count", mysql.TypeDouble, mysql.MaxRealWidth))
 		p.SetSchema(schema)
-		p.explainedPlans = map[string]bool{}
-		p.prepareRootTaskInfo(p.StmtPlan.(PhysicalPlan))
+		if targetPlan != nil {
+			p.explainedPlans = map[string]bool{}
+			p.prepareRootTaskInfo(targetPlan)
+		}
 	} else {
 		schema := expression.NewSchema(make([]*expression.Column, 0, 3)...)
 		schema.Append(buildColumn("", "ID", mysql.TypeString, mysql.MaxBlobWidth))
 		schema.Append(buildColumn("", "Json", mysql.TypeString, mysql.MaxBlobWidth))
 		schema.Append(buildColumn("", "ParentID", mysql.TypeString, mysql.MaxBlobWidth))
 		p.SetSchema(schema)
-		p.prepareExplainInfo(p.StmtPlan, nil)
+		if targetPlan != nil {
+			p.prepareExplainInfo(targetPlan, nil)
+		}
 	}
 	return p
 }
@@ -1086,6 +1399,9 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 			"Repeats", "Lower_Bound", "Upper_Bound"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeTiny, mysql.TypeLonglong,
 			mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar}
+	case ast.ShowOptRules:
+		names = []string{"Name", "Description", "Disabled"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeTiny}
 	}
 	return composeShowSchema(names, ftypes)
 }
@@ -32,6 +32,32 @@ type ShowDDL struct {
 	basePlan
 }
 
+// ShowIndexUsage is for showing the access statistics of the indices, built from the 'admin show index usage' statement.
+type ShowIndexUsage struct {
+	basePlan
+}
+
+// ShowTopSQL is for showing the statements consuming the most resources recently, built from the 'admin show top sql' statement.
+type ShowTopSQL struct {
+	basePlan
+
+	// ByTime ranks the statements by the total execution time, otherwise by the CPU time.
+	ByTime bool
+	Count  int
+}
+
+// defaultTopSQLCount is the number of the statements returned by 'admin show top sql' if it isn't specified.
+const defaultTopSQLCount = 10
+
+// ShowSlow is for showing the slow queries kept in memory, built from the 'admin show slow' statement.
+type ShowSlow struct {
+	basePlan
+
+	// Top returns the slowest queries, otherwise the most recent ones.
+	Top   bool
+	Count int
+}
+
 // CheckTable is used for checking table data, built from the 'admin check table' statement.
 type CheckTable struct {
 	basePlan
@@ -39,6 +65,13 @@ type CheckTable struct {
 	Tables []*ast.TableName
 }
 
+// RecommendIndex is used for recommending indexes for a query, built from the 'admin recommend index' statement.
+type RecommendIndex struct {
+	basePlan
+
+	Rows [][]types.Datum
+}
+
 // SelectLock represents a select lock plan.
 type SelectLock struct {
 	*basePlan
@@ -116,12 +149,20 @@ type Insert struct {
 	Lists       [][]expression.Expression
 	Setlist     []*expression.Assignment
 	OnDuplicate []*expression.Assignment
+	// CheckConstraints are evaluated on the inserted and updated rows of the table.
+	CheckConstraints []*CheckConstraint
 
 	IsReplace bool
 	Priority  mysql.PriorityEnum
 	Ignore    bool
 }
 
+// CheckConstraint is a check constraint of a table, its expression is evaluated on the rows made of the public columns of the table.
+type CheckConstraint struct {
+	Name string
+	Expr expression.Expression
+}
+
 // AnalyzeColumnsTask is used for analyze columns.
 type AnalyzeColumnsTask struct {
 	TableInfo *model.TableInfo
@@ -153,6 +194,8 @@ type LoadData struct {
 	Columns    []*ast.ColumnName
 	FieldsInfo *ast.FieldsClause
 	LinesInfo  *ast.LinesClause
+
+	CheckConstraints []*CheckConstraint
 }
 
 // DDL represents a DDL statement plan.
@@ -160,6 +203,8 @@ type DDL struct {
 	basePlan
 
 	Statement ast.DDLNode
+	// SelectPlan is the plan of the query in CREATE TABLE ... SELECT.
+	SelectPlan Plan
 }
 
 // Explain represents a explain plan.
@@ -169,6 +214,8 @@ type Explain struct {
 	StmtPlan       Plan
 	Rows           [][]types.Datum
 	explainedPlans map[string]bool
+	// parents are the IDs of the parents of the plans, see collectParents4FinalPlan.
+	parents map[string][]string
 }
 
 func (e *Explain) prepareExplainInfo(p Plan, parent Plan) error {
@@ -194,16 +241,11 @@ func (e *Explain) prepareExplainInfo(p Plan, parent Plan) error {
 // prepareExplainInfo4DAGTask generates the following information for every plan:
 // ["id", "parents", "task", "operator info"].
 func (e *Explain) prepareExplainInfo4DAGTask(p PhysicalPlan, taskType string) {
-	parents := p.Parents()
-	parentIDs := make([]string, 0, len(parents))
-	for _, parent := range parents {
-		parentIDs = append(parentIDs, parent.ID())
-	}
 	childrenIDs := make([]string, 0, len(p.Children()))
 	for _, ch := range p.Children() {
 		childrenIDs = append(childrenIDs, ch.ID())
 	}
-	parentInfo := strings.Join(parentIDs, ",")
+	parentInfo := strings.Join(e.parents[p.ID()], ",")
 	childrenInfo := strings.Join(childrenIDs, ",")
 	operatorInfo := p.ExplainInfo()
 	count := p.statsProfile().count
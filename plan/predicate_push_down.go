@@ -427,6 +427,13 @@ func (p *Limit) PredicatePushDown(predicates []expression.Expression) ([]express
 	return predicates, p, errors.Trace(err)
 }
 
+// PredicatePushDown implements LogicalPlan PredicatePushDown interface.
+func (p *Window) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
+	// Window forbids any condition to push down, because the window of a row contains the other rows.
+	_, _, err := p.baseLogicalPlan.PredicatePushDown(nil)
+	return predicates, p, errors.Trace(err)
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *MaxOneRow) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	// MaxOneRow forbids any condition to push down.
@@ -14,11 +14,12 @@
 package plan
 
 import (
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
 )
 
 func (p *DataSource) preparePossibleProperties() (result [][]*expression.Column) {
-	indices, includeTS := availableIndices(p.indexHints, p.tableInfo)
+	indices, includeTS := availableIndices(p.indexHints, p.tableInfo, ast.HintForScan)
 	if includeTS {
 		col := p.getPKIsHandleCol()
 		if col != nil {
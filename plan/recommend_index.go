@@ -0,0 +1,345 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// indexCandidate is a hypothetical index which may reduce the cost of a query.
+type indexCandidate struct {
+	dbName  model.CIStr
+	table   *model.TableInfo
+	columns []*model.ColumnInfo
+}
+
+func (c *indexCandidate) name() string {
+	names := make([]string, 0, len(c.columns))
+	for _, col := range c.columns {
+		names = append(names, col.Name.L)
+	}
+	return "idx_" + strings.Join(names, "_")
+}
+
+// statement returns the DDL statement which creates the index.
+func (c *indexCandidate) statement() string {
+	cols := make([]string, 0, len(c.columns))
+	for _, col := range c.columns {
+		cols = append(cols, fmt.Sprintf("`%s`", col.Name.O))
+	}
+	return fmt.Sprintf("ALTER TABLE `%s`.`%s` ADD INDEX `%s`(%s)", c.dbName.O, c.table.Name.O, c.name(), strings.Join(cols, ", "))
+}
+
+// indexInfo builds the hypothetical index, its ID doesn't conflict with the existing indices of the table,
+// so the index has no statistics and its row count is estimated in the pseudo way.
+func (c *indexCandidate) indexInfo() *model.IndexInfo {
+	var maxID int64
+	for _, idx := range c.table.Indices {
+		if idx.ID > maxID {
+			maxID = idx.ID
+		}
+	}
+	idx := &model.IndexInfo{
+		ID:    maxID + 1,
+		Name:  model.NewCIStr(c.name()),
+		Table: c.table.Name,
+		State: model.StatePublic,
+	}
+	for _, col := range c.columns {
+		idx.Columns = append(idx.Columns, &model.IndexColumn{
+			Name:   col.Name,
+			Offset: col.Offset,
+			Length: types.UnspecifiedLength,
+		})
+	}
+	return idx
+}
+
+// indexCandidate returns the index built from the pushed down conditions of the DataSource. The columns compared
+// with constants by equality come first, followed by at most one column compared with a constant by range.
+func (p *DataSource) indexCandidate() *indexCandidate {
+	if infoschema.IsMemoryDB(p.DBName.L) {
+		return nil
+	}
+	var eqCols, rangeCols []*model.ColumnInfo
+	for _, cond := range p.pushedDownConds {
+		sf, ok := cond.(*expression.ScalarFunction)
+		if !ok {
+			continue
+		}
+		var col *model.ColumnInfo
+		if sf.FuncName.L == ast.LogicOr {
+			col = p.indexableColumn(columnOfDNF(sf))
+		} else {
+			col = p.indexableColumn(columnComparedWithConstants(sf))
+		}
+		if col == nil {
+			continue
+		}
+		switch sf.FuncName.L {
+		case ast.EQ, ast.NullEQ, ast.In, ast.LogicOr:
+			eqCols = appendColumnInfo(eqCols, col)
+		case ast.LT, ast.LE, ast.GT, ast.GE:
+			rangeCols = appendColumnInfo(rangeCols, col)
+		}
+	}
+	for _, col := range rangeCols {
+		if !containsColumnInfo(eqCols, col) {
+			eqCols = append(eqCols, col)
+			break
+		}
+	}
+	if len(eqCols) == 0 {
+		return nil
+	}
+	candidate := &indexCandidate{dbName: p.DBName, table: p.tableInfo, columns: eqCols}
+	// The recommended statement would fail if the table has an index of the same name.
+	name := candidate.name()
+	for _, idx := range p.tableInfo.Indices {
+		if idx.Name.L == name {
+			return nil
+		}
+	}
+	return candidate
+}
+
+// indexableColumn returns the column info of col if it can be the column of an index without a prefix length.
+// The integer primary key isn't indexable because the table is already ordered by it.
+func (p *DataSource) indexableColumn(col *expression.Column) *model.ColumnInfo {
+	if col == nil {
+		return nil
+	}
+	for _, colInfo := range p.tableInfo.Columns {
+		if colInfo.Name.L != col.ColName.L {
+			continue
+		}
+		if p.tableInfo.PKIsHandle && mysql.HasPriKeyFlag(colInfo.Flag) {
+			return nil
+		}
+		if types.IsTypeBlob(colInfo.Tp) || colInfo.Tp == mysql.TypeJSON {
+			return nil
+		}
+		return colInfo
+	}
+	return nil
+}
+
+// columnComparedWithConstants returns the column if the function only compares one column with constants,
+// such as `a > 1` and `a in (1, 2)`.
+func columnComparedWithConstants(sf *expression.ScalarFunction) *expression.Column {
+	var col *expression.Column
+	for _, arg := range sf.GetArgs() {
+		switch x := arg.(type) {
+		case *expression.Column:
+			if col != nil {
+				return nil
+			}
+			col = x
+		case *expression.Constant:
+		default:
+			return nil
+		}
+	}
+	return col
+}
+
+// columnOfDNF returns the column if every item of the DNF compares the same column with constants by equality,
+// such as `a = 1 or a = 2`, the small IN lists are rewritten to this form.
+func columnOfDNF(sf *expression.ScalarFunction) *expression.Column {
+	var col *expression.Column
+	for _, item := range expression.SplitDNFItems(sf) {
+		f, ok := item.(*expression.ScalarFunction)
+		if !ok || (f.FuncName.L != ast.EQ && f.FuncName.L != ast.In) {
+			return nil
+		}
+		c := columnComparedWithConstants(f)
+		if c == nil || (col != nil && !c.Equal(col, nil)) {
+			return nil
+		}
+		col = c
+	}
+	return col
+}
+
+func appendColumnInfo(cols []*model.ColumnInfo, col *model.ColumnInfo) []*model.ColumnInfo {
+	if containsColumnInfo(cols, col) {
+		return cols
+	}
+	return append(cols, col)
+}
+
+func containsColumnInfo(cols []*model.ColumnInfo, col *model.ColumnInfo) bool {
+	for _, c := range cols {
+		if c.ID == col.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func collectDataSources(p LogicalPlan, dataSources []*DataSource) []*DataSource {
+	if ds, ok := p.(*DataSource); ok {
+		return append(dataSources, ds)
+	}
+	for _, child := range p.Children() {
+		dataSources = collectDataSources(child.(LogicalPlan), dataSources)
+	}
+	return dataSources
+}
+
+// buildOptimizedLogicalPlan builds the logical plan of the query and applies the logical optimization rules.
+func (b *planBuilder) buildOptimizedLogicalPlan(node ast.Node) (LogicalPlan, error) {
+	if err := expression.InferType(b.ctx.GetSessionVars().StmtCtx, node); err != nil {
+		return nil, errors.Trace(err)
+	}
+	builder := &planBuilder{
+		ctx:       b.ctx,
+		is:        b.is,
+		colMapper: make(map[*ast.ColumnNameExpr]int),
+		allocator: new(idAllocator),
+	}
+	p := builder.build(node)
+	if builder.err != nil {
+		return nil, errors.Trace(builder.err)
+	}
+	if pm := privilege.GetPrivilegeManager(b.ctx); pm != nil {
+		if !checkPrivilege(pm, builder.visitInfo) {
+			return nil, errors.New("privilege check fail")
+		}
+	}
+	logic, ok := p.(LogicalPlan)
+	if !ok {
+		return nil, ErrUnsupportedType.Gen("Unsupported type %T", p)
+	}
+	logic, err := logicalOptimize(builder.optFlag, logic, b.ctx, builder.allocator)
+	return logic, errors.Trace(err)
+}
+
+// estimateCost returns the cost of the best physical plan of the logical plan.
+func estimateCost(logic LogicalPlan) (float64, error) {
+	logic.preparePossibleProperties()
+	logic.prepareStatsProfile()
+	t, err := logic.convert2NewPhysicalPlan(&requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64})
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return t.cost(), nil
+}
+
+// estimateCostWithIndex returns the cost of the query as if the table of the candidate had the index.
+func (b *planBuilder) estimateCostWithIndex(node ast.Node, candidate *indexCandidate) (float64, error) {
+	logic, err := b.buildOptimizedLogicalPlan(node)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	idx := candidate.indexInfo()
+	for _, ds := range collectDataSources(logic, nil) {
+		if ds.tableInfo.ID != candidate.table.ID {
+			continue
+		}
+		tblInfo := *ds.tableInfo
+		tblInfo.Indices = make([]*model.IndexInfo, 0, len(ds.tableInfo.Indices)+1)
+		tblInfo.Indices = append(tblInfo.Indices, ds.tableInfo.Indices...)
+		tblInfo.Indices = append(tblInfo.Indices, idx)
+		ds.tableInfo = &tblInfo
+	}
+	cost, err := estimateCost(logic)
+	return cost, errors.Trace(err)
+}
+
+type indexRecommendation struct {
+	candidate     *indexCandidate
+	costWithIndex float64
+}
+
+// buildRecommendIndex evaluates an index candidate for every table which is filtered by the query, the candidates
+// which reduce the estimated cost of the query are recommended in the order of the cost with the index.
+func (b *planBuilder) buildRecommendIndex(as *ast.AdminStmt) Plan {
+	if !UseDAGPlanBuilder(b.ctx) {
+		b.err = ErrUnsupportedType.Gen("ADMIN RECOMMEND INDEX needs the cost based optimizer")
+		return nil
+	}
+	logic, err := b.buildOptimizedLogicalPlan(as.Stmt)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	var candidates []*indexCandidate
+	names := make(map[string]bool)
+	for _, ds := range collectDataSources(logic, nil) {
+		candidate := ds.indexCandidate()
+		if candidate == nil {
+			continue
+		}
+		name := fmt.Sprintf("%d.%s", candidate.table.ID, candidate.name())
+		if !names[name] {
+			names[name] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+	cost, err := estimateCost(logic)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	var recommendations []indexRecommendation
+	for _, candidate := range candidates {
+		costWithIndex, err := b.estimateCostWithIndex(as.Stmt, candidate)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		if costWithIndex < cost {
+			recommendations = append(recommendations, indexRecommendation{candidate: candidate, costWithIndex: costWithIndex})
+		}
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].costWithIndex < recommendations[j].costWithIndex
+	})
+
+	p := &RecommendIndex{}
+	for _, r := range recommendations {
+		c := r.candidate
+		cols := make([]string, 0, len(c.columns))
+		for _, col := range c.columns {
+			cols = append(cols, col.Name.O)
+		}
+		p.Rows = append(p.Rows, types.MakeDatums(c.dbName.O+"."+c.table.Name.O, strings.Join(cols, ","),
+			c.statement(), cost, r.costWithIndex))
+	}
+	p.SetSchema(buildRecommendIndexFields())
+	return p
+}
+
+func buildRecommendIndexFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 5)...)
+	schema.Append(buildColumn("", "Table", mysql.TypeVarchar, 128))
+	schema.Append(buildColumn("", "Columns", mysql.TypeVarchar, 128))
+	schema.Append(buildColumn("", "Statement", mysql.TypeVarchar, 1024))
+	schema.Append(buildColumn("", "Est_Cost", mysql.TypeDouble, mysql.MaxRealWidth))
+	schema.Append(buildColumn("", "Est_Cost_With_Index", mysql.TypeDouble, mysql.MaxRealWidth))
+	return schema
+}
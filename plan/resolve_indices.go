@@ -201,6 +201,20 @@ func (p *Sort) ResolveIndices() {
 	}
 }
 
+// ResolveIndices implements Plan interface.
+func (p *Window) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	for _, arg := range p.Args {
+		arg.ResolveIndices(p.children[0].Schema())
+	}
+	for _, expr := range p.PartitionBy {
+		expr.ResolveIndices(p.children[0].Schema())
+	}
+	for _, item := range p.OrderBy {
+		item.Expr.ResolveIndices(p.children[0].Schema())
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *TopN) ResolveIndices() {
 	p.basePlan.ResolveIndices()
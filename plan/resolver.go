@@ -80,6 +80,9 @@ type resolverContext struct {
 	tableMap map[string]int
 	// table map to lookup and check derived-table(subselect) name conflict.
 	derivedTableMap map[string]int
+	// common table expressions defined in the WITH clause, they are visible in this
+	// level and the subqueries.
+	cteMap map[string]*ast.CommonTableExpression
 	// tableSources collected in from clause.
 	tables []*ast.TableSource
 	// result fields collected in select field list.
@@ -117,7 +120,7 @@ type resolverContext struct {
 	inCreateOrDropTable bool
 	// When visiting show statement.
 	inShow bool
-	// When visiting create/alter table statement.
+	// When visiting the column options or the constraints of create/alter table statement.
 	inColumnOption bool
 }
 
@@ -195,7 +198,7 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 	case *ast.CreateTableStmt:
 		nr.pushContext()
 		nr.currentContext().inCreateOrDropTable = true
-	case *ast.ColumnOption:
+	case *ast.ColumnOption, *ast.Constraint:
 		nr.currentContext().inColumnOption = true
 	case *ast.DeleteStmt:
 		nr.pushContext()
@@ -273,11 +276,13 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.handleTableName(v)
 	case *ast.ColumnNameExpr:
 		nr.handleColumnName(v)
+	case *ast.CommonTableExpression:
+		nr.handleCommonTableExpression(v)
 	case *ast.CreateIndexStmt:
 		nr.popContext()
 	case *ast.CreateTableStmt:
 		nr.popContext()
-	case *ast.ColumnOption:
+	case *ast.ColumnOption, *ast.Constraint:
 		nr.currentContext().inColumnOption = false
 	case *ast.DeleteTableList:
 		nr.currentContext().inDeleteTableList = false
@@ -359,9 +364,69 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 	return inNode, nr.Err == nil
 }
 
+// handleCommonTableExpression checks the column names of the common table expression
+// and puts it in current resolverContext, so the following table names can refer to it.
+func (nr *nameResolver) handleCommonTableExpression(cte *ast.CommonTableExpression) {
+	ctx := nr.currentContext()
+	if _, ok := ctx.cteMap[cte.Name.L]; ok {
+		nr.Err = ErrNonUniqTable.GenByArgs(cte.Name.O)
+		return
+	}
+	if len(cte.ColNames) > 0 {
+		if len(cte.ColNames) != len(cte.Query.GetResultFields()) {
+			nr.Err = ErrViewWrongList.GenByArgs()
+			return
+		}
+		dupNames := make(map[string]struct{}, len(cte.ColNames))
+		for _, name := range cte.ColNames {
+			if _, ok := dupNames[name.L]; ok {
+				nr.Err = ErrDupFieldName.GenByArgs(name.O)
+				return
+			}
+			dupNames[name.L] = struct{}{}
+		}
+	}
+	if ctx.cteMap == nil {
+		ctx.cteMap = make(map[string]*ast.CommonTableExpression)
+	}
+	ctx.cteMap[cte.Name.L] = cte
+}
+
+// lookupCTE finds the common table expression with the name from the innermost context.
+func (nr *nameResolver) lookupCTE(name model.CIStr) *ast.CommonTableExpression {
+	for i := len(nr.contextStack) - 1; i >= 0; i-- {
+		if cte, ok := nr.contextStack[i].cteMap[name.L]; ok {
+			return cte
+		}
+	}
+	return nil
+}
+
+// handleCTEName sets the result fields for the table name that refers to a common table expression.
+// The result fields are copied from the query, because they are changed by table source.
+func (nr *nameResolver) handleCTEName(tn *ast.TableName, cte *ast.CommonTableExpression) {
+	tn.CTE = cte
+	queryFields := cte.Query.GetResultFields()
+	rfs := make([]*ast.ResultField, 0, len(queryFields))
+	for i, v := range queryFields {
+		rf := *v
+		col := *v.Column
+		rf.Column = &col
+		if len(cte.ColNames) > 0 {
+			rf.ColumnAsName = cte.ColNames[i]
+		}
+		rfs = append(rfs, &rf)
+	}
+	tn.SetResultFields(rfs)
+}
+
 // handleTableName looks up and sets the schema information and result fields for table name.
 func (nr *nameResolver) handleTableName(tn *ast.TableName) {
 	if tn.Schema.L == "" {
+		if cte := nr.lookupCTE(tn.Name); cte != nil {
+			nr.handleCTEName(tn, cte)
+			return
+		}
 		sessionVars := nr.Ctx.GetSessionVars()
 		if sessionVars.CurrentDB == "" {
 			nr.Err = errors.Trace(ErrNoDB)
@@ -438,8 +503,13 @@ func (nr *nameResolver) handleTableName(tn *ast.TableName) {
 // "select * from t as a join t as a;" is duplicate.
 // "select * from (select 1) as a join (select 1) as a;" is duplicate.
 func (nr *nameResolver) handleTableSource(ts *ast.TableSource) {
+	asName := ts.AsName
+	if tn, ok := ts.Source.(*ast.TableName); ok && tn.CTE != nil && asName.L == "" {
+		// The common table expression is referenced like a derived table named by it.
+		asName = tn.Name
+	}
 	for _, v := range ts.GetResultFields() {
-		v.TableAsName = ts.AsName
+		v.TableAsName = asName
 	}
 	ctx := nr.currentContext()
 	switch ts.Source.(type) {
@@ -997,6 +1067,9 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 			"Repeats", "Lower_Bound", "Upper_Bound"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeTiny, mysql.TypeLonglong,
 			mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar}
+	case ast.ShowOptRules:
+		names = []string{"Name", "Description", "Disabled"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeTiny}
 	}
 	for i, name := range names {
 		f := &ast.ResultField{
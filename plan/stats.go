@@ -179,6 +179,18 @@ func (p *Projection) prepareStatsProfile() *statsProfile {
 	return p.profile
 }
 
+func (p *Window) prepareStatsProfile() *statsProfile {
+	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
+	p.profile = &statsProfile{
+		count:       childProfile.count,
+		cardinality: make([]float64, 0, p.schema.Len()),
+	}
+	p.profile.cardinality = append(p.profile.cardinality, childProfile.cardinality...)
+	// We don't know the cardinality of the window function result, so we use a conservative strategy.
+	p.profile.cardinality = append(p.profile.cardinality, childProfile.count)
+	return p.profile
+}
+
 func (p *LogicalAggregation) prepareStatsProfile() *statsProfile {
 	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
 	var gbyCols []*expression.Column
@@ -105,6 +105,14 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = "Lock"
 	case *ShowDDL:
 		str = "ShowDDL"
+	case *ShowIndexUsage:
+		str = "ShowIndexUsage"
+	case *ShowTopSQL:
+		str = "ShowTopSQL"
+	case *ShowSlow:
+		str = "ShowSlow"
+	case *Window:
+		str = "Window(" + x.Name + ")"
 	case *Sort:
 		str = "Sort"
 		if x.ExecLimit != nil {
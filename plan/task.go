@@ -311,9 +311,13 @@ func (p *TopN) getCost(count float64) float64 {
 }
 
 // canPushDown checks if this topN can be pushed down. If each of the expression can be converted to pb, it can be pushed.
+// The coprocessor always treats NULL as the smallest value, so a topN with NullsHigh items can't be pushed.
 func (p *TopN) canPushDown() bool {
 	exprs := make([]expression.Expression, 0, len(p.ByItems))
 	for _, item := range p.ByItems {
+		if item.NullsHigh {
+			return false
+		}
 		exprs = append(exprs, item.Expr)
 	}
 	_, _, remained := expression.ExpressionsToPB(p.ctx.GetSessionVars().StmtCtx, exprs, p.ctx.GetClient())
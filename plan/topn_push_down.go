@@ -61,10 +61,24 @@ func (s *TopN) setChild(p LogicalPlan, eliminable bool) LogicalPlan {
 	return s
 }
 
+// maxTopNCount is the max Offset+Count of a limit which is converted to topN with the sort below it.
+// The topN keeps Offset+Count rows in a heap, which is slower than sorting all the rows when it's large.
+const maxTopNCount = 1 << 16
+
+// isSmallLimit checks whether the Offset+Count of a limit is small enough to be done by topN.
+func isSmallLimit(offset, count uint64) bool {
+	total := offset + count
+	return total >= offset && total <= maxTopNCount
+}
+
 func (s *Sort) pushDownTopN(topN *TopN) LogicalPlan {
 	if topN == nil {
 		return s.baseLogicalPlan.pushDownTopN(nil)
 	} else if topN.isLimit() {
+		if !isSmallLimit(topN.Offset, topN.Count) {
+			// Keep the sort and put the limit on it.
+			return s.baseLogicalPlan.pushDownTopN(topN)
+		}
 		topN.ByItems = s.ByItems
 		// If a Limit is pushed down, the Sort should be converted to topN and be pushed again.
 		return s.children[0].(LogicalPlan).pushDownTopN(topN)
@@ -92,7 +106,7 @@ func (p *Union) pushDownTopN(topN *TopN) LogicalPlan {
 			newTopN = TopN{Count: topN.Count + topN.Offset, partial: true}.init(p.allocator, p.ctx)
 			for _, by := range topN.ByItems {
 				newExpr := expression.ColumnSubstitute(by.Expr, p.schema, expression.Column2Exprs(child.Schema().Columns))
-				newTopN.ByItems = append(newTopN.ByItems, &ByItems{newExpr, by.Desc})
+				newTopN.ByItems = append(newTopN.ByItems, &ByItems{newExpr, by.Desc, by.NullsHigh})
 			}
 		}
 		p.children[i] = child.(LogicalPlan).pushDownTopN(newTopN)
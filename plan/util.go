@@ -15,6 +15,7 @@ package plan
 
 import (
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
 )
 
 // AggregateFuncExtractor visits Expr tree.
@@ -45,3 +46,68 @@ func (a *AggregateFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
 	}
 	return n, true
 }
+
+// windowFuncExtractor collects the window functions which are not in subqueries.
+// The window functions in the arguments of a window function are not collected, they are reported as errors
+// when the arguments are rewritten.
+type windowFuncExtractor struct {
+	windowFuncs []*ast.WindowFuncExpr
+}
+
+// Enter implements Visitor interface.
+func (e *windowFuncExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.WindowFuncExpr:
+		e.windowFuncs = append(e.windowFuncs, v)
+		return n, true
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (e *windowFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// nonAggColumnExtractor collects the column references which are neither in aggregate functions nor in subqueries.
+// The columns in ANY_VALUE are skipped too, since it's used to get the value of an arbitrary row in the group.
+type nonAggColumnExtractor struct {
+	cols []*ast.ColumnNameExpr
+}
+
+// Enter implements Visitor interface.
+func (e *nonAggColumnExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.AggregateFuncExpr, *ast.SubqueryExpr:
+		return n, true
+	case *ast.FuncCallExpr:
+		return n, v.FnName.L == ast.AnyValue
+	case *ast.ColumnNameExpr:
+		e.cols = append(e.cols, v)
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (e *nonAggColumnExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// findDataSourceOfColumn finds the DataSource in the plan tree which the column comes from.
+func findDataSourceOfColumn(p Plan, col *expression.Column) *DataSource {
+	if ds, ok := p.(*DataSource); ok {
+		if ds.Schema().Contains(col) {
+			return ds
+		}
+		return nil
+	}
+	for _, child := range p.Children() {
+		if ds := findDataSourceOfColumn(child, col); ds != nil {
+			return ds
+		}
+	}
+	return nil
+}
@@ -37,6 +37,9 @@ type Manager interface {
 	RequestVerification(db, table, column string, priv mysql.PrivilegeType) bool
 	// ConnectionVerification verifies user privilege for connection.
 	ConnectionVerification(host, user string, auth, salt []byte) bool
+	// DefaultDB returns the default database of the user verified by ConnectionVerification,
+	// it's used by the connections not specifying a database.
+	DefaultDB() string
 
 	// DBIsVisible returns true is the database is visible to current user.
 	DBIsVisible(db string) bool
@@ -50,6 +50,7 @@ type userRecord struct {
 	User       string // max length 16, primary key
 	Password   string // max length 41
 	Privileges mysql.PrivilegeType
+	DefaultDB  string // max length 64, the database used by the connections not specifying one
 
 	// patChars is compiled from Host, cached for pattern match performance.
 	patChars []byte
@@ -149,7 +150,14 @@ func noSuchTable(err error) bool {
 
 // LoadUserTable loads the mysql.user table from database.
 func (p *MySQLPrivilege) LoadUserTable(ctx context.Context) error {
-	return p.loadTable(ctx, "select Host,User,Password,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Process_priv,Grant_priv,References_priv,Alter_priv,Show_db_priv,Super_priv,Execute_priv,Index_priv,Create_user_priv,Trigger_priv from mysql.user order by host, user;", p.decodeUserTableRow)
+	const sql = "select Host,User,Password,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Process_priv,Grant_priv,References_priv,Alter_priv,Show_db_priv,Super_priv,Execute_priv,Index_priv,Create_user_priv,Trigger_priv%s from mysql.user order by host, user;"
+	err := p.loadTable(ctx, fmt.Sprintf(sql, ",Default_db"), p.decodeUserTableRow)
+	if e, ok := errors.Cause(err).(*terror.Error); ok && e.ToSQLError().Code == mysql.ErrBadField {
+		// The user table copied from MySQL doesn't have the Default_db column.
+		p.User = nil
+		err = p.loadTable(ctx, fmt.Sprintf(sql, ""), p.decodeUserTableRow)
+	}
+	return errors.Trace(err)
 }
 
 // LoadDBTable loads the mysql.db table from database.
@@ -209,6 +217,8 @@ func (p *MySQLPrivilege) decodeUserTableRow(row *ast.Row, fs []*ast.ResultField)
 			value.patChars, value.patTypes = stringutil.CompilePattern(value.Host, '\\')
 		case f.ColumnAsName.L == "password":
 			value.Password = d.GetString()
+		case f.ColumnAsName.L == "default_db":
+			value.DefaultDB = d.GetString()
 		case d.Kind() == types.KindMysqlEnum:
 			ed := d.GetMysqlEnum()
 			if ed.String() != "Y" {
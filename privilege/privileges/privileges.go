@@ -17,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
@@ -93,6 +94,11 @@ func (p *UserPrivileges) ConnectionVerification(user, host string, authenticatio
 		return false
 	}
 
+	if len(pwd) == 0 && config.GetGlobalConfig().DisallowEmptyPassword {
+		log.Errorf("User [%s] has an empty password, which is disallowed", user)
+		return false
+	}
+
 	// empty password
 	if len(pwd) == 0 && len(authentication) == 0 {
 		p.user = user
@@ -119,6 +125,18 @@ func (p *UserPrivileges) ConnectionVerification(user, host string, authenticatio
 	return true
 }
 
+// DefaultDB implements the Manager interface.
+func (p *UserPrivileges) DefaultDB() string {
+	if SkipWithGrant || (p.user == "" && p.host == "") {
+		return ""
+	}
+	record := p.Handle.Get().connectionVerification(p.user, p.host)
+	if record == nil {
+		return ""
+	}
+	return record.DefaultDB
+}
+
 // DBIsVisible implements the Manager interface.
 func (p *UserPrivileges) DBIsVisible(db string) bool {
 	if !Enable || SkipWithGrant {
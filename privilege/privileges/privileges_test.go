@@ -19,6 +19,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
@@ -27,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/types"
 )
 
 func TestT(t *testing.T) {
@@ -275,6 +277,27 @@ func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	c.Assert(se.Auth(&auth.UserIdentity{Username: "u4", Hostname: "localhost"}, nil, nil), IsFalse)
 }
 
+func (s *testPrivilegeSuite) TestDisallowEmptyPassword(c *C) {
+	defer testleak.AfterTest(c)()
+
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE USER 'empty_pwd'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(newSession(c, s.store, s.dbName).Auth(&auth.UserIdentity{Username: "empty_pwd", Hostname: "localhost"}, nil, nil), IsTrue)
+
+	cfg := config.GetGlobalConfig()
+	cfg.DisallowEmptyPassword = true
+	defer func() { cfg.DisallowEmptyPassword = false }()
+	c.Assert(newSession(c, s.store, s.dbName).Auth(&auth.UserIdentity{Username: "empty_pwd", Hostname: "localhost"}, nil, nil), IsFalse)
+	// The users with passwords can still log in.
+	mustExec(c, se, `SET PASSWORD FOR 'empty_pwd'@'localhost' = 'abc';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	salt := []byte{85, 92, 45, 22, 58, 79, 107, 6, 122, 125, 58, 80, 12, 90, 103, 32, 90, 10, 74, 82}
+	authentication := []byte{24, 180, 183, 225, 166, 6, 81, 102, 70, 248, 199, 143, 91, 204, 169, 9, 161, 171, 203, 33}
+	c.Assert(newSession(c, s.store, s.dbName).Auth(&auth.UserIdentity{Username: "empty_pwd", Hostname: "localhost"}, authentication, salt), IsTrue)
+	mustExec(c, se, "drop user 'empty_pwd'@'localhost'")
+}
+
 func (s *testPrivilegeSuite) TestInformationSchema(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -287,6 +310,44 @@ func (s *testPrivilegeSuite) TestInformationSchema(c *C) {
 	mustExec(c, se, `select * from information_schema.key_column_usage`)
 }
 
+func (s *testPrivilegeSuite) TestSlowQuery(c *C) {
+	defer testleak.AfterTest(c)()
+	cfg := config.GetGlobalConfig()
+	oldThreshold := cfg.SlowThreshold
+	defer func() { cfg.SlowThreshold = oldThreshold }()
+	// Every statement is recorded with 0 threshold.
+	cfg.SlowThreshold = 0
+
+	// The statement is recorded when the record set is closed.
+	query := func(se tidb.Session, sql string) types.Datum {
+		rs, err := se.Execute(sql)
+		c.Assert(err, IsNil)
+		row, err := rs[0].Next()
+		c.Assert(err, IsNil)
+		c.Assert(rs[0].Close(), IsNil)
+		return row.Data[0]
+	}
+	count := func(se tidb.Session, user string) int64 {
+		d := query(se, fmt.Sprintf(`select count(*) from information_schema.slow_query where user = '%s'`, user))
+		return d.GetInt64()
+	}
+	rootSe := newSession(c, s.store, s.dbName)
+	mustExec(c, rootSe, `CREATE USER 'slow'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	c.Assert(rootSe.Auth(&auth.UserIdentity{Username: "root", Hostname: "localhost"}, nil, nil), IsTrue)
+	query(rootSe, `select 'slow_root'`)
+	se := newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "slow", Hostname: "localhost"}, nil, nil), IsTrue)
+	query(se, `select 'slow_user'`)
+	// The user without the PROCESS privilege only sees its own statements.
+	c.Assert(count(se, "slow"), Greater, int64(0))
+	c.Assert(count(se, "root"), Equals, int64(0))
+
+	mustExec(c, rootSe, `GRANT PROCESS ON *.* TO 'slow'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	c.Assert(count(se, "root"), Greater, int64(0))
+}
+
 func mustExec(c *C, se tidb.Session, sql string) {
 	_, err := se.Execute(sql)
 	c.Assert(err, IsNil)
@@ -50,10 +50,12 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/logutil"
 )
 
 var defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
@@ -79,6 +81,30 @@ type clientConn struct {
 	ctx          QueryCtx          // an interface to execute sql statements.
 	attrs        map[string]string // attributes parsed from client handshake response, not used for now.
 	killed       bool
+	connectTime  time.Time // the time when the connection is accepted.
+	closeReason  string    // the reason of closing the connection, logged if LogConnections is set.
+	// preparedStmts is the number of the statements prepared by COM_STMT_PREPARE and not closed.
+	preparedStmts int
+}
+
+// The reasons of closing a connection.
+const (
+	closeReasonQuit    = "client quit"
+	closeReasonKill    = "kill"
+	closeReasonError   = "error"
+	closeReasonTimeout = "timeout"
+	closeReasonIdleTxn = "idle in transaction timeout"
+)
+
+// closeReasonOf returns the reason of closing the connection for the error of reading from the client.
+func closeReasonOf(err error) string {
+	if terror.ErrorEqual(err, io.EOF) {
+		return closeReasonQuit
+	}
+	if netErr, ok := errors.Cause(err).(net.Error); ok && netErr.Timeout() {
+		return closeReasonTimeout
+	}
+	return closeReasonError
 }
 
 func (cc *clientConn) String() string {
@@ -123,6 +149,7 @@ func (cc *clientConn) Close() error {
 	cc.server.rwlock.Unlock()
 	connGauge.Set(float64(connections))
 	cc.conn.Close()
+	cc.releasePreparedStmts(cc.preparedStmts)
 	if cc.ctx != nil {
 		return cc.ctx.Close()
 	}
@@ -325,6 +352,12 @@ func (cc *clientConn) readHandshakeResponse() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+	} else if db := cc.ctx.DefaultDB(); db != "" {
+		// The client doesn't specify a database, use the default database of the user.
+		// The connection isn't refused if the default database can't be used, like it has been dropped.
+		if err = cc.useDB(db); err != nil {
+			log.Warnf("[%d] use the default database %s of user %s failed %v", cc.connectionID, db, cc.user, err)
+		}
 	}
 	cc.ctx.SetSessionManager(cc.server)
 	return nil
@@ -342,32 +375,59 @@ func (cc *clientConn) Run() {
 			stackSize := runtime.Stack(buf, false)
 			buf = buf[:stackSize]
 			log.Errorf("lastCmd %s, %v, %s", cc.lastCmd, r, buf)
+			cc.closeReason = closeReasonError
 		}
 		cc.Close()
 	}()
 
 	for !cc.killed {
 		cc.alloc.Reset()
+		idleInTxn, err := cc.setIdleDeadline()
+		if err != nil {
+			log.Errorf("[%d] set read deadline error, close this connection %s", cc.connectionID, errors.ErrorStack(err))
+			cc.closeReason = closeReasonError
+			return
+		}
 		data, err := cc.readPacket()
 		if err != nil || cc.killed {
-			if terror.ErrorNotEqual(err, io.EOF) {
+			if idleInTxn && closeReasonOf(err) == closeReasonTimeout {
+				// The transaction is rolled back when the connection is closed, so the locks are freed.
+				log.Warnf("[%d] idle in transaction longer than %v, close this connection", cc.connectionID,
+					cc.server.cfg.IdleInTxnTimeout)
+				idleTxnKilledCounter.Inc()
+				cc.closeReason = closeReasonIdleTxn
+				return
+			}
+			if terror.ErrorNotEqual(err, io.EOF) && !cc.killed {
 				log.Errorf("[%d] read packet error, close this connection %s",
 					cc.connectionID, errors.ErrorStack(err))
 			}
 			if cc.killed {
 				log.Warnf("[%d] session is killed.", cc.connectionID)
+				cc.closeReason = closeReasonKill
+			} else {
+				cc.closeReason = closeReasonOf(err)
 			}
 			return
 		}
+		if idleInTxn {
+			if err = cc.conn.SetReadDeadline(time.Time{}); err != nil {
+				log.Errorf("[%d] clear read deadline error, close this connection %s", cc.connectionID, errors.ErrorStack(err))
+				cc.closeReason = closeReasonError
+				return
+			}
+		}
 
 		startTime := time.Now()
 		if err = cc.dispatch(data); err != nil {
 			if terror.ErrorEqual(err, io.EOF) {
 				cc.addMetrics(data[0], startTime, nil)
+				cc.closeReason = closeReasonQuit
 				return
 			} else if terror.ErrResultUndetermined.Equal(err) {
 				log.Errorf("[%d] result undetermined error, close this connection %s",
 					cc.connectionID, errors.ErrorStack(err))
+				cc.closeReason = closeReasonError
 				return
 			} else if terror.ErrCritical.Equal(err) {
 				log.Errorf("[%d] critical error, stop the server listener %s",
@@ -377,6 +437,7 @@ func (cc *clientConn) Run() {
 				case cc.server.stopListenerCh <- struct{}{}:
 				default:
 				}
+				cc.closeReason = closeReasonError
 				return
 			}
 			log.Warnf("[%d] dispatch error:\n%s\n%s\n%s",
@@ -386,6 +447,18 @@ func (cc *clientConn) Run() {
 		cc.addMetrics(data[0], startTime, err)
 		cc.pkt.sequence = 0
 	}
+	cc.closeReason = closeReasonKill
+}
+
+// setIdleDeadline sets the read deadline of the next command to IdleInTxnTimeout if the connection is in a
+// transaction, so a client which leaves a transaction open and idle can't hold the locks forever.
+// It returns whether the deadline is set.
+func (cc *clientConn) setIdleDeadline() (bool, error) {
+	timeout := cc.server.cfg.IdleInTxnTimeout
+	if timeout <= 0 || !cc.ctx.GetSessionVars().InTxn() {
+		return false, nil
+	}
+	return true, errors.Trace(cc.conn.SetReadDeadline(time.Now().Add(timeout)))
 }
 
 func queryStrForLog(query string) string {
@@ -470,10 +543,12 @@ func (cc *clientConn) dispatch(data []byte) error {
 		if len(data) > 0 && data[len(data)-1] == 0 {
 			data = data[:len(data)-1]
 		}
+		cc.logGeneral(generalLogQuery, hack.String(data))
 		return cc.handleQuery(hack.String(data))
 	case mysql.ComPing:
 		return cc.writeOK()
 	case mysql.ComInitDB:
+		cc.logGeneral(generalLogInitDB, hack.String(data))
 		if err := cc.useDB(hack.String(data)); err != nil {
 			return errors.Trace(err)
 		}
@@ -481,6 +556,7 @@ func (cc *clientConn) dispatch(data []byte) error {
 	case mysql.ComFieldList:
 		return cc.handleFieldList(hack.String(data))
 	case mysql.ComStmtPrepare:
+		cc.logGeneral(generalLogPrepare, hack.String(data))
 		return cc.handleStmtPrepare(hack.String(data))
 	case mysql.ComStmtExecute:
 		return cc.handleStmtExecute(data)
@@ -497,6 +573,17 @@ func (cc *clientConn) dispatch(data []byte) error {
 	}
 }
 
+// logGeneral writes the command to the general log if it's enabled for the server or the session.
+// The SQL text is redacted and truncated as the slow query log.
+func (cc *clientConn) logGeneral(cmd, arg string) {
+	if cc.server.cfg.GeneralLog || cc.ctx.GetSessionVars().GeneralLog {
+		if cmd == generalLogQuery || cmd == generalLogPrepare {
+			arg = logutil.FormatQuery(cc.server.cfg, arg)
+		}
+		cc.server.generalLog.write(cc.connectionID, cc.user, cc.ctx.GetSessionVars().TraceID, cmd, arg)
+	}
+}
+
 func (cc *clientConn) useDB(db string) (err error) {
 	// if input is "use `SELECT`", mysql client just send "SELECT"
 	// so we add `` around db.
@@ -764,6 +851,7 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 		return errors.Trace(err)
 	}
 
+	resultCharset := cc.resultCharset()
 	for {
 		if err != nil {
 			return errors.Trace(err)
@@ -771,6 +859,7 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 		if row == nil {
 			break
 		}
+		row = encodeResultRow(resultCharset, columns, row)
 		data = data[0:4]
 		if binary {
 			var rowData []byte
@@ -808,9 +897,23 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 	return errors.Trace(cc.flush())
 }
 
+// resultCharset returns character_set_results of the session, the strings of the results are encoded in it.
+func (cc *clientConn) resultCharset() string {
+	if cc.ctx == nil {
+		return ""
+	}
+	return cc.ctx.GetSessionVars().Systems[variable.CharacterSetResults]
+}
+
 func (cc *clientConn) writeMultiResultset(rss []ResultSet, binary bool) error {
-	for _, rs := range rss {
+	for i, rs := range rss {
 		if err := cc.writeResultset(rs, binary, true); err != nil {
+			// The result sets not written are closed to stop their executors.
+			for _, rest := range rss[i+1:] {
+				if err1 := rest.Close(); err1 != nil {
+					log.Warnf("[%d] close result set error %v", cc.connectionID, err1)
+				}
+			}
 			return errors.Trace(err)
 		}
 	}
@@ -38,6 +38,7 @@ import (
 	"encoding/binary"
 	"math"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
@@ -45,8 +46,12 @@ import (
 )
 
 func (cc *clientConn) handleStmtPrepare(sql string) error {
+	if err := cc.reservePreparedStmt(); err != nil {
+		return errors.Trace(err)
+	}
 	stmt, columns, params, err := cc.ctx.Prepare(sql)
 	if err != nil {
+		cc.releasePreparedStmts(1)
 		return errors.Trace(err)
 	}
 	data := make([]byte, 4, 128)
@@ -115,6 +120,7 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 		return mysql.NewErr(mysql.ErrUnknownStmtHandler,
 			strconv.FormatUint(uint64(stmtID), 10), "stmt_execute")
 	}
+	cc.logGeneral(generalLogExecute, "statement "+strconv.FormatUint(uint64(stmtID), 10))
 
 	flag := data[pos]
 	pos++
@@ -162,6 +168,9 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 		if err != nil {
 			return errors.Trace(err)
 		}
+	} else if len(data) > pos {
+		// The statement has no placeholder, but the client sends parameters.
+		return errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 	}
 	rs, err := stmt.Execute(args...)
 	if err != nil {
@@ -171,7 +180,13 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 		return errors.Trace(cc.writeOK())
 	}
 
-	return errors.Trace(cc.writeResultset(rs, true, false))
+	return errors.Trace(cc.writeStmtResultset(rs))
+}
+
+// writeStmtResultset writes the resultset of a prepared statement.
+// It's encoded in BINARY format unless the server is configured to force the text protocol.
+func (cc *clientConn) writeStmtResultset(rs ResultSet) error {
+	return errors.Trace(cc.writeResultset(rs, !cc.server.cfg.ForceTextProtocol, false))
 }
 
 func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTypes, paramValues []byte) (err error) {
@@ -204,7 +219,7 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 
 		case mysql.TypeTiny:
 			if len(paramValues) < (pos + 1) {
-				err = mysql.ErrMalformPacket
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 
@@ -219,7 +234,7 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 
 		case mysql.TypeShort, mysql.TypeYear:
 			if len(paramValues) < (pos + 2) {
-				err = mysql.ErrMalformPacket
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 			valU16 := binary.LittleEndian.Uint16(paramValues[pos : pos+2])
@@ -233,7 +248,7 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 
 		case mysql.TypeInt24, mysql.TypeLong:
 			if len(paramValues) < (pos + 4) {
-				err = mysql.ErrMalformPacket
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 			valU32 := binary.LittleEndian.Uint32(paramValues[pos : pos+4])
@@ -247,7 +262,7 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 
 		case mysql.TypeLonglong:
 			if len(paramValues) < (pos + 8) {
-				err = mysql.ErrMalformPacket
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 			valU64 := binary.LittleEndian.Uint64(paramValues[pos : pos+8])
@@ -261,7 +276,7 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 
 		case mysql.TypeFloat:
 			if len(paramValues) < (pos + 4) {
-				err = mysql.ErrMalformPacket
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 
@@ -271,7 +286,7 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 
 		case mysql.TypeDouble:
 			if len(paramValues) < (pos + 8) {
-				err = mysql.ErrMalformPacket
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 
@@ -286,13 +301,15 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 			mysql.TypeDate, mysql.TypeNewDate,
 			mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDuration:
 			if len(paramValues) < (pos + 1) {
-				err = mysql.ErrMalformPacket
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 
 			v, isNull, n, err = parseLengthEncodedBytes(paramValues[pos:])
 			pos += n
 			if err != nil {
+				// The value is truncated.
+				err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
 				return
 			}
 
@@ -307,6 +324,10 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 			return
 		}
 	}
+	if pos != len(paramValues) {
+		// The client sends more parameter values than the placeholders.
+		err = errIncorrectArgs.GenByArgs("mysqld_stmt_execute")
+	}
 	return
 }
 
@@ -318,11 +339,42 @@ func (cc *clientConn) handleStmtClose(data []byte) (err error) {
 	stmtID := int(binary.LittleEndian.Uint32(data[0:4]))
 	stmt := cc.ctx.GetStatement(stmtID)
 	if stmt != nil {
-		return errors.Trace(stmt.Close())
+		err = stmt.Close()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		cc.releasePreparedStmts(1)
 	}
 	return
 }
 
+// reservePreparedStmt counts a statement to be prepared, it fails if the statements prepared by the connection
+// or all the connections reach the limits.
+func (cc *clientConn) reservePreparedStmt() error {
+	cfg := cc.server.cfg
+	if cfg.MaxPreparedStmtCountPerConn > 0 && cc.preparedStmts >= cfg.MaxPreparedStmtCountPerConn {
+		return errMaxPreparedStmts.GenByArgs("max-prepared-stmt-count-per-conn", cfg.MaxPreparedStmtCountPerConn)
+	}
+	count := atomic.AddInt64(&cc.server.preparedStmts, 1)
+	if cfg.MaxPreparedStmtCount > 0 && count > int64(cfg.MaxPreparedStmtCount) {
+		atomic.AddInt64(&cc.server.preparedStmts, -1)
+		return errMaxPreparedStmts.GenByArgs("max-prepared-stmt-count", cfg.MaxPreparedStmtCount)
+	}
+	cc.preparedStmts++
+	preparedStmtGauge.Inc()
+	return nil
+}
+
+// releasePreparedStmts uncounts the closed statements.
+func (cc *clientConn) releasePreparedStmts(n int) {
+	if n == 0 {
+		return
+	}
+	cc.preparedStmts -= n
+	atomic.AddInt64(&cc.server.preparedStmts, int64(-n))
+	preparedStmtGauge.Sub(float64(n))
+}
+
 func (cc *clientConn) handleStmtSendLongData(data []byte) (err error) {
 	if len(data) < 6 {
 		return mysql.ErrMalformPacket
@@ -0,0 +1,113 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+)
+
+func (ts ConnTestSuite) TestParseStmtArgs(c *C) {
+	tests := []struct {
+		args        []interface{}
+		nullBitmap  []byte
+		paramTypes  []byte
+		paramValues []byte
+		expect      []interface{}
+		err         error
+	}{
+		// One integer.
+		{
+			make([]interface{}, 1),
+			[]byte{0x0},
+			[]byte{mysql.TypeLong, 0},
+			[]byte{0x1, 0x0, 0x0, 0x0},
+			[]interface{}{int64(1)},
+			nil,
+		},
+		// A NULL and a string, the NULL has no value.
+		{
+			make([]interface{}, 2),
+			[]byte{0x1},
+			[]byte{mysql.TypeLong, 0, mysql.TypeVarString, 0},
+			[]byte{0x3, 'a', 'b', 'c'},
+			[]interface{}{nil, "abc"},
+			nil,
+		},
+		// The integer is truncated.
+		{
+			make([]interface{}, 1),
+			[]byte{0x0},
+			[]byte{mysql.TypeLong, 0},
+			[]byte{0x1, 0x0},
+			nil,
+			errIncorrectArgs,
+		},
+		// The string is truncated.
+		{
+			make([]interface{}, 1),
+			[]byte{0x0},
+			[]byte{mysql.TypeVarString, 0},
+			[]byte{0x3, 'a', 'b'},
+			nil,
+			errIncorrectArgs,
+		},
+		// The second value is missing.
+		{
+			make([]interface{}, 2),
+			[]byte{0x0},
+			[]byte{mysql.TypeTiny, 0, mysql.TypeTiny, 0},
+			[]byte{0x1},
+			nil,
+			errIncorrectArgs,
+		},
+		// More values than the placeholders.
+		{
+			make([]interface{}, 1),
+			[]byte{0x0},
+			[]byte{mysql.TypeLong, 0},
+			[]byte{0x1, 0x0, 0x0, 0x0, 0x2, 0x0, 0x0, 0x0},
+			nil,
+			errIncorrectArgs,
+		},
+		// A value for a NULL parameter.
+		{
+			make([]interface{}, 1),
+			[]byte{0x1},
+			[]byte{mysql.TypeTiny, 0},
+			[]byte{0x1},
+			nil,
+			errIncorrectArgs,
+		},
+		// The parameter types are never sent.
+		{
+			make([]interface{}, 1),
+			[]byte{0x0},
+			nil,
+			[]byte{0x1},
+			nil,
+			mysql.ErrMalformPacket,
+		},
+	}
+	for i, tt := range tests {
+		err := parseStmtArgs(tt.args, make([][]byte, len(tt.args)), tt.nullBitmap, tt.paramTypes, tt.paramValues)
+		if tt.err != nil {
+			c.Assert(terror.ErrorEqual(err, tt.err), IsTrue, Commentf("case %d, err %v", i, err))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("case %d", i))
+		c.Assert(tt.args, DeepEquals, tt.expect, Commentf("case %d", i))
+	}
+}
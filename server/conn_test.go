@@ -17,9 +17,14 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"net"
+	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/types"
 )
 
 type ConnTestSuite struct{}
@@ -132,6 +137,94 @@ func (ts ConnTestSuite) TestInitialHandshake(c *C) {
 	c.Assert(outBuffer.Bytes()[4:], DeepEquals, expected.Bytes())
 }
 
+type mockResultSet struct {
+	columns []*ColumnInfo
+	rows    [][]types.Datum
+}
+
+func (rs *mockResultSet) Columns() ([]*ColumnInfo, error) {
+	return rs.columns, nil
+}
+
+func (rs *mockResultSet) Next() ([]types.Datum, error) {
+	if len(rs.rows) == 0 {
+		return nil, nil
+	}
+	row := rs.rows[0]
+	rs.rows = rs.rows[1:]
+	return row, nil
+}
+
+func (rs *mockResultSet) Close() error {
+	return nil
+}
+
+func (ts ConnTestSuite) TestForceTextProtocol(c *C) {
+	c.Parallel()
+	newResultSet := func() ResultSet {
+		return &mockResultSet{
+			columns: []*ColumnInfo{{Name: "a", Type: mysql.TypeLonglong}},
+			rows:    [][]types.Datum{types.MakeDatums(1), types.MakeDatums(2)},
+		}
+	}
+	writeResultset := func(forceText bool, write func(cc *clientConn) error) []byte {
+		var outBuffer bytes.Buffer
+		cc := &clientConn{
+			server: &Server{cfg: &config.Config{ForceTextProtocol: forceText}},
+			alloc:  arena.NewAllocator(1024),
+			pkt: &packetIO{
+				wb: bufio.NewWriter(&outBuffer),
+			},
+		}
+		err := write(cc)
+		c.Assert(err, IsNil)
+		return outBuffer.Bytes()
+	}
+
+	text := writeResultset(false, func(cc *clientConn) error {
+		return cc.writeResultset(newResultSet(), false, false)
+	})
+	binaryData := writeResultset(false, func(cc *clientConn) error {
+		return cc.writeResultset(newResultSet(), true, false)
+	})
+	c.Assert(text, Not(DeepEquals), binaryData)
+
+	// The result set of a prepared statement is encoded in binary protocol by default.
+	stmt := writeResultset(false, func(cc *clientConn) error {
+		return cc.writeStmtResultset(newResultSet())
+	})
+	c.Assert(stmt, DeepEquals, binaryData)
+	// The text protocol is used if it's forced.
+	stmt = writeResultset(true, func(cc *clientConn) error {
+		return cc.writeStmtResultset(newResultSet())
+	})
+	c.Assert(stmt, DeepEquals, text)
+}
+
+func (ts ConnTestSuite) TestCloseReason(c *C) {
+	c.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	defer ln.Close()
+	client, err := net.Dial("tcp", ln.Addr().String())
+	c.Assert(err, IsNil)
+	conn, err := ln.Accept()
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	pkt := newPacketIO(conn)
+
+	c.Assert(conn.SetReadDeadline(time.Now().Add(10*time.Millisecond)), IsNil)
+	_, err = pkt.readPacket()
+	c.Assert(closeReasonOf(err), Equals, closeReasonTimeout)
+
+	c.Assert(conn.SetReadDeadline(time.Time{}), IsNil)
+	client.Close()
+	_, err = pkt.readPacket()
+	c.Assert(closeReasonOf(err), Equals, closeReasonQuit)
+
+	c.Assert(closeReasonOf(errInvalidSequence.Gen("invalid sequence")), Equals, closeReasonError)
+}
+
 func mapIdentical(m1, m2 map[string]string) bool {
 	return mapBelong(m1, m2) && mapBelong(m2, m1)
 }
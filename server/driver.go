@@ -16,6 +16,7 @@ package server
 import (
 	"fmt"
 
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
@@ -77,6 +78,9 @@ type QueryCtx interface {
 	// Auth verifies user's authentication.
 	Auth(user *auth.UserIdentity, auth []byte, salt []byte) bool
 
+	// DefaultDB returns the default database of the authenticated user.
+	DefaultDB() string
+
 	// ShowProcess shows the information about the session.
 	ShowProcess() util.ProcessInfo
 
@@ -84,6 +88,9 @@ type QueryCtx interface {
 
 	// Cancel the execution of current transaction.
 	Cancel()
+
+	// GetSessionVars returns the session variables.
+	GetSessionVars() *variable.SessionVars
 }
 
 // PreparedStatement is the interface to use a prepared statement.
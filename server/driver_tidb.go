@@ -21,8 +21,12 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -135,9 +139,23 @@ func (qd *TiDBDriver) OpenCtx(connID uint64, capability uint32, collation uint8,
 		currentDB: dbname,
 		stmts:     make(map[int]*TiDBStatement),
 	}
+	cs, co := handshakeCharset(collation)
+	if err = varsutil.SetNames(session.GetSessionVars(), cs, co); err != nil {
+		return nil, errors.Trace(err)
+	}
 	return tc, nil
 }
 
+// handshakeCharset returns the charset and collation of the collation ID sent by the client in the handshake,
+// the default ones are returned if the collation isn't supported.
+func handshakeCharset(collationID uint8) (string, string) {
+	co, err := charset.GetCollationByName(mysql.Collations[collationID])
+	if err != nil {
+		return mysql.DefaultCharset, mysql.DefaultCollationName
+	}
+	return co.CharsetName, co.Name
+}
+
 // Status implements QueryCtx Status method.
 func (tc *TiDBContext) Status() uint16 {
 	return tc.session.Status()
@@ -222,6 +240,15 @@ func (tc *TiDBContext) Auth(user *auth.UserIdentity, auth []byte, salt []byte) b
 	return tc.session.Auth(user, auth, salt)
 }
 
+// DefaultDB implements QueryCtx DefaultDB method.
+func (tc *TiDBContext) DefaultDB() string {
+	pm := privilege.GetPrivilegeManager(tc.session)
+	if pm == nil {
+		return ""
+	}
+	return pm.DefaultDB()
+}
+
 // FieldList implements QueryCtx FieldList method.
 func (tc *TiDBContext) FieldList(table string) (colums []*ColumnInfo, err error) {
 	rs, err := tc.Execute("SELECT * FROM `" + table + "` LIMIT 0")
@@ -281,6 +308,11 @@ func (tc *TiDBContext) Cancel() {
 	tc.session.Cancel()
 }
 
+// GetSessionVars implements QueryCtx GetSessionVars method.
+func (tc *TiDBContext) GetSessionVars() *variable.SessionVars {
+	return tc.session.GetSessionVars()
+}
+
 type tidbResultSet struct {
 	recordSet ast.RecordSet
 }
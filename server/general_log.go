@@ -0,0 +1,95 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+// The commands written to the general log.
+const (
+	generalLogQuery   = "Query"
+	generalLogInitDB  = "Init DB"
+	generalLogPrepare = "Prepare"
+	generalLogExecute = "Execute"
+)
+
+// generalLogger writes the statements received by the server to the general log before they're executed,
+// like the general query log of MySQL. Each line is made of the time, the connection ID, the user,
+// the command and its argument, separated by tabs, followed by the tidb_trace_id of the session if it's set.
+type generalLogger struct {
+	sampleRate float64
+
+	mu struct {
+		sync.Mutex
+		// file is nil if the general log is written to the server log.
+		file *os.File
+	}
+}
+
+// newGeneralLogger creates a generalLogger appending to the file of path, or writing to the server log if path is empty.
+func newGeneralLogger(path string, sampleRate float64) (*generalLogger, error) {
+	l := &generalLogger{sampleRate: sampleRate}
+	if len(path) == 0 {
+		return l, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	l.mu.file = f
+	return l, nil
+}
+
+// write writes a command to the general log, it's skipped if it's not sampled.
+func (l *generalLogger) write(connID uint32, user, traceID, cmd, arg string) {
+	if l.sampleRate > 0 && l.sampleRate < 1 && rand.Float64() >= l.sampleRate {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.file == nil {
+		if len(traceID) > 0 {
+			log.Infof("[general] [%d][trace_id=%s] %s %s: %s", connID, traceID, user, cmd, arg)
+			return
+		}
+		log.Infof("[general] [%d] %s %s: %s", connID, user, cmd, arg)
+		return
+	}
+	line := fmt.Sprintf("%s\t%d\t%s\t%s\t%s", time.Now().Format("2006-01-02T15:04:05.000000Z07:00"), connID, user, cmd, arg)
+	if len(traceID) > 0 {
+		line += "\t" + traceID
+	}
+	line += "\n"
+	if _, err := l.mu.file.WriteString(line); err != nil {
+		log.Errorf("[general] write general log failed: %v", err)
+	}
+}
+
+// close closes the general log file, the following commands are written to the server log.
+func (l *generalLogger) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.file != nil {
+		l.mu.file.Close()
+		l.mu.file = nil
+	}
+}
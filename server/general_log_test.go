@@ -0,0 +1,65 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/pingcap/check"
+)
+
+type testGeneralLogSuite struct{}
+
+var _ = Suite(&testGeneralLogSuite{})
+
+func (s *testGeneralLogSuite) TestTraceID(c *C) {
+	path := filepath.Join(c.MkDir(), "general.log")
+	l, err := newGeneralLogger(path, 1)
+	c.Assert(err, IsNil)
+	l.write(1, "root", "", generalLogQuery, "select 1")
+	l.write(1, "root", "trace-1", generalLogQuery, "select 2")
+	l.close()
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	c.Assert(lines, HasLen, 2)
+	c.Assert(strings.Split(lines[0], "\t")[1:], DeepEquals, []string{"1", "root", generalLogQuery, "select 1"})
+	c.Assert(strings.Split(lines[1], "\t")[1:], DeepEquals, []string{"1", "root", generalLogQuery, "select 2", "trace-1"})
+}
+
+func (s *testGeneralLogSuite) TestSampleRate(c *C) {
+	dir := c.MkDir()
+	for _, rate := range []float64{1, 0.5} {
+		path := filepath.Join(dir, "general.log")
+		l, err := newGeneralLogger(path, rate)
+		c.Assert(err, IsNil)
+		for i := 0; i < 1000; i++ {
+			l.write(1, "root", "", generalLogQuery, "select 1")
+		}
+		l.close()
+		data, err := ioutil.ReadFile(path)
+		c.Assert(err, IsNil)
+		lines := strings.Count(string(data), "\n")
+		if rate == 1 {
+			c.Assert(lines, Equals, 1000)
+		} else {
+			c.Assert(lines > 300 && lines < 700, IsTrue, Commentf("%d lines are logged", lines))
+		}
+		// The file is appended, remove it for the next rate.
+		c.Assert(os.Remove(path), IsNil)
+	}
+}
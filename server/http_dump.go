@@ -0,0 +1,104 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"runtime/pprof"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+// maxConcurrentDumps is the max number of profiles that can be dumped at the same time,
+// the requests beyond it are rejected instead of queued.
+const maxConcurrentDumps = 2
+
+// dumpTokens bounds the concurrent dumps, a token is taken from it during a dump.
+var dumpTokens = make(chan struct{}, maxConcurrentDumps)
+
+// dumpResult is the response of /status/debug/dump.
+type dumpResult struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// handleDump writes a heap or goroutine profile to the dump directory and returns its path.
+// It's only served on the status address, so the profile can be got without exposing pprof.
+func (s *Server) handleDump(w http.ResponseWriter, req *http.Request) {
+	tp := req.FormValue("type")
+	if tp != "heap" && tp != "goroutine" {
+		http.Error(w, fmt.Sprintf("invalid dump type %q, it should be heap or goroutine", tp), http.StatusBadRequest)
+		return
+	}
+	select {
+	case dumpTokens <- struct{}{}:
+		defer func() { <-dumpTokens }()
+	default:
+		http.Error(w, "too many dumps in progress", http.StatusServiceUnavailable)
+		return
+	}
+
+	path, err := s.dumpProfile(tp)
+	if err != nil {
+		log.Errorf("[status] dump %s profile failed: %v", tp, errors.ErrorStack(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Infof("[status] dump %s profile to %s", tp, path)
+
+	js, err := json.Marshal(dumpResult{Type: tp, Path: path})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Encode json error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(js)
+}
+
+func (s *Server) dumpProfile(tp string) (string, error) {
+	dir := s.cfg.DumpDir
+	if len(dir) == 0 {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Trace(err)
+	}
+	prefix := fmt.Sprintf("tidb-%s-%s-", tp, time.Now().Format("20060102-150405"))
+	f, err := ioutil.TempFile(dir, prefix)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	// The goroutine profile is dumped as readable stack traces, while the heap profile
+	// is dumped in the format of pprof, so it can be analyzed by `go tool pprof`.
+	debug := 0
+	if tp == "goroutine" {
+		debug = 2
+	}
+	err = pprof.Lookup(tp).WriteTo(f, debug)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", errors.Trace(err)
+	}
+	return f.Name(), nil
+}
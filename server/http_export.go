@@ -0,0 +1,272 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/types"
+	goctx "golang.org/x/net/context"
+)
+
+// exportNullValue is written for the NULL values, it's the same as the default of LOAD DATA.
+const exportNullValue = `\N`
+
+// exportHandler is the handler for exporting the data of a table to CSV files.
+// "/export/{db}/{table}" splits the records of the table into key ranges by regions,
+// and exports the ranges concurrently to the export directory, one file for each range.
+// All the ranges are read at the same version, so the files make up a consistent snapshot,
+// and GC is held back to the version until the export is done.
+// The status API isn't authenticated, so the system databases, e.g. the users and their passwords, can't be exported.
+type exportHandler struct {
+	dom         *domain.Domain
+	store       kv.Storage
+	dir         string
+	concurrency int
+}
+
+// exportResult is the response of /export/{db}/{table}.
+type exportResult struct {
+	Version uint64   `json:"version"`
+	Rows    int64    `json:"rows"`
+	Files   []string `json:"files"`
+}
+
+// ServeHTTP handles request of exporting a table.
+func (h exportHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if len(h.dir) == 0 {
+		http.Error(w, "export is disabled, the export directory is not set", http.StatusForbidden)
+		return
+	}
+	is := h.dom.InfoSchema()
+	params := mux.Vars(req)
+	db, ok := is.SchemaByName(model.NewCIStr(params[pDBName]))
+	if !ok {
+		http.Error(w, fmt.Sprintf("database %s doesn't exist", params[pDBName]), http.StatusNotFound)
+		return
+	}
+	if db.Name.L == mysql.SystemDB || infoschema.IsMemoryDB(db.Name.L) {
+		http.Error(w, fmt.Sprintf("exporting the system database %s is not allowed", db.Name), http.StatusForbidden)
+		return
+	}
+	t, err := is.TableByName(db.Name, model.NewCIStr(params[pTableName]))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	result, err := h.export(db.Name.O, t)
+	if err != nil {
+		log.Errorf("[status] export table %s.%s failed: %v", db.Name, t.Meta().Name, errors.ErrorStack(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Infof("[status] export %d rows of table %s.%s to %d files at version %d",
+		result.Rows, db.Name, t.Meta().Name, len(result.Files), result.Version)
+	writeJSON(w, result)
+}
+
+func (h exportHandler) export(dbName string, t table.Table) (*exportResult, error) {
+	if err := os.MkdirAll(h.dir, 0755); err != nil {
+		return nil, errors.Trace(err)
+	}
+	ver, err := h.store.CurrentVersion()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, ok := h.store.(kvStore); ok {
+		service := fmt.Sprintf("export_%d", ver.Ver)
+		if err = tikv.RegisterGCServiceSafePoint(h.store, service, ver.Ver); err != nil {
+			return nil, errors.Trace(err)
+		}
+		defer func() {
+			if err1 := tikv.UnregisterGCServiceSafePoint(h.store, service); err1 != nil {
+				log.Warnf("[status] unregister GC safe point %s failed: %v", service, err1)
+			}
+		}()
+	}
+	ranges, err := h.splitRanges(t.Meta().ID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	result := &exportResult{
+		Version: ver.Ver,
+		Files:   make([]string, len(ranges)),
+	}
+	for i := range ranges {
+		result.Files[i] = filepath.Join(h.dir, exportFileName(dbName, t.Meta().Name.O, ver.Ver, i))
+	}
+
+	concurrency := h.concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	// The remaining ranges are canceled on the first error.
+	ctx, cancel := goctx.WithCancel(goctx.Background())
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		done     = make([]bool, len(ranges))
+	)
+	taskCh := make(chan int, len(ranges))
+	for i := range ranges {
+		taskCh <- i
+	}
+	close(taskCh)
+	for i := 0; i < concurrency && i < len(ranges); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range taskCh {
+				if ctx.Err() != nil {
+					return
+				}
+				rows, err := h.exportRange(ctx, ver.Ver, t, ranges[idx], result.Files[idx])
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+				atomic.AddInt64(&result.Rows, rows)
+				done[idx] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		for i, path := range result.Files {
+			if done[i] {
+				os.Remove(path)
+			}
+		}
+		return nil, errors.Trace(firstErr)
+	}
+	return result, nil
+}
+
+// exportFileName returns the name of the file of the i-th range of the table exported at the version ver.
+// The characters of the names other than letters, digits and underscores are escaped as "%XX",
+// so the file is always in the export directory.
+func exportFileName(dbName, tableName string, ver uint64, i int) string {
+	escape := func(name string) string {
+		var buf bytes.Buffer
+		for _, b := range []byte(name) {
+			if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '_' {
+				buf.WriteByte(b)
+			} else {
+				fmt.Fprintf(&buf, "%%%02X", b)
+			}
+		}
+		return buf.String()
+	}
+	return fmt.Sprintf("%s.%s.%d.%d.csv", escape(dbName), escape(tableName), ver, i)
+}
+
+// splitRanges splits the record range of the table by regions if the store is TiKV,
+// otherwise the whole record range is exported as one range.
+func (h exportHandler) splitRanges(tableID int64) ([]kv.KeyRange, error) {
+	startKey := tablecodec.GenTableRecordPrefix(tableID)
+	endKey := startKey.PrefixNext()
+	tikvStore, ok := h.store.(kvStore)
+	if !ok {
+		return []kv.KeyRange{{StartKey: startKey, EndKey: endKey}}, nil
+	}
+	bo := tikv.NewBackoffer(500, goctx.Background())
+	ranges, err := tikvStore.GetRegionCache().SplitKeyRangeByRegions(bo, startKey, endKey)
+	return ranges, errors.Trace(err)
+}
+
+// exportRange writes the records in the key range r to the file of path, which are read at the version startTS.
+// It stops when ctx is canceled.
+func (h exportHandler) exportRange(ctx goctx.Context, startTS uint64, t table.Table, r kv.KeyRange, path string) (rows int64, err error) {
+	se, err := tidb.CreateSession(h.store)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer se.Close()
+	se.PrepareTxnCtx()
+	if err = se.InitTxnWithStartTS(startTS); err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = errors.Trace(closeErr)
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	w := csv.NewWriter(f)
+	cols := t.Cols()
+	record := make([]string, len(cols))
+	err = t.IterRecords(se, r.StartKey, cols, func(handle int64, data []types.Datum, cols []*table.Column) (bool, error) {
+		if len(r.EndKey) > 0 && t.RecordKey(handle).Cmp(r.EndKey) >= 0 {
+			return false, nil
+		}
+		if err1 := ctx.Err(); err1 != nil {
+			return false, errors.Trace(err1)
+		}
+		for i, d := range data {
+			if d.IsNull() {
+				record[i] = exportNullValue
+				continue
+			}
+			s, err1 := d.ToString()
+			if err1 != nil {
+				return false, errors.Trace(err1)
+			}
+			record[i] = s
+		}
+		rows++
+		return true, errors.Trace(w.Write(record))
+	})
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	w.Flush()
+	return rows, errors.Trace(w.Error())
+}
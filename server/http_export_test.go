@@ -0,0 +1,173 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/store/tikv/mock-tikv"
+	"github.com/pingcap/tidb/tablecodec"
+	goctx "golang.org/x/net/context"
+)
+
+type testExportHandlerSuite struct {
+	store   kv.Storage
+	dom     *domain.Domain
+	cluster *mocktikv.Cluster
+	mvcc    *mocktikv.MvccStore
+}
+
+var _ = Suite(&testExportHandlerSuite{})
+
+func (ts *testExportHandlerSuite) SetUpSuite(c *C) {
+	ts.cluster = mocktikv.NewCluster()
+	mocktikv.BootstrapWithSingleStore(ts.cluster)
+	ts.mvcc = mocktikv.NewMvccStore()
+	store, err := tikv.NewMockTikvStore(tikv.WithCluster(ts.cluster), tikv.WithMVCCStore(ts.mvcc))
+	c.Assert(err, IsNil)
+	ts.store = store
+	ts.dom, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+}
+
+func (ts *testExportHandlerSuite) TearDownSuite(c *C) {
+	ts.store.Close()
+}
+
+func (ts *testExportHandlerSuite) export(dir, path string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.Handle("/export/{db}/{table}", exportHandler{ts.dom, ts.store, dir, 2})
+	req, _ := http.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func (ts *testExportHandlerSuite) TestExport(c *C) {
+	se, err := tidb.CreateSession(ts.store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	_, err = se.Execute("create database export_api; use export_api;" +
+		"create table t (a int primary key, b varchar(10), c double)")
+	c.Assert(err, IsNil)
+	var expected []string
+	for i := 1; i <= 100; i++ {
+		b := fmt.Sprintf("'b,%d'", i)
+		expectedB := fmt.Sprintf(`"b,%d"`, i)
+		if i%10 == 0 {
+			b, expectedB = "null", exportNullValue
+		}
+		_, err = se.Execute(fmt.Sprintf("insert into t values (%d, %s, %d.5)", i, b, i))
+		c.Assert(err, IsNil)
+		expected = append(expected, fmt.Sprintf("%d,%s,%d.5", i, expectedB, i))
+	}
+	tbl, err := sessionctx.GetDomain(se.(context.Context)).InfoSchema().TableByName(model.NewCIStr("export_api"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	ts.cluster.SplitTable(ts.mvcc, tbl.Meta().ID, 4)
+	// Drop the region cached before the split, just like the region cache does when TiKV reports `NotInRegion`.
+	regionCache := ts.store.(kvStore).GetRegionCache()
+	loc, err := regionCache.LocateKey(tikv.NewBackoffer(500, goctx.Background()), tablecodec.GenTableRecordPrefix(tbl.Meta().ID))
+	c.Assert(err, IsNil)
+	regionCache.DropRegion(loc.Region)
+
+	dir := filepath.Join(c.MkDir(), "export")
+	w := ts.export(dir, "/export/export_api/T")
+	c.Assert(w.Code, Equals, http.StatusOK, Commentf("%s", w.Body.String()))
+	var result exportResult
+	err = json.Unmarshal(w.Body.Bytes(), &result)
+	c.Assert(err, IsNil)
+	c.Assert(result.Rows, Equals, int64(100))
+	c.Assert(result.Version, Greater, uint64(0))
+	c.Assert(len(result.Files), GreaterEqual, 4)
+
+	var lines []string
+	for _, path := range result.Files {
+		c.Assert(filepath.Dir(path), Equals, dir)
+		f, err := os.Open(path)
+		c.Assert(err, IsNil)
+		records, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		c.Assert(err, IsNil)
+		for _, r := range records {
+			if r[1] != exportNullValue {
+				r[1] = fmt.Sprintf("%q", r[1])
+			}
+			lines = append(lines, strings.Join(r, ","))
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return lineID(lines[i]) < lineID(lines[j]) })
+	c.Assert(lines, DeepEquals, expected)
+
+	// A later export reads at a newer version, so it sees the rows written after the former one.
+	_, err = se.Execute("insert into t values (101, 'x', 1)")
+	c.Assert(err, IsNil)
+	w = ts.export(dir, "/export/export_api/t")
+	c.Assert(w.Code, Equals, http.StatusOK)
+	var result2 exportResult
+	err = json.Unmarshal(w.Body.Bytes(), &result2)
+	c.Assert(err, IsNil)
+	c.Assert(result2.Rows, Equals, int64(101))
+	c.Assert(result2.Version, Greater, result.Version)
+
+	// The GC safe point registered for the export is removed when it's done.
+	rs, err := se.Execute("select count(*) from mysql.tidb where variable_name like 'tikv_gc_service_safe_point_%'")
+	c.Assert(err, IsNil)
+	row, err := rs[0].Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data[0].GetInt64(), Equals, int64(0))
+	rs[0].Close()
+}
+
+func (ts *testExportHandlerSuite) TestExportFileName(c *C) {
+	c.Assert(exportFileName("db_1", "T2", 10, 3), Equals, "db_1.T2.10.3.csv")
+	c.Assert(exportFileName("..", "a/../b\\c", 10, 0), Equals, "%2E%2E.a%2F%2E%2E%2Fb%5Cc.10.0.csv")
+}
+
+func lineID(line string) int {
+	var id int
+	fmt.Sscanf(line, "%d,", &id)
+	return id
+}
+
+func (ts *testExportHandlerSuite) TestExportError(c *C) {
+	w := ts.export("", "/export/mysql/user")
+	c.Assert(w.Code, Equals, http.StatusForbidden)
+	dir := c.MkDir()
+	// The system databases can't be exported.
+	for _, path := range []string{"/export/mysql/user", "/export/INFORMATION_SCHEMA/tables", "/export/performance_schema/setup_actors"} {
+		w = ts.export(dir, path)
+		c.Assert(w.Code, Equals, http.StatusForbidden, Commentf("path %s", path))
+	}
+	for _, path := range []string{"/export/unknown/t", "/export/test/unknown"} {
+		w = ts.export(dir, path)
+		c.Assert(w.Code, Equals, http.StatusNotFound, Commentf("path %s", path))
+	}
+}
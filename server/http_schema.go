@@ -0,0 +1,71 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/model"
+)
+
+// schemaHandler is the handler for dumping the table definitions, so the schema can be got without a SQL connection.
+// "/schema/{db}" lists the tables in the database, "/schema/{db}/{table}" returns the definition of the table.
+type schemaHandler struct {
+	dom *domain.Domain
+}
+
+// ServeHTTP handles request of the table definitions.
+func (h schemaHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	is := h.dom.InfoSchema()
+	params := mux.Vars(req)
+	dbName := model.NewCIStr(params[pDBName])
+	if !is.SchemaExists(dbName) {
+		http.Error(w, fmt.Sprintf("database %s doesn't exist", dbName), http.StatusNotFound)
+		return
+	}
+	tableName, ok := params[pTableName]
+	if !ok {
+		tables := is.SchemaTables(dbName)
+		names := make([]string, 0, len(tables))
+		for _, t := range tables {
+			names = append(names, t.Meta().Name.O)
+		}
+		sort.Strings(names)
+		writeJSON(w, names)
+		return
+	}
+	t, err := is.TableByName(dbName, model.NewCIStr(tableName))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, t.Meta())
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		log.Errorf("[status] encode json error: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.Write(js)
+}
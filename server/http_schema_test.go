@@ -0,0 +1,98 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	tmysql "github.com/pingcap/tidb/mysql"
+)
+
+type testSchemaHandlerSuite struct {
+	store  kv.Storage
+	router *mux.Router
+}
+
+var _ = Suite(&testSchemaHandlerSuite{})
+
+func (ts *testSchemaHandlerSuite) SetUpSuite(c *C) {
+	store, err := tidb.NewStore("memory:///tmp/tidb_schema_handler")
+	c.Assert(err, IsNil)
+	ts.store = store
+	dom, err := tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	_, err = se.Execute("create database schema_api; use schema_api;" +
+		"create table t2 (a int primary key, b varchar(10) not null default 'x', index idx_b(b)) comment 'test table';" +
+		"create table t1 (a int);")
+	c.Assert(err, IsNil)
+
+	ts.router = mux.NewRouter()
+	ts.router.Handle("/schema/{db}", schemaHandler{dom})
+	ts.router.Handle("/schema/{db}/{table}", schemaHandler{dom})
+}
+
+func (ts *testSchemaHandlerSuite) TearDownSuite(c *C) {
+	ts.store.Close()
+}
+
+func (ts *testSchemaHandlerSuite) get(path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+	return w
+}
+
+func (ts *testSchemaHandlerSuite) TestListTables(c *C) {
+	w := ts.get("/schema/SCHEMA_API")
+	c.Assert(w.Code, Equals, http.StatusOK)
+	c.Assert(w.Header().Get(headerContentType), Equals, contentTypeJSON)
+	var names []string
+	err := json.Unmarshal(w.Body.Bytes(), &names)
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"t1", "t2"})
+}
+
+func (ts *testSchemaHandlerSuite) TestTableInfo(c *C) {
+	w := ts.get("/schema/schema_api/T2")
+	c.Assert(w.Code, Equals, http.StatusOK)
+	var tblInfo model.TableInfo
+	err := json.Unmarshal(w.Body.Bytes(), &tblInfo)
+	c.Assert(err, IsNil)
+	c.Assert(tblInfo.Name.O, Equals, "t2")
+	c.Assert(tblInfo.Comment, Equals, "test table")
+	c.Assert(tblInfo.PKIsHandle, IsTrue)
+	c.Assert(tblInfo.Columns, HasLen, 2)
+	c.Assert(tblInfo.Columns[1].Name.O, Equals, "b")
+	c.Assert(tblInfo.Columns[1].Tp, Equals, tmysql.TypeVarchar)
+	c.Assert(tblInfo.Columns[1].DefaultValue, Equals, "x")
+	c.Assert(tblInfo.Indices, HasLen, 1)
+	c.Assert(tblInfo.Indices[0].Name.O, Equals, "idx_b")
+}
+
+func (ts *testSchemaHandlerSuite) TestNotFound(c *C) {
+	for _, path := range []string{"/schema/unknown", "/schema/unknown/t1", "/schema/schema_api/unknown"} {
+		w := ts.get(path)
+		c.Assert(w.Code, Equals, http.StatusNotFound, Commentf("path %s", path))
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+)
+
+// statsHandler is the handler for reporting the loading status of the stats, "/status/stats" lists the tables whose
+// latest stats are loaded and the ones pending, so we can know whether the optimizer is warmed up.
+type statsHandler struct {
+	dom *domain.Domain
+	se  *statusSession
+}
+
+// statusSession is the session shared by the status handlers to read the storage by SQL, so a session isn't created
+// for every request. The handlers use it one at a time.
+type statusSession struct {
+	mu    sync.Mutex
+	store kv.Storage
+	se    tidb.Session
+}
+
+func newStatusSession(store kv.Storage) *statusSession {
+	return &statusSession{store: store}
+}
+
+// run calls f with the shared session, the session is created on first use.
+func (s *statusSession) run(f func(ctx context.Context) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.se == nil {
+		se, err := tidb.CreateSession(s.store)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.se = se
+	}
+	return errors.Trace(f(s.se.(context.Context)))
+}
+
+// tableStatsStatus is the loading status of the stats of a table.
+type tableStatsStatus struct {
+	DB      string `json:"db"`
+	Table   string `json:"table"`
+	TableID int64  `json:"table_id"`
+	// UpdateTime is the time when the stats in the storage are updated.
+	UpdateTime time.Time `json:"update_time"`
+	// LastAnalyzeTime is nil if the loaded stats have no analyzed histogram.
+	LastAnalyzeTime *time.Time `json:"last_analyze_time,omitempty"`
+}
+
+type statsStatus struct {
+	LoadedRatio float64            `json:"loaded_ratio"`
+	Loaded      []tableStatsStatus `json:"loaded"`
+	Pending     []tableStatsStatus `json:"pending"`
+}
+
+// ServeHTTP handles request of the stats loading status.
+func (h statsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	st, err := h.status()
+	if err != nil {
+		log.Errorf("[status] get stats status failed: %v", errors.ErrorStack(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, st)
+}
+
+func (h statsHandler) status() (*statsStatus, error) {
+	is := h.dom.InfoSchema()
+	var tableStatus []statistics.TableLoadStatus
+	err := h.se.run(func(ctx context.Context) error {
+		var err1 error
+		tableStatus, err1 = h.dom.StatsHandle().LoadStatus(ctx, is)
+		return errors.Trace(err1)
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	names := make(map[int64][2]string)
+	for _, db := range is.AllSchemas() {
+		for _, tbl := range db.Tables {
+			names[tbl.ID] = [2]string{db.Name.O, tbl.Name.O}
+		}
+	}
+	st := &statsStatus{
+		LoadedRatio: statistics.LoadedRatio(tableStatus),
+		Loaded:      []tableStatsStatus{},
+		Pending:     []tableStatsStatus{},
+	}
+	for i := range tableStatus {
+		s := &tableStatus[i]
+		name := names[s.TableID]
+		ts := tableStatsStatus{
+			DB:         name[0],
+			Table:      name[1],
+			TableID:    s.TableID,
+			UpdateTime: versionToTime(s.Version),
+		}
+		if s.LastAnalyzeVersion != 0 {
+			t := versionToTime(s.LastAnalyzeVersion)
+			ts.LastAnalyzeTime = &t
+		}
+		if s.Loaded() {
+			st.Loaded = append(st.Loaded, ts)
+		} else {
+			st.Pending = append(st.Pending, ts)
+		}
+	}
+	return st, nil
+}
+
+// statsReloadHandler is the handler for "/status/stats/reload", it reloads all the stats from the storage at once
+// instead of waiting for the stats lease, and reports the loading status after the reload.
+type statsReloadHandler struct {
+	statsHandler
+}
+
+// ServeHTTP handles request of reloading the stats.
+func (h statsReloadHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	err := h.reload()
+	if err != nil {
+		log.Errorf("[status] reload stats failed: %v", errors.ErrorStack(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Infof("[status] reload stats")
+	h.statsHandler.ServeHTTP(w, req)
+}
+
+func (h statsReloadHandler) reload() error {
+	statsHandle := h.dom.StatsHandle()
+	if statsHandle.Lease <= 0 {
+		return errors.Trace(statsHandle.Reload(h.dom.InfoSchema()))
+	}
+	// The stats are updated by the stats loop of the domain if the lease is set.
+	done := make(chan error, 1)
+	statsHandle.ReloadCh() <- done
+	return errors.Trace(<-done)
+}
+
+func versionToTime(version uint64) time.Time {
+	return time.Unix(0, oracle.ExtractPhysical(version)*int64(time.Millisecond))
+}
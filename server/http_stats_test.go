@@ -0,0 +1,155 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+)
+
+type testStatsHandlerSuite struct {
+	store  kv.Storage
+	se     tidb.Session
+	router *mux.Router
+}
+
+var _ = Suite(&testStatsHandlerSuite{})
+
+func (ts *testStatsHandlerSuite) SetUpSuite(c *C) {
+	// The stats are updated at once after analyzing if the lease is 0.
+	tidb.SetStatsLease(0)
+	store, err := tidb.NewStore("memory:///tmp/tidb_stats_handler")
+	c.Assert(err, IsNil)
+	ts.store = store
+	dom, err := tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	ts.se, err = tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+	_, err = ts.se.Execute("create database stats_api; use stats_api;" +
+		"create table t1 (a int, b int, index idx_b(b)); insert into t1 values (1, 1), (2, 2);" +
+		"create table t2 (a int);")
+	c.Assert(err, IsNil)
+
+	ts.router = mux.NewRouter()
+	stats := statsHandler{dom, newStatusSession(store)}
+	ts.router.Handle("/status/stats", stats)
+	ts.router.Handle("/status/stats/reload", statsReloadHandler{stats}).Methods("POST")
+}
+
+func (ts *testStatsHandlerSuite) TearDownSuite(c *C) {
+	ts.se.Close()
+	ts.store.Close()
+}
+
+func (ts *testStatsHandlerSuite) getStatus(c *C) *statsStatus {
+	req, _ := http.NewRequest("GET", "/status/stats", nil)
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+	c.Assert(w.Code, Equals, http.StatusOK)
+	c.Assert(w.Header().Get(headerContentType), Equals, contentTypeJSON)
+	var st statsStatus
+	err := json.Unmarshal(w.Body.Bytes(), &st)
+	c.Assert(err, IsNil)
+	return &st
+}
+
+func (ts *testStatsHandlerSuite) TestStatsStatus(c *C) {
+	// No table has stats in the storage since the ddl events aren't handled without the lease.
+	st := ts.getStatus(c)
+	c.Assert(st.LoadedRatio, Equals, float64(1))
+	c.Assert(st.Loaded, HasLen, 0)
+	c.Assert(st.Pending, HasLen, 0)
+
+	_, err := ts.se.Execute("analyze table t1")
+	c.Assert(err, IsNil)
+	st = ts.getStatus(c)
+	c.Assert(st.LoadedRatio, Equals, float64(1))
+	c.Assert(st.Pending, HasLen, 0)
+	c.Assert(st.Loaded, HasLen, 1)
+	c.Assert(st.Loaded[0].DB, Equals, "stats_api")
+	c.Assert(st.Loaded[0].Table, Equals, "t1")
+	c.Assert(st.Loaded[0].LastAnalyzeTime, NotNil)
+	c.Assert(st.Loaded[0].LastAnalyzeTime.Equal(st.Loaded[0].UpdateTime), IsTrue)
+
+	// The stats changed in the storage are pending until the next update.
+	_, err = ts.se.Execute("update mysql.stats_meta set version = version + 1")
+	c.Assert(err, IsNil)
+	st = ts.getStatus(c)
+	c.Assert(st.LoadedRatio, Equals, float64(0))
+	c.Assert(st.Loaded, HasLen, 0)
+	c.Assert(st.Pending, HasLen, 1)
+	c.Assert(st.Pending[0].Table, Equals, "t1")
+
+	do := sessionctx.GetDomain(ts.se.(context.Context))
+	err = do.StatsHandle().Update(do.InfoSchema())
+	c.Assert(err, IsNil)
+	st = ts.getStatus(c)
+	c.Assert(st.LoadedRatio, Equals, float64(1))
+	c.Assert(st.Loaded, HasLen, 1)
+	c.Assert(st.Pending, HasLen, 0)
+}
+
+func (ts *testStatsHandlerSuite) TestStatsReload(c *C) {
+	_, err := ts.se.Execute("create table t3 (a int); insert into t3 values (1), (2); analyze table t3")
+	c.Assert(err, IsNil)
+	defer func() {
+		_, err = ts.se.Execute("drop table t3")
+		c.Assert(err, IsNil)
+	}()
+	do := sessionctx.GetDomain(ts.se.(context.Context))
+	tbl, err := do.InfoSchema().TableByName(model.NewCIStr("stats_api"), model.NewCIStr("t3"))
+	c.Assert(err, IsNil)
+	tableID := tbl.Meta().ID
+	c.Assert(do.StatsHandle().GetTableStats(tableID).Count, Equals, int64(2))
+
+	// The stats restored without changing the version aren't loaded by the update.
+	_, err = ts.se.Execute(fmt.Sprintf("update mysql.stats_meta set count = 100 where table_id = %d", tableID))
+	c.Assert(err, IsNil)
+	c.Assert(do.StatsHandle().GetTableStats(tableID).Count, Equals, int64(2))
+
+	// The reload is only served for POST.
+	req, _ := http.NewRequest("GET", "/status/stats/reload", nil)
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+	c.Assert(w.Code, Equals, http.StatusNotFound)
+
+	req, _ = http.NewRequest("POST", "/status/stats/reload", nil)
+	w = httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+	c.Assert(w.Code, Equals, http.StatusOK)
+	var st statsStatus
+	err = json.Unmarshal(w.Body.Bytes(), &st)
+	c.Assert(err, IsNil)
+	c.Assert(st.LoadedRatio, Equals, float64(1))
+	c.Assert(do.StatsHandle().GetTableStats(tableID).Count, Equals, int64(100))
+
+	// The stats deleted from the storage are removed from the cache by the reload.
+	_, err = ts.se.Execute(fmt.Sprintf("delete from mysql.stats_meta where table_id = %d", tableID))
+	c.Assert(err, IsNil)
+	req, _ = http.NewRequest("POST", "/status/stats/reload", nil)
+	w = httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+	c.Assert(w.Code, Equals, http.StatusOK)
+	c.Assert(do.StatsHandle().GetTableStats(tableID).Pseudo, IsTrue)
+}
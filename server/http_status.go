@@ -14,12 +14,18 @@
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/prometheus/client_golang/prometheus"
@@ -38,8 +44,24 @@ func (s *Server) startStatusHTTP() {
 func (s *Server) startHTTPServer() {
 	router := mux.NewRouter()
 	router.HandleFunc("/status", s.handleStatus)
+	// HTTP path for the queries and the transactions of the connections.
+	router.HandleFunc("/status/queries", s.handleQueries)
+	// HTTP path for dumping heap or goroutine profile to a file.
+	router.HandleFunc("/status/debug/dump", s.handleDump)
 	// HTTP path for prometheus.
 	router.Handle("/metrics", prometheus.Handler())
+	if driver, ok := s.driver.(*TiDBDriver); ok {
+		// HTTP path for dumping the table definitions.
+		router.Handle("/schema/{db}", schemaHandler{s.dom})
+		router.Handle("/schema/{db}/{table}", schemaHandler{s.dom})
+		// HTTP path for exporting the table data to CSV files concurrently.
+		router.Handle("/export/{db}/{table}", exportHandler{s.dom, driver.store, s.cfg.ExportDir, s.cfg.ExportConcurrency})
+		stats := statsHandler{s.dom, newStatusSession(driver.store)}
+		// HTTP path for the loading status of the stats.
+		router.Handle("/status/stats", stats)
+		// HTTP path for reloading the stats from the storage at once.
+		router.Handle("/status/stats/reload", statsReloadHandler{stats}).Methods("POST")
+	}
 
 	if s.cfg.Store == "tikv" {
 		tikvHandler := s.newRegionHandler()
@@ -54,14 +76,54 @@ func (s *Server) startHTTPServer() {
 	if len(addr) == 0 {
 		addr = defaultStatusAddr
 	}
-	log.Infof("Listening on %v for status and metrics report.", addr)
 	http.Handle("/", router)
-	err := http.ListenAndServe(addr, nil)
+	if len(s.cfg.StatusSSLCert) == 0 {
+		// The default mux also serves /debug/pprof registered by net/http/pprof.
+		log.Warnf("The status and metrics report on %v is served in plaintext with pprof enabled, set status-ssl-cert and status-ssl-key to serve it over HTTPS.", addr)
+		log.Infof("Listening on %v for status and metrics report.", addr)
+		err := http.ListenAndServe(addr, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	tlsConfig, err := newStatusTLSConfig(s.cfg)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	log.Infof("Listening on %v for status and metrics report over HTTPS.", addr)
+	srv := &http.Server{Addr: addr, TLSConfig: tlsConfig}
+	// The certificate is loaded in tlsConfig already.
+	err = srv.ListenAndServeTLS("", "")
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
+// newStatusTLSConfig loads the certificate of the status server, and the CA to verify the client certificates with
+// if StatusSSLCA is set.
+func newStatusTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.StatusSSLCert, cfg.StatusSSLKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if len(cfg.StatusSSLCA) == 0 {
+		return tlsConfig, nil
+	}
+	caCert, err := ioutil.ReadFile(cfg.StatusSSLCA)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.Errorf("no certificate is found in status-ssl-ca %s", cfg.StatusSSLCA)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
+
 // TiDB status
 type status struct {
 	Connections int    `json:"connections"`
@@ -85,3 +147,50 @@ func (s *Server) handleStatus(w http.ResponseWriter, req *http.Request) {
 		w.Write(js)
 	}
 }
+
+// queryInfo is the state of a connection reported by /status/queries.
+type queryInfo struct {
+	ID             uint64 `json:"id"`
+	User           string `json:"user"`
+	Host           string `json:"host"`
+	DB             string `json:"db"`
+	Command        string `json:"command"`
+	Time           uint64 `json:"time"`
+	State          uint16 `json:"state"`
+	Info           string `json:"info"`
+	IsolationLevel string `json:"isolation_level"`
+	TxnMode        string `json:"txn_mode"`
+	InTxn          bool   `json:"in_transaction"`
+}
+
+func (s *Server) handleQueries(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	queries := []queryInfo{}
+	for _, pi := range s.ShowProcessList() {
+		var t uint64
+		if len(pi.Info) != 0 {
+			t = uint64(time.Since(pi.Time) / time.Second)
+		}
+		queries = append(queries, queryInfo{
+			ID:             pi.ID,
+			User:           pi.User,
+			Host:           pi.Host,
+			DB:             pi.DB,
+			Command:        pi.Command,
+			Time:           t,
+			State:          pi.State,
+			Info:           pi.Info,
+			IsolationLevel: pi.IsolationLevel,
+			TxnMode:        pi.TxnMode,
+			InTxn:          pi.InTxn,
+		})
+	}
+	js, err := json.Marshal(queries)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Encode json error", err)
+	} else {
+		w.Write(js)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/config"
+)
+
+type testStatusTLSSuite struct {
+	dir string
+}
+
+var _ = Suite(&testStatusTLSSuite{})
+
+func (ts *testStatusTLSSuite) SetUpSuite(c *C) {
+	dir, err := ioutil.TempDir("", "tidb_status_tls")
+	c.Assert(err, IsNil)
+	ts.dir = dir
+
+	caKey, caCert := ts.createCert(c, "ca", nil, nil, true)
+	ts.createCert(c, "server", caKey, caCert, false)
+	ts.createCert(c, "client", caKey, caCert, false)
+}
+
+func (ts *testStatusTLSSuite) TearDownSuite(c *C) {
+	os.RemoveAll(ts.dir)
+}
+
+// createCert creates a certificate signed by the parent, it's self-signed if the parent is nil.
+// The certificate is written to name.pem, and the private key is written to name-key.pem.
+func (ts *testStatusTLSSuite) createCert(c *C, name string, parentKey *ecdsa.PrivateKey, parent *x509.Certificate, isCA bool) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if isCA {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	c.Assert(err, IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, IsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+	ts.writePEM(c, name+".pem", "CERTIFICATE", der)
+	ts.writePEM(c, name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	return key, cert
+}
+
+func (ts *testStatusTLSSuite) writePEM(c *C, name string, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	err := ioutil.WriteFile(ts.path(name), data, 0600)
+	c.Assert(err, IsNil)
+}
+
+func (ts *testStatusTLSSuite) path(name string) string {
+	return filepath.Join(ts.dir, name)
+}
+
+// startServer starts an HTTPS server with the TLS config of the status server.
+func (ts *testStatusTLSSuite) startServer(c *C, cfg *config.Config) *httptest.Server {
+	tlsConfig, err := newStatusTLSConfig(cfg)
+	c.Assert(err, IsNil)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	return srv
+}
+
+func (ts *testStatusTLSSuite) get(c *C, url string, withClientCert bool) error {
+	caCert, err := ioutil.ReadFile(ts.path("ca.pem"))
+	c.Assert(err, IsNil)
+	pool := x509.NewCertPool()
+	c.Assert(pool.AppendCertsFromPEM(caCert), IsTrue)
+	tlsConfig := &tls.Config{RootCAs: pool}
+	if withClientCert {
+		cert, err1 := tls.LoadX509KeyPair(ts.path("client.pem"), ts.path("client-key.pem"))
+		c.Assert(err1, IsNil)
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(string(body), Equals, "ok")
+	return nil
+}
+
+func (ts *testStatusTLSSuite) TestStatusTLS(c *C) {
+	cfg := &config.Config{
+		StatusSSLCert: ts.path("server.pem"),
+		StatusSSLKey:  ts.path("server-key.pem"),
+	}
+	srv := ts.startServer(c, cfg)
+	c.Assert(ts.get(c, srv.URL, false), IsNil)
+	c.Assert(ts.get(c, srv.URL, true), IsNil)
+	srv.Close()
+
+	// The client certificate is required if the CA is set.
+	cfg.StatusSSLCA = ts.path("ca.pem")
+	srv = ts.startServer(c, cfg)
+	c.Assert(ts.get(c, srv.URL, false), NotNil)
+	c.Assert(ts.get(c, srv.URL, true), IsNil)
+	srv.Close()
+}
+
+func (ts *testStatusTLSSuite) TestStatusTLSConfigError(c *C) {
+	_, err := newStatusTLSConfig(&config.Config{
+		StatusSSLCert: ts.path("not_exists.pem"),
+		StatusSSLKey:  ts.path("server-key.pem"),
+	})
+	c.Assert(err, NotNil)
+	// The CA file doesn't contain any certificate.
+	_, err = newStatusTLSConfig(&config.Config{
+		StatusSSLCert: ts.path("server.pem"),
+		StatusSSLKey:  ts.path("server-key.pem"),
+		StatusSSLCA:   ts.path("server-key.pem"),
+	})
+	c.Assert(err, NotNil)
+}
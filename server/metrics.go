@@ -47,6 +47,14 @@ var (
 			Help:      "Number of connections.",
 		})
 
+	handlerGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "connection_handlers",
+			Help:      "Number of the running connection handler goroutines, including the ones doing the handshake.",
+		})
+
 	executeErrorCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "tidb",
@@ -62,13 +70,32 @@ var (
 			Name:      "critical_error",
 			Help:      "Counter of critical errors.",
 		})
+
+	preparedStmtGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "prepared_stmts",
+			Help:      "Number of the statements prepared by COM_STMT_PREPARE and not closed.",
+		})
+
+	idleTxnKilledCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "idle_txn_killed_total",
+			Help:      "Counter of the connections closed for being idle in transaction longer than idle-in-transaction-timeout.",
+		})
 )
 
 func init() {
 	prometheus.MustRegister(queryHistogram)
 	prometheus.MustRegister(queryCounter)
 	prometheus.MustRegister(connGauge)
+	prometheus.MustRegister(handlerGauge)
 	prometheus.MustRegister(criticalErrorCounter)
+	prometheus.MustRegister(idleTxnKilledCounter)
+	prometheus.MustRegister(preparedStmtGauge)
 }
 
 func executeErrorToLabel(err error) string {
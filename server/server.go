@@ -39,17 +39,33 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/sqllog"
 )
 
 var (
 	baseConnID uint32
 )
 
+// When the global kill is enabled, the connection IDs are unique among the servers sharing the store,
+// a global connection ID always sets the highest bit of the 32 bits ID, stores the server ID in the
+// following 11 bits and the local connection ID in the lowest 20 bits. The server ID is acquired from the store when the server starts, the local connection ID is
+// allocated by the server, it wraps around and skips the IDs of the alive connections.
+const (
+	globalConnIDMark = 1 << 31
+	localConnIDBits  = 20
+	maxServerID      = 1<<11 - 1
+	maxLocalConnID   = 1<<localConnIDBits - 1
+)
+
 var (
 	errUnknownFieldType  = terror.ClassServer.New(codeUnknownFieldType, "unknown field type")
 	errInvalidPayloadLen = terror.ClassServer.New(codeInvalidPayloadLen, "invalid payload length")
@@ -57,6 +73,8 @@ var (
 	errInvalidType       = terror.ClassServer.New(codeInvalidType, "invalid type")
 	errNotAllowedCommand = terror.ClassServer.New(codeNotAllowedCommand, "the used command is not allowed with this TiDB version")
 	errAccessDenied      = terror.ClassServer.New(codeAccessDenied, mysql.MySQLErrName[mysql.ErrAccessDenied])
+	errIncorrectArgs     = terror.ClassServer.New(codeIncorrectArgs, mysql.MySQLErrName[mysql.ErrWrongArguments])
+	errMaxPreparedStmts  = terror.ClassServer.New(codeMaxPreparedStmts, "Can't create more than %s statements (current value: %d)")
 )
 
 // Server is the MySQL protocol server
@@ -67,6 +85,20 @@ type Server struct {
 	rwlock            *sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint32]*clientConn
+	generalLog        *generalLogger
+	// handlers is the number of the running connection handler goroutines, including the ones
+	// doing the handshake, it's accessed atomically.
+	handlers int32
+	// preparedStmts is the number of the statements prepared by COM_STMT_PREPARE and not closed in all
+	// the connections, it's accessed atomically.
+	preparedStmts int64
+
+	// dom is the domain of the store if the driver is the TiDB driver, it's shared by the global kill and the
+	// status handlers. serverID is set if the global kill is enabled, it's reset to 0 if the server ID is lost,
+	// it's protected by rwlock.
+	dom         *domain.Domain
+	serverID    uint64
+	localConnID uint32
 
 	// When a critical error occurred, we don't want to exit the process, because there may be
 	// a supervisor automatically restart it, then new client connection will be created, but we can't server it.
@@ -74,6 +106,11 @@ type Server struct {
 	stopListenerCh chan struct{}
 }
 
+// HandlerCount gets the number of the running connection handler goroutines.
+func (s *Server) HandlerCount() int {
+	return int(atomic.LoadInt32(&s.handlers))
+}
+
 // ConnectionCount gets current connection count.
 func (s *Server) ConnectionCount() int {
 	var cnt int
@@ -111,9 +148,10 @@ func (s *Server) newConn(conn net.Conn) *clientConn {
 		conn:         conn,
 		pkt:          newPacketIO(conn),
 		server:       s,
-		connectionID: atomic.AddUint32(&baseConnID, 1),
+		connectionID: s.allocConnID(),
 		collation:    mysql.DefaultCollationID,
 		alloc:        arena.NewAllocator(32 * 1024),
+		connectTime:  time.Now(),
 	}
 	log.Infof("[%d] new connection %s", cc.connectionID, conn.RemoteAddr().String())
 	if s.cfg.TCPKeepAlive {
@@ -127,6 +165,34 @@ func (s *Server) newConn(conn net.Conn) *clientConn {
 	return cc
 }
 
+// allocConnID allocates a connection ID, it's a global connection ID if the global kill is enabled.
+func (s *Server) allocConnID() uint32 {
+	s.rwlock.Lock()
+	defer s.rwlock.Unlock()
+	if s.serverID == 0 {
+		return atomic.AddUint32(&baseConnID, 1)
+	}
+	for {
+		s.localConnID = (s.localConnID + 1) & maxLocalConnID
+		if s.localConnID == 0 {
+			continue
+		}
+		connID := globalConnIDMark | uint32(s.serverID)<<localConnIDBits | s.localConnID
+		if _, ok := s.clients[connID]; !ok {
+			return connID
+		}
+	}
+}
+
+// parseGlobalConnID returns the server ID of a global connection ID,
+// the second return value is false if it isn't a global connection ID.
+func parseGlobalConnID(connID uint64) (uint64, bool) {
+	if connID>>32 != 0 || connID&globalConnIDMark == 0 {
+		return 0, false
+	}
+	return (connID &^ globalConnIDMark) >> localConnIDBits, true
+}
+
 func (s *Server) skipAuth() bool {
 	return s.cfg.SkipAuth
 }
@@ -145,6 +211,10 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 	}
 
 	var err error
+	s.generalLog, err = newGeneralLogger(cfg.GeneralLogFile, cfg.GeneralLogSampleRate)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	if cfg.Socket != "" {
 		cfg.SkipAuth = true
 		s.listener, err = net.Listen("unix", cfg.Socket)
@@ -152,8 +222,23 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		s.listener, err = net.Listen("tcp", s.cfg.Addr)
 	}
 	if err != nil {
+		s.generalLog.close()
 		return nil, errors.Trace(err)
 	}
+	if driver, ok := driver.(*TiDBDriver); ok {
+		if s.dom, err = getDomain(driver.store); err != nil {
+			s.listener.Close()
+			s.generalLog.close()
+			return nil, errors.Trace(err)
+		}
+	}
+	if cfg.EnableGlobalKill {
+		if err = s.enableGlobalKill(); err != nil {
+			s.listener.Close()
+			s.generalLog.close()
+			return nil, errors.Trace(err)
+		}
+	}
 
 	// Init rand seed for randomBuf()
 	rand.Seed(time.Now().UTC().UnixNano())
@@ -161,6 +246,44 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 	return s, nil
 }
 
+// getDomain gets the domain of the store, the domain is created once for a store and shared by all the sessions.
+func getDomain(store kv.Storage) (*domain.Domain, error) {
+	se, err := tidb.CreateSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer se.Close()
+	return sessionctx.GetDomain(se), nil
+}
+
+// enableGlobalKill acquires a server ID for the global connection IDs, and receives the KILL
+// requests of the connections on this server sent by the other servers.
+func (s *Server) enableGlobalKill() error {
+	if s.dom == nil {
+		return errors.New("global kill is only supported by the TiDB driver")
+	}
+	serverID, err := s.dom.AcquireServerID(maxServerID, s.onServerIDLost)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.rwlock.Lock()
+	s.serverID = serverID
+	s.rwlock.Unlock()
+	s.dom.GlobalKillLoop(serverID, s.killConn)
+	log.Infof("global kill is enabled, server ID %d", serverID)
+	return nil
+}
+
+// onServerIDLost is called if the server ID may be acquired by another server. The new connections get the
+// local connection IDs, which are only killed by this server, the connections with the global connection IDs
+// of the lost server ID are still killed by this server if the KILL statement is executed on it.
+func (s *Server) onServerIDLost() {
+	s.rwlock.Lock()
+	defer s.rwlock.Unlock()
+	log.Errorf("server ID %d is lost, global kill is disabled for the new connections", s.serverID)
+	s.serverID = 0
+}
+
 // Run runs the server.
 func (s *Server) Run() error {
 	// Start HTTP API to report tidb info such as TPS.
@@ -201,7 +324,7 @@ func (s *Server) shouldStopListener() bool {
 	}
 }
 
-// Close closes the server.
+// Close closes the server, the client connections are closed so their handlers exit.
 func (s *Server) Close() {
 	s.rwlock.Lock()
 	defer s.rwlock.Unlock()
@@ -210,22 +333,40 @@ func (s *Server) Close() {
 		s.listener.Close()
 		s.listener = nil
 	}
+	for _, cc := range s.clients {
+		cc.conn.Close()
+	}
+	s.generalLog.close()
 }
 
 // onConn runs in its own goroutine, handles queries from this connection.
 func (s *Server) onConn(c net.Conn) {
+	atomic.AddInt32(&s.handlers, 1)
+	handlerGauge.Inc()
 	conn := s.newConn(c)
 	defer func() {
+		atomic.AddInt32(&s.handlers, -1)
+		handlerGauge.Dec()
 		log.Infof("[%d] close connection", conn.connectionID)
+		if s.cfg.LogConnections {
+			log.Infof("[%d] connection closed, user: %s, host: %s, duration: %v, reason: %s",
+				conn.connectionID, conn.user, c.RemoteAddr(), time.Since(conn.connectTime), conn.closeReason)
+		}
 	}()
 
-	if err := conn.handshake(); err != nil {
+	if err := s.handshake(conn); err != nil {
 		// Some keep alive services will send request to TiDB and disconnect immediately.
 		// So we use info log level.
 		log.Infof("handshake error %s", errors.ErrorStack(err))
-		c.Close()
+		conn.closeReason = closeReasonOf(err)
+		// The session may be opened before the handshake fails, e.g. the authentication fails,
+		// it's closed with the connection.
+		conn.Close()
 		return
 	}
+	if s.cfg.LogConnections {
+		log.Infof("[%d] connection established, user: %s, host: %s", conn.connectionID, conn.user, c.RemoteAddr())
+	}
 
 	s.rwlock.Lock()
 	s.clients[conn.connectionID] = conn
@@ -236,6 +377,22 @@ func (s *Server) onConn(c net.Conn) {
 	conn.Run()
 }
 
+// handshake does the connection handshake within the handshake timeout, a client that doesn't
+// finish the handshake in time would get the connection closed. The timeout only applies to
+// the handshake, it's cleared for the authenticated connection.
+func (s *Server) handshake(cc *clientConn) error {
+	if s.cfg.HandshakeTimeout <= 0 {
+		return errors.Trace(cc.handshake())
+	}
+	if err := cc.conn.SetDeadline(time.Now().Add(s.cfg.HandshakeTimeout)); err != nil {
+		return errors.Trace(err)
+	}
+	if err := cc.handshake(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.conn.SetDeadline(time.Time{}))
+}
+
 // ShowProcessList implements the SessionManager interface.
 func (s *Server) ShowProcessList() []util.ProcessInfo {
 	var rs []util.ProcessInfo
@@ -251,7 +408,37 @@ func (s *Server) ShowProcessList() []util.ProcessInfo {
 }
 
 // Kill implements the SessionManager interface.
-func (s *Server) Kill(connectionID uint64, query bool) {
+// If the connection isn't on this server and it's a global connection ID of another server,
+// the KILL request is sent to that server.
+func (s *Server) Kill(connectionID uint64, query bool) error {
+	s.rwlock.RLock()
+	_, local := s.clients[uint32(connectionID)]
+	ownServerID := s.serverID
+	s.rwlock.RUnlock()
+	if !local && s.cfg.EnableGlobalKill {
+		if serverID, ok := parseGlobalConnID(connectionID); ok && serverID != ownServerID {
+			return errors.Trace(s.dom.GlobalKill(serverID, connectionID, query))
+		}
+	}
+	s.killConn(connectionID, query)
+	return nil
+}
+
+// SetSQLLogger implements the SessionManager interface.
+func (s *Server) SetSQLLogger(connectionID uint64, logger *sqllog.Logger) bool {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	conn, ok := s.clients[uint32(connectionID)]
+	if !ok {
+		return false
+	}
+	if old := conn.ctx.GetSessionVars().SetSQLLogger(logger); old != nil {
+		old.Close()
+	}
+	return true
+}
+
+func (s *Server) killConn(connectionID uint64, query bool) {
 	s.rwlock.Lock()
 	defer s.rwlock.Unlock()
 
@@ -263,6 +450,9 @@ func (s *Server) Kill(connectionID uint64, query bool) {
 	conn.ctx.Cancel()
 	if !query {
 		conn.killed = true
+		// Close the socket, so the handler blocked in reading the next command exits at once
+		// instead of waiting for the client.
+		conn.conn.Close()
 	}
 }
 
@@ -275,12 +465,16 @@ const (
 
 	codeNotAllowedCommand = 1148
 	codeAccessDenied      = mysql.ErrAccessDenied
+	codeIncorrectArgs     = mysql.ErrWrongArguments
+	codeMaxPreparedStmts  = mysql.ErrMaxPreparedStmtCountReached
 )
 
 func init() {
 	serverMySQLErrCodes := map[terror.ErrCode]uint16{
 		codeNotAllowedCommand: mysql.ErrNotAllowedCommand,
 		codeAccessDenied:      mysql.ErrAccessDenied,
+		codeIncorrectArgs:     mysql.ErrWrongArguments,
+		codeMaxPreparedStmts:  mysql.ErrMaxPreparedStmtCountReached,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassServer] = serverMySQLErrCodes
 }
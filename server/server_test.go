@@ -19,7 +19,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"testing"
@@ -238,6 +240,46 @@ func runTestPreparedString(t *C) {
 	})
 }
 
+func runTestCharset(t *C) {
+	runTests(t, dsn, func(dbt *DBTest) {
+		// The charset variables are set by the collation sent in the handshake.
+		rows := dbt.mustQuery("select @@character_set_client, @@character_set_results, @@collation_connection")
+		t.Assert(rows.Next(), IsTrue)
+		var client, results, collation string
+		t.Assert(rows.Scan(&client, &results, &collation), IsNil)
+		t.Assert(client, Equals, "utf8")
+		t.Assert(results, Equals, "utf8")
+		t.Assert(collation, Equals, "utf8_general_ci")
+		rows.Close()
+
+		rows = dbt.mustQuery("select '\u00e9\u4e2d'")
+		t.Assert(rows.Next(), IsTrue)
+		var out []byte
+		t.Assert(rows.Scan(&out), IsNil)
+		t.Assert(out, DeepEquals, []byte("\u00e9\u4e2d"))
+		rows.Close()
+	})
+
+	runTests(t, dsn+"&collation=latin1_swedish_ci", func(dbt *DBTest) {
+		dbt.mustExec("create table test (a varchar(10), b varbinary(10))")
+		dbt.mustExec("insert test values ('\u00e9\u4e2d', '\u00e9')")
+		// The results are encoded in latin1, the characters which can't be represented are replaced by '?'.
+		rows := dbt.mustQuery("select a, b from test")
+		t.Assert(rows.Next(), IsTrue)
+		var outA, outB []byte
+		t.Assert(rows.Scan(&outA, &outB), IsNil)
+		t.Assert(outA, DeepEquals, []byte{0xe9, '?'})
+		t.Assert(outB, DeepEquals, []byte("\u00e9"))
+		rows.Close()
+
+		rows = dbt.mustQuery("select a from test where 1 = ?", 1)
+		t.Assert(rows.Next(), IsTrue)
+		t.Assert(rows.Scan(&outA), IsNil)
+		t.Assert(outA, DeepEquals, []byte{0xe9, '?'})
+		rows.Close()
+	})
+}
+
 func runTestLoadData(c *C) {
 	// create a file and write data.
 	path := "/tmp/load_data_test.csv"
@@ -505,6 +547,34 @@ func runTestIssue3682(c *C) {
 	c.Assert(err.Error(), Equals, "Error 1045: Access denied for user 'abc'@'127.0.0.1' (using password: YES)")
 }
 
+func runTestDefaultDB(c *C) {
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE DATABASE default_db;`)
+		dbt.mustExec(`CREATE USER 'default_db'@'%' IDENTIFIED BY '123';`)
+		dbt.mustExec(`UPDATE mysql.user SET Default_db = 'default_db' WHERE User = 'default_db';`)
+		dbt.mustExec(`FLUSH PRIVILEGES;`)
+	})
+	checkCurrentDB := func(dsn string, expected string) {
+		runTests(c, dsn, func(dbt *DBTest) {
+			var db sql.NullString
+			dbt.Assert(dbt.db.QueryRow("SELECT DATABASE();").Scan(&db), IsNil)
+			dbt.Check(db.String, Equals, expected)
+		})
+	}
+	// The user connects without specifying a database uses the default one.
+	checkCurrentDB("default_db:123@tcp(127.0.0.1:4001)/?strict=true", "default_db")
+	// The database specified by the client takes precedence.
+	checkCurrentDB("default_db:123@tcp(127.0.0.1:4001)/test?strict=true", "test")
+	// Other users aren't affected.
+	checkCurrentDB("root@tcp(127.0.0.1:4001)/?strict=true", "")
+
+	// The connection isn't refused if the default database doesn't exist.
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec(`DROP DATABASE default_db;`)
+	})
+	checkCurrentDB("default_db:123@tcp(127.0.0.1:4001)/?strict=true", "")
+}
+
 func runTestIssues(c *C) {
 	// For issue #263
 	unExistsSchemaDsn := "root@tcp(localhost:4001)/unexists_schema?strict=true"
@@ -537,6 +607,83 @@ func runTestStatusAPI(c *C) {
 	c.Assert(data.GitHash, Equals, printer.TiDBGitHash)
 }
 
+func runTestQueriesAPI(c *C, server *Server) {
+	db, err := sql.Open("mysql", dsn)
+	c.Assert(err, IsNil)
+	defer db.Close()
+	// Keep all the statements on the same connection.
+	db.SetMaxOpenConns(1)
+
+	getQuery := func(connID uint64) *queryInfo {
+		// The status server on 10090 may belong to another server of the tests, so the handler is called directly.
+		req, _ := http.NewRequest("GET", "/status/queries", nil)
+		w := httptest.NewRecorder()
+		server.handleQueries(w, req)
+		var queries []queryInfo
+		c.Assert(json.NewDecoder(w.Body).Decode(&queries), IsNil)
+		for i := range queries {
+			if queries[i].ID == connID {
+				return &queries[i]
+			}
+		}
+		return nil
+	}
+
+	txn, err := db.Begin()
+	c.Assert(err, IsNil)
+	_, err = txn.Exec("set @@tx_isolation = 'READ-COMMITTED'")
+	c.Assert(err, IsNil)
+	var connID uint64
+	c.Assert(txn.QueryRow("select connection_id()").Scan(&connID), IsNil)
+	query := getQuery(connID)
+	c.Assert(query, NotNil)
+	c.Assert(query.User, Equals, "root")
+	c.Assert(query.DB, Equals, "test")
+	c.Assert(query.IsolationLevel, Equals, "READ-COMMITTED")
+	c.Assert(query.TxnMode, Equals, "OPTIMISTIC")
+	c.Assert(query.InTxn, IsTrue)
+
+	c.Assert(txn.Commit(), IsNil)
+	query = getQuery(connID)
+	c.Assert(query, NotNil)
+	c.Assert(query.InTxn, IsFalse)
+}
+
+func runTestDumpAPI(c *C) {
+	for _, tp := range []string{"heap", "goroutine"} {
+		resp, err := http.Get("http://127.0.0.1:10090/status/debug/dump?type=" + tp)
+		c.Assert(err, IsNil)
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		var data dumpResult
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		resp.Body.Close()
+		c.Assert(err, IsNil)
+		c.Assert(data.Type, Equals, tp)
+		c.Assert(filepath.Dir(data.Path), Equals, filepath.Clean(os.TempDir()))
+		fi, err := os.Stat(data.Path)
+		c.Assert(err, IsNil)
+		c.Assert(fi.Size(), Greater, int64(0))
+		os.Remove(data.Path)
+	}
+
+	resp, err := http.Get("http://127.0.0.1:10090/status/debug/dump?type=cpu")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+
+	// All the tokens are taken, the dump is rejected.
+	for i := 0; i < maxConcurrentDumps; i++ {
+		dumpTokens <- struct{}{}
+	}
+	resp, err = http.Get("http://127.0.0.1:10090/status/debug/dump?type=heap")
+	for i := 0; i < maxConcurrentDumps; i++ {
+		<-dumpTokens
+	}
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusServiceUnavailable)
+}
+
 func runTestMultiStatements(c *C) {
 	runTestsOnNewDB(c, "MultiStatements", func(dbt *DBTest) {
 		// Create Table
@@ -16,12 +16,21 @@ package server
 
 import (
 	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ngaut/log"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/config"
+	tmysql "github.com/pingcap/tidb/mysql"
+	goctx "golang.org/x/net/context"
 )
 
 type TidbTestSuite struct {
@@ -79,6 +88,10 @@ func (ts *TidbTestSuite) TestSpecialType(c *C) {
 	runTestSpecialType(c)
 }
 
+func (ts *TidbTestSuite) TestCharset(c *C) {
+	runTestCharset(c)
+}
+
 func (ts *TidbTestSuite) TestPreparedString(c *C) {
 	c.Parallel()
 	runTestPreparedString(c)
@@ -100,6 +113,10 @@ func (ts *TidbTestSuite) TestAuth(c *C) {
 	runTestAuth(c)
 }
 
+func (ts *TidbTestSuite) TestDefaultDB(c *C) {
+	runTestDefaultDB(c)
+}
+
 func (ts *TidbTestSuite) TestIssues(c *C) {
 	runTestIssues(c)
 }
@@ -113,6 +130,14 @@ func (ts *TidbTestSuite) TestStatusAPI(c *C) {
 	runTestStatusAPI(c)
 }
 
+func (ts *TidbTestSuite) TestQueriesAPI(c *C) {
+	runTestQueriesAPI(c, ts.server)
+}
+
+func (ts *TidbTestSuite) TestDumpAPI(c *C) {
+	runTestDumpAPI(c)
+}
+
 func (ts *TidbTestSuite) TestMultiStatements(c *C) {
 	c.Parallel()
 	runTestMultiStatements(c)
@@ -137,6 +162,97 @@ func (ts *TidbTestSuite) TestSocket(c *C) {
 	server.Close()
 }
 
+func (ts *TidbTestSuite) TestHandshakeTimeout(c *C) {
+	c.Parallel()
+	cfg := &config.Config{
+		Addr:             ":4002",
+		LogLevel:         "debug",
+		HandshakeTimeout: 200 * time.Millisecond,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	defer server.Close()
+	time.Sleep(time.Millisecond * 100)
+
+	// The connection is closed if the client doesn't finish the handshake.
+	conn, err := net.Dial("tcp", "127.0.0.1:4002")
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	pkt := newPacketIO(conn)
+	_, err = pkt.readPacket()
+	c.Assert(err, IsNil)
+	start := time.Now()
+	_, err = pkt.readPacket()
+	c.Assert(err, NotNil)
+	c.Assert(time.Since(start) < 5*time.Second, IsTrue)
+
+	// The timeout doesn't apply to the authenticated connection.
+	db, err := sql.Open("mysql", "root@tcp(127.0.0.1:4002)/test?strict=true")
+	c.Assert(err, IsNil)
+	defer db.Close()
+	sqlConn, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	defer sqlConn.Close()
+	c.Assert(sqlConn.PingContext(goctx.Background()), IsNil)
+	time.Sleep(cfg.HandshakeTimeout * 2)
+	c.Assert(sqlConn.PingContext(goctx.Background()), IsNil)
+}
+
+func (ts *TidbTestSuite) TestIdleInTxnTimeout(c *C) {
+	c.Parallel()
+	cfg := &config.Config{
+		Addr:             ":4006",
+		LogLevel:         "debug",
+		IdleInTxnTimeout: 200 * time.Millisecond,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	defer server.Close()
+	time.Sleep(time.Millisecond * 100)
+
+	db, err := sql.Open("mysql", "root@tcp(127.0.0.1:4006)/test?strict=true")
+	c.Assert(err, IsNil)
+	defer db.Close()
+	_, err = db.Exec("create table idle_txn (a int)")
+	c.Assert(err, IsNil)
+	defer db.Exec("drop table idle_txn")
+
+	// The connection idle out of transactions is kept.
+	sqlConn, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	defer sqlConn.Close()
+	c.Assert(sqlConn.PingContext(goctx.Background()), IsNil)
+	time.Sleep(cfg.IdleInTxnTimeout * 2)
+	c.Assert(sqlConn.PingContext(goctx.Background()), IsNil)
+
+	// The connection is closed and the transaction is rolled back if it's idle in the transaction for too long.
+	txn, err := db.Begin()
+	c.Assert(err, IsNil)
+	_, err = txn.Exec("insert into idle_txn values (1)")
+	c.Assert(err, IsNil)
+	time.Sleep(cfg.IdleInTxnTimeout * 3)
+	_, err = txn.Exec("insert into idle_txn values (2)")
+	c.Assert(err, NotNil)
+	txn.Rollback()
+	var count int
+	c.Assert(db.QueryRow("select count(*) from idle_txn").Scan(&count), IsNil)
+	c.Assert(count, Equals, 0)
+
+	// The transaction which isn't idle for too long commits.
+	txn, err = db.Begin()
+	c.Assert(err, IsNil)
+	for i := 0; i < 3; i++ {
+		_, err = txn.Exec("insert into idle_txn values (?)", i)
+		c.Assert(err, IsNil)
+		time.Sleep(cfg.IdleInTxnTimeout / 4)
+	}
+	c.Assert(txn.Commit(), IsNil)
+	c.Assert(db.QueryRow("select count(*) from idle_txn").Scan(&count), IsNil)
+	c.Assert(count, Equals, 3)
+}
+
 func (ts *TidbTestSuite) TestIssue3662(c *C) {
 	c.Parallel()
 	db, err := sql.Open("mysql", "root@tcp(localhost:4001)/a_database_not_exist")
@@ -182,3 +298,258 @@ func (ts *TidbTestSuite) TestDBNameEscape(c *C) {
 func (ts *TidbTestSuite) TestIssue3682(c *C) {
 	runTestIssue3682(c)
 }
+
+func (ts *TidbTestSuite) TestGlobalKill(c *C) {
+	c.Parallel()
+	var servers [2]*Server
+	for i := range servers {
+		cfg := &config.Config{
+			Addr:             fmt.Sprintf(":%d", 4003+i),
+			LogLevel:         "debug",
+			EnableGlobalKill: true,
+		}
+		server, err := NewServer(cfg, ts.tidbdrv)
+		c.Assert(err, IsNil)
+		c.Assert(server.serverID, Not(Equals), uint64(0))
+		go server.Run()
+		defer server.Close()
+		servers[i] = server
+	}
+	c.Assert(servers[0].serverID, Not(Equals), servers[1].serverID)
+	time.Sleep(time.Millisecond * 100)
+
+	db, err := sql.Open("mysql", "root@tcp(127.0.0.1:4003)/test?strict=true")
+	c.Assert(err, IsNil)
+	defer db.Close()
+	sqlConn, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	defer sqlConn.Close()
+	var connID uint64
+	err = sqlConn.QueryRowContext(goctx.Background(), "select connection_id()").Scan(&connID)
+	c.Assert(err, IsNil)
+	serverID, ok := parseGlobalConnID(connID)
+	c.Assert(ok, IsTrue)
+	c.Assert(serverID, Equals, servers[0].serverID)
+	c.Assert(connID&maxLocalConnID, Not(Equals), uint64(0))
+
+	// The KILL request of a connection on an unknown server fails.
+	err = servers[1].Kill(uint64(globalConnIDMark|maxServerID<<localConnIDBits|1), false)
+	c.Assert(err, NotNil)
+
+	// The connection is killed by the server which owns it.
+	err = servers[1].Kill(connID, false)
+	c.Assert(err, IsNil)
+	for i := 0; i < 50; i++ {
+		servers[0].rwlock.RLock()
+		cc, ok := servers[0].clients[uint32(connID)]
+		killed := !ok || cc.killed
+		servers[0].rwlock.RUnlock()
+		if killed {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	_, err = sqlConn.ExecContext(goctx.Background(), "select 1")
+	c.Assert(err, NotNil)
+
+	// The new connections get the local connection IDs once the server ID is lost.
+	servers[0].onServerIDLost()
+	_, ok = parseGlobalConnID(uint64(servers[0].allocConnID()))
+	c.Assert(ok, IsFalse)
+}
+
+func (ts *TidbTestSuite) TestGeneralLog(c *C) {
+	c.Parallel()
+	logFile := filepath.Join(c.MkDir(), "general.log")
+	cfg := &config.Config{
+		Addr:                 ":4005",
+		LogLevel:             "debug",
+		GeneralLogFile:       logFile,
+		GeneralLogSampleRate: 1,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	defer server.Close()
+	time.Sleep(time.Millisecond * 100)
+
+	db, err := sql.Open("mysql", "root@tcp(127.0.0.1:4005)/test?strict=true")
+	c.Assert(err, IsNil)
+	defer db.Close()
+	sqlConn, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	defer sqlConn.Close()
+	// The statements are logged only after the session turns on general_log.
+	_, err = sqlConn.ExecContext(goctx.Background(), "select 'not logged'")
+	c.Assert(err, IsNil)
+	_, err = sqlConn.ExecContext(goctx.Background(), "set @@general_log = 1")
+	c.Assert(err, IsNil)
+	_, err = sqlConn.ExecContext(goctx.Background(), "select 'logged'")
+	c.Assert(err, IsNil)
+	_, err = sqlConn.ExecContext(goctx.Background(), "select ?", 1)
+	c.Assert(err, IsNil)
+	// The statement is logged before it's executed, so the failed one is logged too.
+	_, err = sqlConn.ExecContext(goctx.Background(), "select * from general_log_not_exist")
+	c.Assert(err, NotNil)
+
+	data, err := ioutil.ReadFile(logFile)
+	c.Assert(err, IsNil)
+	var logged [][]string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Split(line, "\t")
+		c.Assert(fields, HasLen, 5, Commentf("line %s", line))
+		c.Assert(fields[2], Equals, "root")
+		logged = append(logged, fields[3:])
+	}
+	c.Assert(logged, DeepEquals, [][]string{
+		{generalLogQuery, "select 'logged'"},
+		{generalLogPrepare, "select ?"},
+		{generalLogExecute, "statement 1"},
+		{generalLogQuery, "select * from general_log_not_exist"},
+	})
+}
+
+func (ts *TidbTestSuite) TestMaxPreparedStmtCount(c *C) {
+	c.Parallel()
+	cfg := &config.Config{
+		Addr:                        ":4008",
+		LogLevel:                    "debug",
+		MaxPreparedStmtCount:        3,
+		MaxPreparedStmtCountPerConn: 2,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	defer server.Close()
+	time.Sleep(time.Millisecond * 100)
+
+	db, err := sql.Open("mysql", "root@tcp(127.0.0.1:4008)/test?strict=true")
+	c.Assert(err, IsNil)
+	defer db.Close()
+	// The connections aren't kept in the pool after they're closed.
+	db.SetMaxIdleConns(0)
+	conn1, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	defer conn1.Close()
+	conn2, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	prepare := func(conn *sql.Conn) (*sql.Stmt, error) {
+		return conn.PrepareContext(goctx.Background(), "select ?")
+	}
+	checkErr := func(err error, limit string) {
+		checkErrorCode(c, err, tmysql.ErrMaxPreparedStmtCountReached)
+		c.Assert(err.Error(), Matches, ".*more than "+limit+" statements.*")
+	}
+
+	// The limit of the connection is reached.
+	stmt1, err := prepare(conn1)
+	c.Assert(err, IsNil)
+	_, err = prepare(conn1)
+	c.Assert(err, IsNil)
+	_, err = prepare(conn1)
+	checkErr(err, "max-prepared-stmt-count-per-conn")
+	c.Assert(atomic.LoadInt64(&server.preparedStmts), Equals, int64(2))
+	// The closed statement isn't counted.
+	c.Assert(stmt1.Close(), IsNil)
+	_, err = prepare(conn1)
+	c.Assert(err, IsNil)
+
+	// The limit of all the connections is reached.
+	_, err = prepare(conn2)
+	c.Assert(err, IsNil)
+	_, err = prepare(conn2)
+	checkErr(err, "max-prepared-stmt-count")
+	// The statements of the closed connection aren't counted.
+	conn2.Close()
+	c.Assert(waitFor(func() bool { return atomic.LoadInt64(&server.preparedStmts) == 2 }), IsTrue)
+	conn3, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	defer conn3.Close()
+	_, err = prepare(conn3)
+	c.Assert(err, IsNil)
+}
+
+// testConnLifecycleSuite isn't parallel, other connections would affect the goroutine count.
+type testConnLifecycleSuite struct {
+	server *Server
+}
+
+var _ = Suite(new(testConnLifecycleSuite))
+
+func (s *testConnLifecycleSuite) SetUpSuite(c *C) {
+	store, err := tidb.NewStore("memory:///tmp/tidb_lifecycle")
+	c.Assert(err, IsNil)
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	cfg := &config.Config{
+		Addr:     ":4007",
+		LogLevel: "debug",
+	}
+	s.server, err = NewServer(cfg, NewTiDBDriver(store))
+	c.Assert(err, IsNil)
+	go s.server.Run()
+	time.Sleep(time.Millisecond * 100)
+}
+
+func (s *testConnLifecycleSuite) TearDownSuite(c *C) {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// waitFor checks cond every 10 ms until it's true or the retries are exhausted.
+func waitFor(cond func() bool) bool {
+	for i := 0; i < retryTime; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return cond()
+}
+
+func (s *testConnLifecycleSuite) TestHandlerLeak(c *C) {
+	baseline := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		// A normal connection.
+		db, err := sql.Open("mysql", "root@tcp(127.0.0.1:4007)/test?strict=true")
+		c.Assert(err, IsNil)
+		_, err = db.Exec("select 1")
+		c.Assert(err, IsNil)
+		db.Close()
+
+		// A connection failed in the authentication.
+		db, err = sql.Open("mysql", "root:wrong@tcp(127.0.0.1:4007)/test")
+		c.Assert(err, IsNil)
+		c.Assert(db.Ping(), NotNil)
+		db.Close()
+
+		// A connection closed by the client in the middle of the handshake.
+		conn, err := net.Dial("tcp", "127.0.0.1:4007")
+		c.Assert(err, IsNil)
+		_, err = conn.Read(make([]byte, 1024))
+		c.Assert(err, IsNil)
+		conn.Close()
+	}
+
+	// An idle connection killed by the server.
+	db, err := sql.Open("mysql", "root@tcp(127.0.0.1:4007)/test?strict=true")
+	c.Assert(err, IsNil)
+	defer db.Close()
+	sqlConn, err := db.Conn(goctx.Background())
+	c.Assert(err, IsNil)
+	defer sqlConn.Close()
+	var connID uint64
+	err = sqlConn.QueryRowContext(goctx.Background(), "select connection_id()").Scan(&connID)
+	c.Assert(err, IsNil)
+	c.Assert(s.server.HandlerCount(), Equals, 1)
+	c.Assert(s.server.Kill(connID, false), IsNil)
+	// The handler exits without waiting for the client.
+	c.Assert(waitFor(func() bool { return s.server.HandlerCount() == 0 }), IsTrue)
+	c.Assert(s.server.ConnectionCount(), Equals, 0)
+	sqlConn.Close()
+	db.Close()
+
+	c.Assert(waitFor(func() bool { return runtime.NumGoroutine() <= baseline }), IsTrue,
+		Commentf("baseline %d, current %d", baseline, runtime.NumGoroutine()))
+}
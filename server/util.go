@@ -40,12 +40,15 @@ import (
 	"math"
 	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/types"
+	"golang.org/x/text/encoding/charmap"
 )
 
 func parseLengthEncodedInt(b []byte) (num uint64, isNull bool, n int) {
@@ -358,3 +361,73 @@ func dumpTextValue(colInfo *ColumnInfo, value types.Datum) ([]byte, error) {
 		return nil, errInvalidType.Gen("invalid type %v", value.Kind())
 	}
 }
+
+// resultRuneEncoder returns the function which encodes a rune in the charset of character_set_results,
+// it returns nil if the results are sent in UTF-8 as they are.
+func resultRuneEncoder(cs string) func(r rune) (byte, bool) {
+	switch cs {
+	case charset.CharsetLatin1:
+		// MySQL's latin1 is cp1252.
+		return charmap.Windows1252.EncodeRune
+	case charset.CharsetASCII:
+		return func(r rune) (byte, bool) {
+			return byte(r), r < utf8.RuneSelf
+		}
+	}
+	return nil
+}
+
+// encodeResultRow converts the strings of the row from UTF-8 to the charset of character_set_results,
+// the characters which can't be represented in the charset are replaced by '?' like MySQL.
+// The values of the binary columns are unchanged, the row is returned as it is if nothing is converted.
+func encodeResultRow(cs string, columns []*ColumnInfo, row []types.Datum) []types.Datum {
+	encodeRune := resultRuneEncoder(cs)
+	if encodeRune == nil {
+		return row
+	}
+	var encoded []types.Datum
+	for i, val := range row {
+		if val.Kind() != types.KindString && val.Kind() != types.KindBytes {
+			continue
+		}
+		if i < len(columns) && columns[i].Charset == uint16(mysql.CharsetIDs[charset.CharsetBin]) {
+			continue
+		}
+		b := val.GetBytes()
+		if isASCII(b) {
+			continue
+		}
+		if encoded == nil {
+			encoded = make([]types.Datum, len(row))
+			copy(encoded, row)
+		}
+		encoded[i].SetBytes(encodeResultString(b, encodeRune))
+	}
+	if encoded == nil {
+		return row
+	}
+	return encoded
+}
+
+func encodeResultString(b []byte, encodeRune func(r rune) (byte, bool)) []byte {
+	res := make([]byte, 0, len(b))
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		c, ok := encodeRune(r)
+		if r == utf8.RuneError || !ok {
+			c = '?'
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
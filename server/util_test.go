@@ -123,3 +123,27 @@ func (s *testUtilSuite) TestDumpTextValue(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(string(bs), Equals, "1.23")
 }
+
+func (s *testUtilSuite) TestEncodeResultRow(c *C) {
+	defer testleak.AfterTest(c)()
+
+	columns := []*ColumnInfo{
+		{Type: mysql.TypeVarchar, Charset: uint16(mysql.CharsetIDs["utf8"])},
+		{Type: mysql.TypeVarchar, Charset: uint16(mysql.CharsetIDs["binary"])},
+		{Type: mysql.TypeLonglong},
+	}
+	row := types.MakeDatums("é中a", "é", 1)
+	// The results are sent as they are in UTF-8.
+	c.Assert(encodeResultRow("utf8", columns, row), DeepEquals, row)
+	c.Assert(encodeResultRow("", columns, row), DeepEquals, row)
+
+	encoded := encodeResultRow("latin1", columns, row)
+	c.Assert(encoded[0].GetBytes(), DeepEquals, []byte{0xe9, '?', 'a'})
+	c.Assert(encoded[1].GetBytes(), DeepEquals, []byte("é"))
+	c.Assert(encoded[2].GetInt64(), Equals, int64(1))
+	// The row isn't changed.
+	c.Assert(row[0].GetString(), Equals, "é中a")
+
+	encoded = encodeResultRow("ascii", columns, row)
+	c.Assert(encoded[0].GetBytes(), DeepEquals, []byte("??a"))
+}
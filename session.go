@@ -38,6 +38,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/perfschema"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx"
@@ -588,6 +589,28 @@ func (s *session) GetGlobalSysVar(name string) (string, error) {
 	return sysVar, nil
 }
 
+// GetAllSysVars implements GlobalVarAccessor.GetAllSysVars interface.
+func (s *session) GetAllSysVars() (map[string]string, error) {
+	if s.Value(context.Initing) != nil {
+		// When running bootstrap or upgrade, we should not access global storage.
+		return nil, nil
+	}
+	sql := fmt.Sprintf(`SELECT VARIABLE_NAME, VARIABLE_VALUE FROM %s.%s;`, mysql.SystemDB, mysql.GlobalVariablesTable)
+	rows, _, err := s.ExecRestrictedSQL(s, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ret := make(map[string]string, len(rows))
+	for _, row := range rows {
+		value, err := row.Data[1].ToString()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ret[row.Data[0].GetString()] = value
+	}
+	return ret, nil
+}
+
 // SetGlobalSysVar implements GlobalVarAccessor.SetGlobalSysVar interface.
 func (s *session) SetGlobalSysVar(name string, value string) error {
 	sql := fmt.Sprintf(`REPLACE %s.%s VALUES ('%s', '%s');`,
@@ -601,7 +624,7 @@ func (s *session) ParseSQL(sql, charset, collation string) ([]ast.StmtNode, erro
 	return s.parser.Parse(sql, charset, collation)
 }
 
-func (s *session) SetProcessInfo(sql string) {
+func (s *session) SetProcessInfo(sql string, p plan.Plan) {
 	pi := util.ProcessInfo{
 		ID:      s.sessionVars.ConnectionID,
 		DB:      s.sessionVars.CurrentDB,
@@ -609,6 +632,14 @@ func (s *session) SetProcessInfo(sql string) {
 		Time:    time.Now(),
 		State:   s.Status(),
 		Info:    sql,
+		Plan:    p,
+
+		IsolationLevel: ast.RepeatableRead,
+		TxnMode:        util.TxnModeOptimistic,
+		InTxn:          s.sessionVars.InTxn(),
+	}
+	if s.sessionVars.Systems[variable.TxnIsolation] == ast.ReadCommitted {
+		pi.IsolationLevel = ast.ReadCommitted
 	}
 	if s.sessionVars.User != nil {
 		pi.User = s.sessionVars.User.Username
@@ -634,9 +665,14 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	ph := sessionctx.GetDomain(s).PerfSchema()
 	for i, rst := range rawStmts {
 		s.PrepareTxnCtx()
-		startTS := time.Now()
 		// Some executions are done in compile stage, so we reset them before compile.
 		executor.ResetStmtCtx(s, rst)
+		if err1 := s.checkBlocklist(rst); err1 != nil {
+			log.Warnf("[%d] blocked statement:\n%s", connID, sql)
+			s.RollbackTxn()
+			return nil, errors.Trace(err1)
+		}
+		startTS := time.Now()
 		st, err1 := Compile(s, rst)
 		if err1 != nil {
 			log.Warnf("[%d] compile error:\n%v\n%s", connID, err1, sql)
@@ -672,8 +708,36 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	return rs, nil
 }
 
+// checkBlocklist returns an error if the digest of the statement is in the blocklist.
+// Internal SQL and admin statements are never blocked, so the blocklist itself can always be changed.
+func (s *session) checkBlocklist(stmt ast.StmtNode) error {
+	if s.sessionVars.InRestrictedSQL {
+		return nil
+	}
+	if _, ok := stmt.(*ast.AdminStmt); ok {
+		return nil
+	}
+	blocklist := sessionctx.GetDomain(s).DigestBlocklist()
+	if blocklist.Empty() {
+		return nil
+	}
+	digest := parser.Digest(stmt.Text())
+	if blocklist.Blocked(digest) {
+		return domain.ErrQueryBlocked.GenByArgs(digest)
+	}
+	return nil
+}
+
 // PrepareStmt is used for executing prepare statement in binary protocol
 func (s *session) PrepareStmt(sql string) (stmtID uint32, paramCount int, fields []*ast.ResultField, err error) {
+	blocklist := sessionctx.GetDomain(s).DigestBlocklist()
+	if !blocklist.Empty() {
+		// The placeholders and the literals are normalized to the same token, so a prepared
+		// statement has the same digest as the statement it is prepared from.
+		if digest := parser.Digest(sql); blocklist.Blocked(digest) {
+			return 0, 0, nil, domain.ErrQueryBlocked.GenByArgs(digest)
+		}
+	}
 	if s.sessionVars.TxnCtx.InfoSchema == nil {
 		// We don't need to create a transaction for prepare statement, just get information schema will do.
 		s.sessionVars.TxnCtx.InfoSchema = sessionctx.GetDomain(s).InfoSchema()
@@ -836,6 +900,12 @@ func (s *session) Close() {
 	if err := s.RollbackTxn(); err != nil {
 		log.Error("session Close error:", errors.ErrorStack(err))
 	}
+	if s.sessionVars.StmtCtx.MemTracker != nil {
+		s.sessionVars.StmtCtx.MemTracker.Detach()
+	}
+	if l := s.sessionVars.SetSQLLogger(nil); l != nil {
+		l.Close()
+	}
 	return
 }
 
@@ -954,6 +1024,14 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	se2, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = dom.LoadBlocklistLoop(se2)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	if raw, ok := store.(domain.EtcdBackend); ok {
 		err = raw.StartGCWorker()
@@ -1028,7 +1106,7 @@ func createSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 15
+	currentBootstrapVersion = 18
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -1077,6 +1155,7 @@ const loadCommonGlobalVarsSQL = "select HIGH_PRIORITY * from mysql.global_variab
 	variable.AutocommitVar + quoteCommaQuote +
 	variable.SQLModeVar + quoteCommaQuote +
 	variable.MaxAllowedPacket + quoteCommaQuote +
+	variable.GeneralLog + quoteCommaQuote +
 	/* TiDB specific global variables: */
 	variable.TiDBSkipUTF8Check + quoteCommaQuote +
 	variable.TiDBIndexJoinBatchSize + quoteCommaQuote +
@@ -1085,6 +1164,9 @@ const loadCommonGlobalVarsSQL = "select HIGH_PRIORITY * from mysql.global_variab
 	variable.TiDBIndexSerialScanConcurrency + quoteCommaQuote +
 	variable.TiDBMaxRowCountForINLJ + quoteCommaQuote +
 	variable.TiDBCBO + quoteCommaQuote +
+	variable.TiDBEnableStatsFeedback + quoteCommaQuote +
+	variable.TiDBMemQuotaQuery + quoteCommaQuote +
+	variable.TiDBEnableSpill + quoteCommaQuote +
 	variable.TiDBDistSQLScanConcurrency + "')"
 
 // loadCommonGlobalVariablesIfNeeded loads and applies commonly used global variables for the session.
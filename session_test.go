@@ -333,6 +333,11 @@ func (s *testSessionSuite) TestAutoIncrementID(c *C) {
 	// The last_insert_id function only use last auto-generated id.
 	mustExecMatch(c, se, "select last_insert_id()", [][]interface{}{{lastID}})
 
+	// The last insert ID is the first auto-generated value, even if it isn't in the first row.
+	mustExecSQL(c, se, "insert t values (200), (null), (null)")
+	c.Assert(se.LastInsertID(), Equals, uint64(201))
+	mustExecMatch(c, se, "select last_insert_id()", [][]interface{}{{201}})
+
 	mustExecSQL(c, se, "drop table if exists t")
 	mustExecSQL(c, se, "create table t (i tinyint unsigned not null auto_increment, primary key (i));")
 	mustExecSQL(c, se, "insert into t set i = 254;")
@@ -2745,3 +2750,61 @@ func (s *testSessionSuite) TestPrepareStmtCommitWhenSchemaChanged(c *C) {
 	// _, err = s2.Execute("commit")
 	// c.Assert(terror.ErrorEqual(err, executor.ErrWrongValueCountOnRow), IsTrue)
 }
+
+func (s *testSessionSuite) TestDigestBlocklist(c *C) {
+	defer testleak.AfterTest(c)()
+	dbName := "test_digest_blocklist"
+	se := newSession(c, s.store, dbName)
+	mustExecSQL(c, se, "create table t (a int, b int)")
+	mustExecSQL(c, se, "insert into t values (1, 1), (2, 2)")
+
+	digest := parser.Digest("select * from t where a = 1")
+	mustExecSQL(c, se, fmt.Sprintf("admin block digest '%s'", digest))
+	// Statements that only differ in literals share the digest.
+	_, err := se.Execute("SELECT * FROM t WHERE a = 2")
+	c.Assert(terror.ErrorEqual(err, domain.ErrQueryBlocked), IsTrue, Commentf("err %v", err))
+	_, _, _, err = se.PrepareStmt("select * from t where a = ?")
+	c.Assert(terror.ErrorEqual(err, domain.ErrQueryBlocked), IsTrue, Commentf("err %v", err))
+	mustExecSQL(c, se, "select * from t where b = 1")
+
+	// The blocklist is loaded from the system table.
+	dom := sessionctx.GetDomain(se)
+	dom.DigestBlocklist().UnblockAll()
+	mustExecSQL(c, se, "select * from t where a = 1")
+	c.Assert(dom.DigestBlocklist().Update(se), IsNil)
+	_, err = se.Execute("select * from t where a = 1")
+	c.Assert(terror.ErrorEqual(err, domain.ErrQueryBlocked), IsTrue, Commentf("err %v", err))
+
+	mustExecSQL(c, se, fmt.Sprintf("admin unblock digest '%s'", strings.ToUpper(digest)))
+	mustExecSQL(c, se, "select * from t where a = 1")
+
+	digest2 := parser.Digest("select * from t where b = 1")
+	mustExecSQL(c, se, fmt.Sprintf("admin block digest '%s', '%s'", digest, digest2))
+	_, err = se.Execute("select * from t where b = 2")
+	c.Assert(terror.ErrorEqual(err, domain.ErrQueryBlocked), IsTrue, Commentf("err %v", err))
+	mustExecSQL(c, se, "admin unblock all")
+	mustExecSQL(c, se, "select * from t where a = 1")
+	mustExecSQL(c, se, "select * from t where b = 1")
+	c.Assert(dom.DigestBlocklist().Update(se), IsNil)
+	c.Assert(dom.DigestBlocklist().Empty(), IsTrue)
+
+	_, err = se.Execute("admin block digest 'abc'")
+	c.Assert(terror.ErrorEqual(err, executor.ErrInvalidDigest), IsTrue, Commentf("err %v", err))
+
+	// The statements prepared before the digest is blocked are rejected when they are executed.
+	stmtID, _, _, err := se.PrepareStmt("select * from t where a = ?")
+	c.Assert(err, IsNil)
+	mustExecSQL(c, se, "prepare stmt from 'select * from t where a = ?'")
+	mustExecSQL(c, se, "set @a = 1")
+	mustExecSQL(c, se, fmt.Sprintf("admin block digest '%s'", digest))
+	_, err = se.ExecutePreparedStmt(stmtID, 1)
+	c.Assert(terror.ErrorEqual(err, domain.ErrQueryBlocked), IsTrue, Commentf("err %v", err))
+	_, err = se.Execute("execute stmt using @a")
+	c.Assert(terror.ErrorEqual(err, domain.ErrQueryBlocked), IsTrue, Commentf("err %v", err))
+	mustExecSQL(c, se, "admin unblock all")
+	rs, err := se.ExecutePreparedStmt(stmtID, 1)
+	c.Assert(err, IsNil)
+	_, err = GetRows(rs)
+	c.Assert(err, IsNil)
+	mustExecSQL(c, se, "execute stmt using @a")
+}
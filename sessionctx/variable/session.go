@@ -16,11 +16,14 @@ package variable
 import (
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/sqllog"
 )
 
 const (
@@ -86,6 +89,19 @@ type TransactionContext struct {
 	SchemaVersion int64
 	StartTS       uint64
 	TableDeltaMap map[int64]TableDelta
+	// Savepoints are the savepoints of the transaction in the order they are set.
+	Savepoints []SavepointRecord
+}
+
+// SavepointRecord is the state of the transaction when a savepoint is set,
+// it's restored by ROLLBACK TO SAVEPOINT.
+type SavepointRecord struct {
+	Name string
+	// Checkpoint is the checkpoint of the kv.Transaction.
+	Checkpoint    int
+	DirtyDB       interface{}
+	Binlog        interface{}
+	TableDeltaMap map[int64]TableDelta
 }
 
 // UpdateDeltaForTable updates the delta info for some table.
@@ -171,6 +187,12 @@ type SessionVars struct {
 	// AllowInSubqueryUnFolding can be set to true to fold in subquery
 	AllowInSubqueryUnFolding bool
 
+	// DisabledOptRules are the names of the logical optimization rules skipped by the optimizer.
+	DisabledOptRules map[string]struct{}
+
+	// AllowCartesianProduct can be set to false to forbid joining tables without equal conditions.
+	AllowCartesianProduct bool
+
 	// CurrInsertValues is used to record current ValuesExpr's values.
 	// See http://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_values
 	CurrInsertValues interface{}
@@ -181,6 +203,14 @@ type SessionVars struct {
 
 	SQLMode mysql.SQLMode
 
+	// GeneralLog makes the server write the statements received from the session to the general log.
+	GeneralLog bool
+
+	// sqlLogger stores the *sqllog.Logger writing the statements of the session, it's set by the other sessions
+	// so it's loaded atomically, and sqlLoggerMu serializes the setters.
+	sqlLogger   atomic.Value
+	sqlLoggerMu sync.Mutex
+
 	/* TiDB system variables */
 
 	// SkipConstraintCheck is true when importing data.
@@ -192,6 +222,15 @@ type SessionVars struct {
 	// BuildStatsConcurrencyVar is used to control statistics building concurrency.
 	BuildStatsConcurrencyVar int
 
+	// HashJoinConcurrency is the number of goroutines a hash join probes with.
+	HashJoinConcurrency int
+
+	// ProjectionConcurrency is the number of goroutines a projection evaluates its expressions with.
+	ProjectionConcurrency int
+
+	// TraceID is the ID provided by the application to correlate the statements with the upstream requests.
+	TraceID string
+
 	// IndexJoinBatchSize is the batch size of a index lookup join.
 	IndexJoinBatchSize int
 
@@ -215,6 +254,15 @@ type SessionVars struct {
 
 	// CBO indicates if we use new planner with cbo.
 	CBO bool
+
+	// EnableStatsFeedback indicates if we collect the actual row count of index scans to correct the statistics.
+	EnableStatsFeedback bool
+
+	// MemQuotaQuery is the memory quota in bytes of a statement, 0 means no quota.
+	MemQuotaQuery int64
+
+	// EnableSpill indicates if the hash join spills to disk when the memory quota is exceeded.
+	EnableSpill bool
 }
 
 // NewSessionVars creates a session vars object.
@@ -230,22 +278,23 @@ func NewSessionVars() *SessionVars {
 		Status:                     mysql.ServerStatusAutocommit,
 		StmtCtx:                    new(StatementContext),
 		AllowAggPushDown:           true,
-		BuildStatsConcurrencyVar:   DefBuildStatsConcurrency,
+		AllowCartesianProduct:      defaultAllowCartesianProduct,
+		BuildStatsConcurrencyVar:   defaultBuildStatsConcurrency,
+		HashJoinConcurrency:        defaultHashJoinConcurrency,
+		ProjectionConcurrency:      defaultProjectionConcurrency,
 		IndexJoinBatchSize:         DefIndexJoinBatchSize,
-		IndexLookupSize:            DefIndexLookupSize,
+		IndexLookupSize:            defaultIndexLookupSize,
 		IndexLookupConcurrency:     DefIndexLookupConcurrency,
 		IndexSerialScanConcurrency: DefIndexSerialScanConcurrency,
 		DistSQLScanConcurrency:     DefDistSQLScanConcurrency,
 		MaxRowCountForINLJ:         DefMaxRowCountForINLJ,
 		CBO:                        true,
+		EnableStatsFeedback:        DefEnableStatsFeedback,
+		MemQuotaQuery:              DefMemQuotaQuery,
+		EnableSpill:                DefEnableSpill,
 	}
 }
 
-const (
-	characterSetConnection = "character_set_connection"
-	collationConnection    = "collation_connection"
-)
-
 // GetCharsetInfo gets charset and collation for current context.
 // What character set should the server translate a statement to after receiving it?
 // For this, the server uses the character_set_connection and collation_connection system variables.
@@ -256,8 +305,8 @@ const (
 // have their own collation, which has a higher collation precedence.
 // See https://dev.mysql.com/doc/refman/5.7/en/charset-connection.html
 func (s *SessionVars) GetCharsetInfo() (charset, collation string) {
-	charset = s.Systems[characterSetConnection]
-	collation = s.Systems[collationConnection]
+	charset = s.Systems[CharacterSetConnection]
+	collation = s.Systems[CollationConnection]
 	return
 }
 
@@ -299,6 +348,22 @@ func (s *SessionVars) GetNextPreparedStmtID() uint32 {
 	return s.preparedStmtID
 }
 
+// SQLLogger returns the logger writing the statements of the session, or nil if it's disabled.
+func (s *SessionVars) SQLLogger() *sqllog.Logger {
+	l, _ := s.sqlLogger.Load().(*sqllog.Logger)
+	return l
+}
+
+// SetSQLLogger sets the logger writing the statements of the session, nil disables it.
+// The former logger is returned, the caller should close it.
+func (s *SessionVars) SetSQLLogger(l *sqllog.Logger) *sqllog.Logger {
+	s.sqlLoggerMu.Lock()
+	defer s.sqlLoggerMu.Unlock()
+	old := s.SQLLogger()
+	s.sqlLogger.Store(l)
+	return old
+}
+
 // GetTimeZone returns the value of time_zone session variable.
 func (s *SessionVars) GetTimeZone() *time.Location {
 	loc := s.TimeZone
@@ -316,6 +381,7 @@ const (
 	MaxAllowedPacket    = "max_allowed_packet"
 	TimeZone            = "time_zone"
 	TxnIsolation        = "tx_isolation"
+	GeneralLog          = "general_log"
 )
 
 // TableDelta stands for the changed count for one table.
@@ -336,6 +402,12 @@ type StatementContext struct {
 	TruncateAsWarning    bool
 	OverflowAsWarning    bool
 	InShowWarning        bool
+	// BadNullAsWarning is set by INSERT IGNORE, a NULL value or a missing value without default
+	// of a NOT NULL column is replaced by the zero value with a warning.
+	BadNullAsWarning bool
+	// DividedByZeroAsWarning is set by INSERT IGNORE and UPDATE IGNORE, a division by zero is a warning
+	// even if ERROR_FOR_DIVISION_BY_ZERO is set in strict mode.
+	DividedByZeroAsWarning bool
 
 	// mu struct holds variables that change during execution.
 	mu struct {
@@ -348,6 +420,10 @@ type StatementContext struct {
 	// Copied from SessionVars.TimeZone.
 	TimeZone *time.Location
 	Priority mysql.PriorityEnum
+	// SnapshotTS is the timestamp of the AS OF TIMESTAMP clause, the statement reads the historical data at it.
+	SnapshotTS uint64
+	// MemTracker tracks the memory consumed by the executors of the statement.
+	MemTracker *memory.Tracker
 }
 
 // AddAffectedRows adds affected rows.
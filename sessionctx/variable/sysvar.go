@@ -59,17 +59,25 @@ const (
 	CodeUnknownStatusVar terror.ErrCode = 1
 	CodeUnknownSystemVar terror.ErrCode = 1193
 	CodeIncorrectScope   terror.ErrCode = 1238
+	CodeWrongValueForVar terror.ErrCode = 1231
+	CodeUnknownCharset   terror.ErrCode = 1115
+	CodeUnknownCollation terror.ErrCode = 1273
+	CodeCollationCharset terror.ErrCode = 1253
 	CodeUnknownTimeZone  terror.ErrCode = 1298
 	CodeReadOnly         terror.ErrCode = 1621
 )
 
 // Variable errors
 var (
-	UnknownStatusVar   = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
-	UnknownSystemVar   = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable '%s'")
-	ErrIncorrectScope  = terror.ClassVariable.New(CodeIncorrectScope, "Incorrect variable scope")
-	ErrUnknownTimeZone = terror.ClassVariable.New(CodeUnknownTimeZone, "unknown or incorrect time zone: %s")
-	ErrReadOnly        = terror.ClassVariable.New(CodeReadOnly, "variable is read only")
+	UnknownStatusVar    = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
+	UnknownSystemVar    = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable '%s'")
+	ErrIncorrectScope   = terror.ClassVariable.New(CodeIncorrectScope, "Incorrect variable scope")
+	ErrWrongValueForVar = terror.ClassVariable.New(CodeWrongValueForVar, mysql.MySQLErrName[mysql.ErrWrongValueForVar])
+	ErrUnknownTimeZone  = terror.ClassVariable.New(CodeUnknownTimeZone, "unknown or incorrect time zone: %s")
+	ErrUnknownCharset   = terror.ClassVariable.New(CodeUnknownCharset, mysql.MySQLErrName[mysql.ErrUnknownCharacterSet])
+	ErrUnknownCollation = terror.ClassVariable.New(CodeUnknownCollation, mysql.MySQLErrName[mysql.ErrUnknownCollation])
+	ErrCollationCharset = terror.ClassVariable.New(CodeCollationCharset, mysql.MySQLErrName[mysql.ErrCollationCharsetMismatch])
+	ErrReadOnly         = terror.ClassVariable.New(CodeReadOnly, "variable is read only")
 )
 
 func init() {
@@ -82,7 +90,11 @@ func init() {
 	mySQLErrCodes := map[terror.ErrCode]uint16{
 		CodeUnknownSystemVar: mysql.ErrUnknownSystemVariable,
 		CodeIncorrectScope:   mysql.ErrIncorrectGlobalLocalVar,
+		CodeWrongValueForVar: mysql.ErrWrongValueForVar,
 		CodeUnknownTimeZone:  mysql.ErrUnknownTimeZone,
+		CodeUnknownCharset:   mysql.ErrUnknownCharacterSet,
+		CodeUnknownCollation: mysql.ErrUnknownCollation,
+		CodeCollationCharset: mysql.ErrCollationCharsetMismatch,
 		CodeReadOnly:         mysql.ErrVariableIsReadonly,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassVariable] = mySQLErrCodes
@@ -180,7 +192,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "innodb_log_write_ahead_size", ""},
 	{ScopeNone, "innodb_log_group_home_dir", "./"},
 	{ScopeNone, "performance_schema_events_statements_history_size", "10"},
-	{ScopeGlobal, "general_log", "OFF"},
+	{ScopeGlobal | ScopeSession, GeneralLog, "OFF"},
 	{ScopeGlobal, "validate_password_dictionary_file", ""},
 	{ScopeGlobal, "binlog_order_commits", "ON"},
 	{ScopeGlobal, "master_verify_checksum", "OFF"},
@@ -601,7 +613,12 @@ var defaultSysVars = []*SysVar{
 	{ScopeSession, TiDBSkipConstraintCheck, "0"},
 	{ScopeSession, TiDBOptAggPushDown, boolToIntStr(DefOptAggPushDown)},
 	{ScopeSession, TiDBOptInSubqUnFolding, boolToIntStr(DefOptInSubqUnfolding)},
+	{ScopeSession, TiDBOptDisableRules, ""},
+	{ScopeSession, TiDBAllowCartesianProduct, boolToIntStr(DefAllowCartesianProduct)},
 	{ScopeSession, TiDBBuildStatsConcurrency, strconv.Itoa(DefBuildStatsConcurrency)},
+	{ScopeSession, TiDBHashJoinConcurrency, strconv.Itoa(DefHashJoinConcurrency)},
+	{ScopeSession, TiDBProjectionConcurrency, strconv.Itoa(DefProjectionConcurrency)},
+	{ScopeSession, TiDBTraceID, DefTraceID},
 	{ScopeGlobal | ScopeSession, TiDBDistSQLScanConcurrency, strconv.Itoa(DefDistSQLScanConcurrency)},
 	{ScopeGlobal | ScopeSession, TiDBIndexJoinBatchSize, strconv.Itoa(DefIndexJoinBatchSize)},
 	{ScopeGlobal | ScopeSession, TiDBIndexLookupSize, strconv.Itoa(DefIndexLookupSize)},
@@ -610,20 +627,35 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, TiDBMaxRowCountForINLJ, strconv.Itoa(DefMaxRowCountForINLJ)},
 	{ScopeGlobal | ScopeSession, TiDBCBO, "ON"},
 	{ScopeGlobal | ScopeSession, TiDBSkipUTF8Check, boolToIntStr(DefSkipUTF8Check)},
+	{ScopeGlobal | ScopeSession, TiDBEnableStatsFeedback, boolToIntStr(DefEnableStatsFeedback)},
 	{ScopeSession, TiDBBatchInsert, boolToIntStr(DefBatchInsert)},
 	{ScopeSession, TiDBCurrentTS, strconv.Itoa(DefCurretTS)},
+	{ScopeGlobal, TiDBDDLReorgWorkerCount, strconv.Itoa(DefTiDBDDLReorgWorkerCount)},
+	{ScopeGlobal, TiDBDDLReorgBatchSize, strconv.Itoa(DefTiDBDDLReorgBatchSize)},
+	{ScopeGlobal, TiDBDDLReorgPriority, DefTiDBDDLReorgPriority},
+	{ScopeSession, TiDBDefaultNullOrder, DefDefaultNullOrder},
+	{ScopeGlobal | ScopeSession, TiDBMemQuotaQuery, strconv.Itoa(DefMemQuotaQuery)},
+	{ScopeGlobal | ScopeSession, TiDBEnableSpill, boolToIntStr(DefEnableSpill)},
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
 var SetNamesVariables = []string{
-	"character_set_client",
-	"character_set_connection",
-	"character_set_results",
+	CharacterSetClient,
+	CharacterSetConnection,
+	CharacterSetResults,
 }
 
 const (
+	// CharacterSetClient is the name for character_set_client system variable.
+	CharacterSetClient = "character_set_client"
+	// CharacterSetConnection is the name for character_set_connection system variable.
+	CharacterSetConnection = "character_set_connection"
+	// CharacterSetServer is the name for character_set_server system variable.
+	CharacterSetServer = "character_set_server"
 	// CollationConnection is the name for collation_connection system variable.
 	CollationConnection = "collation_connection"
+	// CollationServer is the name for collation_server system variable.
+	CollationServer = "collation_server"
 	// CharsetDatabase is the name for character_set_database system variable.
 	CharsetDatabase = "character_set_database"
 	// CollationDatabase is the name for collation_database system variable.
@@ -634,6 +666,9 @@ const (
 type GlobalVarAccessor interface {
 	// GetGlobalSysVar gets the global system variable value for name.
 	GetGlobalSysVar(name string) (string, error)
+	// GetAllSysVars gets all the saved global system variable values, the variables which are not saved yet
+	// are not in the returned map.
+	GetAllSysVars() (map[string]string, error)
 	// SetGlobalSysVar sets the global system variable name to value.
 	SetGlobalSysVar(name string, value string) error
 }
@@ -36,3 +36,13 @@ func (*testSysVarSuite) TestSysVar(c *C) {
 	f = GetSysVar("wrong-var-name")
 	c.Assert(f, IsNil)
 }
+
+func (*testSysVarSuite) TestSetDefaultIndexLookupSize(c *C) {
+	defer SetDefaultIndexLookupSize(DefIndexLookupSize)
+
+	c.Assert(NewSessionVars().IndexLookupSize, Equals, DefIndexLookupSize)
+	SetDefaultIndexLookupSize(100)
+	c.Assert(DefaultIndexLookupSize(), Equals, 100)
+	c.Assert(NewSessionVars().IndexLookupSize, Equals, 100)
+	c.Assert(GetSysVar(TiDBIndexLookupSize).Value, Equals, "100")
+}
@@ -13,6 +13,8 @@
 
 package variable
 
+import "strconv"
+
 /*
 	Steps to add a new TiDB specific system variable:
 
@@ -44,10 +46,32 @@ const (
 	// tidb_opt_insubquery_unfold is used to enable/disable the optimizer rule of in subquery unfold.
 	TiDBOptInSubqUnFolding = "tidb_opt_insubquery_unfold"
 
+	// tidb_opt_disable_rules is a comma-separated list of the logical optimization rules the optimizer skips,
+	// it's used to find out which rule leads to a bad plan. The rules are listed by "show opt_rules".
+	TiDBOptDisableRules = "tidb_opt_disable_rules"
+
+	// tidb_allow_cartesian_product is used to allow/forbid the queries which join tables without equal conditions.
+	// The default value is set by the -cross-join flag of tidb-server.
+	TiDBAllowCartesianProduct = "tidb_allow_cartesian_product"
+
 	// tidb_build_stats_concurrency is used to speed up the ANALYZE statement, when a table has multiple indices,
 	// those indices can be scanned concurrently, with the cost of higher system performance impact.
 	TiDBBuildStatsConcurrency = "tidb_build_stats_concurrency"
 
+	// tidb_hash_join_concurrency is the number of goroutines a hash join uses to probe the hash table.
+	// The default value is set by the -hash-join-concurrency flag of tidb-server, which defaults to the
+	// general join concurrency set by the -join-concurrency flag. A value that isn't positive resets it to the default.
+	TiDBHashJoinConcurrency = "tidb_hash_join_concurrency"
+
+	// tidb_projection_concurrency is the number of goroutines a projection evaluates its expressions with.
+	// The default value is set by the -projection-concurrency flag of tidb-server, "1" means evaluating
+	// the expressions in the goroutine reading the rows. A value that isn't positive resets it to the default.
+	TiDBProjectionConcurrency = "tidb_projection_concurrency"
+
+	// tidb_trace_id is an ID provided by the application to correlate the statements with the upstream requests,
+	// it's written to the slow query log and the general log with the statements of the session.
+	TiDBTraceID = "tidb_trace_id"
+
 	// TiDBCurrentTS is used to get the current transaction timestamp.
 	// It is read-only.
 	TiDBCurrentTS = "tidb_current_ts"
@@ -99,6 +123,48 @@ const (
 
 	// tidb_cbo uses new planner with cost based optimizer.
 	TiDBCBO = "tidb_cbo"
+
+	// tidb_enable_stats_feedback is used to collect the actual row count of index scans and use it to
+	// correct the index histograms when the estimation is inaccurate.
+	TiDBEnableStatsFeedback = "tidb_enable_stats_feedback"
+
+	// tidb_default_null_order is the order of NULLs for the order by items without NULLS FIRST or NULLS LAST.
+	// "low" treats NULL as the smallest value like MySQL, "high" treats NULL as the largest value like PostgreSQL,
+	// "first" and "last" put NULLs first or last regardless of the sort direction.
+	TiDBDefaultNullOrder = "tidb_default_null_order"
+
+	// tidb_mem_quota_query is the memory quota in bytes of a statement, the statement fails when the memory
+	// tracked by its executors exceeds it, unless the executor is able to spill the data to disk. 0 means no quota.
+	TiDBMemQuotaQuery = "tidb_mem_quota_query"
+
+	// tidb_enable_spill makes the hash join partition its build side to disk instead of failing
+	// when the memory quota is exceeded. The partitions are joined one by one, which is slower.
+	TiDBEnableSpill = "tidb_enable_spill"
+
+	/* Global only */
+
+	// tidb_ddl_reorg_worker_cnt is the number of the concurrent tasks backfilling an index, a task backfills
+	// 'tidb_ddl_reorg_batch_size' rows in a transaction. The DDL owner reads it between the batches, so changing it
+	// takes effect on the running ADD INDEX jobs. Lower value reduces the impact on the foreground traffic.
+	TiDBDDLReorgWorkerCount = "tidb_ddl_reorg_worker_cnt"
+
+	// tidb_ddl_reorg_batch_size is the number of rows backfilled by an ADD INDEX task in a transaction.
+	TiDBDDLReorgBatchSize = "tidb_ddl_reorg_batch_size"
+
+	// tidb_ddl_reorg_priority is the priority of the requests sent by the ADD INDEX backfill,
+	// it can be "PRIORITY_LOW", "PRIORITY_NORMAL" or "PRIORITY_HIGH".
+	TiDBDDLReorgPriority = "tidb_ddl_reorg_priority"
+)
+
+// MaxTraceIDLength is the max length of tidb_trace_id.
+const MaxTraceIDLength = 128
+
+// Values of tidb_default_null_order.
+const (
+	NullOrderLow   = "low"
+	NullOrderHigh  = "high"
+	NullOrderFirst = "first"
+	NullOrderLast  = "last"
 )
 
 // Default TiDB system variable values.
@@ -109,10 +175,95 @@ const (
 	DefIndexLookupSize            = 20000
 	DefDistSQLScanConcurrency     = 10
 	DefBuildStatsConcurrency      = 4
+	DefHashJoinConcurrency        = 5
+	DefProjectionConcurrency      = 1
+	DefTraceID                    = ""
 	DefMaxRowCountForINLJ         = 128
 	DefSkipUTF8Check              = false
 	DefOptAggPushDown             = true
 	DefOptInSubqUnfolding         = false
+	DefAllowCartesianProduct      = true
 	DefBatchInsert                = false
+	DefEnableStatsFeedback        = false
 	DefCurretTS                   = 0
+	DefDefaultNullOrder           = NullOrderLow
+	DefMemQuotaQuery              = 0
+	DefEnableSpill                = false
+	DefTiDBDDLReorgWorkerCount    = 16
+	DefTiDBDDLReorgBatchSize      = 128
+	DefTiDBDDLReorgPriority       = "PRIORITY_NORMAL"
 )
+
+// The ranges of the ADD INDEX backfill variables.
+const (
+	MinDDLReorgWorkerCount = 1
+	MaxDDLReorgWorkerCount = 128
+	MinDDLReorgBatchSize   = 32
+	MaxDDLReorgBatchSize   = 10240
+)
+
+// defaultIndexLookupSize is the default value of tidb_index_lookup_size.
+var defaultIndexLookupSize = DefIndexLookupSize
+
+// SetDefaultIndexLookupSize sets the default value of tidb_index_lookup_size.
+// The value is used by new sessions and saved as the global value when the store is bootstrapped,
+// so it should be called before any session is created.
+func SetDefaultIndexLookupSize(size int) {
+	defaultIndexLookupSize = size
+	SysVars[TiDBIndexLookupSize].Value = strconv.Itoa(size)
+}
+
+// DefaultIndexLookupSize returns the default value of tidb_index_lookup_size.
+func DefaultIndexLookupSize() int {
+	return defaultIndexLookupSize
+}
+
+// defaultAllowCartesianProduct is the default value of tidb_allow_cartesian_product.
+var defaultAllowCartesianProduct = DefAllowCartesianProduct
+
+// SetDefaultAllowCartesianProduct sets the default value of tidb_allow_cartesian_product.
+// It should be called before any session is created.
+func SetDefaultAllowCartesianProduct(allow bool) {
+	defaultAllowCartesianProduct = allow
+	SysVars[TiDBAllowCartesianProduct].Value = boolToIntStr(allow)
+}
+
+// defaultBuildStatsConcurrency is the default value of tidb_build_stats_concurrency.
+var defaultBuildStatsConcurrency = DefBuildStatsConcurrency
+
+// SetDefaultBuildStatsConcurrency sets the default value of tidb_build_stats_concurrency.
+// It should be called before any session is created.
+func SetDefaultBuildStatsConcurrency(concurrency int) {
+	defaultBuildStatsConcurrency = concurrency
+	SysVars[TiDBBuildStatsConcurrency].Value = strconv.Itoa(concurrency)
+}
+
+// defaultHashJoinConcurrency is the default value of tidb_hash_join_concurrency.
+var defaultHashJoinConcurrency = DefHashJoinConcurrency
+
+// SetDefaultHashJoinConcurrency sets the default value of tidb_hash_join_concurrency.
+// It should be called before any session is created.
+func SetDefaultHashJoinConcurrency(concurrency int) {
+	defaultHashJoinConcurrency = concurrency
+	SysVars[TiDBHashJoinConcurrency].Value = strconv.Itoa(concurrency)
+}
+
+// DefaultHashJoinConcurrency returns the default value of tidb_hash_join_concurrency.
+func DefaultHashJoinConcurrency() int {
+	return defaultHashJoinConcurrency
+}
+
+// defaultProjectionConcurrency is the default value of tidb_projection_concurrency.
+var defaultProjectionConcurrency = DefProjectionConcurrency
+
+// SetDefaultProjectionConcurrency sets the default value of tidb_projection_concurrency.
+// It should be called before any session is created.
+func SetDefaultProjectionConcurrency(concurrency int) {
+	defaultProjectionConcurrency = concurrency
+	SysVars[TiDBProjectionConcurrency].Value = strconv.Itoa(concurrency)
+}
+
+// DefaultProjectionConcurrency returns the default value of tidb_projection_concurrency.
+func DefaultProjectionConcurrency() int {
+	return defaultProjectionConcurrency
+}
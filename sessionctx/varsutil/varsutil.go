@@ -20,8 +20,10 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -124,20 +126,46 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		if isAutocommit {
 			vars.SetStatusFlag(mysql.ServerStatusInTrans, false)
 		}
+	case variable.GeneralLog:
+		vars.GeneralLog = tidbOptOn(sVal)
 	case variable.TiDBSkipConstraintCheck:
 		vars.SkipConstraintCheck = tidbOptOn(sVal)
 	case variable.TiDBSkipUTF8Check:
 		vars.SkipUTF8Check = tidbOptOn(sVal)
+	case variable.TiDBEnableStatsFeedback:
+		vars.EnableStatsFeedback = tidbOptOn(sVal)
+	case variable.TiDBEnableSpill:
+		vars.EnableSpill = tidbOptOn(sVal)
+	case variable.TiDBMemQuotaQuery:
+		var quota int64
+		quota, err = strconv.ParseInt(sVal, 10, 64)
+		if err != nil || quota < 0 {
+			return variable.ErrWrongValueForVar.GenByArgs(name, sVal)
+		}
+		vars.MemQuotaQuery = quota
 	case variable.TiDBOptAggPushDown:
 		vars.AllowAggPushDown = tidbOptOn(sVal)
 	case variable.TiDBOptInSubqUnFolding:
 		vars.AllowInSubqueryUnFolding = tidbOptOn(sVal)
+	case variable.TiDBOptDisableRules:
+		vars.DisabledOptRules = ParseOptRuleList(sVal)
+	case variable.TiDBAllowCartesianProduct:
+		vars.AllowCartesianProduct = tidbOptOn(sVal)
+	case variable.TiDBTraceID:
+		if err = checkTraceID(sVal); err != nil {
+			return errors.Trace(err)
+		}
+		vars.TraceID = sVal
+	case variable.TiDBHashJoinConcurrency:
+		vars.HashJoinConcurrency = tidbOptPositiveInt(sVal, variable.DefaultHashJoinConcurrency())
+	case variable.TiDBProjectionConcurrency:
+		vars.ProjectionConcurrency = tidbOptPositiveInt(sVal, variable.DefaultProjectionConcurrency())
 	case variable.TiDBIndexLookupConcurrency:
 		vars.IndexLookupConcurrency = tidbOptPositiveInt(sVal, variable.DefIndexLookupConcurrency)
 	case variable.TiDBIndexJoinBatchSize:
 		vars.IndexJoinBatchSize = tidbOptPositiveInt(sVal, variable.DefIndexJoinBatchSize)
 	case variable.TiDBIndexLookupSize:
-		vars.IndexLookupSize = tidbOptPositiveInt(sVal, variable.DefIndexLookupSize)
+		vars.IndexLookupSize = tidbOptPositiveInt(sVal, variable.DefaultIndexLookupSize())
 	case variable.TiDBDistSQLScanConcurrency:
 		vars.DistSQLScanConcurrency = tidbOptPositiveInt(sVal, variable.DefDistSQLScanConcurrency)
 	case variable.TiDBIndexSerialScanConcurrency:
@@ -150,11 +178,111 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.CBO = tidbOptOn(sVal)
 	case variable.TiDBCurrentTS:
 		return variable.ErrReadOnly
+	case variable.TiDBDefaultNullOrder:
+		sVal = strings.ToLower(sVal)
+		switch sVal {
+		case variable.NullOrderLow, variable.NullOrderHigh, variable.NullOrderFirst, variable.NullOrderLast:
+		default:
+			return variable.ErrWrongValueForVar.GenByArgs(name, sVal)
+		}
+	case variable.CharacterSetClient, variable.CharacterSetResults:
+		if sVal, err = checkCharset(sVal); err != nil {
+			return errors.Trace(err)
+		}
+	case variable.CharacterSetConnection, variable.CharsetDatabase, variable.CharacterSetServer:
+		// Like MySQL, the collation variable is changed to the default collation of the charset.
+		if sVal, err = checkCharset(sVal); err != nil {
+			return errors.Trace(err)
+		}
+		vars.Systems[charsetToCollationVars[name]], err = charset.GetDefaultCollation(sVal)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	case variable.CollationConnection, variable.CollationDatabase, variable.CollationServer:
+		// Like MySQL, the charset variable is changed to the charset of the collation.
+		co, err := charset.GetCollationByName(sVal)
+		if err != nil {
+			return variable.ErrUnknownCollation.GenByArgs(sVal)
+		}
+		sVal = co.Name
+		vars.Systems[collationToCharsetVars[name]] = co.CharsetName
 	}
 	vars.Systems[name] = sVal
 	return nil
 }
 
+var charsetToCollationVars = map[string]string{
+	variable.CharacterSetConnection: variable.CollationConnection,
+	variable.CharsetDatabase:        variable.CollationDatabase,
+	variable.CharacterSetServer:     variable.CollationServer,
+}
+
+var collationToCharsetVars = map[string]string{
+	variable.CollationConnection: variable.CharacterSetConnection,
+	variable.CollationDatabase:   variable.CharsetDatabase,
+	variable.CollationServer:     variable.CharacterSetServer,
+}
+
+// checkCharset returns the lower case name of the charset, or an error if the charset isn't supported.
+func checkCharset(cs string) (string, error) {
+	cs = strings.ToLower(cs)
+	if _, _, err := charset.GetCharsetInfo(cs); err != nil {
+		return "", variable.ErrUnknownCharset.GenByArgs(cs)
+	}
+	return cs, nil
+}
+
+// SetNames implements SET NAMES cs [COLLATE co]. It sets character_set_client, character_set_connection
+// and character_set_results to cs, and collation_connection to co or the default collation of cs.
+func SetNames(vars *variable.SessionVars, cs, co string) error {
+	cs, err := checkCharset(cs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if co == "" {
+		co, err = charset.GetDefaultCollation(cs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	} else {
+		collation, err := charset.GetCollationByName(co)
+		if err != nil {
+			return variable.ErrUnknownCollation.GenByArgs(co)
+		}
+		if collation.CharsetName != cs {
+			return variable.ErrCollationCharset.GenByArgs(collation.Name, cs)
+		}
+		co = collation.Name
+	}
+	for _, v := range variable.SetNamesVariables {
+		vars.Systems[v] = cs
+	}
+	vars.Systems[variable.CollationConnection] = co
+	return nil
+}
+
+// SetCharacterSet implements SET CHARACTER SET cs. It sets character_set_client and character_set_results to cs,
+// and character_set_connection and collation_connection to the ones of the current database.
+func SetCharacterSet(vars *variable.SessionVars, cs string) error {
+	cs, err := checkCharset(cs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dbCharset, err := GetSessionSystemVar(vars, variable.CharsetDatabase)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dbCollation, err := GetSessionSystemVar(vars, variable.CollationDatabase)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	vars.Systems[variable.CharacterSetClient] = cs
+	vars.Systems[variable.CharacterSetResults] = cs
+	vars.Systems[variable.CharacterSetConnection] = dbCharset
+	vars.Systems[variable.CollationConnection] = dbCollation
+	return nil
+}
+
 // tidbOptOn could be used for all tidb session variable options, we use "ON"/1 to turn on those options.
 func tidbOptOn(opt string) bool {
 	return strings.EqualFold(opt, "ON") || opt == "1"
@@ -168,6 +296,70 @@ func tidbOptPositiveInt(opt string, defaultVal int) int {
 	return val
 }
 
+// ValidateGlobalSystemVar checks the value of a SET GLOBAL statement, and returns the normalized value to be saved.
+func ValidateGlobalSystemVar(name string, value string) (string, error) {
+	switch strings.ToLower(name) {
+	case variable.TiDBDDLReorgWorkerCount:
+		return checkIntRange(variable.TiDBDDLReorgWorkerCount, value,
+			variable.MinDDLReorgWorkerCount, variable.MaxDDLReorgWorkerCount)
+	case variable.TiDBDDLReorgBatchSize:
+		return checkIntRange(variable.TiDBDDLReorgBatchSize, value,
+			variable.MinDDLReorgBatchSize, variable.MaxDDLReorgBatchSize)
+	case variable.TiDBDDLReorgPriority:
+		if _, err := ParseDDLReorgPriority(value); err != nil {
+			return "", errors.Trace(err)
+		}
+		return strings.ToUpper(value), nil
+	}
+	return value, nil
+}
+
+func checkIntRange(name string, value string, min, max int) (string, error) {
+	val, err := strconv.Atoi(value)
+	if err != nil || val < min || val > max {
+		return "", variable.ErrWrongValueForVar.GenByArgs(name, value)
+	}
+	return strconv.Itoa(val), nil
+}
+
+// ParseDDLReorgPriority parses the value of tidb_ddl_reorg_priority to the kv priority.
+func ParseDDLReorgPriority(s string) (int, error) {
+	switch strings.ToUpper(s) {
+	case "PRIORITY_LOW":
+		return kv.PriorityLow, nil
+	case "PRIORITY_NORMAL":
+		return kv.PriorityNormal, nil
+	case "PRIORITY_HIGH":
+		return kv.PriorityHigh, nil
+	}
+	return kv.PriorityNormal, variable.ErrWrongValueForVar.GenByArgs(variable.TiDBDDLReorgPriority, s)
+}
+
+// ParseOptRuleList parses the comma-separated rule names of tidb_opt_disable_rules, the names are case-insensitive.
+func ParseOptRuleList(s string) map[string]struct{} {
+	rules := make(map[string]struct{})
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			rules[name] = struct{}{}
+		}
+	}
+	return rules
+}
+
+// checkTraceID checks the trace ID doesn't break the log lines, it can't be too long or have spaces or control characters.
+func checkTraceID(s string) error {
+	if len(s) > variable.MaxTraceIDLength {
+		return variable.ErrWrongValueForVar.GenByArgs(variable.TiDBTraceID, s)
+	}
+	for _, r := range s {
+		if r <= ' ' || r == 0x7f {
+			return variable.ErrWrongValueForVar.GenByArgs(variable.TiDBTraceID, s)
+		}
+	}
+	return nil
+}
+
 func parseTimeZone(s string) (*time.Location, error) {
 	if s == "SYSTEM" {
 		// TODO: Support global time_zone variable, it should be set to global time_zone value.
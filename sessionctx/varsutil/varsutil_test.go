@@ -18,6 +18,7 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
@@ -152,10 +153,123 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	SetSessionSystemVar(v, variable.TiDBBatchInsert, types.NewStringDatum("1"))
 	c.Assert(v.BatchInsert, IsTrue)
 
+	// Test case for tidb_enable_stats_feedback.
+	c.Assert(v.EnableStatsFeedback, IsFalse)
+	SetSessionSystemVar(v, variable.TiDBEnableStatsFeedback, types.NewStringDatum("1"))
+	c.Assert(v.EnableStatsFeedback, IsTrue)
+
+	// Test case for tidb_mem_quota_query and tidb_enable_spill.
+	c.Assert(v.MemQuotaQuery, Equals, int64(0))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaQuery, types.NewStringDatum("1073741824"))
+	c.Assert(v.MemQuotaQuery, Equals, int64(1073741824))
+	err = SetSessionSystemVar(v, variable.TiDBMemQuotaQuery, types.NewStringDatum("-1"))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+	c.Assert(v.MemQuotaQuery, Equals, int64(1073741824))
+	c.Assert(v.EnableSpill, IsFalse)
+	SetSessionSystemVar(v, variable.TiDBEnableSpill, types.NewStringDatum("1"))
+	c.Assert(v.EnableSpill, IsTrue)
+
+	// Test case for tidb_allow_cartesian_product.
+	c.Assert(v.AllowCartesianProduct, IsTrue)
+	SetSessionSystemVar(v, variable.TiDBAllowCartesianProduct, types.NewStringDatum("0"))
+	c.Assert(v.AllowCartesianProduct, IsFalse)
+
+	// Test case for tidb_opt_disable_rules.
+	c.Assert(v.DisabledOptRules, HasLen, 0)
+	SetSessionSystemVar(v, variable.TiDBOptDisableRules, types.NewStringDatum(" Predicate_Push_Down,,column_pruning "))
+	c.Assert(v.DisabledOptRules, DeepEquals, map[string]struct{}{"predicate_push_down": {}, "column_pruning": {}})
+	SetSessionSystemVar(v, variable.TiDBOptDisableRules, types.NewStringDatum(""))
+	c.Assert(v.DisabledOptRules, HasLen, 0)
+
+	// Test case for general_log.
+	c.Assert(v.GeneralLog, IsFalse)
+	SetSessionSystemVar(v, variable.GeneralLog, types.NewStringDatum("ON"))
+	c.Assert(v.GeneralLog, IsTrue)
+
+	// Test case for tidb_trace_id.
+	err = SetSessionSystemVar(v, variable.TiDBTraceID, types.NewStringDatum("00-4bf92f3577b34da6-01"))
+	c.Assert(err, IsNil)
+	c.Assert(v.TraceID, Equals, "00-4bf92f3577b34da6-01")
+	err = SetSessionSystemVar(v, variable.TiDBTraceID, types.NewStringDatum("a\tb"))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	c.Assert(v.TraceID, Equals, "00-4bf92f3577b34da6-01")
+
+	// Test case for tidb_hash_join_concurrency.
+	c.Assert(v.HashJoinConcurrency, Equals, 5)
+	SetSessionSystemVar(v, variable.TiDBHashJoinConcurrency, types.NewStringDatum("8"))
+	c.Assert(v.HashJoinConcurrency, Equals, 8)
+	SetSessionSystemVar(v, variable.TiDBHashJoinConcurrency, types.NewStringDatum("0"))
+	c.Assert(v.HashJoinConcurrency, Equals, 5)
+	// An invalid value falls back to the configured default.
+	variable.SetDefaultHashJoinConcurrency(5)
+	SetSessionSystemVar(v, variable.TiDBHashJoinConcurrency, types.NewStringDatum("-1"))
+	c.Assert(v.HashJoinConcurrency, Equals, 5)
+	variable.SetDefaultHashJoinConcurrency(variable.DefHashJoinConcurrency)
+
+	// Test case for tidb_projection_concurrency.
+	c.Assert(v.ProjectionConcurrency, Equals, 1)
+	SetSessionSystemVar(v, variable.TiDBProjectionConcurrency, types.NewStringDatum("4"))
+	c.Assert(v.ProjectionConcurrency, Equals, 4)
+	SetSessionSystemVar(v, variable.TiDBProjectionConcurrency, types.NewStringDatum("0"))
+	c.Assert(v.ProjectionConcurrency, Equals, 1)
+	variable.SetDefaultProjectionConcurrency(2)
+	SetSessionSystemVar(v, variable.TiDBProjectionConcurrency, types.NewStringDatum("0"))
+	c.Assert(v.ProjectionConcurrency, Equals, 2)
+	variable.SetDefaultProjectionConcurrency(variable.DefProjectionConcurrency)
+
 	//Test case for tidb_max_row_count_for_inlj.
 	c.Assert(v.MaxRowCountForINLJ, Equals, 128)
 	SetSessionSystemVar(v, variable.TiDBMaxRowCountForINLJ, types.NewStringDatum("127"))
 	c.Assert(v.MaxRowCountForINLJ, Equals, 127)
+
+	// Test case for tidb_default_null_order.
+	err = SetSessionSystemVar(v, variable.TiDBDefaultNullOrder, types.NewStringDatum("HIGH"))
+	c.Assert(err, IsNil)
+	val, err = GetSessionSystemVar(v, variable.TiDBDefaultNullOrder)
+	c.Assert(err, IsNil)
+	c.Assert(val, Equals, variable.NullOrderHigh)
+	err = SetSessionSystemVar(v, variable.TiDBDefaultNullOrder, types.NewStringDatum("middle"))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	val, err = GetSessionSystemVar(v, variable.TiDBDefaultNullOrder)
+	c.Assert(err, IsNil)
+	c.Assert(val, Equals, variable.NullOrderHigh)
+}
+
+func (s *testVarsutilSuite) TestValidateGlobalSystemVar(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		name  string
+		value string
+		ok    bool
+		res   string
+	}{
+		{variable.TiDBDDLReorgWorkerCount, "1", true, "1"},
+		{variable.TiDBDDLReorgWorkerCount, "128", true, "128"},
+		{variable.TiDBDDLReorgWorkerCount, "0", false, ""},
+		{variable.TiDBDDLReorgWorkerCount, "129", false, ""},
+		{variable.TiDBDDLReorgWorkerCount, "a", false, ""},
+		{variable.TiDBDDLReorgBatchSize, "32", true, "32"},
+		{variable.TiDBDDLReorgBatchSize, "10240", true, "10240"},
+		{variable.TiDBDDLReorgBatchSize, "31", false, ""},
+		{variable.TiDBDDLReorgBatchSize, "10241", false, ""},
+		{variable.TiDBDDLReorgPriority, "priority_low", true, "PRIORITY_LOW"},
+		{variable.TiDBDDLReorgPriority, "PRIORITY_HIGH", true, "PRIORITY_HIGH"},
+		{variable.TiDBDDLReorgPriority, "low", false, ""},
+		{variable.TiDBIndexLookupSize, "abc", true, "abc"},
+	}
+	for _, t := range tests {
+		res, err := ValidateGlobalSystemVar(t.name, t.value)
+		if !t.ok {
+			c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("%s = %s", t.name, t.value))
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(res, Equals, t.res)
+	}
+
+	p, err := ParseDDLReorgPriority("Priority_Low")
+	c.Assert(err, IsNil)
+	c.Assert(p, Equals, kv.PriorityLow)
 }
 
 type mockGlobalAccessor struct {
@@ -176,6 +290,10 @@ func (m *mockGlobalAccessor) GetGlobalSysVar(name string) (string, error) {
 	return m.vars[name], nil
 }
 
+func (m *mockGlobalAccessor) GetAllSysVars() (map[string]string, error) {
+	return m.vars, nil
+}
+
 func (m *mockGlobalAccessor) SetGlobalSysVar(name string, value string) error {
 	m.vars[name] = value
 	return nil
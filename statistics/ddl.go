@@ -98,6 +98,10 @@ func (h *Handle) DeleteTableStatsFromKV(id int64) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	_, err = exec.Execute(fmt.Sprintf("delete from mysql.stats_index_usage where table_id = %d", id))
+	if err != nil {
+		return errors.Trace(err)
+	}
 	_, err = exec.Execute("commit")
 	return errors.Trace(err)
 }
@@ -184,6 +188,12 @@ func (h *Handle) deleteHistStatsFromKV(tableID int64, histID int64, isIndex int)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if isIndex == 1 {
+		_, err = exec.Execute(fmt.Sprintf("delete from mysql.stats_index_usage where table_id = %d and index_id = %d", tableID, histID))
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	_, err = exec.Execute("commit")
 	return errors.Trace(err)
 }
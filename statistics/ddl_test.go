@@ -102,6 +102,36 @@ func (s *testStatsCacheSuite) TestDDLTable(c *C) {
 	h.Update(do.InfoSchema())
 	statsTbl = h.GetTableStats(tableInfo.ID)
 	c.Assert(statsTbl.Pseudo, IsTrue)
+
+	testKit.MustExec("create table t2 (c1 int, c2 int)")
+	err = h.HandleDDLEvent(<-h.DDLEventCh())
+	c.Assert(err, IsNil)
+	testKit.MustExec("insert into t2 values (1, 2), (3, 4)")
+	testKit.MustExec("analyze table t2")
+	is = do.InfoSchema()
+	tbl, err = is.TableByName(model.NewCIStr("test"), model.NewCIStr("t2"))
+	c.Assert(err, IsNil)
+	oldTableInfo := tbl.Meta()
+	h.Update(is)
+	c.Assert(h.GetTableStats(oldTableInfo.ID).Count, Equals, int64(2))
+
+	// Truncating the table drops the old statistics and creates the empty new ones.
+	testKit.MustExec("truncate table t2")
+	err = h.HandleDDLEvent(<-h.DDLEventCh())
+	c.Assert(err, IsNil)
+	err = h.HandleDDLEvent(<-h.DDLEventCh())
+	c.Assert(err, IsNil)
+	is = do.InfoSchema()
+	tbl, err = is.TableByName(model.NewCIStr("test"), model.NewCIStr("t2"))
+	c.Assert(err, IsNil)
+	tableInfo = tbl.Meta()
+	c.Assert(tableInfo.ID, Not(Equals), oldTableInfo.ID)
+	h.Update(is)
+	statsTbl = h.GetTableStats(oldTableInfo.ID)
+	c.Assert(statsTbl.Pseudo, IsTrue)
+	statsTbl = h.GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.Pseudo, IsFalse)
+	c.Assert(statsTbl.Count, Equals, int64(0))
 }
 
 func (s *testStatsCacheSuite) TestDDLHistogram(c *C) {
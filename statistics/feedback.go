@@ -0,0 +1,166 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"bytes"
+	"math"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// MaxQueryFeedbackCount is the max number of feedback that the handle buffers between two updates.
+// Feedback beyond this limit is dropped.
+var MaxQueryFeedbackCount = 1024
+
+// feedbackErrorRate is the relative error between the estimated and the actual row count
+// that is tolerated before the feedback is applied to the histogram.
+const feedbackErrorRate = 0.2
+
+// QueryFeedback records the actual row count of an index scan over some ranges,
+// it is used to correct the index histogram when the estimation is inaccurate.
+type QueryFeedback struct {
+	tableID int64
+	idxID   int64
+	ranges  []*types.IndexRange
+	actual  int64
+}
+
+// NewQueryFeedback creates a feedback for the index scan of idxID on tableID over ranges.
+// The ranges are copied because they are aligned when the feedback is applied.
+func NewQueryFeedback(tableID, idxID int64, ranges []*types.IndexRange) *QueryFeedback {
+	q := &QueryFeedback{tableID: tableID, idxID: idxID, ranges: make([]*types.IndexRange, 0, len(ranges))}
+	for _, ran := range ranges {
+		q.ranges = append(q.ranges, &types.IndexRange{
+			LowVal:      append([]types.Datum(nil), ran.LowVal...),
+			LowExclude:  ran.LowExclude,
+			HighVal:     append([]types.Datum(nil), ran.HighVal...),
+			HighExclude: ran.HighExclude,
+		})
+	}
+	return q
+}
+
+// Update adds cnt to the actual row count.
+func (q *QueryFeedback) Update(cnt int64) {
+	q.actual += cnt
+}
+
+type feedbackBuffer struct {
+	sync.Mutex
+	items []*QueryFeedback
+}
+
+// StoreQueryFeedback saves the feedback, it will be applied to the stats cache next time
+// UpdateStatsByQueryFeedback is called.
+func (h *Handle) StoreQueryFeedback(q *QueryFeedback) {
+	h.feedback.Lock()
+	defer h.feedback.Unlock()
+	if len(h.feedback.items) >= MaxQueryFeedbackCount {
+		return
+	}
+	h.feedback.items = append(h.feedback.items, q)
+}
+
+// UpdateStatsByQueryFeedback applies the buffered feedback to the index histograms in the stats cache.
+func (h *Handle) UpdateStatsByQueryFeedback() {
+	h.feedback.Lock()
+	items := h.feedback.items
+	h.feedback.items = nil
+	h.feedback.Unlock()
+
+	sc := &variable.StatementContext{}
+	for _, q := range items {
+		tbl := h.GetTableStats(q.tableID)
+		idx := tbl.Indices[q.idxID]
+		if tbl.Pseudo || idx == nil || len(idx.Buckets) == 0 {
+			continue
+		}
+		newIdx, err := idx.updateByFeedback(sc, q, tbl.Count)
+		if err != nil {
+			log.Warnf("[stats] apply feedback on table %d index %d failed: %v", q.tableID, q.idxID, errors.ErrorStack(err))
+			continue
+		}
+		if newIdx == nil {
+			continue
+		}
+		newTbl := tbl.copy()
+		newTbl.ModifyCount = tbl.ModifyCount
+		newTbl.Version = tbl.Version
+		newTbl.Indices[q.idxID] = newIdx
+		h.UpdateTableStats([]*Table{newTbl}, nil)
+	}
+}
+
+// updateByFeedback returns a new index whose buckets overlapping the feedback ranges are scaled to
+// match the actual row count, the other buckets are scaled the other way so the total row count is kept.
+// It returns nil if the estimation is accurate enough.
+func (idx *Index) updateByFeedback(sc *variable.StatementContext, q *QueryFeedback, totalCount int64) (*Index, error) {
+	estimate, err := idx.getRowCount(sc, q.ranges)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	actual := float64(q.actual) / idx.getIncreaseFactor(totalCount)
+	if estimate <= 0 || math.Abs(actual-estimate) <= feedbackErrorRate*estimate {
+		return nil, nil
+	}
+	lbs, rbs := make([][]byte, len(q.ranges)), make([][]byte, len(q.ranges))
+	for i, ran := range q.ranges {
+		lbs[i], rbs[i], err = idx.encodeRange(ran)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	overlapped := make([]bool, len(idx.Buckets))
+	var inCount, preCount int64
+	for i, bkt := range idx.Buckets {
+		for j := range q.ranges {
+			if bytes.Compare(bkt.UpperBound.GetBytes(), lbs[j]) >= 0 && bytes.Compare(bkt.LowerBound.GetBytes(), rbs[j]) < 0 {
+				overlapped[i] = true
+				inCount += bkt.Count - preCount
+				break
+			}
+		}
+		preCount = bkt.Count
+	}
+	total := idx.totalRowCount()
+	inRatio := actual / estimate
+	outRatio := 1.0
+	if outCount := total - float64(inCount); outCount > 0 {
+		outRatio = math.Max((total-float64(inCount)*inRatio)/outCount, 0)
+	}
+	newIdx := &Index{Info: idx.Info, Histogram: idx.Histogram}
+	newIdx.Buckets = make([]Bucket, len(idx.Buckets))
+	preCount = 0
+	var newCount int64
+	for i, bkt := range idx.Buckets {
+		ratio := outRatio
+		if overlapped[i] {
+			ratio = inRatio
+		}
+		cnt := int64(math.Floor(float64(bkt.Count-preCount)*ratio + 0.5))
+		preCount = bkt.Count
+		newCount += cnt
+		newIdx.Buckets[i] = bkt
+		newIdx.Buckets[i].Count = newCount
+		if newIdx.Buckets[i].Repeats > cnt {
+			newIdx.Buckets[i].Repeats = cnt
+		}
+	}
+	return newIdx, nil
+}
@@ -45,10 +45,20 @@ type Handle struct {
 	// analyzeResultCh is a channel to notify an analyze index or column operation has ended.
 	// We need this to avoid updating the stats simultaneously.
 	analyzeResultCh chan *AnalyzeResult
+	// reloadCh is a channel to request a full reload of the stats cache, the result of the reload is sent back.
+	// We need this to avoid updating the stats simultaneously.
+	reloadCh chan chan error
 	// All the stats collector required by session are maintained in this list.
 	listHead *SessionStatsCollector
 	// We collect the delta map and merge them with globalMap.
 	globalMap tableDeltaMap
+	// feedback buffers the query feedback until it is applied to the stats cache.
+	feedback feedbackBuffer
+	// indexUsage collects the access count of the indices.
+	indexUsage indexUsageCollector
+	// metaVersions is the latest version of the stats meta read from the storage for each table, it's used to
+	// compute the loaded ratio of the stats. It's only accessed by the goroutine updating the stats cache.
+	metaVersions map[int64]uint64
 
 	Lease time.Duration
 }
@@ -66,8 +76,11 @@ func NewHandle(ctx context.Context, lease time.Duration) *Handle {
 		ctx:             ctx,
 		ddlEventCh:      make(chan *ddl.Event, 100),
 		analyzeResultCh: make(chan *AnalyzeResult, 100),
+		reloadCh:        make(chan chan error),
 		listHead:        &SessionStatsCollector{mapper: make(tableDeltaMap)},
 		globalMap:       make(tableDeltaMap),
+		indexUsage:      indexUsageCollector{delta: make(indexUsageMap), stored: make(indexUsageMap)},
+		metaVersions:    make(map[int64]uint64),
 		Lease:           lease,
 	}
 	handle.statsCache.Store(statsCache{})
@@ -79,12 +92,48 @@ func (h *Handle) AnalyzeResultCh() chan *AnalyzeResult {
 	return h.analyzeResultCh
 }
 
+// ReloadCh returns the channel to request a full reload of the stats cache.
+func (h *Handle) ReloadCh() chan chan error {
+	return h.reloadCh
+}
+
 // Update reads stats meta from store and updates the stats map.
 func (h *Handle) Update(is infoschema.InfoSchema) error {
+	tables, deletedTableIDs, err := h.readTableStats(is)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	h.UpdateTableStats(tables, deletedTableIDs)
+	h.updateLoadedRatio()
+	return nil
+}
+
+// Reload reads all the stats meta from store and rebuilds the stats map, so the stats changed by an external
+// ANALYZE or restore are loaded at once, even if their versions are older than the loaded ones.
+func (h *Handle) Reload(is infoschema.InfoSchema) error {
+	h.LastVersion = 0
+	h.PrevLastVersion = 0
+	h.metaVersions = make(map[int64]uint64)
+	tables, _, err := h.readTableStats(is)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	newCache := make(statsCache, len(tables))
+	for _, tbl := range tables {
+		newCache[tbl.TableID] = tbl
+	}
+	h.statsCache.Store(newCache)
+	h.updateLoadedRatio()
+	return nil
+}
+
+// readTableStats reads the stats of the tables whose stats meta are updated after PrevLastVersion, and the IDs of
+// the tables whose stats should be deleted.
+func (h *Handle) readTableStats(is infoschema.InfoSchema) ([]*Table, []int64, error) {
 	sql := fmt.Sprintf("SELECT version, table_id, modify_count, count from mysql.stats_meta where version > %d order by version", h.PrevLastVersion)
 	rows, _, err := h.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(h.ctx, sql)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 	h.PrevLastVersion = h.LastVersion
 	tables := make([]*Table, 0, len(rows))
@@ -95,8 +144,10 @@ func (h *Handle) Update(is infoschema.InfoSchema) error {
 		if !ok {
 			log.Debugf("Unknown table ID %d in stats meta table, maybe it has been dropped", tableID)
 			deletedTableIDs = append(deletedTableIDs, tableID)
+			delete(h.metaVersions, tableID)
 			continue
 		}
+		h.metaVersions[tableID] = version
 		tableInfo := table.Meta()
 		tbl, err := h.tableStatsFromStorage(tableInfo)
 		// Error is not nil may mean that there are some ddl changes on this table, we will not update it.
@@ -106,6 +157,7 @@ func (h *Handle) Update(is infoschema.InfoSchema) error {
 		}
 		if tbl == nil {
 			deletedTableIDs = append(deletedTableIDs, tableID)
+			delete(h.metaVersions, tableID)
 			continue
 		}
 		tbl.Version = version
@@ -114,8 +166,7 @@ func (h *Handle) Update(is infoschema.InfoSchema) error {
 		tables = append(tables, tbl)
 		h.LastVersion = version
 	}
-	h.UpdateTableStats(tables, deletedTableIDs)
-	return nil
+	return tables, deletedTableIDs, nil
 }
 
 // GetTableStats retrieves the statistics table from cache, and the cache will be updated by a goroutine.
@@ -127,6 +178,32 @@ func (h *Handle) GetTableStats(tblID int64) *Table {
 	return tbl
 }
 
+// ColumnNDV implements infoschema.StatsProvider interface.
+func (h *Handle) ColumnNDV(tableID, colID int64) (int64, bool) {
+	tbl, ok := h.statsCache.Load().(statsCache)[tableID]
+	if !ok || tbl.Pseudo {
+		return 0, false
+	}
+	col, ok := tbl.Columns[colID]
+	if !ok {
+		return 0, false
+	}
+	return col.NDV, true
+}
+
+// IndexNDV implements infoschema.StatsProvider interface.
+func (h *Handle) IndexNDV(tableID, idxID int64) (int64, bool) {
+	tbl, ok := h.statsCache.Load().(statsCache)[tableID]
+	if !ok || tbl.Pseudo {
+		return 0, false
+	}
+	idx, ok := tbl.Indices[idxID]
+	if !ok {
+		return 0, false
+	}
+	return idx.NDV, true
+}
+
 func (h *Handle) copyFromOldCache() statsCache {
 	newCache := statsCache{}
 	oldCache := h.statsCache.Load().(statsCache)
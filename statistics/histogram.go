@@ -439,24 +439,33 @@ func (idx *Index) String() string {
 	return idx.Histogram.toString(true)
 }
 
+// encodeRange encodes the index range to the bytes range [lb, rb) which can be compared with the buckets.
+func (idx *Index) encodeRange(indexRange *types.IndexRange) (lb, rb []byte, err error) {
+	indexRange.Align(len(idx.Info.Columns))
+	lb, err = codec.EncodeKey(nil, indexRange.LowVal...)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if indexRange.LowExclude {
+		lb = append(lb, 0)
+	}
+	rb, err = codec.EncodeKey(nil, indexRange.HighVal...)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if !indexRange.HighExclude {
+		rb = append(rb, 0)
+	}
+	return lb, rb, nil
+}
+
 func (idx *Index) getRowCount(sc *variable.StatementContext, indexRanges []*types.IndexRange) (float64, error) {
 	totalCount := float64(0)
 	for _, indexRange := range indexRanges {
-		indexRange.Align(len(idx.Info.Columns))
-		lb, err := codec.EncodeKey(nil, indexRange.LowVal...)
-		if err != nil {
-			return 0, errors.Trace(err)
-		}
-		if indexRange.LowExclude {
-			lb = append(lb, 0)
-		}
-		rb, err := codec.EncodeKey(nil, indexRange.HighVal...)
+		lb, rb, err := idx.encodeRange(indexRange)
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
-		if !indexRange.HighExclude {
-			rb = append(rb, 0)
-		}
 		l := types.NewBytesDatum(lb)
 		r := types.NewBytesDatum(rb)
 		rowCount, err := idx.betweenRowCount(sc, l, r)
@@ -0,0 +1,152 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// MaxIndexUsageCount is the max number of indices whose usage the handle collects between two dumps.
+// The usage of the other indices is dropped until the collected usage is dumped.
+var MaxIndexUsageCount = 10000
+
+// indexUsageTimeFormat is the format of the last used time saved in mysql.stats_index_usage.
+const indexUsageTimeFormat = "2006-01-02 15:04:05"
+
+// IndexUsage is the number of statements which accessed an index and the time of the last access.
+type IndexUsage struct {
+	AccessCount int64
+	// LastUsed is zero if the index is never accessed.
+	LastUsed time.Time
+}
+
+func (u *IndexUsage) merge(o IndexUsage) {
+	u.AccessCount += o.AccessCount
+	if o.LastUsed.After(u.LastUsed) {
+		u.LastUsed = o.LastUsed
+	}
+}
+
+type indexUsageKey struct {
+	tableID int64
+	indexID int64
+}
+
+type indexUsageMap map[indexUsageKey]IndexUsage
+
+func (m indexUsageMap) merge(key indexUsageKey, usage IndexUsage) {
+	item := m[key]
+	item.merge(usage)
+	m[key] = item
+}
+
+type indexUsageCollector struct {
+	sync.Mutex
+	// delta is the usage collected since the last dump.
+	delta indexUsageMap
+	// stored is the usage loaded from mysql.stats_index_usage.
+	stored indexUsageMap
+}
+
+// RecordIndexUsage counts an access to the index, it's called once for each index reader of a statement.
+func (h *Handle) RecordIndexUsage(tableID, indexID int64) {
+	key := indexUsageKey{tableID: tableID, indexID: indexID}
+	h.indexUsage.Lock()
+	defer h.indexUsage.Unlock()
+	if _, ok := h.indexUsage.delta[key]; !ok && len(h.indexUsage.delta) >= MaxIndexUsageCount {
+		return
+	}
+	h.indexUsage.delta.merge(key, IndexUsage{AccessCount: 1, LastUsed: time.Now()})
+}
+
+// GetIndexUsage returns the usage of the index, including the usage which isn't dumped yet.
+func (h *Handle) GetIndexUsage(tableID, indexID int64) IndexUsage {
+	key := indexUsageKey{tableID: tableID, indexID: indexID}
+	h.indexUsage.Lock()
+	defer h.indexUsage.Unlock()
+	usage := h.indexUsage.stored[key]
+	usage.merge(h.indexUsage.delta[key])
+	return usage
+}
+
+// DumpIndexUsageToKV adds the collected usage to mysql.stats_index_usage, then reloads the usage from it,
+// so the usage collected by the other servers is seen too.
+func (h *Handle) DumpIndexUsageToKV() error {
+	h.indexUsage.Lock()
+	delta := h.indexUsage.delta
+	h.indexUsage.delta = make(indexUsageMap)
+	h.indexUsage.Unlock()
+
+	if len(delta) > 0 {
+		if err := h.dumpIndexUsageDeltaToKV(delta); err != nil {
+			// Keep the delta to dump it next time.
+			h.indexUsage.Lock()
+			for key, usage := range delta {
+				h.indexUsage.delta.merge(key, usage)
+			}
+			h.indexUsage.Unlock()
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(h.LoadIndexUsage())
+}
+
+func (h *Handle) dumpIndexUsageDeltaToKV(delta indexUsageMap) error {
+	exec := h.ctx.(sqlexec.SQLExecutor)
+	_, err := exec.Execute("begin")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for key, usage := range delta {
+		lastUsed := usage.LastUsed.Format(indexUsageTimeFormat)
+		sql := fmt.Sprintf("insert into mysql.stats_index_usage (table_id, index_id, access_count, last_used_at) values (%d, %d, %d, '%s') "+
+			"on duplicate key update access_count = access_count + %d, last_used_at = greatest(last_used_at, '%s')",
+			key.tableID, key.indexID, usage.AccessCount, lastUsed, usage.AccessCount, lastUsed)
+		if _, err = exec.Execute(sql); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	_, err = exec.Execute("commit")
+	return errors.Trace(err)
+}
+
+// LoadIndexUsage loads the usage of all the indices from mysql.stats_index_usage.
+func (h *Handle) LoadIndexUsage() error {
+	sql := "select table_id, index_id, access_count, last_used_at from mysql.stats_index_usage"
+	rows, _, err := h.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(h.ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	stored := make(indexUsageMap, len(rows))
+	for _, row := range rows {
+		key := indexUsageKey{tableID: row.Data[0].GetInt64(), indexID: row.Data[1].GetInt64()}
+		usage := IndexUsage{AccessCount: row.Data[2].GetInt64()}
+		if !row.Data[3].IsNull() {
+			usage.LastUsed, err = row.Data[3].GetMysqlTime().Time.GoTime(time.Local)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		stored[key] = usage
+	}
+	h.indexUsage.Lock()
+	h.indexUsage.stored = stored
+	h.indexUsage.Unlock()
+	return nil
+}
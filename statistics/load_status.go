@@ -0,0 +1,108 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// TableLoadStatus is the loading status of the stats of a table.
+type TableLoadStatus struct {
+	TableID int64
+	// Version is the version of the stats meta in the storage.
+	Version uint64
+	// LoadedVersion is the version of the stats in the cache, it's 0 if the stats aren't loaded.
+	LoadedVersion uint64
+	// LastAnalyzeVersion is the latest version of the analyzed histograms in the cache, it's 0 if there is none.
+	LastAnalyzeVersion uint64
+}
+
+// Loaded returns whether the latest stats of the table in the storage are loaded.
+func (s *TableLoadStatus) Loaded() bool {
+	return s.LoadedVersion != 0 && s.LoadedVersion >= s.Version
+}
+
+// LoadStatus compares the stats meta in the storage with the stats cache for the tables in the schema, the tables
+// whose stats are changed after the last update are pending. The stats meta is read by ctx, it must not be the
+// context of the handle, which is used by the goroutine updating the stats.
+func (h *Handle) LoadStatus(ctx context.Context, is infoschema.InfoSchema) ([]TableLoadStatus, error) {
+	sql := "SELECT table_id, version from mysql.stats_meta order by table_id"
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cache := h.statsCache.Load().(statsCache)
+	status := make([]TableLoadStatus, 0, len(rows))
+	for _, row := range rows {
+		s := TableLoadStatus{TableID: row.Data[0].GetInt64(), Version: row.Data[1].GetUint64()}
+		if _, ok := is.TableByID(s.TableID); !ok {
+			// The table is dropped, its stats are going to be deleted.
+			continue
+		}
+		if tbl, ok := cache[s.TableID]; ok {
+			s.LoadedVersion = tbl.Version
+			s.LastAnalyzeVersion = tbl.lastAnalyzeVersion()
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// LoadedRatio returns the ratio of the tables whose latest stats are loaded, it's 1 if there is no table.
+func LoadedRatio(status []TableLoadStatus) float64 {
+	if len(status) == 0 {
+		return 1
+	}
+	loaded := 0
+	for i := range status {
+		if status[i].Loaded() {
+			loaded++
+		}
+	}
+	return float64(loaded) / float64(len(status))
+}
+
+// updateLoadedRatio refreshes the loaded ratio gauge by the stats meta versions read by the last update.
+func (h *Handle) updateLoadedRatio() {
+	cache := h.statsCache.Load().(statsCache)
+	status := make([]TableLoadStatus, 0, len(h.metaVersions))
+	for tableID, version := range h.metaVersions {
+		s := TableLoadStatus{TableID: tableID, Version: version}
+		if tbl, ok := cache[tableID]; ok {
+			s.LoadedVersion = tbl.Version
+		}
+		status = append(status, s)
+	}
+	loadedRatioGauge.Set(LoadedRatio(status))
+}
+
+// lastAnalyzeVersion returns the latest version of the histograms with buckets, the histograms inserted for the new
+// tables and columns have no bucket.
+func (t *Table) lastAnalyzeVersion() uint64 {
+	var version uint64
+	for _, col := range t.Columns {
+		if len(col.Buckets) > 0 && col.LastUpdateVersion > version {
+			version = col.LastUpdateVersion
+		}
+	}
+	for _, idx := range t.Indices {
+		if len(idx.Buckets) > 0 && idx.LastUpdateVersion > version {
+			version = idx.LastUpdateVersion
+		}
+	}
+	return version
+}
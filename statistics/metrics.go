@@ -0,0 +1,32 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	loadedRatioGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "statistics",
+			Name:      "loaded_ratio",
+			Help:      "Ratio of the tables whose latest stats in the storage are loaded.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(loadedRatioGauge)
+}
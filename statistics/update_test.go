@@ -16,6 +16,7 @@ package statistics_test
 import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -223,3 +224,113 @@ func (s *testStatsUpdateSuite) TestTxnWithFailure(c *C) {
 	stats1 = h.GetTableStats(tableInfo1.ID)
 	c.Assert(stats1.Count, Equals, int64(rowCount1+1))
 }
+
+func (s *testStatsUpdateSuite) TestQueryFeedback(c *C) {
+	store, do, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	testKit := testkit.NewTestKit(c, store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int, b int, index idx(b))")
+	for i := 1; i <= 10; i++ {
+		testKit.MustExec("insert into t values (?, ?)", i, i)
+	}
+	testKit.MustExec("analyze table t")
+	for i := 0; i < 30; i++ {
+		testKit.MustExec("insert into t values (0, 3)")
+	}
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tblInfo := tbl.Meta()
+	idxID := tblInfo.Indices[0].ID
+	h := do.StatsHandle()
+	h.DumpStatsDeltaToKV()
+	c.Assert(h.Update(is), IsNil)
+
+	sc := testKit.Se.GetSessionVars().StmtCtx
+	ranges := func() []*types.IndexRange {
+		return []*types.IndexRange{{LowVal: []types.Datum{types.NewIntDatum(3)}, HighVal: []types.Datum{types.NewIntDatum(3)}}}
+	}
+	tblCount := h.GetTableStats(tblInfo.ID).Count
+	before, err := h.GetTableStats(tblInfo.ID).GetRowCountByIndexRanges(sc, idxID, ranges())
+	c.Assert(err, IsNil)
+
+	// The feedback is not collected by default.
+	c.Assert(testKit.MustQuery("select b from t where b = 3").Rows(), HasLen, 31)
+	h.UpdateStatsByQueryFeedback()
+	count, err := h.GetTableStats(tblInfo.ID).GetRowCountByIndexRanges(sc, idxID, ranges())
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, before)
+
+	testKit.MustExec("set @@session.tidb_enable_stats_feedback = 1")
+	c.Assert(testKit.MustQuery("select b from t where b = 3").Rows(), HasLen, 31)
+	h.UpdateStatsByQueryFeedback()
+	count, err = h.GetTableStats(tblInfo.ID).GetRowCountByIndexRanges(sc, idxID, ranges())
+	c.Assert(err, IsNil)
+	c.Assert(count, Greater, before)
+	c.Assert(31-count, Less, 31-before)
+	c.Assert(h.GetTableStats(tblInfo.ID).Count, Equals, tblCount)
+}
+
+func (s *testStatsUpdateSuite) TestIndexUsage(c *C) {
+	store, do, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	testKit := testkit.NewTestKit(c, store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int, b int, c int, index idx_a(a), index idx_b(b))")
+	testKit.MustExec("insert into t values (1, 1, 1), (2, 2, 2)")
+	h := do.StatsHandle()
+	h.HandleDDLEvent(<-h.DDLEventCh())
+
+	tbl, err := do.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+	idxA, idxB := tableInfo.Indices[0], tableInfo.Indices[1]
+
+	testKit.MustQuery("select * from t use index(idx_a) where a = 1").Check(testkit.Rows("1 1 1"))
+	testKit.MustQuery("select a from t use index(idx_a) where a > 0").Check(testkit.Rows("1", "2"))
+	testKit.MustQuery("select * from t where c = 1").Check(testkit.Rows("1 1 1"))
+	usage := h.GetIndexUsage(tableInfo.ID, idxA.ID)
+	c.Assert(usage.AccessCount, Equals, int64(2))
+	c.Assert(usage.LastUsed.IsZero(), IsFalse)
+	usage = h.GetIndexUsage(tableInfo.ID, idxB.ID)
+	c.Assert(usage.AccessCount, Equals, int64(0))
+	c.Assert(usage.LastUsed.IsZero(), IsTrue)
+
+	// The usage is loaded after restart once it's dumped.
+	c.Assert(h.DumpIndexUsageToKV(), IsNil)
+	newHandle := statistics.NewHandle(testKit.Se, 0)
+	c.Assert(newHandle.GetIndexUsage(tableInfo.ID, idxA.ID).AccessCount, Equals, int64(0))
+	c.Assert(newHandle.LoadIndexUsage(), IsNil)
+	c.Assert(newHandle.GetIndexUsage(tableInfo.ID, idxA.ID).AccessCount, Equals, int64(2))
+	c.Assert(newHandle.GetIndexUsage(tableInfo.ID, idxA.ID).LastUsed.IsZero(), IsFalse)
+
+	// The usage is accumulated by every dump.
+	testKit.MustQuery("select * from t use index(idx_a) where a = 2").Check(testkit.Rows("2 2 2"))
+	c.Assert(h.GetIndexUsage(tableInfo.ID, idxA.ID).AccessCount, Equals, int64(3))
+	c.Assert(h.DumpIndexUsageToKV(), IsNil)
+	c.Assert(h.GetIndexUsage(tableInfo.ID, idxA.ID).AccessCount, Equals, int64(3))
+	c.Assert(newHandle.LoadIndexUsage(), IsNil)
+	c.Assert(newHandle.GetIndexUsage(tableInfo.ID, idxA.ID).AccessCount, Equals, int64(3))
+
+	// The usage of too many indices is dropped until the next dump.
+	origin := statistics.MaxIndexUsageCount
+	statistics.MaxIndexUsageCount = 1
+	defer func() {
+		statistics.MaxIndexUsageCount = origin
+	}()
+	testKit.MustQuery("select * from t use index(idx_b) where b = 1").Check(testkit.Rows("1 1 1"))
+	testKit.MustQuery("select * from t use index(idx_a) where a = 1").Check(testkit.Rows("1 1 1"))
+	testKit.MustQuery("select * from t use index(idx_b) where b = 2").Check(testkit.Rows("2 2 2"))
+	c.Assert(h.GetIndexUsage(tableInfo.ID, idxA.ID).AccessCount, Equals, int64(3))
+	c.Assert(h.GetIndexUsage(tableInfo.ID, idxB.ID).AccessCount, Equals, int64(2))
+
+	// The usage is deleted with the index.
+	testKit.MustExec("alter table t drop index idx_a")
+	c.Assert(h.HandleDDLEvent(<-h.DDLEventCh()), IsNil)
+	c.Assert(h.DumpIndexUsageToKV(), IsNil)
+	c.Assert(h.GetIndexUsage(tableInfo.ID, idxA.ID).AccessCount, Equals, int64(0))
+	c.Assert(h.GetIndexUsage(tableInfo.ID, idxB.ID).AccessCount, Equals, int64(2))
+}
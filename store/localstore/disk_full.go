@@ -0,0 +1,96 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/kv"
+)
+
+// diskFullRetryInterval is the interval a commit is tried again when the store is read-only for the full disk,
+// the other commits are rejected at once. The store becomes writable again once a commit succeeds.
+var diskFullRetryInterval = 5 * time.Second
+
+// isDiskFullError checks whether the error is caused by no space left on the device.
+func isDiskFullError(err error) bool {
+	err = errors.Cause(err)
+	switch x := err.(type) {
+	case *os.PathError:
+		err = x.Err
+	case *os.SyscallError:
+		err = x.Err
+	case *os.LinkError:
+		err = x.Err
+	}
+	if errno, ok := err.(syscall.Errno); ok {
+		return errno == syscall.ENOSPC
+	}
+	// The engines may wrap the error of the file system in their own errors.
+	return err != nil && strings.Contains(err.Error(), syscall.ENOSPC.Error())
+}
+
+// diskFullState puts the store into a read-only state when its disk is full, the reads are not affected.
+type diskFullState struct {
+	mu        sync.Mutex
+	full      bool
+	lastRetry time.Time
+}
+
+// allowCommit returns whether a commit can be tried, it's false if the disk is full and the commit shouldn't be retried yet.
+func (d *diskFullState) allowCommit() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.full {
+		return true
+	}
+	now := time.Now()
+	if now.Sub(d.lastRetry) < diskFullRetryInterval {
+		diskFullRejectedCounter.Inc()
+		return false
+	}
+	d.lastRetry = now
+	return true
+}
+
+// onCommit updates the state with the result of a commit, and returns the error to the client.
+func (d *diskFullState) onCommit(path string, err error) error {
+	full := isDiskFullError(err)
+	if err != nil && !full {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if full {
+		if !d.full {
+			log.Errorf("[kv] the disk of the local store %s is full, the store is read-only until some space is freed: %v", path, err)
+			d.full = true
+			diskFullGauge.Set(1)
+		}
+		d.lastRetry = time.Now()
+		return kv.ErrDiskFull
+	}
+	if d.full {
+		log.Warnf("[kv] the disk of the local store %s has free space, the store is writable again", path)
+		d.full = false
+		diskFullGauge.Set(0)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/store/localstore/engine"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/terror"
+)
+
+var _ = Suite(&testDiskFullSuite{})
+
+type testDiskFullSuite struct{}
+
+// diskFullDB fails the commits with ENOSPC when full is set.
+type diskFullDB struct {
+	engine.DB
+	full    bool
+	commits int
+}
+
+func (db *diskFullDB) Commit(b engine.Batch) error {
+	db.commits++
+	if db.full {
+		return &os.PathError{Op: "write", Path: "000001.log", Err: syscall.ENOSPC}
+	}
+	return db.DB.Commit(b)
+}
+
+type diskFullDriver struct {
+	db *diskFullDB
+}
+
+func (d *diskFullDriver) Open(schema string) (engine.DB, error) {
+	db, err := goleveldb.MemoryDriver{}.Open(schema)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	d.db = &diskFullDB{DB: db}
+	return d.db, nil
+}
+
+func (s *testDiskFullSuite) TestIsDiskFullError(c *C) {
+	c.Assert(isDiskFullError(nil), IsFalse)
+	c.Assert(isDiskFullError(errors.New("unknown")), IsFalse)
+	c.Assert(isDiskFullError(syscall.ENOSPC), IsTrue)
+	c.Assert(isDiskFullError(errors.Trace(&os.PathError{Op: "write", Path: "a", Err: syscall.ENOSPC})), IsTrue)
+	c.Assert(isDiskFullError(os.NewSyscallError("fsync", syscall.ENOSPC)), IsTrue)
+	c.Assert(isDiskFullError(&os.PathError{Op: "write", Path: "a", Err: syscall.EIO}), IsFalse)
+	c.Assert(isDiskFullError(fmt.Errorf("leveldb: write 000001.log: %v", syscall.ENOSPC)), IsTrue)
+}
+
+func (s *testDiskFullSuite) TestDiskFull(c *C) {
+	defer func(interval time.Duration) {
+		diskFullRetryInterval = interval
+	}(diskFullRetryInterval)
+	diskFullRetryInterval = time.Hour
+
+	driver := &diskFullDriver{}
+	store, err := Driver{driver}.Open(fmt.Sprintf("memory://disk_full_%d", time.Now().UnixNano()))
+	c.Assert(err, IsNil)
+	defer store.Close()
+	set := func(key, value string) error {
+		txn, err := store.Begin()
+		c.Assert(err, IsNil)
+		c.Assert(txn.Set([]byte(key), []byte(value)), IsNil)
+		return txn.Commit()
+	}
+	get := func(key string) string {
+		txn, err := store.Begin()
+		c.Assert(err, IsNil)
+		defer txn.Rollback()
+		value, err := txn.Get([]byte(key))
+		c.Assert(err, IsNil)
+		return string(value)
+	}
+	c.Assert(set("a", "1"), IsNil)
+
+	driver.db.full = true
+	err = set("a", "2")
+	c.Assert(terror.ErrorEqual(err, kv.ErrDiskFull), IsTrue, Commentf("err %v", err))
+	sqlErr := errors.Cause(err).(*terror.Error).ToSQLError()
+	c.Assert(sqlErr.Code, Equals, uint16(mysql.ErrDiskFull))
+	c.Assert(driver.db.commits, Equals, 2)
+	// The store is read-only, the commits are rejected without writing the engine.
+	err = set("b", "1")
+	c.Assert(terror.ErrorEqual(err, kv.ErrDiskFull), IsTrue, Commentf("err %v", err))
+	c.Assert(driver.db.commits, Equals, 2)
+	c.Assert(get("a"), Equals, "1")
+
+	// The store is writable after some space is freed, and the keys of the failed commits aren't locked.
+	driver.db.full = false
+	diskFullRetryInterval = 0
+	c.Assert(set("a", "3"), IsNil)
+	c.Assert(set("b", "1"), IsNil)
+	c.Assert(get("a"), Equals, "3")
+	c.Assert(get("b"), Equals, "1")
+	c.Assert(driver.db.commits, Equals, 4)
+}
@@ -170,6 +170,9 @@ func (s *dbStore) doCommit(txn *dbTxn) error {
 		s.mu.Unlock()
 	}()
 	// Here we are sure no concurrent committing happens.
+	if !s.diskFull.allowCommit() {
+		return errors.Trace(kv.ErrDiskFull)
+	}
 	err = s.tryLock(txn)
 	if err != nil {
 		return errors.Trace(err)
@@ -188,6 +191,8 @@ func (s *dbStore) doCommit(txn *dbTxn) error {
 	})
 	err = s.writeBatch(b)
 	if err != nil {
+		// The keys are unlocked without being updated, so the store isn't blocked by the failed transaction.
+		s.releaseKeys(txn)
 		return errors.Trace(err)
 	}
 	// Update commit version.
@@ -233,6 +238,8 @@ type dbStore struct {
 	closed       bool
 	committingTS uint64
 
+	diskFull diskFullState
+
 	pd     localPD
 	oracle oracle.Oracle
 }
@@ -402,7 +409,7 @@ func (s *dbStore) writeBatch(b engine.Batch) error {
 	if b.Len() == 0 {
 		return nil
 	}
-	err := s.db.Commit(b)
+	err := s.diskFull.onCommit(s.path, s.db.Commit(b))
 	if err != nil {
 		log.Error(err)
 		return errors.Trace(err)
@@ -431,3 +438,12 @@ func (s *dbStore) unLockKeys(txn *dbTxn) error {
 	}
 	return nil
 }
+
+// releaseKeys unlocks the keys of a transaction failed to commit.
+func (s *dbStore) releaseKeys(txn *dbTxn) {
+	for k := range txn.lockedKeys {
+		if tid, ok := s.keysLocked[k]; ok && tid == txn.tid {
+			delete(s.keysLocked, k)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	diskFullGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "localstore",
+			Name:      "disk_full",
+			Help:      "Whether the local store is read-only because its disk is full, 1 means full.",
+		})
+
+	diskFullRejectedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "localstore",
+			Name:      "disk_full_rejected_total",
+			Help:      "Counter of the commits rejected because the disk of the local store is full.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(diskFullGauge)
+	prometheus.MustRegister(diskFullRejectedCounter)
+}
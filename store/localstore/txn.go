@@ -163,6 +163,14 @@ func (txn *dbTxn) Valid() bool {
 	return txn.valid
 }
 
+func (txn *dbTxn) Checkpoint() int {
+	return txn.us.Checkpoint()
+}
+
+func (txn *dbTxn) RollbackToCheckpoint(cp int) {
+	txn.us.RollbackToCheckpoint(cp)
+}
+
 func (txn *dbTxn) Size() int {
 	return txn.us.Size()
 }
@@ -129,6 +129,7 @@ const (
 	gcResolveLockMaxBackoff = 100000
 	gcDeleteRangeMaxBackoff = 100000
 	rawkvMaxBackoff         = 20000
+	splitRegionMaxBackoff   = 20000
 )
 
 var commitMaxBackoff = 20000
@@ -23,6 +23,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/tikvpb"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
+	"github.com/prometheus/client_golang/prometheus"
 	goctx "golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
@@ -39,6 +40,9 @@ const (
 
 	rpcLabelKV  = "kv"
 	rpcLabelCop = "cop"
+
+	connPoolInUse = "in_use"
+	connPoolIdle  = "idle"
 )
 
 // Client is a client that sends RPC.
@@ -53,12 +57,23 @@ type Client interface {
 type connArray struct {
 	index uint32
 	v     []*grpc.ClientConn
+	// inflight is the number of in-flight requests on each connection.
+	inflight []int32
+
+	mu struct {
+		sync.Mutex
+		// inUse and idle are only set when the array is registered in the rpcClient,
+		// and are reset when the array is closed.
+		inUse prometheus.Gauge
+		idle  prometheus.Gauge
+	}
 }
 
 func newConnArray(maxSize uint32, addr string) (*connArray, error) {
 	a := &connArray{
-		index: 0,
-		v:     make([]*grpc.ClientConn, maxSize),
+		index:    0,
+		v:        make([]*grpc.ClientConn, maxSize),
+		inflight: make([]int32, maxSize),
 	}
 	if err := a.Init(addr); err != nil {
 		return nil, err
@@ -86,9 +101,48 @@ func (a *connArray) Init(addr string) error {
 	return nil
 }
 
-func (a *connArray) Get() *grpc.ClientConn {
+// Get gets a connection in round robin and marks a request in flight on it,
+// the caller should call Put with the returned index when the request is finished.
+func (a *connArray) Get() (*grpc.ClientConn, uint32) {
 	next := atomic.AddUint32(&a.index, 1) % uint32(len(a.v))
-	return a.v[next]
+	if atomic.AddInt32(&a.inflight[next], 1) == 1 {
+		a.updateGauges(1)
+	}
+	return a.v[next], next
+}
+
+// Put marks a request on the connection with the index finished.
+func (a *connArray) Put(idx uint32) {
+	if atomic.AddInt32(&a.inflight[idx], -1) == 0 {
+		a.updateGauges(-1)
+	}
+}
+
+// register binds the array to the connection pool gauges of the addr.
+func (a *connArray) register(addr string) {
+	a.mu.Lock()
+	a.mu.inUse = connPoolGauge.WithLabelValues(addr, connPoolInUse)
+	a.mu.idle = connPoolGauge.WithLabelValues(addr, connPoolIdle)
+	var inUse int
+	for i := range a.inflight {
+		if atomic.LoadInt32(&a.inflight[i]) > 0 {
+			inUse++
+		}
+	}
+	a.mu.inUse.Set(float64(inUse))
+	a.mu.idle.Set(float64(len(a.v) - inUse))
+	a.mu.Unlock()
+}
+
+// updateGauges moves delta connections from idle to in use. It does nothing if the
+// array is not registered or is closed, so the gauges never go negative.
+func (a *connArray) updateGauges(delta float64) {
+	a.mu.Lock()
+	if a.mu.inUse != nil {
+		a.mu.inUse.Add(delta)
+		a.mu.idle.Sub(delta)
+	}
+	a.mu.Unlock()
 }
 
 func (a *connArray) Close() {
@@ -98,6 +152,13 @@ func (a *connArray) Close() {
 			a.v[i] = nil
 		}
 	}
+	a.mu.Lock()
+	if a.mu.inUse != nil {
+		a.mu.inUse.Set(0)
+		a.mu.idle.Set(0)
+		a.mu.inUse, a.mu.idle = nil, nil
+	}
+	a.mu.Unlock()
 }
 
 // TODO: Add flow control between RPC clients in TiDB ond RPC servers in TiKV.
@@ -117,11 +178,13 @@ func newRPCClient() *rpcClient {
 	}
 }
 
-func (c *rpcClient) getConn(addr string) (*grpc.ClientConn, error) {
+// getConn gets a connection to the addr, the returned function should be called
+// to release the connection when the request is finished.
+func (c *rpcClient) getConn(addr string) (*grpc.ClientConn, func(), error) {
 	c.RLock()
 	if c.isClosed {
 		c.RUnlock()
-		return nil, errors.Errorf("rpcClient is closed")
+		return nil, nil, errors.Errorf("rpcClient is closed")
 	}
 	array, ok := c.conns[addr]
 	c.RUnlock()
@@ -129,10 +192,11 @@ func (c *rpcClient) getConn(addr string) (*grpc.ClientConn, error) {
 		var err error
 		array, err = c.createConnArray(addr)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	return array.Get(), nil
+	conn, idx := array.Get()
+	return conn, func() { array.Put(idx) }, nil
 }
 
 func (c *rpcClient) createConnArray(addr string) (*connArray, error) {
@@ -146,6 +210,7 @@ func (c *rpcClient) createConnArray(addr string) (*connArray, error) {
 			return nil, err
 		}
 		c.conns[addr] = array
+		array.register(addr)
 	}
 	return array, nil
 }
@@ -171,10 +236,13 @@ func (c *rpcClient) SendReq(ctx goctx.Context, addr string, req *tikvrpc.Request
 	}
 	defer func() { sendReqHistogram.WithLabelValues(label).Observe(time.Since(start).Seconds()) }()
 
-	conn, err := c.getConn(addr)
+	getConnStart := time.Now()
+	conn, release, err := c.getConn(addr)
+	connPoolHistogram.WithLabelValues(label).Observe(time.Since(getConnStart).Seconds())
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	defer release()
 	client := tikvpb.NewTikvClient(conn)
 	resp, err := c.callRPC(ctx, client, req)
 	if err != nil {
@@ -14,9 +14,11 @@
 package tikv
 
 import (
+	"sync"
 	"testing"
 
 	. "github.com/pingcap/check"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestT(t *testing.T) {
@@ -33,15 +35,60 @@ func (s *testClientSuite) TestConn(c *C) {
 	client := newRPCClient()
 
 	addr := "127.0.0.1:6379"
-	conn1, err := client.getConn(addr)
+	conn1, release1, err := client.getConn(addr)
 	c.Assert(err, IsNil)
 
-	conn2, err := client.getConn(addr)
+	conn2, release2, err := client.getConn(addr)
 	c.Assert(err, IsNil)
 	c.Assert(conn2, Not(Equals), conn1)
+	release1()
+	release2()
 
 	client.Close()
-	conn3, err := client.getConn(addr)
+	conn3, _, err := client.getConn(addr)
 	c.Assert(err, NotNil)
 	c.Assert(conn3, IsNil)
 }
+
+func (s *testClientSuite) TestConnPoolMetrics(c *C) {
+	client := newRPCClient()
+
+	addr := "127.0.0.1:6380"
+	getGauge := func(tp string) float64 {
+		m := &dto.Metric{}
+		err := connPoolGauge.WithLabelValues(addr, tp).Write(m)
+		c.Assert(err, IsNil)
+		return m.GetGauge().GetValue()
+	}
+
+	var wg sync.WaitGroup
+	releases := make(chan func(), maxConnectionNumber*2)
+	for i := 0; i < maxConnectionNumber*2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, release, err := client.getConn(addr)
+			c.Assert(err, IsNil)
+			releases <- release
+		}()
+	}
+	wg.Wait()
+	close(releases)
+	// Every connection has in-flight requests.
+	c.Assert(getGauge(connPoolInUse), Equals, float64(maxConnectionNumber))
+	c.Assert(getGauge(connPoolIdle), Equals, float64(0))
+
+	for release := range releases {
+		release()
+	}
+	c.Assert(getGauge(connPoolInUse), Equals, float64(0))
+	c.Assert(getGauge(connPoolIdle), Equals, float64(maxConnectionNumber))
+
+	_, release, err := client.getConn(addr)
+	c.Assert(err, IsNil)
+	client.Close()
+	// The request finished after the client is closed doesn't change the gauges.
+	release()
+	c.Assert(getGauge(connPoolInUse), Equals, float64(0))
+	c.Assert(getGauge(connPoolIdle), Equals, float64(0))
+}
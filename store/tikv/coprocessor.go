@@ -29,6 +29,10 @@ import (
 	goctx "golang.org/x/net/context"
 )
 
+// MaxScanRegions is the max number of Regions a coprocessor request scans, the requests scanning more Regions
+// fail when the tasks are built, before any of them is sent. 0 means no limit.
+var MaxScanRegions = 0
+
 // CopClient is coprocessor client.
 type CopClient struct {
 	store *tikvStore
@@ -237,6 +241,9 @@ func buildCopTasks(bo *Backoffer, cache *RegionCache, ranges *copRanges, desc bo
 	}
 
 	for ranges.len() > 0 {
+		if MaxScanRegions > 0 && len(tasks) >= MaxScanRegions {
+			return nil, errors.Trace(kv.ErrTooManyScanRegions.GenByArgs(MaxScanRegions))
+		}
 		loc, err := cache.LocateKey(bo, ranges.at(0).StartKey)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -116,6 +116,26 @@ func (s *testCoprocessorSuite) TestRebuild(c *C) {
 	s.taskEqual(c, tasks[0], regionIDs[2], "q", "z")
 }
 
+func (s *testCoprocessorSuite) TestBuildTasksMaxScanRegions(c *C) {
+	// nil --- 'g' --- 'n' --- 't' --- nil
+	// <-  0  -> <- 1 -> <- 2 -> <- 3 ->
+	cluster := mocktikv.NewCluster()
+	mocktikv.BootstrapWithMultiRegions(cluster, []byte("g"), []byte("n"), []byte("t"))
+	pdCli := &codecPDClient{mocktikv.NewPDClient(cluster)}
+	cache := NewRegionCache(pdCli)
+	bo := NewBackoffer(3000, goctx.Background())
+
+	defer func(old int) { MaxScanRegions = old }(MaxScanRegions)
+	MaxScanRegions = 3
+	tasks, err := buildCopTasks(bo, cache, buildKeyRanges("a", "p"), false)
+	c.Assert(err, IsNil)
+	c.Assert(tasks, HasLen, 3)
+	_, err = buildCopTasks(bo, cache, buildKeyRanges("a", "x"), false)
+	c.Assert(kv.ErrTooManyScanRegions.Equal(err), IsTrue, Commentf("err %v", err))
+	_, err = buildCopTasks(bo, cache, buildKeyRanges("a", "b", "h", "i", "o", "p", "u", "v"), true)
+	c.Assert(kv.ErrTooManyScanRegions.Equal(err), IsTrue, Commentf("err %v", err))
+}
+
 func buildKeyRanges(keys ...string) *copRanges {
 	var ranges []kv.KeyRange
 	for i := 0; i < len(keys); i += 2 {
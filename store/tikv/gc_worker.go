@@ -96,6 +96,13 @@ const (
 	gcLifeTimeKey     = "tikv_gc_life_time"
 	gcDefaultLifeTime = time.Minute * 10
 	gcSafePointKey    = "tikv_gc_safe_point"
+
+	// gcServiceSafePointPrefix is the prefix of the variables which hold the safe point back for the services
+	// reading an old version for a long time, e.g. exporting a table, see RegisterGCServiceSafePoint.
+	gcServiceSafePointPrefix = "tikv_gc_service_safe_point_"
+	// gcMaxServiceSafePointAge is how long a service safe point holds GC back at most,
+	// in case the service quits without unregistering it.
+	gcMaxServiceSafePointAge = time.Hour * 24
 )
 
 var gcVariableComments = map[string]string{
@@ -271,6 +278,13 @@ func (w *GCWorker) calculateNewSafePoint(now time.Time) (*time.Time, error) {
 		return nil, errors.Trace(err)
 	}
 	safePoint := now.Add(-*lifeTime)
+	serviceSafePoint, err := w.loadMinServiceSafePoint(now)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if serviceSafePoint != nil && serviceSafePoint.Before(safePoint) {
+		safePoint = *serviceSafePoint
+	}
 	// We should never decrease safePoint.
 	if lastSafePoint != nil && safePoint.Before(*lastSafePoint) {
 		return nil, nil
@@ -670,6 +684,100 @@ func (w *GCWorker) saveValueToSysTable(key, value string) error {
 	return errors.Trace(err)
 }
 
+// loadMinServiceSafePoint loads the earliest service safe point, the expired ones are ignored.
+func (w *GCWorker) loadMinServiceSafePoint(now time.Time) (*time.Time, error) {
+	stmt := fmt.Sprintf(`SELECT variable_name, variable_value FROM mysql.tidb WHERE variable_name LIKE '%s%%'`, gcServiceSafePointPrefix)
+	rs, err := w.session.Execute(stmt)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer rs[0].Close()
+	var minSafePoint *time.Time
+	for {
+		row, err := rs[0].Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			return minSafePoint, nil
+		}
+		t, err := time.Parse(gcTimeFormat, row.Data[1].GetString())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if t.Add(gcMaxServiceSafePointAge).Before(now) {
+			log.Warnf("[gc worker] ignore the expired service safe point %s: %v", row.Data[0].GetString(), t)
+			continue
+		}
+		if minSafePoint == nil || t.Before(*minSafePoint) {
+			minSafePoint = &t
+		}
+	}
+}
+
+// RegisterGCServiceSafePoint keeps GC from collecting the versions after ts for the service,
+// until it's unregistered by UnregisterGCServiceSafePoint or it's older than 24 hours.
+// It fails if GC has collected the versions already.
+func RegisterGCServiceSafePoint(store kv.Storage, service string, ts uint64) error {
+	se, err := createGCServiceSession(store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer se.Close()
+	physical := oracle.ExtractPhysical(ts)
+	t := time.Unix(physical/1e3, (physical%1e3)*1e6)
+	stmt := fmt.Sprintf(`INSERT INTO mysql.tidb VALUES ('%[1]s', '%[2]s', 'GC safe point of the service. (DO NOT EDIT)')
+			       ON DUPLICATE KEY
+			       UPDATE variable_value = '%[2]s'`,
+		gcServiceSafePointPrefix+service, t.Format(gcTimeFormat))
+	if _, err = se.Execute(stmt); err != nil {
+		return errors.Trace(err)
+	}
+	// GC may have advanced the safe point before it's registered.
+	stmt = fmt.Sprintf(`SELECT variable_value FROM mysql.tidb WHERE variable_name = '%s'`, gcSafePointKey)
+	rs, err := se.Execute(stmt)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	row, err := rs[0].Next()
+	rs[0].Close()
+	if err != nil || row == nil {
+		return errors.Trace(err)
+	}
+	safePoint, err := time.Parse(gcTimeFormat, row.Data[0].GetString())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if safePoint.After(t) {
+		if _, err = se.Execute(fmt.Sprintf(`DELETE FROM mysql.tidb WHERE variable_name = '%s'`, gcServiceSafePointPrefix+service)); err != nil {
+			log.Warnf("[gc worker] unregister the service safe point %s failed: %v", service, err)
+		}
+		return errors.Errorf("GC life time is shorter than the service %s, safe point %v is after %v", service, safePoint, t)
+	}
+	return nil
+}
+
+// UnregisterGCServiceSafePoint removes the service safe point registered by RegisterGCServiceSafePoint.
+func UnregisterGCServiceSafePoint(store kv.Storage, service string) error {
+	se, err := createGCServiceSession(store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer se.Close()
+	_, err = se.Execute(fmt.Sprintf(`DELETE FROM mysql.tidb WHERE variable_name = '%s'`, gcServiceSafePointPrefix+service))
+	return errors.Trace(err)
+}
+
+func createGCServiceSession(store kv.Storage) (tidb.Session, error) {
+	se, err := tidb.CreateSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	privilege.BindPrivilegeManager(se, nil)
+	se.GetSessionVars().InRestrictedSQL = true
+	return se, nil
+}
+
 // MockGCWorker is for test.
 type MockGCWorker struct {
 	worker GCWorker
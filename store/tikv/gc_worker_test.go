@@ -19,6 +19,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/store/tikv/oracle"
 )
 
 type testGCWorkerSuite struct {
@@ -102,3 +103,37 @@ func (s *testGCWorkerSuite) TestPrepareGC(c *C) {
 	c.Assert(err, IsNil)
 	s.timeEqual(c, safePoint.Add(time.Minute*30), now, 2*time.Second)
 }
+
+func (s *testGCWorkerSuite) TestServiceSafePoint(c *C) {
+	now, err := s.gcWorker.getOracleTime()
+	c.Assert(err, IsNil)
+	// The service holds the safe point back to its version.
+	serviceTime := now.Add(-time.Minute * 5)
+	err = RegisterGCServiceSafePoint(s.store, "test", oracle.ComposeTS(oracle.GetPhysical(serviceTime), 0))
+	c.Assert(err, IsNil)
+	// The expired service safe points are ignored.
+	err = s.gcWorker.saveTime(gcServiceSafePointPrefix+"expired", now.Add(-gcMaxServiceSafePointAge-time.Hour))
+	c.Assert(err, IsNil)
+	s.oracle.addOffset(time.Minute * 20)
+	ok, _, err := s.gcWorker.prepare()
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	safePoint, err := s.gcWorker.loadTime(gcSafePointKey)
+	c.Assert(err, IsNil)
+	s.timeEqual(c, *safePoint, serviceTime, 2*time.Second)
+
+	c.Assert(UnregisterGCServiceSafePoint(s.store, "test"), IsNil)
+	s.oracle.addOffset(time.Minute * 20)
+	now, err = s.gcWorker.getOracleTime()
+	c.Assert(err, IsNil)
+	ok, _, err = s.gcWorker.prepare()
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	safePoint, err = s.gcWorker.loadTime(gcSafePointKey)
+	c.Assert(err, IsNil)
+	s.timeEqual(c, safePoint.Add(gcDefaultLifeTime), now, 2*time.Second)
+
+	// The versions before the safe point may have been collected.
+	err = RegisterGCServiceSafePoint(s.store, "test", oracle.ComposeTS(oracle.GetPhysical(serviceTime), 0))
+	c.Assert(err, NotNil)
+}
@@ -310,6 +310,34 @@ func (s *tikvStore) SupportDeleteRange() (supported bool) {
 	return true
 }
 
+// SupportSplitRegion implements the kv.SplitableStore interface.
+// Neither PD nor the TiKV RPC can split a region on demand now, a real TiKV cluster splits its regions by itself,
+// so only the mock cluster supports it.
+func (s *tikvStore) SupportSplitRegion() (supported bool) {
+	_, ok := s.client.(*mocktikv.RPCClient)
+	return ok
+}
+
+// SplitRegion implements the kv.SplitableStore interface.
+func (s *tikvStore) SplitRegion(splitKey kv.Key) error {
+	client, ok := s.client.(*mocktikv.RPCClient)
+	if !ok {
+		return errors.New("split region is not supported by the store")
+	}
+	bo := NewBackoffer(splitRegionMaxBackoff, goctx.Background())
+	loc, err := s.regionCache.LocateKey(bo, splitKey)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if kv.Key(loc.StartKey).Cmp(splitKey) == 0 {
+		return nil
+	}
+	newRegionID, peerID := client.Cluster.AllocID(), client.Cluster.AllocID()
+	client.Cluster.Split(loc.Region.id, newRegionID, splitKey, []uint64{peerID}, peerID)
+	s.regionCache.DropRegion(loc.Region)
+	return nil
+}
+
 func (s *tikvStore) SendReq(bo *Backoffer, req *tikvrpc.Request, regionID RegionVerID, timeout time.Duration) (*tikvrpc.Response, error) {
 	sender := NewRegionRequestSender(s.regionCache, s.client, kvrpcpb.IsolationLevel_SI)
 	return sender.SendReq(bo, req, regionID, timeout)
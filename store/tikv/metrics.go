@@ -15,6 +15,7 @@ package tikv
 
 import (
 	"github.com/pingcap/kvproto/pkg/errorpb"
+	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -78,6 +79,14 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 18),
 		}, []string{"type"})
 
+	connPoolGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "conn_pool_conns",
+			Help:      "Number of in-use and idle connections in conn pool.",
+		}, []string{"store", "type"})
+
 	sendReqHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "tidb",
@@ -182,6 +191,30 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 21),
 		}, []string{"type"})
 
+	regionCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "region_cache_operations_total",
+			Help:      "Counter of region cache hits, misses, expirations and invalidations.",
+		}, []string{"type"})
+
+	kvReadBytesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "read_bytes_total",
+			Help:      "Counter of bytes read from the kv store, which are the sizes of the responses of the read requests.",
+		}, []string{"type"})
+
+	kvWriteBytesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "tikvclient",
+			Name:      "write_bytes_total",
+			Help:      "Counter of bytes written to the kv store, which are the sizes of the write requests.",
+		}, []string{"type"})
+
 	txnRegionsNumHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "tidb",
@@ -212,6 +245,33 @@ func reportRegionError(e *errorpb.Error) {
 	}
 }
 
+// reportKVBytes adds the size of the response of a read request to kvReadBytesCounter, or the size of
+// a write request to kvWriteBytesCounter. The labels are the fixed command names.
+func reportKVBytes(req *tikvrpc.Request, resp *tikvrpc.Response) {
+	switch req.Type {
+	case tikvrpc.CmdGet:
+		kvReadBytesCounter.WithLabelValues("get").Add(float64(resp.Get.Size()))
+	case tikvrpc.CmdBatchGet:
+		kvReadBytesCounter.WithLabelValues("batch_get").Add(float64(resp.BatchGet.Size()))
+	case tikvrpc.CmdScan:
+		kvReadBytesCounter.WithLabelValues("scan").Add(float64(resp.Scan.Size()))
+	case tikvrpc.CmdCop:
+		kvReadBytesCounter.WithLabelValues("cop").Add(float64(resp.Cop.Size()))
+	case tikvrpc.CmdRawGet:
+		kvReadBytesCounter.WithLabelValues("raw_get").Add(float64(resp.RawGet.Size()))
+	case tikvrpc.CmdRawScan:
+		kvReadBytesCounter.WithLabelValues("raw_scan").Add(float64(resp.RawScan.Size()))
+	case tikvrpc.CmdPrewrite:
+		kvWriteBytesCounter.WithLabelValues("prewrite").Add(float64(req.Prewrite.Size()))
+	case tikvrpc.CmdCommit:
+		kvWriteBytesCounter.WithLabelValues("commit").Add(float64(req.Commit.Size()))
+	case tikvrpc.CmdRawPut:
+		kvWriteBytesCounter.WithLabelValues("raw_put").Add(float64(req.RawPut.Size()))
+	case tikvrpc.CmdRawDelete:
+		kvWriteBytesCounter.WithLabelValues("raw_delete").Add(float64(req.RawDelete.Size()))
+	}
+}
+
 func init() {
 	prometheus.MustRegister(txnCounter)
 	prometheus.MustRegister(snapshotCounter)
@@ -221,6 +281,7 @@ func init() {
 	prometheus.MustRegister(backoffHistogram)
 	prometheus.MustRegister(sendReqHistogram)
 	prometheus.MustRegister(connPoolHistogram)
+	prometheus.MustRegister(connPoolGauge)
 	prometheus.MustRegister(coprocessorCounter)
 	prometheus.MustRegister(coprocessorHistogram)
 	prometheus.MustRegister(gcWorkerCounter)
@@ -233,4 +294,7 @@ func init() {
 	prometheus.MustRegister(rawkvCmdHistogram)
 	prometheus.MustRegister(rawkvSizeHistogram)
 	prometheus.MustRegister(txnRegionsNumHistogram)
+	prometheus.MustRegister(regionCacheCounter)
+	prometheus.MustRegister(kvReadBytesCounter)
+	prometheus.MustRegister(kvWriteBytesCounter)
 }
@@ -16,6 +16,7 @@ package tikv
 import (
 	"bytes"
 	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
@@ -23,12 +24,19 @@ import (
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/pd-client"
+	"github.com/pingcap/tidb/kv"
 	goctx "golang.org/x/net/context"
 )
 
+// RegionCacheTTL is the duration a Region is cached, the Regions cached longer than it are reloaded
+// from PD when they're located, so the changes of the cluster not reported by region errors are picked up.
+// 0 means the Regions are cached until they're invalidated by the errors.
+var RegionCacheTTL = 10 * time.Minute
+
 // RegionCache caches Regions loaded from PD.
 type RegionCache struct {
 	pdClient pd.Client
+	ttl      time.Duration
 	mu       struct {
 		sync.RWMutex
 		regions map[RegionVerID]*Region
@@ -44,6 +52,7 @@ type RegionCache struct {
 func NewRegionCache(pdClient pd.Client) *RegionCache {
 	c := &RegionCache{
 		pdClient: pdClient,
+		ttl:      RegionCacheTTL,
 	}
 	c.mu.regions = make(map[RegionVerID]*Region)
 	c.mu.sorted = llrb.New()
@@ -110,16 +119,18 @@ func (l *KeyLocation) Contains(key []byte) bool {
 // LocateKey searches for the region and range that the key is located.
 func (c *RegionCache) LocateKey(bo *Backoffer, key []byte) (*KeyLocation, error) {
 	c.mu.RLock()
-	if r := c.getRegionFromCache(key); r != nil {
+	if r := c.getRegionFromCache(key); r != nil && !c.expired(r) {
 		loc := &KeyLocation{
 			Region:   r.VerID(),
 			StartKey: r.StartKey(),
 			EndKey:   r.EndKey(),
 		}
 		c.mu.RUnlock()
+		regionCacheCounter.WithLabelValues("hit").Inc()
 		return loc, nil
 	}
 	c.mu.RUnlock()
+	regionCacheCounter.WithLabelValues("miss").Inc()
 
 	r, err := c.loadRegion(bo, key)
 	if err != nil {
@@ -139,16 +150,18 @@ func (c *RegionCache) LocateKey(bo *Backoffer, key []byte) (*KeyLocation, error)
 // LocateRegionByID searches for the region with ID
 func (c *RegionCache) LocateRegionByID(bo *Backoffer, regionID uint64) (*KeyLocation, error) {
 	c.mu.RLock()
-	if r := c.getRegionByIDFromCache(regionID); r != nil {
+	if r := c.getRegionByIDFromCache(regionID); r != nil && !c.expired(r) {
 		loc := &KeyLocation{
 			Region:   r.VerID(),
 			StartKey: r.StartKey(),
 			EndKey:   r.EndKey(),
 		}
 		c.mu.RUnlock()
+		regionCacheCounter.WithLabelValues("hit").Inc()
 		return loc, nil
 	}
 	c.mu.RUnlock()
+	regionCacheCounter.WithLabelValues("miss").Inc()
 
 	r, err := c.loadRegionByID(bo, regionID)
 	if err != nil {
@@ -205,6 +218,24 @@ func (c *RegionCache) ListRegionIDsInKeyRange(bo *Backoffer, startKey, endKey []
 	return regionIDs, nil
 }
 
+// SplitKeyRangeByRegions splits [startKey, endKey) into the ranges that each is located in one region.
+// An empty endKey means the range is not bounded.
+func (c *RegionCache) SplitKeyRangeByRegions(bo *Backoffer, startKey, endKey []byte) ([]kv.KeyRange, error) {
+	var ranges []kv.KeyRange
+	for {
+		loc, err := c.LocateKey(bo, startKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(loc.EndKey) == 0 || (len(endKey) > 0 && bytes.Compare(endKey, loc.EndKey) <= 0) {
+			ranges = append(ranges, kv.KeyRange{StartKey: startKey, EndKey: endKey})
+			return ranges, nil
+		}
+		ranges = append(ranges, kv.KeyRange{StartKey: startKey, EndKey: loc.EndKey})
+		startKey = loc.EndKey
+	}
+}
+
 // DropRegion removes a cached Region.
 func (c *RegionCache) DropRegion(id RegionVerID) {
 	c.mu.Lock()
@@ -242,15 +273,24 @@ func (c *RegionCache) getRegionFromCache(key []byte) *Region {
 	return nil
 }
 
+// expired checks whether the Region has been cached longer than the TTL.
+func (c *RegionCache) expired(r *Region) bool {
+	return c.ttl > 0 && time.Since(r.loadTime) > c.ttl
+}
+
 // insertRegionToCache tries to insert the Region to cache. If there is an old
-// Region with the same VerID, it will return the old one instead.
+// Region with the same VerID, it will return the old one instead unless the old one is expired.
 func (c *RegionCache) insertRegionToCache(r *Region) *Region {
-	if old, ok := c.mu.regions[r.VerID()]; ok {
+	if old, ok := c.mu.regions[r.VerID()]; ok && !c.expired(old) {
 		return old
 	}
 	old := c.mu.sorted.ReplaceOrInsert(newRBItem(r))
 	if old != nil {
-		delete(c.mu.regions, old.(*llrbItem).region.VerID())
+		oldRegion := old.(*llrbItem).region
+		if c.expired(oldRegion) {
+			regionCacheCounter.WithLabelValues("expired").Inc()
+		}
+		delete(c.mu.regions, oldRegion.VerID())
 	}
 	c.mu.regions[r.VerID()] = r
 	return r
@@ -273,6 +313,7 @@ func (c *RegionCache) dropRegionFromCache(verID RegionVerID) {
 	}
 	c.mu.sorted.Delete(newRBItem(r))
 	delete(c.mu.regions, r.VerID())
+	regionCacheCounter.WithLabelValues("invalidated").Inc()
 }
 
 // loadRegion loads region from pd client, and picks the first peer as leader.
@@ -299,8 +340,9 @@ func (c *RegionCache) loadRegion(bo *Backoffer, key []byte) (*Region, error) {
 			return nil, errors.New("receive Region with no peer")
 		}
 		region := &Region{
-			meta: meta,
-			peer: meta.Peers[0],
+			meta:     meta,
+			peer:     meta.Peers[0],
+			loadTime: time.Now(),
 		}
 		if leader != nil {
 			region.SwitchPeer(leader.GetStoreId())
@@ -333,8 +375,9 @@ func (c *RegionCache) loadRegionByID(bo *Backoffer, regionID uint64) (*Region, e
 			return nil, errors.New("receive Region with no peer")
 		}
 		region := &Region{
-			meta: meta,
-			peer: meta.Peers[0],
+			meta:     meta,
+			peer:     meta.Peers[0],
+			loadTime: time.Now(),
 		}
 		if leader != nil {
 			region.SwitchPeer(leader.GetStoreId())
@@ -441,8 +484,9 @@ func (c *RegionCache) OnRegionStale(ctx *RPCContext, newRegions []*metapb.Region
 			}
 		}
 		region := &Region{
-			meta: meta,
-			peer: meta.Peers[0],
+			meta:     meta,
+			peer:     meta.Peers[0],
+			loadTime: time.Now(),
 		}
 		region.SwitchPeer(ctx.KVCtx.GetPeer().GetStoreId())
 		c.insertRegionToCache(region)
@@ -494,6 +538,8 @@ type Region struct {
 	meta              *metapb.Region
 	peer              *metapb.Peer
 	unreachableStores []uint64
+	// loadTime is when the Region is loaded from PD or the region error.
+	loadTime time.Time
 }
 
 // GetID returns id.
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/mock-tikv"
 	goctx "golang.org/x/net/context"
 )
@@ -225,6 +226,55 @@ func (s *testRegionCacheSuite) TestMerge(c *C) {
 	s.checkCache(c, 1)
 }
 
+// expireCache makes the cached regions look loaded before the TTL.
+func (s *testRegionCacheSuite) expireCache() {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	for _, r := range s.cache.mu.regions {
+		r.loadTime = time.Now().Add(-2 * s.cache.ttl)
+	}
+}
+
+func (s *testRegionCacheSuite) TestRegionCacheTTL(c *C) {
+	s.cache.ttl = time.Minute
+	c.Assert(s.getAddr(c, []byte("a")), Equals, s.storeAddr(s.store1))
+
+	// Move the region from store1 to store3 without any error reported.
+	store3 := s.cluster.AllocID()
+	peer3 := s.cluster.AllocID()
+	s.cluster.AddStore(store3, s.storeAddr(store3))
+	s.cluster.AddPeer(s.region1, store3, peer3)
+	s.cluster.ChangeLeader(s.region1, peer3)
+	s.cluster.RemovePeer(s.region1, s.peer1)
+	// The cached region is used until it's expired.
+	c.Assert(s.getAddr(c, []byte("a")), Equals, s.storeAddr(s.store1))
+	s.expireCache()
+	c.Assert(s.getAddr(c, []byte("a")), Equals, s.storeAddr(store3))
+	s.checkCache(c, 1)
+
+	// split to ['' - 'm' - 'z']
+	region2 := s.cluster.AllocID()
+	newPeers := s.cluster.AllocIDs(2)
+	s.cluster.Split(s.region1, region2, []byte("m"), newPeers, newPeers[0])
+	loc, err := s.cache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(loc.EndKey, HasLen, 0)
+	s.expireCache()
+	loc, err = s.cache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(loc.Region.id, Equals, s.region1)
+	c.Assert(loc.EndKey, DeepEquals, []byte("m"))
+	s.checkCache(c, 1)
+
+	// The regions are never expired if the TTL is 0.
+	s.expireCache()
+	s.cache.ttl = 0
+	s.cluster.Merge(s.region1, region2)
+	loc, err = s.cache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(loc.EndKey, DeepEquals, []byte("m"))
+}
+
 func (s *testRegionCacheSuite) TestReconnect(c *C) {
 	loc, err := s.cache.LocateKey(s.bo, []byte("a"))
 	c.Assert(err, IsNil)
@@ -318,3 +368,29 @@ func (s *testRegionCacheSuite) TestListRegionIDsInCache(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(regionIDs, DeepEquals, []uint64{s.region1, region2})
 }
+
+func (s *testRegionCacheSuite) TestSplitKeyRangeByRegions(c *C) {
+	// ['' - 'm' - 'z']
+	region2 := s.cluster.AllocID()
+	newPeers := s.cluster.AllocIDs(2)
+	s.cluster.Split(s.region1, region2, []byte("m"), newPeers, newPeers[0])
+
+	ranges, err := s.cache.SplitKeyRangeByRegions(s.bo, []byte("a"), []byte("z"))
+	c.Assert(err, IsNil)
+	c.Assert(ranges, DeepEquals, []kv.KeyRange{
+		{StartKey: []byte("a"), EndKey: []byte("m")},
+		{StartKey: []byte("m"), EndKey: []byte("z")},
+	})
+	ranges, err = s.cache.SplitKeyRangeByRegions(s.bo, []byte("a"), []byte("m"))
+	c.Assert(err, IsNil)
+	c.Assert(ranges, DeepEquals, []kv.KeyRange{{StartKey: []byte("a"), EndKey: []byte("m")}})
+	ranges, err = s.cache.SplitKeyRangeByRegions(s.bo, []byte("n"), nil)
+	c.Assert(err, IsNil)
+	c.Assert(ranges, DeepEquals, []kv.KeyRange{{StartKey: []byte("n"), EndKey: nil}})
+	ranges, err = s.cache.SplitKeyRangeByRegions(s.bo, []byte("a"), nil)
+	c.Assert(err, IsNil)
+	c.Assert(ranges, DeepEquals, []kv.KeyRange{
+		{StartKey: []byte("a"), EndKey: []byte("m")},
+		{StartKey: []byte("m"), EndKey: nil},
+	})
+}
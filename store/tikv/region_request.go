@@ -116,6 +116,7 @@ func (s *RegionRequestSender) sendReqToRegion(bo *Backoffer, ctx *RPCContext, re
 		}
 		return nil, true, nil
 	}
+	reportKVBytes(req, resp)
 	return
 }
 
@@ -27,6 +27,8 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	goctx "golang.org/x/net/context"
 )
 
@@ -390,3 +392,37 @@ func (s *testStoreSuite) TestRequestPriority(c *C) {
 	}
 	iter.Close()
 }
+
+func (s *testStoreSuite) TestKVBytesMetrics(c *C) {
+	getCounter := func(counter *prometheus.CounterVec, tp string) float64 {
+		m := &dto.Metric{}
+		err := counter.WithLabelValues(tp).Write(m)
+		c.Assert(err, IsNil)
+		return m.GetCounter().GetValue()
+	}
+	prewrite := getCounter(kvWriteBytesCounter, "prewrite")
+	commit := getCounter(kvWriteBytesCounter, "commit")
+	get := getCounter(kvReadBytesCounter, "get")
+	scan := getCounter(kvReadBytesCounter, "scan")
+
+	value := make([]byte, 1024)
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	err = txn.Set([]byte("bytes_key"), value)
+	c.Assert(err, IsNil)
+	err = txn.Commit()
+	c.Assert(err, IsNil)
+	// The prewrite request carries the value.
+	c.Assert(getCounter(kvWriteBytesCounter, "prewrite")-prewrite, Greater, float64(len(value)))
+	c.Assert(getCounter(kvWriteBytesCounter, "commit"), Greater, commit)
+
+	txn, err = s.store.Begin()
+	c.Assert(err, IsNil)
+	_, err = txn.Get([]byte("bytes_key"))
+	c.Assert(err, IsNil)
+	c.Assert(getCounter(kvReadBytesCounter, "get")-get, Greater, float64(len(value)))
+	iter, err := txn.Seek([]byte("bytes_key"))
+	c.Assert(err, IsNil)
+	iter.Close()
+	c.Assert(getCounter(kvReadBytesCounter, "scan")-scan, Greater, float64(len(value)))
+}
@@ -197,6 +197,14 @@ func (txn *tikvTxn) Valid() bool {
 	return txn.valid
 }
 
+func (txn *tikvTxn) Checkpoint() int {
+	return txn.us.Checkpoint()
+}
+
+func (txn *tikvTxn) RollbackToCheckpoint(cp int) {
+	txn.us.RollbackToCheckpoint(cp)
+}
+
 func (txn *tikvTxn) Len() int {
 	return txn.us.Len()
 }
@@ -341,7 +341,13 @@ func getColDefaultValueFromNil(ctx context.Context, col *model.ColumnInfo) (type
 		// TODO: add warning.
 		return GetZeroValue(col), nil
 	}
-	return types.Datum{}, errNoDefaultValue.Gen("Field '%s' doesn't have a default value", col.Name)
+	err := errNoDefaultValue.Gen("Field '%s' doesn't have a default value", col.Name)
+	if sc := ctx.GetSessionVars().StmtCtx; sc.BadNullAsWarning {
+		// INSERT IGNORE uses zero value with a warning.
+		sc.AppendWarning(err)
+		return GetZeroValue(col), nil
+	}
+	return types.Datum{}, err
 }
 
 // GetZeroValue gets zero value for given column type.
@@ -53,8 +53,12 @@ var (
 	ErrIndexStateCantNone = terror.ClassTable.New(codeIndexStateCantNone, "index can not be in none state")
 	// ErrInvalidRecordKey returns for invalid record key.
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
+	// ErrRowIDOverflow returns for the row ID exceeding the range left by the shard bits.
+	ErrRowIDOverflow = terror.ClassTable.New(codeRowIDOverflow, "row id %d overflows with %d shard bits")
 	// ErrTruncateWrongValue returns for truncate wrong value for field.
 	ErrTruncateWrongValue = terror.ClassTable.New(codeTruncateWrongValue, "Incorrect value")
+	// ErrCheckConstraintViolated returns for the row violating a check constraint.
+	ErrCheckConstraintViolated = terror.ClassTable.New(codeCheckConstraintViolated, mysql.MySQLErrName[mysql.ErrCheckConstraintViolated])
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -144,12 +148,15 @@ const (
 	codeColumnStateNonPublic = 7
 	codeIndexStateCantNone   = 8
 	codeInvalidRecordKey     = 9
+	codeRowIDOverflow        = 10
 
 	codeColumnCantNull     = 1048
 	codeUnknownColumn      = 1054
 	codeDuplicateColumn    = 1110
 	codeNoDefaultValue     = 1364
 	codeTruncateWrongValue = 1366
+
+	codeCheckConstraintViolated = 3819
 )
 
 // Slice is used for table sorting.
@@ -165,11 +172,12 @@ func (s Slice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
 func init() {
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeColumnCantNull:     mysql.ErrBadNull,
-		codeUnknownColumn:      mysql.ErrBadField,
-		codeDuplicateColumn:    mysql.ErrFieldSpecifiedTwice,
-		codeNoDefaultValue:     mysql.ErrNoDefaultForField,
-		codeTruncateWrongValue: mysql.ErrTruncatedWrongValueForField,
+		codeColumnCantNull:          mysql.ErrBadNull,
+		codeUnknownColumn:           mysql.ErrBadField,
+		codeDuplicateColumn:         mysql.ErrFieldSpecifiedTwice,
+		codeNoDefaultValue:          mysql.ErrNoDefaultForField,
+		codeTruncateWrongValue:      mysql.ErrTruncatedWrongValueForField,
+		codeCheckConstraintViolated: mysql.ErrCheckConstraintViolated,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
@@ -18,6 +18,8 @@
 package tables
 
 import (
+	"encoding/binary"
+	"hash/fnv"
 	"strings"
 
 	"github.com/juju/errors"
@@ -28,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util"
@@ -231,7 +234,12 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 
 	for _, col := range t.WritableCols() {
 		var value types.Datum
-		if col.State != model.StatePublic {
+		if col.ChangingFrom != 0 {
+			value, err = t.changingColumnValue(col, newData)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		} else if col.State != model.StatePublic {
 			// If col is in write only or write reorganization state
 			// and the value is not default, keep the original value.
 			value, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
@@ -320,14 +328,20 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 			break
 		}
 	}
+	txn := ctx.Txn()
 	if !hasRecordID {
 		recordID, err = t.alloc.Alloc(t.ID)
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
+		if t.meta.ShardRowIDBits > 0 {
+			recordID, err = shardRowID(recordID, t.meta.ShardRowIDBits, txn.StartTS())
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+		}
 	}
 
-	txn := ctx.Txn()
 	bs := kv.NewBufferStore(txn)
 
 	skipCheck := ctx.GetSessionVars().SkipConstraintCheck
@@ -348,7 +362,12 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 
 	for _, col := range t.WritableCols() {
 		var value types.Datum
-		if col.State != model.StatePublic {
+		if col.ChangingFrom != 0 {
+			value, err = t.changingColumnValue(col, r)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+		} else if col.State != model.StatePublic {
 			// If col is in write only or write reorganization state, we must add it with its default value.
 			value, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
 			if err != nil {
@@ -705,6 +724,21 @@ func GetColDefaultValue(ctx context.Context, col *table.Column, defaultVals []ty
 	return colVal, nil
 }
 
+// shardRowID puts a shard derived from startTS into the high bits of the row ID below the sign bit,
+// so the rows written by different transactions are scattered over 2^shardBits ranges.
+func shardRowID(recordID int64, shardBits uint64, startTS uint64) (int64, error) {
+	shiftBits := 64 - shardBits - 1
+	if recordID >= int64(1)<<shiftBits {
+		return 0, table.ErrRowIDOverflow.GenByArgs(recordID, shardBits)
+	}
+	h := fnv.New64()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], startTS)
+	h.Write(b[:])
+	shard := int64(h.Sum64() & (1<<shardBits - 1))
+	return shard<<shiftBits | recordID, nil
+}
+
 // AllocAutoID implements table.Table AllocAutoID interface.
 func (t *Table) AllocAutoID() (int64, error) {
 	return t.alloc.Alloc(t.ID)
@@ -754,6 +788,18 @@ func (t *Table) getMutation(ctx context.Context) *binlog.TableMutation {
 	return &bin.Mutations[idx]
 }
 
+// changingColumnValue converts the value of the column whose type is being changed to the type of the
+// changing column. The type change is checked in strict mode, the value is rejected if it doesn't fit.
+func (t *Table) changingColumnValue(col *table.Column, r []types.Datum) (types.Datum, error) {
+	for _, origin := range t.Cols() {
+		if origin.ID == col.ChangingFrom {
+			value, err := r[origin.Offset].ConvertTo(&variable.StatementContext{}, &col.FieldType)
+			return value, errors.Trace(err)
+		}
+	}
+	return types.Datum{}, errors.Errorf("column %d is not found", col.ChangingFrom)
+}
+
 // canSkip is for these cases, we can skip the columns in encoded row:
 // 1. the column is included in primary key;
 // 2. the column's default value is null, and the value equals to that;
@@ -35,9 +35,13 @@ import (
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/server"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore/boltdb"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/topsql"
 	"github.com/pingcap/tipb/go-binlog"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
@@ -45,32 +49,71 @@ import (
 )
 
 var (
-	version             = flagBoolean("V", false, "print version information and exit")
-	store               = flag.String("store", "goleveldb", "registered store name, [memory, goleveldb, boltdb, tikv, mocktikv]")
-	storePath           = flag.String("path", "/tmp/tidb", "tidb storage path")
-	logLevel            = flag.String("L", "info", "log level: info, debug, warn, error, fatal")
-	host                = flag.String("host", "0.0.0.0", "tidb server host")
-	port                = flag.String("P", "4000", "tidb server port")
-	statusPort          = flag.String("status", "10080", "tidb server status port")
-	ddlLease            = flag.String("lease", "10s", "schema lease duration, very dangerous to change only if you know what you do")
-	statsLease          = flag.String("statsLease", "3s", "stats lease duration, which inflences the time of analyze and stats load.")
-	socket              = flag.String("socket", "", "The socket file to use for connection.")
-	enablePS            = flagBoolean("perfschema", false, "If enable performance schema.")
-	enablePrivilege     = flagBoolean("privilege", true, "If enable privilege check feature. This flag will be removed in the future.")
-	reportStatus        = flagBoolean("report-status", true, "If enable status report HTTP service.")
-	logFile             = flag.String("log-file", "", "log file path")
-	joinCon             = flag.Int("join-concurrency", 5, "the number of goroutines that participate joining.")
-	crossJoin           = flagBoolean("cross-join", true, "whether support cartesian product or not.")
-	metricsAddr         = flag.String("metrics-addr", "", "prometheus pushgateway address, leaves it empty will disable prometheus push.")
-	metricsInterval     = flag.Int("metrics-interval", 15, "prometheus client push interval in second, set \"0\" to disable prometheus push.")
-	binlogSocket        = flag.String("binlog-socket", "", "socket file to write binlog")
-	runDDL              = flagBoolean("run-ddl", true, "run ddl worker on this tidb-server")
-	retryLimit          = flag.Int("retry-limit", 10, "the maximum number of retries when commit a transaction")
-	skipGrantTable      = flagBoolean("skip-grant-table", false, "This option causes the server to start without using the privilege system at all.")
-	slowThreshold       = flag.Int("slow-threshold", 300, "Queries with execution time greater than this value will be logged. (Milliseconds)")
-	queryLogMaxlen      = flag.Int("query-log-max-len", 2048, "Maximum query length recorded in log")
-	tcpKeepAlive        = flagBoolean("tcp-keep-alive", false, "set keep alive option for tcp connection.")
-	timeJumpBackCounter = prometheus.NewCounter(
+	version               = flagBoolean("V", false, "print version information and exit")
+	store                 = flag.String("store", "goleveldb", "registered store name, [memory, goleveldb, boltdb, tikv, mocktikv]")
+	storePath             = flag.String("path", "/tmp/tidb", "tidb storage path")
+	logLevel              = flag.String("L", "info", "log level: info, debug, warn, error, fatal")
+	host                  = flag.String("host", "0.0.0.0", "tidb server host")
+	port                  = flag.String("P", "4000", "tidb server port")
+	statusPort            = flag.String("status", "10080", "tidb server status port")
+	ddlLease              = flag.String("lease", "10s", "schema lease duration, very dangerous to change only if you know what you do")
+	statsLease            = flag.String("statsLease", "3s", "stats lease duration, which inflences the time of analyze and stats load.")
+	socket                = flag.String("socket", "", "The socket file to use for connection.")
+	enablePS              = flagBoolean("perfschema", false, "If enable performance schema.")
+	enablePrivilege       = flagBoolean("privilege", true, "If enable privilege check feature. This flag will be removed in the future.")
+	reportStatus          = flagBoolean("report-status", true, "If enable status report HTTP service.")
+	statusSSLCert         = flag.String("status-ssl-cert", "", "the certificate file to serve the status service over HTTPS, it's served in plaintext if it's empty.")
+	statusSSLKey          = flag.String("status-ssl-key", "", "the private key file of status-ssl-cert.")
+	statusSSLCA           = flag.String("status-ssl-ca", "", "the CA file to verify the client certificates of the status service, the clients must present certificates signed by it if it's not empty.")
+	logFile               = flag.String("log-file", "", "log file path")
+	joinCon               = flag.Int("join-concurrency", 5, "the number of goroutines that participate joining.")
+	hashJoinConcurrency   = flag.Int("hash-join-concurrency", 0, "the default value of tidb_hash_join_concurrency, the number of goroutines a hash join probes with, set \"0\" to use join-concurrency.")
+	projectionConcurrency = flag.Int("projection-concurrency", variable.DefProjectionConcurrency, "the default value of tidb_projection_concurrency, the number of goroutines a projection evaluates its expressions with.")
+	indexLookupSize       = flag.Int("index-lookup-size", variable.DefIndexLookupSize, "the default value of tidb_index_lookup_size, it's saved as the global value when the store is bootstrapped.")
+	buildStatsConcurrency = flag.Int("build-stats-concurrency", variable.DefBuildStatsConcurrency, "the default value of tidb_build_stats_concurrency, the number of tables and indices ANALYZE builds statistics for concurrently.")
+	crossJoin             = flagBoolean("cross-join", true, "whether support cartesian product or not.")
+	maxJoinTables         = flag.Int("max-join-tables", plan.MaxJoinTables, "the maximum number of tables in a join, the queries joining more tables are rejected, set \"0\" to disable the limit.")
+	metricsAddr           = flag.String("metrics-addr", "", "prometheus pushgateway address, leaves it empty will disable prometheus push.")
+	metricsInterval       = flag.Int("metrics-interval", 15, "prometheus client push interval in second, set \"0\" to disable prometheus push.")
+	binlogSocket          = flag.String("binlog-socket", "", "socket file to write binlog")
+	runDDL                = flagBoolean("run-ddl", true, "run ddl worker on this tidb-server")
+	ddlHistoryLimit       = flag.Int64("ddl-history-limit", 0, "the maximum number of history DDL jobs retained, the older ones are pruned in background, set \"0\" to disable pruning.")
+	retryLimit            = flag.Int("retry-limit", 10, "the maximum number of retries when commit a transaction")
+	skipGrantTable        = flagBoolean("skip-grant-table", false, "This option causes the server to start without using the privilege system at all.")
+	slowThreshold         = flag.Int("slow-threshold", 300, "Queries with execution time greater than this value will be logged. (Milliseconds)")
+	queryLogMaxlen        = flag.Int("query-log-max-len", 2048, "deprecated, use log-query-max-len instead.")
+	logQueryMaxLen        = flag.Int("log-query-max-len", 2048, "the SQL text written to the slow query log and the general log is truncated to this number of characters with an ellipsis, set \"0\" to disable truncation.")
+	logMessageMaxLen      = flag.Int("log-message-max-len", 0, "the log entries longer than this number of bytes are truncated with an ellipsis, set \"0\" to disable truncation.")
+	logRedactLiterals     = flagBoolean("log-redact-literals", false, "replace the literal values in the SQL text written to the slow query log and the general log with '?'.")
+	tcpKeepAlive          = flagBoolean("tcp-keep-alive", false, "set keep alive option for tcp connection.")
+	dumpDir               = flag.String("dump-dir", "", "the directory to write the profiles requested by the status API /status/debug/dump, the system temporary directory is used if it's empty.")
+	handshakeTimeout      = flag.String("handshake-timeout", "10s", "the connection is closed if the client doesn't finish the handshake within this duration, set \"0\" to disable it.")
+	idleInTxnTimeout      = flag.String("idle-in-transaction-timeout", "0", "the connection is closed and its transaction is rolled back if it's idle in the transaction longer than this duration, set \"0\" to disable it.")
+	forceTextProtocol     = flagBoolean("force-text-protocol", false, "encode the result sets of prepared statements in text protocol, for the clients which mis-handle the binary protocol.")
+	enableGlobalKill      = flagBoolean("enable-global-kill", false, "allocate connection IDs unique among the tidb-servers sharing the store, so KILL can be sent to any tidb-server. The connection ID has the highest bit set, with the server ID in the next 11 bits and the local connection ID in the lowest 20 bits.")
+	storeConnectTimeout   = flag.String("store-connect-timeout", "0", "the server fails to start if the store isn't opened within this duration, e.g. the tikv or pd servers are unreachable, set \"0\" to wait forever.")
+	logConnections        = flagBoolean("log-connections", false, "log every connection when it's established and closed, with the user, host, connection ID, duration and the reason of closing.")
+	disallowEmptyPassword = flagBoolean("disallow-empty-password", false, "reject the login of the users with empty passwords, and creating users or setting passwords with empty passwords.")
+	maxPreparedStmtCount  = flag.Int("max-prepared-stmt-count", config.DefMaxPreparedStmtCount, "the max number of the statements prepared by COM_STMT_PREPARE and not closed in all the connections, set \"0\" to disable the limit.")
+	maxPreparedPerConn    = flag.Int("max-prepared-stmt-count-per-conn", 0, "the max number of the statements prepared by COM_STMT_PREPARE and not closed in a connection, set \"0\" to disable the limit.")
+	maxPreparedParams     = flag.Int("max-prepared-stmt-params", config.DefMaxPreparedStmtParams, "the max number of the placeholders in a prepared statement, in [1, 65535].")
+	exportDir             = flag.String("export-dir", "", "the directory to write the CSV files of the tables exported by the status API /export/{db}/{table}, the API is disabled if it's empty.")
+	exportConcurrency     = flag.Int("export-concurrency", 4, "the max number of key ranges that are exported concurrently by an export request.")
+	sqlLogDir             = flag.String("sql-log-dir", "", "the directory to write the files of ADMIN ENABLE LOG FOR CONNECTION, the statement is disabled if it's empty.")
+	generalLog            = flagBoolean("general-log", false, "write the statements received from all the connections to the general log before they're executed, the sessions can also turn on the general_log variable to write their own statements.")
+	generalLogFile        = flag.String("general-log-file", "", "the file the general log is appended to, the server log is used if it's empty.")
+	generalLogSampleRate  = flag.Float64("general-log-sample-rate", 1, "the fraction of the statements written to the general log, in (0, 1].")
+	memQuotaTotal         = flag.Int64("mem-quota-total", 0, "the quota in bytes of the memory tracked by the executors of all the sessions, the oom-action is taken when it's exceeded, set \"0\" to disable the quota.")
+	oomAction             = flag.String("oom-action", memory.ActionCancel, "the action taken when mem-quota-total is exceeded, [cancel, reject]. \"cancel\" cancels the statement consuming the most memory, \"reject\" fails the executors which consume more memory.")
+	serverMemQuota        = flag.Int64("server-mem-quota", 0, "the quota in bytes of the memory tracked by the executors of all the sessions, the new memory-intensive statements are rejected when server-mem-shed-ratio of it is reached, and the consumption beyond it fails. It can't be used with mem-quota-total, set \"0\" to disable it.")
+	serverMemShedRatio    = flag.Float64("server-mem-shed-ratio", 0.8, "the fraction of server-mem-quota at which the server starts to reject the new memory-intensive statements, in (0, 1].")
+	serverMemKillLargest  = flagBoolean("server-mem-kill-largest", false, "cancel the statement consuming the most memory when server-mem-quota is exceeded, instead of failing the consumption beyond it.")
+	enableTopSQL          = flagBoolean("enable-top-sql", false, "aggregate the execution count, CPU time and execution time of the statements by digest, they're shown by \"admin show top sql\".")
+	topSQLWindow          = flag.String("top-sql-window", "5m", "the statements executed within this duration are aggregated by top SQL.")
+	topSQLMaxDigests      = flag.Int("top-sql-max-digests", 1000, "the max number of digests tracked by top SQL, the least recently executed one is evicted when it's exceeded.")
+	maxScanRegions        = flag.Int("max-scan-regions", 0, "the max number of regions a coprocessor request of a query scans, the queries scanning more regions fail, set \"0\" to disable the limit.")
+	regionCacheTTL        = flag.String("region-cache-ttl", "10m", "the regions cached longer than this duration are reloaded from PD when they're accessed, set \"0\" to keep them until region errors invalidate them.")
+	timeJumpBackCounter   = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "tidb",
 			Subsystem: "monitor",
@@ -101,7 +144,51 @@ func main() {
 	statsLeaseDuration := parseLease(*statsLease)
 	tidb.SetStatsLease(statsLeaseDuration)
 	ddl.RunWorker = *runDDL
+	if *indexLookupSize <= 0 {
+		log.Fatalf("invalid index-lookup-size %d, it should be positive", *indexLookupSize)
+	}
+	variable.SetDefaultIndexLookupSize(*indexLookupSize)
+	if *buildStatsConcurrency <= 0 {
+		log.Fatalf("invalid build-stats-concurrency %d, it should be positive", *buildStatsConcurrency)
+	}
+	variable.SetDefaultBuildStatsConcurrency(*buildStatsConcurrency)
+	if *hashJoinConcurrency < 0 {
+		log.Fatalf("invalid hash-join-concurrency %d, it should not be negative", *hashJoinConcurrency)
+	}
+	if *hashJoinConcurrency == 0 && *joinCon > 0 {
+		*hashJoinConcurrency = *joinCon
+	}
+	if *hashJoinConcurrency > 0 {
+		variable.SetDefaultHashJoinConcurrency(*hashJoinConcurrency)
+	}
+	if *projectionConcurrency <= 0 {
+		log.Fatalf("invalid projection-concurrency %d, it should be positive", *projectionConcurrency)
+	}
+	variable.SetDefaultProjectionConcurrency(*projectionConcurrency)
+	if *oomAction != memory.ActionCancel && *oomAction != memory.ActionReject {
+		log.Fatalf("invalid oom-action %s, it should be cancel or reject", *oomAction)
+	}
+	if *serverMemQuota > 0 && *memQuotaTotal > 0 {
+		log.Fatalf("server-mem-quota and mem-quota-total can't be set together")
+	}
+	if *serverMemShedRatio <= 0 || *serverMemShedRatio > 1 {
+		log.Fatalf("invalid server-mem-shed-ratio %v, it should be in (0, 1]", *serverMemShedRatio)
+	}
+	if *maxScanRegions < 0 {
+		log.Fatalf("invalid max-scan-regions %d, it should not be negative", *maxScanRegions)
+	}
+	if *topSQLMaxDigests <= 0 {
+		log.Fatalf("invalid top-sql-max-digests %d, it should be positive", *topSQLMaxDigests)
+	}
+	topSQLWindowDuration := parseDuration(*topSQLWindow)
+	if topSQLWindowDuration <= 0 {
+		log.Fatalf("invalid top-sql-window %s, it should be positive", *topSQLWindow)
+	}
+	ddl.HistoryJobLimit = *ddlHistoryLimit
 	tidb.SetCommitRetryLimit(*retryLimit)
+	tidb.SetStoreConnectTimeout(parseDuration(*storeConnectTimeout))
+	tikv.RegionCacheTTL = parseDuration(*regionCacheTTL)
+	tikv.MaxScanRegions = *maxScanRegions
 
 	cfg := config.GetGlobalConfig()
 	cfg.Addr = fmt.Sprintf("%s:%s", *host, *port)
@@ -112,23 +199,64 @@ func main() {
 	cfg.Store = *store
 	cfg.StorePath = *storePath
 	cfg.SlowThreshold = *slowThreshold
-	cfg.QueryLogMaxlen = *queryLogMaxlen
+	cfg.QueryLogMaxlen = *logQueryMaxLen
+	if isFlagSet("query-log-max-len") && !isFlagSet("log-query-max-len") {
+		cfg.QueryLogMaxlen = *queryLogMaxlen
+	}
+	if cfg.QueryLogMaxlen < 0 {
+		log.Fatalf("invalid log-query-max-len %d, it should not be negative", cfg.QueryLogMaxlen)
+	}
+	cfg.LogRedactLiterals = *logRedactLiterals
 	cfg.TCPKeepAlive = *tcpKeepAlive
+	cfg.HandshakeTimeout = parseDuration(*handshakeTimeout)
+	cfg.IdleInTxnTimeout = parseDuration(*idleInTxnTimeout)
+	cfg.ForceTextProtocol = *forceTextProtocol
+	cfg.DumpDir = *dumpDir
+	cfg.EnableGlobalKill = *enableGlobalKill
+	cfg.LogConnections = *logConnections
+	cfg.DisallowEmptyPassword = *disallowEmptyPassword
+	cfg.ExportDir = *exportDir
+	cfg.ExportConcurrency = *exportConcurrency
+	cfg.SQLLogDir = *sqlLogDir
+	if *generalLogSampleRate <= 0 || *generalLogSampleRate > 1 {
+		log.Fatalf("invalid general-log-sample-rate %v, it should be in (0, 1]", *generalLogSampleRate)
+	}
+	cfg.GeneralLog = *generalLog
+	cfg.GeneralLogFile = *generalLogFile
+	cfg.GeneralLogSampleRate = *generalLogSampleRate
+	if (*statusSSLCert == "") != (*statusSSLKey == "") {
+		log.Fatalf("status-ssl-cert and status-ssl-key should be set together")
+	}
+	if *statusSSLCA != "" && *statusSSLCert == "" {
+		log.Fatalf("status-ssl-ca requires status-ssl-cert and status-ssl-key")
+	}
+	cfg.StatusSSLCert = *statusSSLCert
+	cfg.StatusSSLKey = *statusSSLKey
+	cfg.StatusSSLCA = *statusSSLCA
+	if *maxPreparedStmtCount < 0 {
+		log.Fatalf("invalid max-prepared-stmt-count %d, it should not be negative", *maxPreparedStmtCount)
+	}
+	if *maxPreparedPerConn < 0 {
+		log.Fatalf("invalid max-prepared-stmt-count-per-conn %d, it should not be negative", *maxPreparedPerConn)
+	}
+	cfg.MaxPreparedStmtCount = *maxPreparedStmtCount
+	if *maxPreparedParams < 1 || *maxPreparedParams > config.DefMaxPreparedStmtParams {
+		log.Fatalf("invalid max-prepared-stmt-params %d, it should be in [1, %d]", *maxPreparedParams, config.DefMaxPreparedStmtParams)
+	}
+	cfg.MaxPreparedStmtCountPerConn = *maxPreparedPerConn
+	cfg.MaxPreparedStmtParams = *maxPreparedParams
 
 	// set log options
-	if len(*logFile) > 0 {
-		err := log.SetOutputByName(*logFile)
-		if err != nil {
-			log.Fatal(errors.ErrorStack(err))
-		}
-		log.SetRotateByDay()
-		log.SetHighlighting(false)
+	if *logMessageMaxLen < 0 {
+		log.Fatalf("invalid log-message-max-len %d, it should not be negative", *logMessageMaxLen)
 	}
-
-	if joinCon != nil && *joinCon > 0 {
-		plan.JoinConcurrency = *joinCon
+	err := logutil.InitLogOutput(*logFile, *logMessageMaxLen)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
 	}
-	plan.AllowCartesianProduct = *crossJoin
+
+	variable.SetDefaultAllowCartesianProduct(*crossJoin)
+	plan.MaxJoinTables = *maxJoinTables
 	// Call this before setting log level to make sure that TiDB info could be printed.
 	printer.PrintTiDBInfo()
 	log.SetLevelByString(cfg.LogLevel)
@@ -178,9 +306,29 @@ func main() {
 
 	pushMetric(*metricsAddr, time.Duration(*metricsInterval)*time.Second)
 
+	governorExitCh := make(chan struct{})
+	if *memQuotaTotal > 0 {
+		go memory.NewGovernor(memory.GlobalTracker(), *memQuotaTotal, *oomAction).Run(governorExitCh)
+	} else if *serverMemQuota > 0 {
+		action := memory.ActionReject
+		if *serverMemKillLargest {
+			action = memory.ActionCancel
+		}
+		g := memory.NewGovernor(memory.GlobalTracker(), *serverMemQuota, action)
+		g.SetShedRatio(*serverMemShedRatio)
+		go g.Run(governorExitCh)
+	}
+
+	if *enableTopSQL {
+		collector := topsql.NewCollector(topSQLWindowDuration, *topSQLMaxDigests)
+		topsql.SetGlobal(collector)
+		go collector.Run(governorExitCh)
+	}
+
 	if err := svr.Run(); err != nil {
 		log.Error(err)
 	}
+	close(governorExitCh)
 	domain.Close()
 	os.Exit(0)
 }
@@ -247,16 +395,31 @@ func instanceName() string {
 
 // parseLease parses lease argument string.
 func parseLease(lease string) time.Duration {
-	dur, err := time.ParseDuration(lease)
+	return parseDuration(lease)
+}
+
+func parseDuration(s string) time.Duration {
+	dur, err := time.ParseDuration(s)
 	if err != nil {
-		dur, err = time.ParseDuration(lease + "s")
+		dur, err = time.ParseDuration(s + "s")
 	}
 	if err != nil || dur < 0 {
-		log.Fatalf("invalid lease duration %s", lease)
+		log.Fatalf("invalid duration %s", s)
 	}
 	return dur
 }
 
+// isFlagSet returns whether the flag is set in the command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func hasRootPrivilege() bool {
 	return os.Geteuid() == 0
 }
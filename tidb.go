@@ -107,6 +107,9 @@ var (
 
 	// The maximum number of retries to recover from retryable errors.
 	commitRetryLimit = 10
+
+	// storeConnectTimeout is the maximum time to open a store, 0 means no limit.
+	storeConnectTimeout time.Duration
 )
 
 // SetSchemaLease changes the default schema lease time for DDL.
@@ -130,6 +133,12 @@ func SetCommitRetryLimit(limit int) {
 	commitRetryLimit = limit
 }
 
+// SetStoreConnectTimeout changes the maximum time NewStore waits for the store to be opened,
+// including the retries. NewStore fails if the backend isn't reachable in time, 0 means no limit.
+func SetStoreConnectTimeout(timeout time.Duration) {
+	storeConnectTimeout = timeout
+}
+
 // Parse parses a query string to raw ast.StmtNode.
 func Parse(ctx context.Context, src string) ([]ast.StmtNode, error) {
 	log.Debug("compiling", src)
@@ -247,8 +256,45 @@ func newStoreWithRetry(path string, maxRetries int) (kv.Storage, error) {
 		return nil, errors.Errorf("invalid uri format, storage %s is not registered", name)
 	}
 
-	var s kv.Storage
+	if storeConnectTimeout <= 0 {
+		s, err := openStoreWithRetry(d, path, maxRetries, nil)
+		return s, errors.Trace(err)
+	}
+
+	type result struct {
+		s   kv.Storage
+		err error
+	}
+	timeout := storeConnectTimeout
+	done := make(chan struct{})
+	resultCh := make(chan result, 1)
+	go func() {
+		s, err1 := openStoreWithRetry(d, path, maxRetries, done)
+		resultCh <- result{s: s, err: err1}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.s, errors.Trace(r.err)
+	case <-time.After(timeout):
+		close(done)
+		// The driver can't be interrupted, close the store if it's opened after the timeout.
+		go func() {
+			if r := <-resultCh; r.s != nil {
+				r.s.Close()
+			}
+		}()
+		return nil, errors.Errorf("open store %s timeout after %v, please check whether the storage backend is reachable", name, timeout)
+	}
+}
+
+// openStoreWithRetry opens the store until it succeeds, the error isn't retryable, or done is closed.
+func openStoreWithRetry(d kv.Driver, path string, maxRetries int, done <-chan struct{}) (s kv.Storage, err error) {
 	util.RunWithRetry(maxRetries, retryInterval, func() (bool, error) {
+		select {
+		case <-done:
+			return false, errors.New("open store canceled")
+		default:
+		}
 		s, err = d.Open(path)
 		return kv.IsRetryableError(err), err
 	})
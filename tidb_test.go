@@ -35,6 +35,8 @@ import (
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 )
 
 var store = flag.String("store", "memory", "registered store name, [memory, goleveldb, boltdb]")
@@ -62,6 +64,16 @@ func (s *brokenStore) Open(schema string) (kv.Storage, error) {
 	return nil, errors.New("try again later")
 }
 
+// unreachableStore is a driver whose Open blocks until the backend becomes reachable.
+type unreachableStore struct {
+	reachable chan struct{}
+}
+
+func (s *unreachableStore) Open(schema string) (kv.Storage, error) {
+	<-s.reachable
+	return nil, errors.New("backend is reachable")
+}
+
 func (s *testMainSuite) SetUpSuite(c *C) {
 	testleak.BeforeTest()
 	s.dbName = "test_main_db"
@@ -251,6 +263,63 @@ func (s *testMainSuite) TestRetryOpenStore(c *C) {
 	c.Assert(uint64(elapse), GreaterEqual, uint64(3*time.Second))
 }
 
+func (s *testMainSuite) TestOpenStoreTimeout(c *C) {
+	defer SetStoreConnectTimeout(0)
+	SetStoreConnectTimeout(100 * time.Millisecond)
+
+	d := &unreachableStore{reachable: make(chan struct{})}
+	defer close(d.reachable)
+	RegisterStore("unreachable", d)
+	begin := time.Now()
+	_, err := NewStore("unreachable://unreachable-store")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*timeout after 100ms.*")
+	c.Assert(uint64(time.Since(begin)), Less, uint64(time.Second))
+
+	// The retries of the retryable errors are limited by the timeout too.
+	RegisterStore("broken", &brokenStore{})
+	begin = time.Now()
+	_, err = NewStore("broken://broken-store")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*timeout after 100ms.*")
+	c.Assert(uint64(time.Since(begin)), Less, uint64(time.Second))
+}
+
+func (s *testMainSuite) TestExecuteDurationMetrics(c *C) {
+	store := newStoreWithBootstrap(c, s.dbName+"execute_duration_metrics")
+	defer store.Close()
+	se := newSession(c, store, "test_execute_duration_metrics")
+	mustExecSQL(c, se, "create table t (a int, b int)")
+
+	sampleCount := func(h prometheus.Histogram) uint64 {
+		m := &dto.Metric{}
+		err := h.Write(m)
+		c.Assert(err, IsNil)
+		return m.GetHistogram().GetSampleCount()
+	}
+	parse, compile, run := sampleCount(sessionExecuteParseDuration), sampleCount(sessionExecuteCompileDuration), sampleCount(sessionExecuteRunDuration)
+	mustExecSQL(c, se, "select count(*) from t t1 join t t2 on t1.a = t2.a where t1.b > 1 group by t1.b")
+	c.Assert(sampleCount(sessionExecuteParseDuration), Equals, parse+1)
+	c.Assert(sampleCount(sessionExecuteCompileDuration), Equals, compile+1)
+	c.Assert(sampleCount(sessionExecuteRunDuration), Equals, run+1)
+
+	// The plan building is not observed if the statement fails to parse,
+	// and the execution is not observed if the statement fails to build a plan.
+	_, err := se.Execute("select * from")
+	c.Assert(err, NotNil)
+	_, err = se.Execute("select * from not_exists")
+	c.Assert(err, NotNil)
+	c.Assert(sampleCount(sessionExecuteParseDuration), Equals, parse+2)
+	c.Assert(sampleCount(sessionExecuteCompileDuration), Equals, compile+1)
+	c.Assert(sampleCount(sessionExecuteRunDuration), Equals, run+1)
+
+	// The buckets cover the plan building of complex queries which takes milliseconds.
+	m := &dto.Metric{}
+	c.Assert(sessionExecuteCompileDuration.Write(m), IsNil)
+	buckets := m.GetHistogram().GetBucket()
+	c.Assert(buckets[len(buckets)-1].GetUpperBound(), Greater, float64(1))
+}
+
 // TODO: Merge TestIssue1435 in session test.
 func (s *testMainSuite) TestSchemaValidity(c *C) {
 	localstore.MockRemoteStore = true
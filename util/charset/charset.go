@@ -156,6 +156,17 @@ func GetCollations() []*Collation {
 	return collations
 }
 
+// GetCollationByName returns the collation of the name, the collations of the unsupported charsets aren't returned.
+func GetCollationByName(name string) (*Collation, error) {
+	name = strings.ToLower(name)
+	for _, c := range charsetInfos {
+		if co, ok := c.Collations[name]; ok {
+			return co, nil
+		}
+	}
+	return nil, errors.Errorf("Unknown collation %s", name)
+}
+
 const (
 	// CharsetBin is used for marking binary charset.
 	CharsetBin = "binary"
@@ -0,0 +1,110 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/juju/errors"
+)
+
+const bufferSize = 64 * 1024
+
+// File is a temporary file the records are appended to and then read back in the same order.
+// It's used by the executors to move the data exceeding the memory quota to disk.
+type File struct {
+	f      *os.File
+	w      *bufio.Writer
+	header [binary.MaxVarintLen64]byte
+	bytes  int64
+}
+
+// NewFile creates a temporary file in the dir, the default temporary directory is used if dir is empty.
+func NewFile(dir, prefix string) (*File, error) {
+	f, err := ioutil.TempFile(dir, prefix)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &File{f: f, w: bufio.NewWriterSize(f, bufferSize)}, nil
+}
+
+// Append appends a record to the file.
+func (f *File) Append(record []byte) error {
+	n := binary.PutUvarint(f.header[:], uint64(len(record)))
+	if _, err := f.w.Write(f.header[:n]); err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := f.w.Write(record); err != nil {
+		return errors.Trace(err)
+	}
+	f.bytes += int64(n + len(record))
+	return nil
+}
+
+// Bytes returns the number of bytes appended to the file.
+func (f *File) Bytes() int64 {
+	return f.bytes
+}
+
+// NewReader flushes the appended records and returns a reader from the beginning of the file.
+// No records should be appended after it's called.
+func (f *File) NewReader() (*Reader, error) {
+	if err := f.w.Flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := f.f.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The buffer is never nil, so an empty record can be told from the end of the file.
+	return &Reader{r: bufio.NewReaderSize(f.f, bufferSize), buf: make([]byte, 0, 64)}, nil
+}
+
+// Close closes and removes the file.
+func (f *File) Close() error {
+	err := f.f.Close()
+	if err1 := os.Remove(f.f.Name()); err == nil {
+		err = err1
+	}
+	return errors.Trace(err)
+}
+
+// Reader reads the records of a File in the order they were appended.
+type Reader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// Next returns the next record, or nil if all the records have been read.
+// The returned slice is only valid until the next call.
+func (r *Reader) Next() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if uint64(cap(r.buf)) < size {
+		r.buf = make([]byte, size)
+	}
+	r.buf = r.buf[:size]
+	if _, err = io.ReadFull(r.r, r.buf); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r.buf, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disk
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testFileSuite{})
+
+type testFileSuite struct{}
+
+func (s *testFileSuite) TestFile(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "util_disk_test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	f, err := NewFile(dir, "test")
+	c.Assert(err, IsNil)
+	var records [][]byte
+	var size int64
+	for i := 0; i < 10000; i++ {
+		record := []byte(fmt.Sprintf("record %d", i))
+		if i%100 == 0 {
+			// Empty and large records.
+			record = bytes.Repeat(record, i)
+		}
+		records = append(records, record)
+		c.Assert(f.Append(record), IsNil)
+		size += int64(len(record))
+	}
+	c.Assert(f.Bytes(), Greater, size)
+
+	// The records can be read more than once.
+	for i := 0; i < 2; i++ {
+		r, err := f.NewReader()
+		c.Assert(err, IsNil)
+		for _, record := range records {
+			got, err := r.Next()
+			c.Assert(err, IsNil)
+			c.Assert(got, BytesEquals, record)
+		}
+		got, err := r.Next()
+		c.Assert(err, IsNil)
+		c.Assert(got, IsNil)
+	}
+
+	c.Assert(f.Close(), IsNil)
+	files, err := ioutil.ReadDir(dir)
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 0)
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+// highlightReset is the escape sequence that ends a highlighted log entry.
+const highlightReset = "\033[0m"
+
+// InitLogOutput sets the output of the server log. The log is appended to the file and rotated by day if the file
+// is set, otherwise it's written to stderr. The log entries longer than maxMessageLen bytes are truncated,
+// 0 means no truncation.
+func InitLogOutput(file string, maxMessageLen int) error {
+	if maxMessageLen == 0 && len(file) > 0 {
+		if err := log.SetOutputByName(file); err != nil {
+			return errors.Trace(err)
+		}
+		log.SetRotateByDay()
+		log.SetHighlighting(false)
+		return nil
+	}
+	var out io.Writer = os.Stderr
+	if len(file) > 0 {
+		// The truncateWriter wraps the file, so the file is rotated by dailyRotateFile instead of ngaut/log.
+		f, err := openDailyRotateFile(file)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		out = f
+		log.SetHighlighting(false)
+	}
+	if maxMessageLen > 0 {
+		out = &truncateWriter{w: out, maxLen: maxMessageLen}
+	}
+	log.SetOutput(out)
+	return nil
+}
+
+// truncateWriter truncates the log entries longer than maxLen bytes with an ellipsis and the original length,
+// the trailing newline and the end of the highlighting are kept.
+// The logger writes an entry by a single Write call and serializes the calls, so it needs no lock.
+type truncateWriter struct {
+	w      io.Writer
+	maxLen int
+}
+
+// Write implements the io.Writer interface.
+func (w *truncateWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.maxLen {
+		return w.w.Write(p)
+	}
+	msg, suffix := p, ""
+	if bytes.HasSuffix(msg, []byte("\n")) {
+		msg, suffix = msg[:len(msg)-1], "\n"
+	}
+	if bytes.HasSuffix(msg, []byte(highlightReset)) {
+		msg, suffix = msg[:len(msg)-len(highlightReset)], highlightReset+suffix
+	}
+	if len(msg) <= w.maxLen {
+		return w.w.Write(p)
+	}
+	end := w.maxLen
+	// Don't split a multi-byte character.
+	for end > 0 && !utf8.RuneStart(msg[end]) {
+		end--
+	}
+	buf := make([]byte, 0, end+len(suffix)+32)
+	buf = append(buf, msg[:end]...)
+	buf = append(buf, fmt.Sprintf("...(len:%d)", len(msg))...)
+	buf = append(buf, suffix...)
+	if _, err := w.w.Write(buf); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ngaut/log"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testLogSuite{})
+
+type testLogSuite struct {
+}
+
+func (s *testLogSuite) TestTruncateWriter(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		entry  string
+		expect string
+	}{
+		{"[info] short\n", "[info] short\n"},
+		{"[error] 0123456789\n", "[error] 0123...(len:18)\n"},
+		{"[error] 0123456789", "[error] 0123...(len:18)"},
+		// The end of the highlighting is kept.
+		{"\033[31m[error] 0123456789" + highlightReset + "\n", "\033[31m[error]...(len:23)" + highlightReset + "\n"},
+		{"[info] 12345" + highlightReset + "\n", "[info] 12345" + highlightReset + "\n"},
+		// The characters aren't split.
+		{"[info] 中文字符\n", "[info] 中...(len:19)\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := &truncateWriter{w: &buf, maxLen: 12}
+		n, err := w.Write([]byte(tt.entry))
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, len(tt.entry))
+		c.Assert(buf.String(), Equals, tt.expect, Commentf("entry %q", tt.entry))
+	}
+}
+
+func (s *testLogSuite) TestInitLogOutput(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "tidb_log")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetHighlighting(true)
+	}()
+
+	path := filepath.Join(dir, "tidb.log")
+	err = InitLogOutput(path, 100)
+	c.Assert(err, IsNil)
+	log.Error("short message")
+	log.Error(strings.Repeat("x", 1000))
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	c.Assert(lines, HasLen, 2)
+	c.Assert(lines[0], Matches, ".*\\[error\\] short message $")
+	c.Assert(len(lines[1]), Less, 120)
+	c.Assert(lines[1], Matches, ".*\\[error\\] x+\\.\\.\\.\\(len:[0-9]+\\)$")
+}
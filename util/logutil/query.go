@@ -0,0 +1,75 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/parser"
+)
+
+// FormatQuery returns the SQL text written to the slow query log and the general log.
+// The literal values are replaced by '?' if LogRedactLiterals is set in the config or the statement may
+// carry a password, and the text longer than QueryLogMaxlen characters is truncated with an ellipsis and its length.
+func FormatQuery(cfg *config.Config, sql string) string {
+	if cfg.LogRedactLiterals {
+		sql = parser.Normalize(sql)
+	} else if mayHavePassword(sql) {
+		if normalized := parser.Normalize(sql); hasPassword(normalized) {
+			sql = normalized
+		}
+	}
+	return truncateQuery(sql, cfg.QueryLogMaxlen)
+}
+
+// passwordStmtPrefixes are the prefixes of the normalized statements which may carry passwords.
+var passwordStmtPrefixes = []string{"create user ", "alter user ", "set password ", "grant "}
+
+// mayHavePassword is a quick check before the statement is normalized, a password is set by IDENTIFIED BY or PASSWORD.
+func mayHavePassword(sql string) bool {
+	sql = strings.ToLower(sql)
+	return strings.Contains(sql, "identified") || strings.Contains(sql, "password")
+}
+
+// hasPassword checks whether the normalized statement may carry a password.
+func hasPassword(normalized string) bool {
+	for _, prefix := range passwordStmtPrefixes {
+		if strings.HasPrefix(normalized, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateQuery truncates the SQL text to maxLen characters, 0 means no truncation.
+func truncateQuery(sql string, maxLen int) string {
+	if maxLen <= 0 || len(sql) <= maxLen {
+		return sql
+	}
+	n := utf8.RuneCountInString(sql)
+	if n <= maxLen {
+		return sql
+	}
+	var end, count int
+	for end = range sql {
+		if count == maxLen {
+			break
+		}
+		count++
+	}
+	return sql[:end] + fmt.Sprintf("...(len:%d)", n)
+}
@@ -0,0 +1,83 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testQuerySuite{})
+
+type testQuerySuite struct {
+}
+
+func (s *testQuerySuite) TestTruncateQuery(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql    string
+		maxLen int
+		expect string
+	}{
+		{"select 1", 0, "select 1"},
+		{"select 1", 8, "select 1"},
+		{"select 1", 100, "select 1"},
+		{"select 12345", 8, "select 1...(len:12)"},
+		// The characters are counted instead of the bytes.
+		{"select '中文字符'", 10, "select '中文...(len:13)"},
+		{"select '中文'", 11, "select '中文'"},
+	}
+	for _, tt := range tests {
+		c.Assert(truncateQuery(tt.sql, tt.maxLen), Equals, tt.expect, Commentf("sql %s, max len %d", tt.sql, tt.maxLen))
+	}
+}
+
+func (s *testQuerySuite) TestFormatQuery(c *C) {
+	defer testleak.AfterTest(c)()
+	cfg := &config.Config{}
+	sql := "update users set password = 'secret' where id = 10"
+	c.Assert(FormatQuery(cfg, sql), Equals, sql)
+	cfg.LogRedactLiterals = true
+	c.Assert(FormatQuery(cfg, sql), Equals, "update users set password = ? where id = ?")
+	// The text is truncated after the literals are redacted.
+	cfg.QueryLogMaxlen = 20
+	c.Assert(FormatQuery(cfg, sql), Equals, "update users set pas...(len:42)")
+	cfg.LogRedactLiterals = false
+	c.Assert(FormatQuery(cfg, sql), Equals, "update users set pas...(len:50)")
+
+	// The passwords are always redacted.
+	cfg.QueryLogMaxlen = 0
+	tests := []struct {
+		sql    string
+		expect string
+	}{
+		{"CREATE USER 'u'@'%' IDENTIFIED BY 'secret'", "create user ?  ? identified by ?"},
+		{"/* c */ alter user 'u'@'%' identified by 'secret'", "alter user ?  ? identified by ?"},
+		{"SET PASSWORD FOR 'u'@'%' = PASSWORD('secret')", "set password for ?  ? = password ( ? )"},
+		{"grant all on *.* to 'u'@'%' identified by 'secret'", "grant all on * . * to ?  ? identified by ?"},
+		{"select password from t where a = 'x'", "select password from t where a = 'x'"},
+		{"create user 'u'@'%'", "create user 'u'@'%'"},
+	}
+	for _, tt := range tests {
+		c.Assert(FormatQuery(cfg, tt.sql), Equals, tt.expect, Commentf("sql %s", tt.sql))
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+// dailyRotateFile appends the log to the file, the file is renamed with the suffix of its date and a new one is
+// created when the date changes. It's the same as the daily rotation of ngaut/log, which can't be used with
+// truncateWriter: ngaut/log rotates the file inside the logger and sets the new file as the output, which drops
+// the writers wrapping the file.
+type dailyRotateFile struct {
+	path   string
+	f      *os.File
+	suffix string
+}
+
+func openDailyRotateFile(path string) (*dailyRotateFile, error) {
+	r := &dailyRotateFile{path: path, suffix: time.Now().Format(log.FORMAT_TIME_DAY)}
+	if err := r.open(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
+
+func (r *dailyRotateFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.f = f
+	return nil
+}
+
+// Write implements the io.Writer interface.
+func (r *dailyRotateFile) Write(p []byte) (int, error) {
+	if suffix := time.Now().Format(log.FORMAT_TIME_DAY); suffix != r.suffix {
+		r.f.Close()
+		if err := os.Rename(r.path, r.path+"."+r.suffix); err != nil {
+			fmt.Fprintf(os.Stderr, "rotate log file %s error %v\n", r.path, err)
+		}
+		if err := r.open(); err != nil {
+			return 0, errors.Trace(err)
+		}
+		r.suffix = suffix
+	}
+	return r.f.Write(p)
+}
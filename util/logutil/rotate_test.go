@@ -0,0 +1,48 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testLogSuite) TestDailyRotateFile(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "tidb_log")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tidb.log")
+	r, err := openDailyRotateFile(path)
+	c.Assert(err, IsNil)
+	defer r.f.Close()
+	_, err = r.Write([]byte("today\n"))
+	c.Assert(err, IsNil)
+	// Pretend the file is opened yesterday.
+	r.suffix = "20170101"
+	_, err = r.Write([]byte("tomorrow\n"))
+	c.Assert(err, IsNil)
+
+	data, err := ioutil.ReadFile(path + ".20170101")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "today\n")
+	data, err = ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "tomorrow\n")
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"time"
+
+	"github.com/ngaut/log"
+)
+
+// The actions taken by the governor when the memory quota is exceeded.
+const (
+	// ActionCancel cancels the statement consuming the most memory.
+	ActionCancel = "cancel"
+	// ActionReject rejects the memory consumption of the executors until the memory is released below the quota.
+	ActionReject = "reject"
+)
+
+// governorInterval is the interval the governor checks the memory consumption.
+var governorInterval = 100 * time.Millisecond
+
+// Governor watches the memory tracked by a root tracker against a quota,
+// and takes the action when the quota is exceeded.
+type Governor struct {
+	root   *Tracker
+	quota  int64
+	action string
+	// shedBytes is the memory consumption at which the governor starts to shed load, 0 means no load shedding.
+	shedBytes int64
+	// exceeded is used to log only once when the quota is exceeded.
+	exceeded bool
+}
+
+// NewGovernor creates a governor for the root tracker, the action is ActionCancel or ActionReject.
+func NewGovernor(root *Tracker, quota int64, action string) *Governor {
+	return &Governor{root: root, quota: quota, action: action}
+}
+
+// SetShedRatio makes the governor mark the root tracker shedding when the memory consumption reaches ratio * quota,
+// and unmark it when the consumption falls below again.
+func (g *Governor) SetShedRatio(ratio float64) {
+	g.shedBytes = int64(float64(g.quota) * ratio)
+}
+
+// Run checks the memory consumption periodically until exitCh is closed.
+func (g *Governor) Run(exitCh <-chan struct{}) {
+	if g.action == ActionReject {
+		g.root.SetBytesLimit(g.quota)
+		defer g.root.SetBytesLimit(0)
+	}
+	defer g.root.SetShedding(false)
+	ticker := time.NewTicker(governorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			g.check()
+		case <-exitCh:
+			return
+		}
+	}
+}
+
+func (g *Governor) check() {
+	consumed := g.root.BytesConsumed()
+	if g.shedBytes > 0 {
+		shedding := consumed >= g.shedBytes
+		if shedding != g.root.Shedding() {
+			log.Warnf("[memory] the tracked memory %d bytes, quota %d bytes, load shedding %v", consumed, g.quota, shedding)
+			g.root.SetShedding(shedding)
+		}
+	}
+	if consumed <= g.quota {
+		g.exceeded = false
+		return
+	}
+	if !g.exceeded {
+		log.Warnf("[memory] the tracked memory %d bytes exceeds the quota %d bytes, action %s", consumed, g.quota, g.action)
+		g.exceeded = true
+	}
+	if g.action != ActionCancel {
+		return
+	}
+	// Wait for the killed statement to release its memory before killing another one.
+	child := g.root.largestChild()
+	if child != nil && child.Kill() {
+		log.Warnf("[memory] kill %s which consumes %d bytes", child.Label(), child.BytesConsumed())
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	trackedBytesGauge = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "memory_tracked_bytes",
+			Help:      "Total memory tracked by the executors of all the sessions.",
+		}, func() float64 {
+			return float64(globalTracker.BytesConsumed())
+		})
+)
+
+func init() {
+	prometheus.MustRegister(trackedBytesGauge)
+}
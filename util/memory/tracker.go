@@ -0,0 +1,225 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+)
+
+const (
+	codeQueryKilled    terror.ErrCode = terror.ErrCode(mysql.ErrQueryInterrupted)
+	codeMemoryExceeded terror.ErrCode = terror.ErrCode(mysql.ErrOutofMemory)
+	codeServerBusy     terror.ErrCode = terror.ErrCode(mysql.ErrOutOfResources)
+)
+
+// Error instances.
+var (
+	ErrQueryKilled    = terror.ClassUtil.New(codeQueryKilled, "Query execution was interrupted, it consumes the most memory when the server memory quota is exceeded")
+	ErrMemoryExceeded = terror.ClassUtil.New(codeMemoryExceeded, "Out of memory, the memory tracked by the server exceeds the quota %d bytes")
+	ErrServerBusy     = terror.ClassUtil.New(codeServerBusy, "Out of resources, the memory tracked by the server is close to the quota, the memory-intensive statements are rejected")
+)
+
+func init() {
+	memoryMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeQueryKilled:    mysql.ErrQueryInterrupted,
+		codeMemoryExceeded: mysql.ErrOutofMemory,
+		codeServerBusy:     mysql.ErrOutOfResources,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassUtil] = memoryMySQLErrCodes
+}
+
+// Tracker tracks the memory consumed by an executor, a statement or the whole server.
+// The trackers make up a tree, the memory consumed by a tracker is also counted by all its ancestors,
+// so the root tracker knows the total memory consumed by all the trackers attached to it.
+type Tracker struct {
+	label string
+	// parentMu protects parent, the consumption holds the read lock while it's added to the ancestors,
+	// so the bytes consumed by the tracker are released exactly once when it's detached.
+	parentMu sync.RWMutex
+	parent   *Tracker
+
+	bytesConsumed int64
+	// bytesLimit is the quota of the tracker, the consumption which makes the tracker exceed it is rejected.
+	// 0 means no limit.
+	bytesLimit int64
+	killed     int32
+	// shedding is set when the memory consumption is close to the quota, the new memory-intensive work should be rejected.
+	shedding int32
+	// onKill is called when the tracker is killed, it interrupts the work which consumes the memory.
+	onKill func()
+
+	mu struct {
+		sync.Mutex
+		children map[*Tracker]struct{}
+	}
+}
+
+// NewTracker creates a tracker with a label, which is used to identify the tracker in the logs.
+func NewTracker(label string) *Tracker {
+	t := &Tracker{label: label}
+	t.mu.children = make(map[*Tracker]struct{})
+	return t
+}
+
+var globalTracker = NewTracker("server")
+
+// GlobalTracker returns the root tracker of the server, all the statement trackers are attached to it.
+func GlobalTracker() *Tracker {
+	return globalTracker
+}
+
+// Label returns the label of the tracker.
+func (t *Tracker) Label() string {
+	return t.label
+}
+
+// SetKillFunc sets the function which is called when the tracker is killed.
+func (t *Tracker) SetKillFunc(f func()) {
+	t.onKill = f
+}
+
+// SetBytesLimit sets the quota of the tracker, 0 means no limit.
+func (t *Tracker) SetBytesLimit(limit int64) {
+	atomic.StoreInt64(&t.bytesLimit, limit)
+}
+
+// AttachTo attaches the tracker to the parent, the memory consumed by the tracker is counted by the parent since then.
+// It does nothing if the parent is nil.
+func (t *Tracker) AttachTo(parent *Tracker) {
+	if parent == nil {
+		return
+	}
+	t.parentMu.Lock()
+	defer t.parentMu.Unlock()
+	t.detach()
+	parent.mu.Lock()
+	parent.mu.children[t] = struct{}{}
+	parent.mu.Unlock()
+	t.parent = parent
+	parent.consume(t.BytesConsumed())
+}
+
+// Detach detaches the tracker from its parent, the memory consumed by the tracker is released from its ancestors.
+func (t *Tracker) Detach() {
+	t.parentMu.Lock()
+	defer t.parentMu.Unlock()
+	t.detach()
+}
+
+// detach detaches the tracker from its parent, parentMu must be locked.
+func (t *Tracker) detach() {
+	parent := t.parent
+	if parent == nil {
+		return
+	}
+	parent.mu.Lock()
+	delete(parent.mu.children, t)
+	parent.mu.Unlock()
+	t.parent = nil
+	parent.consume(-t.BytesConsumed())
+}
+
+func (t *Tracker) getParent() *Tracker {
+	t.parentMu.RLock()
+	defer t.parentMu.RUnlock()
+	return t.parent
+}
+
+// Consume adds the bytes to the memory consumed by the tracker and its ancestors, a negative value releases memory.
+// It returns ErrQueryKilled if the tracker or any of its ancestors has been killed, or ErrMemoryExceeded
+// if the consumption makes any of them exceed its quota. The bytes are still counted when an error is returned,
+// they're released when the tracker is detached.
+func (t *Tracker) Consume(bytes int64) error {
+	t.consume(bytes)
+	if bytes <= 0 {
+		return nil
+	}
+	for tracker := t; tracker != nil; tracker = tracker.getParent() {
+		if atomic.LoadInt32(&tracker.killed) != 0 {
+			return ErrQueryKilled
+		}
+		limit := atomic.LoadInt64(&tracker.bytesLimit)
+		if limit > 0 && tracker.BytesConsumed() > limit {
+			return ErrMemoryExceeded.GenByArgs(limit)
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) consume(bytes int64) {
+	t.parentMu.RLock()
+	defer t.parentMu.RUnlock()
+	atomic.AddInt64(&t.bytesConsumed, bytes)
+	if t.parent != nil {
+		t.parent.consume(bytes)
+	}
+}
+
+// BytesConsumed returns the memory consumed by the tracker and its descendants.
+func (t *Tracker) BytesConsumed() int64 {
+	return atomic.LoadInt64(&t.bytesConsumed)
+}
+
+// Kill marks the tracker killed so the following consumption of it and its descendants fails,
+// and calls the kill function to interrupt the work. It returns false if the tracker has been killed.
+func (t *Tracker) Kill() bool {
+	if !atomic.CompareAndSwapInt32(&t.killed, 0, 1) {
+		return false
+	}
+	if t.onKill != nil {
+		t.onKill()
+	}
+	return true
+}
+
+// Killed returns whether the tracker has been killed.
+func (t *Tracker) Killed() bool {
+	return atomic.LoadInt32(&t.killed) != 0
+}
+
+// SetShedding sets whether the tracker is shedding load.
+func (t *Tracker) SetShedding(shedding bool) {
+	var v int32
+	if shedding {
+		v = 1
+	}
+	atomic.StoreInt32(&t.shedding, v)
+}
+
+// Shedding returns whether the tracker is shedding load, the new memory-intensive work should be rejected if it's true.
+func (t *Tracker) Shedding() bool {
+	return atomic.LoadInt32(&t.shedding) != 0
+}
+
+// largestChild returns the child consuming the most memory, or nil if the tracker has no children.
+// The children are compared after the lock is released, so attaching and detaching the trackers aren't blocked.
+func (t *Tracker) largestChild() *Tracker {
+	t.mu.Lock()
+	children := make([]*Tracker, 0, len(t.mu.children))
+	for child := range t.mu.children {
+		children = append(children, child)
+	}
+	t.mu.Unlock()
+	var largest *Tracker
+	for _, child := range children {
+		if largest == nil || child.BytesConsumed() > largest.BytesConsumed() {
+			largest = child
+		}
+	}
+	return largest
+}
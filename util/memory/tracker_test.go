@@ -0,0 +1,205 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTrackerSuite{})
+
+type testTrackerSuite struct{}
+
+func (s *testTrackerSuite) TestConsume(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("root")
+	stmt := NewTracker("stmt")
+	stmt.AttachTo(root)
+	sort := NewTracker("sort")
+	c.Assert(sort.Consume(100), IsNil)
+	sort.AttachTo(stmt)
+	c.Assert(root.BytesConsumed(), Equals, int64(100))
+
+	join := NewTracker("join")
+	join.AttachTo(stmt)
+	c.Assert(join.Consume(50), IsNil)
+	c.Assert(join.Consume(-20), IsNil)
+	c.Assert(stmt.BytesConsumed(), Equals, int64(130))
+	c.Assert(root.BytesConsumed(), Equals, int64(130))
+	c.Assert(root.largestChild(), Equals, stmt)
+
+	sort.Detach()
+	c.Assert(sort.BytesConsumed(), Equals, int64(100))
+	c.Assert(stmt.BytesConsumed(), Equals, int64(30))
+	c.Assert(root.BytesConsumed(), Equals, int64(30))
+	stmt.Detach()
+	c.Assert(root.BytesConsumed(), Equals, int64(0))
+	c.Assert(root.largestChild(), IsNil)
+	// Detaching a detached tracker does nothing.
+	stmt.Detach()
+	c.Assert(root.BytesConsumed(), Equals, int64(0))
+}
+
+func (s *testTrackerSuite) TestConcurrentDetach(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("root")
+	stmt := NewTracker("stmt")
+	stmt.AttachTo(root)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				stmt.Consume(1)
+			}
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stmt.Detach()
+		}()
+	}
+	wg.Wait()
+	// The consumption is released from the parent exactly once whenever it's counted.
+	c.Assert(stmt.BytesConsumed(), Equals, int64(8000))
+	c.Assert(root.BytesConsumed(), Equals, int64(0))
+}
+
+func (s *testTrackerSuite) TestBytesLimit(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("root")
+	root.SetBytesLimit(100)
+	stmt := NewTracker("stmt")
+	stmt.AttachTo(root)
+	c.Assert(stmt.Consume(100), IsNil)
+	err := stmt.Consume(1)
+	c.Assert(terror.ErrorEqual(err, ErrMemoryExceeded), IsTrue, Commentf("err %v", err))
+	// Releasing memory is always allowed.
+	c.Assert(stmt.Consume(-51), IsNil)
+	c.Assert(stmt.Consume(1), IsNil)
+	root.SetBytesLimit(0)
+	c.Assert(stmt.Consume(1000), IsNil)
+}
+
+func (s *testTrackerSuite) TestKill(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("root")
+	stmt := NewTracker("stmt")
+	stmt.AttachTo(root)
+	sort := NewTracker("sort")
+	sort.AttachTo(stmt)
+	var killed int
+	stmt.SetKillFunc(func() { killed++ })
+	c.Assert(stmt.Kill(), IsTrue)
+	c.Assert(stmt.Kill(), IsFalse)
+	c.Assert(killed, Equals, 1)
+	c.Assert(stmt.Killed(), IsTrue)
+	err := sort.Consume(10)
+	c.Assert(terror.ErrorEqual(err, ErrQueryKilled), IsTrue, Commentf("err %v", err))
+	c.Assert(sort.Consume(-10), IsNil)
+}
+
+func (s *testTrackerSuite) TestGovernor(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("root")
+	small, large := NewTracker("small"), NewTracker("large")
+	small.AttachTo(root)
+	large.AttachTo(root)
+	c.Assert(small.Consume(40), IsNil)
+	c.Assert(large.Consume(60), IsNil)
+
+	g := NewGovernor(root, 100, ActionCancel)
+	g.check()
+	c.Assert(large.Killed(), IsFalse)
+	c.Assert(small.Consume(1), IsNil)
+	g.check()
+	c.Assert(large.Killed(), IsTrue)
+	c.Assert(small.Killed(), IsFalse)
+	// The killed statement is still the largest one, so no more statements are killed until it releases the memory.
+	g.check()
+	c.Assert(small.Killed(), IsFalse)
+
+	root = NewTracker("root")
+	stmt := NewTracker("stmt")
+	stmt.AttachTo(root)
+	exitCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		NewGovernor(root, 100, ActionReject).Run(exitCh)
+		close(done)
+	}()
+	// Wait for the governor to set the limit.
+	for i := 0; i < 100 && stmt.Consume(101) == nil; i++ {
+		c.Assert(stmt.Consume(-101), IsNil)
+		time.Sleep(10 * time.Millisecond)
+	}
+	err := stmt.Consume(1)
+	c.Assert(terror.ErrorEqual(err, ErrMemoryExceeded), IsTrue, Commentf("err %v", err))
+	c.Assert(stmt.Killed(), IsFalse)
+	close(exitCh)
+	<-done
+	c.Assert(stmt.Consume(1), IsNil)
+}
+
+func (s *testTrackerSuite) TestLoadShedding(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("root")
+	g := NewGovernor(root, 100, ActionCancel)
+	g.SetShedRatio(0.8)
+	// Simulate the sessions consuming memory together.
+	sessions := make([]*Tracker, 4)
+	for i := range sessions {
+		sessions[i] = NewTracker("session")
+		sessions[i].AttachTo(root)
+		c.Assert(sessions[i].Consume(int64(15+i)), IsNil)
+	}
+	g.check()
+	c.Assert(root.Shedding(), IsFalse)
+
+	// 15 + 16 + 17 + 32 = 80 reaches the shedding threshold, but not the quota.
+	c.Assert(sessions[3].Consume(14), IsNil)
+	g.check()
+	c.Assert(root.Shedding(), IsTrue)
+	for _, t := range sessions {
+		c.Assert(t.Killed(), IsFalse)
+	}
+
+	// The largest session is killed when the quota is exceeded.
+	c.Assert(sessions[0].Consume(21), IsNil)
+	g.check()
+	c.Assert(root.Shedding(), IsTrue)
+	c.Assert(sessions[0].Killed(), IsTrue)
+	for _, t := range sessions[1:] {
+		c.Assert(t.Killed(), IsFalse)
+	}
+
+	// The load shedding stops when the memory is released below the threshold.
+	sessions[0].Detach()
+	g.check()
+	c.Assert(root.Shedding(), IsFalse)
+}
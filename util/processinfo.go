@@ -15,6 +15,8 @@ package util
 
 import (
 	"time"
+
+	"github.com/pingcap/tidb/util/sqllog"
 )
 
 // ProcessInfo is a struct used for show processlist statement.
@@ -27,11 +29,28 @@ type ProcessInfo struct {
 	Time    time.Time
 	State   uint16
 	Info    string
+	// Plan is the plan of the running statement, it's used by EXPLAIN FOR CONNECTION,
+	// which only reads it because it's being executed.
+	Plan interface{}
+	// IsolationLevel is the isolation level of the transactions started by the connection.
+	IsolationLevel string
+	// TxnMode is the mode of the transactions started by the connection.
+	TxnMode string
+	// InTxn is true if the connection is in an explicit transaction.
+	InTxn bool
 }
 
+// TxnModeOptimistic is the only transaction mode now, conflicts are checked when the transaction commits.
+const TxnModeOptimistic = "OPTIMISTIC"
+
 // SessionManager is an interface for session manage. Show processlist and
 // kill statement rely on this interface.
 type SessionManager interface {
 	ShowProcessList() []ProcessInfo
-	Kill(connectionID uint64, query bool)
+	// Kill kills the connection or the query running on it, an error is returned if the KILL request
+	// can't be sent to the server owning the connection.
+	Kill(connectionID uint64, query bool) error
+	// SetSQLLogger sets the logger writing the statements of the connection, nil disables it.
+	// It returns false if the connection doesn't exist, the former logger is closed.
+	SetSQLLogger(connectionID uint64, logger *sqllog.Logger) bool
 }
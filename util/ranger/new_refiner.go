@@ -14,6 +14,8 @@
 package ranger
 
 import (
+	"sort"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
@@ -184,6 +186,121 @@ func detachIndexScanConditions(conditions []expression.Expression, cols []*expre
 	return accessConds, filterConds, accessEqualCount, accessInAndEqCount
 }
 
+// detachDNFPointConditions finds the DNF condition whose every item pins the leading index columns by equal conditions
+// with constants, e.g. "(a, b) in ((1, 2), (3, 4))" or "a = 1 and b = 2 or a = 3 and b = 4" for index (a, b).
+// The offset of the condition is returned with the equal conditions of every item on the leading columns pinned by
+// all the items, which are ordered by the index columns. If the condition is not fully converted to the equal conditions,
+// exact is false and it should be kept as a filter. If there are several such conditions, the one pins the most columns is chosen.
+func detachDNFPointConditions(conditions []expression.Expression, cols []*expression.Column, lengths []int) (offset int,
+	points [][]expression.Expression, exact bool) {
+	offset = -1
+	for i, cond := range conditions {
+		sf, ok := cond.(*expression.ScalarFunction)
+		if !ok || sf.FuncName.L != ast.LogicOr {
+			continue
+		}
+		dnfItems := expression.SplitDNFItems(sf)
+		itemPoints := make([][]expression.Expression, 0, len(dnfItems))
+		eqCount, itemsExact := len(cols), true
+		for _, item := range dnfItems {
+			eqConds, itemExact := getPointConditions(item, cols, lengths)
+			if len(eqConds) < eqCount {
+				eqCount = len(eqConds)
+			}
+			itemsExact = itemsExact && itemExact
+			itemPoints = append(itemPoints, eqConds)
+		}
+		if eqCount == 0 || (len(points) > 0 && eqCount <= len(points[0])) {
+			continue
+		}
+		for j := range itemPoints {
+			if len(itemPoints[j]) > eqCount {
+				itemPoints[j] = itemPoints[j][:eqCount]
+				itemsExact = false
+			}
+		}
+		offset, points, exact = i, itemPoints, itemsExact
+	}
+	return offset, points, exact
+}
+
+// getPointConditions gets the equal conditions with constants on the leading index columns from the CNF condition,
+// they're returned in the order of the index columns. exact is false if there are other conditions in the CNF condition.
+func getPointConditions(cond expression.Expression, cols []*expression.Column, lengths []int) (eqConds []expression.Expression, exact bool) {
+	eqConds = make([]expression.Expression, len(cols))
+	exact = true
+	for _, item := range expression.SplitCNFItems(cond) {
+		offset := getEQColOffset(item, cols)
+		if offset == -1 || eqConds[offset] != nil || lengths[offset] != types.UnspecifiedLength {
+			exact = false
+			continue
+		}
+		eqConds[offset] = item
+	}
+	for i, eqCond := range eqConds {
+		if eqCond != nil {
+			continue
+		}
+		for _, rest := range eqConds[i+1:] {
+			if rest != nil {
+				exact = false
+				break
+			}
+		}
+		return eqConds[:i], exact
+	}
+	return eqConds, exact
+}
+
+// buildPointIndexRanges builds the point ranges of the DNF items, the ranges are sorted and the duplicated ones are removed,
+// so every row is read only once and the order of the index is kept.
+func buildPointIndexRanges(sc *variable.StatementContext, cols []*expression.Column, lengths []int,
+	points [][]expression.Expression) ([]*types.IndexRange, error) {
+	var ranges []*types.IndexRange
+	for _, eqConds := range points {
+		itemRanges, err := buildIndexRange(sc, cols, lengths, len(eqConds), eqConds)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ranges = append(ranges, itemRanges...)
+	}
+	var err error
+	sort.Slice(ranges, func(i, j int) bool {
+		cmp, err1 := compareIndexValues(sc, ranges[i].LowVal, ranges[j].LowVal)
+		if err1 != nil {
+			err = err1
+		}
+		return cmp < 0
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := ranges[:0]
+	for i, ran := range ranges {
+		if i > 0 {
+			cmp, err := compareIndexValues(sc, ranges[i-1].LowVal, ran.LowVal)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if cmp == 0 {
+				continue
+			}
+		}
+		result = append(result, ran)
+	}
+	return result, nil
+}
+
+func compareIndexValues(sc *variable.StatementContext, a, b []types.Datum) (int, error) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		cmp, err := a[i].CompareDatum(sc, b[i])
+		if err != nil || cmp != 0 {
+			return cmp, errors.Trace(err)
+		}
+	}
+	return len(a) - len(b), nil
+}
+
 // buildColumnRange builds the range for sampling histogram to calculate the row count.
 func buildColumnRange(conds []expression.Expression, sc *variable.StatementContext, tp *types.FieldType) ([]*types.ColumnRange, error) {
 	if len(conds) == 0 {
@@ -230,8 +347,24 @@ func BuildRange(sc *variable.StatementContext, conds []expression.Expression, ra
 		}
 	} else if rangeType == IndexRangeType {
 		var eqAndInCount int
+		originConds := append([]expression.Expression(nil), conds...)
+		dnfOffset, points, exact := detachDNFPointConditions(originConds, cols, lengths)
 		accessConditions, otherConditions, _, eqAndInCount = detachIndexScanConditions(conds, cols, lengths)
-		ranges, err := buildIndexRange(sc, cols, lengths, eqAndInCount, accessConditions)
+		var ranges []*types.IndexRange
+		var err error
+		// Use the point ranges of the DNF condition only if it pins more index columns,
+		// e.g. "(a, b) in ((1, 2), (3, 4))" can't be converted to the ranges by the equal and in conditions.
+		if dnfOffset != -1 && len(points[0]) > eqAndInCount {
+			accessConditions = []expression.Expression{originConds[dnfOffset]}
+			if exact {
+				otherConditions = append(originConds[:dnfOffset:dnfOffset], originConds[dnfOffset+1:]...)
+			} else {
+				otherConditions = originConds
+			}
+			ranges, err = buildPointIndexRanges(sc, cols, lengths, points)
+		} else {
+			ranges, err = buildIndexRange(sc, cols, lengths, eqAndInCount, accessConditions)
+		}
 		if err != nil {
 			return nil, nil, nil, errors.Trace(err)
 		}
@@ -275,6 +275,31 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 			resultStr:  `[[a 1,a 1] [a 2,a 2] [a 3,a 3]]`,
 			inAndEqCnt: 2,
 		},
+		{
+			exprStr:    `(a, b) in (('b', 2), ('a', 1), ('b', 2))`,
+			resultStr:  `[[a 1,a 1] [b 2,b 2]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `a = 'a' and b = 1 or b = 2 and a = 'b' or a = 'a' and b = 3`,
+			resultStr:  `[[a 1,a 1] [a 3,a 3] [b 2,b 2]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `(a = 'a' or a = 'b') and b > 1`,
+			resultStr:  `[[a,a] [b,b]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `a = 'a' and b = 1 or a = 'b'`,
+			resultStr:  `[[a,a] [b,b]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `a = 'a' and b = 1 or b = 2`,
+			resultStr:  `[[<nil>,+inf]]`,
+			inAndEqCnt: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -311,6 +336,113 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 	}
 }
 
+func (s *testRangerSuite) TestTimeIndexRange(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := newStoreWithBootstrap()
+	defer store.Close()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t")
+	testKit.MustExec("create table t(a date, b datetime, c timestamp null, d time, index idx_a(a), index idx_b(b), index idx_c(c), index idx_d(d))")
+
+	tests := []struct {
+		exprStr   string
+		idx       int
+		resultStr string
+	}{
+		{
+			exprStr:   "a between '2020-01-01' and '2020-02-01'",
+			idx:       0,
+			resultStr: "[[2020-01-01,2020-02-01]]",
+		},
+		{
+			exprStr:   "a between '2020-01-01 10:00:00' and '2020-02-01 10:00:00'",
+			idx:       0,
+			resultStr: "[(2020-01-01,2020-02-01]]",
+		},
+		{
+			exprStr:   "a > '2020-1-1' and a < '2020-2-1'",
+			idx:       0,
+			resultStr: "[(2020-01-01,2020-02-01)]",
+		},
+		{
+			exprStr:   "a >= 20200101 and a < 20200201",
+			idx:       0,
+			resultStr: "[[2020-01-01,2020-02-01)]",
+		},
+		{
+			exprStr:   "a = '2020-01-01 10:00:00'",
+			idx:       0,
+			resultStr: "[]",
+		},
+		{
+			exprStr:   "a in ('2020-01-02', '2020-1-2', '2020-01-01 10:00:00')",
+			idx:       0,
+			resultStr: "[[2020-01-02,2020-01-02]]",
+		},
+		{
+			exprStr:   "b between '2020-01-01' and '2020-02-01'",
+			idx:       1,
+			resultStr: "[[2020-01-01 00:00:00,2020-02-01 00:00:00]]",
+		},
+		{
+			exprStr:   "b >= '2020-1-5' and b < '2020-02-01 10:00:00'",
+			idx:       1,
+			resultStr: "[[2020-01-05 00:00:00,2020-02-01 10:00:00)]",
+		},
+		{
+			exprStr:   "c between '2020-01-01' and '2020-02-01'",
+			idx:       2,
+			resultStr: "[[2020-01-01 00:00:00,2020-02-01 00:00:00]]",
+		},
+		{
+			exprStr:   "c > '2020-01-01 10:00:00'",
+			idx:       2,
+			resultStr: "[(2020-01-01 10:00:00,+inf]]",
+		},
+		{
+			exprStr:   "d between '10:00:00' and '11:00'",
+			idx:       3,
+			resultStr: "[[10:00:00,11:00:00]]",
+		},
+	}
+
+	for _, tt := range tests {
+		sql := "select * from t where " + tt.exprStr
+		ctx := testKit.Se.(context.Context)
+		stmts, err := tidb.Parse(ctx, sql)
+		c.Assert(err, IsNil, Commentf("error %v, for expr %s", err, tt.exprStr))
+		c.Assert(stmts, HasLen, 1)
+		is := sessionctx.GetDomain(ctx).InfoSchema()
+		err = plan.ResolveName(stmts[0], is, ctx)
+		c.Assert(err, IsNil, Commentf("error %v, for resolve name, expr %s", err, tt.exprStr))
+		p, err := plan.BuildLogicalPlan(ctx, stmts[0], is)
+		c.Assert(err, IsNil, Commentf("error %v, for build plan, expr %s", err, tt.exprStr))
+		var selection *plan.Selection
+		for _, child := range p.Children() {
+			plan, ok := child.(*plan.Selection)
+			if ok {
+				selection = plan
+				break
+			}
+		}
+		c.Assert(selection, NotNil, Commentf("expr:%v", tt.exprStr))
+		tbl := selection.Children()[0].(*plan.DataSource).TableInfo()
+		conds := make([]expression.Expression, 0, len(selection.Conditions))
+		for _, cond := range selection.Conditions {
+			conds = append(conds, expression.PushDownNot(cond, false, ctx))
+		}
+		cols, lengths := expression.IndexInfo2Cols(selection.Schema().Columns, tbl.Indices[tt.idx])
+		c.Assert(cols, NotNil)
+		result, _, filterConds, err := ranger.BuildRange(ctx.GetSessionVars().StmtCtx, conds, ranger.IndexRangeType, cols, lengths)
+		c.Assert(err, IsNil)
+		c.Assert(filterConds, HasLen, 0, Commentf("different for expr %s", tt.exprStr))
+		got := fmt.Sprintf("%v", result)
+		c.Assert(got, Equals, tt.resultStr, Commentf("different for expr %s", tt.exprStr))
+	}
+}
+
 func (s *testRangerSuite) TestColumnRange(c *C) {
 	defer testleak.AfterTest(c)()
 	store, err := newStoreWithBootstrap()
@@ -0,0 +1,101 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SlowQueryInfo is a statement recorded by the slow query log, it's used for the
+// information_schema.slow_query table.
+type SlowQueryInfo struct {
+	ConnID   uint64
+	User     string
+	Host     string
+	DB       string
+	Start    time.Time
+	Duration time.Duration
+	Query    string
+	TraceID  string
+}
+
+// SlowQueryBuffer is a ring buffer which keeps the latest slow queries.
+type SlowQueryBuffer struct {
+	mu    sync.Mutex
+	items []*SlowQueryInfo
+	next  int
+	full  bool
+}
+
+// NewSlowQueryBuffer creates a SlowQueryBuffer which keeps at most capacity slow queries.
+func NewSlowQueryBuffer(capacity int) *SlowQueryBuffer {
+	return &SlowQueryBuffer{items: make([]*SlowQueryInfo, capacity)}
+}
+
+// SlowQueries keeps the latest slow queries of this server.
+var SlowQueries = NewSlowQueryBuffer(1024)
+
+// Push adds a slow query, the oldest one is dropped if the buffer is full.
+func (b *SlowQueryBuffer) Push(info *SlowQueryInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.items) == 0 {
+		return
+	}
+	b.items[b.next] = info
+	b.next++
+	if b.next == len(b.items) {
+		b.next = 0
+		b.full = true
+	}
+}
+
+// Items returns the slow queries in the buffer, from the oldest to the latest.
+func (b *SlowQueryBuffer) Items() []*SlowQueryInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]*SlowQueryInfo(nil), b.items[:b.next]...)
+	}
+	items := make([]*SlowQueryInfo, 0, len(b.items))
+	items = append(items, b.items[b.next:]...)
+	return append(items, b.items[:b.next]...)
+}
+
+// Recent returns at most count latest slow queries, from the latest to the oldest.
+func (b *SlowQueryBuffer) Recent(count int) []*SlowQueryInfo {
+	items := b.Items()
+	if count > len(items) {
+		count = len(items)
+	}
+	recent := make([]*SlowQueryInfo, 0, count)
+	for i := len(items) - 1; i >= len(items)-count; i-- {
+		recent = append(recent, items[i])
+	}
+	return recent
+}
+
+// Top returns at most count slowest queries in the buffer, from the slowest one.
+func (b *SlowQueryBuffer) Top(count int) []*SlowQueryInfo {
+	items := b.Items()
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Duration > items[j].Duration
+	})
+	if count < len(items) {
+		items = items[:count]
+	}
+	return items
+}
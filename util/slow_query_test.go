@@ -0,0 +1,64 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testSlowQuerySuite{})
+
+type testSlowQuerySuite struct {
+}
+
+func (s *testSlowQuerySuite) TestSlowQueryBuffer(c *C) {
+	defer testleak.AfterTest(c)()
+	ids := func(items []*SlowQueryInfo) []uint64 {
+		var res []uint64
+		for _, item := range items {
+			res = append(res, item.ConnID)
+		}
+		return res
+	}
+	b := NewSlowQueryBuffer(3)
+	c.Assert(b.Items(), HasLen, 0)
+	b.Push(&SlowQueryInfo{ConnID: 1})
+	b.Push(&SlowQueryInfo{ConnID: 2})
+	c.Assert(ids(b.Items()), DeepEquals, []uint64{1, 2})
+	b.Push(&SlowQueryInfo{ConnID: 3})
+	c.Assert(ids(b.Items()), DeepEquals, []uint64{1, 2, 3})
+	b.Push(&SlowQueryInfo{ConnID: 4})
+	b.Push(&SlowQueryInfo{ConnID: 5})
+	c.Assert(ids(b.Items()), DeepEquals, []uint64{3, 4, 5})
+
+	c.Assert(ids(b.Recent(2)), DeepEquals, []uint64{5, 4})
+	c.Assert(ids(b.Recent(10)), DeepEquals, []uint64{5, 4, 3})
+	c.Assert(b.Recent(0), HasLen, 0)
+
+	b = NewSlowQueryBuffer(4)
+	b.Push(&SlowQueryInfo{ConnID: 1, Duration: 2 * time.Second})
+	b.Push(&SlowQueryInfo{ConnID: 2, Duration: 3 * time.Second})
+	b.Push(&SlowQueryInfo{ConnID: 3, Duration: time.Second})
+	b.Push(&SlowQueryInfo{ConnID: 4, Duration: 3 * time.Second})
+	c.Assert(ids(b.Top(3)), DeepEquals, []uint64{2, 4, 1})
+	c.Assert(ids(b.Top(10)), DeepEquals, []uint64{2, 4, 1, 3})
+	c.Assert(ids(b.Items()), DeepEquals, []uint64{1, 2, 3, 4})
+
+	b = NewSlowQueryBuffer(0)
+	b.Push(&SlowQueryInfo{ConnID: 1})
+	c.Assert(b.Items(), HasLen, 0)
+}
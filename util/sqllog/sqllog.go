@@ -0,0 +1,79 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqllog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+// Logger writes the statements executed by a single session to a file, it's enabled by
+// "admin enable log for connection" to debug the behavior of one client.
+// Each line is made of the start time, the connection ID, the execution time and the statement, separated by tabs.
+type Logger struct {
+	connID uint64
+	path   string
+
+	mu struct {
+		sync.Mutex
+		// file is nil after the logger is closed.
+		file *os.File
+	}
+}
+
+// NewLogger creates a Logger appending the statements of the connection to the file of path.
+func NewLogger(connID uint64, path string) (*Logger, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	l := &Logger{connID: connID, path: path}
+	l.mu.file = f
+	return l, nil
+}
+
+// Path returns the path of the log file.
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// Write writes a statement started at startTime and executed in cost to the file.
+func (l *Logger) Write(startTime time.Time, cost time.Duration, sql string) {
+	line := fmt.Sprintf("%s\t%d\t%v\t%s\n", startTime.Format("2006-01-02T15:04:05.000000Z07:00"), l.connID, cost, sql)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.file == nil {
+		return
+	}
+	if _, err := l.mu.file.WriteString(line); err != nil {
+		log.Errorf("[%d] write sql log %s failed: %v", l.connID, l.path, err)
+	}
+}
+
+// Close closes the file, the statements written later are dropped.
+func (l *Logger) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.file != nil {
+		if err := l.mu.file.Close(); err != nil {
+			log.Errorf("[%d] close sql log %s failed: %v", l.connID, l.path, err)
+		}
+		l.mu.file = nil
+	}
+}
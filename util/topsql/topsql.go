@@ -0,0 +1,232 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topsql
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bucketsPerWindow is the number of the buckets the window is divided into, the window rolls by a bucket.
+const bucketsPerWindow = 10
+
+// sampleInterval is the interval the CPU time of the process is sampled and attributed to the running statements.
+var sampleInterval = 100 * time.Millisecond
+
+// Stats is the statistics of the statements with the same digest in the window.
+type Stats struct {
+	Digest string
+	// SQL is the normalized text of the statements.
+	SQL       string
+	ExecCount int64
+	CPUTime   time.Duration
+	TotalTime time.Duration
+	LastSeen  time.Time
+}
+
+// Stmt is a statement being executed, the CPU time of the process is attributed to it while it's running.
+type Stmt struct {
+	collector *Collector
+	start     time.Time
+	// cpu is the CPU time attributed to the statement, it's protected by the mutex of the collector.
+	cpu time.Duration
+}
+
+type bucket struct {
+	// slot is the number of the time slot the bucket is for, the bucket is reset when it's reused.
+	slot      int64
+	execCount int64
+	cpuTime   time.Duration
+	totalTime time.Duration
+}
+
+type digestEntry struct {
+	digest   string
+	sql      string
+	lastSeen time.Time
+	buckets  [bucketsPerWindow]bucket
+}
+
+// Collector aggregates the execution count, the CPU time and the total execution time of the statements
+// by digest in a rolling window. The number of the digests is capped, the least recently executed digest
+// is evicted when a new one comes.
+//
+// The CPU time of a single statement can't be measured since its work is done by many goroutines,
+// so the CPU time consumed by the process is sampled periodically and divided equally among the
+// statements running at that moment. It's an approximation which is good for the frequent statements.
+type Collector struct {
+	bucketSize time.Duration
+	maxDigests int
+
+	mu struct {
+		sync.Mutex
+		digests map[string]*list.Element
+		// lru holds the *digestEntry, the most recently executed one is at the front.
+		lru     *list.List
+		running map[*Stmt]struct{}
+	}
+}
+
+// NewCollector creates a Collector which aggregates the statements executed in the last window,
+// and tracks at most maxDigests digests.
+func NewCollector(window time.Duration, maxDigests int) *Collector {
+	bucketSize := window / bucketsPerWindow
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+	c := &Collector{bucketSize: bucketSize, maxDigests: maxDigests}
+	c.mu.digests = make(map[string]*list.Element)
+	c.mu.lru = list.New()
+	c.mu.running = make(map[*Stmt]struct{})
+	return c
+}
+
+var globalCollector atomic.Value
+
+// SetGlobal sets the collector of this server, nil disables top SQL.
+func SetGlobal(c *Collector) {
+	globalCollector.Store(&c)
+}
+
+// Global returns the collector of this server, it's nil if top SQL is disabled.
+func Global() *Collector {
+	if c, ok := globalCollector.Load().(**Collector); ok {
+		return *c
+	}
+	return nil
+}
+
+// Begin starts tracking a statement, Finish must be called when the statement is done.
+func (c *Collector) Begin() *Stmt {
+	s := &Stmt{collector: c, start: time.Now()}
+	c.mu.Lock()
+	c.mu.running[s] = struct{}{}
+	c.mu.Unlock()
+	return s
+}
+
+// Finish stops tracking the statement and adds it to the statistics of its digest.
+func (s *Stmt) Finish(digest, sql string) {
+	c := s.collector
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.mu.running, s)
+	c.record(digest, sql, now, now.Sub(s.start), s.cpu)
+}
+
+func (c *Collector) record(digest, sql string, now time.Time, totalTime, cpuTime time.Duration) {
+	var e *digestEntry
+	if elem, ok := c.mu.digests[digest]; ok {
+		c.mu.lru.MoveToFront(elem)
+		e = elem.Value.(*digestEntry)
+	} else {
+		if c.mu.lru.Len() >= c.maxDigests {
+			oldest := c.mu.lru.Back()
+			if oldest == nil {
+				return
+			}
+			c.mu.lru.Remove(oldest)
+			delete(c.mu.digests, oldest.Value.(*digestEntry).digest)
+		}
+		e = &digestEntry{digest: digest, sql: sql}
+		c.mu.digests[digest] = c.mu.lru.PushFront(e)
+	}
+	slot := now.UnixNano() / int64(c.bucketSize)
+	b := &e.buckets[slot%bucketsPerWindow]
+	if b.slot != slot {
+		*b = bucket{slot: slot}
+	}
+	b.execCount++
+	b.cpuTime += cpuTime
+	b.totalTime += totalTime
+	e.lastSeen = now
+}
+
+// Run samples the CPU time of the process and attributes it to the running statements until exitCh is closed.
+func (c *Collector) Run(exitCh <-chan struct{}) {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+	last := getProcessCPUTime()
+	for {
+		select {
+		case <-ticker.C:
+			cur := getProcessCPUTime()
+			c.attributeCPU(cur - last)
+			last = cur
+		case <-exitCh:
+			return
+		}
+	}
+}
+
+// attributeCPU divides the CPU time equally among the running statements,
+// it's dropped if no statement is running.
+func (c *Collector) attributeCPU(cpu time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cpu <= 0 || len(c.mu.running) == 0 {
+		return
+	}
+	share := cpu / time.Duration(len(c.mu.running))
+	for s := range c.mu.running {
+		s.cpu += share
+	}
+}
+
+// TopByCPU returns at most n digests with the most CPU time in the window, in descending order.
+func (c *Collector) TopByCPU(n int) []*Stats {
+	return c.top(n, func(a, b *Stats) bool { return a.CPUTime > b.CPUTime })
+}
+
+// TopByTime returns at most n digests with the most total execution time in the window, in descending order.
+func (c *Collector) TopByTime(n int) []*Stats {
+	return c.top(n, func(a, b *Stats) bool { return a.TotalTime > b.TotalTime })
+}
+
+func (c *Collector) top(n int, less func(a, b *Stats) bool) []*Stats {
+	all := c.snapshot(time.Now())
+	sort.SliceStable(all, func(i, j int) bool { return less(all[i], all[j]) })
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
+
+// snapshot returns the statistics of the digests executed in the window, from the most recently executed one.
+func (c *Collector) snapshot(now time.Time) []*Stats {
+	minSlot := now.UnixNano()/int64(c.bucketSize) - bucketsPerWindow + 1
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var all []*Stats
+	for elem := c.mu.lru.Front(); elem != nil; elem = elem.Next() {
+		e := elem.Value.(*digestEntry)
+		st := &Stats{Digest: e.digest, SQL: e.sql, LastSeen: e.lastSeen}
+		for _, b := range e.buckets {
+			if b.slot < minSlot {
+				continue
+			}
+			st.ExecCount += b.execCount
+			st.CPUTime += b.cpuTime
+			st.TotalTime += b.totalTime
+		}
+		if st.ExecCount > 0 {
+			all = append(all, st)
+		}
+	}
+	return all
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topsql
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testTopSQLSuite{})
+
+type testTopSQLSuite struct {
+}
+
+func digests(stats []*Stats) []string {
+	var res []string
+	for _, st := range stats {
+		res = append(res, st.Digest)
+	}
+	return res
+}
+
+func (s *testTopSQLSuite) TestTop(c *C) {
+	defer testleak.AfterTest(c)()
+	col := NewCollector(time.Minute, 10)
+	col.mu.Lock()
+	now := time.Now()
+	col.record("a", "select ?", now, 10*time.Millisecond, 8*time.Millisecond)
+	col.record("b", "update t set a = ?", now, 50*time.Millisecond, 2*time.Millisecond)
+	col.record("a", "select ?", now, 10*time.Millisecond, 8*time.Millisecond)
+	col.record("c", "insert into t values ( ? )", now, 15*time.Millisecond, 5*time.Millisecond)
+	col.mu.Unlock()
+
+	c.Assert(digests(col.TopByCPU(10)), DeepEquals, []string{"a", "c", "b"})
+	c.Assert(digests(col.TopByTime(10)), DeepEquals, []string{"b", "a", "c"})
+	top := col.TopByCPU(1)
+	c.Assert(top, HasLen, 1)
+	c.Assert(*top[0], DeepEquals, Stats{
+		Digest:    "a",
+		SQL:       "select ?",
+		ExecCount: 2,
+		CPUTime:   16 * time.Millisecond,
+		TotalTime: 20 * time.Millisecond,
+		LastSeen:  now,
+	})
+}
+
+func (s *testTopSQLSuite) TestWindow(c *C) {
+	defer testleak.AfterTest(c)()
+	col := NewCollector(10*time.Second, 10)
+	now := time.Now()
+	col.mu.Lock()
+	col.record("a", "select ?", now.Add(-20*time.Second), time.Second, time.Second)
+	col.record("b", "select ?", now.Add(-5*time.Second), time.Second, time.Second)
+	col.record("b", "select ?", now, time.Second, time.Second)
+	col.mu.Unlock()
+
+	// The executions out of the window aren't counted.
+	stats := col.snapshot(now)
+	c.Assert(digests(stats), DeepEquals, []string{"b"})
+	c.Assert(stats[0].ExecCount, Equals, int64(2))
+	stats = col.snapshot(now.Add(8 * time.Second))
+	c.Assert(stats[0].ExecCount, Equals, int64(1))
+	c.Assert(col.snapshot(now.Add(20*time.Second)), HasLen, 0)
+}
+
+func (s *testTopSQLSuite) TestEviction(c *C) {
+	defer testleak.AfterTest(c)()
+	col := NewCollector(time.Minute, 2)
+	now := time.Now()
+	col.mu.Lock()
+	col.record("a", "", now, time.Second, 0)
+	col.record("b", "", now, time.Second, 0)
+	col.record("a", "", now, time.Second, 0)
+	// "b" is the least recently executed digest.
+	col.record("c", "", now, time.Second, 0)
+	col.mu.Unlock()
+	c.Assert(digests(col.snapshot(now)), DeepEquals, []string{"c", "a"})
+}
+
+func (s *testTopSQLSuite) TestAttributeCPU(c *C) {
+	defer testleak.AfterTest(c)()
+	col := NewCollector(time.Minute, 10)
+	// The CPU time is dropped if no statement is running.
+	col.attributeCPU(time.Second)
+	s1 := col.Begin()
+	col.attributeCPU(10 * time.Millisecond)
+	s2 := col.Begin()
+	col.attributeCPU(10 * time.Millisecond)
+	s1.Finish("a", "select ?")
+	col.attributeCPU(10 * time.Millisecond)
+	s2.Finish("b", "select ?")
+
+	stats := col.TopByCPU(10)
+	c.Assert(digests(stats), DeepEquals, []string{"b", "a"})
+	c.Assert(stats[0].CPUTime, Equals, 15*time.Millisecond)
+	c.Assert(stats[1].CPUTime, Equals, 15*time.Millisecond)
+	c.Assert(col.mu.running, HasLen, 0)
+}
+
+func (s *testTopSQLSuite) TestGlobal(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(Global(), IsNil)
+	col := NewCollector(time.Minute, 10)
+	SetGlobal(col)
+	c.Assert(Global(), Equals, col)
+	SetGlobal(nil)
+	c.Assert(Global(), IsNil)
+}
@@ -246,10 +246,29 @@ func (s *testTypeConvertSuite) TestConvertType(c *C) {
 	v, err = Convert("-10000", ft)
 	c.Assert(terror.ErrorEqual(err, ErrOverflow), IsTrue)
 	c.Assert(v.(*MyDecimal).String(), Equals, "-9999.9999")
+	_, err = Convert("1.2x", ft)
+	c.Assert(terror.ErrorEqual(err, ErrTruncated), IsTrue)
+	_, err = Convert("x", ft)
+	c.Assert(terror.ErrorEqual(err, ErrTruncated), IsTrue)
+	v, err = Convert(" 1.2 ", ft)
+	c.Assert(err, IsNil)
+	c.Assert(v.(*MyDecimal).String(), Equals, "1.2000")
+
+	// The invalid characters are truncated with a warning if TruncateAsWarning is set.
+	sc := &variable.StatementContext{TruncateAsWarning: true}
+	d := NewDatum("1.2x")
+	ret, err := d.ConvertTo(sc, ft)
+	c.Assert(err, IsNil)
+	c.Assert(ret.GetMysqlDecimal().String(), Equals, "1.2000")
+	d = NewDatum("x")
+	ret, err = d.ConvertTo(sc, ft)
+	c.Assert(err, IsNil)
+	c.Assert(ret.GetMysqlDecimal().String(), Equals, "0.0000")
+	c.Assert(sc.WarningCount(), Equals, uint16(2))
 
 	// Test Datum.ToDecimal with bad number.
-	d := NewDatum("hello")
-	sc := new(variable.StatementContext)
+	d = NewDatum("hello")
+	sc = new(variable.StatementContext)
 	v, err = d.ToDecimal(sc)
 	c.Assert(terror.ErrorEqual(err, ErrBadNumber), IsTrue)
 
@@ -1084,7 +1084,14 @@ func (d *Datum) convertToMysqlDecimal(sc *variable.StatementContext, target *Fie
 	case KindFloat32, KindFloat64:
 		dec.FromFloat64(d.GetFloat64())
 	case KindString, KindBytes:
-		err = dec.FromString(d.GetBytes())
+		// Only the valid number prefix of the string is converted, like "1.2x" is converted to 1.2
+		// and "x" to 0, the truncation is an error or a warning depending on the StmtCtx.
+		var s string
+		s, err = getValidFloatPrefix(sc, strings.TrimSpace(d.GetString()))
+		if err != nil {
+			return ret, errors.Trace(err)
+		}
+		err = dec.FromString(hack.Slice(s))
 	case KindMysqlDecimal:
 		*dec = *d.GetMysqlDecimal()
 	case KindMysqlTime:
@@ -619,6 +619,8 @@ func (s *testMyDecimalSuite) TestMul(c *C) {
 		{"123", "0.01", "1.23", nil},
 		{"123", "0", "0", nil},
 		{"1" + strings.Repeat("0", 60), "1" + strings.Repeat("0", 60), "0", ErrOverflow},
+		{strings.Repeat("9", 65), "123456789.5", "12345678899999999999999999999999999999999999999999999999999999999876543211", ErrTruncated},
+		{strings.Repeat("9", 30) + "." + strings.Repeat("9", 30), strings.Repeat("9", 20) + ".9", "99999999999999999998999999999999999999999999999999.999999900000000000000000001", ErrTruncated},
 	}
 	for _, tt := range tests {
 		var a, b, product MyDecimal
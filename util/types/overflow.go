@@ -60,9 +60,8 @@ func SubUint64(a uint64, b uint64) (uint64, error) {
 
 // SubInt64 subtracts int64 a with b and returns int64 if no overflow error.
 func SubInt64(a int64, b int64) (int64, error) {
-	if (a > 0 && b < 0 && math.MaxInt64-a < -b) ||
-		(a < 0 && b > 0 && math.MinInt64-a > -b) ||
-		(a == 0 && b == math.MinInt64) {
+	if (a >= 0 && b < 0 && a > math.MaxInt64+b) ||
+		(a < 0 && b > 0 && a < math.MinInt64+b) {
 		return 0, ErrOverflow.GenByArgs("BIGINT", fmt.Sprintf("(%d, %d)", a, b))
 	}
 	return a - b, nil
@@ -131,6 +131,8 @@ func (s *testOverflowSuite) TestSub(c *C) {
 		{math.MaxInt64, -1, 0, true},
 		{0, math.MinInt64, 0, true},
 		{-1, math.MinInt64, math.MaxInt64, false},
+		{1, math.MinInt64, 0, true},
+		{math.MaxInt64, math.MinInt64, 0, true},
 		{math.MinInt64, math.MaxInt64, 0, true},
 		{math.MinInt64, math.MinInt64, 0, false},
 		{math.MinInt64, -math.MaxInt64, -1, false},